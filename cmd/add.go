@@ -1,13 +1,18 @@
 package cmd
 
 import (
-	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 
+	"github.com/hunkim/sgit/pkg/git"
+	"github.com/hunkim/sgit/pkg/glob"
+	"github.com/hunkim/sgit/pkg/progress"
+	"github.com/hunkim/sgit/pkg/secrets"
 	"github.com/hunkim/sgit/pkg/solar"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -19,18 +24,40 @@ var (
 	addForce  bool
 	addDryRun bool
 	addAI     bool
+
+	addAllowSecrets bool
+	addBatchSize    int
+	addBatchWords   int
+	addJobs         int
 )
 
+// addOnlyFlags are sgit's own add flags, which are never passed through to git
+var addOnlyFlags = map[string]bool{
+	"allow-secrets": true,
+	"batch-size":    true,
+	"batch-words":   true,
+	"jobs":          true,
+}
+
 // addCmd represents the smart add command
 var addCmd = &cobra.Command{
 	Use:   "add [files...]",
 	Short: "Add files to git with optional AI analysis",
 	Long: `Add files to git staging area. By default, analyzes untracked files with AI
-when no specific files are given. Supports all git add options for full compatibility.`,
+when no specific files are given. Supports all git add options for full compatibility.
+
+With -p --ai, each unstaged hunk is shown with a one-line AI explanation and a
+stage/skip recommendation. Answer y or n per hunk, press Enter to do what the AI
+recommends, or a to follow the AI plan for all remaining hunks.
+
+Examples:
+  sgit add --all-ai
+  sgit add -p --ai
+  sgit add -p --ai src/
+  sgit add -p --ai --dry-run-ai`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runSmartAdd(cmd, args); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
@@ -43,6 +70,10 @@ func init() {
 	addCmd.Flags().BoolVar(&addForce, "force-ai", false, "add files without AI confirmation (smart filtering only)")
 	addCmd.Flags().BoolVar(&addDryRun, "dry-run-ai", false, "show what would be added without actually adding")
 	addCmd.Flags().BoolVar(&addAI, "ai", false, "force AI analysis even with specific files")
+	addCmd.Flags().BoolVar(&addAllowSecrets, "allow-secrets", false, "add files even if they contain potential secrets")
+	addCmd.Flags().IntVar(&addBatchSize, "batch-size", 10, "maximum number of files analyzed in one AI request")
+	addCmd.Flags().IntVar(&addBatchWords, "batch-words", 8000, "approximate word budget of one AI request")
+	addCmd.Flags().IntVar(&addJobs, "jobs", 4, "number of AI requests run concurrently")
 
 	// Standard git add flags - we'll pass these through to git
 	addCmd.Flags().BoolP("all", "A", false, "add all changes (git standard)")
@@ -71,11 +102,30 @@ func runSmartAdd(cmd *cobra.Command, args []string) error {
 	// Check if any git-specific flags are set that should bypass AI
 	shouldUseGitDirectly := shouldBypassAIForAdd(cmd)
 
+	// add_ai: never in config keeps add plain git, even with --ai or --all-ai
+	if aiDisabled("add", addAI || addAll) {
+		if len(args) > 0 || shouldUseGitDirectly {
+			return executeGitAddPassthrough(cmd, args)
+		}
+		fmt.Println("Use 'sgit add <files>' for standard git add behavior")
+		return nil
+	}
+
+	// -p with --ai explains and recommends each hunk
+	if addAI && cmd.Flags().Changed("patch") {
+		return runAIPatchAdd(cmd, args)
+	}
+
 	// If specific files are provided or git flags are used, use git behavior
 	if (len(args) > 0 && !addAI) || (shouldUseGitDirectly && !addAI) {
 		return executeGitAddPassthrough(cmd, args)
 	}
 
+	// add_ai: true in config makes a bare 'sgit add' analyze untracked files
+	if len(args) == 0 && useAIFor("add", false, false, false) {
+		addAll = true
+	}
+
 	// Only use AI analysis when --all-ai flag is used or no args and no git flags
 	if !addAll && len(args) == 0 {
 		fmt.Println("Use 'sgit add --all-ai' for AI analysis of untracked files")
@@ -86,6 +136,7 @@ func runSmartAdd(cmd *cobra.Command, args []string) error {
 	// AI-enhanced add logic (only when explicitly requested)
 
 	// Check configuration and setup if needed (unless in force mode)
+	policy := loadAddPolicy()
 	if !addForce {
 		if err := ensureConfiguration(); err != nil {
 			return err
@@ -95,7 +146,7 @@ func runSmartAdd(cmd *cobra.Command, args []string) error {
 	// Get untracked files
 	untrackedFiles, err := getUntrackedFiles()
 	if err != nil {
-		return fmt.Errorf("error getting untracked files: %v", err)
+		return fmt.Errorf("error getting untracked files: %w", err)
 	}
 
 	if len(untrackedFiles) == 0 {
@@ -103,11 +154,33 @@ func runSmartAdd(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	fmt.Printf("Found %d untracked files. Analyzing with Solar LLM...\n", len(untrackedFiles))
+	statusf("Found %d untracked files. Analyzing with Solar LLM...\n", len(untrackedFiles))
 
-	// Analyze each file
+	// Filter out files that must never be staged, then analyze the rest
 	filesToAdd := []string{}
+	var candidates, skippedByAI []string
 	for _, file := range untrackedFiles {
+		// add_policy globs decide known patterns without asking the model
+		rule, pattern := policy.match(file)
+		if rule == addPolicyNever {
+			fmt.Printf("⛔ Skipping %s (add_policy never: %s)\n", file, pattern)
+			continue
+		}
+
+		// Never stage files with detected secrets unless explicitly allowed
+		if !addAllowSecrets {
+			if findings := scanFileForSecrets(file); len(findings) > 0 {
+				fmt.Printf("🚨 Skipping %s: contains potential secrets (%s)\n", file, findings[0])
+				continue
+			}
+		}
+
+		if rule == addPolicyAlways {
+			filesToAdd = append(filesToAdd, file)
+			fmt.Printf("✅ Will add: %s (add_policy always: %s)\n", file, pattern)
+			continue
+		}
+
 		// Skip binary files
 		if isBinaryFile(file) {
 			fmt.Printf("⏭️  Skipping binary file: %s\n", file)
@@ -120,24 +193,48 @@ func runSmartAdd(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
-		if addForce {
+		// Files matching add_policy ask are analyzed even in force mode
+		if addForce && rule != addPolicyAsk {
 			filesToAdd = append(filesToAdd, file)
 			fmt.Printf("✅ Will add: %s (force mode)\n", file)
 			continue
 		}
 
-		// Use AI to analyze the file
-		shouldAdd, reason, err := analyzeFileWithAI(file)
+		candidates = append(candidates, file)
+	}
+
+	if len(candidates) > 0 {
+		if addForce {
+			if err := ensureConfiguration(); err != nil {
+				return err
+			}
+		}
+
+		// Use AI to analyze the files in batches
+		results, err := analyzeFilesWithAI(cmd.Context(), candidates)
 		if err != nil {
-			fmt.Printf("❌ Error analyzing %s: %v\n", file, err)
-			continue
+			return err
 		}
 
-		if shouldAdd {
-			fmt.Printf("✅ Recommended to add: %s\n   Reason: %s\n", file, reason)
-			filesToAdd = append(filesToAdd, file)
-		} else {
-			fmt.Printf("❌ Recommended to skip: %s\n   Reason: %s\n", file, reason)
+		for _, file := range candidates {
+			result := results[file]
+			switch {
+			case result.err != nil:
+				fmt.Printf("❌ Error analyzing %s: %v\n", file, result.err)
+			case result.add:
+				fmt.Printf("✅ Recommended to add: %s\n   Reason: %s\n", file, result.reason)
+				filesToAdd = append(filesToAdd, file)
+			default:
+				fmt.Printf("❌ Recommended to skip: %s\n   Reason: %s\n", file, result.reason)
+				skippedByAI = append(skippedByAI, file)
+			}
+		}
+	}
+
+	// Keep skipped artifacts from showing up again
+	if len(skippedByAI) > 0 && !addDryRun {
+		if err := offerGitignorePatterns(skippedByAI); err != nil {
+			return err
 		}
 	}
 
@@ -158,11 +255,7 @@ func runSmartAdd(cmd *cobra.Command, args []string) error {
 	}
 
 	if !addForce {
-		fmt.Print("\nAdd these files? (y/n): ")
-		reader := bufio.NewReader(os.Stdin)
-		response, _ := reader.ReadString('\n')
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
+		if !confirm("\nAdd these files? (y/n): ") {
 			fmt.Println("Add cancelled")
 			return nil
 		}
@@ -172,6 +265,40 @@ func runSmartAdd(cmd *cobra.Command, args []string) error {
 	return executeGitAdd(filesToAdd)
 }
 
+// Rules of the add_policy config section
+const (
+	addPolicyAlways = "always"
+	addPolicyNever  = "never"
+	addPolicyAsk    = "ask"
+)
+
+// addPolicy holds the add_policy globs from config, e.g.
+//
+//	add_policy:
+//	  never: ["*.env", "dist/**"]
+//	  always: ["go.mod", "go.sum"]
+//	  ask: ["*.json"]
+type addPolicy map[string][]string
+
+func loadAddPolicy() addPolicy {
+	policy := make(addPolicy)
+	for _, rule := range []string{addPolicyNever, addPolicyAlways, addPolicyAsk} {
+		policy[rule] = viper.GetStringSlice("add_policy." + rule)
+	}
+	return policy
+}
+
+// match returns the rule that applies to a file and the pattern that matched, or ""
+// when no pattern matches. never wins over always, and always over ask.
+func (p addPolicy) match(file string) (string, string) {
+	for _, rule := range []string{addPolicyNever, addPolicyAlways, addPolicyAsk} {
+		if pattern, ok := glob.MatchAny(p[rule], filepath.ToSlash(file)); ok {
+			return rule, pattern
+		}
+	}
+	return "", ""
+}
+
 func shouldBypassAIForAdd(cmd *cobra.Command) bool {
 	// Check for flags that indicate user wants standard git behavior
 	flags := []string{
@@ -197,7 +324,7 @@ func executeGitAddPassthrough(cobraCmd *cobra.Command, args []string) error {
 	// Add all the flags that were set (excluding our custom AI flags)
 	cobraCmd.Flags().Visit(func(flag *pflag.Flag) {
 		flagName := flag.Name
-		if strings.HasSuffix(flagName, "-ai") || flagName == "ai" {
+		if isGlobalFlag(flag) || strings.HasSuffix(flagName, "-ai") || flagName == "ai" || addOnlyFlags[flagName] {
 			return // Skip our custom AI flags
 		}
 
@@ -225,60 +352,11 @@ func executeGitAddPassthrough(cobraCmd *cobra.Command, args []string) error {
 }
 
 func getUntrackedFiles() ([]string, error) {
-	cmd := exec.Command("git", "ls-files", "--others", "--exclude-standard")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-
-	files := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(files) == 1 && files[0] == "" {
-		return []string{}, nil
-	}
-
-	return files, nil
+	return gitRepo.UntrackedFiles(context.Background())
 }
 
 func isBinaryFile(filename string) bool {
-	// Check file extension for common binary types
-	ext := strings.ToLower(filepath.Ext(filename))
-	binaryExts := []string{
-		".exe", ".dll", ".so", ".dylib", ".a", ".o", ".obj",
-		".jpg", ".jpeg", ".png", ".gif", ".bmp", ".ico", ".tiff", ".svg",
-		".mp3", ".mp4", ".avi", ".mov", ".mkv", ".flv", ".wav",
-		".zip", ".tar", ".gz", ".bz2", ".xz", ".7z", ".rar",
-		".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx",
-		".bin", ".dat", ".db", ".sqlite", ".sqlite3",
-	}
-
-	for _, bext := range binaryExts {
-		if ext == bext {
-			return true
-		}
-	}
-
-	// Check if file contains null bytes (binary indicator)
-	file, err := os.Open(filename)
-	if err != nil {
-		return false
-	}
-	defer file.Close()
-
-	// Read first 512 bytes to check for binary content
-	buffer := make([]byte, 512)
-	n, err := file.Read(buffer)
-	if err != nil && n == 0 {
-		return false
-	}
-
-	// Check for null bytes
-	for i := 0; i < n; i++ {
-		if buffer[i] == 0 {
-			return true
-		}
-	}
-
-	return false
+	return git.IsBinaryFile(filename)
 }
 
 func isLargeFile(filename string) bool {
@@ -290,70 +368,122 @@ func isLargeFile(filename string) bool {
 	return info.Size() > 1024*1024
 }
 
-func analyzeFileWithAI(filename string) (bool, string, error) {
-	// Read file content
-	content, err := os.ReadFile(filename)
+// fileAnalysis is the AI recommendation for one untracked file
+type fileAnalysis struct {
+	add    bool
+	reason string
+	err    error
+}
+
+// analyzeFilesWithAI asks the model which files belong in version control. Small
+// files are batched into shared requests within the word budget, and batches are
+// analyzed concurrently by a pool of workers.
+func analyzeFilesWithAI(ctx context.Context, files []string) (map[string]fileAnalysis, error) {
+	client, err := newSolarClient()
 	if err != nil {
-		return false, "", fmt.Errorf("error reading file: %v", err)
+		return nil, err
 	}
 
-	// Use word-based limiting for consistency with other commands
-	contentStr := string(content)
-	// For file analysis, use a smaller limit (5000 words ≈ 7500 tokens)
-	words := len(strings.Fields(contentStr))
-	if words > 5000 {
-		words := strings.Fields(contentStr)
-		contentStr = strings.Join(words[:5000], " ") + "\n... [truncated for size]"
+	results := make(map[string]fileAnalysis, len(files))
+	var samples []solar.FileSample
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			results[file] = fileAnalysis{err: fmt.Errorf("error reading file: %w", err)}
+			continue
+		}
+		samples = append(samples, solar.FileSample{Path: file, Content: string(content)})
 	}
 
-	apiKey := viper.GetString("upstage_api_key")
-	modelName := viper.GetString("upstage_model_name")
-
-	client := solar.NewClient(apiKey, modelName, getEffectiveLanguage())
-
-	prompt := fmt.Sprintf(`You are a helpful assistant that analyzes files in software projects to determine if they should be added to git version control.
-
-Analyze the following file and determine if it should be added to git:
-
-File: %s
-Content:
-%s
-
-Consider these factors:
-1. Is this a source code file, configuration, or documentation that belongs in version control?
-2. Is this a temporary file, log file, or build artifact that should be ignored?
-3. Does this file contain sensitive information (passwords, keys, tokens)?
-4. Is this a generated file that can be recreated from source?
+	batches := batchFileSamples(samples, addBatchSize, addBatchWords)
+	jobs := addJobs
+	if jobs < 1 {
+		jobs = 1
+	}
 
-Respond with only:
-- "YES: [brief reason]" if the file should be added
-- "NO: [brief reason]" if the file should not be added
+	bar := progress.NewBar("Analyzing files", len(samples))
+	defer bar.Finish()
+
+	var mu sync.Mutex
+	queue := make(chan []solar.FileSample)
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range queue {
+				decisions, err := client.AnalyzeFilesForStaging(ctx, batch)
+
+				mu.Lock()
+				for _, sample := range batch {
+					decision, ok := decisions[sample.Path]
+					switch {
+					case err != nil:
+						results[sample.Path] = fileAnalysis{err: err}
+					case !ok:
+						// Be conservative when the model skipped a file
+						results[sample.Path] = fileAnalysis{reason: "AI response unclear, skipping for safety"}
+					default:
+						results[sample.Path] = fileAnalysis{add: decision.Add, reason: decision.Reason}
+					}
+				}
+				mu.Unlock()
+				bar.Add(len(batch))
+			}
+		}()
+	}
 
-Keep the reason under 50 characters.`, filename, contentStr)
+	for _, batch := range batches {
+		queue <- batch
+	}
+	close(queue)
+	wg.Wait()
 
-	response, err := client.GenerateResponse(prompt)
-	if err != nil {
-		return false, "", err
+	for _, result := range results {
+		if isDryRun(result.err) {
+			return nil, result.err
+		}
 	}
+	return results, nil
+}
 
-	response = strings.TrimSpace(response)
+// batchFileSamples groups files into batches of at most maxFiles files and roughly
+// maxWords words; a file larger than maxWords gets a batch of its own
+func batchFileSamples(samples []solar.FileSample, maxFiles, maxWords int) [][]solar.FileSample {
+	if maxFiles < 1 {
+		maxFiles = 1
+	}
 
-	if strings.HasPrefix(strings.ToUpper(response), "YES:") {
-		reason := strings.TrimSpace(strings.TrimPrefix(response, "YES:"))
-		if strings.HasPrefix(strings.ToUpper(reason), "YES:") {
-			reason = strings.TrimSpace(strings.TrimPrefix(reason, "YES:"))
+	counter := solar.NewTokenCounter()
+	var batches [][]solar.FileSample
+	var current []solar.FileSample
+	currentWords := 0
+	for _, sample := range samples {
+		words := counter.CountWords(sample.Content)
+		if words > solar.MaxStagingFileWords {
+			words = solar.MaxStagingFileWords
 		}
-		return true, reason, nil
-	} else if strings.HasPrefix(strings.ToUpper(response), "NO:") {
-		reason := strings.TrimSpace(strings.TrimPrefix(response, "NO:"))
-		if strings.HasPrefix(strings.ToUpper(reason), "NO:") {
-			reason = strings.TrimSpace(strings.TrimPrefix(reason, "NO:"))
+
+		if len(current) > 0 && (len(current) >= maxFiles || currentWords+words > maxWords) {
+			batches = append(batches, current)
+			current, currentWords = nil, 0
 		}
-		return false, reason, nil
+		current = append(current, sample)
+		currentWords += words
 	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
 
-	// Fallback: if response doesn't match expected format, be conservative
-	return false, "AI response unclear, skipping for safety", nil
+// scanFileForSecrets runs the regex secret heuristics over a file's content
+func scanFileForSecrets(filename string) []secrets.Finding {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+	return secrets.ScanText(filename, string(content))
 }
 
 func executeGitAdd(files []string) error {
@@ -367,7 +497,7 @@ func executeGitAdd(files []string) error {
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("error adding files: %v", err)
+		return fmt.Errorf("error adding files: %w", err)
 	}
 
 	fmt.Printf("Successfully added %d files\n", len(files))
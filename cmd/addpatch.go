@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/secrets"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+)
+
+// hunksPerRequest bounds how many hunks are explained in one AI request
+const hunksPerRequest = 30
+
+// patchFile is one file of an unstaged diff, split into its header and hunks
+type patchFile struct {
+	path   string
+	header string
+	hunks  []string
+}
+
+// patchHunk is a hunk with the AI's advice and the user's choice
+type patchHunk struct {
+	file     *patchFile
+	text     string
+	advice   solar.HunkDecision
+	advised  bool
+	findings []secrets.Finding
+	stage    bool
+}
+
+// recommended reports whether the hunk should be staged when following the AI plan.
+// Hunks with potential secrets are never part of the plan.
+func (h *patchHunk) recommended() bool {
+	return h.advised && h.advice.Stage && len(h.findings) == 0
+}
+
+// runAIPatchAdd is 'sgit add -p --ai': each unstaged hunk is shown with a one-line AI
+// explanation and a stage/skip recommendation, and the chosen hunks are staged
+func runAIPatchAdd(cmd *cobra.Command, args []string) error {
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	diff, err := runGitOutput(append([]string{"diff", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/", "--"}, args...)...)
+	if err != nil {
+		return fmt.Errorf("error getting unstaged changes: %w", err)
+	}
+
+	var hunks []*patchHunk
+	var samples []solar.HunkSample
+	for _, file := range parsePatchFiles(diff) {
+		if len(file.hunks) == 0 {
+			fmt.Printf("⏭️  Skipping %s: binary or mode-only change (use 'sgit add %s')\n", file.path, file.path)
+			continue
+		}
+		for _, text := range file.hunks {
+			hunks = append(hunks, &patchHunk{file: file, text: text, findings: secrets.ScanDiff(file.header + text)})
+			samples = append(samples, solar.HunkSample{Path: file.path, Hunk: text})
+		}
+	}
+	if len(hunks) == 0 {
+		fmt.Println("No unstaged changes")
+		return nil
+	}
+
+	statusf("Found %d unstaged hunks. Analyzing with Solar LLM...\n", len(hunks))
+	for start := 0; start < len(samples); start += hunksPerRequest {
+		end := min(start+hunksPerRequest, len(samples))
+		decisions, err := client.AnalyzeHunksForStaging(cmd.Context(), samples[start:end])
+		if err != nil {
+			return fmt.Errorf("error analyzing hunks: %w", err)
+		}
+		for i, decision := range decisions {
+			hunks[start+i].advice = decision
+			hunks[start+i].advised = true
+		}
+	}
+
+	if addDryRun {
+		for i, hunk := range hunks {
+			printPatchHunk(hunk, i, len(hunks))
+		}
+		printPatchPlan(hunks, true)
+		fmt.Println("\n[DRY RUN] No hunks were actually staged")
+		return nil
+	}
+
+	if err := choosePatchHunks(hunks); err != nil {
+		return err
+	}
+
+	staged := printPatchPlan(hunks, false)
+	if staged == 0 {
+		fmt.Println("No hunks staged")
+		return nil
+	}
+	if err := applyToIndex(buildPatch(hunks)); err != nil {
+		return fmt.Errorf("error staging hunks: %w", err)
+	}
+	fmt.Printf("✅ Staged %d of %d hunk(s)\n", staged, len(hunks))
+	return nil
+}
+
+// choosePatchHunks walks through the hunks asking what to do with each, like git add -p
+func choosePatchHunks(hunks []*patchHunk) error {
+	// --yes answers every hunk the way the AI recommends
+	followAI := assumeYes
+	if followAI {
+		statusln("Following the AI plan for every hunk (--yes)")
+	}
+	skipFile := (*patchFile)(nil)
+	for i := 0; i < len(hunks); i++ {
+		hunk := hunks[i]
+		if followAI {
+			hunk.stage = hunk.recommended()
+			continue
+		}
+		if hunk.file == skipFile {
+			continue
+		}
+
+		printPatchHunk(hunk, i, len(hunks))
+		answer := strings.ToLower(ask("Stage this hunk [y,n,Enter=AI,a,d,q,?]? "))
+		switch answer {
+		case "":
+			hunk.stage = hunk.recommended()
+		case "y", "yes":
+			if len(hunk.findings) > 0 && !addAllowSecrets {
+				fmt.Println("🚨 Not staging a hunk with potential secrets (use --allow-secrets)")
+				continue
+			}
+			hunk.stage = true
+		case "n", "no":
+			hunk.stage = false
+		case "a":
+			followAI = true
+			hunk.stage = hunk.recommended()
+		case "d":
+			skipFile = hunk.file
+		case "q":
+			return nil
+		default:
+			fmt.Println(`y - stage this hunk
+n - do not stage this hunk
+Enter - do what the AI recommends for this hunk
+a - follow the AI plan for this hunk and all remaining hunks
+d - do not stage this hunk or any remaining hunk in the file
+q - quit; stage the hunks chosen so far`)
+			i--
+		}
+	}
+	return nil
+}
+
+func printPatchHunk(hunk *patchHunk, index, total int) {
+	fmt.Printf("\n📄 %s (hunk %d/%d)\n", hunk.file.path, index+1, total)
+	fmt.Print(hunk.text)
+	if !strings.HasSuffix(hunk.text, "\n") {
+		fmt.Println()
+	}
+	switch {
+	case !hunk.advised:
+		fmt.Println("🤖 No recommendation from the AI (skip)")
+	case hunk.advice.Stage:
+		fmt.Printf("🤖 Stage: %s\n", hunk.advice.Explanation)
+	default:
+		fmt.Printf("🤖 Skip: %s\n", hunk.advice.Explanation)
+	}
+	for _, finding := range hunk.findings {
+		fmt.Printf("🚨 Potential secret, skipped by the AI plan: %s\n", finding)
+	}
+}
+
+// printPatchPlan lists the hunks to stage per file and returns their number. With
+// recommended set it shows the AI plan instead of the user's choices.
+func printPatchPlan(hunks []*patchHunk, recommended bool) int {
+	staged := 0
+	var lines []string
+	for i, hunk := range hunks {
+		stage := hunk.stage
+		if recommended {
+			stage = hunk.recommended()
+		}
+		mark := "  skip "
+		if stage {
+			mark = "  stage"
+			staged++
+		}
+		lines = append(lines, fmt.Sprintf("%s %s (hunk %d)", mark, hunk.file.path, i+1))
+	}
+	fmt.Printf("\nHunks to stage: %d of %d\n", staged, len(hunks))
+	fmt.Println(strings.Join(lines, "\n"))
+	return staged
+}
+
+// buildPatch joins the chosen hunks under their file headers. Hunk line numbers refer
+// to the index, so skipped hunks don't shift the ones that are kept.
+func buildPatch(hunks []*patchHunk) string {
+	var b strings.Builder
+	var current *patchFile
+	for _, hunk := range hunks {
+		if !hunk.stage {
+			continue
+		}
+		if hunk.file != current {
+			current = hunk.file
+			b.WriteString(current.header)
+		}
+		b.WriteString(hunk.text)
+	}
+	return b.String()
+}
+
+// applyToIndex stages a patch with git apply --cached
+func applyToIndex(patch string) error {
+	gitCmd := exec.Command("git", "apply", "--cached", "-")
+	gitCmd.Stdin = strings.NewReader(patch)
+	var stderr bytes.Buffer
+	gitCmd.Stderr = &stderr
+	if err := gitCmd.Run(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// parsePatchFiles splits the output of git diff into files and hunks
+func parsePatchFiles(diff string) []*patchFile {
+	var files []*patchFile
+	var current *patchFile
+	var header, hunk strings.Builder
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if hunk.Len() > 0 {
+			current.hunks = append(current.hunks, hunk.String())
+			hunk.Reset()
+		}
+		if current.header == "" {
+			current.header = header.String()
+		}
+	}
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			header.Reset()
+			current = &patchFile{}
+			files = append(files, current)
+			// The "+++ b/" line below names the file more reliably when there is one
+			if _, b, found := strings.Cut(strings.TrimRight(line, "\n"), " b/"); found {
+				current.path = b
+			}
+			header.WriteString(line)
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			if hunk.Len() > 0 {
+				current.hunks = append(current.hunks, hunk.String())
+				hunk.Reset()
+			} else if current.header == "" {
+				current.header = header.String()
+			}
+			hunk.WriteString(line)
+		case hunk.Len() > 0 || len(current.hunks) > 0:
+			hunk.WriteString(line)
+		default:
+			if path, found := strings.CutPrefix(strings.TrimRight(line, "\n"), "+++ b/"); found {
+				current.path = path
+			}
+			header.WriteString(line)
+		}
+	}
+	flush()
+	return files
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// emptyTreeHash is git's empty tree, the base when amending a root commit
+const emptyTreeHash = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// amendCmd amends the last commit with a message regenerated for the combined change
+var amendCmd = &cobra.Command{
+	Use:   "amend [commit options]",
+	Short: "Amend the last commit, regenerating its message for the combined change",
+	Long: `Amend the last commit with the staged changes and an AI message that covers
+both the original commit and what was added. Short for 'sgit commit --amend' and
+takes the same options.
+
+--no-edit keeps the current message, like git.
+
+Examples:
+  sgit add forgotten_file.go && sgit amend
+  sgit amend -a --skip-editor
+  sgit amend --hint "also handles empty input"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runAmend(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(amendCmd)
+	// The commit flags are added in commit.go's init, once they are defined
+}
+
+func runAmend(cmd *cobra.Command, args []string) error {
+	if err := cmd.Flags().Set("amend", "true"); err != nil {
+		return err
+	}
+	return runCommit(cmd, args)
+}
+
+// amendBase returns what the commit being amended is compared with: its first
+// parent, or the empty tree for a root commit
+func amendBase() (string, error) {
+	if _, err := runGitOutput("rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return "", fmt.Errorf("there is no commit to amend yet")
+	}
+	if parent, err := runGitOutput("rev-parse", "--verify", "--quiet", "HEAD^"); err == nil {
+		return strings.TrimSpace(parent), nil
+	}
+	return emptyTreeHash, nil
+}
+
+// getAmendDiff returns the diff the amended commit will have: the last commit's
+// changes plus everything staged since
+func getAmendDiff(base string) (string, error) {
+	return runGitOutput("diff", "--cached", "--no-color", base)
+}
+
+// getAmendFileList lists the files the amended commit changes, like getEnhancedFileList
+func getAmendFileList(base string) (string, error) {
+	output, err := runGitOutput("diff", "--cached", "--name-status", base)
+	if err != nil {
+		return "", err
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 {
+			files = append(files, fmt.Sprintf("- %s %s", fields[0], strings.Join(fields[1:], " ")))
+		}
+	}
+	if len(files) == 0 {
+		return "No files changed", nil
+	}
+	return strings.Join(files, "\n"), nil
+}
+
+// getAmendRecentCommits returns the commits before the one being amended, so the
+// model doesn't treat the current message as an earlier commit
+func getAmendRecentCommits(base string, count int) (string, error) {
+	if base == emptyTreeHash {
+		return "", nil
+	}
+	output, err := runGitOutput("log", fmt.Sprintf("-%d", count), "--oneline", "--no-merges", base)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
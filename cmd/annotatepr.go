@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/actions"
+	"github.com/hunkim/sgit/pkg/github"
+	"github.com/hunkim/sgit/pkg/i18n"
+	"github.com/hunkim/sgit/pkg/progress"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	annotatePRPost              bool
+	annotatePRRemote            string
+	annotatePRGitHubAnnotations bool
+)
+
+// annotatePRCmd reviews a pull request and posts the review with inline comments
+var annotatePRCmd = &cobra.Command{
+	Use:   "annotate-pr [<number>]",
+	Short: "Review a GitHub pull request with AI and comment on specific lines",
+	Long: `Review a GitHub pull request with Solar LLM: an overall verdict (approve, request
+changes or comment), a summary, and comments on the specific lines of the diff that
+need attention. Without a number, the open pull request of the current branch is
+reviewed, or in a pull_request workflow on GitHub Actions, the one it runs for.
+
+The review is only previewed locally. With --post, it is posted on the pull request,
+after confirmation, as a single GitHub review with its comments inline. Posting needs
+a GitHub token (GITHUB_TOKEN, GH_TOKEN or github_token in config). Leftover debug
+code on added lines, such as print statements, FIXMEs and focused tests, gets a
+comment too, whether or not the model noticed it.
+
+--github-annotations also reports the line comments as GitHub Actions annotations,
+warnings when the verdict is to request changes and notices otherwise, writes the
+review to the job summary, and exits non-zero when it requests changes, so the
+review can run as a pull request check.
+
+The pull request is looked up on the base repository: the remote set with --remote,
+or as for 'sgit pr', pr_remote in config, upstream, or origin.
+
+Examples:
+  sgit annotate-pr
+  sgit annotate-pr 42
+  sgit annotate-pr 42 --post
+  sgit annotate-pr --remote upstream --post
+  sgit annotate-pr --github-annotations`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runAnnotatePR(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(annotatePRCmd)
+
+	annotatePRCmd.Flags().BoolVar(&annotatePRPost, "post", false, "post the review on the pull request")
+	annotatePRCmd.Flags().StringVar(&annotatePRRemote, "remote", "", "remote of the repository the pull request is on (default: the base remote)")
+	annotatePRCmd.Flags().BoolVar(&annotatePRGitHubAnnotations, "github-annotations", false, "report the review as GitHub Actions annotations and job summary")
+}
+
+func runAnnotatePR(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	remote := annotatePRRemote
+	if remote == "" {
+		remote = baseRemote()
+	}
+	owner, repo, ok := githubRemote(remote)
+	if !ok {
+		return fmt.Errorf("remote '%s' is not a GitHub repository (set github_api_url for GitHub Enterprise)", remote)
+	}
+	token := getGitHubToken()
+	if annotatePRPost && token == "" {
+		return fmt.Errorf("no GitHub token configured to post the review (set GITHUB_TOKEN or github_token in config)")
+	}
+	gh := github.NewClient(token, viper.GetString("github_api_url"))
+	ctx := cmd.Context()
+
+	number := 0
+	if len(args) > 0 {
+		n, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid pull request number '%s'", args[0])
+		}
+		number = n
+	} else if n := actions.PullRequestNumber(); n > 0 {
+		number = n
+	} else {
+		n, err := currentBranchPullRequest(cmd, gh, owner, repo)
+		if err != nil {
+			return err
+		}
+		number = n
+	}
+
+	pr, err := gh.GetPullRequest(ctx, owner, repo, number)
+	if err != nil {
+		return fmt.Errorf("error fetching pull request #%d: %w", number, err)
+	}
+	diff, err := gh.GetPullRequestDiff(ctx, owner, repo, number)
+	if err != nil {
+		return fmt.Errorf("error fetching the diff of pull request #%d: %w", number, err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		fmt.Printf("Pull request #%d has no changes to review\n", number)
+		return nil
+	}
+	if pr.State != "" && pr.State != "open" {
+		statusf("⚠️  Pull request #%d is %s\n", number, pr.State)
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	fileList := pullRequestFileList(diff)
+	scope := fmt.Sprintf("#%d %s (%s into %s)", pr.Number, pr.Title, pr.Head.Ref, pr.Base.Ref)
+	if body := strings.TrimSpace(pr.Body); body != "" {
+		scope += "\n\n" + body
+	}
+	statusf("🔍 Reviewing pull request #%d: %s\n", pr.Number, pr.Title)
+	printContentStats("Pull request analysis", diff, scope, fileList)
+	if diff, err = checkDiffSize(diff); err != nil {
+		return err
+	}
+
+	reviewing := progress.Begin(i18n.T("Reviewing the pull request"))
+	review, err := client.ReviewPullRequest(ctx, diff, scope, fileList)
+	reviewing.End()
+	if err != nil {
+		return fmt.Errorf("error reviewing pull request: %w", err)
+	}
+	// The model may overlook debug code left in; a rule finds it every time
+	addLeftoverComments(&review, diff)
+	printPullRequestReview(review)
+	if annotatePRGitHubAnnotations {
+		writeGitHubResults(reviewAnnotations(review), reviewSummary(pr, review))
+	}
+
+	if !annotatePRPost {
+		if !annotatePRGitHubAnnotations {
+			fmt.Printf("💡 Use 'sgit annotate-pr %d --post' to post this review on GitHub\n", number)
+		}
+		return reviewCheckError(review)
+	}
+	if !confirm(fmt.Sprintf("Post this review on pull request #%d? (y/n): ", number)) {
+		fmt.Println("Review not posted")
+		return reviewCheckError(review)
+	}
+
+	comments := make([]github.ReviewComment, len(review.Comments))
+	for i, comment := range review.Comments {
+		comments[i] = github.ReviewComment{Path: comment.Path, Line: comment.Line, Side: "RIGHT", Body: comment.Body}
+	}
+	posted, err := gh.CreateReview(ctx, owner, repo, number, github.NewReview{
+		CommitID: pr.Head.SHA,
+		Body:     review.Summary,
+		Event:    review.Verdict,
+		Comments: comments,
+	})
+	if err != nil {
+		// GitHub doesn't let authors approve or request changes on their own pull requests
+		if review.Verdict != solar.VerdictComment && strings.Contains(err.Error(), "status 422") {
+			return fmt.Errorf("error posting review (GitHub refuses approvals and change requests on your own pull request): %w", err)
+		}
+		return fmt.Errorf("error posting review: %w", err)
+	}
+
+	fmt.Printf("✅ Posted review with %d comment(s) on pull request #%d: %s\n", len(comments), number, posted.HTMLURL)
+	return reviewCheckError(review)
+}
+
+// reviewCheckError fails the check with --github-annotations when the review
+// requests changes
+func reviewCheckError(review solar.PullRequestReview) error {
+	if annotatePRGitHubAnnotations && review.Verdict == solar.VerdictRequestChanges {
+		return fmt.Errorf("the review requests changes")
+	}
+	return nil
+}
+
+// reviewAnnotations reports the line comments of a review as annotations, warnings
+// when it requests changes
+func reviewAnnotations(review solar.PullRequestReview) []actions.Annotation {
+	level := actions.LevelNotice
+	if review.Verdict == solar.VerdictRequestChanges {
+		level = actions.LevelWarning
+	}
+	annotations := make([]actions.Annotation, len(review.Comments))
+	for i, comment := range review.Comments {
+		annotations[i] = actions.Annotation{
+			Level:   level,
+			File:    comment.Path,
+			Line:    comment.Line,
+			Title:   "sgit review",
+			Message: comment.Body,
+		}
+	}
+	return annotations
+}
+
+// reviewSummary is the job summary of a review: the verdict, the summary and the
+// line comments
+func reviewSummary(pr *github.PullRequest, review solar.PullRequestReview) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### sgit review of #%d %s\n\n**%s**\n\n", pr.Number, pr.Title, verdictHeading(review.Verdict))
+	if review.Summary != "" {
+		fmt.Fprintf(&b, "%s\n\n", review.Summary)
+	}
+	if len(review.Comments) == 0 {
+		return b.String()
+	}
+	b.WriteString("| File | Line | Comment |\n|---|---|---|\n")
+	for _, comment := range review.Comments {
+		fmt.Fprintf(&b, "| %s | %d | %s |\n", actions.TableCell(comment.Path), comment.Line, actions.TableCell(comment.Body))
+	}
+	return b.String()
+}
+
+// currentBranchPullRequest finds the open pull request of the current branch on
+// owner/repo, from the repository the branch is pushed to
+func currentBranchPullRequest(cmd *cobra.Command, gh *github.Client, owner, repo string) (int, error) {
+	branch, err := getCurrentBranch()
+	if err != nil || branch == "" {
+		return 0, fmt.Errorf("not on a branch, pass the pull request number: sgit annotate-pr <number>")
+	}
+	headOwner := owner
+	if pushOwner, _, ok := githubRemote(pushRemote(branch)); ok {
+		headOwner = pushOwner
+	}
+
+	prs, err := gh.ListPullRequests(cmd.Context(), owner, repo, headOwner+":"+branch)
+	if err != nil {
+		return 0, fmt.Errorf("error looking up the pull request of '%s': %w", branch, err)
+	}
+	if len(prs) == 0 {
+		return 0, fmt.Errorf("no open pull request for '%s' on %s/%s, pass its number: sgit annotate-pr <number>", branch, owner, repo)
+	}
+	return prs[0].Number, nil
+}
+
+// pullRequestFileList lists the files changed in a pull request diff with their
+// added and removed lines
+func pullRequestFileList(diff string) string {
+	type fileStat struct {
+		path           string
+		added, removed int
+	}
+	var files []*fileStat
+	var current *fileStat
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			// "diff --git a/<path> b/<path>"
+			path := line[strings.LastIndex(line, " b/")+len(" b/"):]
+			current = &fileStat{path: path}
+			files = append(files, current)
+			inHunk = false
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case current == nil || !inHunk:
+		case strings.HasPrefix(line, "+"):
+			current.added++
+		case strings.HasPrefix(line, "-"):
+			current.removed++
+		}
+	}
+
+	lines := make([]string, len(files))
+	for i, file := range files {
+		lines[i] = fmt.Sprintf("- %s (+%d -%d)", file.path, file.added, file.removed)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// printPullRequestReview shows a review before it is posted
+func printPullRequestReview(review solar.PullRequestReview) {
+	fmt.Printf("\n%s\n\n", verdictHeading(review.Verdict))
+	if review.Summary != "" {
+		fmt.Println(review.Summary)
+		fmt.Println()
+	}
+	if len(review.Comments) == 0 {
+		fmt.Println("No inline comments")
+		fmt.Println()
+		return
+	}
+	fmt.Printf("💬 %d inline comment(s):\n", len(review.Comments))
+	for _, comment := range review.Comments {
+		fmt.Printf("\n  %s:%d\n", comment.Path, comment.Line)
+		fmt.Printf("    %s\n", comment.Body)
+	}
+	fmt.Println()
+}
+
+// verdictHeading is the heading a review verdict is shown under
+func verdictHeading(verdict string) string {
+	switch verdict {
+	case solar.VerdictApprove:
+		return "✅ Verdict: approve"
+	case solar.VerdictRequestChanges:
+		return "❌ Verdict: request changes"
+	default:
+		return "💬 Verdict: comment"
+	}
+}
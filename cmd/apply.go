@@ -0,0 +1,539 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// patchRepairAttempts is how many patches the model writes before giving up, each
+	// told why the one before didn't apply
+	patchRepairAttempts = 2
+	// rejectContextLines is how many lines around a rejected hunk the model sees of a
+	// large file
+	rejectContextLines = 40
+	// wholeFileLines is the size up to which the model sees all of a file with
+	// rejected hunks, since the code they belong to may have moved anywhere in it
+	wholeFileLines = 400
+)
+
+// applyPassthroughFlags are git apply and git am options that apply nothing to repair,
+// or apply in a way --reject can't be combined with; they go straight to git
+var applyPassthroughFlags = map[string]bool{
+	"--check":   true,
+	"--stat":    true,
+	"--numstat": true,
+	"--summary": true,
+	"--cached":  true,
+	"--reject":  true,
+	"-3":        true,
+	"--3way":    true,
+	// git am
+	"--abort":              true,
+	"--skip":               true,
+	"--quit":               true,
+	"--show-current-patch": true,
+}
+
+// applyValueFlags are git apply and git am options that take a separate value argument
+var applyValueFlags = map[string]bool{
+	"-p":             true,
+	"-C":             true,
+	"--directory":    true,
+	"--exclude":      true,
+	"--include":      true,
+	"--whitespace":   true,
+	"--patch-format": true,
+	"--resolvemsg":   true,
+}
+
+// rejectedPatch matches git apply --reject's report of a file with rejected hunks
+var rejectedPatch = regexp.MustCompile(`(?m)^Applying patch (.+) with \d+ rejects?\.\.\.$`)
+
+// hunkHeader matches the old line range of a hunk header, e.g. "@@ -12,7 +12,9 @@"
+var hunkHeader = regexp.MustCompile(`(?m)^@@ -(\d+)(?:,(\d+))?`)
+
+// applyCmd wraps git apply and git am, adapting hunks that no longer apply with AI
+var applyCmd = &cobra.Command{
+	Use:   "apply [--am] [--no-ai] [git apply or git am options] [<patch>...]",
+	Short: "Apply a patch, with AI repair of the hunks that no longer apply",
+	Long: `Passthrough to git apply, or to git am with --am. When the patch doesn't apply
+because the code changed since it was written, the hunks that still apply are
+applied and the rejected ones (written to .rej files by git apply --reject) are
+given to Solar LLM with the current code around them. It writes an adapted patch,
+which is checked with git apply --check and shown for review before it is applied:
+[y]es applies it, [e]dit opens it in your editor first, [n]o saves it as
+sgit-repaired.patch at the top of the repository, next to the .rej files, for you
+to finish.
+
+With --am, each patch of a mailbox that fails is repaired the same way, its files
+are staged and git am continues with the next one. If you fix a patch yourself, run
+sgit apply --am --continue to get the same help for the rest.
+
+--no-ai (or apply_ai: false in config) is plain git apply / git am. --check,
+--stat, --cached, --3way and the other options that --reject doesn't combine with
+are passed straight to git, like git am's --abort and --skip.
+
+Examples:
+  sgit apply fix.patch
+  sgit apply -p2 --index upstream.diff
+  curl -sL https://example.com/pr.diff | sgit apply
+  sgit apply --am 0001-*.patch
+  sgit apply --am --continue`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runApply(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+	DisableFlagParsing: true,
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	// Flag parsing is disabled so every git apply and git am option passes through; pick
+	// out ours
+	useAm, noAI := false, false
+	var gitArgs []string
+	for _, arg := range extractGlobalFlags(args) {
+		switch arg {
+		case "--am":
+			useAm = true
+		case "--no-ai":
+			noAI = true
+		case "-h", "--help":
+			return cmd.Help()
+		default:
+			gitArgs = append(gitArgs, arg)
+		}
+	}
+	gitCommand := "apply"
+	if useAm {
+		gitCommand = "am"
+	}
+
+	var options, patches []string
+	continuing, passthrough := false, false
+	for i := 0; i < len(gitArgs); i++ {
+		arg := gitArgs[i]
+		name, _, _ := strings.Cut(arg, "=")
+		switch {
+		case useAm && arg == "--continue":
+			continuing = true
+		case applyPassthroughFlags[name]:
+			passthrough = true
+		case arg == "-" || !strings.HasPrefix(arg, "-"):
+			patches = append(patches, arg)
+		default:
+			options = append(options, arg)
+			if applyValueFlags[arg] && i+1 < len(gitArgs) {
+				i++
+				options = append(options, gitArgs[i])
+			}
+		}
+	}
+
+	if passthrough || !useAIFor("apply", true, false, noAI) {
+		executeGitCommand(append([]string{gitCommand}, gitArgs...))
+		return nil
+	}
+
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+	root, err := gitRepo.Root(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	// git runs at the top of the repository, where .rej files are written relative to,
+	// so the patch paths are made absolute. A patch read from stdin is saved, since it
+	// may be applied twice.
+	if !continuing && (len(patches) == 0 || slices.Contains(patches, "-")) {
+		saved, err := saveStdinPatch()
+		if err != nil {
+			return err
+		}
+		defer os.Remove(saved)
+		patches = slices.DeleteFunc(patches, func(patch string) bool { return patch == "-" })
+		patches = append(patches, saved)
+	}
+	for i, patch := range patches {
+		if absolute, err := filepath.Abs(patch); err == nil {
+			patches[i] = absolute
+		}
+	}
+
+	if useAm {
+		return runAmWithRepair(cmd.Context(), root, options, patches, continuing)
+	}
+
+	output, err := runGitIn(root, append(append([]string{"apply"}, options...), patches...)...)
+	fmt.Print(output)
+	if err == nil {
+		fmt.Println("✅ Patch applied")
+		return nil
+	}
+	applied, err := applyWithRepair(cmd.Context(), root, options, patches)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return fmt.Errorf("the patch is only partly applied: the rejected hunks are in the .rej files")
+	}
+	fmt.Println("✅ Patch applied, with the rejected hunks adapted; review the result with 'git diff'")
+	return nil
+}
+
+// runAmWithRepair runs git am, repairing each patch that fails to apply and continuing
+// with the next, until every patch is applied or one is left for the user
+func runAmWithRepair(ctx context.Context, root string, options, patches []string, continuing bool) error {
+	step := append(append([]string{"am"}, options...), patches...)
+	if continuing {
+		step = []string{"am", "--continue"}
+	}
+	for {
+		output, err := runGitIn(root, step...)
+		fmt.Print(output)
+		if err == nil {
+			fmt.Println("✅ All patches applied")
+			return nil
+		}
+		if !isAmInProgress() {
+			return fmt.Errorf("git am failed: %v", err)
+		}
+
+		current, err := runGitOutput("am", "--show-current-patch=diff")
+		if err != nil || strings.TrimSpace(current) == "" {
+			return fmt.Errorf("can't read the patch git am stopped at: %v", err)
+		}
+		patchFile, err := writeTempPatch("sgit-am-*.patch", current)
+		if err != nil {
+			return err
+		}
+		applied, err := applyWithRepair(ctx, root, nil, []string{patchFile})
+		os.Remove(patchFile)
+		if err != nil || !applied {
+			fmt.Println("\n💡 Finish the patch by hand (the rejected hunks are in the .rej files), stage the files,")
+			fmt.Println("   then run 'sgit apply --am --continue', or 'git am --skip' / 'git am --abort'")
+			if err == nil {
+				err = fmt.Errorf("git am stopped at a patch that doesn't apply")
+			}
+			return err
+		}
+
+		// git am commits what is staged
+		files := patchFiles(current)
+		if len(files) > 0 {
+			if output, err := runGitIn(root, append([]string{"add", "-A", "--"}, files...)...); err != nil {
+				return fmt.Errorf("error staging the repaired files: %v: %s", err, strings.TrimSpace(output))
+			}
+		}
+		step = []string{"am", "--continue"}
+	}
+}
+
+// applyWithRepair applies patches with git apply --reject, so the hunks that still
+// apply are applied and the others are written to .rej files, then has the model adapt
+// the rejected hunks and applies its patch once the user accepts it. It reports whether
+// the whole change is applied.
+func applyWithRepair(ctx context.Context, root string, options, patches []string) (bool, error) {
+	output, err := runGitIn(root, append(append([]string{"apply", "--reject"}, options...), patches...)...)
+	if err == nil {
+		// Applied after all, e.g. when git am failed on something else
+		return true, nil
+	}
+	matches := rejectedPatch.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		fmt.Print(output)
+		return false, fmt.Errorf("the patch doesn't apply, and there are no rejected hunks to repair (see git's error above)")
+	}
+
+	var rejects, current strings.Builder
+	var rejectFiles []string
+	for _, m := range matches {
+		file := m[1]
+		rejectFile := filepath.Join(root, file+".rej")
+		reject, err := os.ReadFile(rejectFile)
+		if err != nil {
+			return false, fmt.Errorf("error reading the rejected hunks of %s: %v", file, err)
+		}
+		rejectFiles = append(rejectFiles, rejectFile)
+		fmt.Printf("⚠️  %s: %d hunk(s) rejected\n", file, len(hunkHeader.FindAllString(string(reject), -1)))
+		rejects.WriteString(strings.TrimRight(string(reject), "\n") + "\n\n")
+		content, err := os.ReadFile(filepath.Join(root, file))
+		if err != nil {
+			return false, fmt.Errorf("error reading %s: %v", file, err)
+		}
+		current.WriteString(rejectContext(file, string(content), string(reject)))
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return false, err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return false, err
+	}
+
+	var whole strings.Builder
+	for _, patch := range patches {
+		content, err := os.ReadFile(patch)
+		if err != nil {
+			return false, fmt.Errorf("error reading %s: %v", patch, err)
+		}
+		whole.Write(content)
+	}
+
+	repaired, err := repairRejectedHunks(ctx, client, root, whole.String(), rejects.String(), current.String())
+	if err != nil || repaired == "" {
+		return false, err
+	}
+	return reviewRepairedPatch(root, options, repaired, rejectFiles)
+}
+
+// repairRejectedHunks asks the model for a patch of the rejected hunks that applies to
+// the current code, checking each one with git apply --check. It returns "" when the
+// model says the hunks can't be adapted.
+func repairRejectedHunks(ctx context.Context, client *solar.Client, root, patch, rejects, current string) (string, error) {
+	correction := ""
+	var repaired string
+	for attempt := 1; attempt <= patchRepairAttempts; attempt++ {
+		statusf("🔧 Adapting the rejected hunks to the current code with Solar LLM...\n")
+		response, err := client.RepairPatch(ctx, patch, rejects, current, correction)
+		if err != nil {
+			return "", fmt.Errorf("error repairing the patch: %w", err)
+		}
+		repaired = stripCodeFence(response)
+		if reason, ok := strings.CutPrefix(repaired, solar.CannotAdaptPrefix); ok {
+			fmt.Printf("🤷 The rejected hunks can't be adapted: %s\n", strings.TrimSpace(reason))
+			return "", nil
+		}
+		repaired = strings.TrimRight(repaired, "\n") + "\n"
+
+		file, err := writeTempPatch("sgit-repaired-*.patch", repaired)
+		if err != nil {
+			return "", err
+		}
+		output, err := runGitIn(root, "apply", "--check", "--recount", file)
+		os.Remove(file)
+		if err == nil {
+			return repaired, nil
+		}
+		correction = strings.TrimSpace(output)
+		statusf("↻ The adapted patch doesn't apply (%s)\n", firstLine(correction))
+	}
+
+	saved, err := writeTempPatch("sgit-repaired-*.patch", repaired)
+	if err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("the adapted patch doesn't apply: %s\nIt is saved in %s for you to fix", correction, saved)
+}
+
+// reviewRepairedPatch shows the adapted patch and applies it if the user accepts it,
+// possibly after editing it, removing the .rej files it replaces. options are those of
+// the original git apply. Declined, the patch is saved for the user.
+func reviewRepairedPatch(root string, options []string, repaired string, rejectFiles []string) (bool, error) {
+	for {
+		fmt.Println("\n=== ADAPTED PATCH ===")
+		fmt.Print(repaired)
+		fmt.Println("=====================")
+
+		answer := "y"
+		if !assumeYes {
+			answer = strings.ToLower(ask("Apply the adapted patch? [y]es / [e]dit / [n]o: "))
+		}
+		switch answer {
+		case "y", "yes":
+			file, err := writeTempPatch("sgit-repaired-*.patch", repaired)
+			if err != nil {
+				return false, err
+			}
+			defer os.Remove(file)
+			if output, err := runGitIn(root, append(append([]string{"apply", "--recount"}, patchIndexOptions(options)...), file)...); err != nil {
+				return false, fmt.Errorf("error applying the adapted patch: %v: %s", err, strings.TrimSpace(output))
+			}
+			for _, rejectFile := range rejectFiles {
+				os.Remove(rejectFile)
+			}
+			return true, nil
+		case "e", "edit":
+			edited, err := editPatch(repaired)
+			if err != nil {
+				return false, err
+			}
+			if strings.TrimSpace(edited) == "" {
+				fmt.Println("Empty patch; nothing applied")
+				return false, nil
+			}
+			repaired = edited
+		default:
+			saved := filepath.Join(root, "sgit-repaired.patch")
+			if err := os.WriteFile(saved, []byte(repaired), 0644); err != nil {
+				return false, fmt.Errorf("error saving the adapted patch: %v", err)
+			}
+			fmt.Printf("💾 Saved the adapted patch to %s; apply it with 'git apply --recount %s'\n", saved, filepath.Base(saved))
+			return false, nil
+		}
+	}
+}
+
+// editPatch opens a patch in the user's editor and returns the edited patch
+func editPatch(patch string) (string, error) {
+	file, err := writeTempPatch("sgit-repaired-*.patch", patch)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file)
+
+	editorParts := strings.Fields(getDefaultEditor())
+	if len(editorParts) == 0 {
+		return "", fmt.Errorf("no editor found")
+	}
+	editor := exec.Command(editorParts[0], append(editorParts[1:], file)...)
+	editor.Stdin, editor.Stdout, editor.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := editor.Run(); err != nil {
+		return "", fmt.Errorf("editor exited with error: %w", err)
+	}
+	edited, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("error reading the edited patch: %v", err)
+	}
+	return string(edited), nil
+}
+
+// rejectContext shows the model the current code of a file with rejected hunks, with
+// line numbers: all of it when it is small, else the lines around where each hunk was
+// meant to go
+func rejectContext(file, content, reject string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	type lineRange struct{ start, end int }
+	var ranges []lineRange
+	if len(lines) <= wholeFileLines {
+		ranges = append(ranges, lineRange{1, len(lines)})
+	} else {
+		for _, m := range hunkHeader.FindAllStringSubmatch(reject, -1) {
+			start, _ := strconv.Atoi(m[1])
+			length := 1
+			if m[2] != "" {
+				length, _ = strconv.Atoi(m[2])
+			}
+			r := lineRange{max(start-rejectContextLines, 1), min(start+length+rejectContextLines, len(lines))}
+			// Hunks close together share their context
+			if n := len(ranges); n > 0 && r.start <= ranges[n-1].end+1 {
+				ranges[n-1].end = max(ranges[n-1].end, r.end)
+				continue
+			}
+			ranges = append(ranges, r)
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range ranges {
+		if r.start > r.end {
+			continue
+		}
+		fmt.Fprintf(&b, "--- %s, lines %d-%d of %d ---\n", file, r.start, r.end, len(lines))
+		for number := r.start; number <= r.end; number++ {
+			fmt.Fprintf(&b, "%5d| %s\n", number, lines[number-1])
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// patchIndexOptions keeps the options of a git apply run that the adapted patch is
+// applied with too: where it is applied, not how the original patch is read
+func patchIndexOptions(options []string) []string {
+	var kept []string
+	for _, option := range options {
+		if option == "--index" || option == "--intent-to-add" || option == "-N" {
+			kept = append(kept, option)
+		}
+	}
+	return kept
+}
+
+// patchFiles returns the files a patch changes, creates or deletes
+func patchFiles(patch string) []string {
+	var files []string
+	for _, line := range strings.Split(patch, "\n") {
+		var file string
+		if name, ok := strings.CutPrefix(line, "--- a/"); ok {
+			file = name
+		} else if name, ok := strings.CutPrefix(line, "+++ b/"); ok {
+			file = name
+		}
+		file = strings.TrimSpace(file)
+		if file != "" && !slices.Contains(files, file) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// isAmInProgress reports whether git am stopped at a patch
+func isAmInProgress() bool {
+	dir, err := runGitOutput("rev-parse", "--git-path", "rebase-apply/applying")
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(strings.TrimSpace(dir))
+	return err == nil
+}
+
+// runGitIn runs git in dir and returns its combined output
+func runGitIn(dir string, args ...string) (string, error) {
+	gitCmd := exec.Command("git", args...)
+	gitCmd.Dir = dir
+	output, err := gitCmd.CombinedOutput()
+	return string(output), err
+}
+
+// saveStdinPatch saves a patch piped to stdin to a temporary file
+func saveStdinPatch() (string, error) {
+	content, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("error reading the patch from stdin: %v", err)
+	}
+	if strings.TrimSpace(string(content)) == "" {
+		return "", fmt.Errorf("no patch given: pass a patch file or pipe one in")
+	}
+	return writeTempPatch("sgit-stdin-*.patch", string(content))
+}
+
+// writeTempPatch writes a patch to a new temporary file and returns its path
+func writeTempPatch(pattern, patch string) (string, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("error creating a temporary file: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(patch); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("error writing %s: %v", file.Name(), err)
+	}
+	return file.Name(), nil
+}
+
+// firstLine returns the first line of text
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hunkim/sgit/pkg/github"
+	"github.com/spf13/viper"
+)
+
+// githubLookupTimeout bounds each GitHub API lookup made while detecting the base
+// branch, so that sgit doesn't hang offline
+const githubLookupTimeout = 3 * time.Second
+
+// githubRepositories caches the repositories looked up on GitHub by remote name
+var githubRepositories = make(map[string]*github.Repository)
+
+// detectBaseBranch finds the branch to compare against and open pull requests on, as a
+// ref such as "upstream/main". In order, it takes:
+//   - the branch the current branch tracks, when that is another branch (a feature
+//     branch made with 'git switch -c feature --track upstream/main')
+//   - the default branch of the base remote: pr_remote in config, a remote named
+//     upstream, the remote for the repository origin was forked from on GitHub, or
+//     origin. The default branch comes from <remote>/HEAD or the GitHub API.
+//   - main or master on that remote, or locally
+func detectBaseBranch() (string, error) {
+	if ref := trackedBaseBranch(); ref != "" {
+		return ref, nil
+	}
+
+	remote := baseRemote()
+	if remote != "" {
+		if branch := remoteDefaultBranch(remote); branch != "" {
+			ref := remote + "/" + branch
+			if refExists(ref) {
+				return ref, nil
+			}
+			return "", fmt.Errorf("the base branch %s has not been fetched - run 'git fetch %s', or use --base", ref, remote)
+		}
+		for _, candidate := range []string{"main", "master"} {
+			if refExists(remote + "/" + candidate) {
+				return remote + "/" + candidate, nil
+			}
+		}
+		statusf("💡 The default branch of %s is not known; set it with: git remote set-head %s --auto\n", remote, remote)
+	}
+
+	for _, candidate := range []string{"main", "master"} {
+		if refExists(candidate) {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not detect base branch, use --base to specify one")
+}
+
+// trackedBaseBranch returns the remote branch the current branch tracks when it has
+// another name, or ""; a branch tracking its own name on a remote is just pushed there
+func trackedBaseBranch() string {
+	branch, err := getCurrentBranch()
+	if err != nil || branch == "" {
+		return ""
+	}
+	ref, err := runGitOutput("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
+	if ref = strings.TrimSpace(ref); err != nil || ref == "" {
+		return ""
+	}
+	if remote, name := splitRemoteRef(ref); remote == "" || name == branch {
+		return ""
+	}
+	return ref
+}
+
+// baseRemote picks the remote pull requests are opened on: pr_remote from config, a
+// remote named upstream, the remote for the GitHub repository origin was forked
+// from, origin, or the only remote. It returns "" when there is no such remote.
+func baseRemote() string {
+	remotes := gitRemotes()
+	if configured := viper.GetString("pr_remote"); configured != "" {
+		if hasRemote(remotes, configured) {
+			return configured
+		}
+		fmt.Fprintf(os.Stderr, "⚠️  pr_remote is '%s', which is not a remote in this repository\n", configured)
+	}
+	if hasRemote(remotes, "upstream") {
+		return "upstream"
+	}
+	if hasRemote(remotes, "origin") {
+		if parent := forkParentRemote(remotes); parent != "" {
+			return parent
+		}
+		return "origin"
+	}
+	if len(remotes) == 1 {
+		return remotes[0]
+	}
+	return ""
+}
+
+// forkParentRemote returns the remote for the repository origin was forked from on
+// GitHub, or "". When origin is a fork but its parent is not a remote, it says how to
+// add it.
+func forkParentRemote(remotes []string) string {
+	origin := githubRepository("origin")
+	if origin == nil || !origin.Fork || origin.Parent == nil {
+		return ""
+	}
+	for _, remote := range remotes {
+		if remote == "origin" {
+			continue
+		}
+		if owner, repo, ok := githubRemote(remote); ok && strings.EqualFold(owner+"/"+repo, origin.Parent.FullName) {
+			githubRepositories[remote] = origin.Parent
+			return remote
+		}
+	}
+	statusf("💡 origin is a fork of %s; to compare against it: git remote add upstream %s && git fetch upstream\n",
+		origin.Parent.FullName, origin.Parent.CloneURL)
+	return ""
+}
+
+// remoteDefaultBranch returns the default branch of remote from <remote>/HEAD, which
+// git sets on clone (or 'git remote set-head <remote> --auto'), or from the GitHub API.
+// It returns "" when neither knows it.
+func remoteDefaultBranch(remote string) string {
+	if ref, err := runGitOutput("rev-parse", "--abbrev-ref", remote+"/HEAD"); err == nil {
+		if ref = strings.TrimSpace(ref); ref != "" && ref != remote+"/HEAD" {
+			return strings.TrimPrefix(ref, remote+"/")
+		}
+	}
+	if repository := githubRepository(remote); repository != nil {
+		return repository.DefaultBranch
+	}
+	return ""
+}
+
+// githubRepository looks up the repository remote points to on GitHub, or returns nil
+// when it isn't on GitHub or can't be reached
+func githubRepository(remote string) *github.Repository {
+	if repository, ok := githubRepositories[remote]; ok {
+		return repository
+	}
+	githubRepositories[remote] = nil
+
+	owner, repo, ok := githubRemote(remote)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), githubLookupTimeout)
+	defer cancel()
+	client := github.NewClient(getGitHubToken(), viper.GetString("github_api_url"))
+	repository, err := client.GetRepository(ctx, owner, repo)
+	if err != nil {
+		return nil
+	}
+	githubRepositories[remote] = repository
+	return repository
+}
+
+// githubRemote returns the owner and name of the GitHub repository remote points to.
+// Hosts other than github.com count only when github_api_url is configured.
+func githubRemote(remote string) (owner, repo string, ok bool) {
+	remoteURL, err := runGitOutput("remote", "get-url", remote)
+	if err != nil {
+		return "", "", false
+	}
+	host, owner, repo, err := github.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return "", "", false
+	}
+	if !strings.EqualFold(host, "github.com") {
+		apiURL, err := url.Parse(viper.GetString("github_api_url"))
+		if err != nil || viper.GetString("github_api_url") == "" || !strings.EqualFold(apiURL.Hostname(), host) {
+			return "", "", false
+		}
+	}
+	return owner, repo, true
+}
+
+// pushRemote returns the remote branch is pushed to: branch.<name>.pushRemote,
+// remote.pushDefault, the remote it tracks, or origin
+func pushRemote(branch string) string {
+	for _, key := range []string{"branch." + branch + ".pushRemote", "remote.pushDefault", "branch." + branch + ".remote"} {
+		if remote := strings.TrimSpace(gitConfigValue(key)); remote != "" && remote != "." {
+			return remote
+		}
+	}
+	return "origin"
+}
+
+// gitRemotes lists the configured remotes
+func gitRemotes() []string {
+	output, err := runGitOutput("remote")
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(output)
+}
+
+func hasRemote(remotes []string, name string) bool {
+	for _, remote := range remotes {
+		if remote == name {
+			return true
+		}
+	}
+	return false
+}
+
+func refExists(ref string) bool {
+	_, err := runGitOutput("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	return err == nil
+}
+
+// splitRemoteRef splits a ref such as "upstream/main" into the remote and the branch;
+// the remote is "" when ref is not a remote branch
+func splitRemoteRef(ref string) (string, string) {
+	if idx := strings.Index(ref, "/"); idx != -1 {
+		if _, err := runGitOutput("remote", "get-url", ref[:idx]); err == nil {
+			return ref[:idx], ref[idx+1:]
+		}
+	}
+	return "", ref
+}
+
+// baseBranchName strips a remote prefix such as "origin/" from a ref
+func baseBranchName(ref string) string {
+	_, branch := splitRemoteRef(ref)
+	return branch
+}
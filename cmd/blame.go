@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultBlameMaxCommits limits how many blamed commits are sent to the model
+const defaultBlameMaxCommits = 10
+
+// blameCmd wraps git blame, adding an AI explanation of the code's history with --ai
+var blameCmd = &cobra.Command{
+	Use:   "blame [--ai] [--max-commits N] [git blame options] [rev] [--] <file>",
+	Short: "Show who changed each line, with an AI explanation of the code's history via --ai",
+	Long: `Passthrough to git blame. With --ai, the commits that last touched the selected
+lines are collected together with their diffs, and Solar LLM explains the rationale
+and evolution of that code. Narrow the selection with -L, e.g.:
+
+  sgit blame --ai -L 120,180 pkg/solar/client.go
+  sgit blame --ai -L :GenerateResponse pkg/solar/client.go
+
+Use --max-commits to change how many of the most recent blamed commits are analyzed
+(default 10).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runBlame(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+	DisableFlagParsing: true,
+}
+
+func init() {
+	rootCmd.AddCommand(blameCmd)
+}
+
+// blamedCommit is a commit that last touched some of the blamed lines
+type blamedCommit struct {
+	sha        string
+	author     string
+	email      string
+	authorTime int64
+	summary    string
+	filename   string
+	lines      int
+}
+
+func runBlame(cmd *cobra.Command, args []string) error {
+	// Flag parsing is disabled so every git blame option passes through; pick out ours
+	useAI, noAI := false, false
+	maxCommits := defaultBlameMaxCommits
+	var gitArgs []string
+	args = extractGlobalFlags(args)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--ai":
+			useAI = true
+		case arg == "--no-ai":
+			noAI = true
+		case arg == "--max-commits" || strings.HasPrefix(arg, "--max-commits="):
+			value := strings.TrimPrefix(arg, "--max-commits=")
+			if arg == "--max-commits" {
+				if i+1 >= len(args) {
+					return fmt.Errorf("--max-commits requires a value")
+				}
+				i++
+				value = args[i]
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid --max-commits value '%s'", value)
+			}
+			maxCommits = n
+		default:
+			gitArgs = append(gitArgs, arg)
+		}
+	}
+
+	if !useAIFor("blame", false, useAI, noAI) {
+		executeGitCommand(append([]string{"blame"}, gitArgs...))
+		return nil
+	}
+
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+	if len(gitArgs) == 0 {
+		return fmt.Errorf("specify a file, e.g. sgit blame --ai -L 10,40 main.go")
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+
+	porcelain, err := gitRepo.Blame(cmd.Context(), gitArgs...)
+	if err != nil {
+		return fmt.Errorf("error running git blame: %w", err)
+	}
+
+	blame := formatBlame(porcelain)
+	commits := parseBlamePorcelain(porcelain)
+	if len(commits) == 0 {
+		return fmt.Errorf("the selected lines have not been committed yet")
+	}
+
+	// Keep the most recent commits, then present them oldest first
+	sort.Slice(commits, func(i, j int) bool { return commits[i].authorTime > commits[j].authorTime })
+	if len(commits) > maxCommits {
+		statusf("📊 Analyzing the %d most recent of %d commits (use --max-commits to change)\n", maxCommits, len(commits))
+		commits = commits[:maxCommits]
+	}
+	sort.Slice(commits, func(i, j int) bool { return commits[i].authorTime < commits[j].authorTime })
+
+	history, err := blameCommitHistory(commits)
+	if err != nil {
+		return err
+	}
+
+	// Show the regular blame first
+	fmt.Println("=== GIT BLAME ===")
+	fmt.Println(strings.TrimRight(blame, "\n"))
+	fmt.Println()
+
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("=== AI HISTORY ===")
+	renderer, err := outputRenderer("")
+	if err != nil {
+		return err
+	}
+	printContentStats("History analysis", blame, history)
+	printer := newStreamPrinter("")
+	printer.renderer = renderer
+	_, err = client.ExplainCodeHistory(cmd.Context(), blameLocation(gitArgs), blame, history, printer.Write)
+	printer.Done()
+	if err != nil {
+		return fmt.Errorf("error explaining code history: %w", err)
+	}
+
+	fmt.Println() // Add newline after streaming output
+	return nil
+}
+
+// parseBlamePorcelain collects the distinct commits in git blame --porcelain output,
+// skipping lines that are not committed yet
+func parseBlamePorcelain(output string) []*blamedCommit {
+	const uncommittedSHA = "0000000000000000000000000000000000000000"
+
+	var commits []*blamedCommit
+	bySHA := make(map[string]*blamedCommit)
+	var current *blamedCommit
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "\t") {
+			// Content line: ends the entry for one blamed line
+			if current != nil {
+				current.lines++
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && len(fields[0]) == 40 {
+			if _, err := strconv.Atoi(fields[1]); err == nil {
+				commit, ok := bySHA[fields[0]]
+				if !ok {
+					commit = &blamedCommit{sha: fields[0]}
+					bySHA[fields[0]] = commit
+					if fields[0] != uncommittedSHA {
+						commits = append(commits, commit)
+					}
+				}
+				current = commit
+				continue
+			}
+		}
+
+		if current == nil {
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			current.author = value
+		case "author-mail":
+			current.email = strings.Trim(value, "<>")
+		case "author-time":
+			current.authorTime, _ = strconv.ParseInt(value, 10, 64)
+		case "summary":
+			current.summary = value
+		case "filename":
+			current.filename = value
+		}
+	}
+
+	return commits
+}
+
+// formatBlame renders git blame --porcelain output like git blame --date=short
+func formatBlame(output string) string {
+	type blameAuthor struct {
+		name string
+		date string
+	}
+	authors := make(map[string]*blameAuthor)
+
+	var b strings.Builder
+	var sha, lineNumber string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "\t") {
+			author := authors[sha]
+			if author == nil {
+				author = &blameAuthor{}
+			}
+			fmt.Fprintf(&b, "%.8s (%s %s %4s) %s\n", sha, author.name, author.date, lineNumber, line[1:])
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && len(fields[0]) == 40 {
+			if _, err := strconv.Atoi(fields[1]); err == nil {
+				sha, lineNumber = fields[0], fields[2]
+				if authors[sha] == nil {
+					authors[sha] = &blameAuthor{}
+				}
+				continue
+			}
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			authors[sha].name = value
+		case "author-time":
+			if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+				authors[sha].date = time.Unix(seconds, 0).Format("2006-01-02")
+			}
+		}
+	}
+	return b.String()
+}
+
+// blameCommitHistory formats each commit's message and its diff for the blamed file
+func blameCommitHistory(commits []*blamedCommit) (string, error) {
+	var b strings.Builder
+	for _, commit := range commits {
+		show, err := runGitOutput("show", "--no-color", "--date=short",
+			"--format=commit %h%nAuthor: %an%nDate: %ad%n%n%B", commit.sha, "--", commit.filename)
+		if err != nil {
+			return "", fmt.Errorf("error reading commit %s: %v", commit.sha[:7], err)
+		}
+		fmt.Fprintf(&b, "%s\n(%d of the blamed lines)\n\n", strings.TrimRight(show, "\n"), commit.lines)
+	}
+	return b.String(), nil
+}
+
+// blameLocation describes the blamed file and line range for the prompt
+func blameLocation(args []string) string {
+	var file, lineRange string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-L" && i+1 < len(args):
+			i++
+			lineRange = args[i]
+		case strings.HasPrefix(arg, "-L"):
+			lineRange = strings.TrimPrefix(arg, "-L")
+		case !strings.HasPrefix(arg, "-"):
+			file = arg
+		}
+	}
+
+	if lineRange == "" {
+		return file
+	}
+	return fmt.Sprintf("%s, lines %s", file, lineRange)
+}
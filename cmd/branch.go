@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/github"
+	"github.com/hunkim/sgit/pkg/tracker"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+var (
+	branchFromIssue  string
+	branchTicket     string
+	branchCount      int
+	branchNoCheckout bool
+)
+
+// defaultBranchPrefixes are used when branch_prefixes is not configured
+var defaultBranchPrefixes = []string{"feature/", "fix/", "chore/", "docs/", "refactor/"}
+
+// branchCmd wraps git branch, adding AI-suggested branch names via "branch new"
+var branchCmd = &cobra.Command{
+	Use:   "branch",
+	Short: "List, create, or delete branches (with AI-suggested names via 'branch new')",
+	Long: `Passthrough to git branch. Use 'sgit branch new "<description>"' to get
+AI-suggested branch names that follow your configured naming patterns
+('sgit branch new --help' for its options).
+
+Only a leading "new" asks for names: 'sgit branch -d new' and 'sgit branch -m old new'
+are passed to git. Use 'git branch new' to create a branch called "new".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		args = extractGlobalFlags(args)
+		if len(args) > 0 && args[0] == "new" {
+			if err := runBranchNewArgs(cmd, args[1:]); err != nil {
+				exitWithError(err)
+			}
+			return
+		}
+		executeGitCommand(append([]string{"branch"}, args...))
+	},
+	DisableFlagParsing: true,
+}
+
+// branchNewCmd suggests branch names with AI and creates the chosen one. It isn't a
+// subcommand of branchCmd, since cobra would then route any "new" among git branch's
+// arguments to it, e.g. the branch name of 'sgit branch -d new'; branchCmd runs it when
+// "new" comes first.
+var branchNewCmd = &cobra.Command{
+	Use:   "sgit branch new [description]",
+	Short: "Create a branch with an AI-suggested name",
+	Long: `Describe the work you're about to do and get 3-5 branch name suggestions that follow
+the configured naming patterns. Use --from-issue to take the description from a GitHub
+issue (number or URL) or a Jira/Linear ticket (e.g. PROJ-123).
+
+Configure patterns in ~/.config/sgit/config.yaml:
+  branch_prefixes: [feature/, fix/, chore/]
+  branch_pattern: "<prefix><ticket>-<short-description>"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runBranchNew(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(branchCmd)
+
+	branchNewCmd.Flags().StringVar(&branchFromIssue, "from-issue", "", "use a GitHub issue (number or URL) or Jira/Linear ticket (KEY-123) as the description")
+	branchNewCmd.Flags().StringVar(&branchTicket, "ticket", "", "ticket key to include in the branch name")
+	branchNewCmd.Flags().IntVar(&branchCount, "count", 4, "number of suggestions (3-5)")
+	branchNewCmd.Flags().BoolVar(&branchNoCheckout, "no-checkout", false, "create the branch without switching to it")
+}
+
+// runBranchNewArgs parses the options of 'sgit branch new' from args and runs it
+func runBranchNewArgs(cmd *cobra.Command, args []string) error {
+	if err := branchNewCmd.ParseFlags(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return branchNewCmd.Help()
+		}
+		return err
+	}
+	branchNewCmd.SetContext(cmd.Context())
+	return runBranchNew(branchNewCmd, branchNewCmd.Flags().Args())
+}
+
+func runBranchNew(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	description := strings.TrimSpace(strings.Join(args, " "))
+	ticket := branchTicket
+
+	if branchFromIssue != "" {
+		issue, err := fetchIssue(cmd.Context(), branchFromIssue)
+		if err != nil {
+			return fmt.Errorf("error fetching issue: %w", err)
+		}
+		fmt.Printf("📋 %s: %s\n", issue.Key, issue.Title)
+		if ticket == "" {
+			ticket = issue.Key
+		}
+		description = strings.TrimSpace(issue.Title + "\n\n" + issue.Description + "\n\n" + description)
+	}
+
+	if description == "" {
+		return fmt.Errorf("describe the work, e.g. sgit branch new \"fix race in token refresh\"")
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	count := branchCount
+	if count < 3 {
+		count = 3
+	} else if count > 5 {
+		count = 5
+	}
+
+	statusln("Generating branch name suggestions with Solar LLM...")
+	response, err := client.SuggestBranchNames(cmd.Context(), description, branchConventions(ticket), count)
+	if err != nil {
+		return fmt.Errorf("error generating branch names: %w", err)
+	}
+
+	suggestions := parseBranchSuggestions(response, count)
+	if len(suggestions) == 0 {
+		return fmt.Errorf("no valid branch names were suggested")
+	}
+
+	fmt.Println("\nSuggested branch names:")
+	for i, name := range suggestions {
+		fmt.Printf("  %d) %s\n", i+1, name)
+	}
+
+	fmt.Print("\nChoose a number, type a custom name, or press Enter to cancel: ")
+	reader := bufio.NewReader(os.Stdin)
+	choice, _ := reader.ReadString('\n')
+	choice = strings.TrimSpace(choice)
+	if choice == "" {
+		fmt.Println("Branch creation cancelled")
+		return nil
+	}
+
+	name := choice
+	if n, err := strconv.Atoi(choice); err == nil {
+		if n < 1 || n > len(suggestions) {
+			return fmt.Errorf("invalid choice: %d", n)
+		}
+		name = suggestions[n-1]
+	}
+
+	if !isValidBranchName(name) {
+		return fmt.Errorf("invalid branch name: %s", name)
+	}
+
+	var gitArgs []string
+	if branchNoCheckout {
+		gitArgs = []string{"branch", name}
+	} else {
+		gitArgs = []string{"checkout", "-b", name}
+	}
+
+	gitCmd := exec.Command("git", gitArgs...)
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	if err := gitCmd.Run(); err != nil {
+		return fmt.Errorf("error creating branch: %w", err)
+	}
+
+	if branchNoCheckout {
+		fmt.Printf("✅ Created branch %s\n", name)
+	}
+	return nil
+}
+
+// branchConventions describes the configured naming patterns for the prompt
+func branchConventions(ticket string) string {
+	prefixes := viper.GetStringSlice("branch_prefixes")
+	if len(prefixes) == 0 {
+		prefixes = defaultBranchPrefixes
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "- Start with one of these prefixes, chosen by the type of work: %s\n", strings.Join(prefixes, ", "))
+	if pattern := viper.GetString("branch_pattern"); pattern != "" {
+		fmt.Fprintf(&b, "- Follow this pattern: %s\n", pattern)
+	}
+	if ticket != "" {
+		fmt.Fprintf(&b, "- Include the ticket key '%s' right after the prefix (e.g. feature/%s-short-description)\n", ticket, ticket)
+	}
+	return b.String()
+}
+
+// branchNameCleaner strips list markers and quotes the model sometimes adds
+var branchNameCleaner = regexp.MustCompile("^(\\d+[.)]\\s*|[-*]\\s*|`)|`$")
+
+// parseBranchSuggestions extracts valid branch names from the model response
+func parseBranchSuggestions(response string, max int) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(response, "\n") {
+		name := strings.TrimSpace(branchNameCleaner.ReplaceAllString(strings.TrimSpace(line), ""))
+		if name == "" || seen[name] || !isValidBranchName(name) {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+		if len(names) == max {
+			break
+		}
+	}
+	return names
+}
+
+// isValidBranchName checks a name with git check-ref-format
+func isValidBranchName(name string) bool {
+	if strings.ContainsAny(name, " \t") {
+		return false
+	}
+	_, err := runGitOutput("check-ref-format", "--branch", name)
+	return err == nil
+}
+
+// githubIssueURL matches GitHub issue URLs
+var githubIssueURL = regexp.MustCompile(`github\.com/([^/]+)/([^/]+)/issues/(\d+)`)
+
+// fetchIssue resolves a GitHub issue (number, #number, or URL) or a Jira/Linear ticket key
+func fetchIssue(ctx context.Context, ref string) (*tracker.Ticket, error) {
+	ref = strings.TrimSpace(ref)
+
+	if tracker.IsTicketKey(ref) {
+		return fetchTicket(ctx, ref)
+	}
+
+	var owner, repo string
+	var number int
+	if m := githubIssueURL.FindStringSubmatch(ref); m != nil {
+		owner, repo = m[1], m[2]
+		number, _ = strconv.Atoi(m[3])
+	} else {
+		n, err := strconv.Atoi(strings.TrimPrefix(ref, "#"))
+		if err != nil {
+			return nil, fmt.Errorf("unrecognized issue reference '%s' (use a number, URL, or ticket key)", ref)
+		}
+		number = n
+
+		remoteURL, err := runGitOutput("remote", "get-url", "origin")
+		if err != nil {
+			return nil, fmt.Errorf("error getting origin remote: %w", err)
+		}
+		if _, owner, repo, err = github.ParseRemoteURL(remoteURL); err != nil {
+			return nil, err
+		}
+	}
+
+	client := github.NewClient(getGitHubToken(), viper.GetString("github_api_url"))
+	issue, err := client.GetIssue(ctx, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tracker.Ticket{
+		Key:         fmt.Sprintf("%d", issue.Number),
+		Title:       issue.Title,
+		Description: issue.Body,
+		URL:         issue.HTMLURL,
+	}, nil
+}
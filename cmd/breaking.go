@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/apidiff"
+	"github.com/spf13/cobra"
+)
+
+var (
+	breakingStaged bool
+	breakingNoAI   bool
+)
+
+// breakingCmd finds public API changes between two versions and classifies them
+var breakingCmd = &cobra.Command{
+	Use:   "breaking [<range>]",
+	Short: "Detect breaking changes to the public API between two versions",
+	Long: `Compare the public API of the changed files between two versions and have Solar
+LLM classify each change as breaking or compatible, with the semantic version bump it
+calls for and a BREAKING CHANGE footer for the commit message.
+
+Exported Go identifiers are found by parsing the code; for Python, JavaScript/TypeScript,
+Java, C# and Rust, top-level public declarations are found with heuristics. Test files
+and Go internal and main packages are not part of the API.
+
+The range defaults to the latest tag..HEAD. A single revision compares it with HEAD,
+and --staged compares HEAD with the staged changes. 'sgit commit' runs the same check
+on the staged changes and asks for a BREAKING CHANGE footer when the API breaks, and
+'sgit semver' takes it into account.
+
+Examples:
+  sgit breaking
+  sgit breaking v1.2.0..v1.3.0
+  sgit breaking main...feature/new-api
+  sgit breaking --staged --no-ai`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runBreaking(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(breakingCmd)
+
+	breakingCmd.Flags().BoolVar(&breakingStaged, "staged", false, "compare HEAD with the staged changes")
+	breakingCmd.Flags().BoolVar(&breakingNoAI, "no-ai", false, "only list the API changes, without AI classification")
+}
+
+// apiClassification is the parsed response of ClassifyAPIChanges
+type apiClassification struct {
+	breaking bool
+	bump     string
+	changes  []string
+	footer   string
+}
+
+func runBreaking(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	var oldRev, newRev, scope string
+	switch {
+	case breakingStaged:
+		if len(args) > 0 {
+			return fmt.Errorf("--staged can't be combined with a range")
+		}
+		if head, _ := runGitOutput("rev-parse", "--verify", "-q", "HEAD"); strings.TrimSpace(head) != "" {
+			oldRev = "HEAD"
+		}
+		scope = "HEAD and the staged changes"
+	case len(args) == 0:
+		tag, err := getLatestTag("HEAD")
+		if err != nil || tag == "" {
+			return fmt.Errorf("no tags to compare with - pass a range (e.g. main..HEAD) or --staged")
+		}
+		oldRev, newRev = tag, "HEAD"
+	default:
+		var err error
+		oldRev, newRev, err = resolveAPIRange(args[0])
+		if err != nil {
+			return err
+		}
+	}
+	if scope == "" {
+		scope = oldRev + ".." + newRev
+	}
+
+	changes, checked, err := detectAPIChanges(oldRev, newRev)
+	if err != nil {
+		return err
+	}
+	if checked == 0 {
+		fmt.Printf("No changed Go, Python, JavaScript/TypeScript, Java, C# or Rust files in %s\n", scope)
+		return nil
+	}
+	if len(changes) == 0 {
+		fmt.Printf("✅ No public API changes in %s (%d file(s) checked)\n", scope, checked)
+		return nil
+	}
+
+	fmt.Printf("🔍 Public API changes in %s:\n\n", scope)
+	printAPIChanges(changes)
+
+	breakingNoAI = !useAIFor("breaking", true, false, breakingNoAI)
+	if breakingNoAI {
+		if removed, changed := countBreakingCandidates(changes); removed+changed > 0 {
+			fmt.Printf("\n⚠️  %d removed and %d changed symbol(s) may break callers\n", removed, changed)
+		}
+		return nil
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	var commits string
+	if newRev != "" && oldRev != "" {
+		commits, _ = runGitOutput("log", "--no-merges", "--format=%h %s", oldRev+".."+newRev)
+	}
+
+	statusln("\nClassifying the API changes with Solar LLM...")
+	response, err := client.ClassifyAPIChanges(cmd.Context(), apidiff.Format(changes), scope, commits)
+	if err != nil {
+		return fmt.Errorf("error classifying API changes: %w", err)
+	}
+	result := parseAPIClassification(response)
+
+	if result.breaking {
+		fmt.Println("\n💥 Breaking changes")
+	} else {
+		fmt.Println("\n✅ Compatible changes")
+	}
+	for _, line := range result.changes {
+		fmt.Printf("  %s\n", line)
+	}
+
+	if result.bump != "" {
+		current := semVersion{prefix: "v"}
+		currentLabel := "no version tags yet"
+		if tag, err := getLatestTag("HEAD"); err == nil && tag != "" {
+			if version, ok := parseSemver(tag); ok {
+				current, currentLabel = version, tag
+			}
+		}
+		fmt.Printf("\nRecommended bump: %s → %s (from %s)\n", result.bump, current.bump(result.bump), currentLabel)
+	}
+
+	if result.breaking && result.footer != "" {
+		fmt.Printf("\nSuggested footer:\n\nBREAKING CHANGE: %s\n", result.footer)
+	}
+	return nil
+}
+
+// resolveAPIRange returns the old and new revisions of "a..b", "a...b" (compared from
+// their merge base) or a single revision compared with HEAD
+func resolveAPIRange(spec string) (string, string, error) {
+	if from, to, ok := strings.Cut(spec, "..."); ok {
+		from, to = defaultRevision(from), defaultRevision(to)
+		base, err := runGitOutput("merge-base", from, to)
+		if err != nil {
+			return "", "", fmt.Errorf("error finding the merge base of %s: %v", spec, err)
+		}
+		return strings.TrimSpace(base), to, nil
+	}
+	if from, to, ok := strings.Cut(spec, ".."); ok {
+		return defaultRevision(from), defaultRevision(to), nil
+	}
+	return spec, "HEAD", nil
+}
+
+// defaultRevision returns HEAD for an empty side of a range, like git
+func defaultRevision(rev string) string {
+	if rev == "" {
+		return "HEAD"
+	}
+	return rev
+}
+
+// detectAPIChanges compares the public API of the files changed between two
+// revisions; an empty newRev means the index, and an empty oldRev an empty tree. It
+// also returns how many changed files are in a supported language.
+func detectAPIChanges(oldRev, newRev string) ([]apidiff.Change, int, error) {
+	diffArgs := []string{"diff", "--name-status", "-M"}
+	if newRev == "" {
+		diffArgs = append(diffArgs, "--cached")
+		if oldRev != "" {
+			diffArgs = append(diffArgs, oldRev)
+		}
+	} else {
+		diffArgs = append(diffArgs, oldRev, newRev)
+	}
+	output, err := runGitOutput(diffArgs...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing changed files: %w", err)
+	}
+
+	var oldSymbols, newSymbols []apidiff.Symbol
+	checked := 0
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		status := fields[0][:1]
+		oldPath, newPath := fields[1], fields[len(fields)-1]
+		if !apidiff.Supported(oldPath) && !apidiff.Supported(newPath) {
+			continue
+		}
+		checked++
+
+		// Copies leave the original untouched; added files have no old version
+		if oldRev != "" && status != "A" && status != "C" {
+			if content, err := runGitOutput("show", oldRev+":"+oldPath); err == nil {
+				oldSymbols = append(oldSymbols, apidiff.Extract(oldPath, []byte(content))...)
+			}
+		}
+		// "<rev>:<path>" reads a revision, ":<path>" the index
+		if status != "D" {
+			if content, err := runGitOutput("show", newRev+":"+newPath); err == nil {
+				newSymbols = append(newSymbols, apidiff.Extract(newPath, []byte(content))...)
+			}
+		}
+	}
+	return apidiff.Compare(oldSymbols, newSymbols), checked, nil
+}
+
+// countBreakingCandidates counts the removed and changed symbols, the changes that
+// can break callers
+func countBreakingCandidates(changes []apidiff.Change) (int, int) {
+	var removed, changed int
+	for _, change := range changes {
+		switch change.Kind {
+		case apidiff.Removed:
+			removed++
+		case apidiff.Changed:
+			changed++
+		}
+	}
+	return removed, changed
+}
+
+// stagedAPIBreaks describes the removed and changed public symbols between oldRev and
+// the index for the commit message prompt, or "" when there are none
+func stagedAPIBreaks(oldRev string) string {
+	changes, _, err := detectAPIChanges(oldRev, "")
+	if err != nil {
+		return ""
+	}
+	var candidates []apidiff.Change
+	for _, change := range changes {
+		if change.Kind != apidiff.Added {
+			candidates = append(candidates, change)
+		}
+	}
+	return apidiff.Format(candidates)
+}
+
+func printAPIChanges(changes []apidiff.Change) {
+	for _, change := range changes {
+		switch change.Kind {
+		case apidiff.Removed:
+			fmt.Printf("  ➖ %s\n       %s\n", change.Key, strings.ReplaceAll(change.Old, "\n", "\n       "))
+		case apidiff.Changed:
+			fmt.Printf("  ✏️  %s\n     - %s\n     + %s\n", change.Key,
+				strings.ReplaceAll(change.Old, "\n", "\n     - "), strings.ReplaceAll(change.New, "\n", "\n     + "))
+		case apidiff.Added:
+			fmt.Printf("  ➕ %s\n       %s\n", change.Key, strings.ReplaceAll(change.New, "\n", "\n       "))
+		}
+	}
+}
+
+// parseAPIClassification extracts the "VERDICT:", "BUMP:", "CHANGES:" and "FOOTER:"
+// sections of the AI response
+func parseAPIClassification(response string) apiClassification {
+	var result apiClassification
+	var footer []string
+	section := ""
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "*"))
+		upper := strings.ToUpper(trimmed)
+		switch {
+		case strings.HasPrefix(upper, "VERDICT:"):
+			result.breaking = strings.HasPrefix(strings.ToLower(strings.TrimSpace(trimmed[len("VERDICT:"):])), "breaking")
+			section = ""
+		case strings.HasPrefix(upper, "BUMP:"):
+			value := strings.ToLower(strings.TrimSpace(trimmed[len("BUMP:"):]))
+			for _, kind := range semverBumps[1:] {
+				if strings.HasPrefix(value, kind) {
+					result.bump = kind
+				}
+			}
+			section = ""
+		case strings.HasPrefix(upper, "CHANGES:"):
+			section = "changes"
+		case strings.HasPrefix(upper, "FOOTER:"):
+			footer = append(footer, strings.TrimSpace(trimmed[len("FOOTER:"):]))
+			section = "footer"
+		case section == "changes" && trimmed != "":
+			result.changes = append(result.changes, trimmed)
+		case section == "footer" && trimmed != "":
+			footer = append(footer, trimmed)
+		}
+	}
+
+	result.footer = strings.TrimSpace(strings.TrimPrefix(strings.Join(footer, " "), "BREAKING CHANGE:"))
+	if strings.EqualFold(strings.Trim(result.footer, "."), "none") {
+		result.footer = ""
+	}
+	return result
+}
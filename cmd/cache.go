@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd groups the AI response cache subcommands
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local AI response cache",
+	Long: `sgit caches AI responses (in ~/.cache/sgit by default) keyed by a hash of the
+prompt, so re-running a command on unchanged content doesn't cost another API call.
+Use --no-cache to bypass it for a single run.`,
+}
+
+// cacheClearCmd removes all cached responses
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached AI responses",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCacheClear(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	cache, err := newResponseCache()
+	if err != nil {
+		return err
+	}
+
+	count, err := cache.Clear()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Removed %d cached responses from %s\n", count, cache.Dir())
+	return nil
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	changelogFormat   string
+	changelogTemplate string
+	changelogVersion  string
+	changelogWrite    bool
+	changelogFile     string
+)
+
+// changelogCmd represents the changelog command
+var changelogCmd = &cobra.Command{
+	Use:   "changelog [from..to]",
+	Short: "Generate CHANGELOG entries from a range of commits with AI",
+	Long: `Feed the commit log and the diff between two refs to Solar LLM and produce
+a grouped changelog entry (features, fixes, breaking changes).
+
+Without a range, uses the commits since the latest tag. Use --write to prepend the
+entry to CHANGELOG.md.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runChangelog(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+
+	changelogCmd.Flags().StringVar(&changelogFormat, "format", "keepachangelog", "output format (keepachangelog|markdown)")
+	changelogCmd.Flags().StringVar(&changelogTemplate, "template", "", "file describing a custom markdown format (overrides --format)")
+	changelogCmd.Flags().StringVar(&changelogVersion, "version", "Unreleased", "version heading for the entry")
+	changelogCmd.Flags().BoolVar(&changelogWrite, "write", false, "prepend the entry to the changelog file")
+	changelogCmd.Flags().StringVar(&changelogFile, "file", "CHANGELOG.md", "changelog file used with --write")
+}
+
+func runChangelog(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+
+	rangeSpec := ""
+	if len(args) > 0 {
+		rangeSpec = args[0]
+	}
+
+	entry, err := generateChangelogEntry(cmd.Context(), rangeSpec, changelogVersion)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\n=== CHANGELOG ===")
+	fmt.Println(entry)
+	fmt.Println()
+
+	if !changelogWrite {
+		return nil
+	}
+
+	if err := prependChangelog(changelogFile, entry); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Updated %s\n", changelogFile)
+	return nil
+}
+
+// generateChangelogEntry produces a changelog entry for a commit range.
+// An empty range means "since the latest tag" (or the whole history without tags).
+func generateChangelogEntry(ctx context.Context, rangeSpec, version string) (string, error) {
+	if rangeSpec == "" {
+		rangeSpec = defaultReleaseRange()
+	}
+
+	logArgs := []string{"log", "--no-merges", "--format=%h %s%n%b"}
+	if rangeSpec != "" {
+		logArgs = append(logArgs, rangeSpec)
+	}
+	commits, err := runGitOutput(logArgs...)
+	if err != nil {
+		return "", fmt.Errorf("error getting commits for %s: %v", rangeDescription(rangeSpec), err)
+	}
+	if strings.TrimSpace(commits) == "" {
+		return "", fmt.Errorf("no commits found in %s", rangeDescription(rangeSpec))
+	}
+
+	// A single ref (history up to a tag) has no meaningful diff
+	var diffStat, diff string
+	if strings.Contains(rangeSpec, "..") {
+		diffStat, _ = runGitOutput("diff", "--stat", rangeSpec)
+		diff, _ = runGitOutput("diff", rangeSpec)
+	}
+
+	formatInstructions, err := changelogFormatInstructions()
+	if err != nil {
+		return "", err
+	}
+
+	client, err := newSolarClient()
+	if err != nil {
+		return "", err
+	}
+
+	statusf("Generating changelog for %s with Solar LLM...\n", rangeDescription(rangeSpec))
+	date := time.Now().Format("2006-01-02")
+	entry, err := client.GenerateChangelog(ctx, commits, diffStat, diff, version, date, formatInstructions)
+	if err != nil {
+		return "", fmt.Errorf("error generating changelog: %w", err)
+	}
+
+	return entry, nil
+}
+
+// changelogFormatInstructions resolves the format from --template, config, or --format
+func changelogFormatInstructions() (string, error) {
+	templatePath := changelogTemplate
+	if templatePath == "" {
+		templatePath = viper.GetString("changelog_template")
+	}
+	if templatePath != "" {
+		content, err := os.ReadFile(expandHome(templatePath))
+		if err != nil {
+			return "", fmt.Errorf("error reading changelog template: %w", err)
+		}
+		return "Use this custom format:\n" + string(content), nil
+	}
+
+	instructions, ok := solar.ChangelogFormats[changelogFormat]
+	if !ok {
+		return "", fmt.Errorf("unknown changelog format '%s' (use keepachangelog or markdown)", changelogFormat)
+	}
+	return instructions, nil
+}
+
+// defaultReleaseRange returns "<latest tag>..HEAD", or "" when the repository has no tags
+func defaultReleaseRange() string {
+	tag, err := getLatestTag("HEAD")
+	if err != nil || tag == "" {
+		return ""
+	}
+	return tag + "..HEAD"
+}
+
+// getLatestTag returns the most recent tag reachable from ref
+func getLatestTag(ref string) (string, error) {
+	output, err := runGitOutput("describe", "--tags", "--abbrev=0", ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// releaseRange returns the commits that belong to a release tagged at target:
+// "<previous tag>..target", or just target when there is no earlier tag
+func releaseRange(tag, target string) string {
+	output, err := runGitOutput("describe", "--tags", "--abbrev=0", "--exclude", tag, target+"^{commit}")
+	if err != nil || strings.TrimSpace(output) == "" {
+		return target
+	}
+	return strings.TrimSpace(output) + ".." + target
+}
+
+func rangeDescription(rangeSpec string) string {
+	if rangeSpec == "" {
+		return "the full history"
+	}
+	return rangeSpec
+}
+
+// prependChangelog inserts an entry above the newest release in a changelog file,
+// keeping any title and introduction at the top of the file
+func prependChangelog(path, entry string) error {
+	entry = strings.TrimSpace(entry) + "\n"
+
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		header := `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.0.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+
+`
+		return os.WriteFile(path, []byte(header+entry), 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", path, err)
+	}
+
+	content := string(existing)
+	insertAt := 0
+	if idx := strings.Index(content, "\n## "); idx != -1 {
+		insertAt = idx + 1
+	} else if strings.HasPrefix(content, "# ") {
+		insertAt = len(content)
+		entry = "\n" + entry
+	}
+
+	updated := content[:insertAt] + entry + "\n" + content[insertAt:]
+	return os.WriteFile(path, []byte(strings.TrimRight(updated, "\n")+"\n"), 0644)
+}
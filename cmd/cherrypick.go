@@ -0,0 +1,427 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+)
+
+// pickValueFlags are git cherry-pick and revert options that take a separate value argument
+var pickValueFlags = map[string]bool{
+	"-m":                true,
+	"--mainline":        true,
+	"-X":                true,
+	"--strategy-option": true,
+	"--strategy":        true,
+	"--cleanup":         true,
+}
+
+// pickOperation is what differs between cherry-pick and revert in the shared driver
+type pickOperation struct {
+	name    string // git subcommand
+	headRef string // pseudo ref git leaves while stopped at a conflict
+	// oldestFirst applies the commits of a range in history order
+	oldestFirst bool
+	// conflictContext explains ours and theirs to the AI for the commit being applied
+	conflictContext func(commit, branch string) string
+	// message writes the new commit message for sha, given the diff of the resulting commit
+	message func(ctx context.Context, client *solar.Client, sha, diff string) (string, error)
+}
+
+// cherryPickCmd wraps git cherry-pick, rewriting the picked commits' messages with AI
+var cherryPickCmd = &cobra.Command{
+	Use:   "cherry-pick [--no-ai] [--ai-help] [git cherry-pick options] <commit>...",
+	Short: "Apply commits from elsewhere with AI-rewritten messages and conflict help",
+	Long: `Passthrough to git cherry-pick. Each picked commit's message is rewritten by
+Solar LLM for the target branch (noting that it is a backport and what actually
+changed if the pick differs from the original), and you confirm it before the
+commit is amended. Commits are picked one at a time; with --ai-help, conflicts
+get per-hunk AI resolutions like 'sgit merge --ai-help'.
+
+--no-ai keeps git's messages. --no-commit, --abort, --skip and --quit are passed
+straight to git.
+
+Examples:
+  sgit cherry-pick a1b2c3d
+  sgit cherry-pick --ai-help -x main~3..main
+  sgit cherry-pick --continue`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCherryPick(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+	DisableFlagParsing: true,
+}
+
+func init() {
+	rootCmd.AddCommand(cherryPickCmd)
+}
+
+func runCherryPick(cmd *cobra.Command, args []string) error {
+	// Flag parsing is disabled so every git cherry-pick option passes through; pick out ours
+	noAI, aiHelp := false, false
+	var gitArgs []string
+	for _, arg := range extractGlobalFlags(args) {
+		switch arg {
+		case "--no-ai":
+			noAI = true
+		case "--ai-help":
+			aiHelp = true
+		default:
+			gitArgs = append(gitArgs, arg)
+		}
+	}
+
+	op := pickOperation{
+		name:        "cherry-pick",
+		headRef:     "CHERRY_PICK_HEAD",
+		oldestFirst: true,
+		conflictContext: func(commit, branch string) string {
+			return fmt.Sprintf(`This conflict happened while cherry-picking the commit "%s" onto the branch '%s'.
+"Ours" is the branch receiving the pick; "theirs" is the change from the picked commit.`, commit, branch)
+		},
+		message: func(ctx context.Context, client *solar.Client, sha, diff string) (string, error) {
+			original, err := runGitOutput("log", "-1", "--format=%B", "HEAD")
+			if err != nil {
+				return "", err
+			}
+			branch, _ := getCurrentBranch()
+			if branch == "" {
+				branch = "HEAD"
+			}
+			return client.GenerateCherryPickMessage(ctx, strings.TrimSpace(original), describePickSource(sha), branch, diff)
+		},
+	}
+
+	// cherry_pick_ai in config can turn the message rewrite off by default, or all AI help off
+	rewrite := useAIFor("cherry_pick", true, false, noAI)
+	aiHelp = aiHelp && !aiDisabled("cherry_pick", true)
+	return runPickCommits(cmd, op, gitArgs, rewrite, aiHelp)
+}
+
+// runPickCommits applies the commits named in gitArgs one at a time with git cherry-pick or
+// git revert, offering AI conflict help and rewriting each resulting commit's message
+func runPickCommits(cmd *cobra.Command, op pickOperation, gitArgs []string, rewrite, aiHelp bool) error {
+	var options, revs, amendOptions []string
+	continuing, passthrough := false, !rewrite && !aiHelp
+	for i := 0; i < len(gitArgs); i++ {
+		arg := gitArgs[i]
+		switch {
+		case arg == "--continue":
+			continuing = true
+		case arg == "-n" || arg == "--no-commit" || arg == "--abort" || arg == "--skip" || arg == "--quit":
+			passthrough = true
+		case arg == "--":
+		case strings.HasPrefix(arg, "-"):
+			options = append(options, arg)
+			if pickValueFlags[arg] && i+1 < len(gitArgs) {
+				i++
+				options = append(options, gitArgs[i])
+			}
+			// The amend that rewrites the message must keep sign-offs and signatures
+			if arg == "-s" || arg == "--signoff" || strings.HasPrefix(arg, "-S") ||
+				strings.HasPrefix(arg, "--gpg-sign") || arg == "--no-gpg-sign" {
+				amendOptions = append(amendOptions, arg)
+			}
+		default:
+			revs = append(revs, arg)
+		}
+	}
+
+	if passthrough || (!continuing && len(revs) == 0) {
+		executeGitCommand(append([]string{op.name}, gitArgs...))
+		return nil
+	}
+
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	var commits []string
+	if continuing {
+		sha, err := runGitOutput("rev-parse", "--verify", "--quiet", op.headRef)
+		if err != nil {
+			return fmt.Errorf("no %s in progress", op.name)
+		}
+		commits = []string{strings.TrimSpace(sha)}
+	} else {
+		resolved, err := resolvePickCommits(revs, op.oldestFirst)
+		if err != nil {
+			return err
+		}
+		commits = resolved
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("no commits to %s", op.name)
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+
+	var client *solar.Client
+	if rewrite {
+		var err error
+		client, err = newSolarClient()
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, sha := range commits {
+		var stepErr error
+		if continuing && i == 0 {
+			stepErr = runGitPickStep(op.name, "--continue")
+		} else {
+			stepErr = runGitPickStep(op.name, append(options, sha)...)
+		}
+
+		if stepErr != nil {
+			if !isPickInProgress(op) {
+				return fmt.Errorf("%s failed: %v", op.name, stepErr)
+			}
+			if !aiHelp || !resolvePickConflicts(cmd.Context(), op, sha) {
+				printPickInstructions(op, options, commits[i+1:])
+				return nil
+			}
+			if err := runGitPickStep(op.name, "--continue"); err != nil {
+				printPickInstructions(op, options, commits[i+1:])
+				return nil
+			}
+		}
+
+		if rewrite {
+			if err := rewritePickMessage(cmd.Context(), client, op, sha, amendOptions); isDryRun(err) {
+				return err
+			} else if err != nil {
+				fmt.Printf("⚠️  Could not rewrite the commit message: %v\n", err)
+			}
+		}
+	}
+
+	statusf("✅ %s finished (%d commit(s))\n", op.name, len(commits))
+	return nil
+}
+
+// resolvePickCommits expands revisions into full commit hashes. Ranges (A..B, ^A B) are
+// walked like git does; single revisions are kept in the order given.
+func resolvePickCommits(revs []string, oldestFirst bool) ([]string, error) {
+	isRange := false
+	for _, rev := range revs {
+		if strings.Contains(rev, "..") || strings.HasPrefix(rev, "^") {
+			isRange = true
+		}
+	}
+
+	if isRange {
+		args := []string{"rev-list"}
+		if oldestFirst {
+			args = append(args, "--reverse")
+		}
+		output, err := runGitOutput(append(args, revs...)...)
+		if err != nil {
+			return nil, fmt.Errorf("error listing commits: %w", err)
+		}
+		return strings.Fields(output), nil
+	}
+
+	var commits []string
+	for _, rev := range revs {
+		sha, err := runGitOutput("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+		if err != nil {
+			return nil, fmt.Errorf("unknown revision '%s'", rev)
+		}
+		commits = append(commits, strings.TrimSpace(sha))
+	}
+	return commits, nil
+}
+
+// runGitPickStep runs git cherry-pick or git revert, keeping git's message instead of
+// opening an editor; sgit rewrites it afterwards
+func runGitPickStep(name string, args ...string) error {
+	gitCmd := exec.Command("git", append([]string{name}, args...)...)
+	gitCmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	gitCmd.Stdin = os.Stdin
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	return gitCmd.Run()
+}
+
+// isPickInProgress reports whether git stopped in the middle of the operation
+func isPickInProgress(op pickOperation) bool {
+	_, err := runGitOutput("rev-parse", "--verify", "--quiet", op.headRef)
+	return err == nil
+}
+
+// resolvePickConflicts offers AI help for the conflicts of the stopped pick and reports
+// whether everything was resolved and the user wants to continue
+func resolvePickConflicts(ctx context.Context, op pickOperation, sha string) bool {
+	conflictFiles, err := getMergeConflicts()
+	if err != nil || len(conflictFiles) == 0 {
+		// Stopped for another reason (e.g. the pick became empty), leave it to the user
+		return false
+	}
+
+	commit := describeCommit(sha)
+	fmt.Printf("\n🚨 Conflicts while applying %s\n", commit)
+
+	branch, _ := getCurrentBranch()
+	if branch == "" {
+		branch = "HEAD"
+	}
+	resolved, err := assistConflicts(ctx, conflictFiles, op.conflictContext(commit, branch))
+	if err != nil {
+		fmt.Printf("Warning: Could not get AI assistance: %v\n", err)
+	}
+	if !resolved {
+		return false
+	}
+
+	return confirm(fmt.Sprintf("\nAll conflicts resolved. Continue the %s? (y/n): ", op.name))
+}
+
+// rewritePickMessage replaces the message of the commit the pick just created with an
+// AI-written one, after the user confirms it
+func rewritePickMessage(ctx context.Context, client *solar.Client, op pickOperation, sha string, amendOptions []string) error {
+	diff, err := runGitOutput("show", "--format=", "HEAD")
+	if err != nil {
+		return err
+	}
+
+	statusf("🤖 Writing the commit message for %s...\n", describeCommit(sha))
+	message, err := op.message(ctx, client, sha, diff)
+	if err != nil {
+		return err
+	}
+	message = formatCommitMessage(applyGitmoji(strings.TrimSpace(message)))
+	if message == "" {
+		return fmt.Errorf("the AI returned an empty message")
+	}
+	original, err := runGitOutput("log", "-1", "--format=%B", "HEAD")
+	if err != nil {
+		return err
+	}
+	message = keepPickReferences(message, original)
+
+	fmt.Printf("\n%s\n\n", message)
+	if !confirm("Use this commit message? (y/n): ") {
+		statusln("⏭️  Kept git's message")
+		return nil
+	}
+
+	messageFile, err := ioutil.TempFile(os.TempDir(), "sgit-pick-*.txt")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(messageFile.Name())
+
+	if _, err := messageFile.WriteString(message + "\n"); err != nil {
+		messageFile.Close()
+		return fmt.Errorf("failed to write to temp file: %w", err)
+	}
+	messageFile.Close()
+
+	args := append([]string{"commit", "--amend", "--allow-empty", "--quiet", "--cleanup=whitespace", "-F", messageFile.Name()}, amendOptions...)
+	gitCmd := exec.Command("git", args...)
+	gitCmd.Stdin = os.Stdin
+	gitCmd.Stderr = os.Stderr
+	if err := gitCmd.Run(); err != nil {
+		return fmt.Errorf("error amending commit: %w", err)
+	}
+	statusln("✅ Commit message updated")
+	return nil
+}
+
+// pickReferencePattern matches the lines git cherry-pick -x and git revert write to
+// name the original commit, including the second line of a merge revert's
+var pickReferencePattern = regexp.MustCompile(`(?i)^\s*(\(cherry[- ]picked from commit\b|this reverts commit\b|changes made to [0-9a-f]+)`)
+
+// keepPickReferences replaces the lines of message that name the picked or reverted
+// commit with the ones git wrote in original, so a hash the AI dropped or got wrong
+// never lands in the history. A revert's line ends the body, before the trailers, and
+// a cherry-pick's is the last line, as git puts them.
+func keepPickReferences(message, original string) string {
+	var kept []string
+	for _, line := range strings.Split(message, "\n") {
+		if !pickReferencePattern.MatchString(line) {
+			kept = append(kept, line)
+		}
+	}
+	message = strings.TrimSpace(strings.Join(kept, "\n"))
+	for strings.Contains(message, "\n\n\n") {
+		message = strings.ReplaceAll(message, "\n\n\n", "\n\n")
+	}
+
+	var reverts, picks []string
+	for _, line := range strings.Split(original, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case !pickReferencePattern.MatchString(line):
+		case strings.HasPrefix(line, "("):
+			picks = append(picks, line)
+		default:
+			reverts = append(reverts, line)
+		}
+	}
+
+	if len(reverts) > 0 {
+		body, trailers := splitTrailers(message)
+		message = body + "\n\n" + strings.Join(reverts, "\n")
+		if len(trailers) > 0 {
+			message += "\n\n" + strings.Join(trailers, "\n")
+		}
+	}
+	if len(picks) > 0 {
+		separator := "\n\n"
+		if _, trailers := splitTrailers(message); len(trailers) > 0 {
+			separator = "\n"
+		}
+		message += separator + strings.Join(picks, "\n")
+	}
+	return message
+}
+
+// describeCommit returns "<short sha> <subject>" of a commit
+func describeCommit(sha string) string {
+	output, err := runGitOutput("log", "-1", "--format=%h %s", sha)
+	if err != nil || strings.TrimSpace(output) == "" {
+		return sha
+	}
+	return strings.TrimSpace(output)
+}
+
+// describePickSource names where a picked commit comes from, e.g. "commit a1b2c3d (main~2)"
+func describePickSource(sha string) string {
+	short := sha
+	if len(short) > 7 {
+		short = short[:7]
+	}
+	name, err := runGitOutput("name-rev", "--name-only", "--no-undefined", sha)
+	if err != nil || strings.TrimSpace(name) == "" {
+		return "commit " + short
+	}
+	return fmt.Sprintf("commit %s (%s)", short, strings.TrimSpace(name))
+}
+
+func printPickInstructions(op pickOperation, options, remaining []string) {
+	fmt.Println("\nPlease resolve the remaining conflicts and then:")
+	fmt.Println("  git add <resolved-files>")
+	fmt.Printf("  sgit %s --continue\n", op.name)
+	fmt.Printf("Or skip this commit with 'sgit %s --skip', or abort with 'sgit %s --abort'\n", op.name, op.name)
+	if len(remaining) > 0 {
+		args := append([]string{op.name}, options...)
+		for _, sha := range remaining {
+			args = append(args, sha[:7])
+		}
+		fmt.Printf("\nNot applied yet, continue afterwards with: sgit %s\n", strings.Join(args, " "))
+	}
+}
@@ -0,0 +1,373 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+)
+
+// cleanDirScanLimit caps how many files of an untracked directory are counted
+const cleanDirScanLimit = 10000
+
+// cleanCmd wraps git clean, classifying untracked files with AI before deleting any
+var cleanCmd = &cobra.Command{
+	Use:   "clean [--ai] [git clean options] [-- <path>...]",
+	Short: "Remove untracked files, with AI help to tell junk from work",
+	Long: `Passthrough to git clean. With --ai, sgit lists the untracked files and directories
+(plus ignored ones with -x, or only ignored ones with -X), and Solar LLM sorts them
+into junk (build output, caches, logs), potentially important (untracked source,
+notes, local config) and unknown. You pick what to delete; nothing is removed
+without confirmation, and -n only shows the classification.
+
+With --yes, only the paths classified as junk are deleted.
+
+Examples:
+  sgit clean --ai
+  sgit clean --ai -x -- build/ tmp/
+  sgit clean --ai -n`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runClean(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+	DisableFlagParsing: true,
+}
+
+func init() {
+	rootCmd.AddCommand(cleanCmd)
+}
+
+// cleanEntry is an untracked path that git clean would remove
+type cleanEntry struct {
+	path        string
+	description string
+	verdict     solar.CleanVerdict
+}
+
+func runClean(cmd *cobra.Command, args []string) error {
+	// Flag parsing is disabled so every git clean option passes through; pick out ours
+	useAI, noAI := false, false
+	var gitArgs []string
+	for _, arg := range extractGlobalFlags(args) {
+		switch arg {
+		case "--ai":
+			useAI = true
+		case "--no-ai":
+			noAI = true
+		default:
+			gitArgs = append(gitArgs, arg)
+		}
+	}
+
+	if !useAIFor("clean", false, useAI, noAI) {
+		executeGitCommand(append([]string{"clean"}, gitArgs...))
+		return nil
+	}
+
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	// Keep the options that select paths; -f, -i and -q don't apply since sgit asks
+	var selectArgs, paths []string
+	dryRun := false
+	for i := 0; i < len(gitArgs); i++ {
+		arg := gitArgs[i]
+		switch {
+		case arg == "--":
+			paths = append(paths, gitArgs[i+1:]...)
+			i = len(gitArgs)
+		case arg == "--exclude":
+			if i+1 < len(gitArgs) {
+				i++
+				selectArgs = append(selectArgs, "-e", gitArgs[i])
+			}
+		case strings.HasPrefix(arg, "--exclude="):
+			selectArgs = append(selectArgs, arg)
+		case arg == "--dry-run":
+			dryRun = true
+		case arg == "--force" || arg == "--interactive" || arg == "--quiet":
+			// Replaced by sgit's confirmation
+		case strings.HasPrefix(arg, "--"):
+			return fmt.Errorf("unsupported option %s with --ai", arg)
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// Short options may be combined, as in -fdx
+			for j := 1; j < len(arg); j++ {
+				switch letter := arg[j]; letter {
+				case 'd', 'f', 'i', 'q':
+					// -d is always on; -f, -i and -q are replaced by sgit's confirmation
+				case 'x', 'X':
+					selectArgs = append(selectArgs, "-"+string(letter))
+				case 'n':
+					dryRun = true
+				case 'e':
+					// The rest of the argument, or the next one, is the pattern
+					if pattern := arg[j+1:]; pattern != "" {
+						selectArgs = append(selectArgs, "-e", pattern)
+					} else if i+1 < len(gitArgs) {
+						i++
+						selectArgs = append(selectArgs, "-e", gitArgs[i])
+					}
+					j = len(arg)
+				default:
+					return fmt.Errorf("unsupported option -%c with --ai", letter)
+				}
+			}
+		default:
+			paths = append(paths, arg)
+		}
+	}
+
+	listArgs := append([]string{"-c", "core.quotepath=off", "clean", "-n", "-d"}, selectArgs...)
+	if len(paths) > 0 {
+		listArgs = append(append(listArgs, "--"), paths...)
+	}
+	output, err := runGitOutput(listArgs...)
+	if err != nil {
+		return fmt.Errorf("error listing untracked files: %w", err)
+	}
+
+	var entries []*cleanEntry
+	for _, line := range strings.Split(output, "\n") {
+		if path := strings.TrimPrefix(line, "Would remove "); path != line && path != "" {
+			entries = append(entries, &cleanEntry{path: path, description: describeUntrackedPath(path)})
+		}
+	}
+	if len(entries) == 0 {
+		fmt.Println("✨ Nothing to clean")
+		return nil
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	samples := make([]solar.UntrackedPath, len(entries))
+	for i, entry := range entries {
+		samples[i] = solar.UntrackedPath{Path: entry.path, Description: entry.description}
+	}
+	projectFiles, _ := describeProjectFiles()
+
+	statusf("Classifying %d untracked path(s) with Solar LLM...\n", len(entries))
+	verdicts, err := client.ClassifyUntrackedPaths(cmd.Context(), projectFiles, samples)
+	if err != nil {
+		return fmt.Errorf("error classifying untracked files: %w", err)
+	}
+	for _, entry := range entries {
+		entry.verdict = verdicts[entry.path]
+		if entry.verdict.Class == "" {
+			entry.verdict = solar.CleanVerdict{Class: solar.CleanUnknown, Reason: "no classification"}
+		}
+	}
+
+	// Number the paths group by group so the selection matches what is shown
+	groups := []struct {
+		class string
+		title string
+	}{
+		{solar.CleanJunk, "🗑️  Junk (safe to delete)"},
+		{solar.CleanImportant, "⚠️  Potentially important"},
+		{solar.CleanUnknown, "❓ Unknown"},
+	}
+	var ordered []*cleanEntry
+	for _, group := range groups {
+		var members []*cleanEntry
+		for _, entry := range entries {
+			if entry.verdict.Class == group.class {
+				members = append(members, entry)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s:\n", group.title)
+		for _, entry := range members {
+			ordered = append(ordered, entry)
+			fmt.Printf("  %2d. %s (%s) - %s\n", len(ordered), entry.path, strings.SplitN(entry.description, "\n", 2)[0], entry.verdict.Reason)
+		}
+	}
+	fmt.Println()
+
+	if dryRun {
+		fmt.Println("💡 Dry run, nothing deleted. Run without -n to choose what to delete")
+		return nil
+	}
+
+	answer := ask("Delete which paths? (Enter = all junk, numbers like 1,3-5, 'all', or 'none'): ")
+	selected, err := selectCleanEntries(answer, ordered)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		fmt.Println("Nothing deleted")
+		return nil
+	}
+
+	var toDelete []string
+	important := 0
+	for _, entry := range selected {
+		toDelete = append(toDelete, entry.path)
+		if entry.verdict.Class != solar.CleanJunk {
+			important++
+		}
+	}
+	question := fmt.Sprintf("Permanently delete %d path(s)? (y/n): ", len(toDelete))
+	if important > 0 {
+		question = fmt.Sprintf("Permanently delete %d path(s), including %d that may be important? (y/n): ", len(toDelete), important)
+	}
+	if !confirm(question) {
+		fmt.Println("Nothing deleted")
+		return nil
+	}
+
+	// git clean only ever removes untracked files, even if a path changed meanwhile. The
+	// paths are literal, so a file named *.log doesn't take other files with it.
+	deleteArgs := append([]string{"--literal-pathspecs", "clean", "-f", "-d"}, selectArgs...)
+	gitCmd := exec.Command("git", append(append(deleteArgs, "--"), toDelete...)...)
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	if err := gitCmd.Run(); err != nil {
+		return fmt.Errorf("git clean failed: %w", err)
+	}
+	fmt.Printf("✅ Deleted %d path(s)\n", len(toDelete))
+	return nil
+}
+
+// selectCleanEntries parses a selection: "" for the junk entries, "all", "none", or
+// numbers and ranges like "1,3-5"
+func selectCleanEntries(answer string, entries []*cleanEntry) ([]*cleanEntry, error) {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	switch answer {
+	case "":
+		var junk []*cleanEntry
+		for _, entry := range entries {
+			if entry.verdict.Class == solar.CleanJunk {
+				junk = append(junk, entry)
+			}
+		}
+		return junk, nil
+	case "all":
+		return entries, nil
+	case "none", "n", "q":
+		return nil, nil
+	}
+
+	picked := make(map[int]bool)
+	for _, part := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(from)
+		end := start
+		if err == nil && isRange {
+			end, err = strconv.Atoi(to)
+		}
+		if err != nil || start < 1 || end > len(entries) || start > end {
+			return nil, fmt.Errorf("invalid selection '%s' (use numbers 1-%d)", part, len(entries))
+		}
+		for n := start; n <= end; n++ {
+			picked[n] = true
+		}
+	}
+
+	numbers := make([]int, 0, len(picked))
+	for n := range picked {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	selected := make([]*cleanEntry, len(numbers))
+	for i, n := range numbers {
+		selected[i] = entries[n-1]
+	}
+	return selected, nil
+}
+
+// describeUntrackedPath summarizes an untracked file or directory for classification:
+// its size and age on the first line, then for a directory a few of its file names
+func describeUntrackedPath(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "unreadable"
+	}
+	age := formatAge(time.Since(info.ModTime()))
+
+	// Only names, sizes and ages are described: file contents never leave the machine,
+	// since untracked and ignored files are where .env files and keys live
+	if !info.IsDir() {
+		return fmt.Sprintf("file, %s, modified %s", formatSize(info.Size()), age)
+	}
+
+	var files int
+	var size int64
+	var names []string
+	latest := info.ModTime()
+	filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if files >= cleanDirScanLimit {
+			return filepath.SkipAll
+		}
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		files++
+		if fileInfo, err := d.Info(); err == nil {
+			size += fileInfo.Size()
+			if fileInfo.ModTime().After(latest) {
+				latest = fileInfo.ModTime()
+			}
+		}
+		if len(names) < 8 {
+			rel, _ := filepath.Rel(path, p)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+
+	count := strconv.Itoa(files)
+	if files >= cleanDirScanLimit {
+		count += "+"
+	}
+	summary := fmt.Sprintf("directory, %s files, %s, last modified %s", count, formatSize(size), formatAge(time.Since(latest)))
+	if len(names) > 0 {
+		summary += "\ncontains: " + strings.Join(names, ", ")
+	}
+	return summary
+}
+
+// formatSize renders a byte count as B, KB, MB or GB
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
+
+// formatAge renders how long ago something happened, e.g. "3 days ago"
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Hour:
+		return "just now"
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%d hours ago", int(age.Hours()))
+	case age < 60*24*time.Hour:
+		return fmt.Sprintf("%d days ago", int(age.Hours()/24))
+	}
+	return fmt.Sprintf("%d months ago", int(age.Hours()/24/30))
+}
@@ -0,0 +1,443 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hunkim/sgit/pkg/difffilter"
+	"github.com/hunkim/sgit/pkg/i18n"
+	"github.com/hunkim/sgit/pkg/progress"
+	"github.com/hunkim/sgit/pkg/redact"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/viper"
+)
+
+// newSolarClient creates a Solar LLM client from the current configuration
+func newSolarClient() (*solar.Client, error) {
+	apiKey := configuredAPIKey()
+
+	// The command's profile or commands.<name>.model may pick another model
+	if profile := activeProfile(); profile != "" && !viper.IsSet("profiles."+profile) {
+		return nil, fmt.Errorf("profile '%s' is not defined under profiles in config", profile)
+	}
+	var modelName string
+	if key := commandSettingKey("model", "upstage_model_name"); key != "" {
+		modelName = viper.GetString(key)
+	}
+
+	// provider and base_url select the API; anything OpenAI-compatible works
+	provider := strings.ToLower(strings.TrimSpace(viper.GetString("provider")))
+	baseURL, err := solar.ProviderBaseURL(provider)
+	if err != nil {
+		return nil, err
+	}
+	if custom := viper.GetString("base_url"); custom != "" {
+		baseURL = custom
+	}
+	// Azure deployments pick the model themselves
+	if modelName == "" && provider != "" && provider != solar.ProviderUpstage && (provider != solar.ProviderAzure || !viper.IsSet("azure.deployment")) {
+		return nil, fmt.Errorf("provider '%s' needs a model name (set upstage_model_name or SGIT_MODEL)", provider)
+	}
+
+	client := solar.NewClient(apiKey, modelName, getEffectiveLanguage())
+	client.SetBaseURL(baseURL)
+	client.SetContextWindow(modelContextWindow(configuredModel()))
+	if err := configureProviderAuth(client, provider, modelName); err != nil {
+		return nil, err
+	}
+
+	convention := viper.GetString("convention")
+	var customGuidelines string
+	if strings.ToLower(strings.TrimSpace(convention)) == "custom" {
+		path := viper.GetString("convention_template")
+		if path == "" {
+			return nil, fmt.Errorf("convention is 'custom' but convention_template is not set in config")
+		}
+		content, err := os.ReadFile(expandHome(path))
+		if err != nil {
+			return nil, fmt.Errorf("error reading convention template: %w", err)
+		}
+		customGuidelines = string(content)
+	}
+
+	if err := client.SetConvention(convention, customGuidelines); err != nil {
+		return nil, err
+	}
+
+	client.SetGitmoji(viper.GetBool("gitmoji"))
+	client.SetCommitTypes(configuredCommitTypes())
+	client.SetCommitScopes(configuredCommitScopes())
+
+	// Retry transient API failures unless disabled with --no-retry
+	maxAttempts := solar.DefaultMaxAttempts
+	if viper.IsSet("max_retry_attempts") {
+		maxAttempts = viper.GetInt("max_retry_attempts")
+	}
+	if noRetry {
+		maxAttempts = 1
+	}
+	client.SetRetryPolicy(maxAttempts, 0)
+	client.SetRetryNotifier(printRetryNotice)
+	client.SetActivityFunc(func() func() {
+		return progress.Begin(i18n.T("Waiting for Solar LLM...")).End
+	})
+
+	client.SetGenerationOptions(generationOptions())
+	client.SetDiffFilter(diffFilterOptions(), printDiffFilterNotice)
+
+	// redact: true masks emails, IP addresses, credentials and redact_patterns in
+	// everything sent to the model
+	if viper.GetBool("redact") {
+		redactor, err := redact.New(viper.GetStringSlice("redact_patterns"))
+		if err != nil {
+			return nil, err
+		}
+		client.SetRedactor(redactor, printRedactionNotice)
+	}
+	client.SetStreamLimits(viper.GetInt("stream_buffer_size"), viper.GetInt("max_stream_event_size"))
+
+	// Prompt templates in the prompt directory replace the built-in ones by name
+	promptDir, err := promptDirectory()
+	if err != nil {
+		return nil, err
+	}
+	client.SetPromptDir(promptDir)
+	client.SetTemplateVars(templateVars(branchTicketKey()))
+
+	// --show-prompt prints the client's first request instead of sending it; every
+	// request then fails with solar.ErrDryRun, which ends the command cleanly
+	if showPrompt {
+		var once sync.Once
+		client.SetDryRun(func(request solar.ChatRequest) {
+			once.Do(func() { printPrompt(request) })
+		})
+	}
+
+	// Reuse responses for identical prompts unless disabled with --no-cache or cache: false
+	if cacheEnabled() {
+		cache, err := newResponseCache()
+		if err != nil {
+			return nil, err
+		}
+		client.SetCache(cache)
+	}
+
+	return client, nil
+}
+
+// promptOutput is where --show-prompt prints prompts; the servers whose stdout carries
+// their protocol move it to stderr
+var promptOutput io.Writer = os.Stdout
+
+// printPrompt prints a request exactly as it would be sent, for --show-prompt
+func printPrompt(request solar.ChatRequest) {
+	progress.Clear()
+	counter := solar.NewTokenCounter()
+	total := 0
+	for _, message := range request.Messages {
+		total += counter.EstimateTokens(message.Content)
+	}
+	fmt.Fprintf(promptOutput, "\n🔎 Prompt for %s (~%d tokens, not sent)\n", request.Model, total)
+	for _, message := range request.Messages {
+		fmt.Fprintf(promptOutput, "\n=== %s ===\n%s\n", strings.ToUpper(message.Role), message.Content)
+	}
+}
+
+// isDryRun reports whether err comes from a request --show-prompt printed instead of
+// sending. Callers that otherwise carry on without the AI stop, since what a command
+// does next depends on the response it never gets.
+func isDryRun(err error) bool {
+	return errors.Is(err, solar.ErrDryRun)
+}
+
+// apiKeySetting is where the config file keeps the API key, upstage_api_key before
+// config version 1
+const apiKeySetting = "providers.upstage.api_key"
+
+// upstageAPIKey returns the API key set with providers.upstage.api_key (SGIT_API_KEY),
+// or with upstage_api_key in a config file sgit couldn't upgrade, e.g. a JSON one
+func upstageAPIKey() string {
+	if apiKey := viper.GetString(apiKeySetting); apiKey != "" {
+		return apiKey
+	}
+	return viper.GetString("upstage_api_key")
+}
+
+// configuredAPIKey returns the API key: providers.upstage.api_key (SGIT_API_KEY), or
+// for Azure and Bedrock the key in their own environment variable
+func configuredAPIKey() string {
+	if apiKey := upstageAPIKey(); apiKey != "" {
+		return apiKey
+	}
+	switch strings.ToLower(strings.TrimSpace(viper.GetString("provider"))) {
+	case solar.ProviderAzure:
+		return os.Getenv("AZURE_OPENAI_API_KEY")
+	case solar.ProviderBedrock:
+		return os.Getenv("AWS_BEARER_TOKEN_BEDROCK")
+	}
+	return ""
+}
+
+// aiConfigured reports whether API requests can be authenticated: with an API key,
+// or for Bedrock with the AWS credentials requests are signed with
+func aiConfigured() bool {
+	return configuredAPIKey() != "" || strings.EqualFold(strings.TrimSpace(viper.GetString("provider")), solar.ProviderBedrock)
+}
+
+// configureProviderAuth points the client at Azure OpenAI or Bedrock, which don't use
+// an OpenAI-style base URL and bearer key. Other providers need nothing more.
+//
+//	azure:
+//	  endpoint: https://my-resource.openai.azure.com   # or base_url, AZURE_OPENAI_ENDPOINT
+//	  deployment: gpt-4o-mini                          # default: the model name
+//	  api_version: 2024-10-21
+//	bedrock:
+//	  region: us-east-1          # default: AWS_REGION or the profile's region
+//	  profile: work              # ~/.aws/credentials profile (default: AWS_PROFILE or default)
+//	  endpoint: https://...      # e.g. a VPC endpoint
+func configureProviderAuth(client *solar.Client, provider, modelName string) error {
+	switch provider {
+	case solar.ProviderAzure:
+		endpoint := viper.GetString("azure.endpoint")
+		if endpoint == "" {
+			endpoint = viper.GetString("base_url")
+		}
+		deployment := viper.GetString("azure.deployment")
+		if deployment == "" {
+			deployment = modelName
+		}
+		return client.UseAzure(solar.AzureConfig{
+			Endpoint:   endpoint,
+			Deployment: deployment,
+			APIVersion: viper.GetString("azure.api_version"),
+			ADToken:    viper.GetString("azure.ad_token"),
+		})
+
+	case solar.ProviderBedrock:
+		profile := viper.GetString("bedrock.profile")
+		region := viper.GetString("bedrock.region")
+		if region == "" {
+			region = solar.LoadAWSRegion(profile)
+		}
+		// Without AWS credentials, a Bedrock API key is used when there is one
+		credentials, err := solar.LoadAWSCredentials(profile)
+		if err != nil && configuredAPIKey() == "" {
+			return fmt.Errorf("bedrock needs AWS credentials: %w", err)
+		}
+		return client.UseBedrock(solar.BedrockConfig{
+			Region:      region,
+			Credentials: credentials,
+			Endpoint:    viper.GetString("bedrock.endpoint"),
+		})
+	}
+	return nil
+}
+
+// activeProfile returns the model profile of the running command: commands.<name>.profile,
+// or the global profile (also SGIT_PROFILE)
+func activeProfile() string {
+	if activeCommand != "" {
+		if profile := viper.GetString("commands." + activeCommand + ".profile"); profile != "" {
+			return profile
+		}
+	}
+	return viper.GetString("profile")
+}
+
+// commandSettingKey returns the config key a generation setting is read from for the
+// running command: commands.<name>.<key> first, then profiles.<profile>.<key> of its
+// profile, then globalKey. It returns "" when none of them is set.
+func commandSettingKey(key, globalKey string) string {
+	if activeCommand != "" {
+		if commandKey := "commands." + activeCommand + "." + key; viper.IsSet(commandKey) {
+			return commandKey
+		}
+	}
+	if profile := activeProfile(); profile != "" {
+		if profileKey := "profiles." + profile + "." + key; viper.IsSet(profileKey) {
+			return profileKey
+		}
+	}
+	if viper.IsSet(globalKey) {
+		return globalKey
+	}
+	return ""
+}
+
+// generationOptions reads the system prompt, sampling and reasoning settings. Global
+// keys (system_prompt, temperature, top_p, max_tokens, reasoning_effort) can be
+// overridden by a profile under profiles.<profile> and per command under
+// commands.<name>, e.g. commands.commit.temperature.
+func generationOptions() solar.GenerationOptions {
+	lookup := func(key string) string {
+		return commandSettingKey(key, key)
+	}
+
+	var options solar.GenerationOptions
+	if key := lookup("system_prompt"); key != "" {
+		options.SystemPrompt = viper.GetString(key)
+	}
+	if key := lookup("temperature"); key != "" {
+		temperature := viper.GetFloat64(key)
+		options.Temperature = &temperature
+	}
+	if key := lookup("top_p"); key != "" {
+		topP := viper.GetFloat64(key)
+		options.TopP = &topP
+	}
+	if key := lookup("max_tokens"); key != "" {
+		options.MaxTokens = viper.GetInt(key)
+	}
+	if key := lookup("reasoning_effort"); key != "" {
+		effort, err := solar.NormalizeReasoningEffort(viper.GetString(key))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v in %s\n", err, key)
+		}
+		options.ReasoningEffort = effort
+	}
+	return options
+}
+
+// diffFilterOptions reads which noise is left out of the diffs sent to the model, or
+// returns nil when diff_filter.enabled is false:
+//
+//	diff_filter:
+//	  exclude: ["*.snap", "fixtures/**"]   # on top of lock files, generated and vendored code
+//	  keep: ["go.sum"]                     # send these anyway
+//	  keep_whitespace: true                # don't drop whitespace-only hunks
+func diffFilterOptions() *difffilter.Options {
+	if viper.IsSet("diff_filter.enabled") && !viper.GetBool("diff_filter.enabled") {
+		return nil
+	}
+	return &difffilter.Options{
+		Exclude:        viper.GetStringSlice("diff_filter.exclude"),
+		Keep:           viper.GetStringSlice("diff_filter.keep"),
+		KeepWhitespace: viper.GetBool("diff_filter.keep_whitespace"),
+	}
+}
+
+// printRedactionNotice reports what was masked in a request before it was sent
+func printRedactionNotice(report redact.Report) {
+	statusf("%s", i18n.T("🔒 Redacted before sending: %s\n", report))
+}
+
+// printDiffFilterNotice tells the user which files were left out of the AI prompt
+func printDiffFilterNotice(result difffilter.Result) {
+	var files []string
+	for _, omitted := range result.Omitted {
+		files = append(files, fmt.Sprintf("%s (%s)", omitted.Path, omitted.Reason))
+	}
+	if len(files) > 0 {
+		statusf("%s", i18n.T("🧹 Left out of the AI prompt: %s\n", strings.Join(files, ", ")))
+	}
+	if result.WhitespaceHunks > 0 {
+		statusf("%s", i18n.T("🧹 Left out %d whitespace-only hunk(s)\n", result.WhitespaceHunks))
+	}
+}
+
+// promptDirectory returns where prompt template overrides are read from (prompts_dir)
+func promptDirectory() (string, error) {
+	if dir := viper.GetString("prompts_dir"); dir != "" {
+		return expandHome(dir), nil
+	}
+	return solar.DefaultPromptDir()
+}
+
+// cacheEnabled reports whether AI responses should be cached
+func cacheEnabled() bool {
+	if noCache {
+		return false
+	}
+	if viper.IsSet("cache") {
+		return viper.GetBool("cache")
+	}
+	return true
+}
+
+// newResponseCache creates the response cache from config (cache_dir, cache_ttl)
+func newResponseCache() (*solar.Cache, error) {
+	dir := viper.GetString("cache_dir")
+	if dir == "" {
+		defaultDir, err := solar.DefaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = defaultDir
+	}
+
+	var ttl time.Duration
+	if value := viper.GetString("cache_ttl"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache_ttl '%s': %v", value, err)
+		}
+		ttl = parsed
+	}
+
+	return solar.NewCache(expandHome(dir), ttl), nil
+}
+
+// expandHome expands a leading ~ in a path to the user's home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
+// aiSetting returns the config key and value of a command's AI default: <command>_ai,
+// or ai when that isn't set
+func aiSetting(command string) (string, string) {
+	key := command + "_ai"
+	if !viper.IsSet(key) {
+		key = "ai"
+	}
+	return key, strings.ToLower(strings.TrimSpace(viper.GetString(key)))
+}
+
+// aiDisabled reports whether AI is turned off for a command with <command>_ai: never,
+// warning when the user asked for it anyway
+func aiDisabled(command string, requested bool) bool {
+	key, mode := aiSetting(command)
+	if mode != "never" {
+		return false
+	}
+	if requested {
+		fmt.Fprintf(os.Stderr, "⚠️  AI is disabled for sgit %s (%s: never)\n", command, key)
+	}
+	return true
+}
+
+// useAIFor decides whether a command calls the AI. <command>_ai in config (or ai for
+// every command) sets its default: true (AI unless --no-ai), false (plain git unless
+// --ai) or never (plain git even with --ai, e.g. for code that must not leave the
+// machine). byDefault is the command's built-in default; forceAI and noAI are its
+// --ai and --no-ai flags.
+func useAIFor(command string, byDefault, forceAI, noAI bool) bool {
+	if aiDisabled(command, forceAI) {
+		return false
+	}
+
+	switch key, mode := aiSetting(command); mode {
+	case "true", "yes", "on", "always":
+		byDefault = true
+	case "false", "no", "off":
+		byDefault = false
+	case "":
+	default:
+		fmt.Fprintf(os.Stderr, "Warning: invalid %s '%s' (use true, false or never)\n", key, mode)
+	}
+
+	if noAI {
+		return false
+	}
+	return forceAI || byDefault
+}
@@ -2,13 +2,23 @@ package cmd
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"strings"
-
+	"sync"
+
+	"github.com/hunkim/sgit/pkg/engine"
+	"github.com/hunkim/sgit/pkg/gitmoji"
+	"github.com/hunkim/sgit/pkg/history"
+	"github.com/hunkim/sgit/pkg/i18n"
+	"github.com/hunkim/sgit/pkg/lint"
+	"github.com/hunkim/sgit/pkg/progress"
 	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/hunkim/sgit/pkg/tui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -20,8 +30,48 @@ var (
 	interactive  bool
 	skipEditor   bool
 	useAI        bool
+	commitAllowSecrets bool
+	commitAllowLeftovers bool
+	commitTUI    bool
+	commitType   string
+	commitScope  string
+	commitScopeDir string
+	commitHint   string
+	commitGitmoji bool
+	commitTicket   string
+	commitNoTicket bool
+	commitRefine   bool
+	commitSuggestTests bool
+	commitReuseLast    bool
+	commitCritique     bool
+	commitIncludeUnstaged bool
+	commitRequireTests    bool
 )
 
+// commitOnlyFlags are sgit's own commit flags, which are never passed through to git
+var commitOnlyFlags = map[string]bool{
+	"no-ai":         true,
+	"interactive":   true,
+	"skip-editor":   true,
+	"ai":            true,
+	"allow-secrets": true,
+	"allow-leftovers": true,
+	"tui":           true,
+	"type":          true,
+	"scope":         true,
+	"hint":          true,
+	"gitmoji":       true,
+	"ticket":        true,
+	"no-ticket":     true,
+	"refine":        true,
+	"suggest-tests": true,
+	"scope-dir":     true,
+	"reuse-last":    true,
+	"critique":      true,
+	"include-unstaged": true,
+	"require-tests":    true,
+}
+
 // commitCmd represents the commit command
 var commitCmd = &cobra.Command{
 	Use:   "commit",
@@ -30,8 +80,7 @@ var commitCmd = &cobra.Command{
 but supports all git commit options for full compatibility.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runCommit(cmd, args); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 	DisableFlagParsing: false,
@@ -45,6 +94,22 @@ func init() {
 	commitCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "review and edit AI-generated message in terminal")
 	commitCmd.Flags().BoolVar(&skipEditor, "skip-editor", false, "skip editor and use AI message directly")
 	commitCmd.Flags().BoolVar(&useAI, "ai", false, "force AI generation even with other git flags")
+	commitCmd.Flags().BoolVar(&commitAllowSecrets, "allow-secrets", false, "commit even if the secret scanner finds potential secrets")
+	commitCmd.Flags().BoolVar(&commitAllowLeftovers, "allow-leftovers", false, "don't check the staged changes for leftover debug code")
+	commitCmd.Flags().BoolVar(&commitTUI, "tui", false, "review the AI message in an interactive terminal UI")
+	commitCmd.Flags().StringVar(&commitType, "type", "", "commit type the AI message should use (e.g. fix, feat)")
+	commitCmd.Flags().StringVar(&commitScope, "scope", "", "commit scope the AI message should use (e.g. auth)")
+	commitCmd.Flags().StringVar(&commitScopeDir, "scope-dir", "", "monorepo subproject the commit belongs to (default: detected from scope_dirs)")
+	commitCmd.Flags().StringVar(&commitHint, "hint", "", "describe the change's intent to guide the AI message")
+	commitCmd.Flags().BoolVar(&commitGitmoji, "gitmoji", false, "prefix the AI message with the gitmoji mapped from the change type")
+	commitCmd.Flags().StringVar(&commitTicket, "ticket", "", "ticket key the change belongs to (default: detected from the branch name)")
+	commitCmd.Flags().BoolVar(&commitNoTicket, "no-ticket", false, "don't look up a ticket or add a ticket footer")
+	commitCmd.Flags().BoolVar(&commitRefine, "refine", false, "give feedback on the AI message and have it revised until you accept it")
+	commitCmd.Flags().BoolVar(&commitCritique, "critique", false, "score the AI message against the diff and regenerate it if the score is low")
+	commitCmd.Flags().BoolVar(&commitReuseLast, "reuse-last", false, "commit with the last generated message again (see 'sgit history')")
+	commitCmd.Flags().BoolVar(&commitSuggestTests, "suggest-tests", false, "suggest tests for the changes once they are committed")
+	commitCmd.Flags().BoolVar(&commitIncludeUnstaged, "include-unstaged", false, "propose which unstaged and untracked files belong in the commit and stage them after confirmation")
+	commitCmd.Flags().BoolVar(&commitRequireTests, "require-tests", false, "don't commit when test_command fails")
 	
 	// Standard git commit flags - we'll pass these through to git
 	commitCmd.Flags().StringVarP(&commitMessage, "message", "m", "", "commit message")
@@ -64,6 +129,14 @@ func init() {
 	commitCmd.Flags().String("template", "", "use specified template file")
 	commitCmd.Flags().Bool("edit", false, "force edit of commit message")
 	commitCmd.Flags().Bool("no-edit", false, "don't edit commit message")
+	addSigningFlags(commitCmd)
+
+	// sgit amend takes the same options; its --amend is implied
+	commitCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		copied := *flag
+		amendCmd.Flags().AddFlag(&copied)
+	})
+	amendCmd.Flags().MarkHidden("amend")
 }
 
 func runCommit(cmd *cobra.Command, args []string) error {
@@ -72,36 +145,105 @@ func runCommit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not a git repository")
 	}
 
+	if !commitSuggestTests {
+		return commitChanges(cmd, args)
+	}
+
+	// Suggest tests only when a commit was actually made, not when it was cancelled
+	before, _ := runGitOutput("rev-parse", "--verify", "-q", "HEAD")
+	if err := commitChanges(cmd, args); err != nil {
+		return err
+	}
+	after, _ := runGitOutput("rev-parse", "--verify", "-q", "HEAD")
+	if after == "" || after == before {
+		return nil
+	}
+	fmt.Println()
+	return suggestTests(cmd.Context(), "HEAD")
+}
+
+// commitChanges makes the commit, with an AI message unless disabled
+func commitChanges(cmd *cobra.Command, args []string) error {
 	// Handle -a flag: stage all modified and deleted files first
 	if cmd.Flags().Changed("all") {
 		allFlag, _ := cmd.Flags().GetBool("all")
 		if allFlag {
-			fmt.Println("Staging all modified and deleted files...")
+			fmt.Println(i18n.T("Staging all modified and deleted files..."))
 			stageCmd := exec.Command("git", "add", "-u")
 			if err := stageCmd.Run(); err != nil {
-				return fmt.Errorf("error staging files with -a: %v", err)
+				return fmt.Errorf("error staging files with -a: %w", err)
 			}
 		}
 	}
 
+	// commit_ai in config can turn AI off by default (--ai brings it back) or entirely
+	aiEnabled := commitMessage == "" && useAIFor("commit", true, useAI, skipLLM)
+
+	// --include-unstaged stages the unstaged changes that belong in the commit first
+	if commitIncludeUnstaged {
+		if err := planUnstagedChanges(cmd.Context(), aiEnabled); err != nil {
+			return err
+		}
+	}
+
+	// Block commits that would leak credentials, unless explicitly overridden.
+	// The AI judgment pass is only used when AI is enabled and configured.
+	if !commitAllowSecrets {
+		useAIForSecrets := aiEnabled && aiConfigured()
+		if err := checkStagedSecrets(cmd.Context(), useAIForSecrets); err != nil {
+			return err
+		}
+	}
+
+	// Debug prints, FIXMEs and the like are pointed out while they are easy to unstage
+	if !commitAllowLeftovers {
+		if err := checkStagedLeftovers(); err != nil {
+			return err
+		}
+	}
+
+	// Find out before generating a message whether the commit can be signed
+	if err := checkCommitSigning(cmd); err != nil {
+		return err
+	}
+
+	if commitReuseLast {
+		return commitWithLastMessage(cmd)
+	}
+
 	// Only bypass AI in these specific cases:
 	// 1. User provided explicit message with -m
-	// 2. User explicitly disabled AI with --no-ai
-	if commitMessage != "" || skipLLM {
+	// 2. User explicitly disabled AI with --no-ai, or commit_ai disabled it
+	// 3. User is amending and keeping the current message with --no-edit
+	amend, _ := cmd.Flags().GetBool("amend")
+	noEdit, _ := cmd.Flags().GetBool("no-edit")
+	if !aiEnabled || (amend && noEdit) {
 		return executeGitCommitPassthrough(cmd, args)
 	}
 
 	// AI-enhanced commit logic for ALL other cases
 	// Even with flags like --amend, --verbose, --signoff, etc.
 	
-	// Check for staged changes (required for AI generation)
-	hasChanges, err := hasUncommittedChanges()
-	if err != nil {
-		return fmt.Errorf("error checking for changes: %v", err)
-	}
-	if !hasChanges {
-		fmt.Println("No changes to commit")
-		return nil
+	// When amending, the message covers the last commit's changes plus the staged
+	// ones, so nothing needs to be staged
+	var amendFrom, amendedMessage string
+	if amend {
+		base, err := amendBase()
+		if err != nil {
+			return err
+		}
+		amendFrom = base
+		amendedMessage, _ = runGitOutput("log", "-1", "--format=%B", "HEAD")
+	} else {
+		// Check for staged changes (required for AI generation)
+		hasChanges, err := hasUncommittedChanges()
+		if err != nil {
+			return fmt.Errorf("error checking for changes: %w", err)
+		}
+		if !hasChanges {
+			fmt.Println(i18n.T("No changes to commit"))
+			return nil
+		}
 	}
 
 	// Check configuration and setup if needed
@@ -109,44 +251,221 @@ func runCommit(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Get git diff
-	diff, err := getGitDiff()
+	// Generate commit message using Solar LLM
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+	// In a monorepo, the subproject narrows the history and names the scope
+	scopeDirPath, mappedScope, err := resolveScopeDir(cmd.Context(), commitScopeDir)
+	if err != nil {
+		return err
+	}
+	if scopeDirPath != "" {
+		if commitScope == "" {
+			commitScope = mappedScope
+		}
+		if commitScope != "" {
+			statusf("%s", i18n.T("📦 Subproject %s (scope: %s)\n", scopeDirPath, commitScope))
+		} else {
+			statusf("%s", i18n.T("📦 Subproject %s\n", scopeDirPath))
+		}
+	}
+
+	// Gather the context for the message: the diff, branch, recent commits, files,
+	// ticket and whatever else the context providers add
+	var tests testRun
+	providers := commitContextProviders(commitContextOptions{amendFrom: amendFrom, scopeDir: scopeDirPath, ticket: commitTicket, tests: &tests})
+	if commitNoTicket {
+		providers.Disable(contextTicket)
+	}
+	gathering := progress.Begin(i18n.T("Gathering context"))
+	commitContext, err := providers.Collect(cmd.Context())
+	gathering.End()
 	if err != nil {
-		return fmt.Errorf("error getting git diff: %v", err)
+		return err
+	}
+	diff, branch, recentCommits, fileList := commitContext.Diff, commitContext.Branch, commitContext.RecentCommits, commitContext.FileList
+	if err := checkTestRun(&tests, commitRequireTests || viper.GetBool("require_tests")); err != nil {
+		return err
 	}
 
 	if strings.TrimSpace(diff) == "" {
-		return fmt.Errorf("no diff found - make sure to add files with 'git add' first")
+		if amend {
+			return fmt.Errorf("the amended commit would have no changes - use --no-ai to amend it anyway")
+		}
+		return fmt.Errorf("no diff found - make sure to add files with 'git add' first, or use --include-unstaged")
 	}
 
-	// Generate commit message using Solar LLM
-	apiKey := viper.GetString("upstage_api_key")
-	modelName := viper.GetString("upstage_model_name")
-	
-	client := solar.NewClient(apiKey, modelName, getEffectiveLanguage())
-	
-	fmt.Println("Generating comprehensive commit message with Solar LLM...")
+	hints := commitContext.Hints
+	hints.Type, hints.Scope, hints.Hint, hints.AmendedMessage = commitType, commitScope, commitHint, amendedMessage
+	if hints.APIChanges != "" {
+		statusln("🔍 The staged changes remove or change public API symbols (see 'sgit breaking --staged')")
+	}
+	ticketKey := hints.TicketKey
+	if hints.TicketTitle != "" {
+		statusf("🎫 %s: %s\n", hints.TicketKey, hints.TicketTitle)
+	}
+	client.SetCommitHints(hints)
+	client.SetContextSections(commitContext.Sections)
+	client.SetTemplateVars(templateVars(ticketKey))
+	if commitGitmoji {
+		client.SetGitmoji(true)
+	}
 	
-	// Gather additional context for comprehensive commit message
-	branch, _ := getCurrentBranch()
-	recentCommits, _ := getRecentCommits(5)
-	fileList, _ := getEnhancedFileList() // Use enhanced file list with content previews
+	if amend {
+		statusln(i18n.T("Regenerating the message for the last commit and the staged changes with Solar LLM..."))
+	} else {
+		statusln(i18n.T("Generating comprehensive commit message with Solar LLM..."))
+	}
 	
-	// Use comprehensive commit message generation with streaming
-	generatedMessage, err := client.GenerateComprehensiveCommitMessageStream(diff, branch, recentCommits, fileList)
+	// An unexpectedly large diff is caught before anything is sent
+	if diff, err = checkDiffSize(diff); err != nil {
+		return err
+	}
+
+	// The prompt of --show-prompt is printed on the normal screen, not the TUI's
+	if commitTUI && !showPrompt {
+		return runCommitTUI(cmd, client, diff, branch, recentCommits, fileList, ticketKey, &tests)
+	}
 	
+	printContentStats("Content analysis", diff, branch, recentCommits, fileList)
+
+	// Use comprehensive commit message generation with streaming, regenerating
+	// messages that don't pass validation
+	generate := func() (string, error) {
+		// r while the message streams starts it over, q stops it
+		message, err := streamWithKeys(cmd.Context(), func(ctx context.Context) (string, error) {
+			printer := newStreamPrinter(i18n.T("Generated commit message: "))
+			message, err := client.GenerateComprehensiveCommitMessageStream(ctx, diff, branch, recentCommits, fileList, printer.Write)
+			printer.Done()
+			return message, err
+		}, func() { useRegenerateSettings(client) })
+		if err != nil {
+			return "", err
+		}
+		if fixed := applyGitmoji(message); fixed != message {
+			message = fixed
+			fmt.Printf("\n🔧 Adjusted gitmoji: %s", strings.SplitN(message, "\n", 2)[0])
+		}
+		return message, nil
+	}
+	fallbackMode, err := commitFallbackMode()
+	if err != nil {
+		return err
+	}
+	generatedMessage, issues, err := generateValidCommitMessage(client, generate, printValidationRetry)
+
+	// When the API can't be reached, fall back to a rule-based draft unless configured
+	// to fail (and never when the user interrupted, or for errors a draft won't fix
+	// such as a bad API key)
+	fallback, draftStat := "", ""
+	if errors.Is(err, errGenerationCancelled) {
+		fmt.Println(i18n.T("Commit cancelled"))
+		return nil
+	}
+	if err != nil {
+		if fallbackMode == fallbackFail || cmd.Context().Err() != nil || !apiUnavailable(err) {
+			return fmt.Errorf("error generating commit message: %w", err)
+		}
+		draftBase := ""
+		if amend {
+			draftBase = amendFrom
+		}
+		draft, stat, draftErr := draftCommitMessage(draftBase)
+		if draftErr != nil {
+			return fmt.Errorf("error generating commit message: %w", err)
+		}
+		fmt.Fprint(os.Stderr, i18n.T("\n⚠️  Couldn't generate a commit message: %v\n", err))
+		if hint := apiErrorHint(err); hint != "" {
+			fmt.Fprintf(os.Stderr, "💡 %s\n", hint)
+		}
+		fmt.Fprint(os.Stderr, i18n.T("📝 Using a draft message from the staged files instead (fallback: %s)\n", fallbackMode))
+		if fallbackMode == fallbackTemplate || assumeYes {
+			fmt.Print(i18n.T("\nDraft commit message:\n%s\n\n", draft))
+		}
+		generatedMessage, draftStat = draft, stat
+		fallback, issues = fallbackMode, nil
+	} else {
+		if critiqueEnabled(commitCritique) {
+			generatedMessage, issues = critiqueCommitMessage(cmd.Context(), client, generatedMessage, issues, diff, fileList, func() (string, []lint.Issue, error) {
+				return generateValidCommitMessage(client, generate, printValidationRetry)
+			})
+		}
+		statusln(i18n.T("\n✓ Commit message generated!"))
+	}
+	printValidationIssues(issues)
+
+	if commitRefine && fallback == "" {
+		generatedMessage, err = refineCommitMessage(cmd.Context(), client, generatedMessage, diff, branch, recentCommits, fileList)
+		if err != nil {
+			return fmt.Errorf("error refining commit message: %w", err)
+		}
+	}
+	if ticketKey != "" {
+		statusf("%s", i18n.T("🎫 Referenced %s in the message footer\n", ticketKey))
+	}
+	if fallback == "" {
+		generatedMessage = appendTestSummary(generatedMessage, &tests)
+	}
+	generatedMessage = applyTrailers(generatedMessage, ticketKey, amendedMessage)
+
+	note := i18n.T("AI-generated message based on your changes.\nYou can edit, replace, or completely rewrite it.")
+	var record *messageRecord
+	if fallback != "" {
+		note = i18n.T("Draft message from the staged files; the AI message couldn't be generated.\nReplace the subject with what the change does and why.") + "\n\n" + draftStat
+	} else {
+		// Save the message first, so it can be reused if the commit is aborted
+		record = recordGeneratedMessage(generatedMessage)
+	}
+	return reviewAndCommit(cmd, generatedMessage, fallback, note, record)
+}
+
+// commitWithLastMessage commits with the newest message in the history, e.g. after the
+// editor was closed by accident
+func commitWithLastMessage(cmd *cobra.Command) error {
+	if commitMessage != "" {
+		return fmt.Errorf("--reuse-last can't be combined with -m")
+	}
+	record, entry, err := lastGeneratedMessage()
+	if err != nil {
+		return err
+	}
+	if entry.Status == history.StatusAccepted {
+		return fmt.Errorf("the last generated message was already committed as %.7s (see 'sgit history')", entry.Commit)
+	}
+
+	hasChanges, err := hasUncommittedChanges()
 	if err != nil {
-		return fmt.Errorf("error generating commit message: %v", err)
+		return fmt.Errorf("error checking for changes: %w", err)
+	}
+	amend, _ := cmd.Flags().GetBool("amend")
+	if !hasChanges && !amend {
+		fmt.Println(i18n.T("No changes to commit"))
+		return nil
 	}
 
-	fmt.Println("\n✓ Commit message generated!")
+	statusf("%s", i18n.T("♻️  Reusing the message generated %s (%s)\n", entry.Time.Local().Format("2006-01-02 15:04"), entry.Status))
+	if skipEditor || assumeYes {
+		fmt.Printf("\n%s\n\n", entry.Text())
+	}
+	note := i18n.T("Message reused from the history ('sgit history').\nYou can edit, replace, or completely rewrite it.")
+	return reviewAndCommit(cmd, entry.Text(), "", note, record)
+}
 
+// reviewAndCommit lets the user review the message the way the flags ask for, then
+// commits with it and the git flags given. fallback is the fallback mode when the
+// message is a rule-based draft, note is shown in the editor, and record (may be nil)
+// is told what became of the message.
+func reviewAndCommit(cmd *cobra.Command, generatedMessage, fallback, note string, record *messageRecord) error {
 	var finalMessage string
 
-	// Handle different interaction modes
-	if interactive {
+	// Handle different interaction modes. A fallback draft is edited, or used as it is
+	// with fallback: template and --yes.
+	editDraft := fallback == fallbackEditor && !assumeYes
+	if interactive && fallback == "" {
 		reader := bufio.NewReader(os.Stdin)
-		fmt.Print("Edit message (press Enter to use as-is): ")
+		fmt.Print(i18n.T("Edit message (press Enter to use as-is): "))
 		userInput, _ := reader.ReadString('\n')
 		userInput = strings.TrimSpace(userInput)
 		if userInput != "" {
@@ -154,26 +473,26 @@ func runCommit(cmd *cobra.Command, args []string) error {
 		} else {
 			finalMessage = generatedMessage
 		}
-	} else if skipEditor {
+	} else if (skipEditor || assumeYes || fallback != "") && !editDraft {
 		// Ask for confirmation before using AI message directly
-		fmt.Print("Use this commit message? (y/n): ")
-		reader := bufio.NewReader(os.Stdin)
-		response, _ := reader.ReadString('\n')
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
-			fmt.Println("Commit cancelled")
+		if !confirm(i18n.T("Use this commit message? (y/n): ")) {
+			fmt.Println(i18n.T("Commit cancelled"))
+			record.finish(history.StatusRejected, "")
 			return nil
 		}
 		finalMessage = generatedMessage
 	} else {
 		// Default behavior: open editor with AI-generated message
-		editedMessage, editorErr := openEditorWithMessage(generatedMessage)
+		editedMessage, editorErr := openEditorWithMessage(generatedMessage, note)
 		if editorErr != nil {
+			record.finish(history.StatusAborted, "")
 			return fmt.Errorf("error opening editor: %v", editorErr)
 		}
 		
 		if strings.TrimSpace(editedMessage) == "" {
-			fmt.Println("Empty commit message, aborting commit")
+			fmt.Println(i18n.T("Empty commit message, aborting commit"))
+			record.finish(history.StatusAborted, "")
+			statusln(i18n.T("💡 Run 'sgit commit --reuse-last' to commit with the message after all"))
 			return nil
 		}
 		
@@ -181,7 +500,16 @@ func runCommit(cmd *cobra.Command, args []string) error {
 	}
 
 	// Execute git commit with AI message AND any additional flags
-	return executeGitCommitWithFlags(finalMessage, cmd)
+	edited := ""
+	if strings.TrimSpace(finalMessage) != strings.TrimSpace(generatedMessage) {
+		edited = finalMessage
+	}
+	if err := executeGitCommitWithFlags(finalMessage, cmd); err != nil {
+		record.finish(history.StatusFailed, edited)
+		return err
+	}
+	record.finish(history.StatusAccepted, edited)
+	return nil
 }
 
 func executeGitCommitPassthrough(cobraCmd *cobra.Command, args []string) error {
@@ -190,17 +518,13 @@ func executeGitCommitPassthrough(cobraCmd *cobra.Command, args []string) error {
 	
 	// Add all the flags that were set
 	cobraCmd.Flags().Visit(func(flag *pflag.Flag) {
-		if flag.Name == "no-ai" || flag.Name == "interactive" || flag.Name == "skip-editor" || flag.Name == "ai" {
+		if isGlobalFlag(flag) || commitOnlyFlags[flag.Name] {
 			return // Skip our custom flags
 		}
 		
-		value := flag.Value.String()
-		if flag.Value.Type() == "bool" && value == "true" {
-			gitArgs = append(gitArgs, "--"+flag.Name)
-		} else if flag.Value.Type() != "bool" && value != "" {
-			gitArgs = append(gitArgs, "--"+flag.Name+"="+value)
-		}
+		gitArgs = append(gitArgs, gitCommitFlagArgs(flag)...)
 	})
+	gitArgs = append(gitArgs, configSigningArgs(cobraCmd)...)
 	
 	// Add any remaining arguments
 	gitArgs = append(gitArgs, args...)
@@ -210,7 +534,13 @@ func executeGitCommitPassthrough(cobraCmd *cobra.Command, args []string) error {
 	gitCmd.Stdin = os.Stdin
 	gitCmd.Stdout = os.Stdout
 	gitCmd.Stderr = os.Stderr
-	return gitCmd.Run()
+	if err := gitCmd.Run(); err != nil {
+		return err
+	}
+	if commitWillBeSigned(cobraCmd) {
+		reportCommitSignature()
+	}
+	return nil
 }
 
 func getDefaultEditor() string {
@@ -251,29 +581,26 @@ func getDefaultEditor() string {
 	return "nano" // fallback
 }
 
-func openEditorWithMessage(message string) (string, error) {
+func openEditorWithMessage(message, note string) (string, error) {
 	// Create temporary file
 	tmpDir := os.TempDir()
 	tmpFile, err := ioutil.TempFile(tmpDir, "sgit-commit-*.txt")
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %v", err)
+		return "", fmt.Errorf("failed to create temp file: %w", err)
 	}
 	defer os.Remove(tmpFile.Name())
 
 	// Write AI-generated message to temp file with some helpful comments
 	content := fmt.Sprintf(`%s
 
-# Please edit the commit message above.
-# Lines starting with '#' will be ignored.
-# An empty message aborts the commit.
+%s
 #
-# AI-generated message based on your changes.
-# You can edit, replace, or completely rewrite it.
-`, message)
+%s
+`, message, commentLines(i18n.T("Please edit the commit message above.\nLines starting with '#' will be ignored.\nAn empty message aborts the commit.")), commentLines(note))
 
 	if _, err := tmpFile.WriteString(content); err != nil {
 		tmpFile.Close()
-		return "", fmt.Errorf("failed to write to temp file: %v", err)
+		return "", fmt.Errorf("failed to write to temp file: %w", err)
 	}
 	tmpFile.Close()
 
@@ -293,13 +620,13 @@ func openEditorWithMessage(message string) (string, error) {
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("editor exited with error: %v", err)
+		return "", fmt.Errorf("editor exited with error: %w", err)
 	}
 
 	// Read the edited content
 	editedBytes, err := ioutil.ReadFile(tmpFile.Name())
 	if err != nil {
-		return "", fmt.Errorf("failed to read edited file: %v", err)
+		return "", fmt.Errorf("failed to read edited file: %w", err)
 	}
 
 	// Process the content (remove comment lines and trim)
@@ -318,32 +645,34 @@ func openEditorWithMessage(message string) (string, error) {
 	return result, nil
 }
 
+// commentLines prefixes each line of text with "# ", so the editor strips them
+func commentLines(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("# "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isGitRepository reports whether sgit runs inside a working tree, which may be a
+// linked worktree or one set with GIT_DIR and GIT_WORK_TREE
 func isGitRepository() bool {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
-	err := cmd.Run()
-	return err == nil
+	if gitRepo.IsRepository(context.Background()) {
+		return true
+	}
+	// A bare repository has no working tree to commit from
+	if bare, err := runGitOutput("rev-parse", "--is-bare-repository"); err == nil && strings.TrimSpace(bare) == "true" {
+		statusln("💡 This is a bare repository: run sgit in a worktree ('git worktree add <path> <branch>') or set GIT_WORK_TREE")
+	}
+	return false
 }
 
 func hasUncommittedChanges() (bool, error) {
-	cmd := exec.Command("git", "diff", "--cached", "--quiet")
-	err := cmd.Run()
-	if err != nil {
-		// If git diff --cached --quiet fails, there are staged changes
-		if exitError, ok := err.(*exec.ExitError); ok {
-			return exitError.ExitCode() != 0, nil
-		}
-		return false, err
-	}
-	return false, nil
+	return gitRepo.HasStagedChanges(context.Background())
 }
 
 func getGitDiff() (string, error) {
-	cmd := exec.Command("git", "diff", "--cached")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return string(output), nil
+	return gitRepo.StagedDiff(context.Background())
 }
 
 func executeGitCommit(message string) error {
@@ -361,7 +690,7 @@ func executeGitCommitWithFlags(message string, cobraCmd *cobra.Command) error {
 	// Add all the git flags that were set (excluding our custom AI flags)
 	cobraCmd.Flags().Visit(func(flag *pflag.Flag) {
 		// Skip our custom sgit flags
-		if flag.Name == "no-ai" || flag.Name == "interactive" || flag.Name == "skip-editor" || flag.Name == "ai" {
+		if isGlobalFlag(flag) || commitOnlyFlags[flag.Name] {
 			return
 		}
 		
@@ -371,20 +700,22 @@ func executeGitCommitWithFlags(message string, cobraCmd *cobra.Command) error {
 		}
 		
 		// Add the flag to git command
-		value := flag.Value.String()
-		if flag.Value.Type() == "bool" && value == "true" {
-			gitArgs = append(gitArgs, "--"+flag.Name)
-		} else if flag.Value.Type() != "bool" && value != "" {
-			gitArgs = append(gitArgs, "--"+flag.Name+"="+value)
-		}
+		gitArgs = append(gitArgs, gitCommitFlagArgs(flag)...)
 	})
+	gitArgs = append(gitArgs, configSigningArgs(cobraCmd)...)
 	
 	// Execute git command with AI message and all user flags
 	gitCmd := exec.Command("git", gitArgs...)
 	gitCmd.Stdin = os.Stdin
 	gitCmd.Stdout = os.Stdout
 	gitCmd.Stderr = os.Stderr
-	return gitCmd.Run()
+	if err := gitCmd.Run(); err != nil {
+		return err
+	}
+	if commitWillBeSigned(cobraCmd) {
+		reportCommitSignature()
+	}
+	return nil
 }
 
 func executeInteractiveGitCommit() error {
@@ -396,100 +727,146 @@ func executeInteractiveGitCommit() error {
 }
 
 func getCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "branch", "--show-current")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(string(output)), nil
+	return gitRepo.CurrentBranch(context.Background())
 }
 
 func getRecentCommits(count int) (string, error) {
-	cmd := exec.Command("git", "log", fmt.Sprintf("-%d", count), "--oneline", "--no-merges")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(string(output)), nil
+	return gitRepo.RecentCommits(context.Background(), count)
 }
 
 func getEnhancedFileList() (string, error) {
-	// Get list of staged files
-	stagedCmd := exec.Command("git", "diff", "--cached", "--name-status")
-	stagedOutput, err := stagedCmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to get staged files: %w", err)
-	}
+	return engine.DescribeStagedFiles(context.Background(), gitRepo)
+}
 
-	if len(stagedOutput) == 0 {
-		return "No files staged for commit", nil
+// runCommitTUI reviews the AI message in the interactive TUI and commits the accepted one
+func runCommitTUI(cmd *cobra.Command, client *solar.Client, diff, branch, recentCommits, fileList, ticketKey string, tests *testRun) error {
+	attempts := 0
+	// Every candidate is saved to the history as it is generated, so --reuse-last
+	// can recover it however the review ends
+	type candidate struct {
+		message string
+		record  *messageRecord
+	}
+	var candidates []candidate
+	var mu sync.Mutex
+	generate := func(ctx context.Context, onChunk func(string)) (string, error) {
+		// Regenerating must produce a fresh candidate rather than the cached one, with
+		// the regenerate settings
+		if attempts > 0 {
+			useRegenerateSettings(client)
+		}
+		attempts++
+		// Retries stream after the rejected attempt; the view shows only the final message
+		message, _, err := generateValidCommitMessage(client, func() (string, error) {
+			message, err := client.GenerateComprehensiveCommitMessageStream(ctx, diff, branch, recentCommits, fileList, onChunk)
+			if err != nil {
+				return "", err
+			}
+			return applyGitmoji(message), nil
+		}, func(issues []lint.Issue, attempt, maxAttempts int) {
+			onChunk(fmt.Sprintf("\n\n(failed validation: %s; regenerating %d/%d)\n\n", issues[0].Message, attempt, maxAttempts))
+		})
+		if err != nil {
+			return "", err
+		}
+		message = applyTrailers(appendTestSummary(message, tests), ticketKey, "")
+		if ctx.Err() == nil {
+			mu.Lock()
+			candidates = append(candidates, candidate{message, recordGeneratedMessage(message)})
+			mu.Unlock()
+		}
+		return message, nil
 	}
 
-	var fileInfo []string
-	lines := strings.Split(strings.TrimSpace(string(stagedOutput)), "\n")
-
-	for _, line := range lines {
-		if line == "" {
-			continue
+	message, accepted, err := tui.RunCommitReview(cmd.Context(), diff, generate)
+	mu.Lock()
+	defer mu.Unlock()
+	if err != nil {
+		for _, c := range candidates {
+			c.record.finish(history.StatusAborted, "")
 		}
-		
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
-			continue
+		return err
+	}
+	if !accepted || strings.TrimSpace(message) == "" {
+		for _, c := range candidates {
+			c.record.finish(history.StatusRejected, "")
 		}
-		
-		status := parts[0]
-		filePath := parts[1]
-		
-		// Get file size
-		fileSize := "unknown"
-		if stat, err := os.Stat(filePath); err == nil {
-			fileSize = fmt.Sprintf("%d bytes", stat.Size())
+		fmt.Println(i18n.T("Commit cancelled"))
+		return nil
+	}
+
+	// The accepted message is the candidate it matches, or else an edit of the newest
+	chosen := len(candidates) - 1
+	for i, c := range candidates {
+		if strings.TrimSpace(c.message) == strings.TrimSpace(message) {
+			chosen = i
 		}
-		
-		fileDesc := fmt.Sprintf("- %s %s (%s)", status, filePath, fileSize)
-		
-		// For new files (A = Added), include content preview
-		if status == "A" && !isBinaryFile(filePath) {
-			if stat, err := os.Stat(filePath); err == nil && stat.Size() <= 50*1024 { // Only for files <= 50KB
-				contentPreview := getFileContentPreview(filePath, 20) // First 20 lines
-				fileDesc += fmt.Sprintf("\n  Content preview:\n%s", 
-					strings.ReplaceAll(contentPreview, "\n", "\n  "))
-			}
+	}
+	edited := ""
+	for i, c := range candidates {
+		if i != chosen {
+			c.record.finish(history.StatusRejected, "")
+		} else if strings.TrimSpace(c.message) != strings.TrimSpace(message) {
+			edited = message
 		}
-		
-		fileInfo = append(fileInfo, fileDesc)
 	}
 
-	return strings.Join(fileInfo, "\n"), nil
+	var record *messageRecord
+	if chosen >= 0 {
+		record = candidates[chosen].record
+	}
+	if err := executeGitCommitWithFlags(message, cmd); err != nil {
+		record.finish(history.StatusFailed, edited)
+		return err
+	}
+	record.finish(history.StatusAccepted, edited)
+	return nil
 }
 
-// Helper function to get file content preview for new files
-func getFileContentPreview(filePath string, maxLines int) string {
-	file, err := os.Open(filePath)
+// refineCommitMessage asks for feedback on message and has the model revise it in
+// the same conversation, until the user accepts a version with an empty answer
+func refineCommitMessage(ctx context.Context, client *solar.Client, message, diff, branch, recentCommits, fileList string) (string, error) {
+	conversation, err := client.CommitMessageConversation(diff, branch, recentCommits, fileList, message)
 	if err != nil {
-		return fmt.Sprintf("Error reading file: %v", err)
+		return "", err
 	}
-	defer file.Close()
 
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	lineCount := 0
-	
-	for scanner.Scan() && lineCount < maxLines {
-		lines = append(lines, scanner.Text())
-		lineCount++
+	options := commitLintOptions()
+	for {
+		feedback := ask(i18n.T("\n💬 How should the message change? (Enter to accept): "))
+		if feedback == "" {
+			return message, nil
+		}
+
+		printer := newStreamPrinter(i18n.T("Refined commit message: "))
+		revised, next, err := client.RefineCommitMessageStream(ctx, conversation, feedback, printer.Write)
+		printer.Done()
+		if err != nil {
+			return "", err
+		}
+		fmt.Println()
+
+		message, conversation = formatCommitMessage(applyGitmoji(revised)), next
+		printValidationIssues(lint.Check(message, options))
 	}
-	
-	if err := scanner.Err(); err != nil {
-		return fmt.Sprintf("Error scanning file: %v", err)
+}
+
+// gitmojiEnabled reports whether commit messages must start with a gitmoji
+func gitmojiEnabled() bool {
+	return commitGitmoji || viper.GetBool("gitmoji") || strings.EqualFold(strings.TrimSpace(viper.GetString("convention")), "gitmoji")
+}
+
+// applyGitmoji validates the gitmoji of a generated message against the canonical list,
+// fixing it from the change type when possible
+func applyGitmoji(message string) string {
+	if !gitmojiEnabled() {
+		return message
 	}
-	
-	content := strings.Join(lines, "\n")
-	if lineCount == maxLines {
-		content += "\n... (file continues)"
+
+	fixed, err := gitmoji.Apply(message)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Gitmoji check: %v\n", err)
+		return message
 	}
-	
-	return content
+	return fixed
 }
-
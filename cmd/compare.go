@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/render"
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareFormat string
+	compareOutput string
+)
+
+// compareCmd writes a reviewer's guide to the changes between two branches
+var compareCmd = &cobra.Command{
+	Use:   "compare [<base>] [<head>]",
+	Short: "Report on a branch for its reviewer: risks, review order and review time",
+	Long: `Compare <head> (default HEAD) with <base> (default the remote default branch)
+and have Solar LLM write a report for whoever reviews it: what each file changes, the
+risk areas to look at closely, the order to review the files in, and an estimate of
+the review time. Like a pull request, only the changes made on <head> since it
+diverged from <base> are compared.
+
+Unlike 'sgit diff', which summarizes what a change does, the report is about how
+to review it.
+
+--format renders the report as ansi, plain, markdown or html; --output also writes
+the markdown report to a file.
+
+Examples:
+  sgit compare
+  sgit compare main feature/login
+  sgit compare origin/main --format html > review.html
+  sgit compare main -o REVIEW.md`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCompare(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+
+	compareCmd.Flags().StringVar(&compareFormat, "format", "", "report output format (auto|ansi|plain|markdown|html)")
+	compareCmd.Flags().StringVarP(&compareOutput, "output", "o", "", "also write the report to a file")
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	renderer, err := outputRenderer(compareFormat)
+	if err != nil {
+		return err
+	}
+	report := renderer.Format() == render.HTML
+
+	base, head := "", "HEAD"
+	if len(args) > 0 {
+		base = args[0]
+	} else if base, err = detectBaseBranch(); err != nil {
+		return fmt.Errorf("could not detect the base branch, pass it: sgit compare <base> [<head>]")
+	}
+	if len(args) > 1 {
+		head = args[1]
+	}
+	for _, ref := range []string{base, head} {
+		if _, err := runGitOutput("rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+			return fmt.Errorf("unknown revision '%s'", ref)
+		}
+	}
+
+	// Three dots: what head changed since the merge base, as a pull request shows it
+	diff, err := runGitOutput("diff", "--no-color", "--no-ext-diff", base+"..."+head)
+	if err != nil {
+		return fmt.Errorf("error getting git diff: %w", err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		fmt.Printf("No changes on %s since it diverged from %s\n", head, base)
+		return nil
+	}
+	commits, _ := runGitOutput("log", "--oneline", "--no-merges", base+".."+head)
+	commits = strings.TrimSpace(commits)
+	fileList, added, removed, files := describeCompareFiles(base + "..." + head)
+
+	commitCount := 0
+	if commits != "" {
+		commitCount = len(strings.Split(commits, "\n"))
+	}
+	headName := head
+	if branch, err := getCurrentBranch(); err == nil && branch != "" && head == "HEAD" {
+		headName = branch
+	}
+	scope := fmt.Sprintf("Changes on %s since it diverged from %s: %d commit(s), %d file(s), +%d -%d lines",
+		headName, base, commitCount, files, added, removed)
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	if !report {
+		statusf("🔍 %s\n", scope)
+		printContentStats("Branch analysis", diff, commits, fileList)
+	}
+	if diff, err = checkDiffSize(diff); err != nil {
+		return err
+	}
+	printer := newStreamPrinter("")
+	printer.renderer = renderer
+	result, err := client.CompareBranchesStream(cmd.Context(), diff, scope, commits, fileList, printer.Write)
+	printer.Done()
+	if err != nil {
+		return fmt.Errorf("error generating review report: %w", err)
+	}
+	fmt.Println() // Add newline after streaming output
+
+	if compareOutput != "" {
+		if err := os.WriteFile(compareOutput, []byte(strings.TrimSpace(result)+"\n"), 0644); err != nil {
+			return fmt.Errorf("error writing %s: %v", compareOutput, err)
+		}
+		fmt.Fprintf(os.Stderr, "✅ Wrote %s\n", compareOutput)
+	}
+	return nil
+}
+
+// describeCompareFiles lists the files changed in a diff range with their added and
+// removed lines, and returns the totals
+func describeCompareFiles(diffRange string) (list string, added, removed, files int) {
+	output, err := runGitOutput("diff", "--numstat", diffRange)
+	if err != nil {
+		return "", 0, 0, 0
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		files++
+		// Binary files have "-" counts
+		if fields[0] == "-" {
+			lines = append(lines, fmt.Sprintf("- %s (binary)", fields[2]))
+			continue
+		}
+		a, _ := strconv.Atoi(fields[0])
+		d, _ := strconv.Atoi(fields[1])
+		added, removed = added+a, removed+d
+		lines = append(lines, fmt.Sprintf("- %s (+%d -%d)", fields[2], a, d))
+	}
+	return strings.Join(lines, "\n"), added, removed, files
+}
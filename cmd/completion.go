@@ -3,23 +3,11 @@ package cmd
 import (
 	"fmt"
 	
+	"github.com/hunkim/sgit/pkg/solar"
 	"github.com/spf13/cobra"
 )
 
 func init() {
-	// Add custom completion for language flag
-	rootCmd.RegisterFlagCompletionFunc("lang", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{
-			"en\tEnglish",
-			"ko\tKorean (한국어)", 
-			"ja\tJapanese (日本語)",
-			"zh\tChinese (中文)",
-			"es\tSpanish (Español)",
-			"fr\tFrench (Français)",
-			"de\tGerman (Deutsch)",
-		}, cobra.ShellCompDirectiveNoFileComp
-	})
-	
 	// Add custom completion help
 	if completionCmd := rootCmd.Commands(); len(completionCmd) > 0 {
 		for _, cmd := range completionCmd {
@@ -36,7 +24,7 @@ Choose your shell and follow the instructions below.
 The script will setup completion for sgit native commands:
   • add, commit, diff, log, merge, config
   • Global flags: --lang, --config  
-  • Language codes: en, ko, ja, zh, es, fr, de
+  • Language codes: en, ko, ja, zh, es, fr, de, pt, vi, ... (any BCP-47 code works)
 
 See each sub-command's help for details on manual installation.`
 				
@@ -55,4 +43,13 @@ See each sub-command's help for details on manual installation.`
 			}
 		}
 	}
-} 
\ No newline at end of file
+} 
+
+// completeLanguages suggests the common language codes for --lang
+func completeLanguages(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var languages []string
+	for _, code := range solar.CommonLanguages {
+		languages = append(languages, code+"\t"+solar.LanguageName(code))
+	}
+	return languages, cobra.ShellCompDirectiveNoFileComp
+}
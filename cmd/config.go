@@ -1,94 +1,191 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
+	"unicode"
 
+	"github.com/hunkim/sgit/pkg/configmigrate"
+	"github.com/hunkim/sgit/pkg/render"
+	"github.com/hunkim/sgit/pkg/solar"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
 )
 
+var configMaskSecrets bool
+
 // configCmd represents the config command
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Configure sgit settings",
-	Long:  `Configure API key and other settings for sgit.`,
+	Long: `Configure API key and other settings for sgit.
+
+Without a subcommand, runs the interactive setup ('sgit config init'). The other
+subcommands read and change single settings, for scripts and dotfiles. Nested
+settings use dots (add_policy.never), and values are parsed as YAML, so true, 3
+and [a, b] become a boolean, a number and a list.
+
+Examples:
+  sgit config init
+  sgit config set upstage_model_name solar-pro2
+  sgit config set add_policy.never '["*.env", "dist/**"]'
+  sgit config get language
+  sgit config list --mask-secrets
+  sgit config unset fallback`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		setupConfig()
 	},
 }
 
-func init() {
-	rootCmd.AddCommand(configCmd)
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up the API key, model and language interactively",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupConfig()
+	},
 }
 
-func readAPIKeyWithVisualFeedback() (string, error) {
-	var apiKey []byte
-	var char byte
-	var err error
-
-	for {
-		// Read one character at a time
-		if char, err = readSingleChar(); err != nil {
-			return "", err
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Long: `Print the value of a setting as sgit sees it, including environment variable
+overrides. Lists are printed one item per line and nested settings as YAML.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigGet(cmd, args); err != nil {
+			exitWithError(err)
 		}
+	},
+}
 
-		// Handle Ctrl-C (ASCII 3)
-		if char == 3 {
-			fmt.Println("\n\n⚠️  Configuration cancelled by user")
-			fmt.Println("💡 Run 'sgit config' again anytime to set up your configuration")
-			os.Exit(0)
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a setting in the config file",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigSet(cmd, args); err != nil {
+			exitWithError(err)
 		}
+	},
+}
 
-		// Handle Enter (newline)
-		if char == '\n' || char == '\r' {
-			fmt.Println()
-			break
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting from the config file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigUnset(cmd, args); err != nil {
+			exitWithError(err)
 		}
+	},
+}
 
-		// Handle Backspace
-		if char == 127 || char == 8 {
-			if len(apiKey) > 0 {
-				apiKey = apiKey[:len(apiKey)-1]
-				fmt.Print("\b \b") // Move back, print space, move back again
-			}
-			continue
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings as key=value",
+	Long: `List every setting as key=value, sorted by key, including environment variable
+overrides. --mask-secrets hides API keys and tokens, for sharing the output.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigList(cmd, args); err != nil {
+			exitWithError(err)
 		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
 
-		// Add character to password
-		apiKey = append(apiKey, char)
+	configListCmd.Flags().BoolVar(&configMaskSecrets, "mask-secrets", false, "hide API keys, tokens and passwords")
+}
 
-		// Display feedback
-		if len(apiKey) <= 3 {
-			// Show first 3 characters (should be "up_")
-			fmt.Print(string(char))
-		} else {
-			// Show asterisk for characters after the first 3
-			fmt.Print("*")
-		}
-	}
+// errInputCancelled is returned when the user presses Ctrl-C during masked input
+var errInputCancelled = fmt.Errorf("input cancelled")
 
-	return string(apiKey), nil
+// readAPIKeyWithVisualFeedback reads the API key, echoing its first 3 characters
+// (the "up_" prefix) and an asterisk for each one after
+func readAPIKeyWithVisualFeedback() (string, error) {
+	key, err := readMaskedInput(3)
+	if err == errInputCancelled {
+		fmt.Println("\n\n⚠️  Configuration cancelled by user")
+		fmt.Println("💡 Run 'sgit config init' again anytime to set up your configuration")
+		os.Exit(0)
+	}
+	return key, err
 }
 
-func readSingleChar() (byte, error) {
-	// Set terminal to raw mode
-	oldState, err := term.MakeRaw(int(syscall.Stdin))
+// readMaskedInput reads a line from the terminal in raw mode, echoing the first
+// visible characters and an asterisk for each one after. x/term puts Unix terminals
+// and Windows consoles (cmd, PowerShell, Windows Terminal) into raw mode; when stdin
+// is not a terminal, e.g. a pipe or Git Bash's mintty without winpty, the line is
+// read as-is and stays visible.
+func readMaskedInput(visible int) (string, error) {
+	fd := int(os.Stdin.Fd())
+	oldState, err := term.MakeRaw(fd)
 	if err != nil {
-		return 0, err
+		line, readErr := stdinReader.ReadString('\n')
+		if readErr != nil && line == "" {
+			return "", readErr
+		}
+		return strings.TrimSpace(line), nil
 	}
-	defer term.Restore(int(syscall.Stdin), oldState)
+	restore := func() { term.Restore(fd, oldState) }
+	defer restore()
+
+	var input []rune
+	for {
+		r, _, err := stdinReader.ReadRune()
+		if err != nil {
+			return "", err
+		}
 
-	// Read one byte
-	var b [1]byte
-	_, err = os.Stdin.Read(b[:])
-	return b[0], err
+		switch {
+		case r == 3: // Ctrl-C
+			restore()
+			return "", errInputCancelled
+		case r == '\r' || r == '\n':
+			// Raw mode doesn't translate newlines, so return the cursor too
+			fmt.Print("\r\n")
+			return string(input), nil
+		case r == 127 || r == 8: // Backspace (Unix sends DEL, Windows BS)
+			if len(input) > 0 {
+				input = input[:len(input)-1]
+				fmt.Print("\b \b")
+			}
+		case r == 27: // Escape sequences such as arrow keys are ignored
+			if next, _, err := stdinReader.ReadRune(); err == nil && next == '[' {
+				for {
+					b, _, err := stdinReader.ReadRune()
+					if err != nil || (b >= 0x40 && b <= 0x7e) {
+						break
+					}
+				}
+			}
+		case unicode.IsPrint(r):
+			input = append(input, r)
+			if len(input) <= visible {
+				fmt.Print(string(r))
+			} else {
+				fmt.Print("*")
+			}
+		}
+	}
 }
 
 func setupConfig() {
@@ -100,20 +197,32 @@ func setupConfig() {
 	go func() {
 		<-sigChan
 		fmt.Println("\n\n⚠️  Configuration cancelled by user")
-		fmt.Println("💡 Run 'sgit config' again anytime to set up your configuration")
+		fmt.Println("💡 Run 'sgit config init' again anytime to set up your configuration")
 		os.Exit(0)
 	}()
 	
-	reader := bufio.NewReader(os.Stdin)
+	reader := stdinReader
 
 	fmt.Println("🔧 sgit Configuration Setup")
 	fmt.Println("Your API key will be stored locally and securely in ~/.config/sgit/config.yaml")
 	fmt.Println("💡 Press Ctrl-C anytime to cancel")
+	// With a profile picked for this run or directory, its settings are the ones set
+	// up; the default profile setting keeps the top-level ones
+	profile, source := configProfile()
+	if source == profileFromConfig {
+		profile = ""
+	}
+	if profile != "" {
+		fmt.Printf("👤 Setting up profile '%s'\n", profile)
+	}
 	fmt.Println()
 
 	// Check existing configuration
-	existingAPIKey := viper.GetString("upstage_api_key")
-	existingModelName := viper.GetString("upstage_model_name")
+	existingAPIKey := upstageAPIKey()
+	var existingModelName string
+	if key := commandSettingKey("model", "upstage_model_name"); key != "" {
+		existingModelName = viper.GetString(key)
+	}
 	existingLanguage := viper.GetString("language")
 
 	var apiKeyStr string
@@ -123,12 +232,7 @@ func setupConfig() {
 	fmt.Println("(get one at https://console.upstage.ai/)")
 	if existingAPIKey != "" {
 		// Show masked existing API key
-		maskedKey := ""
-		if len(existingAPIKey) >= 3 {
-			maskedKey = existingAPIKey[:3] + strings.Repeat("*", len(existingAPIKey)-3)
-		} else {
-			maskedKey = strings.Repeat("*", len(existingAPIKey))
-		}
+		maskedKey := maskSecret(existingAPIKey)
 		fmt.Printf("Enter your Upstage API key (current: %s, press Enter to keep): ", maskedKey)
 		
 		// For existing keys, use simple input to allow easy Enter-to-keep
@@ -137,7 +241,7 @@ func setupConfig() {
 			// Check if it's an interrupt
 			if strings.Contains(err.Error(), "interrupt") {
 				fmt.Println("\n\n⚠️  Configuration cancelled by user")
-				fmt.Println("💡 Run 'sgit config' again anytime to set up your configuration")
+				fmt.Println("💡 Run 'sgit config init' again anytime to set up your configuration")
 				os.Exit(0)
 			}
 			fmt.Printf("Error reading API key: %v\n", err)
@@ -178,7 +282,7 @@ func setupConfig() {
 		// Check if it's an interrupt
 		if strings.Contains(err.Error(), "interrupt") {
 			fmt.Println("\n\n⚠️  Configuration cancelled by user")
-			fmt.Println("💡 Run 'sgit config' again anytime to set up your configuration")
+			fmt.Println("💡 Run 'sgit config init' again anytime to set up your configuration")
 			os.Exit(0)
 		}
 		fmt.Printf("Error reading model name: %v\n", err)
@@ -197,32 +301,15 @@ func setupConfig() {
 	}
 
 	// Get language preference with existing value
-	fmt.Println("\nAvailable languages:")
-	fmt.Println("  en - English")
-	fmt.Println("  ko - Korean (한국어)")
-	fmt.Println("  ja - Japanese (日本語)")
-	fmt.Println("  zh - Chinese (中文)")
-	fmt.Println("  es - Spanish (Español)")
-	fmt.Println("  fr - French (Français)")
-	fmt.Println("  de - German (Deutsch)")
+	fmt.Println("\nCommon languages (any BCP-47 code like pt-BR, or a language name, also works):")
+	for _, code := range solar.CommonLanguages {
+		fmt.Printf("  %-5s - %s\n", code, solar.LanguageName(code))
+	}
 	
 	if existingLanguage != "" {
-		validLanguages := map[string]string{
-			"en": "English",
-			"ko": "Korean",
-			"ja": "Japanese",
-			"zh": "Chinese",
-			"es": "Spanish",
-			"fr": "French",
-			"de": "German",
-		}
-		currentLangName := validLanguages[existingLanguage]
-		if currentLangName == "" {
-			currentLangName = existingLanguage
-		}
-		fmt.Printf("Enter language code (current: %s - %s, press Enter to keep): ", existingLanguage, currentLangName)
+		fmt.Printf("Enter language (current: %s - %s, press Enter to keep): ", existingLanguage, solar.LanguageName(existingLanguage))
 	} else {
-		fmt.Print("Enter language code (default: en): ")
+		fmt.Print("Enter language (default: en): ")
 	}
 	
 	language, err := reader.ReadString('\n')
@@ -230,13 +317,13 @@ func setupConfig() {
 		// Check if it's an interrupt
 		if strings.Contains(err.Error(), "interrupt") {
 			fmt.Println("\n\n⚠️  Configuration cancelled by user")
-			fmt.Println("💡 Run 'sgit config' again anytime to set up your configuration")
+			fmt.Println("💡 Run 'sgit config init' again anytime to set up your configuration")
 			os.Exit(0)
 		}
 		fmt.Printf("Error reading language: %v\n", err)
 		return
 	}
-	language = strings.TrimSpace(strings.ToLower(language))
+	language = strings.TrimSpace(language)
 	
 	// Use existing value if empty, otherwise use default
 	if language == "" {
@@ -249,33 +336,46 @@ func setupConfig() {
 	}
 	
 	// Validate language code
-	validLanguages := map[string]string{
-		"en": "English",
-		"ko": "Korean",
-		"ja": "Japanese",
-		"zh": "Chinese",
-		"es": "Spanish",
-		"fr": "French",
-		"de": "German",
-	}
-	
-	if _, valid := validLanguages[language]; !valid {
-		fmt.Printf("Invalid language code '%s'. Defaulting to 'en' (English)\n", language)
+	if normalized, err := solar.NormalizeLanguage(language); err != nil {
+		fmt.Printf("%v. Defaulting to 'en' (English)\n", err)
 		language = "en"
 	} else {
-		fmt.Printf("Selected language: %s (%s)\n", language, validLanguages[language])
+		language = normalized
+		fmt.Printf("Selected language: %s (%s)\n", language, solar.LanguageName(language))
 	}
 
-	// Save configuration
-	viper.Set("upstage_api_key", apiKeyStr)
-	viper.Set("upstage_model_name", modelName)
+	// Save configuration, under profiles.<name> when a profile is in use
+	settings := [][2]string{{apiKeySetting, apiKeyStr}, {"upstage_model_name", modelName}, {"language", language}}
+	if profile != "" {
+		settings = [][2]string{{"profiles." + profile + ".api_key", apiKeyStr}, {"profiles." + profile + ".model", modelName}, {"profiles." + profile + ".language", language}}
+	}
+	viper.Set(apiKeySetting, apiKeyStr)
 	viper.Set("language", language)
 
-	// Get config file path
-	configDir := filepath.Join(os.Getenv("HOME"), ".config", "sgit")
-	configFile := filepath.Join(configDir, "config.yaml")
+	configFile, err := configFilePath()
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		fmt.Printf("Error creating config directory: %v\n", err)
+		return
+	}
 
-	if err := viper.WriteConfigAs(configFile); err != nil {
+	// Only the answered settings are written, not values from the environment or a profile
+	doc, err := loadConfigDocument(configFile)
+	if err == nil {
+		for _, setting := range settings {
+			viper.Set(setting[0], setting[1])
+			if err = setConfigNode(doc.Content[0], strings.Split(setting[0], "."), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: setting[1]}); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = saveConfigDocument(configFile, doc)
+	}
+	if err != nil {
 		fmt.Printf("Error saving configuration: %v\n", err)
 		return
 	}
@@ -288,20 +388,344 @@ func setupConfig() {
 
 // ensureConfiguration checks if configuration exists and runs setup if needed
 func ensureConfiguration() error {
-	apiKey := viper.GetString("upstage_api_key")
-	if apiKey == "" {
-		fmt.Println("No API key configured. Running setup...")
-		fmt.Println()
-		setupConfig()
-		
-		// Re-read configuration after setup
-		apiKey = viper.GetString("upstage_api_key")
-		if apiKey == "" {
-			return fmt.Errorf("configuration setup failed or was cancelled")
+	// --show-prompt sends nothing
+	if showPrompt || aiConfigured() {
+		return nil
+	}
+	// Scripts, CI and containers can't answer the setup questions
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("no API key configured (set SGIT_API_KEY or run 'sgit config init')")
+	}
+	fmt.Println("No API key configured. Running setup...")
+	fmt.Println()
+	setupConfig()
+	
+	// Re-read configuration after setup
+	if !aiConfigured() {
+		return fmt.Errorf("configuration setup failed or was cancelled")
+	}
+	
+	fmt.Println()
+	fmt.Println("Configuration complete! Continuing...")
+	return nil
+} 
+// configFilePath returns the config file sgit reads and writes: --config, or
+// ~/.config/sgit/config.yaml. HOME is usually unset on Windows, so the OS is asked.
+func configFilePath() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "sgit", "config.yaml"), nil
+}
+
+// configValidators check and normalize the values of settings that only take some values
+var configValidators = map[string]func(string) (string, error){
+	"language":    solar.NormalizeLanguage,
+	"ui_language": solar.NormalizeLanguage,
+	"fallback": func(value string) (string, error) {
+		switch mode := strings.ToLower(value); mode {
+		case fallbackEditor, fallbackTemplate, fallbackFail:
+			return mode, nil
+		}
+		return "", fmt.Errorf("invalid fallback '%s' (use editor, template or fail)", value)
+	},
+	"reasoning_effort": solar.NormalizeReasoningEffort,
+	"sign":             normalizeSignMode,
+	"large_diff":       normalizeLargeDiffMode,
+	"commit_msg_check": normalizeCommitMsgCheckMode,
+	"leftover_check":   normalizeLeftoverCheckMode,
+	"critique_threshold": func(value string) (string, error) {
+		if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 10 {
+			return "", fmt.Errorf("invalid critique_threshold '%s' (use a score from 1 to 10)", value)
+		}
+		return value, nil
+	},
+	"output_format": func(value string) (string, error) {
+		format := strings.ToLower(value)
+		if format == "auto" {
+			return format, nil
+		}
+		if _, err := render.New(format); err != nil {
+			return "", fmt.Errorf("invalid output_format '%s' (use auto, %s)", value, strings.Join(render.Formats(), ", "))
+		}
+		return format, nil
+	},
+}
+
+// secretKeyPattern matches the names of settings that hold credentials
+var secretKeyPattern = regexp.MustCompile(`(?i)(api_key|token|secret|password)`)
+
+// maskSecret hides all but the first 3 characters of a secret (the "up_" prefix of
+// Upstage keys)
+func maskSecret(value string) string {
+	if len(value) < 3 {
+		return strings.Repeat("*", len(value))
+	}
+	return value[:3] + strings.Repeat("*", len(value)-3)
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	key := strings.ToLower(args[0])
+	if !viper.IsSet(key) {
+		return fmt.Errorf("%s is not set", key)
+	}
+
+	switch value := viper.Get(key).(type) {
+	case map[string]interface{}:
+		out, err := encodeYAML(value)
+		if err != nil {
+			return fmt.Errorf("error formatting %s: %v", key, err)
+		}
+		fmt.Print(out)
+	case []interface{}:
+		for _, item := range value {
+			fmt.Println(item)
+		}
+	default:
+		fmt.Println(value)
+	}
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	key, raw := strings.ToLower(args[0]), args[1]
+	// Profiles and commands.<name> take the same values as the global setting
+	if validate, ok := configValidators[key[strings.LastIndex(key, ".")+1:]]; ok {
+		normalized, err := validate(raw)
+		if err != nil {
+			return err
+		}
+		raw = normalized
+	}
+
+	configFile, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	doc, err := loadConfigDocument(configFile)
+	if err != nil {
+		return err
+	}
+	if err := setConfigNode(doc.Content[0], strings.Split(key, "."), parseConfigValue(raw)); err != nil {
+		return err
+	}
+	if err := saveConfigDocument(configFile, doc); err != nil {
+		return err
+	}
+
+	if env := overridingEnv(key); env != "" {
+		fmt.Fprintf(os.Stderr, "⚠️  %s is set and overrides %s from the config file\n", env, key)
+	}
+	return nil
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	key := strings.ToLower(args[0])
+	configFile, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	doc, err := loadConfigDocument(configFile)
+	if err != nil {
+		return err
+	}
+	if !unsetConfigNode(doc.Content[0], strings.Split(key, ".")) {
+		return fmt.Errorf("%s is not set in %s", key, configFile)
+	}
+	if err := saveConfigDocument(configFile, doc); err != nil {
+		return err
+	}
+
+	if env := overridingEnv(key); env != "" {
+		fmt.Fprintf(os.Stderr, "⚠️  %s is still set in the environment\n", env)
+	}
+	return nil
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	settings := make(map[string]string)
+	flattenConfig("", viper.AllSettings(), settings)
+
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := settings[key]
+		if configMaskSecrets && secretKeyPattern.MatchString(key) {
+			value = maskSecret(value)
+		}
+		fmt.Printf("%s=%s\n", key, value)
+	}
+	return nil
+}
+
+// flattenConfig adds the leaves of nested settings to out under dotted keys, with lists
+// in flow style ([a, b])
+func flattenConfig(prefix string, settings map[string]interface{}, out map[string]string) {
+	for key, value := range settings {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch value := value.(type) {
+		case map[string]interface{}:
+			flattenConfig(key, value, out)
+		case []interface{}:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			out[key] = "[" + strings.Join(items, ", ") + "]"
+		default:
+			out[key] = fmt.Sprint(value)
+		}
+	}
+}
+
+// overridingEnv returns the environment variable that overrides a setting, if one is set
+func overridingEnv(key string) string {
+	names := append([]string{"SGIT_" + strings.ToUpper(envKeyReplacer.Replace(key))}, envAliases[key]...)
+	for _, name := range names {
+		if _, ok := os.LookupEnv(name); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// loadConfigDocument reads the config file as a YAML document whose content is the
+// top-level mapping, keeping comments and key order. A missing file is an empty mapping.
+func loadConfigDocument(configFile string) (*yaml.Node, error) {
+	if ext := strings.ToLower(filepath.Ext(configFile)); ext != ".yaml" && ext != ".yml" {
+		return nil, fmt.Errorf("only YAML config files can be edited (%s)", configFile)
+	}
+
+	doc := &yaml.Node{Kind: yaml.DocumentNode}
+	content, err := os.ReadFile(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading %s: %v", configFile, err)
+	}
+	if err := yaml.Unmarshal(content, doc); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", configFile, err)
+	}
+
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s does not contain a mapping of settings", configFile)
+	}
+	return doc, nil
+}
+
+// saveConfigDocument writes the config file, creating it readable only by the user
+// since it holds the API key. A new file starts at the current config version.
+func saveConfigDocument(configFile string, doc *yaml.Node) error {
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		configmigrate.Stamp(doc.Content[0])
+	}
+	content, err := encodeYAML(doc)
+	if err != nil {
+		return fmt.Errorf("error formatting config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		return fmt.Errorf("error writing %s: %v", configFile, err)
+	}
+	return nil
+}
+
+// encodeYAML formats a value as YAML indented by two spaces, like the config file
+func encodeYAML(value interface{}) (string, error) {
+	var b strings.Builder
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// parseConfigValue parses a value given on the command line as YAML, so booleans,
+// numbers and lists keep their type; anything else is a string
+func parseConfigValue(raw string) *yaml.Node {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err == nil && len(doc.Content) == 1 {
+		if node := doc.Content[0]; node.Kind == yaml.SequenceNode ||
+			(node.Kind == yaml.ScalarNode && node.Tag != "!!null") {
+			return node
+		}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: raw}
+}
+
+// setConfigNode sets the value at a dotted key path, creating the mappings on the way.
+// Keys match case-insensitively, like viper's.
+func setConfigNode(mapping *yaml.Node, path []string, value *yaml.Node) error {
+	for i, name := range path {
+		last := i == len(path)-1
+		child := configChild(mapping, name)
+		if child == nil {
+			child = value
+			if !last {
+				child = &yaml.Node{Kind: yaml.MappingNode}
+			}
+			mapping.Content = append(mapping.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, child)
+		} else if last {
+			// Keep the comments next to the old value
+			head, line, foot := child.HeadComment, child.LineComment, child.FootComment
+			*child = *value
+			child.HeadComment, child.LineComment, child.FootComment = head, line, foot
+		} else if child.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s is not a group of settings", strings.Join(path[:i+1], "."))
+		}
+		mapping = child
+	}
+	return nil
+}
+
+// unsetConfigNode removes the value at a dotted key path, and the mappings left empty
+// by it, reporting whether it was there
+func unsetConfigNode(mapping *yaml.Node, path []string) bool {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if !strings.EqualFold(mapping.Content[i].Value, path[0]) {
+			continue
+		}
+		if len(path) > 1 {
+			child := mapping.Content[i+1]
+			if child.Kind != yaml.MappingNode || !unsetConfigNode(child, path[1:]) {
+				return false
+			}
+			if len(child.Content) > 0 {
+				return true
+			}
+		}
+		mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+		return true
+	}
+	return false
+}
+
+// configChild returns the value of a key in a mapping node, or nil
+func configChild(mapping *yaml.Node, name string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if strings.EqualFold(mapping.Content[i].Value, name) {
+			return mapping.Content[i+1]
 		}
-		
-		fmt.Println()
-		fmt.Println("Configuration complete! Continuing...")
 	}
 	return nil
-} 
\ No newline at end of file
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/conflict"
+)
+
+// conflictContextLines is how many lines around each conflict are sent to the AI
+const conflictContextLines = 5
+
+// conflictedFile holds the parsed conflicts of one file
+type conflictedFile struct {
+	path    string
+	content string
+	hunks   []conflict.Hunk
+}
+
+// loadConflictedFiles reads and parses the conflict hunks of each file
+func loadConflictedFiles(paths []string) []conflictedFile {
+	var files []conflictedFile
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("⏭️  %s: cannot read file (%v), resolve it manually\n", path, err)
+			continue
+		}
+
+		hunks, err := conflict.Parse(string(content), conflictContextLines)
+		if err != nil {
+			fmt.Printf("⏭️  %s: %v, resolve it manually\n", path, err)
+			continue
+		}
+		if len(hunks) == 0 {
+			fmt.Printf("⏭️  %s: no conflict markers (binary or delete/modify conflict), resolve it manually\n", path)
+			continue
+		}
+
+		files = append(files, conflictedFile{path: path, content: string(content), hunks: hunks})
+	}
+	return files
+}
+
+// formatConflictHunks renders every hunk of every file for the AI
+func formatConflictHunks(files []conflictedFile) string {
+	var b strings.Builder
+	for _, file := range files {
+		fmt.Fprintf(&b, "File: %s (%d conflicts)\n", file.path, len(file.hunks))
+		for _, hunk := range file.hunks {
+			fmt.Fprintf(&b, "--- Conflict %d (lines %d-%d) ---\n%s\n", hunk.Index+1, hunk.StartLine, hunk.EndLine, hunk.String())
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// assistConflicts sends the conflicted hunks to the AI for an overview, then proposes
+// a resolution for each hunk that the user can apply or skip. Files left without
+// conflict markers are staged. It reports whether every conflicted file was resolved.
+func assistConflicts(ctx context.Context, paths []string, operationContext string) (bool, error) {
+	client, err := newSolarClient()
+	if err != nil {
+		return false, err
+	}
+
+	files := loadConflictedFiles(paths)
+	if len(files) == 0 {
+		return false, nil
+	}
+
+	statusln("Analyzing conflicts with Solar LLM...")
+	overview, err := client.AnalyzeMergeConflicts(ctx, operationContext+"\n\n"+formatConflictHunks(files))
+	if err != nil {
+		return false, err
+	}
+	fmt.Println("\n=== AI CONFLICT ASSISTANCE ===")
+	fmt.Println(overview)
+	fmt.Println()
+
+	resolvedFiles := 0
+	quit := false
+
+	for _, file := range files {
+		resolutions := make(map[int]string)
+
+		for _, hunk := range file.hunks {
+			if quit {
+				break
+			}
+
+			fmt.Printf("\n📄 %s — conflict %d/%d (lines %d-%d)\n", file.path, hunk.Index+1, len(file.hunks), hunk.StartLine, hunk.EndLine)
+			response, err := client.ResolveConflictHunk(ctx, file.path, hunk.String(), operationContext)
+			if err != nil {
+				if ctx.Err() != nil {
+					return false, ctx.Err()
+				}
+				fmt.Printf("Warning: could not get a resolution: %v\n", err)
+				continue
+			}
+
+			explanation, resolution, ok := parseConflictResolution(response)
+			if !ok {
+				fmt.Println("Warning: could not parse the proposed resolution, skipping")
+				fmt.Println(response)
+				continue
+			}
+
+			fmt.Printf("💡 %s\n", explanation)
+			fmt.Println("Proposed resolution:")
+			fmt.Println("----------------------------------------")
+			fmt.Println(resolution)
+			fmt.Println("----------------------------------------")
+
+			choice := "a"
+			if assumeYes {
+				statusln("Applying resolution (--yes)")
+			} else {
+				choice = ask("Apply this resolution? [a]pply / [s]kip / [q]uit: ")
+			}
+			switch strings.ToLower(choice) {
+			case "a", "apply", "y", "yes":
+				resolutions[hunk.Index] = resolution
+			case "q", "quit":
+				quit = true
+			default:
+				fmt.Println("⏭️  Skipped")
+			}
+		}
+
+		if len(resolutions) > 0 {
+			staged, err := applyConflictResolutions(file, resolutions)
+			if err != nil {
+				return false, err
+			}
+			if staged {
+				resolvedFiles++
+			}
+		}
+
+		if quit {
+			break
+		}
+	}
+
+	return resolvedFiles == len(paths), nil
+}
+
+// applyConflictResolutions writes the accepted resolutions to the file and stages it
+// once no conflict markers remain. It reports whether the file was staged.
+func applyConflictResolutions(file conflictedFile, resolutions map[int]string) (bool, error) {
+	resolved, err := conflict.Resolve(file.content, resolutions)
+	if err != nil {
+		return false, fmt.Errorf("error resolving %s: %v", file.path, err)
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(file.path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(file.path, []byte(resolved), mode); err != nil {
+		return false, fmt.Errorf("error writing %s: %v", file.path, err)
+	}
+
+	if conflict.HasMarkers(resolved) {
+		fmt.Printf("✅ Applied %d resolution(s) to %s (conflicts remain)\n", len(resolutions), file.path)
+		return false, nil
+	}
+
+	if err := exec.Command("git", "add", "--", file.path).Run(); err != nil {
+		return false, fmt.Errorf("error staging %s: %v", file.path, err)
+	}
+	fmt.Printf("✅ Resolved and staged %s\n", file.path)
+	return true, nil
+}
+
+// parseConflictResolution splits an AI response into the explanation and the resolved code
+func parseConflictResolution(response string) (string, string, bool) {
+	idx := strings.Index(response, "RESOLUTION:")
+	if idx == -1 {
+		return "", "", false
+	}
+
+	explanation := strings.TrimSpace(response[:idx])
+	explanation = strings.TrimSpace(strings.TrimPrefix(explanation, "EXPLANATION:"))
+
+	code := response[idx+len("RESOLUTION:"):]
+	if start := strings.Index(code, "```"); start != -1 {
+		code = code[start+3:]
+		// Drop the language tag on the opening fence line
+		if newline := strings.Index(code, "\n"); newline != -1 {
+			code = code[newline+1:]
+		}
+		if end := strings.LastIndex(code, "```"); end != -1 {
+			code = code[:end]
+		}
+	}
+
+	code = strings.TrimRight(strings.TrimLeft(code, "\n"), " \t\n")
+	return explanation, code, true
+}
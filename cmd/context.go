@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hunkim/sgit/pkg/engine"
+	"github.com/hunkim/sgit/pkg/git"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/viper"
+)
+
+// Names of sgit's context providers, on top of the engine's built-in ones
+const (
+	contextTicket     = "ticket"
+	contextAPIChanges = "api_changes"
+	contextTests      = "tests"
+)
+
+// contextCommandTimeout bounds how long a commit_context.commands command may run
+const contextCommandTimeout = 30 * time.Second
+
+// commitContextOptions selects what the context of a commit message is collected from
+type commitContextOptions struct {
+	// amendFrom is the commit the amended commit's changes start from, "" when not amending
+	amendFrom string
+	// scopeDir is the monorepo subproject the commit is in, if any
+	scopeDir string
+	// ticket is the ticket key given with --ticket; "" detects it from the branch name
+	ticket string
+	// tests, if not nil, receives the outcome of test_command when it runs
+	tests *testRun
+}
+
+// commitContextProviders returns the providers the context of sgit commit and the
+// prepare-commit-msg hook is collected from: the diff, branch, recent commits and
+// files, the ticket, the public API changes, the results of test_command and the
+// commit_context.commands, minus the providers turned off with commit_context.disabled.
+//
+// Unlike the engine's, the diff provider doesn't fail on an empty diff, so callers
+// can explain what to do about it.
+func commitContextProviders(options commitContextOptions) *engine.Registry {
+	registry := engine.NewRegistry(engine.DefaultProviders(gitRepo)...)
+
+	registry.Register(engine.NewProvider(engine.ProviderDiff, func(ctx context.Context, commitContext *engine.CommitContext) error {
+		var diff string
+		var err error
+		if options.amendFrom != "" {
+			diff, err = getAmendDiff(options.amendFrom)
+		} else {
+			diff, err = getGitDiff()
+		}
+		if err != nil {
+			return fmt.Errorf("error getting git diff: %w", err)
+		}
+		commitContext.Diff = diff
+		return nil
+	}))
+
+	// When amending, the context covers the amended commit's changes too
+	if options.amendFrom != "" {
+		registry.Register(engine.NewProvider(engine.ProviderRecentCommits, func(ctx context.Context, commitContext *engine.CommitContext) error {
+			commitContext.RecentCommits, _ = getAmendRecentCommits(options.amendFrom, 5)
+			return nil
+		}))
+		registry.Register(engine.NewProvider(engine.ProviderFiles, func(ctx context.Context, commitContext *engine.CommitContext) error {
+			commitContext.FileList, _ = getAmendFileList(options.amendFrom)
+			return nil
+		}))
+	}
+
+	// In a monorepo, the subproject narrows the history and frames the files
+	if options.scopeDir != "" {
+		recentCommits, files := registry.Lookup(engine.ProviderRecentCommits), registry.Lookup(engine.ProviderFiles)
+		registry.Register(engine.NewProvider(engine.ProviderRecentCommits, func(ctx context.Context, commitContext *engine.CommitContext) error {
+			if options.amendFrom == "" {
+				if scoped, _ := getScopedRecentCommits(options.scopeDir, 5); scoped != "" {
+					commitContext.RecentCommits = scoped
+					return nil
+				}
+			}
+			return recentCommits.Provide(ctx, commitContext)
+		}))
+		registry.Register(engine.NewProvider(engine.ProviderFiles, func(ctx context.Context, commitContext *engine.CommitContext) error {
+			if err := files.Provide(ctx, commitContext); err != nil {
+				return err
+			}
+			commitContext.FileList = describeScopeDir(options.scopeDir, commitContext.FileList)
+			return nil
+		}))
+	}
+
+	registry.Register(engine.NewProvider(contextTicket, func(ctx context.Context, commitContext *engine.CommitContext) error {
+		if ticket := resolveCommitTicket(ctx, options.ticket); ticket != nil {
+			hints := &commitContext.Hints
+			hints.TicketKey, hints.TicketTitle, hints.TicketDescription = ticket.Key, ticket.Title, ticket.Description
+		}
+		return nil
+	}))
+
+	// Removed or changed public symbols may call for a BREAKING CHANGE footer
+	registry.Register(engine.NewProvider(contextAPIChanges, func(ctx context.Context, commitContext *engine.CommitContext) error {
+		base := options.amendFrom
+		if base == "" {
+			if head, _ := runGitOutput("rev-parse", "--verify", "-q", "HEAD"); strings.TrimSpace(head) != "" {
+				base = "HEAD"
+			}
+		}
+		if base != "" {
+			commitContext.Hints.APIChanges = stagedAPIBreaks(base)
+		}
+		return nil
+	}))
+
+	// Whether the change passes its tests, when test_command is set
+	if command := testCommand(); command != "" {
+		registry.Register(testContextProvider(command, options.tests))
+	}
+
+	configureContextProviders(registry, gitRepo)
+	return registry
+}
+
+// configureContextProviders adds a provider for each of the commit_context.commands
+// to registry and turns off the providers listed in commit_context.disabled
+func configureContextProviders(registry *engine.Registry, repo git.Repository) {
+	commands := viper.GetStringMapString("commit_context.commands")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if registry.Lookup(name) != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  commit_context.commands can't replace the built-in '%s' context, rename it\n", name)
+			continue
+		}
+		registry.Register(commandContextProvider(name, commands[name], repo))
+	}
+
+	for _, name := range configList("commit_context.disabled") {
+		if !slices.Contains(registry.Names(), name) {
+			fmt.Fprintf(os.Stderr, "⚠️  Unknown context provider '%s' in commit_context.disabled (known: %s)\n", name, strings.Join(registry.Names(), ", "))
+			continue
+		}
+		registry.Disable(name)
+	}
+}
+
+// commandContextProvider adds the output of a shell command, run at the top of the
+// repository, as a context section titled name. A command that fails still adds its
+// output, e.g. failing tests; one that can't be run is left out with a warning.
+func commandContextProvider(name, command string, repo git.Repository) engine.ContextProvider {
+	return engine.NewProvider(name, func(ctx context.Context, commitContext *engine.CommitContext) error {
+		ctx, cancel := context.WithTimeout(ctx, contextCommandTimeout)
+		defer cancel()
+
+		shell, flag := "sh", "-c"
+		if runtime.GOOS == "windows" {
+			shell, flag = "cmd", "/C"
+		}
+		cmd := exec.CommandContext(ctx, shell, flag, command)
+		if root, err := repo.Root(ctx); err == nil {
+			cmd.Dir = root
+		}
+		var output bytes.Buffer
+		cmd.Stdout, cmd.Stderr = &output, &output
+		err := cmd.Run()
+
+		var exitError *exec.ExitError
+		switch {
+		case ctx.Err() != nil:
+			fmt.Fprintf(os.Stderr, "⚠️  Left out the %s context: '%s' didn't finish within %s\n", name, command, contextCommandTimeout)
+			return nil
+		case errors.As(err, &exitError):
+			fmt.Fprintf(&output, "\n(exit status %d)", exitError.ExitCode())
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "⚠️  Left out the %s context: %v\n", name, err)
+			return nil
+		}
+		commitContext.Sections = append(commitContext.Sections, solar.ContextSection{Title: name, Text: output.String()})
+		return nil
+	})
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hunkim/sgit/pkg/i18n"
+	"github.com/hunkim/sgit/pkg/lint"
+	"github.com/hunkim/sgit/pkg/progress"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultCritiqueThreshold is the lowest critique score a message is kept with
+	defaultCritiqueThreshold = 7
+	// critiqueRegenerations bounds how often a message scored below the threshold is
+	// regenerated
+	critiqueRegenerations = 2
+)
+
+// critiqueEnabled reports whether generated messages get the critique pass: with
+// --critique, or critique: true in config
+func critiqueEnabled(requested bool) bool {
+	return requested || viper.GetBool("critique")
+}
+
+// critiqueThreshold returns critique_threshold from config, from 1 to 10
+func critiqueThreshold() int {
+	if !viper.IsSet("critique_threshold") {
+		return defaultCritiqueThreshold
+	}
+	return max(1, min(viper.GetInt("critique_threshold"), 10))
+}
+
+// critiqueCommitMessage has a second LLM pass score message against the diff, and
+// while the score is below critique_threshold, regenerates it with the problems
+// pointed out. The best scored of the attempts is returned, preferring ones that pass
+// validation. A critique that fails leaves the message as it is.
+func critiqueCommitMessage(ctx context.Context, client *solar.Client, message string, issues []lint.Issue, diff, fileList string, regenerate func() (string, []lint.Issue, error)) (string, []lint.Issue) {
+	threshold := critiqueThreshold()
+	best, bestIssues, bestScore := message, issues, 0
+
+	for attempt := 1; ; attempt++ {
+		critiquing := progress.Begin(i18n.T("Critiquing the commit message"))
+		critique, err := client.CritiqueCommitMessage(ctx, message, diff, fileList)
+		critiquing.End()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\n⚠️  Could not critique the message: %v\n", err)
+			return best, bestIssues
+		}
+
+		fmt.Fprintf(os.Stderr, "\n🧐 Critique: %s\n", critique)
+		// A message that passes validation beats one that doesn't, whatever the scores
+		passes, bestPasses := len(issues) == 0, len(bestIssues) == 0
+		if attempt == 1 || passes && !bestPasses || passes == bestPasses && critique.Score() > bestScore {
+			best, bestIssues, bestScore = message, issues, critique.Score()
+		}
+		if critique.Score() >= threshold || len(critique.Problems) == 0 {
+			return best, bestIssues
+		}
+		if attempt > critiqueRegenerations {
+			fmt.Fprintf(os.Stderr, "⚠️  Still below the critique threshold (%d/10); keeping the best scored message\n", threshold)
+			return best, bestIssues
+		}
+
+		fmt.Fprintf(os.Stderr, "⚠️  Scored below the critique threshold (%d/10), regenerating (attempt %d/%d):\n", threshold, attempt+1, critiqueRegenerations+1)
+		for _, problem := range critique.Problems {
+			fmt.Fprintf(os.Stderr, "   - %s\n", problem)
+		}
+		client.SetCorrection(message, critique.Problems)
+		message, issues, err = regenerate()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\n⚠️  Could not regenerate the message: %v\n", err)
+			return best, bestIssues
+		}
+	}
+}
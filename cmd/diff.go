@@ -1,31 +1,50 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 
-	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/hunkim/sgit/pkg/render"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
-	"github.com/spf13/viper"
 )
 
 var (
-	diffNoAI bool
+	diffAI          bool
+	diffNoAI        bool
+	diffSummaryOnly bool
+	diffFormat      string
 )
 
 // diffCmd represents the diff command
 var diffCmd = &cobra.Command{
-	Use:   "diff [files...]",
+	Use:   "diff [<commit> [<commit>] | <commit>..<commit>] [--] [files...]",
 	Short: "Show changes with AI summary (default)",
 	Long: `Show changes between commits, commit and working tree, etc. with AI-powered summaries by default.
-Supports all git diff options for full compatibility. Use --no-ai to disable AI analysis.`,
+Supports all git diff options for full compatibility. Use --no-ai to disable AI analysis.
+
+Revisions work as in git diff, and the summary is told what they compare; for a
+range the commits in it are summarized along with the diff. Use --summary-only to
+print just the AI summary without the raw diff.
+
+--format picks how the summary is shown: ansi (styled markdown), plain, markdown, or
+html (a standalone report of just the summary). The default, auto, is ansi on a
+terminal and plain otherwise; set output_format in the config to change it.
+
+Set diff_ai: false in the config to show plain git diff unless --ai is given.
+
+Examples:
+  sgit diff HEAD~3              # working tree against HEAD~3
+  sgit diff v1.2.0..v1.3.0      # between two tags
+  sgit diff main...feature      # what feature changed since it branched from main
+  sgit diff a1b2c3d^! --summary-only   # just the changes of one commit
+  sgit diff main...feature --format html > review.html`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runDiff(cmd, args); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
@@ -34,7 +53,10 @@ func init() {
 	rootCmd.AddCommand(diffCmd)
 	
 	// AI-specific flags
+	diffCmd.Flags().BoolVar(&diffAI, "ai", false, "use the AI summary even when diff_ai is false in config")
 	diffCmd.Flags().BoolVar(&diffNoAI, "no-ai", false, "disable AI summary and use standard git diff")
+	diffCmd.Flags().BoolVar(&diffSummaryOnly, "summary-only", false, "print only the AI summary, not the raw diff")
+	diffCmd.Flags().StringVar(&diffFormat, "format", "", "summary output format (auto|ansi|plain|markdown|html)")
 	
 	// Standard git diff flags - we'll pass these through to git
 	diffCmd.Flags().Bool("cached", false, "show diff of staged changes")
@@ -64,8 +86,8 @@ func runDiff(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not a git repository")
 	}
 
-	// Use AI summary by default, unless --no-ai is specified
-	if !diffNoAI {
+	// Use AI summary by default, unless --no-ai is specified or diff_ai says otherwise
+	if useAIFor("diff", true, diffAI, diffNoAI) {
 		return runDiffWithAISummary(cmd, args)
 	}
 
@@ -74,6 +96,14 @@ func runDiff(cmd *cobra.Command, args []string) error {
 }
 
 func runDiffWithAISummary(cmd *cobra.Command, args []string) error {
+	renderer, err := outputRenderer(diffFormat)
+	if err != nil {
+		return err
+	}
+	// An HTML report holds only the summary
+	report := renderer.Format() == render.HTML
+	summaryOnly := diffSummaryOnly || report
+
 	// Check configuration and setup if needed
 	if err := ensureConfiguration(); err != nil {
 		return err
@@ -82,7 +112,7 @@ func runDiffWithAISummary(cmd *cobra.Command, args []string) error {
 	// First, get the git diff output
 	diff, err := getGitDiffOutput(cmd, args)
 	if err != nil {
-		return fmt.Errorf("error getting git diff: %v", err)
+		return fmt.Errorf("error getting git diff: %w", err)
 	}
 
 	if strings.TrimSpace(diff) == "" {
@@ -91,20 +121,38 @@ func runDiffWithAISummary(cmd *cobra.Command, args []string) error {
 	}
 
 	// Show the regular diff first
-	fmt.Println("=== GIT DIFF ===")
-	fmt.Println(diff)
-	fmt.Println()
+	if !summaryOnly {
+		fmt.Println("=== GIT DIFF ===")
+		fmt.Println(diff)
+		fmt.Println()
+	}
 
 	// Generate AI summary with streaming
-	apiKey := viper.GetString("upstage_api_key")
-	modelName := viper.GetString("upstage_model_name")
-	
-	client := solar.NewClient(apiKey, modelName, getEffectiveLanguage())
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
 	
-	fmt.Println("=== AI SUMMARY ===")
-	_, err = client.SummarizeDiffStream(diff)
+	scope, commits := describeDiffScope(cmd, args)
+
+	if !summaryOnly {
+		fmt.Println("=== AI SUMMARY ===")
+	}
+	if !report {
+		if scope != "" {
+			statusf("🔍 %s\n", scope)
+		}
+		printContentStats("Diff analysis", diff)
+	}
+	if diff, err = checkDiffSize(diff); err != nil {
+		return err
+	}
+	printer := newStreamPrinter("")
+	printer.renderer = renderer
+	_, err = client.SummarizeRevisionDiffStream(cmd.Context(), diff, scope, commits, printer.Write)
+	printer.Done()
 	if err != nil {
-		return fmt.Errorf("error generating diff summary: %v", err)
+		return fmt.Errorf("error generating diff summary: %w", err)
 	}
 
 	fmt.Println() // Add newline after streaming output
@@ -118,7 +166,7 @@ func executeGitDiffPassthrough(cobraCmd *cobra.Command, args []string) error {
 	// Add all the flags that were set (excluding our custom AI flags)
 	cobraCmd.Flags().Visit(func(flag *pflag.Flag) {
 		flagName := flag.Name
-		if flagName == "no-ai" {
+		if isGlobalFlag(flag) || flagName == "ai" || flagName == "no-ai" || flagName == "summary-only" || flagName == "format" {
 			return // Skip our custom AI flags
 		}
 		
@@ -152,7 +200,7 @@ func getGitDiffOutput(cmd *cobra.Command, args []string) (string, error) {
 	// Add all the flags that were set (excluding our custom AI flags)
 	cmd.Flags().Visit(func(flag *pflag.Flag) {
 		flagName := flag.Name
-		if flagName == "no-ai" {
+		if isGlobalFlag(flag) || flagName == "ai" || flagName == "no-ai" || flagName == "summary-only" || flagName == "format" {
 			return // Skip our custom AI flags
 		}
 		
@@ -172,11 +220,67 @@ func getGitDiffOutput(cmd *cobra.Command, args []string) (string, error) {
 	gitArgs = append(gitArgs, args...)
 	
 	// Execute git command and capture output
-	gitCmd := exec.Command("git", gitArgs...)
-	output, err := gitCmd.Output()
+	return gitRepo.Diff(cmd.Context(), gitArgs[1:]...)
+} 
+// describeDiffScope explains what the revision arguments of a diff compare and, for
+// ranges, lists the commits in the range. Both are empty without revisions.
+func describeDiffScope(cmd *cobra.Command, args []string) (string, string) {
+	// Paths after -- are never revisions
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		args = args[:dash]
+	}
+	cached, _ := cmd.Flags().GetBool("cached")
+	staged, _ := cmd.Flags().GetBool("staged")
+
+	var revs []string
+	for _, arg := range args {
+		switch {
+		case strings.HasSuffix(arg, "^!"):
+			commit := strings.TrimSuffix(arg, "^!")
+			return fmt.Sprintf("The diff shows the changes introduced by commit %s.", commit),
+				diffRangeCommits("-1", commit)
+		case strings.Contains(arg, "..."):
+			from, to, _ := strings.Cut(arg, "...")
+			from, to = defaultRev(from), defaultRev(to)
+			return fmt.Sprintf("The diff shows the changes on %s since it diverged from %s.", to, from),
+				diffRangeCommits(from + ".." + to)
+		case strings.Contains(arg, ".."):
+			from, to, _ := strings.Cut(arg, "..")
+			from, to = defaultRev(from), defaultRev(to)
+			return fmt.Sprintf("The diff shows the changes from %s to %s.", from, to),
+				diffRangeCommits(from + ".." + to)
+		}
+		if _, err := runGitOutput("rev-parse", "--verify", "--quiet", arg+"^{commit}"); err == nil {
+			revs = append(revs, arg)
+		}
+	}
+
+	switch {
+	case len(revs) >= 2:
+		return fmt.Sprintf("The diff shows the changes from %s to %s.", revs[0], revs[1]),
+			diffRangeCommits(revs[0] + ".." + revs[1])
+	case len(revs) == 1 && (cached || staged):
+		return fmt.Sprintf("The diff shows the staged changes compared to %s.", revs[0]), ""
+	case len(revs) == 1:
+		return fmt.Sprintf("The diff shows the working tree compared to %s, including commits made since then and uncommitted changes.", revs[0]),
+			diffRangeCommits(revs[0] + "..HEAD")
+	}
+	return "", ""
+}
+
+// defaultRev returns HEAD for the empty side of a range like "main.."
+func defaultRev(rev string) string {
+	if rev == "" {
+		return "HEAD"
+	}
+	return rev
+}
+
+// diffRangeCommits lists the commits of a range for the summary prompt
+func diffRangeCommits(args ...string) string {
+	output, err := gitRepo.Log(context.Background(), append([]string{"--oneline", "--no-merges", "-n", "100"}, args...)...)
 	if err != nil {
-		return "", err
+		return ""
 	}
-	
-	return string(output), nil
-} 
\ No newline at end of file
+	return strings.TrimSpace(output)
+}
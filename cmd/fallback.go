@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/viper"
+)
+
+// What sgit commit does when the AI message can't be generated, set with "fallback"
+// in config
+const (
+	fallbackEditor   = "editor"   // edit a rule-based draft (the default)
+	fallbackTemplate = "template" // use the rule-based draft as it is
+	fallbackFail     = "fail"     // abort with the error
+)
+
+// commitFallbackMode returns the configured fallback for failed AI messages
+func commitFallbackMode() (string, error) {
+	mode := strings.ToLower(strings.TrimSpace(viper.GetString("fallback")))
+	switch mode {
+	case "":
+		return fallbackEditor, nil
+	case fallbackEditor, fallbackTemplate, fallbackFail:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid fallback '%s' in config (use editor, template or fail)", mode)
+}
+
+// apiUnavailable reports whether err means the API couldn't be used for now: it
+// couldn't be reached, failed on its side (5xx), or kept rate limiting until the
+// retries ran out. Only then is a draft message a fallback; errors such as a rejected
+// API key or a prompt too large are returned, so they aren't hidden behind a draft.
+func apiUnavailable(err error) bool {
+	var apiErr *solar.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode >= 500 {
+		return true
+	}
+	return errors.Is(err, solar.ErrNetwork) || errors.Is(err, solar.ErrRateLimited)
+}
+
+// draftCommitMessage builds a commit message from the staged files alone, for when the
+// AI can't be reached: a subject from the --type/--scope flags or the kind of files
+// changed, and a body listing the files with their line counts. base is the commit the
+// staged changes are compared with ("" for HEAD). It also returns the diffstat.
+func draftCommitMessage(base string) (string, string, error) {
+	numstatArgs := []string{"diff", "--cached", "--numstat", "-M"}
+	statArgs := []string{"diff", "--cached", "--stat", "-M"}
+	if base != "" {
+		numstatArgs = append(numstatArgs, base)
+		statArgs = append(statArgs, base)
+	}
+	numstat, err := runGitOutput(numstatArgs...)
+	if err != nil {
+		return "", "", fmt.Errorf("error getting staged files: %w", err)
+	}
+	stat, _ := runGitOutput(statArgs...)
+
+	var files, body []string
+	for _, line := range strings.Split(strings.TrimSpace(numstat), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		file := fields[2]
+		// Renames are shown as "old => new" or "dir/{old => new}"
+		if strings.Contains(file, " => ") {
+			file = strings.NewReplacer("{", "", "}", "").Replace(file)
+			file = file[strings.LastIndex(file, " => ")+len(" => "):]
+		}
+		files = append(files, file)
+		if fields[0] == "-" {
+			body = append(body, fmt.Sprintf("- %s (binary)", fields[2]))
+		} else {
+			body = append(body, fmt.Sprintf("- %s (+%s -%s)", fields[2], fields[0], fields[1]))
+		}
+	}
+	if len(files) == 0 {
+		return "", "", fmt.Errorf("no staged changes to describe")
+	}
+
+	commitKind := commitType
+	if commitKind == "" {
+		commitKind = draftCommitType(files)
+	}
+	subject := commitKind
+	if commitScope != "" {
+		subject += "(" + commitScope + ")"
+	}
+	subject += ": update " + draftSubjectTarget(files)
+
+	message := subject + "\n\n" + strings.Join(body, "\n")
+	return applyGitmoji(message), strings.TrimRight(stat, "\n"), nil
+}
+
+// draftCommitType guesses the conventional commit type from the kind of files changed
+func draftCommitType(files []string) string {
+	docs, tests, ci := true, true, true
+	for _, file := range files {
+		ext := strings.ToLower(path.Ext(file))
+		docs = docs && (ext == ".md" || ext == ".rst" || ext == ".txt" || ext == ".adoc" || strings.HasPrefix(file, "docs/"))
+		tests = tests && isTestFile(file)
+		ci = ci && (strings.HasPrefix(file, ".github/") || strings.HasPrefix(file, ".gitlab-ci") || strings.HasPrefix(file, ".circleci/"))
+	}
+	switch {
+	case docs:
+		return "docs"
+	case tests:
+		return "test"
+	case ci:
+		return "ci"
+	}
+	return "chore"
+}
+
+// draftSubjectTarget names what changed: the file, the directory all files share, or
+// the number of files
+func draftSubjectTarget(files []string) string {
+	if len(files) == 1 {
+		return files[0]
+	}
+	dir := path.Dir(files[0])
+	for _, file := range files[1:] {
+		for dir != "." && !strings.HasPrefix(file, dir+"/") {
+			dir = path.Dir(dir)
+		}
+	}
+	if dir != "." {
+		return fmt.Sprintf("%s (%d files)", dir, len(files))
+	}
+	return fmt.Sprintf("%d files", len(files))
+}
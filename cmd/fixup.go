@@ -0,0 +1,403 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// fixupMaxCandidates bounds how many earlier commits are considered
+	fixupMaxCandidates = 30
+	// fixupShownCandidates is how many of the best candidates are listed and sent to the AI
+	fixupShownCandidates = 8
+)
+
+var (
+	fixupNoAI       bool
+	fixupDryRun     bool
+	fixupAutosquash bool
+	fixupBase       string
+)
+
+var (
+	// unifiedHunkPattern reads the old side of a hunk header ("@@ -12,3 +12,4 @@")
+	unifiedHunkPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+\d+(?:,\d+)? @@`)
+	// blameCommitPattern matches the line starting each entry of git blame --porcelain
+	blameCommitPattern = regexp.MustCompile(`^([0-9a-f]{40}) \d+ \d+`)
+)
+
+// fixupCmd turns the staged change into a fixup commit for the commit it belongs to
+var fixupCmd = &cobra.Command{
+	Use:   "fixup [--base <commit>] [--autosquash] [--dry-run] [--no-ai]",
+	Short: "Commit the staged change as a fixup of the earlier commit it belongs to",
+	Long: `Find the earlier commit the staged change corrects and create a
+'git commit --fixup=<commit>' for it. Candidates are the commits not yet pushed to the
+upstream branch (or after --base, or the last 30), ranked by how many of the changed
+lines they last touched (git blame) and how many of the same files they changed.
+Solar LLM reads the change and the best candidates and picks the one it belongs to.
+Nothing is committed without confirmation.
+
+With --autosquash (or when you agree afterwards), the fixup is squashed into its
+commit right away with 'git rebase -i --autosquash', without opening an editor.
+
+Examples:
+  sgit add -p && sgit fixup
+  sgit fixup --autosquash
+  sgit fixup --base main --dry-run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runFixup(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fixupCmd)
+
+	fixupCmd.Flags().StringVar(&fixupBase, "base", "", "only consider commits after this one (default: the upstream branch)")
+	fixupCmd.Flags().BoolVar(&fixupAutosquash, "autosquash", false, "squash the fixup into its commit right away")
+	fixupCmd.Flags().BoolVarP(&fixupDryRun, "dry-run", "n", false, "show the chosen commit without committing")
+	fixupCmd.Flags().BoolVar(&fixupNoAI, "no-ai", false, "choose by blame and file overlap only")
+}
+
+// fixupCandidate is an earlier commit the staged change may belong to
+type fixupCandidate struct {
+	sha     string
+	short   string
+	subject string
+	files   []string
+	// blamedLines is how many of the changed lines (or the lines next to insertions)
+	// this commit last touched
+	blamedLines int
+	// sharedFiles is how many staged files this commit also changed
+	sharedFiles int
+}
+
+func (c *fixupCandidate) score() int {
+	return c.blamedLines*3 + c.sharedFiles
+}
+
+// evidence describes why the candidate is ranked where it is
+func (c *fixupCandidate) evidence() string {
+	var parts []string
+	if c.blamedLines > 0 {
+		parts = append(parts, fmt.Sprintf("last touched %d changed line(s)", c.blamedLines))
+	}
+	if c.sharedFiles > 0 {
+		parts = append(parts, fmt.Sprintf("changed %d of the same file(s)", c.sharedFiles))
+	}
+	if len(parts) == 0 {
+		return "no overlap"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func runFixup(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	diff, err := getGitDiff()
+	if err != nil {
+		return fmt.Errorf("error getting staged changes: %w", err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		return fmt.Errorf("no staged changes; stage the fix first (e.g. 'sgit add -p')")
+	}
+
+	candidates, err := findFixupCandidates()
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no earlier commits to fix up (all are pushed, or use --base)")
+	}
+	if err := rankFixupCandidates(candidates); err != nil {
+		return err
+	}
+	if len(candidates) > fixupShownCandidates {
+		candidates = candidates[:fixupShownCandidates]
+	}
+
+	fmt.Println("🔎 Candidate commits:")
+	for i, candidate := range candidates {
+		fmt.Printf("  %d. %s %s\n     %s\n", i+1, candidate.short, candidate.subject, candidate.evidence())
+	}
+	fmt.Println()
+
+	choice := 0
+	if len(candidates) > 1 && useAIFor("fixup", true, false, fixupNoAI) {
+		picked, ok, err := chooseFixupTarget(cmd, diff, candidates)
+		if err != nil {
+			return err
+		}
+		if ok {
+			choice = picked
+		}
+	}
+
+	if fixupDryRun {
+		fmt.Printf("💡 Dry run: would run git commit --fixup=%s (%s)\n", candidates[choice].short, candidates[choice].subject)
+		return nil
+	}
+
+	answer := strings.ToLower(ask(fmt.Sprintf("Fix up %d (%s %s)? (Enter = yes, another number, or n): ",
+		choice+1, candidates[choice].short, candidates[choice].subject)))
+	switch {
+	case answer == "" || answer == "y" || answer == "yes":
+	case answer == "n" || answer == "no" || answer == "q":
+		fmt.Println("Nothing committed")
+		return nil
+	default:
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 1 || n > len(candidates) {
+			return fmt.Errorf("invalid answer '%s' (use 1-%d)", answer, len(candidates))
+		}
+		choice = n - 1
+	}
+	target := candidates[choice]
+
+	gitCmd := exec.Command("git", "commit", "--fixup="+target.sha)
+	gitCmd.Stdin = os.Stdin
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	if err := gitCmd.Run(); err != nil {
+		return fmt.Errorf("git commit --fixup failed: %w", err)
+	}
+
+	// --yes alone never rewrites history; that takes --autosquash
+	if !fixupAutosquash && (assumeYes || !confirm(fmt.Sprintf("Squash it into %s now (git rebase --autosquash)? (y/n): ", target.short))) {
+		fmt.Printf("💡 Squash it later with: git rebase -i --autosquash %s~1\n", target.short)
+		return nil
+	}
+	return autosquashInto(target)
+}
+
+// findFixupCandidates lists the commits the staged change may fix up, newest first:
+// those after --base, else those not on the upstream branch, else the last ones
+func findFixupCandidates() ([]*fixupCandidate, error) {
+	logArgs := []string{"log", "-" + strconv.Itoa(fixupMaxCandidates), "--no-merges", "--format=%x01%H%x00%h%x00%s", "--name-only"}
+	switch {
+	case fixupBase != "":
+		logArgs = append(logArgs, fixupBase+"..HEAD")
+	case hasUpstream():
+		logArgs = append(logArgs, "@{upstream}..HEAD")
+	default:
+		logArgs = append(logArgs, "HEAD")
+	}
+	output, err := runGitOutput(logArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("error reading commits: %w", err)
+	}
+
+	var candidates []*fixupCandidate
+	for _, record := range strings.Split(output, "\x01") {
+		header, files, _ := strings.Cut(record, "\n")
+		fields := strings.SplitN(header, "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		// Fixups of earlier commits are not targets themselves
+		subject := fields[2]
+		if strings.HasPrefix(subject, "fixup! ") || strings.HasPrefix(subject, "squash! ") || strings.HasPrefix(subject, "amend! ") {
+			continue
+		}
+		candidate := &fixupCandidate{sha: fields[0], short: fields[1], subject: subject}
+		for _, file := range strings.Split(files, "\n") {
+			if file = strings.TrimSpace(file); file != "" {
+				candidate.files = append(candidate.files, file)
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+// hasUpstream reports whether the current branch tracks a remote branch
+func hasUpstream() bool {
+	_, err := runGitOutput("rev-parse", "--verify", "--quiet", "@{upstream}")
+	return err == nil
+}
+
+// rankFixupCandidates scores the candidates by blame and file overlap with the staged
+// change, best first. Ties keep the newest first.
+func rankFixupCandidates(candidates []*fixupCandidate) error {
+	changed, err := runGitOutput("diff", "--cached", "--no-color", "--no-ext-diff", "-U0")
+	if err != nil {
+		return fmt.Errorf("error getting staged changes: %w", err)
+	}
+
+	bySHA := make(map[string]*fixupCandidate)
+	for _, candidate := range candidates {
+		bySHA[candidate.sha] = candidate
+	}
+
+	// The diff names files from the top of the repository, but blame takes them
+	// relative to the current directory
+	prefix, err := runGitOutput("rev-parse", "--show-prefix")
+	if err != nil {
+		return fmt.Errorf("error finding the repository root: %w", err)
+	}
+	prefix = strings.TrimSpace(prefix)
+
+	var path string
+	for _, line := range strings.Split(changed, "\n") {
+		if name, found := strings.CutPrefix(line, "--- a/"); found {
+			path = name
+			continue
+		}
+		if strings.HasPrefix(line, "--- /dev/null") {
+			// A new file has no earlier lines to blame
+			path = ""
+			continue
+		}
+		match := unifiedHunkPattern.FindStringSubmatch(line)
+		if match == nil || path == "" {
+			continue
+		}
+		start, _ := strconv.Atoi(match[1])
+		count := 1
+		if match[2] != "" {
+			count, _ = strconv.Atoi(match[2])
+		}
+		// An insertion changes no lines; the line it follows is the closest evidence
+		if count == 0 {
+			if start == 0 {
+				continue
+			}
+			count = 1
+		}
+		rel, err := filepath.Rel(prefix, path)
+		if err != nil {
+			continue
+		}
+		blame, err := runGitOutput("blame", "--porcelain", "-L", fmt.Sprintf("%d,+%d", start, count), "HEAD", "--", filepath.ToSlash(rel))
+		if err != nil {
+			continue
+		}
+		for _, blameLine := range strings.Split(blame, "\n") {
+			if match := blameCommitPattern.FindStringSubmatch(blameLine); match != nil {
+				if candidate, ok := bySHA[match[1]]; ok {
+					candidate.blamedLines++
+				}
+			}
+		}
+	}
+	staged := make(map[string]bool)
+	if names, err := runGitOutput("diff", "--cached", "--name-only"); err == nil {
+		for _, name := range strings.Split(strings.TrimSpace(names), "\n") {
+			staged[name] = true
+		}
+	}
+
+	for _, candidate := range candidates {
+		for _, file := range candidate.files {
+			if staged[file] {
+				candidate.sharedFiles++
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score() > candidates[j].score()
+	})
+	return nil
+}
+
+// chooseFixupTarget asks the model which candidate the change belongs to. It reports
+// the chosen candidate's index, or false when the answer couldn't be used; the only
+// error is solar.ErrDryRun.
+func chooseFixupTarget(cmd *cobra.Command, diff string, candidates []*fixupCandidate) (int, bool, error) {
+	if err := ensureConfiguration(); err != nil {
+		fmt.Printf("⚠️  %v; choosing without AI\n", err)
+		return 0, false, nil
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		fmt.Printf("⚠️  %v; choosing without AI\n", err)
+		return 0, false, nil
+	}
+
+	var listing strings.Builder
+	for i, candidate := range candidates {
+		files := candidate.files
+		if len(files) > 10 {
+			files = append(files[:10:10], fmt.Sprintf("and %d more", len(candidate.files)-10))
+		}
+		fmt.Fprintf(&listing, "%d. %s %s\n   files: %s\n   overlap: %s\n", i+1, candidate.short, candidate.subject,
+			strings.Join(files, ", "), candidate.evidence())
+	}
+
+	statusf("Matching the change to a commit with Solar LLM...\n")
+	response, err := client.ChooseFixupTarget(cmd.Context(), diff, listing.String())
+	if isDryRun(err) {
+		return 0, false, err
+	}
+	if err != nil {
+		fmt.Printf("⚠️  Could not get an AI choice: %v\n", err)
+		return 0, false, nil
+	}
+
+	choice := -1
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "*"))
+		label, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToUpper(strings.TrimSpace(label)) {
+		case "CHOICE":
+			if n, err := strconv.Atoi(strings.Trim(value, ". ")); err == nil && n >= 1 && n <= len(candidates) {
+				choice = n - 1
+			}
+		case "WHY":
+			fmt.Printf("💡 %s\n", value)
+		}
+	}
+	if choice < 0 {
+		return 0, false, nil
+	}
+	fmt.Printf("🤖 Best match: %s %s\n\n", candidates[choice].short, candidates[choice].subject)
+	return choice, true, nil
+}
+
+// autosquashInto squashes the fixup commit into target with a non-interactive
+// git rebase -i --autosquash
+func autosquashInto(target *fixupCandidate) error {
+	if pushed, err := runGitOutput("branch", "-r", "--contains", target.sha); err == nil && strings.TrimSpace(pushed) != "" {
+		fmt.Printf("⚠️  %s is already pushed; the rebase rewrites it and needs a force push\n", target.short)
+	}
+
+	rebaseArgs := []string{"rebase", "-i", "--autosquash", "--autostash"}
+	if _, err := runGitOutput("rev-parse", "--verify", "--quiet", target.sha+"^"); err == nil {
+		rebaseArgs = append(rebaseArgs, target.sha+"^")
+	} else {
+		rebaseArgs = append(rebaseArgs, "--root")
+	}
+
+	// The todo list autosquash prepared is accepted as is
+	gitCmd := exec.Command("git", rebaseArgs...)
+	gitCmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=true")
+	gitCmd.Stdin = os.Stdin
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	if err := gitCmd.Run(); err != nil {
+		if isRebaseInProgress() {
+			printRebaseInstructions()
+			return nil
+		}
+		return fmt.Errorf("rebase failed: %w", err)
+	}
+	fmt.Printf("✅ Squashed the fixup into %s\n", target.short)
+	return nil
+}
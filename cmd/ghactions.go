@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hunkim/sgit/pkg/actions"
+)
+
+// writeGitHubResults prints annotations for GitHub Actions to pick up and adds
+// summary to the job summary. Outside a workflow only the annotations are printed.
+func writeGitHubResults(annotations []actions.Annotation, summary string) {
+	actions.Write(os.Stdout, annotations)
+	written, err := actions.AppendSummary(summary)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not write the job summary: %v\n", err)
+	} else if !written {
+		statusln("💡 GITHUB_STEP_SUMMARY is not set, skipped the job summary")
+	}
+}
@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"os/exec"
 
+	"github.com/hunkim/sgit/pkg/git"
+	"github.com/hunkim/sgit/pkg/logging"
 	"github.com/spf13/cobra"
 )
 
+// gitRepo is the repository the AI commands read diffs, logs and file lists from
+var gitRepo git.Repository = git.NewCLI("")
+
 // gitCmd represents passthrough git commands
 var gitCmd = &cobra.Command{
 	Use:   "git",
@@ -23,6 +29,7 @@ func init() {
 }
 
 func executeGitCommand(args []string) {
+	logging.Debug("git command", "args", args)
 	gitCmd := exec.Command("git", args...)
 	gitCmd.Stdin = os.Stdin
 	gitCmd.Stdout = os.Stdout
@@ -34,4 +41,9 @@ func executeGitCommand(args []string) {
 		}
 		os.Exit(1)
 	}
-} 
\ No newline at end of file
+}
+
+// runGitOutput runs a git command and returns its stdout
+func runGitOutput(args ...string) (string, error) {
+	return gitRepo.Output(context.Background(), args...)
+}
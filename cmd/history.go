@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/history"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	historyAll   bool
+	historyLimit int
+)
+
+// historyStatusIcons marks each outcome in the history list
+var historyStatusIcons = map[string]string{
+	history.StatusGenerated: "💭",
+	history.StatusAccepted:  "✅",
+	history.StatusRejected:  "❌",
+	history.StatusAborted:   "🚪",
+	history.StatusFailed:    "⚠️ ",
+}
+
+// historyCmd lists the commit messages sgit generated
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse the commit messages sgit generated",
+	Long: `List the commit messages sgit generated in this repository, newest first, with
+whether each was committed, rejected or abandoned. Messages are kept locally in
+~/.config/sgit/history.jsonl (set history: false in config to turn this off).
+
+'sgit commit --reuse-last' commits with the last generated message again, e.g. after
+the editor was closed by accident.
+
+Examples:
+  sgit history
+  sgit history --all -n 50
+  sgit history show 2
+  sgit commit -m "$(sgit history show 1)"`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runHistory(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// historyShowCmd prints one message in full
+var historyShowCmd = &cobra.Command{
+	Use:   "show [<n>]",
+	Short: "Print a generated message in full (1 is the newest)",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runHistoryShow(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// historyClearCmd removes the history
+var historyClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all saved messages",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runHistoryClear(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyClearCmd)
+
+	historyCmd.PersistentFlags().BoolVar(&historyAll, "all", false, "include messages from every repository")
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "number of messages to list")
+}
+
+// newHistoryStore opens the message history, or returns nil when history: false
+func newHistoryStore() (*history.Store, error) {
+	if viper.IsSet("history") && !viper.GetBool("history") {
+		return nil, nil
+	}
+	path, err := history.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return history.New(path, viper.GetInt("history_size")), nil
+}
+
+// historyRepo identifies the current repository in the history, or "" outside one
+func historyRepo() string {
+	root, err := runGitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(root)
+}
+
+// listHistory returns the saved messages of the current repository (or all with --all)
+func listHistory() ([]history.Entry, error) {
+	store, err := newHistoryStore()
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return nil, fmt.Errorf("history is turned off (history: false in config)")
+	}
+
+	repo := ""
+	if !historyAll {
+		if repo = historyRepo(); repo == "" {
+			return nil, fmt.Errorf("not a git repository (use --all for every repository)")
+		}
+	}
+	return store.List(repo)
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	entries, err := listHistory()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No generated commit messages yet")
+		return nil
+	}
+
+	if historyLimit > 0 && len(entries) > historyLimit {
+		entries = entries[:historyLimit]
+	}
+	for i, entry := range entries {
+		subject, _, _ := strings.Cut(entry.Text(), "\n")
+		commit := ""
+		if entry.Commit != "" {
+			commit = " " + entry.Commit[:min(len(entry.Commit), 7)]
+		}
+		fmt.Printf("%3d  %s  %s %-9s%s  %s\n", i+1, entry.Time.Local().Format("2006-01-02 15:04"),
+			historyStatusIcons[entry.Status], entry.Status, commit, subject)
+		if historyAll && entry.Repo != "" {
+			fmt.Printf("     %s\n", entry.Repo)
+		}
+	}
+	fmt.Println("\n💡 'sgit history show <n>' prints a message in full; 'sgit commit --reuse-last' commits with the newest")
+	return nil
+}
+
+func runHistoryShow(cmd *cobra.Command, args []string) error {
+	n := 1
+	if len(args) == 1 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+			return fmt.Errorf("invalid message number '%s'", args[0])
+		}
+	}
+
+	entries, err := listHistory()
+	if err != nil {
+		return err
+	}
+	if n > len(entries) {
+		return fmt.Errorf("there are only %d saved message(s)", len(entries))
+	}
+	fmt.Println(entries[n-1].Text())
+	return nil
+}
+
+func runHistoryClear(cmd *cobra.Command, args []string) error {
+	path, err := history.DefaultPath()
+	if err != nil {
+		return err
+	}
+	if !confirm(fmt.Sprintf("Remove all saved messages in %s? (y/n): ", path)) {
+		fmt.Println("Cancelled")
+		return nil
+	}
+	if err := history.New(path, 0).Clear(); err != nil {
+		return err
+	}
+	fmt.Println("✅ Removed the message history")
+	return nil
+}
+
+// messageRecord tracks one generated message in the history; a nil record (history
+// turned off or unavailable) does nothing
+type messageRecord struct {
+	store *history.Store
+	id    string
+}
+
+// recordGeneratedMessage saves a generated message before the user decides on it, so
+// it survives an aborted commit
+func recordGeneratedMessage(message string) *messageRecord {
+	store, err := newHistoryStore()
+	if err != nil || store == nil {
+		return nil
+	}
+	branch, _ := getCurrentBranch()
+	id, err := store.Add(history.Entry{Repo: historyRepo(), Branch: strings.TrimSpace(branch), Message: message})
+	if err != nil {
+		statusf("⚠️  Couldn't save the message to the history: %v\n", err)
+		return nil
+	}
+	return &messageRecord{store: store, id: id}
+}
+
+// finish records what became of the message; final is the committed message
+func (r *messageRecord) finish(status, final string) {
+	if r == nil {
+		return
+	}
+	update := history.Entry{Status: status, Final: final}
+	if status == history.StatusAccepted {
+		if head, err := runGitOutput("rev-parse", "HEAD"); err == nil {
+			update.Commit = strings.TrimSpace(head)
+		}
+	}
+	r.store.Update(r.id, update)
+}
+
+// lastGeneratedMessage returns the newest saved message of the current repository,
+// with a record to tell what became of it this time
+func lastGeneratedMessage() (*messageRecord, history.Entry, error) {
+	store, err := newHistoryStore()
+	if err != nil {
+		return nil, history.Entry{}, err
+	}
+	if store == nil {
+		return nil, history.Entry{}, fmt.Errorf("history is turned off (history: false in config)")
+	}
+	entries, err := store.List(historyRepo())
+	if err != nil {
+		return nil, history.Entry{}, err
+	}
+	if len(entries) == 0 {
+		return nil, history.Entry{}, fmt.Errorf("no generated commit message saved for this repository")
+	}
+	return &messageRecord{store: store, id: entries[0].ID}, entries[0], nil
+}
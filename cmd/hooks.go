@@ -0,0 +1,497 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/lint"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// hookMarker identifies hook scripts managed by sgit
+const hookMarker = "# sgit-managed hook"
+
+// supportedHooks maps each installable hook type to a short description
+var supportedHooks = map[string]string{
+	"prepare-commit-msg": "pre-fill the commit message with an AI-generated one",
+	"commit-msg":         "check the final commit message against the convention, with AI suggestions",
+	"pre-push":           "print an AI summary of the commits being pushed",
+}
+
+// Values of the commit_msg_check setting: what the commit-msg hook does with a
+// message that fails validation
+const (
+	commitMsgCheckBlock = "block"
+	commitMsgCheckWarn  = "warn"
+	commitMsgCheckOff   = "off"
+)
+
+// errCommitMsgRejected makes the commit-msg hook fail, aborting the commit
+var errCommitMsgRejected = errors.New("commit message failed validation")
+
+var (
+	hookTypes []string
+	hookForce bool
+)
+
+// hooksCmd groups the git hook management subcommands
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Install git hooks so plain git commit gets AI messages",
+	Long: `Manage git hooks that call sgit, so plain 'git commit' and IDE commits also
+benefit from AI-generated messages. Set SGIT_NO_HOOKS=1 to bypass the hooks.
+
+The commit-msg hook checks the final message, after your edits, with the same rules
+as sgit lint, and prints an AI rewrite that fixes the problems. A message that fails
+aborts the commit; set commit_msg_check: warn in config to only warn, or commit with
+SGIT_COMMIT_MSG_CHECK=off to skip the check once. An empty message is replaced with
+an AI-generated one.
+
+Examples:
+  sgit hooks install
+  sgit hooks install --hook-type prepare-commit-msg,commit-msg
+  sgit hooks uninstall --hook-type commit-msg`,
+}
+
+// hooksInstallCmd installs sgit hooks into the repository
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install sgit git hooks",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runHooksInstall(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// hooksUninstallCmd removes sgit hooks from the repository
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove sgit git hooks",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runHooksUninstall(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// hooksRunCmd is invoked by the installed hook scripts
+var hooksRunCmd = &cobra.Command{
+	Use:    "run <hook-type> [hook args...]",
+	Short:  "Run an sgit hook (called by the installed hook scripts)",
+	Hidden: true,
+	Args:   cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Hooks must never break the user's git workflow because of an AI failure;
+		// only a message rejected by commit_msg_check aborts the commit
+		if err := runHook(cmd.Context(), args[0], args[1:]); err != nil {
+			if errors.Is(err, errCommitMsgRejected) {
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stderr, "sgit %s hook: %v\n", args[0], err)
+		}
+	},
+	DisableFlagParsing: true,
+}
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksInstallCmd)
+	hooksCmd.AddCommand(hooksUninstallCmd)
+	hooksCmd.AddCommand(hooksRunCmd)
+
+	for _, c := range []*cobra.Command{hooksInstallCmd, hooksUninstallCmd} {
+		c.Flags().StringSliceVar(&hookTypes, "hook-type", []string{"prepare-commit-msg"}, "hook types (prepare-commit-msg, commit-msg, pre-push)")
+	}
+	hooksInstallCmd.Flags().BoolVar(&hookForce, "force", false, "replace existing hooks (they are backed up with a .sgit-backup suffix)")
+}
+
+// getHooksDir returns the hooks directory, honoring core.hooksPath and worktrees
+func getHooksDir() (string, error) {
+	output, err := runGitOutput("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", fmt.Errorf("not a git repository")
+	}
+	return filepath.Abs(strings.TrimSpace(output))
+}
+
+// hookScript returns the shell script installed for a hook type
+func hookScript(hookType string) string {
+	fallback := "sgit"
+	if exe, err := os.Executable(); err == nil {
+		fallback = exe
+	}
+
+	return fmt.Sprintf(`#!/bin/sh
+%s (installed by 'sgit hooks install')
+# Set SGIT_NO_HOOKS=1 to skip.
+[ -n "$SGIT_NO_HOOKS" ] && exit 0
+SGIT_BIN=$(command -v sgit 2>/dev/null || echo "%s")
+[ -x "$SGIT_BIN" ] || exit 0
+exec "$SGIT_BIN" hooks run %s "$@"
+`, hookMarker, fallback, hookType)
+}
+
+func validateHookTypes() error {
+	for _, hookType := range hookTypes {
+		if _, ok := supportedHooks[hookType]; !ok {
+			return fmt.Errorf("unsupported hook type '%s' (supported: prepare-commit-msg, commit-msg, pre-push)", hookType)
+		}
+	}
+	return nil
+}
+
+func runHooksInstall(cmd *cobra.Command, args []string) error {
+	if err := validateHookTypes(); err != nil {
+		return err
+	}
+
+	hooksDir, err := getHooksDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return fmt.Errorf("error creating hooks directory: %w", err)
+	}
+
+	for _, hookType := range hookTypes {
+		hookPath := filepath.Join(hooksDir, hookType)
+
+		if existing, err := os.ReadFile(hookPath); err == nil && !strings.Contains(string(existing), hookMarker) {
+			if !hookForce {
+				return fmt.Errorf("%s hook already exists at %s (use --force to replace it)", hookType, hookPath)
+			}
+			if err := os.Rename(hookPath, hookPath+".sgit-backup"); err != nil {
+				return fmt.Errorf("error backing up existing hook: %w", err)
+			}
+			fmt.Printf("📦 Backed up existing %s hook to %s.sgit-backup\n", hookType, hookPath)
+		}
+
+		if err := os.WriteFile(hookPath, []byte(hookScript(hookType)), 0755); err != nil {
+			return fmt.Errorf("error writing %s hook: %v", hookType, err)
+		}
+		fmt.Printf("✅ Installed %s hook: %s\n", hookType, supportedHooks[hookType])
+	}
+
+	return nil
+}
+
+func runHooksUninstall(cmd *cobra.Command, args []string) error {
+	if err := validateHookTypes(); err != nil {
+		return err
+	}
+
+	hooksDir, err := getHooksDir()
+	if err != nil {
+		return err
+	}
+
+	for _, hookType := range hookTypes {
+		hookPath := filepath.Join(hooksDir, hookType)
+
+		existing, err := os.ReadFile(hookPath)
+		if err != nil || !strings.Contains(string(existing), hookMarker) {
+			fmt.Printf("⏭️  No sgit %s hook installed\n", hookType)
+			continue
+		}
+
+		if err := os.Remove(hookPath); err != nil {
+			return fmt.Errorf("error removing %s hook: %v", hookType, err)
+		}
+
+		// Restore a hook that was backed up during install
+		if _, err := os.Stat(hookPath + ".sgit-backup"); err == nil {
+			if err := os.Rename(hookPath+".sgit-backup", hookPath); err != nil {
+				return fmt.Errorf("error restoring backed up hook: %w", err)
+			}
+			fmt.Printf("📦 Restored previous %s hook\n", hookType)
+		}
+
+		fmt.Printf("✅ Removed %s hook\n", hookType)
+	}
+
+	return nil
+}
+
+// runHook dispatches a hook invocation from git
+func runHook(ctx context.Context, hookType string, args []string) error {
+	switch hookType {
+	case "prepare-commit-msg":
+		return runPrepareCommitMsgHook(ctx, args)
+	case "commit-msg":
+		return runCommitMsgHook(ctx, args)
+	case "pre-push":
+		return runPrePushHook(ctx, args)
+	}
+	return fmt.Errorf("unsupported hook type '%s'", hookType)
+}
+
+// runPrepareCommitMsgHook pre-fills the message file for plain 'git commit'.
+// Args: <message file> [<source> [<sha>]]
+func runPrepareCommitMsgHook(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("missing commit message file argument")
+	}
+
+	// Only generate when git has no message of its own (-m, -F, merge, squash, amend)
+	if len(args) >= 2 && args[1] != "" && args[1] != "template" {
+		return nil
+	}
+
+	message, err := generateHookCommitMessage(ctx)
+	if err != nil || message == "" {
+		return err
+	}
+
+	existing, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("error reading commit message file: %w", err)
+	}
+
+	content := message + "\n" + string(existing)
+	return os.WriteFile(args[0], []byte(content), 0644)
+}
+
+// runCommitMsgHook fills in an AI message when the user left the message empty, and
+// otherwise checks the final message, after any edits, against the lint rules.
+// Args: <message file>
+func runCommitMsgHook(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("missing commit message file argument")
+	}
+
+	existing, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("error reading commit message file: %w", err)
+	}
+	if message := stripCommentLines(string(existing)); message != "" {
+		return checkCommitMsg(ctx, message)
+	}
+
+	message, err := generateHookCommitMessage(ctx)
+	if err != nil || message == "" {
+		return err
+	}
+
+	return os.WriteFile(args[0], []byte(message+"\n"), 0644)
+}
+
+// commitMsgCheckMode returns commit_msg_check from config: block (the default), warn
+// or off
+func commitMsgCheckMode() string {
+	mode, err := normalizeCommitMsgCheckMode(viper.GetString("commit_msg_check"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v, using %s\n", err, commitMsgCheckBlock)
+		return commitMsgCheckBlock
+	}
+	return mode
+}
+
+// normalizeCommitMsgCheckMode checks a commit_msg_check setting
+func normalizeCommitMsgCheckMode(value string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(value)); mode {
+	case "":
+		return commitMsgCheckBlock, nil
+	case commitMsgCheckBlock, commitMsgCheckWarn, commitMsgCheckOff:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid commit_msg_check '%s' (use block, warn or off)", value)
+}
+
+// checkCommitMsg validates a message written or edited by hand. When it fails, the
+// problems are printed with an AI rewrite that fixes them, and with commit_msg_check:
+// block the commit is aborted. Merges, reverts and fixup commits are left alone, as
+// git writes those messages.
+func checkCommitMsg(ctx context.Context, message string) error {
+	mode := commitMsgCheckMode()
+	if mode == commitMsgCheckOff {
+		return nil
+	}
+	for _, prefix := range []string{"Merge ", "Revert \"", "fixup!", "squash!", "amend!"} {
+		if strings.HasPrefix(message, prefix) {
+			return nil
+		}
+	}
+
+	issues := lint.Check(message, commitLintOptions())
+	if len(issues) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(os.Stderr, "⚠️  The commit message doesn't pass validation:")
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stderr, "   - %s\n", issue)
+	}
+
+	// The suggestion is best effort: without it the problems above still stand
+	if suggestion, err := suggestCommitMsg(ctx, message, issues); err != nil {
+		fmt.Fprintf(os.Stderr, "sgit: no AI suggestion: %v\n", err)
+	} else if suggestion != "" {
+		fmt.Fprintf(os.Stderr, "\n💡 Suggested message:\n\n%s\n\n", suggestion)
+	}
+
+	if mode == commitMsgCheckWarn {
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, "❌ Commit aborted. Fix the message, or commit anyway with SGIT_COMMIT_MSG_CHECK=off (or git commit --no-verify)")
+	return errCommitMsgRejected
+}
+
+// suggestCommitMsg has the AI rewrite a message so it fixes the issues, using the
+// staged files for context
+func suggestCommitMsg(ctx context.Context, message string, issues []lint.Issue) (string, error) {
+	client, err := newHookSolarClient()
+	if err != nil {
+		return "", err
+	}
+
+	problems := make([]string, len(issues))
+	for i, issue := range issues {
+		problems[i] = issue.String()
+	}
+	diffStat, _ := runGitOutput("diff", "--cached", "--stat")
+
+	fmt.Fprintln(os.Stderr, "sgit: asking Solar LLM for a better message...")
+	suggestion, err := client.RewriteCommitMessage(ctx, message, strings.Join(problems, "\n"), diffStat)
+	if err != nil {
+		return "", err
+	}
+	return formatCommitMessage(applyGitmoji(suggestion)), nil
+}
+
+// runPrePushHook prints an AI summary of the commits about to be pushed.
+// Git passes "<local ref> <local sha> <remote ref> <remote sha>" lines on stdin.
+func runPrePushHook(ctx context.Context, args []string) error {
+	const zeroSHA = "0000000000000000000000000000000000000000"
+
+	var logs []string
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 || fields[1] == zeroSHA {
+			continue
+		}
+
+		rangeSpec := fields[1]
+		if fields[3] != zeroSHA {
+			rangeSpec = fields[3] + ".." + fields[1]
+		} else {
+			// New remote branch: limit to commits not on any remote yet
+			rangeSpec = fields[1] + " --not --remotes"
+		}
+
+		logOutput, err := runGitOutput(append([]string{"log", "--oneline", "--no-merges", "-50"}, strings.Fields(rangeSpec)...)...)
+		if err == nil && strings.TrimSpace(logOutput) != "" {
+			logs = append(logs, fmt.Sprintf("%s → %s:\n%s", fields[0], fields[2], logOutput))
+		}
+	}
+
+	if len(logs) == 0 {
+		return nil
+	}
+
+	client, err := newHookSolarClient()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, "=== AI PUSH SUMMARY ===")
+	summary, err := client.AnalyzeLog(ctx, strings.Join(logs, "\n"), "commits about to be pushed")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, summary)
+	return nil
+}
+
+// newHookSolarClient creates a Solar client without the interactive setup,
+// since hooks may run without a terminal (e.g. from an IDE)
+func newHookSolarClient() (*solar.Client, error) {
+	if !aiConfigured() {
+		return nil, fmt.Errorf("no API key configured, run 'sgit config init'")
+	}
+	return newSolarClient()
+}
+
+// generateHookCommitMessage generates a commit message for the staged changes
+func generateHookCommitMessage(ctx context.Context) (string, error) {
+	// Nothing to describe, e.g. git commit --allow-empty
+	if staged, err := gitRepo.HasStagedChanges(ctx); err == nil && !staged {
+		return "", nil
+	}
+
+	// The subproject comes from scope_dirs, as in sgit commit
+	scopeDirPath, scope, err := resolveScopeDir(ctx, "")
+	if err != nil {
+		return "", err
+	}
+	// The ticket comes from the branch name, as in sgit commit
+	commitContext, err := commitContextProviders(commitContextOptions{scopeDir: scopeDirPath}).Collect(ctx)
+	if err != nil {
+		return "", err
+	}
+	diff, branch, recentCommits, fileList := commitContext.Diff, commitContext.Branch, commitContext.RecentCommits, commitContext.FileList
+	if strings.TrimSpace(diff) == "" {
+		return "", nil
+	}
+	warnHookLeftovers(diff)
+
+	client, err := newHookSolarClient()
+	if err != nil {
+		return "", err
+	}
+	hints := commitContext.Hints
+	if scopeDirPath != "" {
+		hints.Scope = scope
+	}
+	ticketKey := hints.TicketKey
+	client.SetCommitHints(hints)
+	client.SetContextSections(commitContext.Sections)
+
+	fmt.Fprintln(os.Stderr, "sgit: generating commit message with Solar LLM...")
+	generate := func() (string, []lint.Issue, error) {
+		return generateValidCommitMessage(client, func() (string, error) {
+			message, err := client.GenerateComprehensiveCommitMessage(ctx, diff, branch, recentCommits, fileList)
+			if err != nil {
+				return "", err
+			}
+			return applyGitmoji(message), nil
+		}, func(issues []lint.Issue, attempt, maxAttempts int) {
+			fmt.Fprintf(os.Stderr, "sgit: generated message failed validation, regenerating (attempt %d/%d)...\n", attempt, maxAttempts)
+		})
+	}
+	message, issues, err := generate()
+	if err != nil {
+		return "", err
+	}
+	// The critique pass is turned on for hooks with critique: true in config
+	if critiqueEnabled(false) {
+		message, issues = critiqueCommitMessage(ctx, client, message, issues, diff, fileList, generate)
+	}
+	printValidationIssues(issues)
+	return applyTrailers(message, ticketKey, ""), nil
+}
+
+// scissorsLine marks where git commit --verbose starts the diff in the message file;
+// everything below it is left out of the message
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+// stripCommentLines removes git comment lines, the --verbose diff and surrounding
+// whitespace from a message
+func stripCommentLines(message string) string {
+	message, _, _ = strings.Cut(message, scissorsLine)
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ignoreArtifactDirs are directory names whose contents are ignored as a whole
+var ignoreArtifactDirs = map[string]bool{
+	"build": true, "dist": true, "out": true, "target": true, "bin": true, "obj": true,
+	"node_modules": true, "vendor": true, "coverage": true, "tmp": true, "temp": true,
+	"__pycache__": true, ".venv": true, "venv": true, ".cache": true, ".idea": true,
+	".vscode": true, ".next": true, ".gradle": true, "logs": true,
+}
+
+// ignoreArtifactExts are file extensions that are ignored by pattern
+var ignoreArtifactExts = map[string]bool{
+	".log": true, ".tmp": true, ".swp": true, ".swo": true, ".bak": true, ".pyc": true,
+	".o": true, ".class": true, ".out": true, ".cache": true, ".pid": true,
+}
+
+var ignoreWrite bool
+
+// ignoreCmd groups the .gitignore helpers
+var ignoreCmd = &cobra.Command{
+	Use:   "ignore",
+	Short: "Manage .gitignore with AI suggestions",
+}
+
+// ignoreSuggestCmd proposes a complete .gitignore for the worktree
+var ignoreSuggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Propose a complete .gitignore with AI-grouped sections",
+	Long: `Scan the worktree (tracked file types, top-level entries, untracked paths and the
+current .gitignore) and let Solar LLM propose a complete .gitignore grouped into
+commented sections. Use --write to replace .gitignore with the proposal.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runIgnoreSuggest(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ignoreCmd)
+	ignoreCmd.AddCommand(ignoreSuggestCmd)
+
+	ignoreSuggestCmd.Flags().BoolVar(&ignoreWrite, "write", false, "replace .gitignore with the proposal (asks for confirmation)")
+}
+
+func runIgnoreSuggest(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+
+	root, err := runGitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("error finding repository root: %w", err)
+	}
+	gitignorePath := path.Join(strings.TrimSpace(root), ".gitignore")
+
+	projectFiles, err := describeProjectFiles()
+	if err != nil {
+		return err
+	}
+	untracked, _ := runGitOutput("ls-files", "--others", "--exclude-standard", "--directory")
+	current, _ := os.ReadFile(gitignorePath)
+
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	statusln("Generating .gitignore suggestions with Solar LLM...")
+	response, err := client.SuggestGitignore(cmd.Context(), projectFiles, limitLines(untracked, 300), string(current))
+	if err != nil {
+		return fmt.Errorf("error generating .gitignore: %w", err)
+	}
+	proposal := stripCodeFence(response) + "\n"
+
+	fmt.Println("\n=== PROPOSED .gitignore ===")
+	fmt.Print(proposal)
+	fmt.Println()
+
+	if !ignoreWrite {
+		fmt.Println("💡 Run 'sgit ignore suggest --write' to replace .gitignore with this proposal")
+		return nil
+	}
+
+	if !confirm("Replace .gitignore with this proposal? (y/n): ") {
+		fmt.Println(".gitignore unchanged")
+		return nil
+	}
+
+	if err := os.WriteFile(gitignorePath, []byte(proposal), 0644); err != nil {
+		return fmt.Errorf("error writing .gitignore: %w", err)
+	}
+	fmt.Printf("✅ Updated %s\n", gitignorePath)
+
+	// Patterns don't untrack files that are already committed; point them out
+	if tracked, _ := runGitOutput("ls-files", "--cached", "--ignored", "--exclude-standard"); strings.TrimSpace(tracked) != "" {
+		fmt.Println("⚠️  These tracked files now match .gitignore (untrack them with 'git rm --cached'):")
+		fmt.Print(limitLines(tracked, 20))
+	}
+	return nil
+}
+
+// describeProjectFiles summarizes the repository's top-level entries and tracked file types
+func describeProjectFiles() (string, error) {
+	tracked, err := runGitOutput("ls-files")
+	if err != nil {
+		return "", fmt.Errorf("error listing tracked files: %w", err)
+	}
+
+	topLevel := make(map[string]bool)
+	extCounts := make(map[string]int)
+	for _, file := range strings.Split(strings.TrimSpace(tracked), "\n") {
+		if file == "" {
+			continue
+		}
+		if i := strings.Index(file, "/"); i != -1 {
+			topLevel[file[:i+1]] = true
+		} else {
+			topLevel[file] = true
+		}
+		if ext := path.Ext(file); ext != "" {
+			extCounts[ext]++
+		}
+	}
+
+	entries := make([]string, 0, len(topLevel))
+	for entry := range topLevel {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+
+	exts := make([]string, 0, len(extCounts))
+	for ext := range extCounts {
+		exts = append(exts, ext)
+	}
+	sort.Slice(exts, func(i, j int) bool { return extCounts[exts[i]] > extCounts[exts[j]] })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Top-level entries: %s\n", strings.Join(entries, ", "))
+	b.WriteString("Tracked file types:")
+	for _, ext := range exts {
+		fmt.Fprintf(&b, " %s (%d)", ext, extCounts[ext])
+	}
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+// ignorePatternFor returns the .gitignore pattern to use for a file the AI recommended skipping
+func ignorePatternFor(file string) string {
+	parts := strings.Split(file, "/")
+	for i, part := range parts[:len(parts)-1] {
+		if ignoreArtifactDirs[part] {
+			if i == 0 {
+				return part + "/"
+			}
+			return strings.Join(parts[:i+1], "/") + "/"
+		}
+	}
+
+	if ext := strings.ToLower(path.Ext(file)); ignoreArtifactExts[ext] {
+		return "*" + ext
+	}
+	return "/" + file
+}
+
+// offerGitignorePatterns proposes .gitignore patterns for skipped files and appends
+// them after confirmation
+func offerGitignorePatterns(files []string) error {
+	existing := make(map[string]bool)
+	if content, err := os.ReadFile(".gitignore"); err == nil {
+		for _, line := range strings.Split(string(content), "\n") {
+			existing[strings.TrimSpace(line)] = true
+		}
+	}
+
+	var patterns []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		pattern := ignorePatternFor(file)
+		if seen[pattern] || existing[pattern] {
+			continue
+		}
+		seen[pattern] = true
+		patterns = append(patterns, pattern)
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	fmt.Println("\nSuggested .gitignore patterns for the skipped files:")
+	for _, pattern := range patterns {
+		fmt.Printf("  %s\n", pattern)
+	}
+	if !confirm("Append these patterns to .gitignore? (y/n): ") {
+		return nil
+	}
+
+	return appendGitignore(".gitignore", patterns)
+}
+
+// appendGitignore appends patterns to a .gitignore file, creating it if needed
+func appendGitignore(gitignorePath string, patterns []string) error {
+	content, err := os.ReadFile(gitignorePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading %s: %v", gitignorePath, err)
+	}
+
+	var b strings.Builder
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		b.WriteString("\n")
+	}
+	if len(content) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("# Added by sgit add --all-ai\n")
+	for _, pattern := range patterns {
+		b.WriteString(pattern + "\n")
+	}
+
+	file, err := os.OpenFile(gitignorePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %v", gitignorePath, err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(b.String()); err != nil {
+		return fmt.Errorf("error writing %s: %v", gitignorePath, err)
+	}
+	fmt.Printf("✅ Added %d pattern(s) to %s\n", len(patterns), gitignorePath)
+	return nil
+}
+
+// stripCodeFence removes a surrounding markdown code fence from a model response
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+
+	// Drop the opening fence line (with its optional language tag) and the closing fence
+	if newline := strings.Index(text, "\n"); newline != -1 {
+		text = text[newline+1:]
+	}
+	text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+	return strings.TrimSpace(text)
+}
+
+// limitLines keeps at most max lines of text
+func limitLines(text string, max int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) <= max {
+		return strings.Join(lines, "\n") + "\n"
+	}
+	return strings.Join(lines[:max], "\n") + fmt.Sprintf("\n... (%d more)\n", len(lines)-max)
+}
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/secrets"
+	"github.com/spf13/cobra"
+)
+
+// maxInitScanFiles bounds how many files sgit init looks at in a large directory
+const maxInitScanFiles = 10000
+
+// initValueFlags are git init options that take a separate value argument
+var initValueFlags = map[string]bool{
+	"-b":                 true,
+	"--initial-branch":   true,
+	"--template":         true,
+	"--separate-git-dir": true,
+	"--object-format":    true,
+	"--ref-format":       true,
+}
+
+// projectManifests are files that tell the language, tools and commands of a project;
+// the start of each is shown to the AI
+var projectManifests = map[string]bool{
+	"go.mod": true, "package.json": true, "pyproject.toml": true, "requirements.txt": true,
+	"setup.py": true, "setup.cfg": true, "Pipfile": true, "Cargo.toml": true, "pom.xml": true,
+	"build.gradle": true, "build.gradle.kts": true, "Gemfile": true, "composer.json": true,
+	"Makefile": true, "CMakeLists.txt": true, "Dockerfile": true, "docker-compose.yml": true,
+	"mix.exs": true, "pubspec.yaml": true, "Package.swift": true, "deno.json": true,
+}
+
+// initCmd wraps git init with AI suggestions for a new repository
+var initCmd = &cobra.Command{
+	Use:   "init [--no-ai] [git init options] [directory]",
+	Short: "Create a repository with an AI-suggested .gitignore, README and first commit",
+	Long: `Run git init, then look at the project directory and have Solar LLM suggest a
+.gitignore, a README skeleton (when there is no README), a branch naming policy and
+the first commit message. Each suggestion is shown and only written or committed after
+you confirm it; files that look like they contain secrets are left out of the first
+commit. Use --no-ai for a plain git init.
+
+Examples:
+  sgit init
+  sgit init my-project
+  sgit init -b main
+  sgit init --no-ai`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runInit(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+	DisableFlagParsing: true,
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	// Flag parsing is disabled so every git init option passes through; pick out ours
+	forceAI, noAI := false, false
+	var gitArgs []string
+	for _, arg := range extractGlobalFlags(args) {
+		switch arg {
+		case "--ai":
+			forceAI = true
+		case "--no-ai":
+			noAI = true
+		default:
+			gitArgs = append(gitArgs, arg)
+		}
+	}
+
+	directory, bare := "", false
+	for i := 0; i < len(gitArgs); i++ {
+		arg := gitArgs[i]
+		switch {
+		case arg == "--bare":
+			bare = true
+		case initValueFlags[arg]:
+			i++
+		case !strings.HasPrefix(arg, "-") && directory == "":
+			directory = arg
+		}
+	}
+
+	executeGitCommand(append([]string{"init"}, gitArgs...))
+	// A bare repository has no files to look at
+	if bare || !useAIFor("init", true, forceAI, noAI) {
+		return nil
+	}
+
+	if directory != "" {
+		if err := os.Chdir(directory); err != nil {
+			return fmt.Errorf("error entering %s: %v", directory, err)
+		}
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	project, err := describeDirectory(".")
+	if err != nil {
+		return err
+	}
+	gitignore, _ := os.ReadFile(".gitignore")
+	readmes, _ := filepath.Glob("[Rr][Ee][Aa][Dd][Mm][Ee]*")
+
+	statusln("\n🤖 Looking at the project with Solar LLM...")
+	scaffold, err := client.ScaffoldProject(cmd.Context(), project, string(gitignore), len(readmes) > 0)
+	if err != nil {
+		return fmt.Errorf("error generating suggestions: %w", err)
+	}
+
+	if scaffold.Gitignore != "" {
+		fmt.Println("\n=== PROPOSED .gitignore ===")
+		fmt.Println(scaffold.Gitignore)
+		question := "\nWrite this .gitignore? (y/n): "
+		if len(gitignore) > 0 {
+			question = "\nReplace the existing .gitignore with this one? (y/n): "
+		}
+		if confirm(question) {
+			if err := os.WriteFile(".gitignore", []byte(scaffold.Gitignore+"\n"), 0644); err != nil {
+				return fmt.Errorf("error writing .gitignore: %w", err)
+			}
+			fmt.Println("✅ Wrote .gitignore")
+		}
+	}
+
+	if scaffold.Readme != "" && len(readmes) == 0 {
+		fmt.Println("\n=== PROPOSED README.md ===")
+		fmt.Println(scaffold.Readme)
+		if confirm("\nWrite this README.md? (y/n): ") {
+			if err := os.WriteFile("README.md", []byte(scaffold.Readme+"\n"), 0644); err != nil {
+				return fmt.Errorf("error writing README.md: %w", err)
+			}
+			fmt.Println("✅ Wrote README.md")
+		}
+	}
+
+	if len(scaffold.BranchPrefixes) > 0 || scaffold.BranchPattern != "" {
+		fmt.Println("\n=== BRANCH NAMING ===")
+		if len(scaffold.BranchPrefixes) > 0 {
+			fmt.Printf("Prefixes: %s\n", strings.Join(scaffold.BranchPrefixes, ", "))
+		}
+		if scaffold.BranchPattern != "" {
+			fmt.Printf("Pattern:  %s\n", scaffold.BranchPattern)
+		}
+		if scaffold.BranchPolicy != "" {
+			fmt.Println(scaffold.BranchPolicy)
+		}
+		fmt.Println("💡 'sgit branch new' follows this policy once it's in your config:")
+		if len(scaffold.BranchPrefixes) > 0 {
+			fmt.Printf("   sgit config set branch_prefixes \"[%s]\"\n", strings.Join(scaffold.BranchPrefixes, ", "))
+		}
+		if scaffold.BranchPattern != "" {
+			fmt.Printf("   sgit config set branch_pattern \"%s\"\n", scaffold.BranchPattern)
+		}
+	}
+
+	if scaffold.CommitMessage == "" {
+		return nil
+	}
+	// Re-running init in an existing repository leaves its history alone
+	if head, _ := runGitOutput("rev-parse", "--verify", "-q", "HEAD"); strings.TrimSpace(head) != "" {
+		return nil
+	}
+	return offerFirstCommit(scaffold.CommitMessage)
+}
+
+// offerFirstCommit shows the files a first commit would contain and commits them with
+// message after confirmation, leaving out files that look like they contain secrets
+func offerFirstCommit(message string) error {
+	output, err := runGitOutput("ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return fmt.Errorf("error listing files: %w", err)
+	}
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil
+	}
+	files := strings.Split(output, "\n")
+
+	fmt.Println("\n=== FIRST COMMIT ===")
+	fmt.Println(message)
+	fmt.Printf("\n%d file(s):\n", len(files))
+	fmt.Print(limitLines(strings.Join(files, "\n"), 20))
+
+	var findings []secrets.Finding
+	for _, file := range files {
+		findings = append(findings, scanFileForSecrets(file)...)
+	}
+	flagged := make(map[string]bool)
+	for _, finding := range findings {
+		flagged[finding.File] = true
+	}
+	if len(findings) > 0 {
+		printSecretFindings(findings)
+		fmt.Printf("⚠️  %d file(s) with potential secrets will be left out of the commit\n", len(flagged))
+	}
+
+	if !confirm("\nStage these files and commit with this message? (y/n): ") {
+		fmt.Println("💡 Stage the files you want and run 'sgit commit' when ready")
+		return nil
+	}
+
+	if _, err := runGitOutput("add", "-A"); err != nil {
+		return fmt.Errorf("error staging files: %w", err)
+	}
+	if len(flagged) > 0 {
+		leftOut := make([]string, 0, len(flagged))
+		for file := range flagged {
+			leftOut = append(leftOut, file)
+		}
+		sort.Strings(leftOut)
+		if _, err := runGitOutput(append([]string{"rm", "--cached", "-q", "--"}, leftOut...)...); err != nil {
+			return fmt.Errorf("error unstaging files with potential secrets: %w", err)
+		}
+	}
+	return executeGitCommit(message)
+}
+
+// describeDirectory summarizes a project directory that isn't committed yet for the
+// AI: its top-level entries, file types, and the start of its manifest files
+func describeDirectory(root string) (string, error) {
+	topLevel := make(map[string]bool)
+	extCounts := make(map[string]int)
+	var manifests, generated []string
+	total := 0
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || path == root {
+			return nil
+		}
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+		first, _, nested := strings.Cut(rel, "/")
+		if !nested {
+			if entry.IsDir() {
+				topLevel[first+"/"] = true
+			} else {
+				topLevel[first] = true
+			}
+		}
+
+		if entry.IsDir() {
+			// Dependencies and build output are named, not listed
+			if ignoreArtifactDirs[entry.Name()] {
+				generated = append(generated, rel+"/")
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		total++
+		if total > maxInitScanFiles {
+			return filepath.SkipAll
+		}
+		if ext := filepath.Ext(entry.Name()); ext != "" {
+			extCounts[ext]++
+		}
+		if !nested && projectManifests[entry.Name()] {
+			manifests = append(manifests, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("error reading the project directory: %w", err)
+	}
+
+	entries := make([]string, 0, len(topLevel))
+	for entry := range topLevel {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+	exts := make([]string, 0, len(extCounts))
+	for ext := range extCounts {
+		exts = append(exts, ext)
+	}
+	sort.Slice(exts, func(i, j int) bool { return extCounts[exts[i]] > extCounts[exts[j]] })
+
+	var b strings.Builder
+	if abs, err := filepath.Abs(root); err == nil {
+		fmt.Fprintf(&b, "Directory name: %s\n", filepath.Base(abs))
+	}
+	fmt.Fprintf(&b, "Files: %d\n", total)
+	fmt.Fprintf(&b, "Top-level entries: %s\n", strings.Join(entries, ", "))
+	b.WriteString("File types:")
+	for _, ext := range exts {
+		fmt.Fprintf(&b, " %s (%d)", ext, extCounts[ext])
+	}
+	b.WriteString("\n")
+	if len(generated) > 0 {
+		fmt.Fprintf(&b, "Dependency, build and cache directories: %s\n", strings.Join(generated, ", "))
+	}
+
+	sort.Strings(manifests)
+	for _, manifest := range manifests {
+		content, err := os.ReadFile(filepath.Join(root, manifest))
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "\n--- %s ---\n%s", manifest, limitLines(string(content), 40))
+	}
+	return b.String(), nil
+}
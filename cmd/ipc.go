@@ -0,0 +1,480 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hunkim/sgit/pkg/engine"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+)
+
+// ipcServerError is the JSON-RPC error code of requests that fail in sgit or the API,
+// with the reason in the error's data
+const ipcServerError = -32000
+
+// Reasons of ipcServerError errors, for plugins to act on without reading the message
+const (
+	ipcReasonNotRepository   = "not_repository"
+	ipcReasonNoStagedChanges = "no_staged_changes"
+	ipcReasonEmptyDiff       = "empty_diff"
+	ipcReasonNoCommits       = "no_commits"
+	ipcReasonRateLimited     = "rate_limited"
+	ipcReasonTooLarge        = "context_too_large"
+	ipcReasonFailed          = "failed"
+)
+
+// ipcMethods are the methods sgit ipc answers, as listed by initialize
+var ipcMethods = []string{"initialize", "shutdown", "generateCommitMessage", "summarizeDiff", "reviewHunks", "analyzeLog"}
+
+// ipcCmd speaks JSON-RPC over stdio for editor plugins
+var ipcCmd = &cobra.Command{
+	Use:   "ipc",
+	Short: "Speak JSON-RPC over stdin/stdout for editor plugins",
+	Long: `Run sgit as a JSON-RPC 2.0 server on stdin/stdout, so editor plugins (VS Code,
+JetBrains and others) can drive it with structured requests and results instead of
+parsing terminal output, and reuse its context gathering.
+
+Messages are either one JSON object per line, or framed with Content-Length headers
+as in the Language Server Protocol; sgit answers in the framing it was sent.
+
+Methods (every one takes an optional "repo" path, default the working directory):
+  initialize             -> {"name", "version", "methods"}
+  generateCommitMessage  {"diff"?, "type"?, "scope"?, "hint"?, "stream"?}
+                         -> {"message", "issues"}; without "diff", for the staged changes
+  summarizeDiff          {"diff"? | "args"?, "stream"?} -> {"summary"}
+  reviewHunks            {"diff"? | "args"?}
+                         -> {"verdict", "summary", "comments": [{"file", "line", "body"}]}
+  analyzeLog             {"args"?, "timeframe"?, "stream"?} -> {"analysis"}
+  shutdown               -> null; the "exit" notification then stops the server
+
+"args" are git diff (or git log) arguments, e.g. ["--cached"]. With "stream": true,
+the response is preceded by "chunk" notifications, {"id": <request id>, "text": "..."},
+as it is generated. A "$/cancelRequest" notification with {"id": <request id>}
+cancels a request. Failures are JSON-RPC errors with code -32000 and a "reason" in
+their data: not_repository, no_staged_changes, empty_diff, no_commits, rate_limited,
+context_too_large or failed.
+
+Examples:
+  echo '{"jsonrpc":"2.0","id":1,"method":"generateCommitMessage","params":{}}' | sgit ipc`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runIPC(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ipcCmd)
+}
+
+// ipcParams are the parameters of every method; each method uses the fields it documents
+type ipcParams struct {
+	serveRequest
+	// Diff is a diff the editor has, used instead of asking git
+	Diff      string `json:"diff"`
+	Timeframe string `json:"timeframe"`
+}
+
+// ipcNotification is an outgoing JSON-RPC notification
+type ipcNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// ipcResponse is an outgoing JSON-RPC response; unlike mcpResponse it keeps a null
+// result, which is what shutdown answers
+type ipcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result"`
+}
+
+// ipcErrorResponse is a JSON-RPC error response with data, which mcpError has no room for
+type ipcErrorResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   ipcError        `json:"error"`
+}
+
+type ipcError struct {
+	Code    int                    `json:"code"`
+	Message string                 `json:"message"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// ipcConn reads and writes JSON-RPC messages, one per line or framed with
+// Content-Length headers
+type ipcConn struct {
+	reader *bufio.Reader
+
+	mu  sync.Mutex // guards out and headers
+	out io.Writer
+	// headers is set once a message arrives with a Content-Length header, so replies
+	// use the same framing
+	headers bool
+}
+
+// read returns the next message, or io.EOF when the input is closed
+func (c *ipcConn) read() ([]byte, error) {
+	for {
+		line, err := c.reader.ReadBytes('\n')
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) == 0 {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		name, value, ok := strings.Cut(string(trimmed), ":")
+		if !ok || !strings.EqualFold(name, "Content-Length") {
+			return trimmed, nil
+		}
+		length, convErr := strconv.Atoi(strings.TrimSpace(value))
+		if convErr != nil || length < 0 {
+			return nil, fmt.Errorf("invalid Content-Length header: %s", value)
+		}
+		// Skip the other headers up to the blank line before the body
+		for {
+			header, err := c.reader.ReadBytes('\n')
+			if err != nil {
+				return nil, err
+			}
+			if len(bytes.TrimSpace(header)) == 0 {
+				break
+			}
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(c.reader, body); err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.headers = true
+		c.mu.Unlock()
+		return body, nil
+	}
+}
+
+// write sends a message in the framing the client uses
+func (c *ipcConn) write(message interface{}) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding response: %v\n", err)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.headers {
+		_, err = fmt.Fprintf(c.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	} else {
+		_, err = c.out.Write(append(body, '\n'))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing response: %v\n", err)
+	}
+}
+
+// ipcServer answers the requests of an editor plugin
+type ipcServer struct {
+	conn *ipcConn
+
+	mu      sync.Mutex // guards cancels
+	cancels map[string]context.CancelFunc
+	running sync.WaitGroup
+}
+
+func runIPC(cmd *cobra.Command, args []string) error {
+	// The server can't ask for an API key, so it must be configured up front
+	if !aiConfigured() {
+		return fmt.Errorf("no API key configured (set SGIT_API_KEY or run 'sgit config init')")
+	}
+	if _, err := newSolarClient(); err != nil {
+		return err
+	}
+	// stdout carries the protocol; keep status messages off it entirely
+	quiet = true
+	promptOutput = os.Stderr
+
+	server := &ipcServer{
+		conn:    &ipcConn{reader: bufio.NewReader(os.Stdin), out: os.Stdout},
+		cancels: make(map[string]context.CancelFunc),
+	}
+	return server.serve(cmd.Context())
+}
+
+// serve answers requests until the input is closed or the client sends exit.
+// Requests run concurrently so a client can cancel one while others are in flight.
+func (s *ipcServer) serve(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer s.running.Wait()
+
+	for {
+		message, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading request: %w", err)
+		}
+		if !s.handle(ctx, message) {
+			return nil
+		}
+	}
+}
+
+// handle answers one message, reporting false when the client asked the server to exit
+func (s *ipcServer) handle(ctx context.Context, message []byte) bool {
+	var req mcpRequest
+	if err := json.Unmarshal(message, &req); err != nil {
+		s.fail(json.RawMessage("null"), mcpParseError, err.Error(), "")
+		return true
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if len(req.ID) > 0 {
+			s.fail(req.ID, mcpInvalidRequest, "not a JSON-RPC 2.0 request", "")
+		}
+		return true
+	}
+
+	// Notifications get no response
+	if len(req.ID) == 0 {
+		switch req.Method {
+		case "exit":
+			return false
+		case "$/cancelRequest":
+			var params struct {
+				ID json.RawMessage `json:"id"`
+			}
+			if json.Unmarshal(req.Params, &params) == nil {
+				s.mu.Lock()
+				if cancel, ok := s.cancels[string(params.ID)]; ok {
+					cancel()
+				}
+				s.mu.Unlock()
+			}
+		}
+		return true
+	}
+
+	switch req.Method {
+	case "initialize":
+		s.reply(req.ID, map[string]interface{}{"name": "sgit", "version": version, "methods": ipcMethods})
+		return true
+	case "shutdown":
+		s.reply(req.ID, nil)
+		return true
+	}
+
+	run, ok := map[string]func(context.Context, *ipcParams, func(string)) (map[string]interface{}, error){
+		"generateCommitMessage": ipcGenerateCommitMessage,
+		"summarizeDiff":         ipcSummarizeDiff,
+		"reviewHunks":           ipcReviewHunks,
+		"analyzeLog":            ipcAnalyzeLog,
+	}[req.Method]
+	if !ok {
+		s.fail(req.ID, mcpMethodNotFound, fmt.Sprintf("method '%s' not found", req.Method), "")
+		return true
+	}
+	var params ipcParams
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			s.fail(req.ID, mcpInvalidParams, err.Error(), "")
+			return true
+		}
+	}
+	if err := checkServeArgs(params.Args); err != nil {
+		s.fail(req.ID, mcpInvalidParams, err.Error(), "")
+		return true
+	}
+
+	callCtx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancels[string(req.ID)] = cancel
+	s.mu.Unlock()
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		var onChunk func(string)
+		if params.Stream {
+			onChunk = func(chunk string) {
+				s.conn.write(ipcNotification{JSONRPC: "2.0", Method: "chunk", Params: map[string]interface{}{"id": req.ID, "text": chunk}})
+			}
+		}
+		result, err := run(callCtx, &params, onChunk)
+
+		s.mu.Lock()
+		delete(s.cancels, string(req.ID))
+		s.mu.Unlock()
+		cancelled := callCtx.Err() != nil && ctx.Err() == nil
+		cancel()
+		switch {
+		case cancelled:
+			// As in LSP, a cancelled request is answered with an error
+			s.fail(req.ID, ipcServerError, "request cancelled", "cancelled")
+		case err != nil:
+			s.fail(req.ID, ipcServerError, err.Error(), ipcErrorReason(err))
+		default:
+			s.reply(req.ID, result)
+		}
+	}()
+	return true
+}
+
+func (s *ipcServer) reply(id json.RawMessage, result interface{}) {
+	s.conn.write(ipcResponse{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *ipcServer) fail(id json.RawMessage, code int, message, reason string) {
+	rpcErr := ipcError{Code: code, Message: message}
+	if reason != "" {
+		rpcErr.Data = map[string]interface{}{"reason": reason}
+	}
+	s.conn.write(ipcErrorResponse{JSONRPC: "2.0", ID: id, Error: rpcErr})
+}
+
+// ipcErrorReason names the reason for an error, for plugins to act on
+func ipcErrorReason(err error) string {
+	switch {
+	case errors.Is(err, engine.ErrNotRepository):
+		return ipcReasonNotRepository
+	case errors.Is(err, engine.ErrNoStagedChanges):
+		return ipcReasonNoStagedChanges
+	case errors.Is(err, engine.ErrEmptyDiff):
+		return ipcReasonEmptyDiff
+	case errors.Is(err, engine.ErrNoCommits):
+		return ipcReasonNoCommits
+	case errors.Is(err, solar.ErrRateLimited):
+		return ipcReasonRateLimited
+	case errors.Is(err, solar.ErrContextTooLarge):
+		return ipcReasonTooLarge
+	}
+	return ipcReasonFailed
+}
+
+// ipcEngine returns an engine for the repository a request is about
+func ipcEngine(params *ipcParams) (*engine.Engine, error) {
+	repo, err := filepath.Abs(params.Repo)
+	if err != nil {
+		return nil, err
+	}
+	return newRepoEngine(repo)
+}
+
+// ipcDiff returns the diff a request is about: the one the editor sent, or the output
+// of git diff with the request's args
+func ipcDiff(ctx context.Context, eng *engine.Engine, params *ipcParams) (string, error) {
+	if params.Diff != "" {
+		return params.Diff, nil
+	}
+	if !eng.Repository().IsRepository(ctx) {
+		return "", engine.ErrNotRepository
+	}
+	diff, err := eng.Repository().Diff(ctx, params.Args...)
+	if err != nil {
+		return "", fmt.Errorf("error getting git diff: %w", err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		return "", engine.ErrEmptyDiff
+	}
+	return diff, nil
+}
+
+func ipcGenerateCommitMessage(ctx context.Context, params *ipcParams, onChunk func(string)) (map[string]interface{}, error) {
+	eng, err := ipcEngine(params)
+	if err != nil {
+		return nil, err
+	}
+	// A diff from the editor stands in for the staged one; the staged files would
+	// describe something else
+	if diff := params.Diff; diff != "" {
+		providers := eng.ContextProviders()
+		providers.Register(engine.NewProvider(engine.ProviderDiff, func(ctx context.Context, commitContext *engine.CommitContext) error {
+			commitContext.Diff = diff
+			return nil
+		}))
+		providers.Disable(engine.ProviderFiles)
+	}
+	return serveCommitMessage(ctx, eng, &params.serveRequest, onChunk)
+}
+
+func ipcSummarizeDiff(ctx context.Context, params *ipcParams, onChunk func(string)) (map[string]interface{}, error) {
+	eng, err := ipcEngine(params)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := ipcDiff(ctx, eng, params)
+	if err != nil {
+		return nil, err
+	}
+	var summary string
+	if onChunk == nil {
+		summary, err = eng.Client().SummarizeDiff(ctx, diff)
+	} else {
+		summary, err = eng.Client().SummarizeDiffStream(ctx, diff, onChunk)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"summary": strings.TrimSpace(summary)}, nil
+}
+
+// ipcReviewHunks reviews a diff with comments on its lines, which a plugin can show
+// as diagnostics, including the leftover debug code sgit commit warns about
+func ipcReviewHunks(ctx context.Context, params *ipcParams, onChunk func(string)) (map[string]interface{}, error) {
+	eng, err := ipcEngine(params)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := ipcDiff(ctx, eng, params)
+	if err != nil {
+		return nil, err
+	}
+	scope := "changes open in an editor"
+	if params.Diff == "" {
+		scope = strings.TrimSpace("git diff " + strings.Join(params.Args, " "))
+	}
+	review, err := eng.Client().ReviewPullRequest(ctx, diff, scope, pullRequestFileList(diff))
+	if err != nil {
+		return nil, err
+	}
+	addLeftoverComments(&review, diff)
+
+	comments := make([]map[string]interface{}, len(review.Comments))
+	for i, comment := range review.Comments {
+		comments[i] = map[string]interface{}{"file": comment.Path, "line": comment.Line, "body": comment.Body}
+	}
+	return map[string]interface{}{"verdict": review.Verdict, "summary": review.Summary, "comments": comments}, nil
+}
+
+func ipcAnalyzeLog(ctx context.Context, params *ipcParams, onChunk func(string)) (map[string]interface{}, error) {
+	eng, err := ipcEngine(params)
+	if err != nil {
+		return nil, err
+	}
+	args, timeframe := params.Args, params.Timeframe
+	if len(args) == 0 {
+		args = []string{"-20"}
+	}
+	if timeframe == "" {
+		timeframe = "git log " + strings.Join(args, " ")
+	}
+	analysis, err := eng.AnalyzeLogStream(ctx, timeframe, onChunk, args...)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"analysis": strings.TrimSpace(analysis)}, nil
+}
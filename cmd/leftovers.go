@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/leftovers"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/viper"
+)
+
+// Values of the leftover_check setting: what a commit with leftover debug code does
+const (
+	leftoverCheckWarn  = "warn"
+	leftoverCheckBlock = "block"
+	leftoverCheckOff   = "off"
+)
+
+// normalizeLeftoverCheckMode checks a leftover_check setting
+func normalizeLeftoverCheckMode(value string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(value)); mode {
+	case "":
+		return leftoverCheckWarn, nil
+	case leftoverCheckWarn, leftoverCheckBlock, leftoverCheckOff:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid leftover_check '%s' (use warn, block or off)", value)
+}
+
+// leftoverCheckMode returns leftover_check from config: warn (the default), block or off
+func leftoverCheckMode() string {
+	mode, err := normalizeLeftoverCheckMode(viper.GetString("leftover_check"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v, using %s\n", err, leftoverCheckWarn)
+		return leftoverCheckWarn
+	}
+	return mode
+}
+
+// checkStagedLeftovers warns about debug code left in the staged changes, such as
+// print statements and FIXMEs, before the commit is made. With leftover_check: block
+// the commit is stopped instead.
+func checkStagedLeftovers() error {
+	mode := leftoverCheckMode()
+	if mode == leftoverCheckOff {
+		return nil
+	}
+	diff, err := getGitDiff()
+	if err != nil {
+		return fmt.Errorf("error getting git diff: %w", err)
+	}
+	findings := leftovers.ScanDiff(diff)
+	if len(findings) == 0 {
+		return nil
+	}
+
+	printLeftovers(os.Stdout, findings)
+	if mode == leftoverCheckBlock {
+		fmt.Println("Remove them from your staged changes, or re-run with --allow-leftovers to commit anyway.")
+		return fmt.Errorf("commit blocked: %d possible leftover(s) in staged changes", len(findings))
+	}
+	fmt.Println()
+	return nil
+}
+
+// warnHookLeftovers warns about debug code in the diff a commit message is generated
+// for by the prepare-commit-msg hook, which can't stop the commit
+func warnHookLeftovers(diff string) {
+	if leftoverCheckMode() == leftoverCheckOff {
+		return
+	}
+	if findings := leftovers.ScanDiff(diff); len(findings) > 0 {
+		printLeftovers(os.Stderr, findings)
+	}
+}
+
+// printLeftovers lists leftovers with their file and line
+func printLeftovers(w io.Writer, findings []leftovers.Finding) {
+	fmt.Fprintf(w, "⚠️  Possible leftover debug code in staged changes (%d):\n", len(findings))
+	for _, f := range findings {
+		fmt.Fprintf(w, "  %s\n", f)
+	}
+}
+
+// addLeftoverComments adds a line comment to review for each leftover in diff that
+// the model didn't already comment on, up to solar.MaxLineComments
+func addLeftoverComments(review *solar.PullRequestReview, diff string) {
+	commented := make(map[string]bool)
+	for _, comment := range review.Comments {
+		commented[fmt.Sprintf("%s:%d", comment.Path, comment.Line)] = true
+	}
+	for _, f := range leftovers.ScanDiff(diff) {
+		if len(review.Comments) >= solar.MaxLineComments {
+			return
+		}
+		if commented[fmt.Sprintf("%s:%d", f.File, f.Line)] {
+			continue
+		}
+		review.Comments = append(review.Comments, solar.LineComment{
+			Path: f.File,
+			Line: f.Line,
+			Body: fmt.Sprintf("Possible leftover %s; remove it unless it is meant to stay.", f.Description),
+		})
+	}
+}
@@ -0,0 +1,527 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/actions"
+	"github.com/hunkim/sgit/pkg/i18n"
+	"github.com/hunkim/sgit/pkg/lint"
+	"github.com/hunkim/sgit/pkg/progress"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	lintNoAI              bool
+	lintFix               bool
+	lintGitHubAnnotations bool
+)
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint [range]",
+	Short: "Check commit messages against the configured convention",
+	Long: `Validate commit messages in a range (default: commits not yet on the upstream or
+base branch) against the configured convention, length limits, and imperative mood,
+using both rules and an AI judgment. Exits non-zero when any commit fails, for CI use.
+
+Use --fix to rewrite the failing messages of unpushed commits via rebase.
+
+--github-annotations also reports the failing commits as GitHub Actions error
+annotations and writes a table of the results to the job summary.
+
+Examples:
+  sgit lint
+  sgit lint origin/main..HEAD --no-ai
+  sgit lint --fix
+  sgit lint origin/main..HEAD --github-annotations`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runLint(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().BoolVar(&lintNoAI, "no-ai", false, "only run the rule-based checks")
+	lintCmd.Flags().BoolVar(&lintFix, "fix", false, "rewrite failing messages of unpushed commits")
+	lintCmd.Flags().BoolVar(&lintGitHubAnnotations, "github-annotations", false, "report the results as GitHub Actions annotations and job summary")
+}
+
+// lintedCommit is a commit message and the issues found in it
+type lintedCommit struct {
+	sha     string
+	message string
+	issues  []lint.Issue
+}
+
+func (c lintedCommit) subject() string {
+	return strings.SplitN(c.message, "\n", 2)[0]
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	rangeArgs := defaultLintRange()
+	if len(args) > 0 {
+		rangeArgs = []string{args[0]}
+	}
+
+	commits, err := loadLintCommits(rangeArgs)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		fmt.Printf("No commits to lint in %s\n", strings.Join(rangeArgs, " "))
+		return nil
+	}
+
+	options := commitLintOptions()
+	for i := range commits {
+		commits[i].issues = lint.Check(commits[i].message, options)
+	}
+
+	// The AI judgment is optional so lint also works in CI without an API key
+	if useAIFor("lint", true, false, lintNoAI) {
+		if !aiConfigured() {
+			fmt.Println("💡 No API key configured, running rule-based checks only")
+		} else if err := judgeCommitsWithAI(cmd.Context(), commits); isDryRun(err) {
+			return err
+		} else if err != nil {
+			fmt.Printf("Warning: Could not get AI judgment: %v\n", err)
+		}
+	}
+
+	failed := 0
+	for _, commit := range commits {
+		if len(commit.issues) == 0 {
+			fmt.Printf("✅ %s %s\n", commit.sha[:7], commit.subject())
+			continue
+		}
+		failed++
+		fmt.Printf("❌ %s %s\n", commit.sha[:7], commit.subject())
+		for _, issue := range commit.issues {
+			fmt.Printf("   - %s\n", issue)
+		}
+	}
+
+	fmt.Printf("\n📊 %d/%d commit(s) passed\n", len(commits)-failed, len(commits))
+	if lintGitHubAnnotations {
+		writeGitHubResults(lintAnnotations(commits), lintSummary(commits, failed))
+	}
+	if failed == 0 {
+		return nil
+	}
+
+	if lintFix {
+		return fixCommitMessages(cmd.Context(), commits)
+	}
+	return fmt.Errorf("%d commit message(s) failed lint", failed)
+}
+
+// lintAnnotations reports each issue of the failing commits as an error annotation
+func lintAnnotations(commits []lintedCommit) []actions.Annotation {
+	var annotations []actions.Annotation
+	for _, commit := range commits {
+		for _, issue := range commit.issues {
+			annotations = append(annotations, actions.Annotation{
+				Level:   actions.LevelError,
+				Title:   fmt.Sprintf("Commit %s: %s", commit.sha[:7], commit.subject()),
+				Message: issue.String(),
+			})
+		}
+	}
+	return annotations
+}
+
+// lintSummary is the job summary of a lint run: a table of the commits and their issues
+func lintSummary(commits []lintedCommit, failed int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### sgit lint\n\n%d/%d commit(s) passed\n\n", len(commits)-failed, len(commits))
+	b.WriteString("| | Commit | Subject | Issues |\n|---|---|---|---|\n")
+	for _, commit := range commits {
+		status := "✅"
+		issues := make([]string, len(commit.issues))
+		for i, issue := range commit.issues {
+			status = "❌"
+			issues[i] = issue.String()
+		}
+		fmt.Fprintf(&b, "| %s | `%s` | %s | %s |\n", status, commit.sha[:7],
+			actions.TableCell(commit.subject()), actions.TableCell(strings.Join(issues, "\n")))
+	}
+	return b.String()
+}
+
+// defaultValidationRetries is how many times a generated commit message that fails
+// the lint rules is regenerated with the problems pointed out
+const defaultValidationRetries = 2
+
+// commitLintOptions returns the lint rules for the configured convention, types,
+// scopes and subject length
+func commitLintOptions() lint.Options {
+	options := lint.DefaultOptions(commitConvention())
+	// Subjects are capped at message_format.subject_max_length when they are formatted
+	if subjectLength := messageFormatOptions().MaxSubjectLength; subjectLength > 0 {
+		options.MaxSubjectLength = subjectLength
+	}
+	options.Gitmoji = gitmojiEnabled()
+	options.Types = configuredCommitTypes()
+	options.Scopes = configuredCommitScopes()
+	return options
+}
+
+// commitConvention returns the configured commit convention
+func commitConvention() string {
+	convention := strings.ToLower(strings.TrimSpace(viper.GetString("convention")))
+	if convention == "" {
+		convention = solar.DefaultConvention
+	}
+	return convention
+}
+
+// configuredCommitTypes returns the commit_types config, a YAML list or a
+// comma-separated string, or nil for the convention's standard types. Entries
+// starting with + or - add types to or remove them from the convention's standard
+// types, e.g. [+infra, -style].
+func configuredCommitTypes() []string {
+	entries := configList("commit_types")
+	relative := false
+	for _, entry := range entries {
+		if strings.HasPrefix(entry, "+") || strings.HasPrefix(entry, "-") {
+			relative = true
+		}
+	}
+	if !relative {
+		return entries
+	}
+
+	types := append([]string{}, lint.DefaultTypes(commitConvention())...)
+	for _, entry := range entries {
+		name := strings.TrimSpace(strings.TrimLeft(entry, "+-"))
+		types = slices.DeleteFunc(types, func(t string) bool { return t == name })
+		if !strings.HasPrefix(entry, "-") {
+			types = append(types, name)
+		}
+	}
+	return types
+}
+
+// configuredCommitScopes returns the commit_scopes config, a YAML list or a
+// comma-separated string, or nil to allow any scope. The entry "dirs" stands for
+// the repository's top-level directories and the scopes of scope_dirs.
+func configuredCommitScopes() []string {
+	var scopes []string
+	for _, entry := range configList("commit_scopes") {
+		if entry != "dirs" {
+			scopes = append(scopes, entry)
+			continue
+		}
+		scopes = append(scopes, topLevelDirs()...)
+		if dirs, err := loadScopeDirs(); err == nil {
+			for _, dir := range dirs {
+				if dir.Scope != "" {
+					scopes = append(scopes, dir.Scope)
+				}
+			}
+		}
+	}
+	slices.Sort(scopes)
+	return slices.Compact(scopes)
+}
+
+// configList reads a setting that is a YAML list or a comma-separated string
+func configList(key string) []string {
+	var list []string
+	for _, value := range viper.GetStringSlice(key) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// topLevelDirs lists the tracked directories at the top of the repository, leaving
+// out hidden ones such as .github
+func topLevelDirs() []string {
+	output, err := runGitOutput("ls-files", "--full-name", "--", topPath(""))
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, file := range strings.Split(output, "\n") {
+		dir, _, nested := strings.Cut(file, "/")
+		if nested && !strings.HasPrefix(dir, ".") {
+			dirs = append(dirs, dir)
+		}
+	}
+	slices.Sort(dirs)
+	return slices.Compact(dirs)
+}
+
+// generateValidCommitMessage calls generate and checks the message against the lint
+// rules. While it fails, the problems are fed back to the model and the message is
+// regenerated, up to validation_retries times. notify, if set, is told about each
+// retry. The last message is returned with any problems it still has.
+func generateValidCommitMessage(client *solar.Client, generate func() (string, error), notify func(issues []lint.Issue, attempt, maxAttempts int)) (string, []lint.Issue, error) {
+	retries := defaultValidationRetries
+	if viper.IsSet("validation_retries") {
+		retries = viper.GetInt("validation_retries")
+	}
+	options := commitLintOptions()
+	defer client.SetCorrection("", nil)
+
+	for attempt := 1; ; attempt++ {
+		message, err := generate()
+		if err != nil {
+			return "", nil, err
+		}
+		message = formatCommitMessage(message)
+
+		issues := lint.Check(message, options)
+		if len(issues) == 0 || attempt > retries {
+			return message, issues, nil
+		}
+
+		if notify != nil {
+			notify(issues, attempt+1, retries+1)
+		}
+		problems := make([]string, len(issues))
+		for i, issue := range issues {
+			problems[i] = issue.Message
+		}
+		client.SetCorrection(message, problems)
+	}
+}
+
+// printValidationRetry tells the user a generated message failed the lint rules
+func printValidationRetry(issues []lint.Issue, attempt, maxAttempts int) {
+	progress.Clear()
+	fmt.Fprint(os.Stderr, i18n.T("\n⚠️  Generated message failed validation, regenerating (attempt %d/%d):\n", attempt, maxAttempts))
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stderr, "   - %s\n", issue)
+	}
+}
+
+// printValidationIssues warns about problems a generated message still has
+func printValidationIssues(issues []lint.Issue) {
+	if len(issues) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, i18n.T("⚠️  The message still doesn't pass validation, please fix it before committing:"))
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stderr, "   - %s\n", issue)
+	}
+}
+
+// defaultLintRange selects the commits not yet on the upstream or base branch,
+// falling back to the last 10 commits
+func defaultLintRange() []string {
+	if _, err := runGitOutput("rev-parse", "--verify", "--quiet", "@{u}"); err == nil {
+		return []string{"@{u}..HEAD"}
+	}
+	if base, err := detectBaseBranch(); err == nil {
+		if branch, _ := getCurrentBranch(); branch != baseBranchName(base) {
+			return []string{base + "..HEAD"}
+		}
+	}
+	return []string{"-n", "10", "HEAD"}
+}
+
+// loadLintCommits returns the non-merge commits in the range, oldest first
+func loadLintCommits(rangeArgs []string) ([]lintedCommit, error) {
+	output, err := runGitOutput(append([]string{"log", "--no-merges", "--reverse", "--format=%H%x00%B%x1e"}, rangeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("error reading commits in %s: %v", strings.Join(rangeArgs, " "), err)
+	}
+
+	var commits []lintedCommit
+	for _, record := range strings.Split(output, "\x1e") {
+		sha, message, ok := strings.Cut(strings.TrimLeft(record, "\n"), "\x00")
+		if !ok {
+			continue
+		}
+		commits = append(commits, lintedCommit{sha: sha, message: strings.TrimSpace(message)})
+	}
+	return commits, nil
+}
+
+// judgeCommitsWithAI adds the AI's judgment of each commit message to its issues
+func judgeCommitsWithAI(ctx context.Context, commits []lintedCommit) error {
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for i, commit := range commits {
+		fmt.Fprintf(&b, "%d:\n%s\n\n", i+1, commit.message)
+	}
+
+	statusln("Reviewing commit messages with Solar LLM...")
+	response, err := client.JudgeCommitMessages(ctx, b.String())
+	if err != nil {
+		return err
+	}
+
+	for num, reason := range parseLintJudgments(response) {
+		if num >= 1 && num <= len(commits) {
+			commits[num-1].issues = append(commits[num-1].issues, lint.Issue{Rule: "ai", Message: reason})
+		}
+	}
+	return nil
+}
+
+// parseLintJudgments extracts "N: ISSUE - reason" lines from the AI response
+func parseLintJudgments(response string) map[int]string {
+	issues := make(map[int]string)
+	for _, line := range strings.Split(response, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		num, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil {
+			continue
+		}
+		verdict := strings.TrimSpace(parts[1])
+		if strings.HasPrefix(strings.ToUpper(verdict), "ISSUE") {
+			issues[num] = strings.TrimSpace(strings.TrimLeft(verdict[len("ISSUE"):], " -"))
+		}
+	}
+	return issues
+}
+
+// fixCommitMessages rewrites the failing messages with AI and applies them via rebase.
+// Only unpushed commits ending at HEAD can be rewritten.
+func fixCommitMessages(ctx context.Context, commits []lintedCommit) error {
+	head, err := runGitOutput("rev-parse", "HEAD")
+	if err != nil || strings.TrimSpace(head) != commits[len(commits)-1].sha {
+		return fmt.Errorf("--fix needs a range ending at HEAD")
+	}
+
+	oldest := commits[0].sha
+	all, err := runGitOutput("rev-list", "--reverse", oldest+"^@..HEAD")
+	if err != nil {
+		all, err = runGitOutput("rev-list", "--reverse", "HEAD")
+		if err != nil {
+			return fmt.Errorf("error listing commits: %w", err)
+		}
+	}
+	shas := strings.Fields(all)
+	if len(shas) != len(commits) {
+		return fmt.Errorf("--fix cannot rewrite ranges that contain merge commits")
+	}
+
+	unpushed, _ := runGitOutput("rev-list", oldest+"^@..HEAD", "--not", "--remotes")
+	if len(strings.Fields(unpushed)) != len(commits) {
+		return fmt.Errorf("--fix only rewrites unpushed commits; some commits in the range are already on a remote")
+	}
+
+	if status, _ := runGitOutput("status", "--porcelain", "--untracked-files=no"); strings.TrimSpace(status) != "" {
+		return fmt.Errorf("--fix needs a clean working tree, commit or stash your changes first")
+	}
+
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	rewrites := make(map[string]string)
+	for _, commit := range commits {
+		if len(commit.issues) == 0 {
+			continue
+		}
+
+		var issues []string
+		for _, issue := range commit.issues {
+			issues = append(issues, issue.String())
+		}
+		diffStat, _ := runGitOutput("show", "--stat", "--format=", commit.sha)
+
+		statusf("\nRewriting %s with Solar LLM...\n", commit.sha[:7])
+		message, err := client.RewriteCommitMessage(ctx, commit.message, strings.Join(issues, "\n"), diffStat)
+		if err != nil {
+			return fmt.Errorf("error rewriting %s: %w", commit.sha[:7], err)
+		}
+		message = formatCommitMessage(applyGitmoji(message))
+
+		fmt.Printf("- %s\n+ %s\n", commit.subject(), strings.SplitN(message, "\n", 2)[0])
+		rewrites[commit.sha] = message
+	}
+
+	if !confirm(fmt.Sprintf("\nRewrite %d commit message(s)? (y/n): ", len(rewrites))) {
+		fmt.Println("Fix cancelled")
+		return fmt.Errorf("commit messages failed lint")
+	}
+
+	if err := rewordCommits(shas, rewrites); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Rewrote %d commit message(s)\n", len(rewrites))
+	return nil
+}
+
+// rewordCommits replays shas (oldest first) with an interactive rebase that amends the
+// messages of the commits in rewrites
+func rewordCommits(shas []string, rewrites map[string]string) error {
+	messageDir, cleanup, err := rebaseMessageDir("lint-")
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var todo strings.Builder
+	for _, sha := range shas {
+		fmt.Fprintf(&todo, "pick %s\n", sha)
+		if message, ok := rewrites[sha]; ok {
+			messageFile := filepath.Join(messageDir, sha+".txt")
+			if err := os.WriteFile(messageFile, []byte(message+"\n"), 0644); err != nil {
+				return fmt.Errorf("failed to write message file: %w", err)
+			}
+			fmt.Fprintf(&todo, "exec git commit --amend --allow-empty --no-verify -F %s\n", shellQuote(messageFile))
+		}
+	}
+
+	todoFile := filepath.Join(messageDir, "todo")
+	if err := os.WriteFile(todoFile, []byte(todo.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write rebase todo: %w", err)
+	}
+
+	rebaseArgs := []string{"rebase", "-i"}
+	if _, err := runGitOutput("rev-parse", "--verify", "--quiet", shas[0]+"^"); err == nil {
+		rebaseArgs = append(rebaseArgs, shas[0]+"^")
+	} else {
+		rebaseArgs = append(rebaseArgs, "--root")
+	}
+
+	gitCmd := exec.Command("git", rebaseArgs...)
+	gitCmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=cp "+shellQuote(todoFile), "GIT_EDITOR=true")
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	if err := gitCmd.Run(); err != nil {
+		return fmt.Errorf("rebase failed (run 'git rebase --abort' to restore): %w", err)
+	}
+	return nil
+}
+
+// shellQuote quotes a string for use in a POSIX shell command
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -6,27 +6,59 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/hunkim/sgit/pkg/render"
 	"github.com/hunkim/sgit/pkg/solar"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
-	"github.com/spf13/viper"
 )
 
 var (
+	logAI         bool
 	logNoAI       bool
 	logTimeframe  string
+	logNoOutput   bool
+
+	logFocusAuthor string
+	logFocusPath   string
+	logFormat      string
+	logScopeDir    string
 )
 
+// logOnlyFlags are sgit's own log flags, which are never passed through to git
+var logOnlyFlags = map[string]bool{
+	"ai":            true,
+	"no-ai":         true,
+	"ai-timeframe":  true,
+	"no-log-output": true,
+	"focus-author":  true,
+	"focus-path":    true,
+	"ai-format":     true,
+	"scope-dir":     true,
+}
+
 // logCmd represents the log command
 var logCmd = &cobra.Command{
 	Use:   "log [options]",
 	Short: "Show commit logs with AI analysis (default)",
 	Long: `Show commit logs with AI-powered analysis of development patterns by default.
-Supports all git log options for full compatibility. Use --no-ai to disable AI analysis.`,
+Supports all git log options for full compatibility. Use --no-ai to disable AI analysis.
+Set log_ai: false in the config to show plain git log unless --ai is given.
+Use --no-log-output to show only the analysis, without the log itself.
+
+--focus-author and --focus-path narrow the log to one contributor or one part of the
+tree and point the analysis at that work, e.g. what someone has been doing in a package
+this month.
+
+--ai-format picks how the analysis is shown (auto|ansi|plain|markdown|html), as
+--format does for sgit diff; --format itself is git log's.
+
+Examples:
+  sgit log
+  sgit log --since "1 month ago" --focus-author alice --focus-path pkg/solar
+  sgit log --no-ai --oneline -10`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runLog(cmd, args); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
@@ -35,8 +67,14 @@ func init() {
 	rootCmd.AddCommand(logCmd)
 	
 	// AI-specific flags
+	logCmd.Flags().BoolVar(&logAI, "ai", false, "use AI analysis even when log_ai is false in config")
 	logCmd.Flags().BoolVar(&logNoAI, "no-ai", false, "disable AI analysis and use standard git log")
 	logCmd.Flags().StringVar(&logTimeframe, "ai-timeframe", "last 20 commits", "timeframe description for AI analysis")
+	logCmd.Flags().BoolVar(&logNoOutput, "no-log-output", false, "show only the AI analysis, not the log it analyzes")
+	logCmd.Flags().StringVar(&logFocusAuthor, "focus-author", "", "analyze the commits of one author (name or email)")
+	logCmd.Flags().StringVar(&logFocusPath, "focus-path", "", "analyze the commits touching one file or directory")
+	logCmd.Flags().StringVar(&logScopeDir, "scope-dir", "", "monorepo subproject to analyze (same as --focus-path)")
+	logCmd.Flags().StringVar(&logFormat, "ai-format", "", "analysis output format (auto|ansi|plain|markdown|html)")
 	
 	// Standard git log flags - we'll pass these through to git
 	logCmd.Flags().Bool("oneline", false, "show commits in one line")
@@ -71,8 +109,13 @@ func runLog(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not a git repository")
 	}
 
+	// A monorepo subproject is a focus path
+	if logFocusPath == "" {
+		logFocusPath = logScopeDir
+	}
+
 	// If AI analysis is requested, we need to get the log first
-	if !logNoAI {
+	if useAIFor("log", true, logAI, logNoAI) {
 		return runLogWithAIAnalysis(cmd, args)
 	}
 
@@ -81,6 +124,14 @@ func runLog(cmd *cobra.Command, args []string) error {
 }
 
 func runLogWithAIAnalysis(cmd *cobra.Command, args []string) error {
+	renderer, err := outputRenderer(logFormat)
+	if err != nil {
+		return err
+	}
+	// An HTML report holds only the analysis
+	report := renderer.Format() == render.HTML
+	analysisOnly := logNoOutput || report
+
 	// Check configuration and setup if needed
 	if err := ensureConfiguration(); err != nil {
 		return err
@@ -89,7 +140,7 @@ func runLogWithAIAnalysis(cmd *cobra.Command, args []string) error {
 	// First, get the git log output
 	logOutput, err := getGitLogOutput(cmd, args)
 	if err != nil {
-		return fmt.Errorf("error getting git log: %v", err)
+		return fmt.Errorf("error getting git log: %w", err)
 	}
 
 	if strings.TrimSpace(logOutput) == "" {
@@ -98,20 +149,40 @@ func runLogWithAIAnalysis(cmd *cobra.Command, args []string) error {
 	}
 
 	// Show the regular log first
-	fmt.Println("=== GIT LOG ===")
-	fmt.Println(logOutput)
-	fmt.Println()
+	if !analysisOnly {
+		fmt.Println("=== GIT LOG ===")
+		fmt.Println(logOutput)
+		fmt.Println()
+	}
 
 	// Generate AI analysis with streaming
-	apiKey := viper.GetString("upstage_api_key")
-	modelName := viper.GetString("upstage_model_name")
-	
-	client := solar.NewClient(apiKey, modelName, getEffectiveLanguage())
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
 	
-	fmt.Println("=== AI ANALYSIS ===")
-	_, err = client.AnalyzeLogStream(logOutput, logTimeframe)
+	if !analysisOnly {
+		fmt.Println("=== AI ANALYSIS ===")
+	}
+	// Unsigned commits are worth pointing out where commits should be signed
+	if signingRequired() {
+		if note := unsignedCommitsNote(gitLogArgs(cmd, args)); note != "" {
+			if !report {
+				fmt.Fprintf(os.Stderr, "🔓 %s\n\n", strings.TrimSuffix(strings.SplitN(note, "\n", 2)[0], ":"))
+			}
+			logOutput += "\n\n=== SIGNATURES ===\n" + note
+		}
+	}
+	if !report {
+		printContentStats("Log analysis", logOutput)
+	}
+	printer := newStreamPrinter("")
+	printer.renderer = renderer
+	focus := solar.LogFocus{Author: logFocusAuthor, Path: logFocusPath}
+	_, err = client.AnalyzeLogFocusStream(cmd.Context(), logOutput, logTimeframe, focus, printer.Write)
+	printer.Done()
 	if err != nil {
-		return fmt.Errorf("error generating log analysis: %v", err)
+		return fmt.Errorf("error generating log analysis: %w", err)
 	}
 
 	fmt.Println() // Add newline after streaming output
@@ -125,7 +196,7 @@ func executeGitLogPassthrough(cobraCmd *cobra.Command, args []string) error {
 	// Add all the flags that were set (excluding our custom AI flags)
 	cobraCmd.Flags().Visit(func(flag *pflag.Flag) {
 		flagName := flag.Name
-		if flagName == "no-ai" || flagName == "ai-timeframe" {
+		if isGlobalFlag(flag) || logOnlyFlags[flagName] {
 			return // Skip our custom AI flags
 		}
 		
@@ -146,7 +217,7 @@ func executeGitLogPassthrough(cobraCmd *cobra.Command, args []string) error {
 	})
 	
 	// Add any remaining arguments
-	gitArgs = append(gitArgs, args...)
+	gitArgs = append(gitArgs, logFocusArgs(args)...)
 	
 	// Execute git command
 	gitCmd := exec.Command("git", gitArgs...)
@@ -157,13 +228,20 @@ func executeGitLogPassthrough(cobraCmd *cobra.Command, args []string) error {
 }
 
 func getGitLogOutput(cmd *cobra.Command, args []string) (string, error) {
+	// Execute git command and capture output
+	return gitRepo.Log(cmd.Context(), gitLogArgs(cmd, args)...)
+}
+
+// gitLogArgs returns the git log arguments for the log given to the AI analysis: the
+// git flags and arguments given, limited to the last 20 commits unless they set a limit
+func gitLogArgs(cmd *cobra.Command, args []string) []string {
 	// Build git command with all flags and arguments (excluding AI flags)
 	gitArgs := []string{"log"}
 	
 	// Add all the flags that were set (excluding our custom AI flags)
 	cmd.Flags().Visit(func(flag *pflag.Flag) {
 		flagName := flag.Name
-		if flagName == "no-ai" || flagName == "ai-timeframe" {
+		if isGlobalFlag(flag) || logOnlyFlags[flagName] {
 			return // Skip our custom AI flags
 		}
 		
@@ -184,7 +262,7 @@ func getGitLogOutput(cmd *cobra.Command, args []string) (string, error) {
 	})
 	
 	// Add any remaining arguments
-	gitArgs = append(gitArgs, args...)
+	gitArgs = append(gitArgs, logFocusArgs(args)...)
 	
 	// If no number limit is specified, default to last 20 commits for AI analysis
 	hasNumberLimit := false
@@ -199,12 +277,26 @@ func getGitLogOutput(cmd *cobra.Command, args []string) (string, error) {
 		gitArgs = append(gitArgs, "-20")
 	}
 	
-	// Execute git command and capture output
-	gitCmd := exec.Command("git", gitArgs...)
-	output, err := gitCmd.Output()
-	if err != nil {
-		return "", err
+	return gitArgs[1:]
+} 
+
+// logFocusArgs adds the --focus-author and --focus-path filters to the git log
+// arguments: an --author option, and the path after the "--" separator
+func logFocusArgs(args []string) []string {
+	if logFocusAuthor != "" {
+		args = append([]string{"--author=" + logFocusAuthor}, args...)
 	}
-	
-	return string(output), nil
-} 
\ No newline at end of file
+	if logFocusPath != "" {
+		hasSeparator := false
+		for _, arg := range args {
+			if arg == "--" {
+				hasSeparator = true
+			}
+		}
+		if !hasSeparator {
+			args = append(args, "--")
+		}
+		args = append(args, logFocusPath)
+	}
+	return args
+}
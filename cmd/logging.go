@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/logging"
+	"github.com/hunkim/sgit/pkg/progress"
+	"github.com/spf13/viper"
+)
+
+var verboseLog bool
+var debugLog bool
+
+// logFile is the open log_file, kept so reconfiguring doesn't open it twice
+var logFile *os.File
+
+// stderrLog writes log lines to stderr, clearing the spinner line first so they
+// don't mix
+type stderrLog struct{}
+
+func (stderrLog) Write(p []byte) (int, error) {
+	progress.Clear()
+	return os.Stderr.Write(p)
+}
+
+// logLevel returns how much to log: --debug or SGIT_DEBUG=1 log everything,
+// --verbose the API requests, timings and token counts
+func logLevel() logging.Level {
+	if debugLog {
+		return logging.LevelDebug
+	}
+	if value := strings.TrimSpace(os.Getenv("SGIT_DEBUG")); value != "" {
+		if on, err := strconv.ParseBool(value); err != nil || on {
+			return logging.LevelDebug
+		}
+	}
+	if verboseLog {
+		return logging.LevelVerbose
+	}
+	return logging.LevelOff
+}
+
+// setupLogging starts logging to stderr, or to log_file when it is set, at the level
+// of the --verbose and --debug flags
+func setupLogging() {
+	level := logLevel()
+	if level == logging.LevelOff {
+		logging.Configure(logging.LevelOff, nil)
+		return
+	}
+
+	var out io.Writer = stderrLog{}
+	if path := expandHome(viper.GetString("log_file")); path != "" {
+		if logFile == nil || logFile.Name() != path {
+			file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  Could not open log_file, logging to stderr: %v\n", err)
+				logging.Configure(level, out)
+				return
+			}
+			logFile = file
+		}
+		out = logFile
+	}
+	logging.Configure(level, out)
+}
@@ -0,0 +1,372 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hunkim/sgit/pkg/engine"
+	"github.com/spf13/cobra"
+)
+
+// mcpProtocolVersions are the MCP revisions the server speaks, newest first
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes used by the MCP server
+const (
+	mcpParseError     = -32700
+	mcpInvalidRequest = -32600
+	mcpMethodNotFound = -32601
+	mcpInvalidParams  = -32602
+)
+
+// mcpCmd runs sgit as a Model Context Protocol server over stdio
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run as an MCP server so AI agents and IDEs can use sgit as tools",
+	Long: `Run sgit as a Model Context Protocol (MCP) server on stdin/stdout, exposing its
+commit message, diff summary, log analysis and review generation as tools that AI
+coding agents and IDEs can call with structured arguments.
+
+Tools:
+  generate_commit_message  commit message for the staged changes
+  summarize_diff           summary of a git diff
+  analyze_log              development patterns in the git log
+  review_diff              code review of a git diff
+
+Every tool takes an optional "repo" path (default: the directory the server was
+started in). Register it with your client, for example:
+
+  {"mcpServers": {"sgit": {"command": "sgit", "args": ["mcp"]}}}`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMCP(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
+
+// mcpRequest is an incoming JSON-RPC request or notification (no ID)
+type mcpRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// mcpResponse is an outgoing JSON-RPC response
+type mcpResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *mcpError       `json:"error,omitempty"`
+}
+
+type mcpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// mcpTool is a tool the server offers, with the JSON schema of its arguments
+type mcpTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+	run         serveHandler
+}
+
+// mcpArguments are the arguments of every tool; each tool uses the fields it documents
+type mcpArguments struct {
+	serveRequest
+	Timeframe string `json:"timeframe"`
+}
+
+// mcpServer answers MCP requests read from in, writing responses to out
+type mcpServer struct {
+	tools map[string]*mcpTool
+	order []string
+
+	mu      sync.Mutex // guards out and cancels
+	out     *json.Encoder
+	cancels map[string]context.CancelFunc
+	running sync.WaitGroup
+}
+
+func runMCP(cmd *cobra.Command, args []string) error {
+	// The server can't ask for an API key, so it must be configured up front
+	if !aiConfigured() {
+		return fmt.Errorf("no API key configured (set SGIT_API_KEY or run 'sgit config init')")
+	}
+	if _, err := newSolarClient(); err != nil {
+		return err
+	}
+	// stdout carries the protocol; keep status messages off it entirely
+	quiet = true
+	promptOutput = os.Stderr
+
+	server := newMCPServer(os.Stdout)
+	return server.serve(cmd.Context(), os.Stdin)
+}
+
+func newMCPServer(out io.Writer) *mcpServer {
+	repoProperty := map[string]interface{}{
+		"type":        "string",
+		"description": "Path of the git repository (default: the server's working directory)",
+	}
+	diffArgsProperty := map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string"},
+		"description": `git diff arguments, e.g. ["--cached"] or ["main...HEAD"] (default: unstaged changes)`,
+	}
+	schema := func(properties map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"type": "object", "properties": properties}
+	}
+
+	tools := []*mcpTool{
+		{
+			Name:        "generate_commit_message",
+			Description: "Write a commit message for the staged changes of a git repository, following the repository's configured convention",
+			InputSchema: schema(map[string]interface{}{
+				"repo":  repoProperty,
+				"type":  map[string]interface{}{"type": "string", "description": "Commit type to use, e.g. fix or feat"},
+				"scope": map[string]interface{}{"type": "string", "description": "Commit scope to use, e.g. auth"},
+				"hint":  map[string]interface{}{"type": "string", "description": "What the change is for, in your own words"},
+			}),
+			run: serveCommitMessage,
+		},
+		{
+			Name:        "summarize_diff",
+			Description: "Summarize a git diff: what changed, why, and its impact",
+			InputSchema: schema(map[string]interface{}{"repo": repoProperty, "args": diffArgsProperty}),
+			run:         serveDiffSummary,
+		},
+		{
+			Name:        "analyze_log",
+			Description: "Analyze the development patterns, themes and contributors in the git log",
+			InputSchema: schema(map[string]interface{}{
+				"repo": repoProperty,
+				"args": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": `git log arguments, e.g. ["-50"] or ["v1.0..HEAD"] (default: ["-20"])`,
+				},
+				"timeframe": map[string]interface{}{"type": "string", "description": "Description of the period, e.g. last 20 commits"},
+			}),
+		},
+		{
+			Name:        "review_diff",
+			Description: "Review a git diff for bugs, security and performance problems, and maintainability issues",
+			InputSchema: schema(map[string]interface{}{"repo": repoProperty, "args": diffArgsProperty}),
+			run:         serveReview,
+		},
+	}
+
+	server := &mcpServer{
+		tools:   make(map[string]*mcpTool),
+		out:     json.NewEncoder(out),
+		cancels: make(map[string]context.CancelFunc),
+	}
+	for _, tool := range tools {
+		server.tools[tool.Name] = tool
+		server.order = append(server.order, tool.Name)
+	}
+	return server
+}
+
+// serve reads newline-delimited JSON-RPC messages until in is closed. Tool calls run
+// concurrently so a client can cancel one while others are in flight.
+func (s *mcpServer) serve(ctx context.Context, in io.Reader) error {
+	defer s.running.Wait()
+
+	reader := bufio.NewReader(in)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			s.handle(ctx, line)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading request: %w", err)
+		}
+	}
+}
+
+func (s *mcpServer) handle(ctx context.Context, line []byte) {
+	var req mcpRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		s.reply(json.RawMessage("null"), nil, &mcpError{Code: mcpParseError, Message: err.Error()})
+		return
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if len(req.ID) > 0 {
+			s.reply(req.ID, nil, &mcpError{Code: mcpInvalidRequest, Message: "not a JSON-RPC 2.0 request"})
+		}
+		return
+	}
+
+	// Notifications get no response
+	if len(req.ID) == 0 {
+		if req.Method == "notifications/cancelled" {
+			var params struct {
+				RequestID json.RawMessage `json:"requestId"`
+			}
+			if json.Unmarshal(req.Params, &params) == nil {
+				s.mu.Lock()
+				if cancel, ok := s.cancels[string(params.RequestID)]; ok {
+					cancel()
+				}
+				s.mu.Unlock()
+			}
+		}
+		return
+	}
+
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params)
+		protocolVersion := mcpProtocolVersions[0]
+		for _, supported := range mcpProtocolVersions {
+			if params.ProtocolVersion == supported {
+				protocolVersion = supported
+			}
+		}
+		s.reply(req.ID, map[string]interface{}{
+			"protocolVersion": protocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]interface{}{"name": "sgit", "version": version},
+		}, nil)
+	case "ping":
+		s.reply(req.ID, map[string]interface{}{}, nil)
+	case "tools/list":
+		tools := make([]*mcpTool, len(s.order))
+		for i, name := range s.order {
+			tools[i] = s.tools[name]
+		}
+		s.reply(req.ID, map[string]interface{}{"tools": tools}, nil)
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			s.reply(req.ID, nil, &mcpError{Code: mcpInvalidParams, Message: err.Error()})
+			return
+		}
+		tool, ok := s.tools[params.Name]
+		if !ok {
+			s.reply(req.ID, nil, &mcpError{Code: mcpInvalidParams, Message: fmt.Sprintf("unknown tool '%s'", params.Name)})
+			return
+		}
+
+		callCtx, cancel := context.WithCancel(ctx)
+		s.mu.Lock()
+		s.cancels[string(req.ID)] = cancel
+		s.mu.Unlock()
+		s.running.Add(1)
+		go func() {
+			defer s.running.Done()
+			result := s.callTool(callCtx, tool, params.Arguments)
+			s.mu.Lock()
+			delete(s.cancels, string(req.ID))
+			s.mu.Unlock()
+			// A request the client cancelled must not be answered
+			cancelled := callCtx.Err() != nil && ctx.Err() == nil
+			cancel()
+			if !cancelled {
+				s.reply(req.ID, result, nil)
+			}
+		}()
+	default:
+		s.reply(req.ID, nil, &mcpError{Code: mcpMethodNotFound, Message: fmt.Sprintf("method '%s' not found", req.Method)})
+	}
+}
+
+// callTool runs a tool and wraps its output, or its error, as an MCP tool result
+func (s *mcpServer) callTool(ctx context.Context, tool *mcpTool, rawArguments json.RawMessage) map[string]interface{} {
+	fail := func(err error) map[string]interface{} {
+		return map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": "Error: " + err.Error()}},
+			"isError": true,
+		}
+	}
+
+	var arguments mcpArguments
+	if len(rawArguments) > 0 {
+		if err := json.Unmarshal(rawArguments, &arguments); err != nil {
+			return fail(fmt.Errorf("invalid arguments: %w", err))
+		}
+	}
+	if err := checkServeArgs(arguments.Args); err != nil {
+		return fail(err)
+	}
+	repo, err := filepath.Abs(arguments.Repo)
+	if err != nil {
+		return fail(err)
+	}
+
+	eng, err := newRepoEngine(repo)
+	if err != nil {
+		return fail(err)
+	}
+
+	var structured map[string]interface{}
+	if tool.run != nil {
+		structured, err = tool.run(ctx, eng, &arguments.serveRequest, nil)
+	} else {
+		structured, err = mcpAnalyzeLog(ctx, eng, arguments)
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	// The main text goes in the content for clients that ignore structured output
+	var text string
+	for _, key := range []string{"message", "summary", "analysis", "review"} {
+		if value, ok := structured[key].(string); ok {
+			text = value
+		}
+	}
+	return map[string]interface{}{
+		"content":           []map[string]string{{"type": "text", "text": text}},
+		"structuredContent": structured,
+	}
+}
+
+func mcpAnalyzeLog(ctx context.Context, eng *engine.Engine, arguments mcpArguments) (map[string]interface{}, error) {
+	args, timeframe := arguments.Args, arguments.Timeframe
+	if len(args) == 0 {
+		args = []string{"-20"}
+	}
+	if timeframe == "" {
+		timeframe = "git log " + strings.Join(args, " ")
+	}
+
+	analysis, err := eng.AnalyzeLog(ctx, timeframe, args...)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"analysis": strings.TrimSpace(analysis)}, nil
+}
+
+func (s *mcpServer) reply(id json.RawMessage, result interface{}, rpcErr *mcpError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.out.Encode(mcpResponse{JSONRPC: "2.0", ID: id, Result: result, Error: rpcErr}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing response: %v\n", err)
+	}
+}
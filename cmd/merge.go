@@ -1,20 +1,20 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 
-	"github.com/hunkim/sgit/pkg/solar"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
-	"github.com/spf13/viper"
 )
 
 var (
 	mergeAIHelp    bool
 	mergeAIMessage bool
+	mergePreview   bool
 )
 
 // mergeCmd represents the merge command
@@ -22,11 +22,20 @@ var mergeCmd = &cobra.Command{
 	Use:   "merge [branch]",
 	Short: "Join development histories with optional AI assistance",
 	Long: `Join two or more development histories together with optional AI assistance
-for conflict resolution and merge message generation. Supports all git merge options.`,
+for conflict resolution and merge message generation. Supports all git merge options.
+
+--preview does a dry run of the merge (git merge-tree) instead, without touching the
+index or the working tree: it lists the commits to merge, the files that would
+conflict and the files the merge changes, and Solar LLM assesses the risk and
+recommends a merge, rebase or squash.
+
+Examples:
+  sgit merge --preview feature/login
+  sgit merge --ai-message feature/login
+  sgit merge --ai-help origin/main`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runMerge(cmd, args); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
@@ -37,6 +46,7 @@ func init() {
 	// AI-specific flags
 	mergeCmd.Flags().BoolVar(&mergeAIHelp, "ai-help", false, "provide AI assistance for merge conflicts")
 	mergeCmd.Flags().BoolVar(&mergeAIMessage, "ai-message", false, "generate AI merge commit message")
+	mergeCmd.Flags().BoolVar(&mergePreview, "preview", false, "show the conflicts and an AI risk assessment of the merge without merging")
 	
 	// Standard git merge flags - we'll pass these through to git
 	mergeCmd.Flags().Bool("commit", false, "perform the merge and commit the result")
@@ -59,7 +69,6 @@ func init() {
 	mergeCmd.Flags().Bool("summary", false, "show summary of changes")
 	mergeCmd.Flags().Bool("no-summary", false, "don't show summary of changes")
 	mergeCmd.Flags().StringP("message", "m", "", "merge commit message")
-	mergeCmd.Flags().Bool("quiet", false, "operate quietly")
 	mergeCmd.Flags().Bool("verbose", false, "be verbose")
 	mergeCmd.Flags().Bool("progress", false, "show progress")
 	mergeCmd.Flags().Bool("no-progress", false, "don't show progress")
@@ -72,6 +81,17 @@ func runMerge(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not a git repository")
 	}
 
+	if mergePreview {
+		return runMergePreview(cmd, args)
+	}
+
+	// merge_ai: true writes AI merge messages by default; never turns all AI help off
+	if aiDisabled("merge", mergeAIHelp || mergeAIMessage) {
+		mergeAIHelp, mergeAIMessage = false, false
+	} else {
+		mergeAIMessage = useAIFor("merge", false, mergeAIMessage, false)
+	}
+
 	// If AI assistance is requested, we handle it specially
 	if mergeAIHelp || mergeAIMessage {
 		return runMergeWithAI(cmd, args)
@@ -114,10 +134,16 @@ func runMergeWithAI(cmd *cobra.Command, args []string) error {
 			fmt.Println("\n🚨 Merge conflicts detected!")
 			
 			if mergeAIHelp {
-				fmt.Println("Getting AI assistance for conflict resolution...")
-				if aiErr := provideMergeConflictHelp(conflictFiles); aiErr != nil {
+				operationContext := fmt.Sprintf(`This conflict happened while merging branch "%s" into "%s".
+"Ours" is %s; "theirs" is %s.`, sourceBranch, targetBranch, targetBranch, sourceBranch)
+
+				resolved, aiErr := assistConflicts(cmd.Context(), conflictFiles, operationContext)
+				if aiErr != nil {
 					fmt.Printf("Warning: Could not get AI assistance: %v\n", aiErr)
 				}
+				if resolved {
+					return completeResolvedMerge(cmd.Context(), sourceBranch, targetBranch)
+				}
 			}
 			
 			fmt.Println("\nPlease resolve conflicts manually and then:")
@@ -125,56 +151,55 @@ func runMergeWithAI(cmd *cobra.Command, args []string) error {
 			fmt.Println("  sgit merge --continue")
 			return nil
 		}
-		return fmt.Errorf("merge failed: %v", err)
+		return fmt.Errorf("merge failed: %w", err)
 	}
 
 	// No conflicts, proceed with commit
 	if mergeAIMessage {
-		return commitMergeWithAIMessage(sourceBranch, targetBranch)
+		return commitMergeWithAIMessage(cmd.Context(), sourceBranch, targetBranch)
 	}
 
 	// Complete the merge with regular commit
 	return exec.Command("git", "commit").Run()
 }
 
-func provideMergeConflictHelp(conflictFiles []string) error {
-	apiKey := viper.GetString("upstage_api_key")
-	modelName := viper.GetString("upstage_model_name")
-	
-	client := solar.NewClient(apiKey, modelName, getEffectiveLanguage())
-	
-	conflictInfo := strings.Join(conflictFiles, "\n")
-	
-	help, err := client.AnalyzeMergeConflicts(conflictInfo)
-	if err != nil {
-		return err
+// completeResolvedMerge offers to commit a merge once every conflict has been resolved
+func completeResolvedMerge(ctx context.Context, sourceBranch, targetBranch string) error {
+	if !confirm("\nAll conflicts resolved. Complete the merge now? (y/n): ") {
+		fmt.Println("\nResolutions are staged. Complete the merge later with:")
+		fmt.Println("  git merge --continue")
+		return nil
 	}
 
-	fmt.Println("\n=== AI MERGE CONFLICT ASSISTANCE ===")
-	fmt.Println(help)
-	fmt.Println()
+	if mergeAIMessage {
+		return commitMergeWithAIMessage(ctx, sourceBranch, targetBranch)
+	}
 
-	return nil
+	// Use git's prepared merge message
+	gitCmd := exec.Command("git", "commit", "--no-edit")
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	return gitCmd.Run()
 }
 
-func commitMergeWithAIMessage(sourceBranch, targetBranch string) error {
+func commitMergeWithAIMessage(ctx context.Context, sourceBranch, targetBranch string) error {
 	// Get information about the changes being merged
-	changesCmd := exec.Command("git", "log", "--oneline", "--no-merges", fmt.Sprintf("%s..%s", targetBranch, sourceBranch))
-	changesOutput, err := changesCmd.Output()
+	changesOutput, err := gitRepo.Log(ctx, "--oneline", "--no-merges", fmt.Sprintf("%s..%s", targetBranch, sourceBranch))
 	if err != nil {
-		changesOutput = []byte("Unable to get merge changes")
+		changesOutput = "Unable to get merge changes"
 	}
 
-	apiKey := viper.GetString("upstage_api_key")
-	modelName := viper.GetString("upstage_model_name")
-	
-	client := solar.NewClient(apiKey, modelName, getEffectiveLanguage())
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
 	
 	fmt.Println("Generating AI merge commit message...")
-	message, err := client.GenerateMergeCommitMessage(sourceBranch, targetBranch, string(changesOutput))
+	message, err := client.GenerateMergeCommitMessage(ctx, sourceBranch, targetBranch, changesOutput)
 	if err != nil {
-		return fmt.Errorf("error generating merge message: %v", err)
+		return fmt.Errorf("error generating merge message: %w", err)
 	}
+	message = formatCommitMessage(message)
 
 	fmt.Printf("Generated merge message:\n%s\n", message)
 
@@ -183,13 +208,12 @@ func commitMergeWithAIMessage(sourceBranch, targetBranch string) error {
 }
 
 func getMergeConflicts() ([]string, error) {
-	cmd := exec.Command("git", "diff", "--name-only", "--diff-filter=U")
-	output, err := cmd.Output()
+	output, err := runGitOutput("diff", "--name-only", "--diff-filter=U")
 	if err != nil {
 		return nil, err
 	}
 
-	files := strings.Split(strings.TrimSpace(string(output)), "\n")
+	files := strings.Split(strings.TrimSpace(output), "\n")
 	if len(files) == 1 && files[0] == "" {
 		return []string{}, nil
 	}
@@ -203,7 +227,7 @@ func buildMergeArgs(cmd *cobra.Command, args []string) []string {
 	// Add all the flags that were set (excluding our custom AI flags)
 	cmd.Flags().Visit(func(flag *pflag.Flag) {
 		flagName := flag.Name
-		if flagName == "ai-help" || flagName == "ai-message" {
+		if isGlobalFlag(flag) || flagName == "ai-help" || flagName == "ai-message" || flagName == "preview" {
 			return // Skip our custom AI flags
 		}
 		
@@ -222,6 +246,11 @@ func buildMergeArgs(cmd *cobra.Command, args []string) []string {
 			}
 		}
 	})
+
+	// The global --quiet also quiets git merge
+	if quiet {
+		gitArgs = append(gitArgs, "--quiet")
+	}
 	
 	// Add any remaining arguments
 	gitArgs = append(gitArgs, args...)
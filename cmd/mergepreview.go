@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// mergeDryRun is a dry run of merging a branch into HEAD
+type mergeDryRun struct {
+	source, target string
+	base           string
+	// incoming and local are the commits only on the source branch and only on HEAD,
+	// one per line
+	incoming, local []string
+	upToDate        bool
+	fastForward     bool
+	conflicts       []mergeConflict
+	diffStat        string
+}
+
+// mergeConflict is a file the merge would leave conflicted, with the kind of conflict
+// git reports, such as content or modify/delete
+type mergeConflict struct {
+	file, kind string
+}
+
+// conflictMessage matches git's conflict messages, e.g. "CONFLICT (content): Merge
+// conflict in app.go"
+var conflictMessage = regexp.MustCompile(`^CONFLICT \(([^)]+)\): (.*)$`)
+
+// runMergePreview shows what merging a branch would do, and asks the model how risky it
+// is and how to integrate the branch, without touching the index or the working tree
+func runMergePreview(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("--preview takes the branch to merge")
+	}
+
+	preview, err := previewMerge(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔍 Merge preview: %s into %s (nothing is merged)\n", preview.source, preview.target)
+	if preview.upToDate {
+		fmt.Printf("✅ Already up to date: %s contains every commit of %s\n", preview.target, preview.source)
+		return nil
+	}
+	summary := describeMergePreview(preview)
+	fmt.Print(summary)
+	if preview.diffStat != "" {
+		fmt.Printf("\nFiles the merge changes:\n%s", preview.diffStat)
+	}
+
+	if aiDisabled("merge", false) {
+		return nil
+	}
+	if err := ensureConfiguration(); err != nil {
+		fmt.Printf("\n⚠️  %v; showing the preview without an AI assessment\n", err)
+		return nil
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	statusf("\nAssessing the merge with Solar LLM...\n")
+	response, err := client.AssessMerge(cmd.Context(), preview.source, preview.target, summary,
+		strings.Join(preview.incoming, "\n"), strings.Join(preview.local, "\n"), preview.diffStat)
+	if err != nil {
+		return fmt.Errorf("error assessing the merge: %w", err)
+	}
+
+	var assessment []string
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "*"))
+		label, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToUpper(strings.TrimSpace(label)) {
+		case "RISK":
+			assessment = append(assessment, "⚖️  Risk: "+value)
+		case "STRATEGY":
+			assessment = append(assessment, "🧭 Recommended: "+value+mergeStrategyCommand(strings.ToLower(value), preview))
+		case "WHY":
+			assessment = append(assessment, "💡 "+value)
+		case "CHECK":
+			assessment = append(assessment, "🔎 "+value)
+		}
+	}
+	if len(assessment) == 0 {
+		// Not in the requested format; show the answer as it is
+		assessment = append(assessment, strings.TrimSpace(response))
+	}
+	fmt.Println()
+	fmt.Println(strings.Join(assessment, "\n"))
+	return nil
+}
+
+// mergeStrategyCommand returns the command that carries out a recommended strategy, to
+// append to it
+func mergeStrategyCommand(strategy string, preview *mergeDryRun) string {
+	switch {
+	case strings.HasPrefix(strategy, "rebase"):
+		return fmt.Sprintf(" (git checkout %s && git rebase %s, then merge)", preview.source, preview.target)
+	case strings.HasPrefix(strategy, "squash"):
+		return fmt.Sprintf(" (sgit merge --squash %s, then sgit commit)", preview.source)
+	case strings.HasPrefix(strategy, "merge"):
+		return fmt.Sprintf(" (sgit merge %s)", preview.source)
+	}
+	return ""
+}
+
+// previewMerge does a dry run of merging source into HEAD with git merge-tree, which
+// writes the merged tree to the object database only
+func previewMerge(source string) (*mergeDryRun, error) {
+	sourceSHA, err := runGitOutput("rev-parse", "--verify", "--quiet", source+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("unknown branch or commit '%s'", source)
+	}
+	headSHA, err := runGitOutput("rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("there is no commit to merge into yet")
+	}
+	preview := &mergeDryRun{source: source, target: "HEAD"}
+	if branch, err := getCurrentBranch(); err == nil && branch != "" {
+		preview.target = branch
+	}
+
+	base, err := runGitOutput("merge-base", "HEAD", source)
+	if err != nil {
+		return nil, fmt.Errorf("%s and %s have no common history", preview.target, source)
+	}
+	preview.base = strings.TrimSpace(base)
+	if preview.base == strings.TrimSpace(sourceSHA) {
+		preview.upToDate = true
+		return preview, nil
+	}
+	preview.fastForward = preview.base == strings.TrimSpace(headSHA)
+
+	incoming, err := runGitOutput("log", "--oneline", "--no-merges", "HEAD.."+source)
+	if err != nil {
+		return nil, fmt.Errorf("error listing the commits to merge: %v", err)
+	}
+	preview.incoming = nonEmptyLines(incoming)
+	if local, err := runGitOutput("log", "--oneline", "--no-merges", source+"..HEAD"); err == nil {
+		preview.local = nonEmptyLines(local)
+	}
+
+	// Exit status 1 means the merge has conflicts; the output is the same
+	var stdout, stderr bytes.Buffer
+	mergeTree := exec.Command("git", "merge-tree", "--write-tree", "--name-only", "HEAD", source)
+	mergeTree.Stdout, mergeTree.Stderr = &stdout, &stderr
+	var exitError *exec.ExitError
+	if err := mergeTree.Run(); err != nil && (!errors.As(err, &exitError) || exitError.ExitCode() != 1) {
+		if strings.Contains(stderr.String(), "usage:") {
+			return nil, fmt.Errorf("--preview needs git 2.38 or newer (git merge-tree --write-tree)")
+		}
+		return nil, fmt.Errorf("git merge-tree failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	tree, conflicts := parseMergeTree(stdout.String())
+	preview.conflicts = conflicts
+	if tree != "" {
+		preview.diffStat, _ = runGitOutput("diff", "--stat", "HEAD", tree)
+	}
+	return preview, nil
+}
+
+// parseMergeTree reads the output of git merge-tree --write-tree --name-only: the
+// merged tree, the conflicted files, and after a blank line git's messages, which say
+// what kind of conflict each file has
+func parseMergeTree(output string) (string, []mergeConflict) {
+	sections := strings.SplitN(output, "\n\n", 2)
+	lines := nonEmptyLines(sections[0])
+	if len(lines) == 0 {
+		return "", nil
+	}
+	var messages []string
+	if len(sections) > 1 {
+		messages = nonEmptyLines(sections[1])
+	}
+
+	var conflicts []mergeConflict
+	for _, file := range lines[1:] {
+		conflict := mergeConflict{file: file}
+		for _, message := range messages {
+			if m := conflictMessage.FindStringSubmatch(message); m != nil && mentionsFile(m[2], file) {
+				conflict.kind = m[1]
+				break
+			}
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return lines[0], conflicts
+}
+
+// mentionsFile reports whether a git message names file as a word of its own, so
+// a.go doesn't match a message about data.go
+func mentionsFile(message, file string) bool {
+	for _, word := range strings.Fields(message) {
+		if strings.TrimRight(word, ".,;") == file {
+			return true
+		}
+	}
+	return false
+}
+
+// describeMergePreview summarizes a dry-run merge, for the user and the model
+func describeMergePreview(preview *mergeDryRun) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📦 %d commit(s) to merge; %s has %d commit(s) of its own since they diverged at %.7s\n",
+		len(preview.incoming), preview.target, len(preview.local), preview.base)
+	if preview.fastForward {
+		fmt.Fprintf(&b, "⏩ Fast-forward: %s can move to %s without a merge commit\n", preview.target, preview.source)
+	}
+	if len(preview.conflicts) == 0 {
+		b.WriteString("✅ No conflicts\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "🚨 %d file(s) will conflict:\n", len(preview.conflicts))
+	for _, conflict := range preview.conflicts {
+		if conflict.kind != "" {
+			fmt.Fprintf(&b, "   %s (%s)\n", conflict.file, conflict.kind)
+		} else {
+			fmt.Fprintf(&b, "   %s\n", conflict.file)
+		}
+	}
+	return b.String()
+}
+
+// nonEmptyLines splits output into its lines, leaving out blank ones
+func nonEmptyLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/hunkim/sgit/pkg/msgformat"
+	"github.com/spf13/viper"
+)
+
+// messageFormatOptions returns the message_format config: subject_max_length,
+// body_width, bullet and strip_markdown, each defaulting to git's conventions
+func messageFormatOptions() msgformat.Options {
+	options := msgformat.DefaultOptions()
+	if viper.IsSet("message_format.subject_max_length") {
+		options.MaxSubjectLength = max(viper.GetInt("message_format.subject_max_length"), 0)
+	}
+	if viper.IsSet("message_format.body_width") {
+		options.BodyWidth = max(viper.GetInt("message_format.body_width"), 0)
+	}
+	if viper.IsSet("message_format.bullet") {
+		options.Bullet = viper.GetString("message_format.bullet")
+	}
+	if viper.IsSet("message_format.strip_markdown") {
+		options.StripMarkdown = viper.GetBool("message_format.strip_markdown")
+	}
+	return options
+}
+
+// formatCommitMessage gives an AI-written commit message the configured shape: the
+// subject capped, the body wrapped, bullets made alike and markdown removed
+func formatCommitMessage(message string) string {
+	return msgformat.Format(message, messageFormatOptions())
+}
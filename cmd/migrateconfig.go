@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hunkim/sgit/pkg/configmigrate"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var migrateConfigDryRun bool
+
+// migrateConfigCmd upgrades the config file to the current schema version
+var migrateConfigCmd = &cobra.Command{
+	Use:   "migrate-config",
+	Short: "Upgrade the config file to the current settings layout",
+	Long: `Upgrade the config file to the current version of its layout, e.g. moving
+upstage_api_key to providers.upstage.api_key, and record the version in
+config_version.
+
+sgit does this by itself when it starts with an old config file, so this is only
+needed to see what would change (--dry-run) or to upgrade a file given with --config
+ahead of time. The old file is kept next to it as <file>.v<version>.bak, and
+comments and the order of the settings are kept.
+
+Examples:
+  sgit migrate-config --dry-run
+  sgit migrate-config
+  sgit migrate-config --config ./ci/sgit.yaml`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMigrateConfig(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateConfigCmd)
+
+	migrateConfigCmd.Flags().BoolVar(&migrateConfigDryRun, "dry-run", false, "show what would change without writing the config file")
+}
+
+func runMigrateConfig(cmd *cobra.Command, args []string) error {
+	configFile, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		fmt.Printf("No config file at %s, nothing to upgrade\n", configFile)
+		return nil
+	}
+	doc, err := loadConfigDocument(configFile)
+	if err != nil {
+		return err
+	}
+	settings := doc.Content[0]
+	from, err := configmigrate.Version(settings)
+	if err != nil {
+		return err
+	}
+	pending, err := configmigrate.Pending(settings)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		fmt.Printf("✅ %s is up to date (config version %d)\n", configFile, from)
+		return nil
+	}
+
+	if !migrateConfigDryRun {
+		applied, backup, err := migrateConfigFile(configFile, doc)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Upgraded %s from config version %d to %d\n", configFile, from, configmigrate.CurrentVersion())
+		printMigrations(applied)
+		fmt.Printf("   The old file is saved as %s\n", backup)
+		return nil
+	}
+
+	applied, err := configmigrate.Migrate(settings)
+	if err != nil {
+		return err
+	}
+	content, err := encodeYAML(doc)
+	if err != nil {
+		return fmt.Errorf("error formatting config: %w", err)
+	}
+	fmt.Printf("🔍 Would upgrade %s from config version %d to %d\n", configFile, from, configmigrate.CurrentVersion())
+	printMigrations(applied)
+	fmt.Printf("\nThe upgraded file would read:\n\n%s", content)
+	return nil
+}
+
+// printMigrations lists the migrations that changed the config file
+func printMigrations(applied []configmigrate.Migration) {
+	if len(applied) == 0 {
+		fmt.Println("   No settings needed to change, only the version is recorded")
+	}
+	for _, migration := range applied {
+		fmt.Printf("   • %s\n", migration.Description)
+	}
+}
+
+// migrateConfigFile upgrades doc, the content of configFile, to the current version
+// and writes it back, after copying the old file next to it. It returns the
+// migrations that changed something and the copy's path.
+func migrateConfigFile(configFile string, doc *yaml.Node) ([]configmigrate.Migration, string, error) {
+	from, err := configmigrate.Version(doc.Content[0])
+	if err != nil {
+		return nil, "", err
+	}
+	applied, err := configmigrate.Migrate(doc.Content[0])
+	if err != nil {
+		return nil, "", err
+	}
+
+	original, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading %s: %v", configFile, err)
+	}
+	// An earlier backup of the same version is kept: it may be the only one left
+	// of settings the user changed since
+	backup := fmt.Sprintf("%s.v%d.bak", configFile, from)
+	if _, err := os.Stat(backup); err == nil {
+		backup = fmt.Sprintf("%s.v%d.%s.bak", configFile, from, time.Now().Format("20060102-150405"))
+	}
+	// The file holds the API key, so the copy is as private as the original
+	if err := os.WriteFile(backup, original, 0600); err != nil {
+		return nil, "", fmt.Errorf("error backing up %s: %v", configFile, err)
+	}
+	if err := saveConfigDocument(configFile, doc); err != nil {
+		return nil, "", err
+	}
+	return applied, backup, nil
+}
+
+// upgradeConfigFile upgrades an old config file when sgit starts, so settings that
+// moved are found where this version looks for them. Failures only warn: the old
+// settings are still read where they can be.
+func upgradeConfigFile() {
+	configFile := viper.ConfigFileUsed()
+	if ext := strings.ToLower(filepath.Ext(configFile)); ext != ".yaml" && ext != ".yml" {
+		return
+	}
+	if _, err := os.Stat(configFile); err != nil {
+		return
+	}
+	doc, err := loadConfigDocument(configFile)
+	if err != nil {
+		return
+	}
+	pending, err := configmigrate.Pending(doc.Content[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	_, backup, err := migrateConfigFile(configFile, doc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not upgrade %s: %v (run 'sgit migrate-config' to retry)\n", configFile, err)
+		return
+	}
+	if err := viper.ReadInConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Error reading %s: %v\n", configFile, err)
+	}
+	statusf("🔧 Upgraded %s to config version %d (the old file is saved as %s)\n", configFile, configmigrate.CurrentVersion(), backup)
+}
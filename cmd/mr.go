@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/bitbucket"
+	"github.com/hunkim/sgit/pkg/gitlab"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	mrBase     string
+	mrCreate   bool
+	mrDraft    bool
+	mrProvider string
+	mrRemote   string
+)
+
+// mrCmd represents the mr command
+var mrCmd = &cobra.Command{
+	Use:   "mr",
+	Short: "Generate a GitLab merge request or Bitbucket pull request with AI",
+	Long: `Diff the current branch against its base branch and generate a merge request
+title and description. Use --create to open it through the GitLab (gitlab.com or
+self-hosted) or Bitbucket Cloud REST API.
+
+Configure in ~/.config/sgit/config.yaml:
+  gitlab_url: https://gitlab.example.com   # default: derived from the remote
+  gitlab_token: <token>                    # or GITLAB_TOKEN
+  bitbucket_username: <user>               # or BITBUCKET_USERNAME
+  bitbucket_app_password: <password>       # or BITBUCKET_APP_PASSWORD
+  bitbucket_token: <token>                 # or BITBUCKET_TOKEN (instead of username/app password)`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMR(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mrCmd)
+
+	mrCmd.Flags().StringVar(&mrBase, "base", "", "target branch to compare against (default: detected from the remotes)")
+	mrCmd.Flags().BoolVar(&mrCreate, "create", false, "create the merge request")
+	mrCmd.Flags().BoolVar(&mrDraft, "draft", false, "create the merge request as a draft")
+	mrCmd.Flags().StringVar(&mrProvider, "provider", "", "hosting provider (gitlab|bitbucket, default: detected from the remote)")
+	mrCmd.Flags().StringVar(&mrRemote, "remote", "origin", "remote hosting the repository")
+}
+
+func runMR(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	remoteURL, err := runGitOutput("remote", "get-url", mrRemote)
+	if err != nil {
+		return fmt.Errorf("error getting %s remote: %v", mrRemote, err)
+	}
+	host, projectPath, err := gitlab.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return err
+	}
+
+	provider, err := detectMRProvider(host)
+	if err != nil {
+		return err
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+
+	mr, err := generatePullRequestContent(cmd, mrBase)
+	if err != nil {
+		return err
+	}
+
+	if !mrCreate {
+		fmt.Printf("💡 Use 'sgit mr --create' to open this merge request on %s\n", host)
+		return nil
+	}
+
+	if !confirm("Create this merge request? (y/n): ") {
+		fmt.Println("Merge request creation cancelled")
+		return nil
+	}
+
+	target := baseBranchName(mr.base)
+	switch provider {
+	case "gitlab":
+		return createGitLabMergeRequest(cmd, host, projectPath, mr, target)
+	default:
+		return createBitbucketPullRequest(cmd, projectPath, mr, target)
+	}
+}
+
+// detectMRProvider picks the hosting provider from --provider, mr_provider, or the remote host
+func detectMRProvider(host string) (string, error) {
+	provider := mrProvider
+	if provider == "" {
+		provider = viper.GetString("mr_provider")
+	}
+	if provider == "" {
+		switch {
+		case strings.Contains(host, "bitbucket"):
+			provider = "bitbucket"
+		case strings.Contains(host, "gitlab"), viper.GetString("gitlab_url") != "":
+			provider = "gitlab"
+		case strings.Contains(host, "github"):
+			return "", fmt.Errorf("%s is a GitHub remote, use 'sgit pr' instead", host)
+		default:
+			return "", fmt.Errorf("could not detect the hosting provider for %s, use --provider gitlab|bitbucket", host)
+		}
+	}
+
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if provider != "gitlab" && provider != "bitbucket" {
+		return "", fmt.Errorf("unsupported provider '%s' (use gitlab or bitbucket)", provider)
+	}
+	return provider, nil
+}
+
+func createGitLabMergeRequest(cmd *cobra.Command, host, projectPath string, mr *generatedPullRequest, target string) error {
+	token := os.Getenv("GITLAB_TOKEN")
+	if token == "" {
+		token = viper.GetString("gitlab_token")
+	}
+	if token == "" {
+		return fmt.Errorf("no GitLab token configured (set GITLAB_TOKEN or gitlab_token in config)")
+	}
+
+	baseURL := viper.GetString("gitlab_url")
+	if baseURL == "" {
+		baseURL = "https://" + host
+	}
+
+	client := gitlab.NewClient(token, baseURL)
+	created, err := client.CreateMergeRequest(cmd.Context(), projectPath, gitlab.NewMergeRequest{
+		SourceBranch: mr.branch,
+		TargetBranch: target,
+		Title:        mr.title,
+		Description:  mr.body,
+	}, mrDraft)
+	if err != nil {
+		return fmt.Errorf("error creating merge request (is '%s' pushed?): %v", mr.branch, err)
+	}
+
+	fmt.Printf("✅ Created merge request !%d: %s\n", created.IID, created.WebURL)
+	return nil
+}
+
+func createBitbucketPullRequest(cmd *cobra.Command, projectPath string, mr *generatedPullRequest, target string) error {
+	parts := strings.Split(projectPath, "/")
+	if len(parts) != 2 {
+		return fmt.Errorf("unrecognized Bitbucket repository path: %s", projectPath)
+	}
+
+	username := firstNonEmpty(os.Getenv("BITBUCKET_USERNAME"), viper.GetString("bitbucket_username"))
+	password := firstNonEmpty(os.Getenv("BITBUCKET_APP_PASSWORD"), viper.GetString("bitbucket_app_password"))
+	token := firstNonEmpty(os.Getenv("BITBUCKET_TOKEN"), viper.GetString("bitbucket_token"))
+	if (username == "" || password == "") && token == "" {
+		return fmt.Errorf("no Bitbucket credentials configured (set BITBUCKET_USERNAME and BITBUCKET_APP_PASSWORD, or BITBUCKET_TOKEN)")
+	}
+	if username != "" && password == "" {
+		username = ""
+	}
+
+	client := bitbucket.NewClient(username, password, token, viper.GetString("bitbucket_api_url"))
+	created, err := client.CreatePullRequest(cmd.Context(), parts[0], parts[1], mr.title, mr.body, mr.branch, target, mrDraft)
+	if err != nil {
+		return fmt.Errorf("error creating pull request (is '%s' pushed?): %v", mr.branch, err)
+	}
+
+	fmt.Printf("✅ Created pull request #%d: %s\n", created.ID, created.Links.HTML.Href)
+	return nil
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
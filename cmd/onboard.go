@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/i18n"
+	"github.com/hunkim/sgit/pkg/progress"
+	"github.com/hunkim/sgit/pkg/render"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+)
+
+var (
+	onboardSince  string
+	onboardFormat string
+	onboardOutput string
+)
+
+// releaseFiles are tracked files that tell how a project is released or built in CI
+var releaseFiles = []string{
+	".goreleaser.yml", ".goreleaser.yaml", ".releaserc", ".releaserc.json", "release.config.js",
+	"CHANGELOG.md", "RELEASING.md", "lerna.json", ".changeset/", ".github/workflows/",
+	".gitlab-ci.yml", "Jenkinsfile", ".circleci/", "azure-pipelines.yml", ".travis.yml",
+}
+
+// onboardCmd writes a newcomer's guide to the repository
+var onboardCmd = &cobra.Command{
+	Use:   "onboard",
+	Short: "Write a newcomer's guide to the repository",
+	Long: `Look at the repository's structure, build files, recent activity, top contributors
+and releases, and have Solar LLM write a guide for a developer who is new to it: what
+to read first, the core packages, how to build and test, where work is happening, how
+releases happen and whom to ask.
+
+--since sets how far back the recent activity goes. --format renders the guide as
+ansi, plain, markdown or html; --output also writes the markdown guide to a file.
+
+Examples:
+  sgit onboard
+  sgit onboard --since "3 months ago"
+  sgit onboard -o ONBOARDING.md`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runOnboard(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(onboardCmd)
+
+	onboardCmd.Flags().StringVar(&onboardSince, "since", "6 months ago", "how far back the recent activity goes")
+	onboardCmd.Flags().StringVar(&onboardFormat, "format", "", "guide output format (auto|ansi|plain|markdown|html)")
+	onboardCmd.Flags().StringVarP(&onboardOutput, "output", "o", "", "also write the guide to a file")
+}
+
+func runOnboard(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	renderer, err := outputRenderer(onboardFormat)
+	if err != nil {
+		return err
+	}
+	report := renderer.Format() == render.HTML
+
+	if head, _ := runGitOutput("rev-parse", "--verify", "-q", "HEAD"); strings.TrimSpace(head) == "" {
+		return fmt.Errorf("the repository has no commits yet - try 'sgit init' instead")
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+
+	gathering := progress.Begin(i18n.T("Exploring the repository"))
+	overview, err := describeRepository(onboardSince)
+	gathering.End()
+	if err != nil {
+		return err
+	}
+
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+	if !report {
+		printContentStats("Repository overview", overview.Structure, overview.Activity, overview.Contributors, overview.Releases)
+	}
+	printer := newStreamPrinter("")
+	printer.renderer = renderer
+	result, err := client.OnboardStream(cmd.Context(), overview, printer.Write)
+	printer.Done()
+	if err != nil {
+		return fmt.Errorf("error generating onboarding guide: %w", err)
+	}
+	fmt.Println() // Add newline after streaming output
+
+	if onboardOutput != "" {
+		if err := os.WriteFile(onboardOutput, []byte(strings.TrimSpace(result)+"\n"), 0644); err != nil {
+			return fmt.Errorf("error writing %s: %v", onboardOutput, err)
+		}
+		fmt.Fprintf(os.Stderr, "✅ Wrote %s\n", onboardOutput)
+	}
+	return nil
+}
+
+// describeRepository gathers what the onboarding guide is written from: the tracked
+// files, the commits since since, the top contributors and the releases
+func describeRepository(since string) (solar.RepositoryOverview, error) {
+	root, err := runGitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return solar.RepositoryOverview{}, fmt.Errorf("error finding the repository root: %w", err)
+	}
+	root = strings.TrimSpace(root)
+	// The whole repository, with paths from its root, wherever sgit runs
+	files, err := runGitOutput("ls-files", "--full-name", ":/")
+	if err != nil {
+		return solar.RepositoryOverview{}, fmt.Errorf("error listing files: %w", err)
+	}
+	tracked := strings.Split(strings.TrimSpace(files), "\n")
+
+	var overview solar.RepositoryOverview
+	overview.Structure = describeTrackedFiles(root, tracked)
+
+	var activity strings.Builder
+	commits, _ := runGitOutput("log", "--no-merges", "--since="+since, "-n", "40", "--date=short", "--format=%ad %h %an: %s")
+	if commits = strings.TrimSpace(commits); commits == "" {
+		commits, _ = runGitOutput("log", "--no-merges", "-n", "20", "--date=short", "--format=%ad %h %an: %s")
+		fmt.Fprintf(&activity, "No commits since %s; the latest ones:\n%s\n", since, strings.TrimSpace(commits))
+	} else {
+		fmt.Fprintf(&activity, "Commits since %s (newest first):\n%s\n", since, commits)
+	}
+	if changed, _ := runGitOutput("log", "--no-merges", "--since="+since, "--name-only", "--format="); strings.TrimSpace(changed) != "" {
+		fmt.Fprintf(&activity, "\nMost changed directories since %s: %s\n", since, rankDirectories(strings.Split(changed, "\n"), 10))
+	}
+	overview.Activity = activity.String()
+
+	// shortlog reads stdin unless given a revision
+	contributors, _ := runGitOutput("shortlog", "-sn", "--no-merges", "HEAD")
+	overview.Contributors = limitLines(strings.TrimSpace(contributors), 10)
+
+	var releases strings.Builder
+	tags, _ := runGitOutput("for-each-ref", "--sort=-creatordate", "--count=15", "--format=%(refname:short) %(creatordate:short)", "refs/tags")
+	if tags = strings.TrimSpace(tags); tags != "" {
+		fmt.Fprintf(&releases, "Newest tags:\n%s\n", tags)
+	} else {
+		releases.WriteString("No tags.\n")
+	}
+	var tooling []string
+	for _, name := range releaseFiles {
+		for _, file := range tracked {
+			if file == name || strings.HasSuffix(name, "/") && strings.HasPrefix(file, name) {
+				tooling = append(tooling, file)
+			}
+		}
+	}
+	if len(tooling) > 0 {
+		fmt.Fprintf(&releases, "Release and CI files: %s\n", strings.Join(tooling, ", "))
+	}
+	overview.Releases = releases.String()
+	return overview, nil
+}
+
+// describeTrackedFiles summarizes the files tracked in the repository at root for the
+// AI: the top-level entries and largest directories with their file counts, the file
+// types, and the start of the README and the manifests at the root
+func describeTrackedFiles(root string, files []string) string {
+	topLevel := make(map[string]int)
+	extCounts := make(map[string]int)
+	var manifests []string
+	readme := ""
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		first, _, nested := strings.Cut(file, "/")
+		if nested {
+			first += "/"
+		}
+		topLevel[first]++
+		if ext := path.Ext(file); ext != "" {
+			extCounts[ext]++
+		}
+		if !nested && projectManifests[file] {
+			manifests = append(manifests, file)
+		}
+		if !nested && readme == "" && strings.HasPrefix(strings.ToUpper(file), "README") {
+			readme = file
+		}
+	}
+
+	entries := make([]string, 0, len(topLevel))
+	for entry := range topLevel {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+	exts := make([]string, 0, len(extCounts))
+	for ext := range extCounts {
+		exts = append(exts, ext)
+	}
+	sort.Slice(exts, func(i, j int) bool { return extCounts[exts[i]] > extCounts[exts[j]] })
+	if len(exts) > 15 {
+		exts = exts[:15]
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Repository: %s\n", filepath.Base(root))
+	fmt.Fprintf(&b, "Tracked files: %d\n", len(files))
+	b.WriteString("Top-level entries (file counts):")
+	for _, entry := range entries {
+		if strings.HasSuffix(entry, "/") {
+			fmt.Fprintf(&b, " %s (%d)", entry, topLevel[entry])
+		} else {
+			fmt.Fprintf(&b, " %s", entry)
+		}
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "Largest directories: %s\n", rankDirectories(files, 15))
+	b.WriteString("File types:")
+	for _, ext := range exts {
+		fmt.Fprintf(&b, " %s (%d)", ext, extCounts[ext])
+	}
+	b.WriteString("\n")
+
+	sort.Strings(manifests)
+	if readme != "" {
+		manifests = append([]string{readme}, manifests...)
+	}
+	for _, manifest := range manifests {
+		content, err := os.ReadFile(filepath.Join(root, manifest))
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "\n--- %s ---\n%s\n", manifest, limitLines(strings.TrimSpace(string(content)), 40))
+	}
+	return b.String()
+}
+
+// rankDirectories counts the files under each directory up to two levels deep and
+// lists the top ones, e.g. "pkg/solar/ (31), cmd/ (54)"
+func rankDirectories(files []string, top int) string {
+	counts := make(map[string]int)
+	for _, file := range files {
+		dir := path.Dir(strings.TrimSpace(file))
+		if dir == "." || strings.TrimSpace(file) == "" {
+			continue
+		}
+		if parts := strings.SplitN(dir, "/", 3); len(parts) > 2 {
+			dir = parts[0] + "/" + parts[1]
+		}
+		counts[dir+"/"]++
+	}
+	dirs := make([]string, 0, len(counts))
+	for dir := range counts {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if counts[dirs[i]] != counts[dirs[j]] {
+			return counts[dirs[i]] > counts[dirs[j]]
+		}
+		return dirs[i] < dirs[j]
+	})
+	if len(dirs) > top {
+		dirs = dirs[:top]
+	}
+	ranked := make([]string, len(dirs))
+	for i, dir := range dirs {
+		ranked[i] = fmt.Sprintf("%s (%d)", dir, counts[dir])
+	}
+	if len(ranked) == 0 {
+		return "none"
+	}
+	return strings.Join(ranked, ", ")
+}
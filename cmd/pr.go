@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/github"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	prBase   string
+	prCreate bool
+	prDraft  bool
+)
+
+// prCmd represents the pr command
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Generate a pull request title and description with AI",
+	Long: `Diff the current branch against its base branch and generate a pull request
+title and markdown description (summary, changes, testing notes).
+Use --create to open the pull request via the GitHub CLI (gh) or the GitHub API.
+
+The base branch is the default branch of the upstream repository in a fork workflow:
+the remote set as pr_remote in config, a remote named upstream, or the remote for the
+repository origin was forked from on GitHub; otherwise origin's. A branch that tracks
+another branch (git switch -c feature --track upstream/main) is compared against it.
+The pull request is opened on the base's repository, from your fork's branch.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runPR(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(prCmd)
+
+	prCmd.Flags().StringVar(&prBase, "base", "", "base branch to compare against (default: the default branch of upstream, or origin)")
+	prCmd.Flags().BoolVar(&prCreate, "create", false, "create the pull request on GitHub")
+	prCmd.Flags().BoolVar(&prDraft, "draft", false, "create the pull request as a draft")
+}
+
+func runPR(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+
+	pr, err := generatePullRequestContent(cmd, prBase)
+	if err != nil {
+		return err
+	}
+
+	if !prCreate {
+		fmt.Println("💡 Use 'sgit pr --create' to open this pull request on GitHub")
+		return nil
+	}
+
+	if !confirm("Create this pull request? (y/n): ") {
+		fmt.Println("Pull request creation cancelled")
+		return nil
+	}
+
+	return createPullRequest(cmd, pr.branch, pr.base, pr.title, pr.body)
+}
+
+// generatedPullRequest is an AI-generated pull/merge request for the current branch
+type generatedPullRequest struct {
+	branch string
+	base   string
+	title  string
+	body   string
+}
+
+// generatePullRequestContent generates and prints a title and description for the
+// current branch against base (detected when empty)
+func generatePullRequestContent(cmd *cobra.Command, base string) (*generatedPullRequest, error) {
+	branch, err := getCurrentBranch()
+	if err != nil || branch == "" {
+		return nil, fmt.Errorf("could not determine current branch (detached HEAD?)")
+	}
+
+	if base == "" {
+		base, err = detectBaseBranch()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	commits, err := runGitOutput("log", "--oneline", "--no-merges", base+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("error getting commits since %s: %v", base, err)
+	}
+	if strings.TrimSpace(commits) == "" {
+		return nil, fmt.Errorf("no commits on %s since %s", branch, base)
+	}
+
+	fileList, _ := runGitOutput("diff", "--name-status", base+"...HEAD")
+	diff, err := runGitOutput("diff", base+"...HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("error getting diff against %s: %v", base, err)
+	}
+
+	client, err := newSolarClient()
+	if err != nil {
+		return nil, err
+	}
+
+	statusf("Generating pull request description for %s → %s with Solar LLM...\n", branch, base)
+	response, err := client.GeneratePullRequest(cmd.Context(), branch, base, commits, fileList, diff)
+	if err != nil {
+		return nil, fmt.Errorf("error generating pull request description: %w", err)
+	}
+
+	title, body := parsePRResponse(response)
+	// pr_footer is added as configured, e.g. "Closes {{.Ticket}}"
+	if footer := viper.GetString("pr_footer"); footer != "" {
+		if footer = strings.TrimSpace(expandTemplateVars("pr_footer", footer, templateVars(branchTicketKey()))); footer != "" {
+			body = strings.TrimSpace(body) + "\n\n" + footer
+		}
+	}
+
+	fmt.Println("\n=== PULL REQUEST ===")
+	fmt.Printf("Title: %s\n\n", title)
+	fmt.Println(body)
+	fmt.Println()
+
+	return &generatedPullRequest{branch: branch, base: base, title: title, body: body}, nil
+}
+
+// parsePRResponse splits the model's "TITLE: ... BODY: ..." response into title and body
+func parsePRResponse(response string) (string, string) {
+	response = strings.TrimSpace(response)
+
+	var title, body string
+	if idx := strings.Index(response, "BODY:"); idx != -1 {
+		title = strings.TrimSpace(response[:idx])
+		body = strings.TrimSpace(response[idx+len("BODY:"):])
+	} else {
+		lines := strings.SplitN(response, "\n", 2)
+		title = lines[0]
+		if len(lines) > 1 {
+			body = strings.TrimSpace(lines[1])
+		}
+	}
+
+	title = strings.TrimSpace(strings.TrimPrefix(title, "TITLE:"))
+	return title, body
+}
+
+// createPullRequest opens a pull request for branch on the repository of the base ref
+// (e.g. upstream/main). When branch is pushed to another repository, as in a fork
+// workflow, the pull request's head is "<fork owner>:<branch>".
+func createPullRequest(cmd *cobra.Command, branch, baseRef, title, body string) error {
+	remote, base := splitRemoteRef(baseRef)
+	if remote == "" {
+		remote = "origin"
+	}
+	remoteURL, err := runGitOutput("remote", "get-url", remote)
+	if err != nil {
+		return fmt.Errorf("error getting %s remote: %w", remote, err)
+	}
+	host, owner, repo, err := github.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return err
+	}
+	head := branch
+	if headRemote := pushRemote(branch); headRemote != remote {
+		if headURL, err := runGitOutput("remote", "get-url", headRemote); err == nil {
+			if _, headOwner, _, err := github.ParseRemoteURL(headURL); err == nil && !strings.EqualFold(headOwner, owner) {
+				head = headOwner + ":" + branch
+			}
+		}
+	}
+
+	// Prefer the GitHub CLI when available since it handles auth and pushing prompts
+	if _, err := exec.LookPath("gh"); err == nil {
+		bodyFile, err := ioutil.TempFile(os.TempDir(), "sgit-pr-*.md")
+		if err != nil {
+			return fmt.Errorf("failed to create temp file: %w", err)
+		}
+		defer os.Remove(bodyFile.Name())
+
+		if _, err := bodyFile.WriteString(body); err != nil {
+			bodyFile.Close()
+			return fmt.Errorf("failed to write to temp file: %w", err)
+		}
+		bodyFile.Close()
+
+		repoName := owner + "/" + repo
+		if !strings.EqualFold(host, "github.com") {
+			repoName = host + "/" + repoName
+		}
+		ghArgs := []string{"pr", "create", "--repo", repoName, "--title", title, "--body-file", bodyFile.Name(), "--base", base, "--head", head}
+		if prDraft {
+			ghArgs = append(ghArgs, "--draft")
+		}
+
+		ghCmd := exec.Command("gh", ghArgs...)
+		ghCmd.Stdin = os.Stdin
+		ghCmd.Stdout = os.Stdout
+		ghCmd.Stderr = os.Stderr
+		return ghCmd.Run()
+	}
+
+	// Fall back to the GitHub REST API
+	token := getGitHubToken()
+	if token == "" {
+		return fmt.Errorf("GitHub CLI not found and no GitHub token configured (set GITHUB_TOKEN or github_token in config)")
+	}
+
+	client := github.NewClient(token, viper.GetString("github_api_url"))
+	pr, err := client.CreatePullRequest(cmd.Context(), owner, repo, github.NewPullRequest{
+		Title: title,
+		Head:  head,
+		Base:  base,
+		Body:  body,
+		Draft: prDraft,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating pull request (is '%s' pushed?): %v", branch, err)
+	}
+
+	fmt.Printf("✅ Created pull request #%d: %s\n", pr.Number, pr.HTMLURL)
+	return nil
+}
+
+// getGitHubToken returns the GitHub token from the environment or config
+func getGitHubToken() string {
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token
+	}
+	if token := os.Getenv("GH_TOKEN"); token != "" {
+		return token
+	}
+	return viper.GetString("github_token")
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/difffilter"
+	"github.com/hunkim/sgit/pkg/i18n"
+	"github.com/hunkim/sgit/pkg/logging"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultMaxDiffTokens is the diff size above which large_diff applies
+	defaultMaxDiffTokens = 20000
+	// condensedLinesPerFile is how many changed lines of each file a diff summarized
+	// per file keeps
+	condensedLinesPerFile = 20
+)
+
+// Values of the large_diff setting: what happens to a diff over max_diff_tokens
+const (
+	largeDiffAsk       = "ask"
+	largeDiffSummarize = "summarize"
+	largeDiffSend      = "send"
+	largeDiffFail      = "fail"
+)
+
+// normalizeLargeDiffMode checks a large_diff setting
+func normalizeLargeDiffMode(value string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(value)); mode {
+	case largeDiffAsk, largeDiffSummarize, largeDiffSend, largeDiffFail:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid large_diff '%s' (use ask, summarize, send or fail)", value)
+}
+
+// maxDiffTokens returns max_diff_tokens from config; 0 or less turns the check off
+func maxDiffTokens() int {
+	if !viper.IsSet("max_diff_tokens") {
+		return defaultMaxDiffTokens
+	}
+	return viper.GetInt("max_diff_tokens")
+}
+
+// estimatedCost describes the input cost of tokens for the configured model, e.g.
+// "~$0.0031", or returns "" when the model's price is unknown. input_price_per_million
+// sets the price for models sgit has no price for.
+func estimatedCost(tokens int) string {
+	price, ok := viper.GetFloat64("input_price_per_million"), viper.IsSet("input_price_per_million")
+	if !ok {
+		if price, ok = solar.InputPrice(configuredModel()); !ok {
+			return ""
+		}
+	}
+	return fmt.Sprintf("~$%.4f", float64(tokens)*price/1e6)
+}
+
+// configuredModel returns the model the running command uses
+func configuredModel() string {
+	if key := commandSettingKey("model", "upstage_model_name"); key != "" && viper.GetString(key) != "" {
+		return viper.GetString(key)
+	}
+	return solar.DefaultModel
+}
+
+// modelContextWindow returns the context size of model in tokens: context_window,
+// which like the model can be set per command or profile, else the longest model
+// prefix in context_windows, else sgit's own table (solar.ContextWindows), else
+// solar.ModelContextLimit. For example:
+//
+//	context_windows:
+//	  my-finetune: 16384
+//	  llama3.1: 32768   # Ollama's num_ctx, when it is lower than the model's
+func modelContextWindow(model string) int {
+	if key := commandSettingKey("context_window", "context_window"); key != "" && viper.GetInt(key) > 0 {
+		return viper.GetInt(key)
+	}
+
+	// Model names contain dots, which viper reads as nesting, so the map is read whole
+	model = strings.ToLower(model)
+	match, tokens := "", 0
+	for prefix, value := range viper.GetStringMap("context_windows") {
+		if !strings.HasPrefix(model, strings.ToLower(prefix)) || len(prefix) <= len(match) {
+			continue
+		}
+		switch size := value.(type) {
+		case int:
+			match, tokens = prefix, size
+		case float64:
+			match, tokens = prefix, int(size)
+		default:
+			fmt.Fprintf(os.Stderr, "Warning: invalid context_windows.%s '%v' (use a number of tokens)\n", prefix, value)
+		}
+	}
+	if tokens > 0 {
+		return tokens
+	}
+	if tokens, ok := solar.ContextWindow(model); ok {
+		return tokens
+	}
+	return solar.ModelContextLimit
+}
+
+// checkDiffSize checks the diff against max_diff_tokens before any request is made.
+// A larger diff is sent, summarized per file, or refused, as large_diff says (asking by
+// default). It returns the diff to send.
+func checkDiffSize(diff string) (string, error) {
+	limit := maxDiffTokens()
+	tokens := solar.NewTokenCounter().EstimateTokens(diff)
+	// Nothing is sent with --show-prompt
+	if limit <= 0 || tokens <= limit || showPrompt {
+		return diff, nil
+	}
+
+	mode := largeDiffAsk
+	if viper.IsSet("large_diff") {
+		var err error
+		if mode, err = normalizeLargeDiffMode(viper.GetString("large_diff")); err != nil {
+			return "", err
+		}
+	}
+	cost := ""
+	if estimate := estimatedCost(tokens); estimate != "" {
+		cost = ", " + estimate
+	}
+	fmt.Fprint(os.Stderr, i18n.T("⚠️  The diff is ~%d tokens%s, over max_diff_tokens (%d)\n", tokens, cost, limit))
+
+	// --yes sends the diff, as a confirmation would
+	if mode == largeDiffAsk && assumeYes {
+		mode = largeDiffSend
+	}
+	if mode == largeDiffAsk {
+		switch strings.ToLower(ask(i18n.T("Send it whole (y), summarized per file (s), or stop (n)? [s]: "))) {
+		case "y", "yes":
+			mode = largeDiffSend
+		case "n", "no":
+			mode = largeDiffFail
+		default:
+			mode = largeDiffSummarize
+		}
+	}
+
+	logging.Info("large diff", "tokens", tokens, "max_diff_tokens", limit, "large_diff", mode)
+	switch mode {
+	case largeDiffSend:
+		return diff, nil
+	case largeDiffFail:
+		return "", fmt.Errorf("the diff is larger than max_diff_tokens (%d) - narrow it down, or raise max_diff_tokens", limit)
+	}
+	condensed := difffilter.Condense(diff, condensedLinesPerFile)
+	condensedTokens := solar.NewTokenCounter().EstimateTokens(condensed)
+	statusf("%s", i18n.T("✂️  Summarized the diff per file: ~%d tokens", condensedTokens))
+	if estimate := estimatedCost(condensedTokens); estimate != "" {
+		statusf(", %s", estimate)
+	}
+	statusln("")
+	return condensed, nil
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// profileFlag is --profile, the config profile of this run
+var profileFlag string
+
+// Where the config profile of a run comes from, in order of precedence
+const (
+	profileFromFlag   = "--profile"
+	profileFromEnv    = "SGIT_PROFILE"
+	profileFromPath   = "paths"
+	profileFromConfig = "profile setting"
+)
+
+// profileGenerationKeys are the settings of a profile that commandSettingKey reads
+// per command, so commands.<name> still wins over them; applyConfigProfile leaves
+// them where they are
+var profileGenerationKeys = []string{"model", "context_window", "system_prompt", "temperature", "top_p", "max_tokens", "reasoning_effort"}
+
+var configProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List the config profiles and which one is in use",
+	Long: `List the profiles under profiles in the config file, marking the one in use and
+why it was picked. A profile can hold any setting, e.g. its own API key, model,
+language and convention, and replaces the top-level setting while it is in use.
+
+The profile is picked by, in order:
+  --profile <name>     for one command
+  SGIT_PROFILE         for a shell or CI job
+  paths                the profile whose paths contain the working directory
+                       (the longest match wins)
+  profile              the default in the config file
+
+Example config:
+  profiles:
+    work:
+      api_key: up_work...
+      convention: conventional
+      paths: [~/work]
+    personal:
+      api_key: up_home...
+      language: ko
+  profile: personal
+
+Examples:
+  sgit config profiles
+  sgit commit --profile work
+  sgit config init --profile work     # set up the API key, model and language of work`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigProfiles(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configProfilesCmd)
+}
+
+func runConfigProfiles(cmd *cobra.Command, args []string) error {
+	profiles := viper.GetStringMap("profiles")
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	active, source := configProfile()
+	if len(names) == 0 {
+		fmt.Println("No profiles defined; add them under profiles in the config file (see 'sgit config profiles --help')")
+		return nil
+	}
+
+	for _, name := range names {
+		marker := "  "
+		if name == active {
+			marker = "* "
+		}
+		var details []string
+		if model := viper.GetString("profiles." + name + ".model"); model != "" {
+			details = append(details, "model "+model)
+		}
+		if language := viper.GetString("profiles." + name + ".language"); language != "" {
+			details = append(details, "language "+language)
+		}
+		if convention := viper.GetString("profiles." + name + ".convention"); convention != "" {
+			details = append(details, "convention "+convention)
+		}
+		if viper.IsSet("profiles."+name+".api_key") || viper.IsSet("profiles."+name+"."+apiKeySetting) {
+			details = append(details, "own API key")
+		}
+		if paths := viper.GetStringSlice("profiles." + name + ".paths"); len(paths) > 0 {
+			details = append(details, "paths "+strings.Join(paths, ", "))
+		}
+		fmt.Printf("%s%s", marker, name)
+		if len(details) > 0 {
+			fmt.Printf("  (%s)", strings.Join(details, "; "))
+		}
+		fmt.Println()
+	}
+
+	switch {
+	case active == "":
+		fmt.Println("\nNo profile in use; the top-level settings apply")
+	case !slices.Contains(names, active):
+		return fmt.Errorf("profile '%s' (from %s) is not defined under profiles in config", active, source)
+	default:
+		fmt.Printf("\nIn use: %s (from %s)\n", active, source)
+	}
+	return nil
+}
+
+// configProfile returns the config profile of this run and where it comes from:
+// --profile, SGIT_PROFILE, the profile whose paths contain the working directory, or
+// the profile setting. It returns "" when no profile is in use.
+func configProfile() (name, source string) {
+	// Setting names are case-insensitive
+	if profileFlag != "" {
+		return strings.ToLower(profileFlag), profileFromFlag
+	}
+	if env := os.Getenv("SGIT_PROFILE"); env != "" {
+		return strings.ToLower(env), profileFromEnv
+	}
+	if name := pathProfile(); name != "" {
+		return name, profileFromPath
+	}
+	if name := viper.GetString("profile"); name != "" {
+		return name, profileFromConfig
+	}
+	return "", ""
+}
+
+// profileArg returns the value of --profile among the arguments of a command that
+// parses its own flags
+func profileArg(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--profile="); ok {
+			return value
+		}
+		if arg == "--profile" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// pathProfile returns the profile with a path that contains the working directory.
+// The longest path wins, so ~/work/oss can use another profile than the rest of ~/work.
+func pathProfile() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	dir = resolvePath(dir)
+
+	best, bestLength := "", 0
+	for name := range viper.GetStringMap("profiles") {
+		for _, path := range viper.GetStringSlice("profiles." + name + ".paths") {
+			root := resolvePath(expandHome(path))
+			if (dir == root || strings.HasPrefix(dir, root+string(filepath.Separator))) && len(root) > bestLength {
+				best, bestLength = name, len(root)
+			}
+		}
+	}
+	return best
+}
+
+// resolvePath returns the absolute path with symlinks resolved where it exists
+func resolvePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return filepath.Clean(path)
+}
+
+// applyConfigProfile puts the config profile of this run in effect. Its model and
+// generation settings are read per command (see commandSettingKey); every other setting
+// in it, e.g. api_key, language, convention or provider, replaces the top-level one.
+func applyConfigProfile() {
+	name, _ := configProfile()
+	if name == "" {
+		return
+	}
+	viper.Set("profile", name)
+
+	// An undefined profile is reported when the client is created
+	profile := viper.Sub("profiles." + name)
+	if profile == nil {
+		return
+	}
+	for _, key := range profile.AllKeys() {
+		if key == "paths" || slices.Contains(profileGenerationKeys, key) {
+			continue
+		}
+		setting := key
+		if key == "api_key" {
+			setting = apiKeySetting
+		}
+		viper.Set(setting, profile.Get(key))
+	}
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hunkim/sgit/pkg/i18n"
+	"github.com/hunkim/sgit/pkg/progress"
+	"github.com/hunkim/sgit/pkg/render"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+// streamPrinter shows a spinner while waiting for the first streamed chunk, then
+// prints each chunk to stdout as it arrives, rendered when a renderer is set
+type streamPrinter struct {
+	label    string
+	stage    *progress.Stage
+	started  bool
+	renderer *render.Renderer
+}
+
+// newStreamPrinter starts a spinner; label is printed before the first chunk
+func newStreamPrinter(label string) *streamPrinter {
+	return &streamPrinter{label: label, stage: progress.Begin(i18n.T("Waiting for Solar LLM..."))}
+}
+
+// Write prints a streamed chunk, stopping the spinner on the first one
+func (p *streamPrinter) Write(chunk string) {
+	if !p.started {
+		p.stage.End()
+		p.started = true
+		if !quiet {
+			fmt.Print(p.label)
+		}
+	}
+	if p.renderer != nil {
+		chunk = p.renderer.Write(chunk)
+	}
+	fmt.Print(rawTerminalText(chunk))
+}
+
+// Done stops the spinner if no chunk was ever received, and prints what the renderer
+// still holds
+func (p *streamPrinter) Done() {
+	p.stage.End()
+	if p.renderer != nil {
+		fmt.Print(rawTerminalText(p.renderer.Flush()))
+	}
+}
+
+// rawTerminalText returns text with carriage returns before newlines while keys are
+// read during a stream, since a raw terminal doesn't add them itself
+func rawTerminalText(text string) string {
+	if !streamKeysActive {
+		return text
+	}
+	return strings.ReplaceAll(text, "\n", "\r\n")
+}
+
+// outputRenderer returns the renderer for AI prose output: the format flag, else
+// output_format from config. auto (the default) styles the markdown on a terminal
+// and prints plain text to pipes or when NO_COLOR is set.
+func outputRenderer(format string) (*render.Renderer, error) {
+	if format == "" {
+		format = viper.GetString("output_format")
+	}
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" || format == "auto" {
+		format = render.Plain
+		if term.IsTerminal(int(os.Stdout.Fd())) && os.Getenv("NO_COLOR") == "" {
+			format = render.ANSI
+		}
+	}
+	return render.New(format)
+}
+
+// printContentStats reports how many tokens of content are sent to the model, and
+// what they cost when the model's price is known
+func printContentStats(label string, texts ...string) {
+	if quiet {
+		return
+	}
+
+	tokens := solar.NewTokenCounter().EstimateTokens(strings.Join(texts, ""))
+	if limit := solar.InputTokenLimit(modelContextWindow(configuredModel())); tokens > limit {
+		fmt.Print(i18n.T("📊 %s: ~%d tokens (truncated from ~%d tokens)", i18n.T(label), limit, tokens))
+		tokens = limit
+	} else {
+		fmt.Print(i18n.T("📊 %s: ~%d tokens", i18n.T(label), tokens))
+	}
+	if cost := estimatedCost(tokens); cost != "" {
+		fmt.Printf(", %s", cost)
+	}
+	fmt.Println()
+}
+
+// printRetryNotice tells the user a failed API request is being retried
+func printRetryNotice(reason string, wait time.Duration, attempt, maxAttempts int) {
+	if quiet {
+		return
+	}
+
+	progress.Clear()
+	fmt.Fprint(os.Stderr, i18n.T("⏳ API request failed (%s), retrying in %s (attempt %d/%d)...\n",
+		reason, wait.Round(time.Second), attempt, maxAttempts))
+}
+
+// printError reports a command's error, with a hint on how to fix it when the AI
+// request failed in a known way
+func printError(err error) {
+	progress.Clear()
+	fmt.Fprint(os.Stderr, i18n.T("Error: %v\n", i18n.Lookup(err.Error())))
+	if hint := apiErrorHint(err); hint != "" {
+		fmt.Fprintf(os.Stderr, "💡 %s\n", hint)
+	}
+}
+
+// exitWithError prints err and exits the way a shell expects: with 130 when the
+// command was interrupted, 0 when --show-prompt printed the prompt instead of sending
+// it, and 1 otherwise
+func exitWithError(err error) {
+	if isDryRun(err) {
+		os.Exit(0)
+	}
+	if errors.Is(err, context.Canceled) {
+		progress.Clear()
+		fmt.Fprintln(os.Stderr, "\n"+i18n.T("Interrupted"))
+		os.Exit(130)
+	}
+	printError(err)
+	os.Exit(1)
+}
+
+// apiErrorHint suggests what to do about a failed API request, or returns "" when
+// there is nothing specific to suggest
+func apiErrorHint(err error) string {
+	var apiErr *solar.APIError
+	switch {
+	case errors.Is(err, solar.ErrAuth):
+		return i18n.T("The API key was rejected. Run 'sgit config init' to set a new one, or set SGIT_API_KEY")
+	case errors.Is(err, solar.ErrRateLimited):
+		wait := i18n.T("a minute")
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			wait = apiErr.RetryAfter.Round(time.Second).String()
+		}
+		return i18n.T("The API is rate limiting requests; retry in %s (max_retry_attempts sets how often sgit retries by itself)", wait)
+	case errors.Is(err, solar.ErrContextTooLarge):
+		return i18n.T("The diff is too large for the model. Stage and commit fewer files at a time, use 'sgit diff --summary-only', or leave files out with diff_filter.exclude")
+	case errors.Is(err, solar.ErrNetwork):
+		return i18n.T("Couldn't reach the API. Check your network connection and proxy settings, and base_url if you set one")
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/progress"
+)
+
+// stdinReader is shared by the prompts so answers piped in ahead are not lost
+// between questions
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// confirm prints a y/n question and reports whether the user answered yes.
+// With --yes the question is answered automatically.
+func confirm(question string) bool {
+	if assumeYes {
+		if !quiet {
+			fmt.Println(question + "y (--yes)")
+		}
+		return true
+	}
+
+	fmt.Print(question)
+	response, _ := stdinReader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes"
+}
+
+// statusf prints a progress message, unless --quiet is set
+func statusf(format string, args ...interface{}) {
+	if !quiet {
+		progress.Printf(format, args...)
+	}
+}
+
+// statusln prints a progress message line, unless --quiet is set
+func statusln(args ...interface{}) {
+	if !quiet {
+		progress.Println(args...)
+	}
+}
+
+// ask prints a question and returns the trimmed answer line. With --yes it returns
+// "" without waiting for input.
+func ask(question string) string {
+	if assumeYes {
+		return ""
+	}
+
+	fmt.Print(question)
+	response, _ := stdinReader.ReadString('\n')
+	return strings.TrimSpace(response)
+}
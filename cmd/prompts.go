@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+)
+
+var promptsForce bool
+
+// promptsCmd groups the prompt template subcommands
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "List, show, and export the AI prompt templates",
+	Long: `sgit's prompts are Go text/template files. A <name>.tmpl file in
+~/.config/sgit/prompts (or prompts_dir in config) replaces the built-in prompt of
+that name, so you can tune the wording, add company guidelines, or translate a
+prompt without forking sgit.
+
+Templates can use these variables (empty when they don't apply to the prompt):
+  {{.Diff}} {{.Scope}} {{.Commits}} {{.Branch}} {{.RecentCommits}} {{.FileList}}
+  {{.Log}} {{.Timeframe}} {{.Focus}} {{.Conflicts}} {{.Convention}} {{.ConventionRules}}
+  {{.Hints}} {{.Context}} {{.Language}}
+and the repository metadata, resolved when the prompt is sent:
+  {{.RepoName}} {{.Ticket}} {{.Author}} {{.Branch}} {{.Date}}
+
+Examples:
+  sgit prompts list
+  sgit prompts export comprehensive-commit   # copy the built-in template to edit it
+  sgit prompts show comprehensive-commit`,
+}
+
+// promptsListCmd lists the prompt names and whether they are overridden
+var promptsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the prompt templates and which ones are overridden",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runPromptsList(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// promptsShowCmd prints the template a prompt currently uses
+var promptsShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print the template a prompt uses (the override if there is one)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runPromptsShow(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// promptsExportCmd writes the built-in templates to the prompt directory
+var promptsExportCmd = &cobra.Command{
+	Use:   "export [name...]",
+	Short: "Copy built-in templates into the prompt directory for editing",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runPromptsExport(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(promptsCmd)
+	promptsCmd.AddCommand(promptsListCmd)
+	promptsCmd.AddCommand(promptsShowCmd)
+	promptsCmd.AddCommand(promptsExportCmd)
+
+	promptsExportCmd.Flags().BoolVarP(&promptsForce, "force", "f", false, "overwrite existing template files")
+}
+
+// newPromptClient returns a client that only resolves templates; no API key is needed
+func newPromptClient() (*solar.Client, string, error) {
+	dir, err := promptDirectory()
+	if err != nil {
+		return nil, "", err
+	}
+	client := solar.NewClient("", "", "")
+	client.SetPromptDir(dir)
+	return client, dir, nil
+}
+
+func runPromptsList(cmd *cobra.Command, args []string) error {
+	client, dir, err := newPromptClient()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Prompt templates (overrides in %s):\n\n", dir)
+	for _, prompt := range solar.PromptNames {
+		source := "built-in"
+		if path := client.PromptOverride(prompt.Name); path != "" {
+			source = "overridden: " + path
+		}
+		fmt.Printf("  %-24s %s\n  %-24s (%s)\n", prompt.Name, prompt.Description, "", source)
+	}
+	return nil
+}
+
+func runPromptsShow(cmd *cobra.Command, args []string) error {
+	client, _, err := newPromptClient()
+	if err != nil {
+		return err
+	}
+
+	name := args[0]
+	text, err := solar.DefaultPrompt(name)
+	if err != nil {
+		return err
+	}
+	if path := client.PromptOverride(name); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", path, err)
+		}
+		statusf("# %s\n", path)
+		text = string(content)
+	}
+
+	fmt.Print(text)
+	return nil
+}
+
+func runPromptsExport(cmd *cobra.Command, args []string) error {
+	_, dir, err := newPromptClient()
+	if err != nil {
+		return err
+	}
+
+	names := args
+	if len(names) == 0 {
+		for _, prompt := range solar.PromptNames {
+			names = append(names, prompt.Name)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating %s: %v", dir, err)
+	}
+
+	for _, name := range names {
+		text, err := solar.DefaultPrompt(name)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, name+".tmpl")
+		if _, err := os.Stat(path); err == nil && !promptsForce {
+			fmt.Printf("⏭️  %s already exists (use --force to overwrite)\n", path)
+			continue
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			return fmt.Errorf("error writing %s: %v", path, err)
+		}
+		fmt.Printf("✅ Wrote %s\n", path)
+	}
+
+	fmt.Println("💡 Edit the files to customize the prompts; delete one to go back to the built-in version")
+	return nil
+}
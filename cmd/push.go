@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/secrets"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultProtectedBranches are used when protected_branches is not configured
+var defaultProtectedBranches = []string{"main", "master", "develop", "production", "release/*"}
+
+// defaultMaxPushFileSizeMB is the size above which pushed files are flagged
+const defaultMaxPushFileSizeMB = 5
+
+// wipCommitPattern matches commit subjects that look unfinished
+var wipCommitPattern = regexp.MustCompile(`(?i)^(wip\b|fixup!|squash!|amend!|tmp\b|temp\b|todo\b|do not merge|don't merge|dnm\b)`)
+
+// pushValueFlags are git push options that take a separate value argument
+var pushValueFlags = map[string]bool{
+	"-o":             true,
+	"--push-option":  true,
+	"--repo":         true,
+	"--receive-pack": true,
+	"--exec":         true,
+}
+
+// pushCmd wraps git push with an AI summary and safety checks
+var pushCmd = &cobra.Command{
+	Use:   "push [--no-ai] [git push options] [remote] [refspec...]",
+	Short: "Push with an AI summary and safety check of the outgoing commits",
+	Long: `Summarize the commits about to be pushed with Solar LLM, flag risky patterns
+(force-push to protected branches, large files, WIP commits, leaked secrets), and
+ask for confirmation before running git push. Use --no-ai for a plain git push.
+
+Configure in ~/.config/sgit/config.yaml:
+  protected_branches: [main, master, release/*]
+  push_max_file_size_mb: 5`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runPush(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+	DisableFlagParsing: true,
+}
+
+func init() {
+	rootCmd.AddCommand(pushCmd)
+}
+
+// pushTarget is one local ref being pushed to a remote branch
+type pushTarget struct {
+	local        string
+	remoteBranch string
+	force        bool
+	delete       bool
+}
+
+func runPush(cmd *cobra.Command, args []string) error {
+	// Flag parsing is disabled so every git push option passes through; pick out ours
+	forceAI, noAI := false, false
+	var gitArgs []string
+	for _, arg := range extractGlobalFlags(args) {
+		switch arg {
+		case "--ai":
+			forceAI = true
+		case "--no-ai":
+			noAI = true
+		default:
+			gitArgs = append(gitArgs, arg)
+		}
+	}
+
+	pushArgs := append([]string{"push"}, gitArgs...)
+	if !useAIFor("push", true, forceAI, noAI) || !isGitRepository() {
+		executeGitCommand(pushArgs)
+		return nil
+	}
+
+	remote, targets := parsePushArgs(gitArgs)
+	if len(targets) == 0 {
+		// --all, --mirror, --tags and similar: nothing specific to summarize
+		executeGitCommand(pushArgs)
+		return nil
+	}
+
+	var risks []string
+	var commitLogs, diffStats []string
+	totalCommits := 0
+
+	for _, target := range targets {
+		destination := remote + "/" + target.remoteBranch
+
+		if target.delete {
+			if isProtectedBranch(target.remoteBranch) {
+				risks = append(risks, fmt.Sprintf("Deleting protected branch %s", destination))
+			}
+			continue
+		}
+		if target.force && isProtectedBranch(target.remoteBranch) {
+			risks = append(risks, fmt.Sprintf("Force-push to protected branch %s", destination))
+		}
+
+		rangeArgs := pushRange(remote, target)
+
+		commits, _ := runGitOutput(append([]string{"log", "--no-merges", "--format=%h %s"}, rangeArgs...)...)
+		commits = strings.TrimSpace(commits)
+		if commits == "" {
+			continue
+		}
+
+		lines := strings.Split(commits, "\n")
+		totalCommits += len(lines)
+		commitLogs = append(commitLogs, fmt.Sprintf("%s → %s:\n%s", target.local, destination, commits))
+
+		for _, line := range lines {
+			if _, subject, ok := strings.Cut(line, " "); ok && wipCommitPattern.MatchString(subject) {
+				risks = append(risks, fmt.Sprintf("Unfinished commit: %s", line))
+			}
+		}
+
+		risks = append(risks, findLargePushedFiles(target.local, rangeArgs)...)
+
+		diff, _ := runGitOutput(append([]string{"log", "-p", "--no-merges", "--format="}, rangeArgs...)...)
+		for _, finding := range secrets.ScanDiff(diff) {
+			risks = append(risks, fmt.Sprintf("Potential secret (%s) in %s:%d: %s", finding.Rule, finding.File, finding.Line, finding.MaskedText()))
+		}
+
+		if stat, err := runGitOutput(append([]string{"log", "--no-merges", "--format=", "--stat"}, rangeArgs...)...); err == nil {
+			diffStats = append(diffStats, strings.TrimSpace(stat))
+		}
+	}
+
+	if totalCommits == 0 && len(risks) == 0 {
+		executeGitCommand(pushArgs)
+		return nil
+	}
+
+	if totalCommits > 0 {
+		fmt.Printf("📦 %d commit(s) to push:\n%s\n", totalCommits, strings.Join(commitLogs, "\n"))
+	}
+
+	if len(risks) > 0 {
+		fmt.Println("\n🚨 Safety check:")
+		for _, risk := range risks {
+			fmt.Printf("  - %s\n", risk)
+		}
+	} else {
+		fmt.Println("\n✅ Safety check passed")
+	}
+
+	if totalCommits > 0 {
+		if err := printPushSummary(cmd, remote, targets, strings.Join(commitLogs, "\n"), strings.Join(diffStats, "\n"), risks); err != nil {
+			return err
+		}
+	}
+
+	prompt := "\nPush? (y/n): "
+	if len(risks) > 0 {
+		prompt = "\n⚠️  Push anyway? (y/n): "
+	}
+	if !confirm(prompt) {
+		fmt.Println("Push cancelled")
+		return nil
+	}
+
+	executeGitCommand(pushArgs)
+	return nil
+}
+
+// printPushSummary prints the AI summary of the outgoing commits. Failures only warn,
+// since the summary is not required to push; the only error is solar.ErrDryRun.
+func printPushSummary(cmd *cobra.Command, remote string, targets []pushTarget, commits, diffStat string, risks []string) error {
+	if !aiConfigured() {
+		fmt.Println("\n💡 Run 'sgit config init' to get an AI summary of your pushes")
+		return nil
+	}
+
+	client, err := newSolarClient()
+	if err != nil {
+		fmt.Printf("Warning: Could not get AI summary: %v\n", err)
+		return nil
+	}
+
+	var destinations []string
+	for _, target := range targets {
+		destinations = append(destinations, remote+"/"+target.remoteBranch)
+	}
+
+	statusln("\nSummarizing push with Solar LLM...")
+	summary, err := client.SummarizePush(cmd.Context(), strings.Join(destinations, ", "), commits, diffStat, strings.Join(risks, "\n"))
+	if isDryRun(err) {
+		return err
+	}
+	if err != nil {
+		fmt.Printf("Warning: Could not get AI summary: %v\n", err)
+		return nil
+	}
+
+	fmt.Println("\n=== PUSH SUMMARY ===")
+	fmt.Println(summary)
+	return nil
+}
+
+// parsePushArgs works out the remote and the refs a git push invocation will update.
+// It returns no targets for pushes that aren't tied to specific refs (--all, --mirror, --tags).
+func parsePushArgs(args []string) (string, []pushTarget) {
+	var positional []string
+	force, deleteRefs := false, false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			positional = append(positional, args[i+1:]...)
+			i = len(args)
+		case pushValueFlags[arg]:
+			i++
+		case arg == "--all", arg == "--mirror", arg == "--tags", arg == "--branches":
+			return "", nil
+		case arg == "--force", strings.HasPrefix(arg, "--force-with-lease"), arg == "--force-if-includes":
+			force = true
+		case arg == "--delete":
+			deleteRefs = true
+		case strings.HasPrefix(arg, "--"):
+			// Other long options don't change which refs are pushed
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			if strings.Contains(arg, "f") {
+				force = true
+			}
+			if strings.Contains(arg, "d") {
+				deleteRefs = true
+			}
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	upstreamRemote, upstreamBranch := currentUpstream()
+
+	remote := upstreamRemote
+	if len(positional) > 0 {
+		remote = positional[0]
+		positional = positional[1:]
+	}
+	if remote == "" {
+		remote = "origin"
+	}
+
+	if len(positional) == 0 {
+		branch, err := getCurrentBranch()
+		if err != nil || branch == "" || branch == "HEAD" {
+			return remote, nil
+		}
+		remoteBranch := branch
+		if upstreamBranch != "" && remote == upstreamRemote {
+			remoteBranch = upstreamBranch
+		}
+		return remote, []pushTarget{{local: branch, remoteBranch: remoteBranch, force: force}}
+	}
+
+	var targets []pushTarget
+	for _, refspec := range positional {
+		target := pushTarget{force: force, delete: deleteRefs}
+		if strings.HasPrefix(refspec, "+") {
+			target.force = true
+			refspec = refspec[1:]
+		}
+
+		src, dst, hasDst := strings.Cut(refspec, ":")
+		if !hasDst {
+			dst = src
+		}
+		if src == "" {
+			target.delete = true
+		}
+		if src == "HEAD" && !hasDst {
+			if branch, err := getCurrentBranch(); err == nil {
+				dst = branch
+			}
+		}
+
+		target.local = src
+		target.remoteBranch = strings.TrimPrefix(dst, "refs/heads/")
+		if target.delete {
+			target.local = ""
+			if target.remoteBranch == "" {
+				target.remoteBranch = src
+			}
+		}
+		targets = append(targets, target)
+	}
+
+	return remote, targets
+}
+
+// currentUpstream returns the remote and branch the current branch tracks, if any
+func currentUpstream() (string, string) {
+	branch, err := getCurrentBranch()
+	if err != nil || branch == "" {
+		return "", ""
+	}
+
+	remote, err := runGitOutput("config", "--get", "branch."+branch+".remote")
+	if err != nil {
+		return "", ""
+	}
+	merge, err := runGitOutput("config", "--get", "branch."+branch+".merge")
+	if err != nil {
+		return strings.TrimSpace(remote), ""
+	}
+	return strings.TrimSpace(remote), strings.TrimPrefix(strings.TrimSpace(merge), "refs/heads/")
+}
+
+// pushRange returns the git log arguments selecting the commits a push would send
+func pushRange(remote string, target pushTarget) []string {
+	remoteRef := "refs/remotes/" + remote + "/" + target.remoteBranch
+	if _, err := runGitOutput("rev-parse", "--verify", "--quiet", remoteRef); err == nil {
+		return []string{remoteRef + ".." + target.local}
+	}
+	// New remote branch: only commits that no remote-tracking branch has yet
+	return []string{target.local, "--not", "--remotes=" + remote}
+}
+
+// isProtectedBranch checks a branch name against protected_branches (glob patterns)
+func isProtectedBranch(branch string) bool {
+	patterns := viper.GetStringSlice("protected_branches")
+	if len(patterns) == 0 {
+		patterns = defaultProtectedBranches
+	}
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, branch); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// findLargePushedFiles flags files added or modified in the range that exceed the size limit
+func findLargePushedFiles(local string, rangeArgs []string) []string {
+	limitMB := defaultMaxPushFileSizeMB
+	if viper.IsSet("push_max_file_size_mb") {
+		limitMB = viper.GetInt("push_max_file_size_mb")
+	}
+	limit := int64(limitMB) * 1024 * 1024
+
+	output, err := runGitOutput(append([]string{"log", "--no-merges", "--format=", "--name-only", "--diff-filter=AM"}, rangeArgs...)...)
+	if err != nil {
+		return nil
+	}
+
+	var risks []string
+	seen := make(map[string]bool)
+	for _, file := range strings.Split(strings.TrimSpace(output), "\n") {
+		if file == "" || seen[file] {
+			continue
+		}
+		seen[file] = true
+
+		sizeOutput, err := runGitOutput("cat-file", "-s", local+":"+file)
+		if err != nil {
+			continue // removed again later in the range
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(sizeOutput), 10, 64)
+		if err == nil && size > limit {
+			risks = append(risks, fmt.Sprintf("Large file %s (%.1f MB)", file, float64(size)/(1024*1024)))
+		}
+	}
+	return risks
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// rebaseCmd wraps git rebase, adding AI conflict assistance with --ai-help
+var rebaseCmd = &cobra.Command{
+	Use:   "rebase [--ai-help] [git rebase options]",
+	Short: "Reapply commits on top of another base with optional AI conflict help",
+	Long: `Passthrough to git rebase. With --ai-help, sgit stops at each conflicting step,
+sends the conflicting hunks to Solar LLM, and proposes a resolution for each hunk
+that you can apply or skip. Once every conflict is resolved, the rebase continues.
+
+Examples:
+  sgit rebase --ai-help main
+  sgit rebase --ai-help --continue`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runRebase(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+	DisableFlagParsing: true,
+}
+
+func init() {
+	rootCmd.AddCommand(rebaseCmd)
+}
+
+func runRebase(cmd *cobra.Command, args []string) error {
+	// Flag parsing is disabled so every git rebase option passes through; pick out ours
+	aiHelp := false
+	var gitArgs []string
+	for _, arg := range extractGlobalFlags(args) {
+		if arg == "--ai-help" {
+			aiHelp = true
+			continue
+		}
+		gitArgs = append(gitArgs, arg)
+	}
+
+	if !aiHelp || aiDisabled("rebase", true) {
+		executeGitCommand(append([]string{"rebase"}, gitArgs...))
+		return nil
+	}
+
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+
+	rebaseErr := runGitRebaseStep(false, gitArgs...)
+
+	for rebaseErr != nil {
+		if !isRebaseInProgress() {
+			return fmt.Errorf("rebase failed: %v", rebaseErr)
+		}
+
+		conflictFiles, err := getMergeConflicts()
+		if err != nil || len(conflictFiles) == 0 {
+			// Stopped for another reason (e.g. an edit step), leave it to the user
+			return nil
+		}
+
+		step := describeRebaseStep()
+		fmt.Printf("\n🚨 Conflicts while applying %s\n", step)
+
+		operationContext := fmt.Sprintf(`This conflict happened during a rebase while replaying the commit "%s".
+"Ours" is the branch being rebased onto (upstream); "theirs" is the change from the commit being replayed.`, step)
+
+		resolved, err := assistConflicts(cmd.Context(), conflictFiles, operationContext)
+		if err != nil {
+			fmt.Printf("Warning: Could not get AI assistance: %v\n", err)
+		}
+		if !resolved {
+			printRebaseInstructions()
+			return nil
+		}
+
+		if !confirm("\nAll conflicts resolved. Continue the rebase? (y/n): ") {
+			printRebaseInstructions()
+			return nil
+		}
+
+		rebaseErr = runGitRebaseStep(true, "--continue")
+	}
+
+	fmt.Println("✅ Rebase complete")
+	return nil
+}
+
+// runGitRebaseStep runs git rebase. With keepMessages, the replayed commit's message is
+// kept as-is instead of opening an editor after a conflict.
+func runGitRebaseStep(keepMessages bool, args ...string) error {
+	gitCmd := exec.Command("git", append([]string{"rebase"}, args...)...)
+	if keepMessages {
+		gitCmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	}
+	gitCmd.Stdin = os.Stdin
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	return gitCmd.Run()
+}
+
+// isRebaseInProgress checks for the rebase state directories git leaves while stopped
+func isRebaseInProgress() bool {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		path, err := runGitOutput("rev-parse", "--git-path", dir)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(strings.TrimSpace(path)); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// rebaseMessageDir creates a directory under the git dir for the message files that
+// the exec lines of a generated rebase todo read. Unlike a temp dir it survives a
+// rebase that stops midway, so 'git rebase --continue' still finds the messages; the
+// returned cleanup removes it only once no rebase is in progress. Directories left by
+// earlier stopped rebases are removed first.
+func rebaseMessageDir(prefix string) (string, func(), error) {
+	output, err := runGitOutput("rev-parse", "--git-path", "sgit-rebase")
+	if err != nil {
+		return "", nil, fmt.Errorf("not a git repository")
+	}
+	base, err := filepath.Abs(strings.TrimSpace(output))
+	if err != nil {
+		return "", nil, err
+	}
+	if !isRebaseInProgress() {
+		os.RemoveAll(base)
+	}
+	if err := os.MkdirAll(base, 0700); err != nil {
+		return "", nil, fmt.Errorf("failed to create message dir: %w", err)
+	}
+	dir, err := os.MkdirTemp(base, prefix)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create message dir: %w", err)
+	}
+	cleanup := func() {
+		if !isRebaseInProgress() {
+			os.RemoveAll(dir)
+		}
+	}
+	return dir, cleanup, nil
+}
+
+// describeRebaseStep returns "<short sha> <subject>" of the commit being replayed
+func describeRebaseStep() string {
+	output, err := runGitOutput("log", "-1", "--format=%h %s", "REBASE_HEAD")
+	if err != nil || strings.TrimSpace(output) == "" {
+		return "the current commit"
+	}
+	return strings.TrimSpace(output)
+}
+
+func printRebaseInstructions() {
+	fmt.Println("\nPlease resolve the remaining conflicts and then:")
+	fmt.Println("  git add <resolved-files>")
+	fmt.Println("  sgit rebase --ai-help --continue")
+	fmt.Println("Or abort with: sgit rebase --abort")
+}
@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/i18n"
+	"github.com/hunkim/sgit/pkg/progress"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// defaultReflogCount is how many reflog entries --ai explains by default
+	defaultReflogCount = 30
+	// maxLostTips is how many separate lines of lost commits are offered for recovery
+	maxLostTips = 5
+)
+
+// reflogCmd wraps git reflog, adding a plain-language explanation and recovery help
+// with --ai
+var reflogCmd = &cobra.Command{
+	Use:   "reflog [--ai] [git reflog options]",
+	Short: "Show the reflog, or with --ai explain it and recover lost work",
+	Long: `Passthrough to git reflog. With --ai, sgit reads the recent reflog entries and
+finds the commits that are no longer on any branch or tag, e.g. after a bad reset,
+rebase, amend or branch deletion. Solar LLM explains what happened in plain language
+("you reset main back 3 commits at 14:02; they added the login form") and which
+recovery option brings the work back. Pick an option by number to run it; nothing
+runs without your choice.
+
+With --ai, sgit reflog takes a ref (HEAD by default) and -n <count> (30 by default).
+Set reflog_ai: true in config to explain the reflog without --ai.
+
+Examples:
+  sgit reflog --ai
+  sgit reflog --ai -n 50
+  sgit reflog --ai main
+  sgit reflog show --date=relative`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runReflog(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+	DisableFlagParsing: true,
+}
+
+func init() {
+	rootCmd.AddCommand(reflogCmd)
+}
+
+// recoveryOption is one way to bring back commits that only the reflog points to
+type recoveryOption struct {
+	args        []string
+	description string
+}
+
+func (o recoveryOption) command() string {
+	return "git " + strings.Join(o.args, " ")
+}
+
+func runReflog(cmd *cobra.Command, args []string) error {
+	// Flag parsing is disabled so every git reflog option passes through; pick out ours
+	ai, noAI := false, false
+	var gitArgs []string
+	for _, arg := range extractGlobalFlags(args) {
+		switch arg {
+		case "--ai":
+			ai = true
+		case "--no-ai":
+			noAI = true
+		default:
+			gitArgs = append(gitArgs, arg)
+		}
+	}
+
+	if !useAIFor("reflog", false, ai, noAI) {
+		executeGitCommand(append([]string{"reflog"}, gitArgs...))
+		return nil
+	}
+
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	ref, count, err := parseReflogArgs(gitArgs)
+	if err != nil {
+		return err
+	}
+	entries, err := readReflog(ref, count)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("the reflog of %s is empty", ref)
+	}
+
+	gathering := progress.Begin(i18n.T("Looking for lost commits"))
+	tips := lostTips(entries)
+	lostWork := describeLostWork(tips)
+	gathering.End()
+
+	var options []recoveryOption
+	branch, _ := getCurrentBranch()
+	for _, tip := range tips {
+		options = append(options, recoveryOption{
+			[]string{"branch", "recovered-" + tip.sha, tip.sha},
+			fmt.Sprintf("keep the lost commits up to %s on a new branch; nothing else changes", tip.sha),
+		})
+		if branch != "" {
+			options = append(options, recoveryOption{
+				[]string{"reset", "--keep", tip.sha},
+				fmt.Sprintf("move %s back to %s (%s), e.g. to undo a reset", branch, tip.sha, tip.selector),
+			})
+		}
+	}
+	var listing strings.Builder
+	for i, option := range options {
+		fmt.Fprintf(&listing, "%d. %s: %s\n", i+1, option.command(), option.description)
+	}
+	if len(options) == 0 {
+		listing.WriteString("None needed: no commits are lost.\n")
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	var reflog strings.Builder
+	for _, entry := range entries {
+		fmt.Fprintf(&reflog, "%s %s %s %s\n", entry.selector, entry.when, entry.sha, entry.subject)
+	}
+	printContentStats("Reflog", reflog.String(), lostWork)
+	printer := newStreamPrinter("")
+	_, err = client.ExplainReflogStream(cmd.Context(), reflog.String(), lostWork, describeUndoState(), listing.String(), printer.Write)
+	printer.Done()
+	if err != nil {
+		return fmt.Errorf("error explaining the reflog: %w", err)
+	}
+	fmt.Println() // Add newline after streaming output
+
+	if len(options) == 0 {
+		fmt.Println("✅ No commits are lost: everything in the reflog is still on a branch or tag")
+		return nil
+	}
+
+	fmt.Println("\nRecovery options:")
+	for i, option := range options {
+		fmt.Printf("  %d. %s\n     %s\n", i+1, option.command(), option.description)
+	}
+	fmt.Println()
+
+	answer := strings.ToLower(ask(fmt.Sprintf("Run which option? (1-%d, Enter = none): ", len(options))))
+	if answer == "" || answer == "n" || answer == "no" || answer == "q" {
+		fmt.Println("Nothing changed")
+		return nil
+	}
+	n, err := strconv.Atoi(answer)
+	if err != nil || n < 1 || n > len(options) {
+		return fmt.Errorf("invalid answer '%s' (use 1-%d)", answer, len(options))
+	}
+	option := options[n-1]
+
+	gitCmd := exec.Command("git", option.args...)
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	if err := gitCmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %v", option.command(), err)
+	}
+	fmt.Printf("✅ Ran %s\n", option.command())
+	return nil
+}
+
+// parseReflogArgs reads the ref and entry count sgit reflog --ai takes
+func parseReflogArgs(args []string) (string, int, error) {
+	ref, count := "HEAD", defaultReflogCount
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value := ""
+		switch {
+		case arg == "show":
+			continue
+		case arg == "-n" || arg == "--max-count":
+			if i+1 >= len(args) {
+				return "", 0, fmt.Errorf("%s needs a count", arg)
+			}
+			i++
+			value = args[i]
+		case strings.HasPrefix(arg, "--max-count="):
+			value = strings.TrimPrefix(arg, "--max-count=")
+		case strings.HasPrefix(arg, "-n"):
+			value = strings.TrimPrefix(arg, "-n")
+		case strings.HasPrefix(arg, "-"):
+			return "", 0, fmt.Errorf("with --ai, sgit reflog takes only [<ref>] [-n <count>], not %s", arg)
+		default:
+			ref = arg
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return "", 0, fmt.Errorf("invalid count '%s'", value)
+		}
+		count = n
+	}
+	return ref, count, nil
+}
+
+// readReflog reads the newest count entries of ref's reflog, with when each was made
+func readReflog(ref string, count int) ([]reflogEntry, error) {
+	// %gd with a date format gives the time of the entry (ref@{<date>}); the
+	// index selector ref@{<n>} comes from the entry's position
+	output, err := runGitOutput("reflog", "show", "-n", strconv.Itoa(count), "--date=format:%Y-%m-%d %H:%M",
+		"--format=%h%x00%gd%x00%gs", ref, "--")
+	if err != nil {
+		return nil, fmt.Errorf("error reading the reflog of %s: %w", ref, err)
+	}
+	var entries []reflogEntry
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		name, when, _ := strings.Cut(fields[1], "@{")
+		entries = append(entries, reflogEntry{
+			sha:      fields[0],
+			subject:  fields[2],
+			when:     strings.TrimSuffix(when, "}"),
+			selector: fmt.Sprintf("%s@{%d}", name, len(entries)),
+		})
+	}
+	return entries, nil
+}
+
+// lostTips returns the reflog entries whose commits are on no branch, tag or remote
+// branch and not in HEAD, newest first, leaving out those that are part of a newer
+// tip's history
+func lostTips(entries []reflogEntry) []reflogEntry {
+	var tips []reflogEntry
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if seen[entry.sha] {
+			continue
+		}
+		seen[entry.sha] = true
+		count, err := runGitOutput("rev-list", "--count", entry.sha, "--not", "--branches", "--tags", "--remotes", "HEAD")
+		if err != nil || strings.TrimSpace(count) == "0" {
+			continue
+		}
+		contained := false
+		for _, tip := range tips {
+			if _, err := runGitOutput("merge-base", "--is-ancestor", entry.sha, tip.sha); err == nil {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			tips = append(tips, entry)
+		}
+		if len(tips) == maxLostTips {
+			break
+		}
+	}
+	return tips
+}
+
+// describeLostWork lists the commits of each lost tip with the files they changed
+func describeLostWork(tips []reflogEntry) string {
+	if len(tips) == 0 {
+		return "None: every commit in the reflog is still on a branch or tag."
+	}
+	var b strings.Builder
+	for _, tip := range tips {
+		commits, _ := runGitOutput("log", "-n", "15", "--date=format:%Y-%m-%d %H:%M", "--format=%h %ad %s", "--stat=80", "--stat-count=10",
+			tip.sha, "--not", "--branches", "--tags", "--remotes", "HEAD")
+		fmt.Fprintf(&b, "Left behind at %s (%s, %s: %s):\n%s\n\n", tip.sha, tip.selector, tip.when, tip.subject, strings.TrimSpace(commits))
+	}
+	return b.String()
+}
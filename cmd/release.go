@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/github"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	releaseDraft      bool
+	releasePrerelease bool
+	releaseRegenerate bool
+	releaseRemote     string
+)
+
+// releaseCmd publishes a tag's release notes as a GitHub Release
+var releaseCmd = &cobra.Command{
+	Use:   "release [tag]",
+	Short: "Publish a GitHub Release with AI release notes",
+	Long: `Publish a GitHub Release for a tag (default: the latest tag). The notes come from
+the annotated tag message written by 'sgit tag --ai-notes', so the tag, the release
+and CHANGELOG.md stay consistent. Lightweight tags, or --regenerate, get fresh notes
+generated from the commits since the previous tag.
+
+Uses the GitHub CLI (gh) when available, otherwise the GitHub API with GITHUB_TOKEN.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runRelease(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+
+	releaseCmd.Flags().BoolVar(&releaseDraft, "draft", false, "create the release as a draft")
+	releaseCmd.Flags().BoolVar(&releasePrerelease, "prerelease", false, "mark the release as a pre-release")
+	releaseCmd.Flags().BoolVar(&releaseRegenerate, "regenerate", false, "generate new notes instead of using the tag message")
+	releaseCmd.Flags().StringVar(&releaseRemote, "remote", "origin", "remote hosting the GitHub repository")
+}
+
+func runRelease(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	var tag string
+	if len(args) > 0 {
+		tag = args[0]
+	} else {
+		latest, err := getLatestTag("HEAD")
+		if err != nil || latest == "" {
+			return fmt.Errorf("no tags found, create one with 'sgit tag vX.Y.Z --ai-notes'")
+		}
+		tag = latest
+	}
+	if _, err := runGitOutput("rev-parse", "-q", "--verify", "refs/tags/"+tag); err != nil {
+		return fmt.Errorf("tag '%s' does not exist", tag)
+	}
+
+	notes := ""
+	if !releaseRegenerate {
+		notes = tagNotes(tag)
+	}
+	if notes == "" {
+		// Check configuration and setup if needed
+		if err := ensureConfiguration(); err != nil {
+			return err
+		}
+
+		generated, err := generateChangelogEntry(cmd.Context(), releaseRange(tag, tag), tag)
+		if err != nil {
+			return err
+		}
+		notes = generated
+	}
+
+	fmt.Printf("\n=== RELEASE %s ===\n", tag)
+	fmt.Println(notes)
+	fmt.Println()
+
+	// GitHub can only publish tags it knows about
+	if remoteTag, _ := runGitOutput("ls-remote", "--tags", releaseRemote, "refs/tags/"+tag); strings.TrimSpace(remoteTag) == "" {
+		if !confirm(fmt.Sprintf("Tag %s is not on %s yet. Push it now? (y/n): ", tag, releaseRemote)) {
+			fmt.Println("Release cancelled")
+			return nil
+		}
+		pushCmd := exec.Command("git", "push", releaseRemote, "refs/tags/"+tag)
+		pushCmd.Stdout = os.Stdout
+		pushCmd.Stderr = os.Stderr
+		if err := pushCmd.Run(); err != nil {
+			return fmt.Errorf("error pushing tag: %w", err)
+		}
+	}
+
+	if !confirm("Publish this GitHub release? (y/n): ") {
+		fmt.Println("Release cancelled")
+		return nil
+	}
+
+	return createGitHubRelease(cmd, tag, notes)
+}
+
+// createGitHubRelease publishes a release with the GitHub CLI, or the REST API as a fallback
+func createGitHubRelease(cmd *cobra.Command, tag, notes string) error {
+	if _, err := exec.LookPath("gh"); err == nil {
+		notesFile, err := ioutil.TempFile(os.TempDir(), "sgit-release-*.md")
+		if err != nil {
+			return fmt.Errorf("failed to create temp file: %w", err)
+		}
+		defer os.Remove(notesFile.Name())
+
+		if _, err := notesFile.WriteString(notes); err != nil {
+			notesFile.Close()
+			return fmt.Errorf("failed to write to temp file: %w", err)
+		}
+		notesFile.Close()
+
+		ghArgs := []string{"release", "create", tag, "--verify-tag", "--title", tag, "--notes-file", notesFile.Name()}
+		if releaseDraft {
+			ghArgs = append(ghArgs, "--draft")
+		}
+		if releasePrerelease {
+			ghArgs = append(ghArgs, "--prerelease")
+		}
+
+		ghCmd := exec.Command("gh", ghArgs...)
+		ghCmd.Stdin = os.Stdin
+		ghCmd.Stdout = os.Stdout
+		ghCmd.Stderr = os.Stderr
+		return ghCmd.Run()
+	}
+
+	// Fall back to the GitHub REST API
+	token := getGitHubToken()
+	if token == "" {
+		return fmt.Errorf("GitHub CLI not found and no GitHub token configured (set GITHUB_TOKEN or github_token in config)")
+	}
+
+	remoteURL, err := runGitOutput("remote", "get-url", releaseRemote)
+	if err != nil {
+		return fmt.Errorf("error getting %s remote: %v", releaseRemote, err)
+	}
+	_, owner, repo, err := github.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return err
+	}
+
+	client := github.NewClient(token, viper.GetString("github_api_url"))
+	release, err := client.CreateRelease(cmd.Context(), owner, repo, github.NewRelease{
+		TagName:    tag,
+		Name:       tag,
+		Body:       notes,
+		Draft:      releaseDraft,
+		Prerelease: releasePrerelease,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating release: %w", err)
+	}
+
+	fmt.Printf("✅ Published release %s: %s\n", tag, release.HTMLURL)
+	return nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+)
+
+// revertCmd wraps git revert, writing the revert messages with AI from a reason
+var revertCmd = &cobra.Command{
+	Use:   "revert [--reason <text>] [--no-ai] [--ai-help] [git revert options] <commit>...",
+	Short: "Revert commits with AI-written messages that explain why",
+	Long: `Passthrough to git revert. Instead of the mechanical 'Revert "..."' message, sgit
+asks why you are reverting (or takes --reason) and Solar LLM writes a message that
+says what behavior is restored and why, keeping the "This reverts commit" line.
+Commits are reverted one at a time; with --ai-help, conflicts get per-hunk AI
+resolutions like 'sgit merge --ai-help'.
+
+--no-ai keeps git's messages. --no-commit, --abort, --skip and --quit are passed
+straight to git.
+
+Examples:
+  sgit revert a1b2c3d --reason "breaks login on Safari"
+  sgit revert --ai-help HEAD~3..HEAD
+  sgit revert --continue`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runRevert(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+	DisableFlagParsing: true,
+}
+
+func init() {
+	rootCmd.AddCommand(revertCmd)
+}
+
+func runRevert(cmd *cobra.Command, args []string) error {
+	// Flag parsing is disabled so every git revert option passes through; pick out ours
+	noAI, aiHelp := false, false
+	reason, reasonSet := "", false
+	var gitArgs []string
+	rest := extractGlobalFlags(args)
+	for i := 0; i < len(rest); i++ {
+		arg := rest[i]
+		switch {
+		case arg == "--no-ai":
+			noAI = true
+		case arg == "--ai-help":
+			aiHelp = true
+		case arg == "--reason" && i+1 < len(rest):
+			i++
+			reason, reasonSet = rest[i], true
+		case strings.HasPrefix(arg, "--reason="):
+			reason, reasonSet = strings.TrimPrefix(arg, "--reason="), true
+		default:
+			gitArgs = append(gitArgs, arg)
+		}
+	}
+
+	// The reason is asked once, before the first AI message is written
+	askedReason := reasonSet
+	op := pickOperation{
+		name:    "revert",
+		headRef: "REVERT_HEAD",
+		conflictContext: func(commit, branch string) string {
+			return fmt.Sprintf(`This conflict happened while reverting the commit "%s" on the branch '%s'.
+"Ours" is the current code; "theirs" is the code with that commit's change undone.`, commit, branch)
+		},
+		message: func(ctx context.Context, client *solar.Client, sha, diff string) (string, error) {
+			if !askedReason {
+				askedReason = true
+				reason = ask(fmt.Sprintf("Why are you reverting %s? (Enter to skip): ", describeCommit(sha)))
+			}
+			reverted, err := runGitOutput("log", "-1", "--format=%H%n%n%B", sha)
+			if err != nil {
+				return "", err
+			}
+			return client.GenerateRevertMessage(ctx, strings.TrimSpace(reverted), reason, diff)
+		},
+	}
+
+	// revert_ai in config can turn the message rewrite off by default, or all AI help off
+	rewrite := useAIFor("revert", true, false, noAI)
+	aiHelp = aiHelp && !aiDisabled("revert", true)
+	return runPickCommits(cmd, op, gitArgs, rewrite, aiHelp)
+}
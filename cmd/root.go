@@ -1,19 +1,35 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/hunkim/sgit/pkg/git"
+	"github.com/hunkim/sgit/pkg/i18n"
+	"github.com/hunkim/sgit/pkg/logging"
+	"github.com/hunkim/sgit/pkg/progress"
+	"github.com/hunkim/sgit/pkg/solar"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 var cfgFile string
 var langFlag string
-var version = "dev" // Will be set during build with -ldflags
+var noRetry bool
+var noCache bool
+var assumeYes bool
+var quiet bool
+var showPrompt bool
+var activeCommand string // top-level command being run, used for per-command AI settings
+var version = "dev"      // Will be set during build with -ldflags
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -24,12 +40,38 @@ commit messages based on your code changes.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	Version:       version, // Will be set during build
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		activeCommand = topLevelCommandName(cmd)
+		// migrate-config shows and makes the upgrade itself
+		if activeCommand != migrateConfigCmd.Name() {
+			upgradeConfigFile()
+		}
+		// Commands that pass their flags through to git leave --profile in args
+		if cmd.DisableFlagParsing {
+			profileFlag = profileArg(args)
+		}
+		applyConfigProfile()
+		progress.SetEnabled(!quiet)
+		setupLogging()
+		i18n.SetLanguage(uiLanguage())
+		logging.Info("command", "name", cmd.CommandPath(), "version", version)
+	},
+}
+
+// topLevelCommandName returns the name of the command directly under sgit (e.g.
+// "branch" for "sgit branch new")
+func topLevelCommandName(cmd *cobra.Command) string {
+	for cmd.HasParent() && cmd.Parent().HasParent() {
+		cmd = cmd.Parent()
+	}
+	return cmd.Name()
 }
 
 // executeGitPassthrough passes commands directly to git
 func executeGitPassthrough(args []string) error {
 	gitArgs := append([]string{}, args...)
 
+	logging.Debug("git command", "args", gitArgs)
 	gitCmd := exec.Command("git", gitArgs...)
 	gitCmd.Stdin = os.Stdin
 	gitCmd.Stdout = os.Stdout
@@ -45,9 +87,46 @@ func executeGitPassthrough(args []string) error {
 	return nil
 }
 
+// newSignalContext returns a context that is cancelled on Ctrl-C or SIGTERM.
+// In-flight AI requests observe the cancellation and the command returns, running its
+// cleanup, and exits with 130. A command blocked elsewhere (e.g. waiting on stdin)
+// is ended by a second Ctrl-C.
+func newSignalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+			select {
+			case <-sigChan:
+			case <-time.After(2 * time.Second):
+				fmt.Fprintln(os.Stderr, "\n"+i18n.T("Still stopping; press Ctrl-C again to quit now"))
+				<-sigChan
+			}
+			fmt.Fprintln(os.Stderr, "\n"+i18n.T("Interrupted"))
+			os.Exit(130)
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(sigChan)
+		cancel()
+	}
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, cancel := newSignalContext()
+	defer cancel()
+
+	start := time.Now()
+	err := rootCmd.ExecuteContext(ctx)
+	logging.Info("command done", "duration", time.Since(start), "error", err)
 
 	// If it's an unknown command error, try to pass it through to git
 	if err != nil && strings.Contains(err.Error(), "unknown command") {
@@ -72,28 +151,27 @@ func Execute() {
 
 	// Handle other errors
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		exitWithError(err)
 	}
 }
 
-// getEffectiveLanguage returns the language to use, considering both config and flag
+// getEffectiveLanguage returns the language to use, considering both config and flag.
+// Any BCP-47 code (pt-BR) or language name (Vietnamese) is accepted.
 func getEffectiveLanguage() string {
 	// Command-line flag takes precedence
 	if langFlag != "" {
-		lang := strings.ToLower(strings.TrimSpace(langFlag))
-		if isValidLanguageCode(lang) {
+		lang, err := solar.NormalizeLanguage(langFlag)
+		if err == nil {
 			return lang
 		}
-		fmt.Fprintf(os.Stderr, "Warning: Invalid language code '%s'. Using default 'en'.\n", langFlag)
+		fmt.Fprintf(os.Stderr, "Warning: %v. Using default 'en'.\n", err)
 		return "en"
 	}
 
 	// Fall back to config file setting
 	configLang := viper.GetString("language")
 	if configLang != "" {
-		lang := strings.ToLower(strings.TrimSpace(configLang))
-		if isValidLanguageCode(lang) {
+		if lang, err := solar.NormalizeLanguage(configLang); err == nil {
 			return lang
 		}
 		return "en"
@@ -103,18 +181,15 @@ func getEffectiveLanguage() string {
 	return "en"
 }
 
-// isValidLanguageCode checks if the provided language code is supported
-func isValidLanguageCode(code string) bool {
-	validCodes := map[string]bool{
-		"en": true,
-		"ko": true,
-		"ja": true,
-		"zh": true,
-		"es": true,
-		"fr": true,
-		"de": true,
+// uiLanguage returns the language of sgit's own messages: ui_language from config, or
+// the language of the AI responses. Messages without a translation stay in English.
+func uiLanguage() string {
+	if configured := viper.GetString("ui_language"); configured != "" {
+		if lang, err := solar.NormalizeLanguage(configured); err == nil {
+			return lang
+		}
 	}
-	return validCodes[code]
+	return getEffectiveLanguage()
 }
 
 func init() {
@@ -122,9 +197,103 @@ func init() {
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/sgit/config.yaml)")
-	rootCmd.PersistentFlags().StringVar(&langFlag, "lang", "", "language for AI responses (en|ko|ja|zh|es|fr|de, overrides config setting)")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "config profile to use, e.g. work or personal (overrides SGIT_PROFILE, profile paths and the profile setting)")
+	rootCmd.PersistentFlags().StringVar(&langFlag, "lang", "", "language for AI responses and sgit's messages (BCP-47 code like ko or pt-BR, or a language name; overrides config setting)")
+	rootCmd.RegisterFlagCompletionFunc("lang", completeLanguages)
+	rootCmd.PersistentFlags().BoolVar(&noRetry, "no-retry", false, "don't retry failed AI API requests")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "don't use cached AI responses")
+	rootCmd.PersistentFlags().BoolVar(&assumeYes, "yes", false, "accept AI messages and answer yes to confirmations (for scripts and CI)")
+	rootCmd.PersistentFlags().BoolVar(&quiet, "quiet", false, "suppress spinners, progress bars and status messages")
+	rootCmd.PersistentFlags().BoolVar(&showPrompt, "show-prompt", false, "print the prompt that would be sent to the AI and stop, without calling the API")
+	rootCmd.PersistentFlags().BoolVar(&showPrompt, "dry-run-ai", false, "same as --show-prompt")
+	rootCmd.PersistentFlags().BoolVar(&verboseLog, "verbose", false, "log API requests, timings and token counts to stderr (or log_file)")
+	rootCmd.PersistentFlags().BoolVar(&debugLog, "debug", false, "also log git commands and truncation decisions (same as SGIT_DEBUG=1)")
+}
+
+// isGlobalFlag reports whether flag is one of sgit's global flags, which must not be
+// passed through to git. A command's own flag of the same name, such as commit's
+// -v/--verbose, shadows the global one and is git's.
+func isGlobalFlag(flag *pflag.Flag) bool {
+	return rootCmd.PersistentFlags().Lookup(flag.Name) == flag
 }
 
+// extractGlobalFlags applies sgit's global flags found in args and returns the rest.
+// Commands that disable flag parsing to pass options through to git use it so
+// --config, --profile, --lang, --no-retry, --no-cache, --yes, --quiet and --debug keep
+// working.
+func extractGlobalFlags(args []string) []string {
+	var rest []string
+	configChanged := false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+
+		switch name {
+		case "--config", "--profile", "--lang":
+			if !hasValue {
+				if i+1 >= len(args) {
+					rest = append(rest, arg)
+					continue
+				}
+				i++
+				value = args[i]
+			}
+			switch name {
+			case "--config":
+				cfgFile = value
+				configChanged = true
+			case "--profile":
+				// Already applied before the command ran
+				profileFlag = value
+			default:
+				langFlag = value
+			}
+		case "--no-retry":
+			noRetry = true
+		case "--no-cache":
+			noCache = true
+		case "--yes":
+			assumeYes = true
+		case "--quiet":
+			quiet = true
+			progress.SetEnabled(false)
+		case "--debug":
+			// --verbose is left for git, where most commands have their own
+			debugLog = true
+		case "--":
+			// Everything after -- belongs to git
+			return append(rest, args[i:]...)
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	if configChanged {
+		initConfig()
+		applyConfigProfile()
+	}
+	setupLogging()
+	i18n.SetLanguage(uiLanguage())
+	return rest
+}
+
+// envAliases are environment variables read for a setting besides SGIT_<SETTING>,
+// in order of precedence
+var envAliases = map[string][]string{
+	apiKeySetting:        {"SGIT_API_KEY", "UPSTAGE_API_KEY"},
+	"upstage_model_name": {"SGIT_MODEL", "UPSTAGE_MODEL_NAME"},
+	"language":           {"SGIT_LANGUAGE", "SGIT_LANG"},
+	"base_url":           {"SGIT_BASE_URL"},
+	"provider":           {"SGIT_PROVIDER"},
+	"azure.endpoint":     {"SGIT_AZURE_ENDPOINT", "AZURE_OPENAI_ENDPOINT"},
+	"azure.api_version":  {"SGIT_AZURE_API_VERSION", "AZURE_OPENAI_API_VERSION"},
+	"azure.ad_token":     {"SGIT_AZURE_AD_TOKEN", "AZURE_OPENAI_AD_TOKEN"},
+}
+
+// envKeyReplacer maps a nested setting to its environment variable name
+var envKeyReplacer = strings.NewReplacer(".", "_")
+
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
 	if cfgFile != "" {
@@ -150,10 +319,26 @@ func initConfig() {
 		}
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	// Every setting can be overridden with SGIT_<SETTING> (e.g. SGIT_CONVENTION, or
+	// SGIT_MESSAGE_FORMAT_SUBJECT_MAX_LENGTH for message_format.subject_max_length), and
+	// the common ones have short names; the environment wins over the config file
+	viper.SetEnvPrefix("sgit")
+	viper.SetEnvKeyReplacer(envKeyReplacer)
+	viper.AutomaticEnv()
+	for key, names := range envAliases {
+		viper.BindEnv(append([]string{key}, names...)...)
+	}
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
 		// Config file loaded successfully
 	}
+
+	// Read diffs, logs, status and blame through the configured backend (git_backend)
+	repo, err := git.New(viper.GetString("git_backend"), "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v, using the git CLI\n", err)
+		repo = git.NewCLI("")
+	}
+	gitRepo = repo
 }
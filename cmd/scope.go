@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// scopeDir maps a monorepo subproject directory to its conventional commit scope,
+// from the scope_dirs config list:
+//
+//	scope_dirs:
+//	  - path: services/api
+//	    scope: api
+//	  - path: web
+//	    scope: web
+type scopeDir struct {
+	Path  string `mapstructure:"path"`
+	Scope string `mapstructure:"scope"`
+}
+
+// contains reports whether a repository path is inside the directory
+func (d scopeDir) contains(file string) bool {
+	return d.Path == "" || file == d.Path || strings.HasPrefix(file, d.Path+"/")
+}
+
+func loadScopeDirs() ([]scopeDir, error) {
+	var dirs []scopeDir
+	if err := viper.UnmarshalKey("scope_dirs", &dirs); err != nil {
+		return nil, fmt.Errorf("invalid scope_dirs in config: %w", err)
+	}
+	for i := range dirs {
+		dirs[i].Path = cleanScopePath(dirs[i].Path)
+	}
+	return dirs, nil
+}
+
+// cleanScopePath turns a directory given by the user into a clean repository path
+func cleanScopePath(dir string) string {
+	return strings.TrimPrefix(path.Clean("/"+strings.TrimSpace(strings.ReplaceAll(dir, "\\", "/"))), "/")
+}
+
+// resolveScopeDir finds the subproject a commit belongs to: dir (--scope-dir, relative
+// to the current directory) when given, otherwise the deepest scope_dirs entry holding
+// every staged file. It returns the directory and its mapped scope, or "" for either
+// when there is none.
+func resolveScopeDir(ctx context.Context, dir string) (string, string, error) {
+	dirs, err := loadScopeDirs()
+	if err != nil {
+		return "", "", err
+	}
+
+	if dir != "" {
+		prefix, _ := runGitOutput("rev-parse", "--show-prefix")
+		dir = cleanScopePath(path.Join(strings.TrimSpace(prefix), filepath.ToSlash(dir)))
+		for _, mapped := range dirs {
+			if mapped.Path == dir {
+				return dir, mapped.Scope, nil
+			}
+		}
+		return dir, "", nil
+	}
+
+	files, err := gitRepo.StagedFiles(ctx)
+	if err != nil || len(files) == 0 || len(dirs) == 0 {
+		return "", "", nil
+	}
+	var best *scopeDir
+	for i, mapped := range dirs {
+		holdsAll := true
+		for _, file := range files {
+			if !mapped.contains(file.Path) {
+				holdsAll = false
+				break
+			}
+		}
+		if holdsAll && (best == nil || len(mapped.Path) > len(best.Path)) {
+			best = &dirs[i]
+		}
+	}
+	if best == nil {
+		return "", "", nil
+	}
+	return best.Path, best.Scope, nil
+}
+
+// getScopedRecentCommits is getRecentCommits limited to the commits touching dir
+func getScopedRecentCommits(dir string, count int) (string, error) {
+	output, err := runGitOutput("log", "-"+strconv.Itoa(count), "--oneline", "--no-merges", "--", dir)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// describeScopeDir puts the subproject at the top of the staged file list, so the
+// message is written for that part of the monorepo
+func describeScopeDir(dir, fileList string) string {
+	return fmt.Sprintf("Monorepo subproject: %s (describe the change from this subproject's point of view)\n%s", dir, fileList)
+}
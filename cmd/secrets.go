@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/actions"
+	"github.com/hunkim/sgit/pkg/secrets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	secretsNoAI              bool
+	secretsGitHubAnnotations bool
+)
+
+// secretsCmd groups the secret scanning subcommands
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Scan changes for leaked secrets",
+	Long: `Scan staged changes or files for API keys, tokens, private keys, and passwords.
+Uses regex heuristics first and then asks Solar LLM to filter out false positives.`,
+}
+
+// secretsScanCmd scans staged changes or specific files for secrets
+var secretsScanCmd = &cobra.Command{
+	Use:   "scan [files...]",
+	Short: "Scan staged changes (or the given files) for secrets",
+	Long: `Scan staged hunks for potential secrets. When files are given, their full content
+is scanned instead. Exits with a non-zero status when secrets are found.
+
+--github-annotations also reports the findings as GitHub Actions error annotations on
+their lines, with the secrets masked, and writes them to the job summary.
+
+Examples:
+  sgit secrets scan
+  sgit secrets scan config/*.yaml --no-ai
+  sgit secrets scan $(git diff --name-only origin/main...) --github-annotations`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSecretsScan(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsScanCmd)
+
+	secretsScanCmd.Flags().BoolVar(&secretsNoAI, "no-ai", false, "use regex heuristics only, skip the AI judgment pass")
+	secretsScanCmd.Flags().BoolVar(&secretsGitHubAnnotations, "github-annotations", false, "report the findings as GitHub Actions annotations and job summary")
+}
+
+func runSecretsScan(cmd *cobra.Command, args []string) error {
+	var findings []secrets.Finding
+
+	if len(args) > 0 {
+		for _, file := range args {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("error reading %s: %v", file, err)
+			}
+			findings = append(findings, secrets.ScanText(file, string(content))...)
+		}
+	} else {
+		if !isGitRepository() {
+			return fmt.Errorf("not a git repository")
+		}
+		diff, err := getGitDiff()
+		if err != nil {
+			return fmt.Errorf("error getting git diff: %w", err)
+		}
+		if strings.TrimSpace(diff) == "" {
+			fmt.Println("No staged changes to scan")
+			return nil
+		}
+		findings = secrets.ScanDiff(diff)
+	}
+
+	if len(findings) > 0 && useAIFor("secrets", true, false, secretsNoAI) {
+		if err := ensureConfiguration(); err != nil {
+			return err
+		}
+		var err error
+		if findings, err = filterFindingsWithAI(cmd.Context(), findings); err != nil {
+			return err
+		}
+	}
+
+	if secretsGitHubAnnotations {
+		// Annotations need paths from the top of the repository; the diff's already are
+		prefix := ""
+		if len(args) > 0 && isGitRepository() {
+			output, _ := runGitOutput("rev-parse", "--show-prefix")
+			prefix = strings.TrimSpace(output)
+		}
+		writeGitHubResults(secretAnnotations(findings, prefix), secretsSummary(findings, prefix))
+	}
+
+	if len(findings) == 0 {
+		fmt.Println("✅ No secrets found")
+		return nil
+	}
+
+	printSecretFindings(findings)
+	return fmt.Errorf("%d potential secret(s) found", len(findings))
+}
+
+// secretAnnotations reports the findings as error annotations on their lines. prefix
+// is the current directory's path in the repository, for files given as arguments.
+func secretAnnotations(findings []secrets.Finding, prefix string) []actions.Annotation {
+	annotations := make([]actions.Annotation, len(findings))
+	for i, f := range findings {
+		annotations[i] = actions.Annotation{
+			Level:   actions.LevelError,
+			File:    repoPath(prefix, f.File),
+			Line:    f.Line,
+			Title:   fmt.Sprintf("Potential secret (%s)", f.Rule),
+			Message: f.MaskedText(),
+		}
+	}
+	return annotations
+}
+
+// secretsSummary is the job summary of a scan: a table of the findings, masked
+func secretsSummary(findings []secrets.Finding, prefix string) string {
+	if len(findings) == 0 {
+		return "### sgit secrets scan\n\n✅ No secrets found\n"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "### sgit secrets scan\n\n🚨 %d potential secret(s) found\n\n", len(findings))
+	b.WriteString("| File | Line | Rule | Text |\n|---|---|---|---|\n")
+	for _, f := range findings {
+		fmt.Fprintf(&b, "| %s | %d | %s | `%s` |\n", actions.TableCell(repoPath(prefix, f.File)), f.Line,
+			f.Rule, actions.TableCell(strings.ReplaceAll(f.MaskedText(), "`", "'")))
+	}
+	return b.String()
+}
+
+// repoPath joins a path relative to the directory at prefix in the repository into
+// one relative to the top of the repository
+func repoPath(prefix, file string) string {
+	if filepath.IsAbs(file) {
+		return filepath.ToSlash(file)
+	}
+	return path.Clean(prefix + filepath.ToSlash(file))
+}
+
+// checkStagedSecrets is the pre-commit gate used by sgit commit.
+// It returns an error when staged changes contain likely secrets.
+func checkStagedSecrets(ctx context.Context, useAI bool) error {
+	diff, err := getGitDiff()
+	if err != nil {
+		return fmt.Errorf("error getting git diff: %w", err)
+	}
+
+	findings := secrets.ScanDiff(diff)
+	if len(findings) == 0 {
+		return nil
+	}
+
+	if useAI {
+		findings, err = filterFindingsWithAI(ctx, findings)
+		if err != nil {
+			return err
+		}
+		if len(findings) == 0 {
+			return nil
+		}
+	}
+
+	printSecretFindings(findings)
+	fmt.Println("Remove the secrets from your staged changes, or re-run with --allow-secrets to commit anyway.")
+	return fmt.Errorf("commit blocked: %d potential secret(s) in staged changes", len(findings))
+}
+
+// filterFindingsWithAI drops findings the model judges to be false positives.
+// If the model can't be reached, all findings are kept to stay on the safe side; the
+// only error is solar.ErrDryRun.
+func filterFindingsWithAI(ctx context.Context, findings []secrets.Finding) ([]secrets.Finding, error) {
+	client, err := newSolarClient()
+	if err != nil {
+		fmt.Printf("Warning: AI verification unavailable, keeping all findings: %v\n", err)
+		return findings, nil
+	}
+
+	var list strings.Builder
+	for i, f := range findings {
+		fmt.Fprintf(&list, "%d. %s:%d [%s] %s\n", i+1, f.File, f.Line, f.Rule, f.MaskedText())
+	}
+
+	fmt.Printf("🔍 Found %d potential secret(s). Asking Solar LLM to verify...\n", len(findings))
+	response, err := client.JudgeSecretFindings(ctx, list.String())
+	if isDryRun(err) {
+		return nil, err
+	}
+	if err != nil {
+		fmt.Printf("Warning: AI verification failed, keeping all findings: %v\n", err)
+		return findings, nil
+	}
+
+	safe := parseSecretJudgments(response)
+
+	var confirmed []secrets.Finding
+	for i, f := range findings {
+		if reason, ok := safe[i+1]; ok {
+			fmt.Printf("  ↪ Ignoring %s:%d (%s)\n", f.File, f.Line, reason)
+			continue
+		}
+		confirmed = append(confirmed, f)
+	}
+
+	return confirmed, nil
+}
+
+// parseSecretJudgments returns the finding numbers the model marked as SAFE, with the reason
+func parseSecretJudgments(response string) map[int]string {
+	safe := make(map[int]string)
+	for _, line := range strings.Split(response, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		num, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil {
+			continue
+		}
+		verdict := strings.TrimSpace(parts[1])
+		if strings.HasPrefix(strings.ToUpper(verdict), "SAFE") {
+			reason := strings.TrimSpace(strings.TrimLeft(verdict[len("SAFE"):], " -"))
+			safe[num] = reason
+		}
+	}
+	return safe
+}
+
+func printSecretFindings(findings []secrets.Finding) {
+	fmt.Printf("\n🚨 Potential secrets detected:\n")
+	for _, f := range findings {
+		fmt.Printf("  %s:%d [%s]\n", f.File, f.Line, f.Rule)
+		fmt.Printf("    %s\n", f.MaskedText())
+	}
+	fmt.Println()
+}
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/apidiff"
+	"github.com/spf13/cobra"
+)
+
+var (
+	semverApply bool
+	semverNoAI  bool
+)
+
+// semverBumps orders the bump kinds from smallest to largest
+var semverBumps = []string{"none", "patch", "minor", "major"}
+
+// semverTagPattern matches version tags such as v1.2.3, 1.2.3 or v1.2.3-rc.1
+var semverTagPattern = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)(?:[-+].*)?$`)
+
+// conventionalSubjectPattern matches "type(scope)!: description" and captures the type and "!"
+var conventionalSubjectPattern = regexp.MustCompile(`^(?:\S+\s+)?([a-zA-Z]+)(?:\([^()]*\))?(!)?:\s`)
+
+// breakingChangePattern matches BREAKING CHANGE footers in commit bodies
+var breakingChangePattern = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:`)
+
+// semverCmd recommends the next semantic version
+var semverCmd = &cobra.Command{
+	Use:   "semver",
+	Short: "Recommend the next semantic version from the commits since the last tag",
+	Long: `Analyze the commits since the latest tag and recommend the next version
+(major/minor/patch) with reasoning. Conventional commit types and BREAKING CHANGE
+markers set the minimum bump; Solar LLM can raise it when the changes show an
+undeclared breaking change or feature. Use --apply to create the tag.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSemver(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(semverCmd)
+
+	semverCmd.Flags().BoolVar(&semverApply, "apply", false, "create an annotated tag for the recommended version")
+	semverCmd.Flags().BoolVar(&semverNoAI, "no-ai", false, "only use conventional commit markers, without AI analysis")
+}
+
+// semVersion is a parsed X.Y.Z version, keeping the tag's "v" prefix
+type semVersion struct {
+	prefix              string
+	major, minor, patch int
+}
+
+func (v semVersion) String() string {
+	return fmt.Sprintf("%s%d.%d.%d", v.prefix, v.major, v.minor, v.patch)
+}
+
+// bump returns the next version for a bump kind. Before 1.0.0, breaking changes bump
+// the minor version, as semver allows for initial development.
+func (v semVersion) bump(kind string) semVersion {
+	if v.major == 0 && kind == "major" {
+		kind = "minor"
+	}
+
+	switch kind {
+	case "major":
+		return semVersion{prefix: v.prefix, major: v.major + 1}
+	case "minor":
+		return semVersion{prefix: v.prefix, major: v.major, minor: v.minor + 1}
+	case "patch":
+		return semVersion{prefix: v.prefix, major: v.major, minor: v.minor, patch: v.patch + 1}
+	}
+	return v
+}
+
+// parseSemver parses a version tag such as v1.2.3
+func parseSemver(tag string) (semVersion, bool) {
+	m := semverTagPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return semVersion{}, false
+	}
+	major, _ := strconv.Atoi(m[2])
+	minor, _ := strconv.Atoi(m[3])
+	patch, _ := strconv.Atoi(m[4])
+	return semVersion{prefix: m[1], major: major, minor: minor, patch: patch}, true
+}
+
+func semverIndex(kind string) int {
+	for i, k := range semverBumps {
+		if k == kind {
+			return i
+		}
+	}
+	return 0
+}
+
+func runSemver(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	current := semVersion{prefix: "v"}
+	currentLabel := "none (no version tags yet)"
+	rangeSpec := "HEAD"
+	if tag, err := getLatestTag("HEAD"); err == nil && tag != "" {
+		version, ok := parseSemver(tag)
+		if !ok {
+			return fmt.Errorf("latest tag '%s' is not a semantic version (vX.Y.Z)", tag)
+		}
+		current, currentLabel = version, tag
+		rangeSpec = tag + "..HEAD"
+	}
+
+	commits, err := runGitOutput("log", "--no-merges", "--format=%h %s%n%b%x00", rangeSpec)
+	if err != nil {
+		return fmt.Errorf("error getting commits: %w", err)
+	}
+	if strings.TrimSpace(strings.ReplaceAll(commits, "\x00", "")) == "" {
+		fmt.Printf("No commits since %s, nothing to release\n", currentLabel)
+		return nil
+	}
+
+	ruleBump, ruleReason := conventionalBump(commits)
+	bump, reason := ruleBump, ruleReason
+	commitLog := strings.ReplaceAll(commits, "\x00", "")
+
+	// The public API changes since the release show undeclared breaking changes
+	var apiChanges []apidiff.Change
+	if strings.Contains(rangeSpec, "..") {
+		apiChanges, _, _ = detectAPIChanges(currentLabel, "HEAD")
+	}
+
+	semverNoAI = !useAIFor("semver", true, false, semverNoAI)
+	if !semverNoAI {
+		// Check configuration and setup if needed
+		if err := ensureConfiguration(); err != nil {
+			return err
+		}
+
+		client, err := newSolarClient()
+		if err != nil {
+			return err
+		}
+
+		var diffStat string
+		if strings.Contains(rangeSpec, "..") {
+			diffStat, _ = runGitOutput("diff", "--stat", rangeSpec)
+		}
+
+		statusln("Analyzing commits with Solar LLM...")
+		response, err := client.RecommendVersionBump(cmd.Context(), currentLabel, commitLog, diffStat, ruleBump, apidiff.Format(apiChanges))
+		if err != nil {
+			return fmt.Errorf("error analyzing commits: %w", err)
+		}
+
+		aiBump, aiReason := parseBumpRecommendation(response)
+		// Conventional markers set the minimum; the AI may only raise the bump
+		if semverIndex(aiBump) > semverIndex(bump) {
+			bump = aiBump
+		}
+		if aiReason != "" {
+			reason = aiReason
+		}
+	}
+
+	next := current.bump(bump)
+
+	fmt.Printf("\nCurrent version: %s\n", currentLabel)
+	fmt.Printf("Commit markers:  %s (%s)\n", ruleBump, ruleReason)
+	if removed, changed := countBreakingCandidates(apiChanges); removed+changed > 0 {
+		fmt.Printf("Public API:      %d removed, %d changed (see 'sgit breaking')\n", removed, changed)
+	}
+	fmt.Printf("Recommended:     %s → %s\n", bump, next)
+	if current.major == 0 && bump == "major" {
+		fmt.Println("                 (breaking changes bump the minor version before 1.0.0)")
+	}
+	if !semverNoAI {
+		fmt.Printf("\n💡 %s\n", reason)
+	}
+
+	if !semverApply {
+		fmt.Printf("\nRun 'sgit semver --apply' or 'sgit tag %s --ai-notes' to create the tag\n", next)
+		return nil
+	}
+
+	fmt.Println()
+	if semverNoAI {
+		if !confirm(fmt.Sprintf("Create annotated tag %s? (y/n): ", next)) {
+			fmt.Println("Tag creation cancelled")
+			return nil
+		}
+		executeGitCommand([]string{"tag", "-a", next.String(), "-m", next.String()})
+		fmt.Printf("✅ Created annotated tag %s\n", next)
+		return nil
+	}
+	return runTag(cmd, []string{next.String(), "--ai-notes"})
+}
+
+// conventionalBump derives the bump from conventional commit types and BREAKING CHANGE
+// markers in NUL-separated "hash subject\nbody" log entries
+func conventionalBump(commits string) (string, string) {
+	bump := "patch"
+	var breaking, features int
+	total := 0
+
+	for _, entry := range strings.Split(commits, "\x00") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		total++
+
+		subject, _, _ := strings.Cut(entry, "\n")
+		// Drop the abbreviated hash in front of the subject
+		if _, rest, ok := strings.Cut(subject, " "); ok {
+			subject = rest
+		}
+
+		m := conventionalSubjectPattern.FindStringSubmatch(subject)
+		switch {
+		case breakingChangePattern.MatchString(entry) || (m != nil && m[2] == "!"):
+			breaking++
+		case m != nil && strings.EqualFold(m[1], "feat"):
+			features++
+		}
+	}
+
+	switch {
+	case breaking > 0:
+		bump = "major"
+	case features > 0:
+		bump = "minor"
+	}
+	return bump, fmt.Sprintf("%d commit(s): %d breaking, %d feature(s)", total, breaking, features)
+}
+
+// parseBumpRecommendation extracts the "BUMP:" and "REASON:" lines of the AI response
+func parseBumpRecommendation(response string) (string, string) {
+	var bump string
+	var reason []string
+	inReason := false
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "*"))
+		upper := strings.ToUpper(trimmed)
+		switch {
+		case strings.HasPrefix(upper, "BUMP:"):
+			value := strings.ToLower(strings.TrimSpace(trimmed[len("BUMP:"):]))
+			for _, kind := range semverBumps[1:] {
+				if strings.HasPrefix(value, kind) {
+					bump = kind
+				}
+			}
+			inReason = false
+		case strings.HasPrefix(upper, "REASON:"):
+			reason = append(reason, strings.TrimSpace(trimmed[len("REASON:"):]))
+			inReason = true
+		case inReason && trimmed != "":
+			reason = append(reason, trimmed)
+		}
+	}
+	return bump, strings.Join(reason, " ")
+}
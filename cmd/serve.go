@@ -0,0 +1,444 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hunkim/sgit/pkg/engine"
+	"github.com/hunkim/sgit/pkg/git"
+	"github.com/hunkim/sgit/pkg/lint"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// serveMaxRequestBytes caps the size of a request body
+const serveMaxRequestBytes = 1 << 20
+
+var (
+	serveAddr   string
+	serveSocket string
+	serveToken  string
+)
+
+// serveCmd runs sgit as a local HTTP service for editor plugins and other tools
+var serveCmd = &cobra.Command{
+	Use:   "serve [--addr host:port | --socket path]",
+	Short: "Serve commit messages, diff summaries and reviews over a local HTTP API",
+	Long: `Run sgit as a local HTTP service so editor plugins and other tools can reuse its
+context gathering and prompts without starting the CLI for every request. Each
+request names the repository it is about, so one server works for every checkout.
+
+Endpoints (POST with a JSON body):
+  /commit-message  {"repo": "/path", "type": "", "scope": "", "hint": ""}
+                   -> {"message": "...", "issues": [...]} for the staged changes
+  /diff-summary    {"repo": "/path", "args": ["--cached"]}  -> {"summary": "..."}
+  /review          {"repo": "/path", "args": ["main...HEAD"]} -> {"review": "..."}
+  GET /health      -> {"status": "ok", "version": "..."}
+
+"args" are git diff arguments (none for the unstaged changes): revisions, --cached,
+--staged, --stat, and paths in the repository after --. Add "stream": true
+to receive newline-delimited JSON: {"chunk": "..."} objects as the response is
+generated, then the final object. Errors are returned as {"error": "..."}.
+
+The server listens on 127.0.0.1 by default. Set --token (or serve_token in config)
+to require an "Authorization: Bearer <token>" header, which you should do before
+listening on any other address. Requests must send Content-Type: application/json,
+and requests from web pages (with an Origin header, or a Host that isn't localhost,
+an IP address or the --addr host) are rejected.
+
+Examples:
+  sgit serve
+  sgit serve --addr 127.0.0.1:9000 --token "$SGIT_SERVE_TOKEN"
+  sgit serve --socket ~/.cache/sgit.sock
+  curl -s localhost:7777/commit-message -H 'Content-Type: application/json' -d '{"repo": "'$PWD'"}'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runServe(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:7777", "TCP address to listen on")
+	serveCmd.Flags().StringVar(&serveSocket, "socket", "", "listen on a unix socket instead of TCP")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "bearer token clients must send (default serve_token in config)")
+}
+
+// serveRequest is the JSON body of the AI endpoints
+type serveRequest struct {
+	Repo   string   `json:"repo"`
+	Args   []string `json:"args"`
+	Type   string   `json:"type"`
+	Scope  string   `json:"scope"`
+	Hint   string   `json:"hint"`
+	Stream bool     `json:"stream"`
+}
+
+// serveHandler generates a response for a request; onChunk streams it (nil when the
+// client didn't ask for streaming). It returns the fields of the final JSON object.
+type serveHandler func(ctx context.Context, eng *engine.Engine, req *serveRequest, onChunk func(string)) (map[string]interface{}, error)
+
+func runServe(cmd *cobra.Command, args []string) error {
+	// The server can't ask for an API key, so it must be configured up front
+	if !aiConfigured() {
+		return fmt.Errorf("no API key configured (set SGIT_API_KEY or run 'sgit config init')")
+	}
+	// Fail at startup rather than on the first request if the config is invalid
+	if _, err := newSolarClient(); err != nil {
+		return err
+	}
+
+	token := serveToken
+	if token == "" {
+		token = viper.GetString("serve_token")
+	}
+
+	var listener net.Listener
+	var err error
+	if serveSocket != "" {
+		socket := expandHome(serveSocket)
+		// A socket left behind by a previous server would make Listen fail
+		if info, statErr := os.Stat(socket); statErr == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(socket)
+		}
+		listener, err = net.Listen("unix", socket)
+		if err == nil {
+			// Only the current user may talk to the server
+			os.Chmod(socket, 0600)
+			defer os.Remove(socket)
+		}
+	} else {
+		listener, err = net.Listen("tcp", serveAddr)
+		if err == nil && token == "" && !isLoopbackAddr(serveAddr) {
+			fmt.Fprintf(os.Stderr, "⚠️  Listening on %s without --token; anyone who can reach it can use your API key\n", serveAddr)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error listening: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		writeServeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "version": version})
+	})
+	mux.Handle("/commit-message", serveEndpoint(serveCommitMessage))
+	mux.Handle("/diff-summary", serveEndpoint(serveDiffSummary))
+	mux.Handle("/review", serveEndpoint(serveReview))
+
+	server := &http.Server{
+		Handler:           serveLogging(serveGuard(serveSocket == "", serveAddr, serveAuth(token, mux))),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Ctrl-C cancels the command's context; let in-flight requests finish briefly
+	go func() {
+		<-cmd.Context().Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("🚀 sgit serving on %s (Ctrl-C to stop)\n", listener.Addr())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// serveEndpoint decodes the request, opens its repository and runs handler with a
+// fresh client, writing a JSON or newline-delimited JSON response
+func serveEndpoint(handler serveHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeServeError(w, http.StatusMethodNotAllowed, fmt.Errorf("use POST"))
+			return
+		}
+
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+			writeServeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("use Content-Type: application/json"))
+			return
+		}
+
+		var req serveRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, serveMaxRequestBytes)).Decode(&req); err != nil {
+			writeServeError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err))
+			return
+		}
+		if req.Repo == "" || !filepath.IsAbs(req.Repo) {
+			writeServeError(w, http.StatusBadRequest, fmt.Errorf("\"repo\" must be an absolute path"))
+			return
+		}
+		if err := checkServeArgs(req.Args); err != nil {
+			writeServeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		eng, err := newRepoEngine(req.Repo)
+		if err != nil {
+			writeServeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !req.Stream {
+			result, err := handler(r.Context(), eng, &req, nil)
+			if err != nil {
+				writeServeError(w, serveErrorStatus(err), err)
+				return
+			}
+			writeServeJSON(w, http.StatusOK, result)
+			return
+		}
+
+		// Stream chunks as they arrive; errors after the first chunk can only be
+		// reported in the body, so they are always sent as a final object
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		flusher, _ := w.(http.Flusher)
+		encoder := json.NewEncoder(w)
+		send := func(value interface{}) {
+			encoder.Encode(value)
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		result, err := handler(r.Context(), eng, &req, func(chunk string) {
+			send(map[string]string{"chunk": chunk})
+		})
+		if err != nil {
+			send(map[string]string{"error": err.Error()})
+			return
+		}
+		send(result)
+	})
+}
+
+func serveCommitMessage(ctx context.Context, eng *engine.Engine, req *serveRequest, onChunk func(string)) (map[string]interface{}, error) {
+	commitContext, err := eng.CommitContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	client := eng.Client()
+	hints := commitContext.Hints
+	hints.Type, hints.Scope, hints.Hint = req.Type, req.Scope, req.Hint
+	client.SetCommitHints(hints)
+	message, issues, err := generateValidCommitMessage(client, func() (string, error) {
+		message, err := eng.GenerateCommitMessageFor(ctx, commitContext, onChunk)
+		if err != nil {
+			return "", err
+		}
+		return applyGitmoji(message), nil
+	}, func(issues []lint.Issue, attempt, maxAttempts int) {
+		if onChunk != nil {
+			onChunk(fmt.Sprintf("\n\n(failed validation: %s; regenerating %d/%d)\n\n", issues[0].Message, attempt, maxAttempts))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	problems := make([]string, len(issues))
+	for i, issue := range issues {
+		problems[i] = issue.String()
+	}
+	return map[string]interface{}{"message": strings.TrimSpace(message), "issues": problems}, nil
+}
+
+func serveDiffSummary(ctx context.Context, eng *engine.Engine, req *serveRequest, onChunk func(string)) (map[string]interface{}, error) {
+	summary, err := eng.SummarizeDiffStream(ctx, onChunk, req.Args...)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"summary": strings.TrimSpace(summary)}, nil
+}
+
+func serveReview(ctx context.Context, eng *engine.Engine, req *serveRequest, onChunk func(string)) (map[string]interface{}, error) {
+	review, err := eng.ReviewDiffStream(ctx, onChunk, req.Args...)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"review": strings.TrimSpace(review)}, nil
+}
+
+// serveArgOptions are the git options requests may use: those of diff, and the ones
+// that pick commits for the log analysis of sgit mcp and sgit ipc. Anything else could
+// read files outside the repository (--no-index), write files (--output) or run
+// programs (--ext-diff, --textconv).
+var serveArgOptions = map[string]bool{
+	"--cached": true, "--staged": true, "--stat": true,
+	"--merges": true, "--no-merges": true, "--first-parent": true,
+}
+
+// serveArgValueOptions are the options of serveArgOptions that take a value, given as
+// --option=value
+var serveArgValueOptions = []string{"--since=", "--until=", "--after=", "--before=", "--author=", "--max-count="}
+
+// checkServeArgs allows only revisions, the options of serveArgOptions and paths in
+// the repository after --. Paths outside it are rejected as well: git diff compares two
+// of them as files on disk, like --no-index.
+func checkServeArgs(args []string) error {
+	paths := false
+	for _, arg := range args {
+		switch {
+		case paths || !strings.HasPrefix(arg, "-"):
+			// Revisions and paths alike must stay inside the repository
+			if clean := filepath.Clean(filepath.FromSlash(arg)); filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+				return fmt.Errorf("path %s is outside the repository", arg)
+			}
+		case arg == "--":
+			paths = true
+		case serveArgOptions[arg] || isCountOption(arg):
+		case slices.ContainsFunc(serveArgValueOptions, func(option string) bool { return strings.HasPrefix(arg, option) }):
+		default:
+			return fmt.Errorf("unsupported git argument %s", arg)
+		}
+	}
+	return nil
+}
+
+// isCountOption reports whether arg limits the number of commits, like -20
+func isCountOption(arg string) bool {
+	_, err := strconv.Atoi(strings.TrimPrefix(arg, "-"))
+	return len(arg) > 1 && err == nil && !strings.HasPrefix(arg, "--")
+}
+
+// newRepoEngine returns an engine for the repository at dir with a client of its own,
+// so hints and corrections never leak between requests. It draws no spinners.
+func newRepoEngine(dir string) (*engine.Engine, error) {
+	repo, err := git.New(viper.GetString("git_backend"), dir)
+	if err != nil {
+		repo = git.NewCLI(dir)
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return nil, err
+	}
+	client.SetActivityFunc(nil)
+	eng := engine.New(client, repo)
+	configureContextProviders(eng.ContextProviders(), repo)
+	return eng, nil
+}
+
+// serveErrorStatus maps an engine error to an HTTP status
+func serveErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, engine.ErrNotRepository):
+		return http.StatusBadRequest
+	case errors.Is(err, engine.ErrNoStagedChanges), errors.Is(err, engine.ErrEmptyDiff):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, solar.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, solar.ErrContextTooLarge):
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadGateway
+}
+
+// serveAuth rejects requests without the bearer token, when one is set
+func serveAuth(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			writeServeError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveGuard rejects requests a web page could have sent: any with an Origin header,
+// which browsers add to cross-origin requests, and, on a TCP listener, any whose Host
+// isn't localhost, an IP address or the host of addr, which a page reaching the server
+// through DNS rebinding would have
+func serveGuard(checkHost bool, addr string, next http.Handler) http.Handler {
+	listenHost, _, _ := net.SplitHostPort(addr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") != "" {
+			writeServeError(w, http.StatusForbidden, fmt.Errorf("requests from web pages are not allowed"))
+			return
+		}
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		host = strings.Trim(host, "[]")
+		if checkHost && !strings.EqualFold(host, "localhost") && net.ParseIP(host) == nil && (listenHost == "" || !strings.EqualFold(host, listenHost)) {
+			writeServeError(w, http.StatusForbidden, fmt.Errorf("unexpected Host %s", r.Host))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveStatusWriter records the status code of a response for the request log
+type serveStatusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *serveStatusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush lets streamed responses through the logging wrapper
+func (w *serveStatusWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// serveLogging prints one line per request, unless --quiet is set
+func serveLogging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &serveStatusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		if !quiet {
+			fmt.Fprintf(os.Stderr, "%s %s %s %d %s\n", start.Format("15:04:05"), r.Method, r.URL.Path,
+				recorder.status, time.Since(start).Round(time.Millisecond))
+		}
+	})
+}
+
+func writeServeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeServeError(w http.ResponseWriter, status int, err error) {
+	writeServeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// isLoopbackAddr reports whether a listen address only accepts local connections
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
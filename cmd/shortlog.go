@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+)
+
+// shortlogCmd wraps git shortlog, adding summaries of a release for engineers or for
+// product and customers with --audience
+var shortlogCmd = &cobra.Command{
+	Use:   "shortlog [--audience engineering|product] [<from> [<to>] | <from>..<to>]",
+	Short: "Summarize the changes between two refs for engineers or customers",
+	Long: `Passthrough to git shortlog. With --audience, Solar LLM summarizes the commits
+between two refs for a release email instead:
+
+  engineering  a technical summary: features, fixes, breaking changes and
+               migrations, internals, naming the components that changed
+  product      a "what's new" list for product, marketing and customers, in
+               user-facing language, without internal work or code
+
+The range is <from>..<to>; a single ref is the changes since it, and without one
+the changes since the latest tag. -o also writes the summary to a file.
+
+Examples:
+  sgit shortlog --audience product
+  sgit shortlog --audience engineering v1.2.0 v1.3.0
+  sgit shortlog --audience product v1.2.0..main -o whats-new.md
+  sgit shortlog -sn --no-merges`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runShortlog(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+	DisableFlagParsing: true,
+}
+
+func init() {
+	rootCmd.AddCommand(shortlogCmd)
+}
+
+func runShortlog(cmd *cobra.Command, args []string) error {
+	// Flag parsing is disabled so every git shortlog option passes through; pick out ours
+	args = extractGlobalFlags(args)
+	var audience, output string
+	var refs []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "--audience", "-o", "--output":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s needs a value", name)
+				}
+				i++
+				value = args[i]
+			}
+			if name == "--audience" {
+				audience = value
+			} else {
+				output = value
+			}
+		case "-h", "--help":
+			return cmd.Help()
+		default:
+			refs = append(refs, arg)
+		}
+	}
+
+	if audience == "" {
+		if output != "" {
+			return fmt.Errorf("-o needs --audience")
+		}
+		executeGitCommand(append([]string{"shortlog"}, refs...))
+		return nil
+	}
+
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	audienceInstructions, ok := solar.ShortlogAudiences[strings.ToLower(audience)]
+	if !ok {
+		names := make([]string, 0, len(solar.ShortlogAudiences))
+		for name := range solar.ShortlogAudiences {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown audience '%s' (use %s)", audience, strings.Join(names, ", "))
+	}
+
+	rangeSpec, err := shortlogRange(refs)
+	if err != nil {
+		return err
+	}
+
+	logArgs := []string{"log", "--no-merges", "--format=%h %an: %s%n%b"}
+	if rangeSpec != "" {
+		logArgs = append(logArgs, rangeSpec)
+	}
+	commits, err := runGitOutput(logArgs...)
+	if err != nil {
+		return fmt.Errorf("error getting commits for %s: %v", rangeDescription(rangeSpec), err)
+	}
+	if strings.TrimSpace(commits) == "" {
+		fmt.Printf("No commits in %s\n", rangeDescription(rangeSpec))
+		return nil
+	}
+	var diffStat string
+	if strings.Contains(rangeSpec, "..") {
+		diffStat, _ = runGitOutput("diff", "--stat", rangeSpec)
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	statusf("Summarizing %s for %s with Solar LLM...\n", rangeDescription(rangeSpec), strings.ToLower(audience))
+	renderer, err := outputRenderer("")
+	if err != nil {
+		return err
+	}
+	printContentStats("Commit history", commits, diffStat)
+	printer := newStreamPrinter("")
+	printer.renderer = renderer
+	summary, err := client.SummarizeChanges(cmd.Context(), rangeDescription(rangeSpec), commits, diffStat, audienceInstructions, printer.Write)
+	printer.Done()
+	if err != nil {
+		return fmt.Errorf("error generating summary: %w", err)
+	}
+	fmt.Println() // Add newline after streaming output
+
+	if output != "" {
+		if err := os.WriteFile(output, []byte(strings.TrimSpace(summary)+"\n"), 0644); err != nil {
+			return fmt.Errorf("error writing %s: %v", output, err)
+		}
+		fmt.Printf("✅ Wrote %s\n", output)
+	}
+	return nil
+}
+
+// shortlogRange turns the refs given to sgit shortlog --audience into a range: two
+// refs are from..to, one ref without ".." is the changes since it, and none is the
+// changes since the latest tag
+func shortlogRange(refs []string) (string, error) {
+	for _, ref := range refs {
+		if strings.HasPrefix(ref, "-") {
+			return "", fmt.Errorf("unknown option '%s' for --audience (it takes a range of commits)", ref)
+		}
+	}
+	switch len(refs) {
+	case 0:
+		return defaultReleaseRange(), nil
+	case 1:
+		if strings.Contains(refs[0], "..") {
+			return refs[0], nil
+		}
+		return refs[0] + "..HEAD", nil
+	case 2:
+		return refs[0] + ".." + refs[1], nil
+	}
+	return "", fmt.Errorf("too many refs: give <from> [<to>] or <from>..<to>")
+}
@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Values of the sign setting
+const (
+	signAlways = "always"
+	signNever  = "never"
+	// signGit leaves signing to git's commit.gpgsign
+	signGit = "git"
+)
+
+// gpgSignDefault is the --gpg-sign value when no key ID is given (-S alone)
+const gpgSignDefault = "default"
+
+// normalizeSignMode checks a sign setting
+func normalizeSignMode(value string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(value)); mode {
+	case signAlways, signNever, signGit:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid sign '%s' (use always, never or git)", value)
+}
+
+// signModeWarned keeps an invalid sign setting from being reported more than once
+var signModeWarned bool
+
+// signMode returns sign from config: always, never, or git (the default)
+func signMode() string {
+	value := viper.GetString("sign")
+	if strings.TrimSpace(value) == "" {
+		return signGit
+	}
+	mode, err := normalizeSignMode(value)
+	if err != nil {
+		if !signModeWarned {
+			signModeWarned = true
+			fmt.Fprintf(os.Stderr, "⚠️  %v, using %s\n", err, signGit)
+		}
+		return signGit
+	}
+	return mode
+}
+
+// addSigningFlags declares git commit's signing flags on cmd
+func addSigningFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("gpg-sign", "S", "", "GPG/SSH-sign the commit, optionally with a key ID (-S, --gpg-sign=<keyid>)")
+	cmd.Flags().Lookup("gpg-sign").NoOptDefVal = gpgSignDefault
+	cmd.Flags().Bool("no-gpg-sign", false, "don't sign the commit, overriding commit.gpgsign and sign: always")
+}
+
+// gitCommitFlagArgs returns the git commit arguments for a flag given to sgit commit
+func gitCommitFlagArgs(flag *pflag.Flag) []string {
+	value := flag.Value.String()
+	if flag.Name == "gpg-sign" {
+		switch value {
+		case "":
+			return nil
+		case gpgSignDefault:
+			return []string{"--gpg-sign"}
+		}
+		return []string{"--gpg-sign=" + value}
+	}
+	if flag.Value.Type() == "bool" && value == "true" {
+		return []string{"--" + flag.Name}
+	} else if flag.Value.Type() != "bool" && value != "" {
+		return []string{"--" + flag.Name + "=" + value}
+	}
+	return nil
+}
+
+// configSigningArgs returns the signing argument sign: always or never adds to git
+// commit when neither -S nor --no-gpg-sign was given
+func configSigningArgs(cmd *cobra.Command) []string {
+	if cmd.Flags().Changed("gpg-sign") || cmd.Flags().Changed("no-gpg-sign") {
+		return nil
+	}
+	switch signMode() {
+	case signAlways:
+		return []string{"--gpg-sign"}
+	case signNever:
+		return []string{"--no-gpg-sign"}
+	}
+	return nil
+}
+
+// commitWillBeSigned reports whether git commit signs the commit: with -S, sign:
+// always in config, or commit.gpgsign in git config, unless turned off
+func commitWillBeSigned(cmd *cobra.Command) bool {
+	if noSign, _ := cmd.Flags().GetBool("no-gpg-sign"); noSign {
+		return false
+	}
+	if cmd.Flags().Changed("gpg-sign") {
+		return true
+	}
+	switch signMode() {
+	case signAlways:
+		return true
+	case signNever:
+		return false
+	}
+	return signingRequired()
+}
+
+// signingRequired reports whether the repository expects signed commits:
+// commit.gpgsign in git config or sign: always in sgit's
+func signingRequired() bool {
+	if signMode() == signAlways {
+		return true
+	}
+	return strings.EqualFold(strings.TrimSpace(gitConfigValue("commit.gpgsign")), "true")
+}
+
+// signingProblem checks that git can sign commits in the configured format
+// (gpg.format), and describes what is missing, or returns ""
+func signingProblem(cmd *cobra.Command) string {
+	format := strings.TrimSpace(gitConfigValue("gpg.format"))
+	if format == "" {
+		format = "openpgp"
+	}
+	program := strings.TrimSpace(gitConfigValue("gpg." + format + ".program"))
+	if program == "" && format == "openpgp" {
+		program = strings.TrimSpace(gitConfigValue("gpg.program"))
+	}
+
+	key := strings.TrimSpace(gitConfigValue("user.signingkey"))
+	if value, _ := cmd.Flags().GetString("gpg-sign"); cmd.Flags().Changed("gpg-sign") && value != gpgSignDefault {
+		key = value
+	}
+
+	switch format {
+	case "ssh":
+		if program == "" {
+			program = "ssh-keygen"
+		}
+		if key == "" && strings.TrimSpace(gitConfigValue("gpg.ssh.defaultKeyCommand")) == "" {
+			return "SSH signing needs a key: git config user.signingkey ~/.ssh/id_ed25519.pub"
+		}
+		// The key is a literal public key ("key::..." or "ssh-ed25519 ...") or a path
+		if key != "" && !strings.HasPrefix(key, "key::") && !strings.HasPrefix(key, "ssh-") {
+			path := key
+			if strings.HasPrefix(path, "~/") {
+				if home, err := os.UserHomeDir(); err == nil {
+					path = filepath.Join(home, path[2:])
+				}
+			}
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Sprintf("the SSH signing key %s does not exist", key)
+			}
+		}
+	case "x509":
+		if program == "" {
+			program = "gpgsm"
+		}
+	default:
+		if program == "" {
+			program = "gpg"
+		}
+	}
+	if _, err := exec.LookPath(program); err != nil {
+		return fmt.Sprintf("%s signing needs %s, which is not installed", format, program)
+	}
+	return ""
+}
+
+// checkCommitSigning makes sure a commit that will be signed can be, before a message
+// is generated for it. When signing isn't set up, the user chooses between committing
+// unsigned and stopping; with --yes it stops.
+func checkCommitSigning(cmd *cobra.Command) error {
+	if !commitWillBeSigned(cmd) {
+		return nil
+	}
+	problem := signingProblem(cmd)
+	if problem == "" {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "⚠️  The commit is to be signed, but %s\n", problem)
+	if assumeYes || !confirm("Commit without signing? (y/n): ") {
+		return fmt.Errorf("commit signing is not set up: %s (or pass --no-gpg-sign)", problem)
+	}
+	cmd.Flags().Set("gpg-sign", "")
+	cmd.Flags().Set("no-gpg-sign", "true")
+	return nil
+}
+
+// reportCommitSignature tells whether the commit just made carries a good signature
+func reportCommitSignature() {
+	status, err := runGitOutput("log", "-1", "--format=%G?%x09%GS", "HEAD")
+	if err != nil {
+		return
+	}
+	code, signer, _ := strings.Cut(strings.TrimSpace(status), "\t")
+	switch code {
+	case "G":
+		if signer != "" {
+			statusf("🔏 Signed by %s\n", signer)
+		} else {
+			statusln("🔏 Signed")
+		}
+	case "N":
+		if !hasSignature("HEAD") {
+			fmt.Fprintln(os.Stderr, "⚠️  The commit is not signed")
+			return
+		}
+		// git can't check SSH signatures without gpg.ssh.allowedSignersFile
+		statusln("🔏 Signed (the signature could not be verified locally)")
+	case "B":
+		fmt.Fprintln(os.Stderr, "⚠️  The commit's signature is bad")
+	case "E":
+		// The signature can't be checked here, e.g. without an allowed signers file for SSH
+		statusln("🔏 Signed (the signature could not be verified locally)")
+	default:
+		statusf("🔏 Signed (signature status %s)\n", code)
+	}
+}
+
+// unsignedCommitsNote lists the commits that git log with args shows which are not
+// signed, for a repository that requires signing; it returns "" when there are none
+func unsignedCommitsNote(args []string) string {
+	output, err := runGitOutput(append(append([]string{"log"}, args...), "--no-patch", "--no-decorate", "--format=%x00%h %G? %s")...)
+	if err != nil {
+		return ""
+	}
+	var unsigned []string
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(line, "\x00") {
+			continue
+		}
+		fields := strings.SplitN(line[1:], " ", 3)
+		if len(fields) == 3 && fields[1] == "N" && !hasSignature(fields[0]) {
+			unsigned = append(unsigned, fields[0]+" "+fields[2])
+		}
+	}
+	if len(unsigned) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("This repository requires signed commits (commit.gpgsign or sign: always), but %d of these commits are unsigned:\n%s",
+		len(unsigned), strings.Join(unsigned, "\n"))
+}
+
+// hasSignature reports whether a commit carries a signature, whether or not git can
+// verify it
+func hasSignature(rev string) bool {
+	object, err := runGitOutput("cat-file", "commit", rev)
+	if err != nil {
+		return false
+	}
+	headers, _, _ := strings.Cut(object, "\n\n")
+	return strings.Contains(headers, "\ngpgsig ") || strings.Contains(headers, "\ngpgsig-sha256 ")
+}
@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/i18n"
+	"github.com/hunkim/sgit/pkg/progress"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+)
+
+var (
+	squashNoAI   bool
+	squashDryRun bool
+	squashHint   string
+)
+
+// squashCmd squashes a run of commits into one with a message written for the result
+var squashCmd = &cobra.Command{
+	Use:   "squash [<count> | <range>]",
+	Short: "Squash commits into one with an AI-written message",
+	Long: `Squash the last <count> commits, or the commits in a range such as main..HEAD,
+into a single commit. Solar LLM reads the messages of the squashed commits and their
+combined diff and writes one message for the end result, so a "wip", "fix", "fix
+again" chain reads as the change it adds up to. Without arguments, the commits not yet
+pushed to the upstream branch are squashed.
+
+The squash runs as a non-interactive rebase: commits after the range are replayed on
+top, and local changes are stashed and restored. Review the message first: y squashes
+with it, e opens it in your editor, n stops without changing anything. With --no-ai
+the original messages are combined as git does.
+
+Examples:
+  sgit squash 3
+  sgit squash main..HEAD
+  sgit squash --hint "add rate limiting to the API"
+  sgit squash 4 --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSquash(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(squashCmd)
+
+	squashCmd.Flags().BoolVar(&squashNoAI, "no-ai", false, "combine the original messages instead of writing a new one")
+	squashCmd.Flags().BoolVarP(&squashDryRun, "dry-run", "n", false, "show the commits and message without squashing")
+	squashCmd.Flags().StringVar(&squashHint, "hint", "", "describe the combined change to guide the AI message")
+}
+
+// squashedCommit is one of the commits being squashed
+type squashedCommit struct {
+	sha     string
+	author  string
+	message string
+}
+
+func (c squashedCommit) subject() string {
+	return strings.SplitN(c.message, "\n", 2)[0]
+}
+
+func runSquash(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+	if isRebaseInProgress() {
+		return fmt.Errorf("a rebase is in progress; finish or abort it first")
+	}
+
+	rangeArgs, err := squashRange(args)
+	if err != nil {
+		return err
+	}
+	commits, replayed, err := loadSquashCommits(rangeArgs)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🧩 Squashing %d commits:\n", len(commits))
+	for _, commit := range commits {
+		fmt.Printf("   %s %s\n", commit.sha[:7], commit.subject())
+	}
+	if len(replayed) > 0 {
+		fmt.Printf("   (%d later commit(s) are replayed on top)\n", len(replayed))
+	}
+	first, last := commits[0].sha, commits[len(commits)-1].sha
+	if unpushed, err := runGitOutput("rev-list", "--count", last, "--not", "--remotes", first+"^@"); err == nil {
+		if n, _ := strconv.Atoi(strings.TrimSpace(unpushed)); n < len(commits) {
+			fmt.Println("⚠️  Some of these commits are already pushed; squashing rewrites them and needs a force push")
+		}
+	}
+	fmt.Println()
+
+	var messages strings.Builder
+	for i, commit := range commits {
+		fmt.Fprintf(&messages, "%d. %s\n\n", i+1, commit.message)
+	}
+
+	var message string
+	if useAIFor("squash", true, false, squashNoAI) {
+		message, err = generateSquashMessage(cmd, commits, messages.String())
+		if err != nil {
+			return err
+		}
+	} else {
+		// git's own squash message, without its comment lines
+		parts := make([]string, len(commits))
+		for i, commit := range commits {
+			parts[i] = commit.message
+		}
+		message = strings.Join(parts, "\n\n")
+	}
+
+	fmt.Printf("=== SQUASHED COMMIT MESSAGE ===\n%s\n\n", message)
+	if squashDryRun {
+		fmt.Println("Dry run: nothing was squashed")
+		return nil
+	}
+
+	answer := "y"
+	if !assumeYes {
+		answer = strings.ToLower(ask("Squash with this message? (y = yes, e = edit, n = no): "))
+	}
+	switch answer {
+	case "y", "yes":
+	case "e", "edit":
+		edited, err := openEditorWithMessage(message, fmt.Sprintf("Message for %d squashed commits.", len(commits)))
+		if err != nil {
+			return fmt.Errorf("error opening editor: %v", err)
+		}
+		if edited == "" {
+			fmt.Println("Empty commit message, squash cancelled")
+			return nil
+		}
+		message = edited
+	default:
+		fmt.Println("Squash cancelled")
+		return nil
+	}
+
+	return squashCommits(commits, replayed, message)
+}
+
+// squashRange turns the argument into the rev-list arguments of the commits to squash:
+// a count of commits before HEAD, a range, or by default the unpushed commits
+func squashRange(args []string) ([]string, error) {
+	if len(args) == 0 {
+		if _, err := runGitOutput("rev-parse", "--verify", "--quiet", "@{u}"); err != nil {
+			return nil, fmt.Errorf("no upstream branch to compare with; give a count (sgit squash 3) or a range (sgit squash main..HEAD)")
+		}
+		return []string{"@{u}..HEAD"}, nil
+	}
+
+	arg := args[0]
+	if n, err := strconv.Atoi(arg); err == nil {
+		if n < 2 {
+			return nil, fmt.Errorf("squashing needs at least 2 commits, not %d", n)
+		}
+		return []string{"-n", strconv.Itoa(n), "HEAD"}, nil
+	}
+	if strings.Contains(arg, "..") {
+		return []string{arg}, nil
+	}
+	return nil, fmt.Errorf("expected a count or a range like main..HEAD, not '%s'", arg)
+}
+
+// loadSquashCommits returns the commits to squash, oldest first, and the shas of the
+// commits after them up to HEAD, which the rebase replays. The commits must be
+// consecutive, in HEAD's history and free of merges.
+func loadSquashCommits(rangeArgs []string) ([]squashedCommit, []string, error) {
+	output, err := runGitOutput(append([]string{"rev-list", "--reverse"}, rangeArgs...)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing commits in %s: %v", strings.Join(rangeArgs, " "), err)
+	}
+	shas := strings.Fields(output)
+	if len(shas) < 2 {
+		return nil, nil, fmt.Errorf("nothing to squash: %s has %d commit(s)", strings.Join(rangeArgs, " "), len(shas))
+	}
+
+	first, last := shas[0], shas[len(shas)-1]
+	if _, err := runGitOutput("merge-base", "--is-ancestor", last, "HEAD"); err != nil {
+		return nil, nil, fmt.Errorf("the commits to squash must be on the current branch")
+	}
+	// first^@ excludes the parents of the oldest commit, or nothing for a root commit
+	if merges, _ := runGitOutput("rev-list", "--min-parents=2", "HEAD", "--not", first+"^@"); strings.TrimSpace(merges) != "" {
+		return nil, nil, fmt.Errorf("can't squash across merge commits")
+	}
+	all, err := runGitOutput("rev-list", "--reverse", "HEAD", "--not", first+"^@")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing commits: %w", err)
+	}
+	history := strings.Fields(all)
+	if len(history) < len(shas) || strings.Join(history[:len(shas)], " ") != strings.Join(shas, " ") {
+		return nil, nil, fmt.Errorf("the commits to squash must be consecutive")
+	}
+
+	commits := make([]squashedCommit, 0, len(shas))
+	for _, sha := range shas {
+		info, err := runGitOutput("log", "-1", "--format=%an <%ae>%x00%B", sha)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading %s: %v", sha[:7], err)
+		}
+		author, message, _ := strings.Cut(info, "\x00")
+		commits = append(commits, squashedCommit{sha: sha, author: author, message: strings.TrimSpace(message)})
+	}
+	return commits, history[len(shas):], nil
+}
+
+// generateSquashMessage has the AI write one message for the combined change of the
+// commits, checked with the lint rules, with the configured trailers and the authors
+// of the other commits as co-authors
+func generateSquashMessage(cmd *cobra.Command, commits []squashedCommit, messages string) (string, error) {
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return "", err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return "", err
+	}
+
+	first, last := commits[0].sha, commits[len(commits)-1].sha
+	base := first + "^"
+	if _, err := runGitOutput("rev-parse", "--verify", "--quiet", base); err != nil {
+		base = emptyTreeHash
+	}
+	diff, err := runGitOutput("diff", base, last)
+	if err != nil {
+		return "", fmt.Errorf("error getting the combined diff: %w", err)
+	}
+	fileList, _ := runGitOutput("diff", "--stat", base, last)
+
+	ticketKey := branchTicketKey()
+	client.SetCommitHints(solar.CommitHints{Hint: squashHint, TicketKey: ticketKey})
+
+	printContentStats("Squashed commits", messages, diff, fileList)
+	generating := progress.Begin(i18n.T("Writing the squashed commit message"))
+	message, issues, err := generateValidCommitMessage(client, func() (string, error) {
+		message, err := client.GenerateSquashMessage(cmd.Context(), messages, diff, fileList)
+		if err != nil {
+			return "", err
+		}
+		return applyGitmoji(message), nil
+	}, printValidationRetry)
+	generating.End()
+	if err != nil {
+		return "", fmt.Errorf("error generating the squashed commit message: %w", err)
+	}
+	printValidationIssues(issues)
+
+	// The trailers of the original messages are kept, and the authors of the other
+	// commits credited, since the squashed commit keeps the first commit's author
+	var kept, coAuthors []string
+	for _, commit := range commits {
+		_, trailers := splitTrailers(commit.message)
+		kept = append(kept, trailers...)
+		if trailer := coAuthoredByToken + ": " + commit.author; commit.author != commits[0].author && !containsTrailer(coAuthors, trailer) {
+			coAuthors = append(coAuthors, trailer)
+		}
+	}
+	message = applyTrailers(message, ticketKey, "\n\n"+strings.Join(append(kept, coAuthors...), "\n"))
+	body, trailers := splitTrailers(message)
+	for _, trailer := range coAuthors {
+		if !containsTrailer(trailers, trailer) {
+			trailers = append(trailers, trailer)
+		}
+	}
+	if len(trailers) == 0 {
+		return body, nil
+	}
+	return body + "\n\n" + strings.Join(trailers, "\n"), nil
+}
+
+// squashCommits squashes commits into the first of them with message, then replays
+// the commits after them, using a rebase whose todo list is written for it
+func squashCommits(commits []squashedCommit, replayed []string, message string) error {
+	messageDir, cleanup, err := rebaseMessageDir("squash-")
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	messageFile := filepath.Join(messageDir, "message.txt")
+	if err := os.WriteFile(messageFile, []byte(message+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write message file: %w", err)
+	}
+
+	var todo strings.Builder
+	fmt.Fprintf(&todo, "pick %s\n", commits[0].sha)
+	for _, commit := range commits[1:] {
+		fmt.Fprintf(&todo, "fixup %s\n", commit.sha)
+	}
+	fmt.Fprintf(&todo, "exec git commit --amend --allow-empty --no-verify -F %s\n", shellQuote(messageFile))
+	for _, sha := range replayed {
+		fmt.Fprintf(&todo, "pick %s\n", sha)
+	}
+
+	todoFile := filepath.Join(messageDir, "todo")
+	if err := os.WriteFile(todoFile, []byte(todo.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write rebase todo: %w", err)
+	}
+
+	rebaseArgs := []string{"rebase", "-i", "--autostash"}
+	if _, err := runGitOutput("rev-parse", "--verify", "--quiet", commits[0].sha+"^"); err == nil {
+		rebaseArgs = append(rebaseArgs, commits[0].sha+"^")
+	} else {
+		rebaseArgs = append(rebaseArgs, "--root")
+	}
+
+	gitCmd := exec.Command("git", rebaseArgs...)
+	gitCmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=cp "+shellQuote(todoFile), "GIT_EDITOR=true")
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	if err := gitCmd.Run(); err != nil {
+		if isRebaseInProgress() {
+			printRebaseInstructions()
+			return nil
+		}
+		return fmt.Errorf("rebase failed: %w", err)
+	}
+	fmt.Printf("✅ Squashed %d commits into one\n", len(commits))
+	return nil
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hunkim/sgit/pkg/repostats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsSince string
+	statsUntil string
+	statsTop   int
+	statsWeeks int
+	statsAI    bool
+	statsNoAI  bool
+)
+
+// statsBarWidth is the width of the longest bar in the charts
+const statsBarWidth = 30
+
+// statsCmd shows repository analytics computed from the history
+var statsCmd = &cobra.Command{
+	Use:   "stats [<revision>]",
+	Short: "Show repository analytics: commit frequency, hotspots, contributors, bus factor",
+	Long: `Compute analytics from the history of <revision> (default HEAD) and show them as a
+dashboard: commit frequency by week, weekday and hour, the most changed files, the
+contributors' share of the work, and the bus factor.
+
+The numbers are counted by sgit from git log, so they are exact and the same on
+every run. With --ai, Solar LLM adds commentary on what they mean; it interprets the
+numbers but doesn't compute any. stats_ai: true in config turns it on by default.
+
+The bus factor is how few authors would have to leave for more than half of the
+files that still exist to have no owner left. An author owns a file when they
+changed at least a quarter of its lines, or the most of anyone. Authors are
+matched by email, after .mailmap.
+
+Examples:
+  sgit stats
+  sgit stats --since "1 year ago"
+  sgit stats --top 20 --weeks 26
+  sgit stats origin/main --ai`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runStats(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVar(&statsSince, "since", "", "only count commits more recent than a date (e.g. \"6 months ago\")")
+	statsCmd.Flags().StringVar(&statsUntil, "until", "", "only count commits older than a date")
+	statsCmd.Flags().IntVar(&statsTop, "top", 10, "number of hotspots and contributors to show")
+	statsCmd.Flags().IntVar(&statsWeeks, "weeks", 12, "number of recent weeks in the commit frequency chart")
+	statsCmd.Flags().BoolVar(&statsAI, "ai", false, "add AI commentary on the numbers")
+	statsCmd.Flags().BoolVar(&statsNoAI, "no-ai", false, "don't add AI commentary, even with stats_ai: true in config")
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	if statsTop < 1 {
+		return fmt.Errorf("--top must be at least 1")
+	}
+
+	revision := "HEAD"
+	if len(args) > 0 {
+		revision = args[0]
+	}
+	if _, err := runGitOutput("rev-parse", "--verify", "--quiet", revision+"^{commit}"); err != nil {
+		if revision == "HEAD" {
+			return fmt.Errorf("the repository has no commits yet")
+		}
+		return fmt.Errorf("unknown revision '%s'", revision)
+	}
+
+	logArgs := []string{"log", "--no-merges", "--no-renames", "--numstat", "--format=" + repostats.LogFormat}
+	if statsSince != "" {
+		logArgs = append(logArgs, "--since="+statsSince)
+	}
+	if statsUntil != "" {
+		logArgs = append(logArgs, "--until="+statsUntil)
+	}
+	output, err := runGitOutput(append(logArgs, revision, "--")...)
+	if err != nil {
+		return fmt.Errorf("error reading the history: %w", err)
+	}
+	commits := repostats.ParseLog(output)
+
+	scope := "all commits on " + revision
+	if statsSince != "" {
+		scope += " since " + statsSince
+	}
+	if statsUntil != "" {
+		scope += " until " + statsUntil
+	}
+	if len(commits) == 0 {
+		fmt.Printf("No commits (%s)\n", scope)
+		return nil
+	}
+
+	// The bus factor counts the files that still exist, not every file ever changed
+	var files []string
+	if tree, err := runGitOutput("ls-tree", "-r", "--name-only", "--full-tree", revision); err == nil {
+		files = strings.Split(strings.TrimSpace(tree), "\n")
+	}
+	stats := repostats.Compute(commits, files)
+
+	report := formatStats(stats, scope)
+	fmt.Print(report)
+
+	if !useAIFor("stats", false, statsAI, statsNoAI) {
+		return nil
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	printContentStats("Stats commentary", report)
+	printer := newStreamPrinter("")
+	_, err = client.CommentOnStatsStream(cmd.Context(), report, scope, printer.Write)
+	printer.Done()
+	if err != nil {
+		return fmt.Errorf("error generating commentary: %w", err)
+	}
+	fmt.Println()
+	return nil
+}
+
+// formatStats lays out the analytics as a text dashboard, which is also what the
+// AI comments on
+func formatStats(stats repostats.Stats, scope string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📊 Repository stats (%s)\n\n", scope)
+
+	fmt.Fprintln(&b, "Overview")
+	fmt.Fprintf(&b, "  Commits        %d on %d active day(s), %s to %s\n", stats.Commits, stats.ActiveDays,
+		stats.First.Format("2006-01-02"), stats.Last.Format("2006-01-02"))
+	fmt.Fprintf(&b, "  Contributors   %d\n", len(stats.Contributors))
+	fmt.Fprintf(&b, "  Lines          +%d -%d in %d file(s)\n", stats.Added, stats.Removed, stats.FilesTouched)
+	if days := stats.Last.Sub(stats.First).Hours() / 24; days >= 7 {
+		fmt.Fprintf(&b, "  Average        %.1f commits per week\n", float64(stats.Commits)/(days/7))
+	}
+
+	weeks := stats.Weeks
+	if statsWeeks > 0 && len(weeks) > statsWeeks {
+		weeks = weeks[len(weeks)-statsWeeks:]
+	}
+	fmt.Fprintf(&b, "\nCommits per week (last %d, up to the last commit)\n", len(weeks))
+	busiest := 0
+	for _, week := range weeks {
+		busiest = max(busiest, week.Commits)
+	}
+	for _, week := range weeks {
+		fmt.Fprintf(&b, "  %s  %-*s %d\n", week.Start.Format("2006-01-02"), statsBarWidth, statsBar(week.Commits, busiest), week.Commits)
+	}
+
+	fmt.Fprintln(&b, "\nCommits by weekday")
+	busiest = 0
+	for _, n := range stats.Weekdays {
+		busiest = max(busiest, n)
+	}
+	// Monday first
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		fmt.Fprintf(&b, "  %s  %-*s %d\n", day.String()[:3], statsBarWidth, statsBar(stats.Weekdays[day], busiest), stats.Weekdays[day])
+	}
+	fmt.Fprintf(&b, "  Busiest hours  %s (in the authors' time zones)\n", busiestHours(stats.Hours))
+
+	hotspots := stats.Hotspots[:min(statsTop, len(stats.Hotspots))]
+	fmt.Fprintf(&b, "\nHotspots (most changed files, top %d)\n", len(hotspots))
+	fmt.Fprintf(&b, "  %7s  %15s  %7s  %s\n", "commits", "lines", "authors", "file")
+	for _, file := range hotspots {
+		lines := fmt.Sprintf("+%d -%d", file.Added, file.Removed)
+		fmt.Fprintf(&b, "  %7d  %15s  %7d  %s\n", file.Commits, lines, file.Authors, file.Path)
+	}
+
+	contributors := stats.Contributors[:min(statsTop, len(stats.Contributors))]
+	fmt.Fprintf(&b, "\nContributors (top %d of %d)\n", len(contributors), len(stats.Contributors))
+	fmt.Fprintf(&b, "  %7s  %6s  %15s  %5s  %-10s  %s\n", "commits", "share", "lines", "files", "last", "author")
+	for _, contributor := range contributors {
+		share := float64(contributor.Commits) * 100 / float64(stats.Commits)
+		lines := fmt.Sprintf("+%d -%d", contributor.Added, contributor.Removed)
+		fmt.Fprintf(&b, "  %7d  %5.1f%%  %15s  %5d  %-10s  %s <%s>\n", contributor.Commits, share, lines, contributor.Files,
+			contributor.Last.Format("2006-01-02"), contributor.Name, contributor.Email)
+	}
+
+	fmt.Fprintln(&b, "\nBus factor")
+	if stats.OwnedFiles == 0 {
+		fmt.Fprintln(&b, "  No changed file still exists")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "  %d: without %s, more than half of the %d file(s) changed that still exist would have no owner left\n",
+		stats.BusFactor, strings.Join(stats.BusFactorAuthors, ", "), stats.OwnedFiles)
+	fmt.Fprintf(&b, "  %d of those file(s) (%.0f%%) were changed by a single author\n",
+		stats.SingleAuthorFiles, float64(stats.SingleAuthorFiles)*100/float64(stats.OwnedFiles))
+	return b.String()
+}
+
+// statsBar draws n as a bar, scaled so that busiest fills statsBarWidth
+func statsBar(n, busiest int) string {
+	if n == 0 || busiest == 0 {
+		return ""
+	}
+	return strings.Repeat("█", max(1, n*statsBarWidth/busiest))
+}
+
+// busiestHours describes the three hours of the day with the most commits
+func busiestHours(hours [24]int) string {
+	var parts []string
+	used := make(map[int]bool)
+	for len(parts) < 3 {
+		best := -1
+		for hour, n := range hours {
+			if n > 0 && !used[hour] && (best < 0 || n > hours[best]) {
+				best = hour
+			}
+		}
+		if best < 0 {
+			break
+		}
+		used[best] = true
+		parts = append(parts, fmt.Sprintf("%02d:00-%02d:00 (%d)", best, (best+1)%24, hours[best]))
+	}
+	return strings.Join(parts, ", ")
+}
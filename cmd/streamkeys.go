@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/hunkim/sgit/pkg/i18n"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/muesli/cancelreader"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+var (
+	// errRegenerate aborts a streaming generation to start it again
+	errRegenerate = errors.New("regeneration requested")
+	// errGenerationCancelled is returned when the user stops a streaming generation
+	errGenerationCancelled = errors.New("generation cancelled")
+)
+
+// streamKeysHinted is set once the keys were explained, so retries don't repeat it
+var streamKeysHinted bool
+
+// streamKeysActive is set while the terminal is in raw mode to read keys during a
+// stream; a newline doesn't return the cursor to the first column then
+var streamKeysActive bool
+
+// streamKeysEnabled reports whether keys can be read while output streams: stdin and
+// stdout are a terminal and nobody asked sgit to run unattended. With --show-prompt
+// nothing streams, and the prompt must print with the terminal in its normal mode.
+func streamKeysEnabled() bool {
+	return !assumeYes && !quiet && !showPrompt && term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// streamWithKeys runs generate with keys the user can press while its output streams:
+// r aborts the generation and starts it again, calling onRegenerate first, and q, Esc
+// or Ctrl+C stop it with errGenerationCancelled. Without a terminal generate simply
+// runs with ctx.
+func streamWithKeys(ctx context.Context, generate func(ctx context.Context) (string, error), onRegenerate func()) (string, error) {
+	if !streamKeysEnabled() {
+		return generate(ctx)
+	}
+
+	if !streamKeysHinted {
+		statusln(i18n.T("⌨️  Press r to regenerate or q to cancel while the message streams"))
+		streamKeysHinted = true
+	}
+	for {
+		attemptCtx, cancel := context.WithCancelCause(ctx)
+		stop, err := watchStreamKeys(cancel)
+		if err != nil {
+			// Keys can't be read from this terminal; generate without them
+			cancel(nil)
+			return generate(ctx)
+		}
+		message, err := generate(attemptCtx)
+		stop()
+		cause := context.Cause(attemptCtx)
+		cancel(nil)
+
+		switch {
+		case errors.Is(cause, errGenerationCancelled):
+			statusln("")
+			return "", errGenerationCancelled
+		case errors.Is(cause, errRegenerate) && ctx.Err() == nil:
+			statusln(i18n.T("\n\n🔄 Regenerating..."))
+			if onRegenerate != nil {
+				onRegenerate()
+			}
+			continue
+		}
+		return message, err
+	}
+}
+
+// watchStreamKeys puts the terminal in raw mode and reads key presses, cancelling the
+// generation with errRegenerate for r and errGenerationCancelled for q, Esc or Ctrl+C.
+// stop restores the terminal; nothing is read from stdin after it returns, so later
+// prompts get all the input.
+func watchStreamKeys(cancel context.CancelCauseFunc) (stop func(), err error) {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := cancelreader.NewReader(os.Stdin)
+	if err != nil {
+		_ = term.Restore(fd, state)
+		return nil, err
+	}
+	streamKeysActive = true
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 16)
+		for {
+			n, err := reader.Read(buf)
+			if err != nil {
+				return
+			}
+			// A lone Esc is the key; longer sequences starting with it are arrows and such
+			if n == 1 && buf[0] == 0x1b {
+				cancel(errGenerationCancelled)
+				continue
+			}
+			for _, key := range buf[:n] {
+				switch key {
+				case 'r', 'R':
+					cancel(errRegenerate)
+				case 'q', 'Q', 0x03:
+					cancel(errGenerationCancelled)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		reader.Cancel()
+		<-done
+		reader.Close()
+		streamKeysActive = false
+		_ = term.Restore(fd, state)
+	}, nil
+}
+
+// useRegenerateSettings switches client to the settings for messages the user asked
+// to regenerate: a fresh response rather than the cached one, and the model and
+// temperature of regenerate.model and regenerate.temperature when they are set
+func useRegenerateSettings(client *solar.Client) {
+	client.SetCache(nil)
+	if model := viper.GetString("regenerate.model"); model != "" {
+		client.SetModel(model)
+		client.SetContextWindow(modelContextWindow(model))
+	}
+	if viper.IsSet("regenerate.temperature") {
+		options := generationOptions()
+		temperature := viper.GetFloat64("regenerate.temperature")
+		options.Temperature = &temperature
+		client.SetGenerationOptions(options)
+	}
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var suggestTestsFramework string
+
+// testFrameworks maps file extensions to the language and test framework tests for
+// them are written in
+var testFrameworks = map[string]string{
+	".go":    "Go: the standard testing package, table-driven tests in <name>_test.go",
+	".py":    "Python: pytest",
+	".js":    "JavaScript: Jest",
+	".jsx":   "JavaScript (React): Jest with React Testing Library",
+	".mjs":   "JavaScript: Jest",
+	".ts":    "TypeScript: Jest",
+	".tsx":   "TypeScript (React): Jest with React Testing Library",
+	".java":  "Java: JUnit 5",
+	".kt":    "Kotlin: JUnit 5",
+	".scala": "Scala: ScalaTest",
+	".rs":    "Rust: #[test] functions in a #[cfg(test)] module",
+	".rb":    "Ruby: RSpec",
+	".php":   "PHP: PHPUnit",
+	".cs":    "C#: xUnit",
+	".swift": "Swift: XCTest",
+	".c":     "C: a unit test framework such as Unity or CMocka",
+	".cc":    "C++: GoogleTest",
+	".cpp":   "C++: GoogleTest",
+	".hpp":   "C++: GoogleTest",
+	".ex":    "Elixir: ExUnit",
+	".exs":   "Elixir: ExUnit",
+	".dart":  "Dart: package:test",
+}
+
+// maxTestFileExamples caps how many existing test files are listed in the prompt
+const maxTestFileExamples = 15
+
+// suggestTestsCmd suggests tests for the staged changes or a commit
+var suggestTestsCmd = &cobra.Command{
+	Use:   "suggest-tests [<commit>]",
+	Short: "Suggest test cases and skeleton tests for your changes",
+	Long: `Analyze the staged changes, or a commit, and have Solar LLM suggest concrete
+test cases and skeleton test functions for the changed code paths.
+
+Tests are written in the test framework of the changed files' language (Go's testing
+package, pytest, Jest, JUnit, ...) and follow the conventions of the existing test
+files next to them. Use --framework to pick another framework.
+
+'sgit commit --suggest-tests' runs this for the new commit once it is made.
+
+Examples:
+  sgit suggest-tests
+  sgit suggest-tests HEAD
+  sgit suggest-tests --framework vitest`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSuggestTests(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(suggestTestsCmd)
+
+	suggestTestsCmd.Flags().StringVar(&suggestTestsFramework, "framework", "", "test framework to write the tests in (default: detected from the changed files)")
+}
+
+func runSuggestTests(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	revision := ""
+	if len(args) == 1 {
+		revision = args[0]
+	}
+	return suggestTests(cmd.Context(), revision)
+}
+
+// suggestTests streams test suggestions for a commit, or for the staged changes when
+// revision is empty
+func suggestTests(ctx context.Context, revision string) error {
+	var diff, files string
+	var err error
+	if revision == "" {
+		diff, err = runGitOutput("diff", "--cached")
+		if err == nil {
+			files, err = runGitOutput("diff", "--cached", "--name-only")
+		}
+	} else {
+		diff, err = runGitOutput("show", "--format=", revision)
+		if err == nil {
+			files, err = runGitOutput("show", "--format=", "--name-only", revision)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error getting git diff: %w", err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		if revision == "" {
+			fmt.Println("No staged changes - stage files with 'sgit add' or pass a commit")
+		} else {
+			fmt.Printf("%s has no changes\n", revision)
+		}
+		return nil
+	}
+
+	changed := strings.Fields(files)
+	frameworks := suggestTestsFramework
+	if frameworks == "" {
+		frameworks = detectTestFrameworks(changed)
+	}
+	testFiles := findRelatedTestFiles(changed)
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	if revision == "" {
+		statusln("Suggesting tests for the staged changes with Solar LLM...")
+	} else {
+		statusf("Suggesting tests for %s with Solar LLM...\n", revision)
+	}
+	printContentStats("Content analysis", diff, testFiles)
+	if diff, err = checkDiffSize(diff); err != nil {
+		return err
+	}
+	printer := newStreamPrinter("")
+	_, err = client.SuggestTests(ctx, diff, frameworks, testFiles, printer.Write)
+	printer.Done()
+	if err != nil {
+		return fmt.Errorf("error suggesting tests: %w", err)
+	}
+	fmt.Println() // Add newline after streaming output
+	return nil
+}
+
+// detectTestFrameworks describes the test framework of each language among the
+// changed files, one per line
+func detectTestFrameworks(files []string) string {
+	seen := make(map[string]bool)
+	var frameworks []string
+	for _, file := range files {
+		framework, ok := testFrameworks[strings.ToLower(path.Ext(file))]
+		if !ok || seen[framework] {
+			continue
+		}
+		seen[framework] = true
+		frameworks = append(frameworks, framework)
+	}
+	sort.Strings(frameworks)
+	return strings.Join(frameworks, "\n")
+}
+
+// isTestFile reports whether a path looks like a test file in one of the common
+// naming schemes
+func isTestFile(file string) bool {
+	base := path.Base(file)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	switch {
+	case strings.HasSuffix(stem, "_test"), strings.HasSuffix(stem, "_spec"),
+		strings.HasSuffix(stem, ".test"), strings.HasSuffix(stem, ".spec"),
+		strings.HasPrefix(stem, "test_"),
+		strings.HasSuffix(stem, "Test"), strings.HasSuffix(stem, "Tests"):
+		return true
+	}
+	for _, dir := range strings.Split(path.Dir(file), "/") {
+		if dir == "test" || dir == "tests" || dir == "__tests__" || dir == "spec" {
+			return true
+		}
+	}
+	return false
+}
+
+// findRelatedTestFiles lists tracked test files for the changed files: those named
+// after a changed file or next to one first, then others in the same languages as
+// examples of the project's conventions
+func findRelatedTestFiles(changed []string) string {
+	tracked, err := runGitOutput("ls-files")
+	if err != nil {
+		return ""
+	}
+
+	stems := make(map[string]bool)
+	dirs := make(map[string]bool)
+	exts := make(map[string]bool)
+	for _, file := range changed {
+		base := path.Base(file)
+		stems[strings.TrimSuffix(base, path.Ext(base))] = true
+		dirs[path.Dir(file)] = true
+		exts[path.Ext(file)] = true
+	}
+
+	var related, examples []string
+	for _, file := range strings.Split(strings.TrimSpace(tracked), "\n") {
+		if file == "" || !isTestFile(file) || !exts[path.Ext(file)] {
+			continue
+		}
+		if dirs[path.Dir(file)] || stems[testSubject(file)] {
+			related = append(related, file)
+		} else {
+			examples = append(examples, file)
+		}
+	}
+
+	files := append(related, examples...)
+	if len(files) > maxTestFileExamples {
+		files = files[:maxTestFileExamples]
+	}
+	return strings.Join(files, "\n")
+}
+
+// testSubject returns the name of the file a test file tests, e.g. "parser" for
+// parser_test.go, test_parser.py or parser.spec.ts
+func testSubject(file string) string {
+	base := path.Base(file)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	for _, suffix := range []string{"_test", "_spec", ".test", ".spec", "Tests", "Test"} {
+		stem = strings.TrimSuffix(stem, suffix)
+	}
+	return strings.TrimPrefix(stem, "test_")
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/cobra"
+)
+
+var (
+	summaryFormat        string
+	summarySince         string
+	summaryUntil         string
+	summaryAuthor        string
+	summaryCurrentBranch bool
+	summaryOutput        string
+)
+
+// summaryDefaultSince is the period each format covers when --since isn't given
+var summaryDefaultSince = map[string]string{
+	"standup":     "yesterday",
+	"weekly":      "1 week ago",
+	"perf-review": "6 months ago",
+}
+
+// summaryCmd turns one author's recent commits into a standup update or report
+var summaryCmd = &cobra.Command{
+	Use:   "summary [--since <date>] [--format standup|weekly|perf-review]",
+	Short: "Summarize your recent commits as a standup update or report",
+	Long: `Gather your commits over a period, across all branches, and have Solar LLM
+write them up in markdown: a standup update, a weekly report, or a self-review for
+a performance review.
+
+Commits are matched by your git user.email unless --author is given. --since and
+--until take any date git understands ("last week", "2 weeks ago", "2024-01-01");
+without --since, standup covers the last day, weekly the last week and perf-review
+the last six months.
+
+Examples:
+  sgit summary
+  sgit summary --since "last week" --format weekly
+  sgit summary --format perf-review --since 2024-01-01 -o review.md
+  sgit summary --author alice@example.com --current-branch`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSummary(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(summaryCmd)
+
+	summaryCmd.Flags().StringVar(&summaryFormat, "format", "standup", "report format (standup|weekly|perf-review)")
+	summaryCmd.Flags().StringVar(&summarySince, "since", "", "start of the period (default depends on --format)")
+	summaryCmd.Flags().StringVar(&summaryUntil, "until", "", "end of the period (default now)")
+	summaryCmd.Flags().StringVar(&summaryAuthor, "author", "", "author to summarize (default your git user.email)")
+	summaryCmd.Flags().BoolVar(&summaryCurrentBranch, "current-branch", false, "only include commits reachable from HEAD")
+	summaryCmd.Flags().StringVarP(&summaryOutput, "output", "o", "", "also write the report to a file")
+}
+
+func runSummary(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	formatInstructions, ok := solar.WorkSummaryFormats[summaryFormat]
+	if !ok {
+		names := make([]string, 0, len(solar.WorkSummaryFormats))
+		for name := range solar.WorkSummaryFormats {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown summary format '%s' (use %s)", summaryFormat, strings.Join(names, ", "))
+	}
+
+	author := summaryAuthor
+	if author == "" {
+		author = strings.TrimSpace(gitConfigValue("user.email"))
+		if author == "" {
+			author = strings.TrimSpace(gitConfigValue("user.name"))
+		}
+		if author == "" {
+			return fmt.Errorf("no git user.email configured; pass --author")
+		}
+	}
+
+	since := summarySince
+	if since == "" {
+		since = summaryDefaultSince[summaryFormat]
+	}
+	period := "since " + since
+	if summaryUntil != "" {
+		period += " until " + summaryUntil
+	}
+
+	commits, count, err := getAuthorCommits(author, since, summaryUntil, !summaryCurrentBranch)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		fmt.Printf("No commits by %s %s\n", author, period)
+		return nil
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	statusf("Summarizing %d commit(s) by %s %s with Solar LLM...\n", count, author, period)
+	renderer, err := outputRenderer("")
+	if err != nil {
+		return err
+	}
+	printContentStats("Commit history", commits)
+	printer := newStreamPrinter("")
+	printer.renderer = renderer
+	report, err := client.SummarizeWork(cmd.Context(), author, period, commits, formatInstructions, printer.Write)
+	printer.Done()
+	if err != nil {
+		return fmt.Errorf("error generating summary: %w", err)
+	}
+	fmt.Println() // Add newline after streaming output
+
+	if summaryOutput != "" {
+		if err := os.WriteFile(summaryOutput, []byte(strings.TrimSpace(report)+"\n"), 0644); err != nil {
+			return fmt.Errorf("error writing %s: %v", summaryOutput, err)
+		}
+		fmt.Printf("✅ Wrote %s\n", summaryOutput)
+	}
+	return nil
+}
+
+// getAuthorCommits returns an author's non-merge commits in a period, oldest first,
+// with the branch each was found on and its size, and how many there are.
+// allBranches includes every local and remote branch instead of just HEAD.
+func getAuthorCommits(author, since, until string, allBranches bool) (string, int, error) {
+	logArgs := []string{"log", "--no-merges", "--reverse", "--date=short", "--shortstat",
+		"--author=" + author, "--since=" + since,
+		"--format=%x1e%ad [%S] %h %s%n%b"}
+	if until != "" {
+		logArgs = append(logArgs, "--until="+until)
+	}
+	if allBranches {
+		logArgs = append(logArgs, "--all", "--source")
+	} else {
+		// %S is only filled in with --source
+		logArgs = append(logArgs, "--source", "HEAD")
+	}
+
+	output, err := runGitOutput(logArgs...)
+	if err != nil {
+		return "", 0, fmt.Errorf("error getting commits: %w", err)
+	}
+
+	var commits []string
+	for _, entry := range strings.Split(output, "\x1e") {
+		// Drop the blank lines around bodies and the shortstat, and the refs/ prefixes
+		var lines []string
+		for _, line := range strings.Split(entry, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		lines[0] = strings.NewReplacer("[refs/heads/", "[", "[refs/remotes/", "[", "[refs/tags/", "[").Replace(lines[0])
+		commits = append(commits, strings.Join(lines, "\n"))
+	}
+	return strings.Join(commits, "\n\n"), len(commits), nil
+}
+
+// gitConfigValue returns a git config value, or "" when it isn't set
+func gitConfigValue(key string) string {
+	value, err := runGitOutput("config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return value
+}
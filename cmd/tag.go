@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// tagValueFlags are git tag options that take a separate value argument
+var tagValueFlags = map[string]bool{
+	"-u":            true,
+	"--local-user":  true,
+	"-m":            true,
+	"--message":     true,
+	"-F":            true,
+	"--file":        true,
+	"--cleanup":     true,
+	"--sort":        true,
+	"--format":      true,
+	"--contains":    true,
+	"--no-contains": true,
+	"--points-at":   true,
+	"--merged":      true,
+	"--no-merged":   true,
+}
+
+// tagCmd wraps git tag, adding AI-generated release notes with --ai-notes
+var tagCmd = &cobra.Command{
+	Use:   "tag [--ai-notes [--changelog]] [git tag options] <tagname> [<commit>]",
+	Short: "Create, list, or delete tags (with AI release notes via --ai-notes)",
+	Long: `Passthrough to git tag. With --ai-notes, the commits since the previous tag are
+summarized into release notes by Solar LLM and used as the annotated tag message.
+The notes use the same format as 'sgit changelog' (see changelog_template), and
+--changelog also prepends them to CHANGELOG.md.
+
+  sgit tag v1.4.0 --ai-notes
+  sgit tag v1.4.0 --ai-notes --changelog -s`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runTag(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+	DisableFlagParsing: true,
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+}
+
+func runTag(cmd *cobra.Command, args []string) error {
+	// Flag parsing is disabled so every git tag option passes through; pick out ours
+	aiNotes, writeChangelog := false, false
+	var gitArgs []string
+	for _, arg := range extractGlobalFlags(args) {
+		switch arg {
+		case "--ai-notes":
+			aiNotes = true
+		case "--changelog":
+			writeChangelog = true
+		default:
+			gitArgs = append(gitArgs, arg)
+		}
+	}
+
+	if !aiNotes {
+		executeGitCommand(append([]string{"tag"}, gitArgs...))
+		return nil
+	}
+
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	var positional []string
+	for i := 0; i < len(gitArgs); i++ {
+		arg := gitArgs[i]
+		name, _, _ := strings.Cut(arg, "=")
+		switch {
+		case name == "-m" || name == "--message" || name == "-F" || name == "--file" || strings.HasPrefix(arg, "-m") && len(arg) > 2:
+			return fmt.Errorf("--ai-notes generates the tag message, don't combine it with -m or -F")
+		case tagValueFlags[arg]:
+			i++
+		case !strings.HasPrefix(arg, "-"):
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) == 0 {
+		return fmt.Errorf("specify the tag name, e.g. sgit tag v1.2.0 --ai-notes")
+	}
+
+	tag := positional[0]
+	target := "HEAD"
+	if len(positional) > 1 {
+		target = positional[1]
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+
+	notes, err := generateChangelogEntry(cmd.Context(), releaseRange(tag, target), tag)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\n=== TAG NOTES ===")
+	fmt.Println(notes)
+	fmt.Println()
+
+	if !confirm(fmt.Sprintf("Create annotated tag %s with these notes? (y/n): ", tag)) {
+		fmt.Println("Tag creation cancelled")
+		return nil
+	}
+
+	// Subject is the tag name, body the notes; verbatim keeps the markdown headings
+	notesFile, err := ioutil.TempFile(os.TempDir(), "sgit-tag-*.md")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(notesFile.Name())
+
+	if _, err := notesFile.WriteString(tag + "\n\n" + strings.TrimSpace(notes) + "\n"); err != nil {
+		notesFile.Close()
+		return fmt.Errorf("failed to write to temp file: %w", err)
+	}
+	notesFile.Close()
+
+	gitCmd := exec.Command("git", append([]string{"tag", "-a", "--cleanup=verbatim", "-F", notesFile.Name()}, gitArgs...)...)
+	gitCmd.Stdin = os.Stdin
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	if err := gitCmd.Run(); err != nil {
+		return fmt.Errorf("error creating tag: %w", err)
+	}
+	fmt.Printf("✅ Created annotated tag %s\n", tag)
+
+	if writeChangelog {
+		if err := prependChangelog(changelogFile, notes); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Updated %s\n", changelogFile)
+	}
+
+	fmt.Printf("💡 Publish it with 'git push origin %s' and 'sgit release %s'\n", tag, tag)
+	return nil
+}
+
+// tagNotes returns the release notes stored in an annotated tag's message (without the
+// subject line and any signature), or "" for lightweight tags
+func tagNotes(tag string) string {
+	output, err := runGitOutput("tag", "-l", "--format=%(contents:body)", tag)
+	if err != nil {
+		return ""
+	}
+	if idx := strings.Index(output, "-----BEGIN PGP SIGNATURE-----"); idx != -1 {
+		output = output[:idx]
+	}
+	if idx := strings.Index(output, "-----BEGIN SSH SIGNATURE-----"); idx != -1 {
+		output = output[:idx]
+	}
+	return strings.TrimSpace(output)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hunkim/sgit/pkg/github"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/hunkim/sgit/pkg/tracker"
+)
+
+// templateVars resolves the repository metadata templates can use, with ticketKey as
+// {{.Ticket}}
+func templateVars(ticketKey string) solar.TemplateVars {
+	vars := solar.TemplateVars{
+		RepoName: repositoryName(),
+		Ticket:   ticketKey,
+		Date:     time.Now().Format("2006-01-02"),
+	}
+	vars.Branch, _ = getCurrentBranch()
+	// GIT_AUTHOR_IDENT honours GIT_AUTHOR_NAME as git commit does
+	if ident, err := runGitOutput("var", "GIT_AUTHOR_IDENT"); err == nil {
+		if name, _, found := strings.Cut(ident, " <"); found {
+			vars.Author = strings.TrimSpace(name)
+		}
+	}
+	return vars
+}
+
+// branchTicketKey returns the ticket key in the current branch's name, or ""
+func branchTicketKey() string {
+	branch, _ := getCurrentBranch()
+	return tracker.FindTicketKey(branch)
+}
+
+// repositoryName returns the name of the repository on origin, or else of the
+// directory it is checked out in
+func repositoryName() string {
+	if remoteURL, err := runGitOutput("remote", "get-url", "origin"); err == nil {
+		if _, _, repo, err := github.ParseRemoteURL(remoteURL); err == nil {
+			return repo
+		}
+	}
+	if root, err := runGitOutput("rev-parse", "--show-toplevel"); err == nil && strings.TrimSpace(root) != "" {
+		return filepath.Base(strings.TrimSpace(root))
+	}
+	return ""
+}
+
+// expandTemplateVars fills the metadata variables into a configured text such as a
+// trailers.custom line; a text that doesn't expand is left out, with a warning
+func expandTemplateVars(setting, text string, vars solar.TemplateVars) string {
+	expanded, err := solar.ExpandTemplate(text, vars)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Leaving out %s: %v\n", setting, err)
+		return ""
+	}
+	return expanded
+}
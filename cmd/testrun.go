@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/hunkim/sgit/pkg/engine"
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/hunkim/sgit/pkg/testresult"
+	"github.com/spf13/viper"
+)
+
+// defaultTestTimeout bounds how long test_command may run, unless test_timeout is set
+const defaultTestTimeout = 10 * time.Minute
+
+// maxTestOutputLines is how much of the output of failing tests the model sees
+const maxTestOutputLines = 40
+
+// testRun is the outcome of running test_command before a commit message is generated
+type testRun struct {
+	command string
+	// ran is false when the command couldn't be run or didn't finish in time
+	ran      bool
+	result   testresult.Result
+	output   string
+	duration time.Duration
+}
+
+// summary describes the run for the commit body, e.g. "All 142 tests pass (go test ./...)."
+func (t *testRun) summary() string {
+	summary := t.result.Summary()
+	return fmt.Sprintf("%s%s (%s).", strings.ToUpper(summary[:1]), summary[1:], t.command)
+}
+
+// testCommand returns test_command from config, or "" when tests aren't run
+func testCommand() string {
+	return strings.TrimSpace(viper.GetString("test_command"))
+}
+
+// testContextProvider runs test_command and adds its result to the context, so the
+// message can say whether the change passes its tests. run, if not nil, receives the
+// outcome.
+func testContextProvider(command string, run *testRun) engine.ContextProvider {
+	if run == nil {
+		run = &testRun{}
+	}
+	return engine.NewProvider(contextTests, func(ctx context.Context, commitContext *engine.CommitContext) error {
+		*run = runTestCommand(ctx, command)
+		if !run.ran {
+			return nil
+		}
+		text := run.summary()
+		if len(run.result.Failures) > 0 {
+			text += "\nFailing: " + strings.Join(run.result.Failures, ", ")
+		}
+		if !run.result.Passed {
+			text += "\n\n" + lastLines(run.output, maxTestOutputLines)
+		}
+		commitContext.Sections = append(commitContext.Sections, solar.ContextSection{Title: "test results", Text: text})
+		return nil
+	})
+}
+
+// runTestCommand runs command in the repository root through the shell, within
+// test_timeout. A failing command is a result; one that can't be run is reported and
+// left out.
+func runTestCommand(ctx context.Context, command string) testRun {
+	run := testRun{command: command}
+	timeout := defaultTestTimeout
+	if value := viper.GetString("test_timeout"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Invalid test_timeout '%s', using %s: %v\n", value, defaultTestTimeout, err)
+		} else {
+			timeout = parsed
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	cmd := exec.CommandContext(ctx, shell, flag, command)
+	if root, err := gitRepo.Root(ctx); err == nil {
+		cmd.Dir = root
+	}
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+	start := time.Now()
+	err := cmd.Run()
+	run.duration = time.Since(start)
+	run.output = output.String()
+
+	var exitError *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		fmt.Fprintf(os.Stderr, "⚠️  Left out the test results: '%s' didn't finish within %s\n", command, timeout)
+		return run
+	case err != nil && !errors.As(err, &exitError):
+		fmt.Fprintf(os.Stderr, "⚠️  Left out the test results: %v\n", err)
+		return run
+	}
+	run.ran = true
+	run.result = testresult.Parse(run.output, err == nil)
+	return run
+}
+
+// checkTestRun reports the outcome of test_command and stops the commit when the tests
+// fail, or couldn't be run, and passing tests are required (--require-tests or
+// require_tests)
+func checkTestRun(run *testRun, required bool) error {
+	if run.command == "" {
+		return nil
+	}
+	switch {
+	case !run.ran:
+		if required {
+			return fmt.Errorf("commit blocked: '%s' didn't finish, and passing tests are required", run.command)
+		}
+	case run.result.Passed:
+		statusf("🧪 %s in %s\n", strings.TrimSuffix(run.summary(), "."), run.duration.Round(100*time.Millisecond))
+	default:
+		fmt.Printf("❌ %s\n", run.summary())
+		for _, failure := range run.result.Failures {
+			fmt.Printf("   %s\n", failure)
+		}
+		if required {
+			fmt.Println(lastLines(run.output, maxTestOutputLines))
+			return fmt.Errorf("commit blocked: the tests fail, and passing tests are required")
+		}
+	}
+	return nil
+}
+
+// appendTestSummary adds the test result to the body of message, before its trailers,
+// unless the message already states it
+func appendTestSummary(message string, run *testRun) string {
+	if run == nil || !run.ran || message == "" {
+		return message
+	}
+	summary := run.summary()
+	if strings.Contains(strings.ToLower(message), strings.ToLower(run.result.Summary())) {
+		return message
+	}
+	body, trailers := splitTrailers(message)
+	message = strings.TrimSpace(body) + "\n\n" + summary
+	if len(trailers) > 0 {
+		message += "\n\n" + strings.Join(trailers, "\n")
+	}
+	return message
+}
+
+// lastLines returns the last n lines of output
+func lastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/tracker"
+	"github.com/spf13/viper"
+)
+
+// defaultTicketFooter is the trailer token used to reference a commit's ticket
+const defaultTicketFooter = "Refs"
+
+// trailerLinePattern matches git trailer lines such as "Refs: PROJ-123"
+var trailerLinePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*: \S`)
+
+// ticketTracker returns the configured issue tracker for ticket keys, or nil when
+// none is configured. ticket_tracker (jira or linear) picks one explicitly;
+// otherwise Jira is used when jira_url is set and Linear when an API key is.
+func ticketTracker() (tracker.Tracker, error) {
+	linearKey := os.Getenv("LINEAR_API_KEY")
+	if linearKey == "" {
+		linearKey = viper.GetString("linear_api_key")
+	}
+
+	newJira := func() (tracker.Tracker, error) {
+		jiraURL := viper.GetString("jira_url")
+		if jiraURL == "" {
+			return nil, fmt.Errorf("jira_url is not configured")
+		}
+		token := os.Getenv("JIRA_API_TOKEN")
+		if token == "" {
+			token = viper.GetString("jira_token")
+		}
+		return tracker.NewJiraClient(jiraURL, viper.GetString("jira_email"), token), nil
+	}
+	newLinear := func() (tracker.Tracker, error) {
+		if linearKey == "" {
+			return nil, fmt.Errorf("no Linear API key configured (set LINEAR_API_KEY or linear_api_key)")
+		}
+		return tracker.NewLinearClient(linearKey, viper.GetString("linear_api_url")), nil
+	}
+
+	switch name := strings.ToLower(strings.TrimSpace(viper.GetString("ticket_tracker"))); name {
+	case "jira":
+		return newJira()
+	case "linear":
+		return newLinear()
+	case "":
+		if viper.GetString("jira_url") != "" {
+			return newJira()
+		}
+		if linearKey != "" {
+			return newLinear()
+		}
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown ticket_tracker '%s' (use jira or linear)", name)
+	}
+}
+
+// fetchTicket fetches a ticket by key from the configured tracker
+func fetchTicket(ctx context.Context, key string) (*tracker.Ticket, error) {
+	t, err := ticketTracker()
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("no ticket tracker configured (set jira_url, or linear_api_key / LINEAR_API_KEY)")
+	}
+	return t.GetTicket(ctx, key)
+}
+
+// resolveCommitTicket finds the ticket a commit belongs to, from key (--ticket) or
+// else the branch name, and fetches its title and description when a tracker is
+// configured. It returns nil when there is no ticket. Keys found in the branch name
+// are dropped when the tracker doesn't know them, since they may be false positives.
+func resolveCommitTicket(ctx context.Context, key string) *tracker.Ticket {
+	fromBranch := false
+	if key == "" {
+		branch, _ := getCurrentBranch()
+		key = tracker.FindTicketKey(branch)
+		fromBranch = true
+	}
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if key == "" {
+		return nil
+	}
+
+	t, err := ticketTracker()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Ticket %s: %v\n", key, err)
+		return &tracker.Ticket{Key: key}
+	}
+	if t == nil {
+		return &tracker.Ticket{Key: key}
+	}
+
+	ticket, err := t.GetTicket(ctx, key)
+	if err != nil {
+		if fromBranch {
+			fmt.Fprintf(os.Stderr, "⚠️  Ignoring %s from the branch name: %v\n", key, err)
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "⚠️  Could not fetch ticket %s: %v\n", key, err)
+		return &tracker.Ticket{Key: key}
+	}
+	return ticket
+}
+
+// appendTicketFooter adds a "Refs: KEY" trailer (token set by ticket_footer) to a
+// commit message unless its trailers already mention the key
+func appendTicketFooter(message, key string) string {
+	message = strings.TrimSpace(message)
+	if key == "" || message == "" {
+		return message
+	}
+
+	token := viper.GetString("ticket_footer")
+	if token == "" {
+		token = defaultTicketFooter
+	}
+	footer := fmt.Sprintf("%s: %s", token, key)
+
+	paragraphs := strings.Split(message, "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+	isTrailerBlock := len(paragraphs) > 1
+	for _, line := range strings.Split(last, "\n") {
+		if !trailerLinePattern.MatchString(line) {
+			isTrailerBlock = false
+			break
+		}
+	}
+
+	if isTrailerBlock {
+		if strings.Contains(last, key) {
+			return message
+		}
+		return message + "\n" + footer
+	}
+	return message + "\n\n" + footer
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/solar"
+	"github.com/spf13/viper"
+)
+
+// Trailer tokens sgit adds itself. The AI is given no names or emails to fill them in
+// with, so any it writes are invented and removed.
+const (
+	signedOffByToken  = "Signed-off-by"
+	coAuthoredByToken = "Co-authored-by"
+)
+
+// maxTemplateScanned bounds the commit.template file read for co-authors
+const maxTemplateScanned = 64 * 1024
+
+// identityPattern keeps "Name <email>" from the output of git var GIT_COMMITTER_IDENT
+var identityPattern = regexp.MustCompile(`^(.*?<[^>]*>)`)
+
+// applyTrailers finishes an AI-generated commit message with the configured trailers:
+// the ticket reference, a DCO sign-off (trailers.signoff), co-authors (trailers.co_authors
+// plus those found in git-mob and commit template config) and trailers.custom lines.
+// Signed-off-by and Co-authored-by lines the AI wrote are dropped unless they are in
+// original, the message being amended.
+func applyTrailers(message, ticketKey, original string) string {
+	message = appendTicketFooter(message, ticketKey)
+	if message == "" {
+		return message
+	}
+
+	body, trailers := splitTrailers(message)
+	_, originalTrailers := splitTrailers(strings.TrimSpace(original))
+	var kept []string
+	for _, trailer := range trailers {
+		token, _, _ := strings.Cut(trailer, ":")
+		managed := strings.EqualFold(token, signedOffByToken) || strings.EqualFold(token, coAuthoredByToken)
+		if managed && !containsTrailer(originalTrailers, trailer) {
+			continue
+		}
+		kept = append(kept, trailer)
+	}
+
+	for _, trailer := range configuredTrailers(ticketKey) {
+		if !containsTrailer(kept, trailer) {
+			kept = append(kept, trailer)
+		}
+	}
+
+	if len(kept) == 0 {
+		return body
+	}
+	return body + "\n\n" + strings.Join(kept, "\n")
+}
+
+// configuredTrailers lists the trailers config asks for, in the order they are added.
+// trailers.custom lines can use the template variables, e.g. "Ticket: {{.Ticket}}"
+// with ticketKey; a line left without a value is skipped.
+func configuredTrailers(ticketKey string) []string {
+	var trailers []string
+	for _, author := range coAuthors() {
+		trailers = append(trailers, coAuthoredByToken+": "+author)
+	}
+	var vars *solar.TemplateVars
+	for _, line := range viper.GetStringSlice("trailers.custom") {
+		if strings.Contains(line, "{{") {
+			if vars == nil {
+				resolved := templateVars(ticketKey)
+				vars = &resolved
+			}
+			line = expandTemplateVars("trailers.custom", line, *vars)
+		}
+		if line = strings.TrimSpace(line); trailerLinePattern.MatchString(line) {
+			trailers = append(trailers, line)
+		}
+	}
+	// The sign-off goes last, as git commit --signoff puts it
+	if viper.GetBool("trailers.signoff") {
+		if ident, err := runGitOutput("var", "GIT_COMMITTER_IDENT"); err == nil {
+			if match := identityPattern.FindStringSubmatch(strings.TrimSpace(ident)); match != nil {
+				trailers = append(trailers, signedOffByToken+": "+match[1])
+			}
+		}
+	}
+	return trailers
+}
+
+// coAuthors returns the pair programming partners of the commit: trailers.co_authors,
+// git-mob's git-mob.co-author entries and Co-authored-by lines in the commit.template
+// file. Detection is skipped with trailers.detect_co_authors: false.
+func coAuthors() []string {
+	authors := viper.GetStringSlice("trailers.co_authors")
+	if viper.IsSet("trailers.detect_co_authors") && !viper.GetBool("trailers.detect_co_authors") {
+		return authors
+	}
+
+	if output, err := runGitOutput("config", "--get-all", "git-mob.co-author"); err == nil {
+		for _, author := range strings.Split(output, "\n") {
+			authors = append(authors, author)
+		}
+	}
+	if path, err := runGitOutput("config", "--path", "commit.template"); err == nil && strings.TrimSpace(path) != "" {
+		if content, err := os.ReadFile(expandHome(strings.TrimSpace(path))); err == nil && len(content) <= maxTemplateScanned {
+			for _, line := range strings.Split(string(content), "\n") {
+				token, author, found := strings.Cut(strings.TrimSpace(line), ":")
+				if found && strings.EqualFold(token, coAuthoredByToken) {
+					authors = append(authors, author)
+				}
+			}
+		}
+	}
+
+	var unique []string
+	seen := make(map[string]bool)
+	for _, author := range authors {
+		author = strings.TrimSpace(author)
+		if author == "" || seen[strings.ToLower(author)] {
+			continue
+		}
+		seen[strings.ToLower(author)] = true
+		unique = append(unique, author)
+	}
+	return unique
+}
+
+// splitTrailers separates a message's trailer block (its last paragraph, when every
+// line of it is a trailer) from the rest
+func splitTrailers(message string) (string, []string) {
+	paragraphs := strings.Split(message, "\n\n")
+	if len(paragraphs) < 2 {
+		return message, nil
+	}
+	last := strings.Split(strings.TrimSpace(paragraphs[len(paragraphs)-1]), "\n")
+	for _, line := range last {
+		if !trailerLinePattern.MatchString(line) {
+			return message, nil
+		}
+	}
+	return strings.TrimSpace(strings.Join(paragraphs[:len(paragraphs)-1], "\n\n")), last
+}
+
+// containsTrailer reports whether trailers has the line, ignoring case and spacing
+func containsTrailer(trailers []string, line string) bool {
+	normalize := func(trailer string) string {
+		return strings.ToLower(strings.Join(strings.Fields(trailer), " "))
+	}
+	for _, trailer := range trailers {
+		if normalize(trailer) == normalize(line) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,360 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// undoReflogDepth is how many reflog entries are read to understand the last operation
+const undoReflogDepth = 20
+
+var (
+	undoNoAI   bool
+	undoDryRun bool
+)
+
+// undoCmd figures out the last git operation from the reflog and undoes it safely
+var undoCmd = &cobra.Command{
+	Use:   "undo [--dry-run] [--no-ai]",
+	Short: "Undo the last git operation safely, with an AI explanation",
+	Long: `Read the reflog to work out what the last operation was (commit, amend, merge,
+pull, reset, rebase, checkout, cherry-pick or revert) and list the ways to undo it:
+reset --soft to keep the changes, revert for commits that are already pushed, and so
+on. Solar LLM explains what happened and what each undo changes, and picks the
+safest option. Nothing runs without confirmation; with --yes, only a revert or a
+soft reset runs.
+
+Operations that stopped halfway (a merge or rebase with conflicts) are aborted
+instead. Uncommitted changes discarded by reset --hard can't be brought back.
+
+Examples:
+  sgit undo
+  sgit undo --dry-run
+  sgit undo --no-ai --yes`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runUndo(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+
+	undoCmd.Flags().BoolVar(&undoNoAI, "no-ai", false, "choose the undo without an AI explanation")
+	undoCmd.Flags().BoolVarP(&undoDryRun, "dry-run", "n", false, "explain the undo without running it")
+}
+
+// reflogEntry is one entry of git reflog: the commit HEAD pointed to and what moved it
+type reflogEntry struct {
+	sha     string
+	subject string
+	// when and selector (e.g. HEAD@{3}) are read by sgit reflog --ai
+	when     string
+	selector string
+}
+
+// undoOption is one way to undo an operation
+type undoOption struct {
+	args        []string
+	description string
+}
+
+func (o undoOption) command() string {
+	return "git " + strings.Join(o.args, " ")
+}
+
+// keepsWork reports whether the option neither rewrites history nor discards changes:
+// a revert, or a soft reset that keeps the undone changes staged
+func (o undoOption) keepsWork() bool {
+	return o.args[0] == "revert" || len(o.args) > 1 && o.args[0] == "reset" && o.args[1] == "--soft"
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	output, err := runGitOutput("reflog", "-n", strconv.Itoa(undoReflogDepth), "--format=%h%x00%gs")
+	if err != nil {
+		return fmt.Errorf("error reading the reflog: %w", err)
+	}
+	var entries []reflogEntry
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if sha, subject, ok := strings.Cut(line, "\x00"); ok {
+			entries = append(entries, reflogEntry{sha: sha, subject: subject})
+		}
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("the reflog is empty; there is nothing to undo")
+	}
+
+	operation, options := planUndo(entries)
+	if len(options) == 0 {
+		return fmt.Errorf("don't know how to undo '%s'", entries[0].subject)
+	}
+
+	fmt.Printf("🔎 Last operation: %s\n\nUndo options:\n", operation)
+	for i, option := range options {
+		fmt.Printf("  %d. %s\n     %s\n", i+1, option.command(), option.description)
+	}
+	fmt.Println()
+
+	choice := 0
+	if useAIFor("undo", true, false, undoNoAI) {
+		picked, ok, err := explainUndo(cmd, operation, entries, options)
+		if err != nil {
+			return err
+		}
+		if ok {
+			choice = picked
+		}
+	}
+
+	if undoDryRun {
+		fmt.Printf("💡 Dry run: would run %s\n", options[choice].command())
+		return nil
+	}
+
+	// With --yes only an undo that keeps both history and changes runs unattended
+	if assumeYes {
+		if !options[choice].keepsWork() {
+			return fmt.Errorf("not running %s without confirmation; run sgit undo without --yes to choose", options[choice].command())
+		}
+		statusf("Running option %d (--yes)\n", choice+1)
+	} else {
+		answer := strings.ToLower(ask(fmt.Sprintf("Run option %d (%s)? (y, another number, or Enter = none): ", choice+1, options[choice].command())))
+		switch {
+		case answer == "y" || answer == "yes":
+		case answer == "" || answer == "n" || answer == "no" || answer == "q":
+			fmt.Println("Nothing changed")
+			return nil
+		default:
+			n, err := strconv.Atoi(answer)
+			if err != nil || n < 1 || n > len(options) {
+				return fmt.Errorf("invalid answer '%s' (use 1-%d)", answer, len(options))
+			}
+			choice = n - 1
+		}
+	}
+
+	gitCmd := exec.Command("git", options[choice].args...)
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	if err := gitCmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %v", options[choice].command(), err)
+	}
+	fmt.Printf("✅ Ran %s\n", options[choice].command())
+	return nil
+}
+
+// explainUndo asks the model to explain the operation and pick an option. It reports
+// the chosen option's index, or false when the answer couldn't be used; the only
+// error is solar.ErrDryRun.
+func explainUndo(cmd *cobra.Command, operation string, entries []reflogEntry, options []undoOption) (int, bool, error) {
+	if err := ensureConfiguration(); err != nil {
+		fmt.Printf("⚠️  %v; choosing without AI\n", err)
+		return 0, false, nil
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		fmt.Printf("⚠️  %v; choosing without AI\n", err)
+		return 0, false, nil
+	}
+
+	var reflog strings.Builder
+	for i, entry := range entries {
+		fmt.Fprintf(&reflog, "HEAD@{%d} %s %s\n", i, entry.sha, entry.subject)
+	}
+	var listing strings.Builder
+	for i, option := range options {
+		fmt.Fprintf(&listing, "%d. %s: %s\n", i+1, option.command(), option.description)
+	}
+
+	statusf("Explaining the undo with Solar LLM...\n")
+	response, err := client.ExplainUndo(cmd.Context(), operation, reflog.String(), describeUndoState(), listing.String())
+	if isDryRun(err) {
+		return 0, false, err
+	}
+	if err != nil {
+		fmt.Printf("⚠️  Could not get an AI explanation: %v\n", err)
+		return 0, false, nil
+	}
+
+	choice := -1
+	var explanation []string
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "*"))
+		label, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToUpper(strings.TrimSpace(label)) {
+		case "WHAT HAPPENED":
+			explanation = append(explanation, "📖 "+value)
+		case "CONSEQUENCES":
+			explanation = append(explanation, "⚖️  "+value)
+		case "WHY":
+			explanation = append(explanation, "💡 "+value)
+		case "CHOICE":
+			if n, err := strconv.Atoi(strings.Trim(value, ". ")); err == nil && n >= 1 && n <= len(options) {
+				choice = n - 1
+			}
+		}
+	}
+
+	fmt.Println(strings.Join(explanation, "\n"))
+	fmt.Println()
+	if choice < 0 {
+		return 0, false, nil
+	}
+	return choice, true, nil
+}
+
+// planUndo reads the last operation from the reflog (newest entry first) and returns
+// a description of it with the ways to undo it, safest first
+func planUndo(entries []reflogEntry) (string, []undoOption) {
+	// An operation that stopped on conflicts is undone by aborting it
+	for _, inProgress := range []struct{ ref, name string }{
+		{"MERGE_HEAD", "merge"}, {"CHERRY_PICK_HEAD", "cherry-pick"}, {"REVERT_HEAD", "revert"},
+	} {
+		if _, err := runGitOutput("rev-parse", "--verify", "--quiet", inProgress.ref); err == nil {
+			return fmt.Sprintf("a %s that stopped with conflicts", inProgress.name), []undoOption{
+				{[]string{inProgress.name, "--abort"}, fmt.Sprintf("abort the %s and return to the state before it", inProgress.name)},
+			}
+		}
+	}
+	if isRebaseInProgress() {
+		return "a rebase that stopped partway", []undoOption{
+			{[]string{"rebase", "--abort"}, "abort the rebase and return the branch to where it was"},
+		}
+	}
+
+	last := entries[0]
+	action, detail, _ := strings.Cut(last.subject, ": ")
+	var previous string
+	if len(entries) > 1 {
+		previous = entries[1].sha
+	}
+	pushed := isHeadPushed()
+
+	// reset --keep refuses to overwrite uncommitted changes, unlike reset --hard
+	moveBack := func(description string) undoOption {
+		return undoOption{[]string{"reset", "--keep", previous}, description}
+	}
+
+	switch {
+	case action == "commit (initial)":
+		return fmt.Sprintf("the first commit of the repository (%s)", detail), []undoOption{
+			{[]string{"update-ref", "-d", "HEAD"}, "remove the commit, keeping its files staged"},
+		}
+
+	case action == "commit (amend)":
+		operation := fmt.Sprintf("amended the last commit (%s)", detail)
+		if pushed {
+			operation += "; the amended commit is already pushed"
+		}
+		return operation, []undoOption{
+			{[]string{"reset", "--soft", previous}, "restore the commit as it was before the amend, keeping the amended changes staged"},
+		}
+
+	case action == "commit (merge)" || strings.HasPrefix(action, "merge ") && !strings.Contains(detail, "Fast-forward") ||
+		strings.HasPrefix(action, "pull") && strings.Contains(detail, "Merge made"):
+		revert := undoOption{[]string{"revert", "-m", "1", "--no-edit", "HEAD"}, "add a commit that reverses the merge, without rewriting history"}
+		reset := moveBack("move the branch back to before the merge")
+		if pushed {
+			return fmt.Sprintf("merge (%s), already pushed", last.subject), []undoOption{revert, reset}
+		}
+		return fmt.Sprintf("merge (%s)", last.subject), []undoOption{reset, revert}
+
+	case strings.HasPrefix(action, "merge ") || strings.HasPrefix(action, "pull") && !strings.Contains(action, "rebase"):
+		reset := moveBack("move the branch back to before the fast-forward")
+		revert := undoOption{[]string{"revert", "--no-edit", previous + "..HEAD"}, "add commits that reverse the ones brought in, without rewriting history"}
+		if pushed {
+			return fmt.Sprintf("fast-forward (%s), already pushed", last.subject), []undoOption{revert, reset}
+		}
+		return fmt.Sprintf("fast-forward (%s)", last.subject), []undoOption{reset, revert}
+
+	case action == "commit" || action == "cherry-pick" || action == "revert":
+		soft := undoOption{[]string{"reset", "--soft", previous}, "remove the commit, keeping its changes staged"}
+		revert := undoOption{[]string{"revert", "--no-edit", "HEAD"}, "add a commit that reverses it, without rewriting history"}
+		discard := moveBack("remove the commit and its changes")
+		if pushed {
+			return fmt.Sprintf("%s \"%s\", already pushed", action, strings.TrimSpace(detail)), []undoOption{revert, soft}
+		}
+		return fmt.Sprintf("%s \"%s\"", action, strings.TrimSpace(detail)), []undoOption{soft, discard, revert}
+
+	case action == "reset":
+		option := moveBack("move the branch back to where it was before the reset")
+		if hasChanges, err := hasUncommittedChanges(); err == nil && hasChanges {
+			// A soft or mixed reset left the undone commit's changes staged
+			option = undoOption{[]string{"reset", "--soft", previous}, "move the branch back to where it was before the reset, keeping what is staged"}
+		}
+		return fmt.Sprintf("reset (%s)", detail), []undoOption{option}
+
+	case action == "checkout":
+		from, _, ok := strings.Cut(strings.TrimPrefix(detail, "moving from "), " to ")
+		if !ok {
+			break
+		}
+		return fmt.Sprintf("checkout (%s)", detail), []undoOption{
+			{[]string{"checkout", from}, fmt.Sprintf("switch back to %s", from)},
+		}
+
+	case strings.Contains(action, "(finish)"):
+		// The entry before the rebase started is where the branch was
+		for i, entry := range entries {
+			if strings.Contains(entry.subject, "(start)") && i+1 < len(entries) {
+				operation := fmt.Sprintf("rebase (%s)", detail)
+				if pushed {
+					operation += "; undoing it needs a force push"
+				}
+				return operation, []undoOption{
+					{[]string{"reset", "--keep", entries[i+1].sha}, "move the branch back to where it was before the rebase"},
+				}
+			}
+		}
+	}
+
+	if previous == "" {
+		return last.subject, nil
+	}
+	return last.subject, []undoOption{moveBack("move HEAD back to where it was before this operation")}
+}
+
+// isHeadPushed reports whether HEAD is on any remote-tracking branch
+func isHeadPushed() bool {
+	output, err := runGitOutput("branch", "-r", "--contains", "HEAD")
+	return err == nil && strings.TrimSpace(output) != ""
+}
+
+// describeUndoState summarizes the working tree and branch for the model
+func describeUndoState() string {
+	var state []string
+	if branch, err := getCurrentBranch(); err == nil && branch != "" {
+		state = append(state, "Branch: "+branch)
+	} else {
+		state = append(state, "Detached HEAD")
+	}
+	if isHeadPushed() {
+		state = append(state, "HEAD is already on a remote branch (pushed)")
+	} else {
+		state = append(state, "HEAD is not on any remote branch (not pushed)")
+	}
+	if status, err := runGitOutput("status", "--porcelain"); err == nil {
+		if strings.TrimSpace(status) == "" {
+			state = append(state, "Working tree clean")
+		} else {
+			state = append(state, "Uncommitted changes:\n"+limitLines(status, 30))
+		}
+	}
+	return strings.Join(state, "\n")
+}
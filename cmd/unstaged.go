@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/i18n"
+	"github.com/hunkim/sgit/pkg/progress"
+	"github.com/hunkim/sgit/pkg/secrets"
+	"github.com/hunkim/sgit/pkg/solar"
+)
+
+// unstagedFile is a changed file that is not staged, with its path relative to the
+// top of the working tree
+type unstagedFile struct {
+	path   string
+	status string // modified, deleted or new
+}
+
+// listUnstagedFiles returns the tracked files with unstaged changes and the untracked
+// files that are not ignored
+func listUnstagedFiles() ([]unstagedFile, error) {
+	output, err := runGitOutput("diff", "--name-status", "--no-renames")
+	if err != nil {
+		return nil, fmt.Errorf("error listing unstaged changes: %w", err)
+	}
+	var files []unstagedFile
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		status, path, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		switch status {
+		case "D":
+			files = append(files, unstagedFile{path: path, status: "deleted"})
+		default:
+			files = append(files, unstagedFile{path: path, status: "modified"})
+		}
+	}
+
+	untracked, err := runGitOutput("ls-files", "--others", "--exclude-standard", "--full-name", "--", topPath(""))
+	if err != nil {
+		return nil, fmt.Errorf("error listing untracked files: %w", err)
+	}
+	for _, path := range strings.Split(strings.TrimSpace(untracked), "\n") {
+		if path != "" {
+			files = append(files, unstagedFile{path: path, status: "new"})
+		}
+	}
+	return files, nil
+}
+
+// topPath turns a path relative to the top of the working tree into a pathspec that
+// works from any subdirectory
+func topPath(path string) string {
+	return ":(top)" + path
+}
+
+// withheldSecretNote replaces content that looks like it holds a secret: planning
+// runs before the staged secret check, so nothing may leave the machine unchecked
+const withheldSecretNote = "(left out: contains a potential secret)"
+
+// unstagedSample returns what the AI sees of an unstaged file: its diff, or the
+// content of a new file, unless it contains a potential secret
+func unstagedSample(root string, file unstagedFile) solar.FileSample {
+	sample := solar.FileSample{Path: fmt.Sprintf("%s (%s)", file.path, file.status)}
+	if file.status != "new" {
+		sample.Content, _ = runGitOutput("diff", "--", topPath(file.path))
+		if len(secrets.ScanDiff(sample.Content)) > 0 {
+			sample.Content = withheldSecretNote
+		}
+		return sample
+	}
+
+	path := filepath.Join(root, file.path)
+	switch {
+	case isBinaryFile(path):
+		sample.Content = "(binary file)"
+	case isLargeFile(path):
+		sample.Content = "(large file)"
+	default:
+		content, _ := os.ReadFile(path)
+		sample.Content = string(content)
+		if len(secrets.ScanText(file.path, sample.Content)) > 0 {
+			sample.Content = withheldSecretNote
+		}
+	}
+	return sample
+}
+
+// planUnstagedChanges offers to stage the unstaged changes that belong in the commit
+// being made, for commit --include-unstaged. With AI, Solar LLM proposes which files
+// make a coherent commit with what is already staged; without it, every file is
+// proposed. Nothing is staged without confirmation.
+func planUnstagedChanges(ctx context.Context, withAI bool) error {
+	files, err := listUnstagedFiles()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	decisions := make(map[string]solar.StagingDecision, len(files))
+	for _, file := range files {
+		decisions[file.path] = solar.StagingDecision{Add: true}
+	}
+	if withAI {
+		if planned, err := planStagingWithAI(ctx, files); isDryRun(err) {
+			return err
+		} else if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Could not plan the staging with AI, proposing every file: %v\n", err)
+		} else {
+			decisions = planned
+		}
+	}
+
+	var planned []string
+	fmt.Println(i18n.T("📋 Unstaged changes:"))
+	for _, file := range files {
+		decision, ok := decisions[file.path]
+		mark := "⏭️ "
+		if decision.Add {
+			mark = "✅"
+			planned = append(planned, file.path)
+		}
+		reason := decision.Reason
+		if !ok {
+			reason = "no recommendation"
+		}
+		if reason != "" {
+			reason = " - " + reason
+		}
+		fmt.Printf("  %s %s (%s)%s\n", mark, file.path, file.status, reason)
+	}
+	fmt.Println()
+
+	answer := strings.ToLower(ask(i18n.T("Stage the %d file(s) marked ✅? (y = yes, a = all, n = none) [y]: ", len(planned))))
+	switch answer {
+	case "", "y", "yes":
+	case "a", "all":
+		planned = planned[:0]
+		for _, file := range files {
+			planned = append(planned, file.path)
+		}
+	default:
+		fmt.Println(i18n.T("Nothing staged"))
+		return nil
+	}
+	if len(planned) == 0 {
+		return nil
+	}
+
+	args := []string{"add", "-A", "--"}
+	for _, path := range planned {
+		args = append(args, topPath(path))
+	}
+	gitCmd := exec.Command("git", args...)
+	gitCmd.Stderr = os.Stderr
+	if err := gitCmd.Run(); err != nil {
+		return fmt.Errorf("error staging files: %w", err)
+	}
+	statusf("%s", i18n.T("✅ Staged %d file(s)\n\n", len(planned)))
+	return nil
+}
+
+// planStagingWithAI asks Solar LLM which unstaged files belong with the staged ones
+func planStagingWithAI(ctx context.Context, files []unstagedFile) (map[string]solar.StagingDecision, error) {
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return nil, err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := gitRepo.Root(ctx)
+	if err != nil {
+		return nil, err
+	}
+	staged, _ := getGitDiff()
+	if len(secrets.ScanDiff(staged)) > 0 {
+		staged = withheldSecretNote
+	}
+	samples := make([]solar.FileSample, len(files))
+	for i, file := range files {
+		samples[i] = unstagedSample(root, file)
+	}
+
+	planning := progress.Begin(i18n.T("Planning which changes to include"))
+	decisions, err := client.PlanCommitStaging(ctx, staged, samples)
+	planning.End()
+	if err != nil {
+		return nil, err
+	}
+
+	// The decisions are keyed by the sample paths, which carry the status
+	byPath := make(map[string]solar.StagingDecision, len(decisions))
+	for i, file := range files {
+		if decision, ok := decisions[samples[i].Path]; ok {
+			byPath[file.path] = decision
+		}
+	}
+	return byPath, nil
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hunkim/sgit/pkg/repostats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	whoKnowsTop   int
+	whoKnowsSince string
+	whoKnowsNoAI  bool
+)
+
+// maxWhoKnowsFiles limits how many files of a directory are blamed; the most often
+// changed ones are kept
+const maxWhoKnowsFiles = 200
+
+// maxWhoKnowsCommits limits how many of each person's commits the model sees
+const maxWhoKnowsCommits = 20
+
+// whoKnowsCmd finds the people who know a file or directory best
+var whoKnowsCmd = &cobra.Command{
+	Use:   "who-knows <path>",
+	Short: "Find who knows a file or directory best, to pick a reviewer or ask a question",
+	Long: `Rank the people who worked on a file or directory by expertise, from git blame
+and git log, and have Solar LLM summarize each person's contributions there and
+suggest reviewers for changes to it.
+
+The score is half each person's share of the current lines (who last changed
+them, according to git blame) and half their share of the commits to the path,
+where a commit counts half as much for every year since it was made. So people
+who wrote the code that is there now, and worked on it recently, rank first.
+Authors are matched by email, after .mailmap.
+
+--since only limits the commits counted; the blame is always of the current code.
+In a large directory, the 200 most often changed files are blamed.
+
+Examples:
+  sgit who-knows pkg/solar
+  sgit who-knows cmd/commit.go --top 3
+  sgit who-knows src/billing --since "1 year ago"
+  sgit who-knows . --no-ai`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runWhoKnows(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whoKnowsCmd)
+
+	whoKnowsCmd.Flags().IntVar(&whoKnowsTop, "top", 5, "number of people to show")
+	whoKnowsCmd.Flags().StringVar(&whoKnowsSince, "since", "", "only count commits more recent than a date (e.g. \"1 year ago\")")
+	whoKnowsCmd.Flags().BoolVar(&whoKnowsNoAI, "no-ai", false, "only show the ranking, without the AI summary")
+}
+
+func runWhoKnows(cmd *cobra.Command, args []string) error {
+	// Check if we're in a git repository
+	if !isGitRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+	if whoKnowsTop < 1 {
+		return fmt.Errorf("--top must be at least 1")
+	}
+	if _, err := runGitOutput("rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return fmt.Errorf("the repository has no commits yet")
+	}
+	path := args[0]
+
+	logArgs := []string{"log", "--no-merges", "--no-renames", "--numstat", "--format=" + repostats.LogFormat}
+	if whoKnowsSince != "" {
+		logArgs = append(logArgs, "--since="+whoKnowsSince)
+	}
+	output, err := runGitOutput(append(logArgs, "HEAD", "--", path)...)
+	if err != nil {
+		return fmt.Errorf("error reading the history of %s: %w", path, err)
+	}
+	commits := repostats.ParseLog(output)
+
+	files, err := whoKnowsFiles(path, commits)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 && len(files) == 0 {
+		return fmt.Errorf("no commits touch '%s'", path)
+	}
+
+	shares := blameShares(cmd, files)
+	experts := repostats.Expertise(commits, shares, time.Now())
+	if len(experts) == 0 {
+		fmt.Printf("Nobody has changed %s", path)
+		if whoKnowsSince != "" {
+			fmt.Printf(" since %s", whoKnowsSince)
+		}
+		fmt.Println()
+		return nil
+	}
+	experts = experts[:min(whoKnowsTop, len(experts))]
+
+	ranking := formatExperts(path, experts, len(commits), len(files))
+	fmt.Print(ranking)
+
+	if !useAIFor("who_knows", true, false, whoKnowsNoAI) {
+		return nil
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	contributions, err := expertContributions(path, experts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	renderer, err := outputRenderer("")
+	if err != nil {
+		return err
+	}
+	printContentStats("Expertise summary", ranking, contributions)
+	printer := newStreamPrinter("")
+	printer.renderer = renderer
+	_, err = client.SummarizeExpertise(cmd.Context(), path, ranking, contributions, printer.Write)
+	printer.Done()
+	if err != nil {
+		return fmt.Errorf("error summarizing expertise: %w", err)
+	}
+	fmt.Println() // Add newline after streaming output
+	return nil
+}
+
+// whoKnowsFiles returns the files under path in HEAD, relative to the working
+// directory like path. When there are more than maxWhoKnowsFiles, the ones the commits
+// changed most often are kept.
+func whoKnowsFiles(path string, commits []repostats.Commit) ([]string, error) {
+	tree, err := runGitOutput("ls-tree", "-r", "--name-only", "HEAD", "--", path)
+	if err != nil {
+		return nil, fmt.Errorf("error listing the files of %s: %w", path, err)
+	}
+	files := nonEmptyLines(tree)
+	if len(files) <= maxWhoKnowsFiles {
+		return files, nil
+	}
+
+	// The log has paths from the top of the repository
+	prefix, _ := runGitOutput("rev-parse", "--show-prefix")
+	prefix = strings.TrimSpace(prefix)
+	changes := make(map[string]int)
+	for _, commit := range commits {
+		for _, change := range commit.Files {
+			changes[change.Path]++
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return changes[prefix+files[i]] > changes[prefix+files[j]]
+	})
+	statusf("📊 Blaming the %d most often changed of %d files\n", maxWhoKnowsFiles, len(files))
+	return files[:maxWhoKnowsFiles], nil
+}
+
+// blameShares counts the lines of files each author last changed. Files that can't be
+// blamed, such as submodules, are skipped.
+func blameShares(cmd *cobra.Command, files []string) []repostats.BlameShare {
+	if len(files) > 1 {
+		statusf("🔍 Reading the blame of %d files...\n", len(files))
+	}
+	var shares []repostats.BlameShare
+	for _, file := range files {
+		porcelain, err := gitRepo.Blame(cmd.Context(), "HEAD", "--", file)
+		if err != nil {
+			continue
+		}
+		for _, commit := range parseBlamePorcelain(porcelain) {
+			shares = append(shares, repostats.BlameShare{Name: commit.author, Email: commit.email, Lines: commit.lines})
+		}
+	}
+	return shares
+}
+
+// formatExperts lays out the ranking, which is also what the AI summarizes
+func formatExperts(path string, experts []repostats.Expert, commits, files int) string {
+	var b strings.Builder
+	scope := fmt.Sprintf("%d commit(s)", commits)
+	if whoKnowsSince != "" {
+		scope += " since " + whoKnowsSince
+	}
+	fmt.Fprintf(&b, "🧭 Who knows %s (%s, %d file(s))\n\n", path, scope, files)
+	fmt.Fprintf(&b, "  %2s  %5s  %7s  %7s  %15s  %-10s  %s\n", "#", "score", "lines", "commits", "changed", "last", "author")
+	for i, expert := range experts {
+		changed := fmt.Sprintf("+%d -%d", expert.Added, expert.Removed)
+		last := "-"
+		if !expert.Last.IsZero() {
+			last = expert.Last.Format("2006-01-02")
+		}
+		author := expert.Name
+		if expert.Email != "" {
+			author += " <" + expert.Email + ">"
+		}
+		fmt.Fprintf(&b, "  %2d  %4.0f%%  %7d  %7d  %15s  %-10s  %s\n", i+1, expert.Score*100, expert.Lines, expert.Commits, changed, last, author)
+	}
+	fmt.Fprintln(&b, "\n  score: share of the current lines and of the commits (recent ones count more)")
+	fmt.Fprintln(&b, "  lines: current lines they last changed (git blame)")
+	return b.String()
+}
+
+// expertContributions lists each expert's commits to path, newest first, for the model
+func expertContributions(path string, experts []repostats.Expert) (string, error) {
+	logArgs := []string{"log", "--no-merges", "--date=short", "--format=%x1e%aE%x1f%ad %h %s"}
+	if whoKnowsSince != "" {
+		logArgs = append(logArgs, "--since="+whoKnowsSince)
+	}
+	output, err := runGitOutput(append(logArgs, "HEAD", "--", path)...)
+	if err != nil {
+		return "", fmt.Errorf("error reading the commits to %s: %w", path, err)
+	}
+
+	byEmail := make(map[string][]string)
+	for _, record := range strings.Split(output, "\x1e") {
+		email, subject, ok := strings.Cut(strings.TrimSpace(record), "\x1f")
+		if !ok {
+			continue
+		}
+		email = strings.ToLower(email)
+		if len(byEmail[email]) < maxWhoKnowsCommits {
+			byEmail[email] = append(byEmail[email], subject)
+		}
+	}
+
+	var b strings.Builder
+	for _, expert := range experts {
+		fmt.Fprintf(&b, "## %s\n", expert.Name)
+		subjects := byEmail[strings.ToLower(expert.Email)]
+		if len(subjects) == 0 {
+			b.WriteString("(no commits in this period; ranked by the current lines they last changed)\n")
+		}
+		for _, subject := range subjects {
+			b.WriteString(subject + "\n")
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
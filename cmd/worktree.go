@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var worktreeNoAI bool
+
+// worktreeCmd wraps git worktree, adding an AI summary of every worktree via "worktree status"
+var worktreeCmd = &cobra.Command{
+	Use:   "worktree",
+	Short: "Manage worktrees (with an AI summary of each via 'worktree status')",
+	Long: `Passthrough to git worktree (add, list, remove, prune, ...). Without arguments, or
+with 'sgit worktree status', sgit shows what's in progress in each worktree of the
+repository: the branch, uncommitted changes, commits to push or pull, a stopped rebase
+or merge, and a Solar LLM summary of the work and its next step.
+
+sgit commit, diff and the other commands work in linked worktrees and with
+GIT_DIR/GIT_WORK_TREE set, like git.
+
+Examples:
+  sgit worktree
+  sgit worktree add ../hotfix -b fix/login
+  sgit worktree status --no-ai
+  sgit worktree remove ../hotfix`,
+	Run: func(cmd *cobra.Command, args []string) {
+		args = extractGlobalFlags(args)
+		if len(args) == 0 {
+			if err := runWorktreeStatus(cmd, args); err != nil {
+				exitWithError(err)
+			}
+			return
+		}
+		executeGitCommand(append([]string{"worktree"}, args...))
+	},
+	DisableFlagParsing: true,
+}
+
+// worktreeStatusCmd summarizes the work in progress in each worktree
+var worktreeStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Summarize what's in progress in each worktree",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runWorktreeStatus(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(worktreeCmd)
+	worktreeCmd.AddCommand(worktreeStatusCmd)
+
+	worktreeStatusCmd.Flags().BoolVar(&worktreeNoAI, "no-ai", false, "only list the worktrees, without the AI summary")
+}
+
+// worktreeInfo is one entry of 'git worktree list' with the state of its working tree
+type worktreeInfo struct {
+	path     string
+	head     string
+	branch   string
+	bare     bool
+	detached bool
+	locked   bool
+	prunable bool
+	current  bool
+
+	staged, unstaged, untracked, conflicted int
+	upstream                                string
+	ahead, behind                           int
+	operation                               string
+	commits                                 string
+	diffStat                                string
+}
+
+// name returns the branch of the worktree, or its path when HEAD is detached
+func (w worktreeInfo) name() string {
+	if w.branch != "" {
+		return w.branch
+	}
+	return w.path
+}
+
+func runWorktreeStatus(cmd *cobra.Command, args []string) error {
+	output, err := runGitOutput("worktree", "list", "--porcelain")
+	if err != nil {
+		return fmt.Errorf("error listing worktrees: %w", err)
+	}
+	worktrees := parseWorktreeList(output)
+	if len(worktrees) == 0 {
+		return fmt.Errorf("no worktrees found")
+	}
+
+	current := ""
+	if root, err := runGitOutput("rev-parse", "--show-toplevel"); err == nil {
+		current = filepath.Clean(strings.TrimSpace(root))
+	}
+	for i := range worktrees {
+		worktrees[i].current = filepath.Clean(worktrees[i].path) == current
+		if !worktrees[i].bare && !worktrees[i].prunable {
+			inspectWorktree(&worktrees[i])
+		}
+	}
+
+	fmt.Printf("🌳 %d worktree(s):\n\n", len(worktrees))
+	for _, w := range worktrees {
+		printWorktree(w)
+	}
+
+	if !useAIFor("worktree", true, false, worktreeNoAI) {
+		return nil
+	}
+
+	// Check configuration and setup if needed
+	if err := ensureConfiguration(); err != nil {
+		return err
+	}
+	client, err := newSolarClient()
+	if err != nil {
+		return err
+	}
+
+	var details strings.Builder
+	for _, w := range worktrees {
+		details.WriteString(describeWorktree(w))
+		details.WriteString("\n")
+	}
+
+	statusln("🤖 Summarizing the worktrees with Solar LLM...")
+	renderer, err := outputRenderer("")
+	if err != nil {
+		return err
+	}
+	fmt.Println("\n📋 In progress:")
+	printer := newStreamPrinter("")
+	printer.renderer = renderer
+	_, err = client.SummarizeWorktrees(cmd.Context(), details.String(), printer.Write)
+	printer.Done()
+	if err != nil {
+		return fmt.Errorf("error summarizing worktrees: %w", err)
+	}
+	fmt.Println() // Add newline after streaming output
+	return nil
+}
+
+// parseWorktreeList reads the blank-line separated records of 'git worktree list --porcelain'
+func parseWorktreeList(output string) []worktreeInfo {
+	var worktrees []worktreeInfo
+	for _, record := range strings.Split(strings.TrimSpace(output), "\n\n") {
+		var w worktreeInfo
+		for _, line := range strings.Split(record, "\n") {
+			key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+			switch key {
+			case "worktree":
+				w.path = value
+			case "HEAD":
+				w.head = value
+			case "branch":
+				w.branch = strings.TrimPrefix(value, "refs/heads/")
+			case "bare":
+				w.bare = true
+			case "detached":
+				w.detached = true
+			case "locked":
+				w.locked = true
+			case "prunable":
+				w.prunable = true
+			}
+		}
+		if w.path != "" {
+			worktrees = append(worktrees, w)
+		}
+	}
+	return worktrees
+}
+
+// inspectWorktree fills in the changes, upstream state, stopped operation and recent
+// commits of a worktree
+func inspectWorktree(w *worktreeInfo) {
+	if status, err := worktreeGit(*w, "status", "--porcelain=v2", "--branch"); err == nil {
+		for _, line := range strings.Split(status, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			switch fields[0] {
+			case "#":
+				if len(fields) >= 3 && fields[1] == "branch.upstream" {
+					w.upstream = fields[2]
+				}
+				if len(fields) >= 4 && fields[1] == "branch.ab" {
+					w.ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[2], "+"))
+					w.behind, _ = strconv.Atoi(strings.TrimPrefix(fields[3], "-"))
+				}
+			case "1", "2":
+				if len(fields) >= 2 && len(fields[1]) == 2 {
+					if fields[1][0] != '.' {
+						w.staged++
+					}
+					if fields[1][1] != '.' {
+						w.unstaged++
+					}
+				}
+			case "u":
+				w.conflicted++
+			case "?":
+				w.untracked++
+			}
+		}
+	}
+
+	// Stopped operations are recorded in the worktree's own git directory
+	if gitDir, err := worktreeGit(*w, "rev-parse", "--absolute-git-dir"); err == nil {
+		gitDir = strings.TrimSpace(gitDir)
+		for _, state := range []struct{ file, operation string }{
+			{"rebase-merge", "rebase"}, {"rebase-apply", "rebase"}, {"MERGE_HEAD", "merge"},
+			{"CHERRY_PICK_HEAD", "cherry-pick"}, {"REVERT_HEAD", "revert"}, {"BISECT_LOG", "bisect"},
+		} {
+			if _, err := os.Stat(filepath.Join(gitDir, state.file)); err == nil {
+				w.operation = state.operation
+				break
+			}
+		}
+	}
+
+	if log, err := worktreeGit(*w, "log", "-5", "--format=%h %s (%cr)"); err == nil {
+		w.commits = strings.TrimSpace(log)
+	}
+	if stat, err := worktreeGit(*w, "diff", "HEAD", "--stat=100"); err == nil {
+		w.diffStat = strings.TrimRight(stat, "\n")
+	}
+}
+
+// worktreeGit runs git in a worktree. For the other worktrees GIT_DIR and GIT_WORK_TREE
+// are left out, so they don't point git back at the current one.
+func worktreeGit(w worktreeInfo, args ...string) (string, error) {
+	gitCmd := exec.Command("git", args...)
+	gitCmd.Dir = w.path
+	if !w.current {
+		for _, env := range os.Environ() {
+			if !strings.HasPrefix(env, "GIT_DIR=") && !strings.HasPrefix(env, "GIT_WORK_TREE=") && !strings.HasPrefix(env, "GIT_INDEX_FILE=") {
+				gitCmd.Env = append(gitCmd.Env, env)
+			}
+		}
+	}
+	var stderr bytes.Buffer
+	gitCmd.Stderr = &stderr
+	output, err := gitCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
+
+// worktreeState lists the notable facts about a worktree, e.g. "2 staged, ↑1"
+func worktreeState(w worktreeInfo) []string {
+	var state []string
+	switch {
+	case w.bare:
+		return []string{"bare repository"}
+	case w.prunable:
+		return []string{"directory is missing (git worktree prune removes it)"}
+	}
+	if w.operation != "" {
+		state = append(state, w.operation+" in progress")
+	}
+	for _, count := range []struct {
+		n    int
+		what string
+	}{{w.conflicted, "conflicted"}, {w.staged, "staged"}, {w.unstaged, "modified"}, {w.untracked, "untracked"}} {
+		if count.n > 0 {
+			state = append(state, fmt.Sprintf("%d %s", count.n, count.what))
+		}
+	}
+	if w.upstream != "" && (w.ahead > 0 || w.behind > 0) {
+		state = append(state, fmt.Sprintf("↑%d ↓%d %s", w.ahead, w.behind, w.upstream))
+	}
+	if len(state) == 0 {
+		state = append(state, "clean")
+	}
+	if w.locked {
+		state = append(state, "locked")
+	}
+	return state
+}
+
+func printWorktree(w worktreeInfo) {
+	marker := ""
+	if w.current {
+		marker = " (current)"
+	}
+	label := w.branch
+	if w.detached {
+		label = "detached at " + w.head[:min(len(w.head), 7)]
+	}
+	if label != "" {
+		label = " [" + label + "]"
+	}
+	fmt.Printf("📂 %s%s%s\n", w.path, label, marker)
+	fmt.Printf("   %s\n", strings.Join(worktreeState(w), " · "))
+	if w.commits != "" {
+		last, _, _ := strings.Cut(w.commits, "\n")
+		fmt.Printf("   last commit: %s\n", last)
+	}
+	fmt.Println()
+}
+
+// describeWorktree writes one worktree's section of the AI context
+func describeWorktree(w worktreeInfo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s ---\n", w.name())
+	fmt.Fprintf(&b, "Path: %s\n", w.path)
+	if w.current {
+		b.WriteString("This is the worktree the developer is in.\n")
+	}
+	if w.detached {
+		fmt.Fprintf(&b, "Detached HEAD at %s\n", w.head)
+	}
+	fmt.Fprintf(&b, "State: %s\n", strings.Join(worktreeState(w), ", "))
+	if w.upstream == "" && !w.bare && !w.prunable {
+		b.WriteString("Upstream: none (never pushed)\n")
+	}
+	if w.commits != "" {
+		fmt.Fprintf(&b, "Recent commits:\n%s\n", w.commits)
+	}
+	if w.diffStat != "" {
+		fmt.Fprintf(&b, "Uncommitted changes:\n%s\n", w.diffStat)
+	}
+	return b.String()
+}
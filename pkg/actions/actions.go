@@ -0,0 +1,110 @@
+// Package actions writes results in the formats GitHub Actions understands: workflow
+// command annotations, which show up inline on the files of a pull request, and the
+// job summary, markdown shown on the run's summary page.
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Annotation levels
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNotice  = "notice"
+)
+
+// Annotation is a message attached to a line of a file, or to the run when File is empty
+type Annotation struct {
+	Level string
+	// File is relative to the top of the repository
+	File    string
+	Line    int
+	Title   string
+	Message string
+}
+
+// String formats the annotation as a workflow command, e.g.
+// "::error file=app.go,line=10,title=Secret::message"
+func (a Annotation) String() string {
+	level := a.Level
+	if level == "" {
+		level = LevelError
+	}
+	var properties []string
+	if a.File != "" {
+		properties = append(properties, "file="+escapeProperty(a.File))
+		if a.Line > 0 {
+			properties = append(properties, "line="+strconv.Itoa(a.Line))
+		}
+	}
+	if a.Title != "" {
+		properties = append(properties, "title="+escapeProperty(a.Title))
+	}
+	command := "::" + level
+	if len(properties) > 0 {
+		command += " " + strings.Join(properties, ",")
+	}
+	return command + "::" + escapeData(a.Message)
+}
+
+// Write writes the annotations to w, which must be the step's stdout for GitHub to
+// pick them up
+func Write(w io.Writer, annotations []Annotation) {
+	for _, annotation := range annotations {
+		fmt.Fprintln(w, annotation)
+	}
+}
+
+// AppendSummary adds markdown to the job summary. It reports false when there is no
+// job summary to write to, i.e. outside GitHub Actions.
+func AppendSummary(markdown string) (bool, error) {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return false, nil
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	if _, err := io.WriteString(file, strings.TrimRight(markdown, "\n")+"\n\n"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PullRequestNumber returns the number of the pull request a pull_request workflow
+// runs for, read from GITHUB_REF ("refs/pull/<number>/merge"), or 0
+func PullRequestNumber() int {
+	ref := strings.TrimPrefix(os.Getenv("GITHUB_REF"), "refs/pull/")
+	number, rest, ok := strings.Cut(ref, "/")
+	if !ok || rest != "merge" && rest != "head" {
+		return 0
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// TableCell escapes text for a markdown table cell
+func TableCell(text string) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "|", `\|`)
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
+
+// escapeData escapes a workflow command's message
+func escapeData(text string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(text)
+}
+
+// escapeProperty escapes a workflow command's property value
+func escapeProperty(text string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(text)
+}
@@ -0,0 +1,395 @@
+// Package apidiff extracts the public API of source files and compares two versions of
+// it. Go files are parsed, so every exported identifier is found with its signature;
+// Python, JavaScript/TypeScript, Java, C# and Rust use line-based heuristics that catch
+// top-level public declarations.
+package apidiff
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Symbol is one public declaration
+type Symbol struct {
+	// Key identifies the symbol across versions: the package directory and name for Go
+	// (e.g. "pkg/solar.Client.StreamChat"), the file and name otherwise
+	Key string
+	// Kind is the declaration kind, e.g. func, method, type, field, class
+	Kind string
+	// Signature is the declaration without its body
+	Signature string
+}
+
+// ChangeKind says how a symbol changed between two versions
+type ChangeKind string
+
+const (
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+	Added   ChangeKind = "added"
+)
+
+// Change is a public symbol that was removed, changed or added. Old is empty for
+// additions and New for removals.
+type Change struct {
+	Kind ChangeKind
+	Key  string
+	Old  string
+	New  string
+}
+
+// Supported reports whether Extract understands a file's language
+func Supported(file string) bool {
+	if isGoFile(file) {
+		return true
+	}
+	_, ok := heuristics[strings.ToLower(path.Ext(file))]
+	return ok
+}
+
+// Extract returns the public symbols declared in a file. Test files, Go internal
+// packages and files that don't parse have none.
+func Extract(file string, src []byte) []Symbol {
+	if isGoFile(file) {
+		return extractGo(file, src)
+	}
+	if rules, ok := heuristics[strings.ToLower(path.Ext(file))]; ok {
+		return extractHeuristic(file, src, rules)
+	}
+	return nil
+}
+
+// Compare returns the changes from the old symbols to the new ones, sorted by kind
+// (removed, changed, added) and key
+func Compare(old, new []Symbol) []Change {
+	oldByKey := groupSignatures(old)
+	newByKey := groupSignatures(new)
+
+	var changes []Change
+	for key, oldSigs := range oldByKey {
+		newSigs, ok := newByKey[key]
+		if !ok {
+			for _, sig := range oldSigs {
+				changes = append(changes, Change{Kind: Removed, Key: key, Old: sig})
+			}
+			continue
+		}
+
+		gone, came := difference(oldSigs, newSigs), difference(newSigs, oldSigs)
+		switch {
+		case len(gone) > 0 && len(came) > 0:
+			changes = append(changes, Change{Kind: Changed, Key: key, Old: strings.Join(gone, "\n"), New: strings.Join(came, "\n")})
+		case len(gone) > 0:
+			// An overload went away
+			changes = append(changes, Change{Kind: Removed, Key: key, Old: strings.Join(gone, "\n")})
+		case len(came) > 0:
+			changes = append(changes, Change{Kind: Added, Key: key, New: strings.Join(came, "\n")})
+		}
+	}
+	for key, newSigs := range newByKey {
+		if _, ok := oldByKey[key]; !ok {
+			for _, sig := range newSigs {
+				changes = append(changes, Change{Kind: Added, Key: key, New: sig})
+			}
+		}
+	}
+
+	order := map[ChangeKind]int{Removed: 0, Changed: 1, Added: 2}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return order[changes[i].Kind] < order[changes[j].Kind]
+		}
+		if changes[i].Key != changes[j].Key {
+			return changes[i].Key < changes[j].Key
+		}
+		return changes[i].Old+changes[i].New < changes[j].Old+changes[j].New
+	})
+	return changes
+}
+
+// groupSignatures maps each key to its sorted, distinct signatures; heuristic
+// languages can declare overloads under one key
+func groupSignatures(symbols []Symbol) map[string][]string {
+	grouped := make(map[string][]string)
+	for _, symbol := range symbols {
+		grouped[symbol.Key] = append(grouped[symbol.Key], symbol.Signature)
+	}
+	for key, sigs := range grouped {
+		sort.Strings(sigs)
+		distinct := sigs[:0]
+		for i, sig := range sigs {
+			if i == 0 || sig != sigs[i-1] {
+				distinct = append(distinct, sig)
+			}
+		}
+		grouped[key] = distinct
+	}
+	return grouped
+}
+
+// difference returns the signatures in a that aren't in b
+func difference(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, sig := range b {
+		inB[sig] = true
+	}
+	var result []string
+	for _, sig := range a {
+		if !inB[sig] {
+			result = append(result, sig)
+		}
+	}
+	return result
+}
+
+func isGoFile(file string) bool {
+	return strings.HasSuffix(file, ".go")
+}
+
+// extractGo returns the exported declarations of a Go file, keyed by package directory
+// so declarations can move between the package's files
+func extractGo(file string, src []byte) []Symbol {
+	dir := path.Dir(file)
+	if strings.HasSuffix(file, "_test.go") || dir == "internal" || strings.HasPrefix(dir, "internal/") ||
+		strings.Contains("/"+dir+"/", "/internal/") {
+		return nil
+	}
+
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, file, src, parser.SkipObjectResolution)
+	if err != nil || parsed.Name.Name == "main" {
+		return nil
+	}
+
+	var symbols []Symbol
+	add := func(name, kind string, node interface{}) {
+		symbols = append(symbols, Symbol{Key: dir + "." + name, Kind: kind, Signature: printNode(fset, node)})
+	}
+
+	for _, decl := range parsed.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if !decl.Name.IsExported() {
+				continue
+			}
+			name, kind := decl.Name.Name, "func"
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				receiver := receiverType(decl.Recv.List[0].Type)
+				if !ast.IsExported(receiver) {
+					continue
+				}
+				name, kind = receiver+"."+name, "method"
+			}
+			// The receiver's name isn't part of the API
+			signature := *decl
+			signature.Body, signature.Doc = nil, nil
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				signature.Recv = &ast.FieldList{List: []*ast.Field{{Type: decl.Recv.List[0].Type}}}
+			}
+			add(name, kind, &signature)
+
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if !spec.Name.IsExported() {
+						continue
+					}
+					extractGoType(spec, fset, add)
+				case *ast.ValueSpec:
+					kind := "var"
+					if decl.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, ident := range spec.Names {
+						if !ident.IsExported() {
+							continue
+						}
+						// Values aren't part of the API, only the name and declared type
+						signature := kind + " " + ident.Name
+						if spec.Type != nil {
+							signature += " " + printNode(fset, spec.Type)
+						}
+						symbols = append(symbols, Symbol{Key: dir + "." + ident.Name, Kind: kind, Signature: signature})
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// extractGoType adds a type and, for structs and interfaces, its exported fields and
+// methods as separate symbols, so adding one isn't reported as changing the type
+func extractGoType(spec *ast.TypeSpec, fset *token.FileSet, add func(name, kind string, node interface{})) {
+	name := spec.Name.Name
+	typeParams := ""
+	if spec.TypeParams != nil {
+		var params []string
+		for _, field := range spec.TypeParams.List {
+			var names []string
+			for _, ident := range field.Names {
+				names = append(names, ident.Name)
+			}
+			params = append(params, strings.Join(names, ", ")+" "+printNode(fset, field.Type))
+		}
+		typeParams = "[" + strings.Join(params, ", ") + "]"
+	}
+
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		add(name, "type", "type "+name+typeParams+" struct")
+		for _, field := range t.Fields.List {
+			for _, ident := range field.Names {
+				if ident.IsExported() {
+					add(name+"."+ident.Name, "field", ident.Name+" "+printNode(fset, field.Type))
+				}
+			}
+			if len(field.Names) == 0 {
+				// Embedded fields promote their methods and fields
+				add(name+"."+printNode(fset, field.Type), "embedded", printNode(fset, field.Type))
+			}
+		}
+	case *ast.InterfaceType:
+		add(name, "type", "type "+name+typeParams+" interface")
+		for _, method := range t.Methods.List {
+			for _, ident := range method.Names {
+				if ident.IsExported() {
+					add(name+"."+ident.Name, "interface method", ident.Name+strings.TrimPrefix(printNode(fset, method.Type), "func"))
+				}
+			}
+			if len(method.Names) == 0 {
+				add(name+"."+printNode(fset, method.Type), "embedded", printNode(fset, method.Type))
+			}
+		}
+	default:
+		assign := " "
+		if spec.Assign.IsValid() {
+			assign = " = "
+		}
+		add(name, "type", "type "+name+typeParams+assign+printNode(fset, spec.Type))
+	}
+}
+
+// receiverType returns the type name of a method receiver such as *Client or List[T]
+func receiverType(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// printNode formats a node on one line; strings are returned as they are
+func printNode(fset *token.FileSet, node interface{}) string {
+	if text, ok := node.(string); ok {
+		return text
+	}
+	var b bytes.Buffer
+	if err := printer.Fprint(&b, fset, node); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// heuristic finds public declarations in a language without parsing it
+type heuristic struct {
+	// pattern matches a public declaration line; the last non-empty group is the name
+	pattern *regexp.Regexp
+	// private reports whether a matched name is private by convention
+	private func(name string) bool
+}
+
+var (
+	pythonDeclaration = regexp.MustCompile(`^(?:async\s+def|def|class)\s+([A-Za-z_]\w*)`)
+	scriptDeclaration = regexp.MustCompile(`^export\s+(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?(?:async\s+)?(?:function\*?|class|const|let|var|interface|type|enum|namespace)\s+([A-Za-z_$][\w$]*)`)
+	javaDeclaration   = regexp.MustCompile(`^\s*public\s+(?:(?:static|final|abstract|sealed|synchronized|default|virtual|override|async|readonly|partial)\s+)*(?:(?:class|interface|enum|record|struct)\s+([A-Za-z_]\w*)|[\w<>\[\],.?\s]*?\s([A-Za-z_]\w*)\s*\()`)
+	rustDeclaration   = regexp.MustCompile(`^\s*pub\s+(?:async\s+)?(?:unsafe\s+)?(?:fn|struct|enum|trait|type|const|static|mod|union)\s+([A-Za-z_]\w*)`)
+)
+
+// heuristics maps file extensions to how their public declarations are found
+var heuristics = map[string]heuristic{
+	".py":   {pythonDeclaration, func(name string) bool { return strings.HasPrefix(name, "_") }},
+	".js":   {scriptDeclaration, nil},
+	".jsx":  {scriptDeclaration, nil},
+	".mjs":  {scriptDeclaration, nil},
+	".ts":   {scriptDeclaration, nil},
+	".tsx":  {scriptDeclaration, nil},
+	".java": {javaDeclaration, nil},
+	".cs":   {javaDeclaration, nil},
+	".rs":   {rustDeclaration, nil},
+}
+
+// testFilePattern matches test files of the heuristic languages
+var testFilePattern = regexp.MustCompile(`(?:^|/)(?:test_[^/]*|[^/]*(?:_test|\.test|\.spec|Tests?)\.\w+)$|(?:^|/)(?:tests?|__tests__|spec)/`)
+
+// extractHeuristic returns the top-level public declarations of a file, keyed by file
+// and name
+func extractHeuristic(file string, src []byte, rules heuristic) []Symbol {
+	if testFilePattern.MatchString(file) {
+		return nil
+	}
+
+	var symbols []Symbol
+	for _, line := range strings.Split(string(src), "\n") {
+		m := rules.pattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name := ""
+		for _, group := range m[1:] {
+			if group != "" {
+				name = group
+			}
+		}
+		if name == "" || (rules.private != nil && rules.private(name)) {
+			continue
+		}
+
+		// The signature is the declaration line without the start of its body
+		signature := strings.TrimSpace(line)
+		signature = strings.TrimSpace(strings.TrimSuffix(signature, "{"))
+		signature = strings.TrimSuffix(signature, ":")
+		symbols = append(symbols, Symbol{Key: file + "." + name, Kind: "declaration", Signature: signature})
+	}
+	return symbols
+}
+
+// Format renders changes for a prompt or terminal, one per line with old and new
+// signatures indented below changed ones
+func Format(changes []Change) string {
+	var b strings.Builder
+	for _, change := range changes {
+		switch change.Kind {
+		case Removed:
+			b.WriteString("- removed " + change.Key + ": " + oneLine(change.Old) + "\n")
+		case Added:
+			b.WriteString("- added " + change.Key + ": " + oneLine(change.New) + "\n")
+		case Changed:
+			b.WriteString("- changed " + change.Key + "\n")
+			b.WriteString("    before: " + oneLine(change.Old) + "\n")
+			b.WriteString("    after:  " + oneLine(change.New) + "\n")
+		}
+	}
+	return b.String()
+}
+
+func oneLine(text string) string {
+	return strings.ReplaceAll(text, "\n", " | ")
+}
@@ -0,0 +1,136 @@
+// Package assets describes changes to binary files such as images and fonts. They
+// have no diff the model could read, so their kind, size and, for images, dimensions
+// are what tells it that "the logo was replaced" rather than nothing at all.
+package assets
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"path"
+	"strings"
+)
+
+// kinds maps file extensions to the kind of asset they hold
+var kinds = map[string]string{
+	".png": "image", ".jpg": "image", ".jpeg": "image", ".gif": "image", ".webp": "image",
+	".bmp": "image", ".ico": "image", ".tif": "image", ".tiff": "image", ".avif": "image",
+	".heic": "image", ".psd": "image",
+	".ttf": "font", ".otf": "font", ".woff": "font", ".woff2": "font", ".eot": "font",
+	".mp3": "audio", ".wav": "audio", ".ogg": "audio", ".flac": "audio", ".m4a": "audio",
+	".mp4": "video", ".mov": "video", ".webm": "video", ".avi": "video", ".mkv": "video",
+	".zip": "archive", ".tar": "archive", ".gz": "archive", ".tgz": "archive", ".7z": "archive",
+	".jar": "archive", ".whl": "archive",
+	".pdf": "document", ".doc": "document", ".docx": "document", ".xls": "document",
+	".xlsx": "document", ".ppt": "document", ".pptx": "document",
+	".db": "database", ".sqlite": "database", ".sqlite3": "database",
+	".exe": "executable", ".dll": "library", ".so": "library", ".dylib": "library",
+}
+
+// Kind returns the kind of asset a file holds judging by its name ("image", "font",
+// "audio", ...), or "file" when the extension is not a known one
+func Kind(name string) string {
+	if kind, ok := kinds[strings.ToLower(path.Ext(name))]; ok {
+		return kind
+	}
+	return "file"
+}
+
+// Dimensions returns the width and height of a PNG, JPEG, GIF or WebP image
+func Dimensions(data []byte) (width, height int, ok bool) {
+	if config, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		return config.Width, config.Height, true
+	}
+	return webpDimensions(data)
+}
+
+// webpDimensions reads the size of a WebP image from its first chunk, which is
+// VP8X for extended, VP8L for lossless and VP8 for lossy images
+func webpDimensions(data []byte) (width, height int, ok bool) {
+	if len(data) < 30 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 0, 0, false
+	}
+	uint24 := func(b []byte) int { return int(b[0]) | int(b[1])<<8 | int(b[2])<<16 }
+	switch string(data[12:16]) {
+	case "VP8X":
+		return uint24(data[24:27]) + 1, uint24(data[27:30]) + 1, true
+	case "VP8L":
+		if data[20] != 0x2f {
+			return 0, 0, false
+		}
+		bits := binary.LittleEndian.Uint32(data[21:25])
+		return int(bits&0x3fff) + 1, int(bits>>14&0x3fff) + 1, true
+	case "VP8 ":
+		if !bytes.Equal(data[23:26], []byte{0x9d, 0x01, 0x2a}) {
+			return 0, 0, false
+		}
+		return int(binary.LittleEndian.Uint16(data[26:28]) & 0x3fff), int(binary.LittleEndian.Uint16(data[28:30]) & 0x3fff), true
+	}
+	return 0, 0, false
+}
+
+// Version is a binary file on one side of a change
+type Version struct {
+	Size int64
+	// Data is the content, only needed for the dimensions of images
+	Data []byte
+}
+
+// Describe summarizes a change to a binary file, e.g. "binary image, 12.4 KB → 8.1
+// KB (-35%), 512x512 → 256x256". old is nil for an added file and new is nil for a
+// deleted one.
+func Describe(name string, old, new *Version) string {
+	parts := []string{"binary " + Kind(name)}
+	switch {
+	case old == nil && new == nil:
+		return parts[0]
+	case old == nil:
+		parts = append(parts, formatSize(new.Size))
+	case new == nil:
+		parts = append(parts, "was "+formatSize(old.Size))
+	case old.Size == new.Size:
+		parts = append(parts, formatSize(new.Size)+", same size")
+	default:
+		change := float64(new.Size-old.Size) * 100 / float64(max(old.Size, 1))
+		parts = append(parts, fmt.Sprintf("%s → %s (%+.0f%%)", formatSize(old.Size), formatSize(new.Size), change))
+	}
+
+	oldSize, newSize := imageSize(old), imageSize(new)
+	switch {
+	case oldSize != "" && newSize != "" && oldSize != newSize:
+		parts = append(parts, oldSize+" → "+newSize)
+	case newSize != "":
+		parts = append(parts, newSize)
+	case oldSize != "":
+		parts = append(parts, oldSize)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// imageSize renders an image's dimensions as "WxH", or returns "" when there is no
+// image Dimensions can read
+func imageSize(version *Version) string {
+	if version == nil || version.Data == nil {
+		return ""
+	}
+	width, height, ok := Dimensions(version.Data)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", width, height)
+}
+
+// formatSize renders a byte count as B, KB or MB
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
@@ -0,0 +1,106 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/httpclient"
+)
+
+// Client is a minimal Bitbucket Cloud REST API (2.0) client
+type Client struct {
+	username string
+	password string
+	token    string
+	baseURL  string
+}
+
+// PullRequest represents a pull request returned by the API
+type PullRequest struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+type branchRef struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+}
+
+type newPullRequest struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Source      branchRef `json:"source"`
+	Destination branchRef `json:"destination"`
+	Draft       bool      `json:"draft,omitempty"`
+}
+
+// NewClient creates a Bitbucket client. With a username, password is sent as an app
+// password using basic auth; otherwise token is sent as a bearer access token.
+func NewClient(username, password, token, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = "https://api.bitbucket.org/2.0"
+	}
+	return &Client{
+		username: username,
+		password: password,
+		token:    token,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// CreatePullRequest opens a pull request from source into destination in workspace/repo
+func (c *Client) CreatePullRequest(ctx context.Context, workspace, repo, title, description, source, destination string, draft bool) (*PullRequest, error) {
+	pr := newPullRequest{Title: title, Description: description, Draft: draft}
+	pr.Source.Branch.Name = source
+	pr.Destination.Branch.Name = destination
+
+	jsonData, err := json.Marshal(pr)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %v", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/repositories/%s/%s/pullrequests", c.baseURL, workspace, repo)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	} else if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := httpclient.Shared().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("Bitbucket API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var created PullRequest
+	if err := json.Unmarshal(body, &created); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %v", err)
+	}
+	return &created, nil
+}
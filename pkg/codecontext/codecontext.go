@@ -0,0 +1,449 @@
+// Package codecontext finds the declarations that the changed lines of a source file
+// fall in: the enclosing function, method, type or class, with its doc comment. Go
+// files are parsed; Python, JavaScript/TypeScript, Java, C#, Kotlin, Rust and Ruby use
+// the nearest declaration line above the change, as git's hunk headers do.
+package codecontext
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxDocLength bounds the doc comment kept for a declaration
+const maxDocLength = 160
+
+// Declaration is a declaration that changed lines fall in
+type Declaration struct {
+	// Kind is the declaration kind, e.g. func, method, type, var, class, def
+	Kind string
+	// Signature is the declaration without its body, on one line
+	Signature string
+	// Doc is the start of the doc comment or docstring, on one line
+	Doc string
+	// Fields lists the changed fields of a Go struct type
+	Fields []string
+	// Lines is how many changed lines fall in the declaration
+	Lines int
+
+	start int
+}
+
+// Supported reports whether Enclosing understands a file's language
+func Supported(file string) bool {
+	if strings.HasSuffix(file, ".go") {
+		return true
+	}
+	_, ok := declarationPatterns[strings.ToLower(path.Ext(file))]
+	return ok
+}
+
+// hunkHeader matches the new-side start line of a hunk, "@@ -a,b +c,d @@"
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// ChangedLines reads a unified diff and returns, for each file, the lines of its new
+// version that were added, and where lines were removed
+func ChangedLines(diff string) map[string][]int {
+	changed := make(map[string][]int)
+	file, line := "", 0
+	for _, text := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			name := strings.Trim(strings.TrimPrefix(text, "+++ "), `"`)
+			file = ""
+			if name != "/dev/null" {
+				file = strings.TrimPrefix(name, "b/")
+			}
+		case strings.HasPrefix(text, "@@"):
+			if m := hunkHeader.FindStringSubmatch(text); m != nil {
+				line, _ = strconv.Atoi(m[1])
+			}
+		case file == "" || line == 0:
+		case strings.HasPrefix(text, "+"):
+			changed[file] = append(changed[file], line)
+			line++
+		case strings.HasPrefix(text, "-"):
+			changed[file] = append(changed[file], max(line, 1))
+		case strings.HasPrefix(text, " "):
+			line++
+		case strings.HasPrefix(text, "diff "):
+			file, line = "", 0
+		}
+	}
+	return changed
+}
+
+// Enclosing returns the declarations of src, the new version of file, that the
+// changed lines fall in, in the order they appear. Lines outside any declaration
+// (imports, package clauses) are left out.
+func Enclosing(file string, src []byte, lines []int) []Declaration {
+	if len(lines) == 0 {
+		return nil
+	}
+	if strings.HasSuffix(file, ".go") {
+		return enclosingGo(file, src, lines)
+	}
+	if pattern, ok := declarationPatterns[strings.ToLower(path.Ext(file))]; ok {
+		return enclosingHeuristic(src, lines, pattern, strings.ToLower(path.Ext(file)) == ".py")
+	}
+	return nil
+}
+
+// Describe formats declarations one per line, with the changed fields and the doc
+// comment, e.g. "func (c *Client) Close() error — Close releases the connection"
+func Describe(decls []Declaration) string {
+	var b strings.Builder
+	for _, decl := range decls {
+		b.WriteString(decl.Signature)
+		if len(decl.Fields) > 0 {
+			fmt.Fprintf(&b, " (fields: %s)", strings.Join(decl.Fields, ", "))
+		}
+		if decl.Doc != "" {
+			b.WriteString(" — " + decl.Doc)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// span is a declaration with the lines it covers
+type span struct {
+	decl       Declaration
+	start, end int
+	// fields are the named struct fields with their lines, for Go struct types
+	fields []span
+}
+
+func enclosingGo(file string, src []byte, lines []int) []Declaration {
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, file, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	lineOf := func(pos token.Pos) int { return fset.Position(pos).Line }
+
+	var spans []span
+	for _, decl := range parsed.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			kind := "func"
+			if decl.Recv != nil {
+				kind = "method"
+			}
+			signature := *decl
+			signature.Body, signature.Doc = nil, nil
+			spans = append(spans, span{
+				decl:  Declaration{Kind: kind, Signature: printNode(fset, &signature), Doc: docText(decl.Doc)},
+				start: docStart(decl.Doc, lineOf(decl.Pos()), fset), end: lineOf(decl.End()),
+			})
+
+		case *ast.GenDecl:
+			if decl.Tok == token.IMPORT {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				doc := decl.Doc
+				s := span{start: lineOf(spec.Pos()), end: lineOf(spec.End())}
+				if len(decl.Specs) == 1 {
+					s.start, s.end = docStart(decl.Doc, lineOf(decl.Pos()), fset), lineOf(decl.End())
+				}
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Doc != nil {
+						doc = spec.Doc
+					}
+					s.decl = Declaration{Kind: "type", Signature: "type " + spec.Name.Name + " " + typeKind(fset, spec.Type), Doc: docText(doc)}
+					if st, ok := spec.Type.(*ast.StructType); ok && st.Fields != nil {
+						for _, field := range st.Fields.List {
+							for _, name := range field.Names {
+								s.fields = append(s.fields, span{decl: Declaration{Signature: name.Name}, start: lineOf(field.Pos()), end: lineOf(field.End())})
+							}
+						}
+					}
+				case *ast.ValueSpec:
+					if spec.Doc != nil {
+						doc = spec.Doc
+					}
+					var names []string
+					for _, name := range spec.Names {
+						names = append(names, name.Name)
+					}
+					signature := decl.Tok.String() + " " + strings.Join(names, ", ")
+					if spec.Type != nil {
+						signature += " " + printNode(fset, spec.Type)
+					}
+					s.decl = Declaration{Kind: decl.Tok.String(), Signature: signature, Doc: docText(doc)}
+				}
+				spans = append(spans, s)
+			}
+		}
+	}
+	return collect(spans, lines)
+}
+
+// collect counts the changed lines in each span and returns the spans they fall in
+func collect(spans []span, lines []int) []Declaration {
+	var decls []Declaration
+	for _, s := range spans {
+		decl := s.decl
+		decl.start = s.start
+		for _, line := range dedupe(lines) {
+			if line < s.start || line > s.end {
+				continue
+			}
+			decl.Lines++
+			for _, field := range s.fields {
+				if line >= field.start && line <= field.end && !contains(decl.Fields, field.decl.Signature) {
+					decl.Fields = append(decl.Fields, field.decl.Signature)
+				}
+			}
+		}
+		if decl.Lines > 0 {
+			decls = append(decls, decl)
+		}
+	}
+	sort.SliceStable(decls, func(i, j int) bool { return decls[i].start < decls[j].start })
+	return decls
+}
+
+// typeKind describes a Go type expression briefly: struct, interface, func or the type
+func typeKind(fset *token.FileSet, expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return "struct"
+	case *ast.InterfaceType:
+		return "interface"
+	case *ast.FuncType:
+		return "func"
+	case *ast.Ident:
+		return t.Name
+	}
+	return printNode(fset, expr)
+}
+
+// docStart returns the first line of a declaration at line, counting its doc comment
+func docStart(doc *ast.CommentGroup, line int, fset *token.FileSet) int {
+	if doc == nil {
+		return line
+	}
+	return fset.Position(doc.Pos()).Line
+}
+
+// printNode formats a node on one line
+func printNode(fset *token.FileSet, node interface{}) string {
+	var b bytes.Buffer
+	if err := printer.Fprint(&b, fset, node); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// docText returns the start of a doc comment on one line
+func docText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	return shorten(group.Text())
+}
+
+// shorten puts text on one line and cuts it at maxDocLength
+func shorten(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > maxDocLength {
+		cut := strings.LastIndex(text[:maxDocLength], " ")
+		if cut <= 0 {
+			cut = maxDocLength
+		}
+		text = text[:cut] + "..."
+	}
+	return text
+}
+
+var (
+	pythonPattern = regexp.MustCompile(`^\s*(async\s+def|def|class)\s+[A-Za-z_]\w*`)
+	scriptPattern = regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?(?:async\s+)?(function\*?|class|interface|enum|(?:const|let|var)\s+[A-Za-z_$][\w$]*\s*=\s*(?:async\s*)?(?:\([^)]*\)|[A-Za-z_$][\w$]*)\s*=>|(?:static\s+)?(?:async\s+)?(?:get\s+|set\s+)?[A-Za-z_$][\w$]*\s*\([^)]*\)\s*\{\s*$)`)
+	javaPattern   = regexp.MustCompile(`^\s*(?:@\w+\s+)*(?:(?:public|private|protected|internal|static|final|abstract|sealed|synchronized|override|virtual|async|open|data|suspend)\s+)*(class|interface|enum|record|struct|object|fun|(?:public|private|protected|internal)\s+[\w<>\[\],.?\s]*?\s[A-Za-z_]\w*\s*\()`)
+	rustPattern   = regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:unsafe\s+)?(fn|struct|enum|trait|impl|mod|union)\b`)
+	rubyPattern   = regexp.MustCompile(`^\s*(def|class|module)\s+\S`)
+)
+
+// declarationPatterns maps file extensions to a pattern matching declaration lines;
+// the first group is the declaration kind
+var declarationPatterns = map[string]*regexp.Regexp{
+	".py":   pythonPattern,
+	".js":   scriptPattern,
+	".jsx":  scriptPattern,
+	".mjs":  scriptPattern,
+	".ts":   scriptPattern,
+	".tsx":  scriptPattern,
+	".java": javaPattern,
+	".cs":   javaPattern,
+	".kt":   javaPattern,
+	".rs":   rustPattern,
+	".rb":   rubyPattern,
+}
+
+// declarationKinds maps the first word of a declaration line to its kind
+var declarationKinds = map[string]string{
+	"def": "def", "async": "def", "class": "class", "function": "function", "function*": "function",
+	"const": "function", "let": "function", "var": "function", "interface": "interface", "enum": "enum",
+	"record": "record", "struct": "struct", "object": "object", "fun": "fun", "fn": "fn", "trait": "trait",
+	"impl": "impl", "mod": "mod", "union": "union", "module": "module",
+}
+
+// controlKeywords start lines that look like a method declaration but aren't one
+var controlKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true, "else": true, "do": true,
+}
+
+// declarationKind returns the kind of a matched declaration line, or "" when the line
+// is a control statement
+func declarationKind(match string) string {
+	word := strings.Fields(match)[0]
+	if kind, ok := declarationKinds[word]; ok {
+		return kind
+	}
+	if name, _, _ := strings.Cut(word, "("); controlKeywords[name] {
+		return ""
+	}
+	return "method"
+}
+
+// enclosingHeuristic attributes each changed line to the nearest declaration line
+// above it that is indented less, or to the declaration on the line itself. Python
+// docstrings follow the declaration; other languages' doc comments precede it.
+func enclosingHeuristic(src []byte, lines []int, pattern *regexp.Regexp, docstrings bool) []Declaration {
+	text := strings.Split(string(src), "\n")
+	var spans []span
+	byStart := make(map[int]int)
+	for _, line := range dedupe(lines) {
+		if line < 1 || line > len(text) {
+			continue
+		}
+		limit := indentation(text[line-1])
+		for i := line - 1; i >= 0; i-- {
+			if strings.TrimSpace(text[i]) == "" || i != line-1 && indentation(text[i]) >= limit {
+				continue
+			}
+			m := pattern.FindStringSubmatch(text[i])
+			if m == nil {
+				continue
+			}
+			kind := declarationKind(m[1])
+			if kind == "" {
+				continue
+			}
+			if index, ok := byStart[i]; ok {
+				spans[index].end = max(spans[index].end, line)
+				break
+			}
+			signature := strings.TrimSpace(text[i])
+			signature = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(signature, "{"), ":"))
+			doc := ""
+			if docstrings {
+				doc = pythonDocstring(text, i+1)
+			} else {
+				doc = leadingComment(text, i)
+			}
+			byStart[i] = len(spans)
+			spans = append(spans, span{decl: Declaration{Kind: kind, Signature: signature, Doc: doc}, start: i + 1, end: line})
+			break
+		}
+	}
+	return collect(spans, lines)
+}
+
+// indentation counts the leading whitespace of a line, a tab as four spaces
+func indentation(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// pythonDocstring returns the docstring starting at line index start, if any
+func pythonDocstring(text []string, start int) string {
+	if start >= len(text) {
+		return ""
+	}
+	first := strings.TrimSpace(text[start])
+	for _, quote := range []string{`"""`, `'''`} {
+		if !strings.HasPrefix(first, quote) {
+			continue
+		}
+		body := strings.TrimPrefix(first, quote)
+		if end := strings.Index(body, quote); end != -1 {
+			return shorten(body[:end])
+		}
+		parts := []string{body}
+		for i := start + 1; i < len(text) && i < start+10; i++ {
+			line := strings.TrimSpace(text[i])
+			if end := strings.Index(line, quote); end != -1 {
+				parts = append(parts, line[:end])
+				break
+			}
+			parts = append(parts, line)
+		}
+		return shorten(strings.Join(parts, " "))
+	}
+	return ""
+}
+
+// leadingComment returns the comment lines directly above line index decl, without
+// their comment markers, skipping annotations and attributes in between
+func leadingComment(text []string, decl int) string {
+	var parts []string
+	for i := decl - 1; i >= 0; i-- {
+		line := strings.TrimSpace(text[i])
+		if strings.HasPrefix(line, "@") || strings.HasPrefix(line, "#[") {
+			continue
+		}
+		stripped := strings.TrimLeft(line, "/*#! ")
+		if !(strings.HasPrefix(line, "//") || strings.HasPrefix(line, "/*") || strings.HasPrefix(line, "*") ||
+			strings.HasPrefix(line, "#")) {
+			break
+		}
+		stripped = strings.TrimSpace(strings.TrimSuffix(stripped, "*/"))
+		if stripped != "" {
+			parts = append([]string{stripped}, parts...)
+		}
+	}
+	return shorten(strings.Join(parts, " "))
+}
+
+func dedupe(lines []int) []int {
+	seen := make(map[int]bool, len(lines))
+	var unique []int
+	for _, line := range lines {
+		if !seen[line] {
+			seen[line] = true
+			unique = append(unique, line)
+		}
+	}
+	return unique
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+// Package configmigrate upgrades sgit config files from older schema versions. The
+// config file records the version of its schema in config_version; each migration
+// moves it one version forward, e.g. by renaming a setting, so that old files keep
+// working after a release changes where a setting lives.
+//
+// Migrations work on the yaml.Node tree of the file rather than on decoded values,
+// so the comments and key order the user wrote survive the upgrade.
+package configmigrate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VersionKey is the setting that holds the schema version of a config file. A file
+// without it is version 0, from before sgit versioned its config.
+const VersionKey = "config_version"
+
+// Migration upgrades a config file from the version before Version to Version
+type Migration struct {
+	Version int
+	// Description says what the migration changes, e.g. for --dry-run
+	Description string
+	// Apply changes the top-level mapping of the config file. It reports whether
+	// anything but the version changed.
+	Apply func(settings *yaml.Node) (bool, error)
+}
+
+// Migrations upgrade config files to the current version, in order. Add new ones at
+// the end, numbered one past the last, and never change one that has been released:
+// files upgraded by it won't run it again.
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "move upstage_api_key to providers.upstage.api_key",
+		Apply:       Rename("upstage_api_key", "providers.upstage.api_key"),
+	},
+}
+
+// CurrentVersion is the schema version this sgit writes
+func CurrentVersion() int {
+	return Migrations[len(Migrations)-1].Version
+}
+
+// Version returns the schema version of the config file whose top-level mapping is
+// settings
+func Version(settings *yaml.Node) (int, error) {
+	node := lookup(settings, []string{VersionKey})
+	if node == nil {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(node.Value)
+	if node.Kind != yaml.ScalarNode || err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid %s '%s' (use a whole number)", VersionKey, node.Value)
+	}
+	return version, nil
+}
+
+// Pending returns the migrations a config file still needs. A file written by a
+// newer sgit is an error, since this one can't know what its settings mean.
+func Pending(settings *yaml.Node) ([]Migration, error) {
+	version, err := Version(settings)
+	if err != nil {
+		return nil, err
+	}
+	if version > CurrentVersion() {
+		return nil, fmt.Errorf("the config file is version %d, newer than this sgit understands (%d); upgrade sgit", version, CurrentVersion())
+	}
+	var pending []Migration
+	for _, migration := range Migrations {
+		if migration.Version > version {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate runs the pending migrations on settings and sets its version to the current
+// one. It returns the migrations that changed something.
+func Migrate(settings *yaml.Node) ([]Migration, error) {
+	pending, err := Pending(settings)
+	if err != nil {
+		return nil, err
+	}
+	var applied []Migration
+	for _, migration := range pending {
+		changed, err := migration.Apply(settings)
+		if err != nil {
+			return applied, fmt.Errorf("error upgrading the config file to version %d (%s): %w", migration.Version, migration.Description, err)
+		}
+		if changed {
+			applied = append(applied, migration)
+		}
+	}
+	Stamp(settings)
+	return applied, nil
+}
+
+// Stamp sets the version of settings to the current one, keeping its place in the
+// file, or adding it at the top of a file that has none
+func Stamp(settings *yaml.Node) {
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(CurrentVersion())}
+	if node := lookup(settings, []string{VersionKey}); node != nil {
+		*node = *value
+		return
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: VersionKey}
+	// A comment at the top of the file stays there
+	if len(settings.Content) > 0 {
+		key.HeadComment, settings.Content[0].HeadComment = settings.Content[0].HeadComment, ""
+	}
+	settings.Content = append([]*yaml.Node{key, value}, settings.Content...)
+}
+
+// Rename returns a migration that moves the setting at the dotted path from to the
+// dotted path to, with its comments. When both are set, the one at to wins and the
+// one at from is dropped, since sgit only ever read the new one.
+func Rename(from, to string) func(settings *yaml.Node) (bool, error) {
+	return func(settings *yaml.Node) (bool, error) {
+		fromPath, toPath := strings.Split(from, "."), strings.Split(to, ".")
+		key, value := remove(settings, fromPath)
+		if value == nil {
+			return false, nil
+		}
+		if lookup(settings, toPath) != nil {
+			return true, nil
+		}
+		mapping := settings
+		for i, name := range toPath[:len(toPath)-1] {
+			child := lookup(mapping, []string{name})
+			if child == nil {
+				child = &yaml.Node{Kind: yaml.MappingNode}
+				mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, child)
+			} else if child.Kind != yaml.MappingNode {
+				return false, fmt.Errorf("%s is not a group of settings", strings.Join(toPath[:i+1], "."))
+			}
+			mapping = child
+		}
+		key.Value = toPath[len(toPath)-1]
+		mapping.Content = append(mapping.Content, key, value)
+		return true, nil
+	}
+}
+
+// lookup returns the value at a path of keys under mapping, or nil
+func lookup(mapping *yaml.Node, path []string) *yaml.Node {
+	for _, name := range path {
+		if mapping == nil || mapping.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			if strings.EqualFold(mapping.Content[i].Value, name) {
+				next = mapping.Content[i+1]
+				break
+			}
+		}
+		mapping = next
+	}
+	return mapping
+}
+
+// remove takes the key and value at a path of keys out of the mapping under settings,
+// leaving the rest of the mapping in place, and returns them; nil when there is none
+func remove(settings *yaml.Node, path []string) (key, value *yaml.Node) {
+	mapping := lookup(settings, path[:len(path)-1])
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if strings.EqualFold(mapping.Content[i].Value, path[len(path)-1]) {
+			key, value = mapping.Content[i], mapping.Content[i+1]
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			// The comment above the first setting is usually about the whole mapping
+			if i == 0 && len(mapping.Content) > 0 {
+				mapping.Content[0].HeadComment, key.HeadComment = key.HeadComment, ""
+			}
+			return key, value
+		}
+	}
+	return nil, nil
+}
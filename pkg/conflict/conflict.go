@@ -0,0 +1,183 @@
+package conflict
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Hunk is a single conflicted region delimited by <<<<<<< / ======= / >>>>>>> markers
+type Hunk struct {
+	Index       int    // position of the hunk within the file, starting at 0
+	StartLine   int    // 1-based line of the <<<<<<< marker
+	EndLine     int    // 1-based line of the >>>>>>> marker
+	OursLabel   string // text after <<<<<<< (e.g. HEAD)
+	TheirsLabel string // text after >>>>>>> (e.g. the merged branch or replayed commit)
+	Ours        string
+	Base        string // common ancestor, only present with merge.conflictStyle=diff3
+	Theirs      string
+	Before      string // context lines preceding the conflict
+	After       string // context lines following the conflict
+}
+
+const (
+	oursMarker   = "<<<<<<<"
+	baseMarker   = "|||||||"
+	splitMarker  = "======="
+	theirsMarker = ">>>>>>>"
+)
+
+// isMarker reports whether line is the given conflict marker, alone or followed by a label
+func isMarker(line, marker string) bool {
+	line = strings.TrimRight(line, "\r")
+	return line == marker || strings.HasPrefix(line, marker+" ")
+}
+
+func markerLabel(line, marker string) string {
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimRight(line, "\r"), marker))
+}
+
+// HasMarkers reports whether content still contains conflict markers
+func HasMarkers(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if isMarker(line, oursMarker) || isMarker(line, theirsMarker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse extracts the conflicted hunks from a file's content, including up to
+// contextLines of surrounding code on each side
+func Parse(content string, contextLines int) ([]Hunk, error) {
+	lines := strings.Split(content, "\n")
+	var hunks []Hunk
+
+	for i := 0; i < len(lines); i++ {
+		if !isMarker(lines[i], oursMarker) {
+			continue
+		}
+
+		hunk := Hunk{
+			Index:     len(hunks),
+			StartLine: i + 1,
+			OursLabel: markerLabel(lines[i], oursMarker),
+		}
+
+		var ours, base, theirs []string
+		section := &ours
+		end := -1
+		for j := i + 1; j < len(lines); j++ {
+			line := lines[j]
+			switch {
+			case isMarker(line, baseMarker) && section == &ours:
+				section = &base
+			case isMarker(line, splitMarker) && section != &theirs:
+				section = &theirs
+			case isMarker(line, theirsMarker) && section == &theirs:
+				hunk.TheirsLabel = markerLabel(line, theirsMarker)
+				end = j
+			default:
+				*section = append(*section, line)
+			}
+			if end != -1 {
+				break
+			}
+		}
+		if end == -1 {
+			return nil, fmt.Errorf("unterminated conflict starting at line %d", i+1)
+		}
+
+		hunk.EndLine = end + 1
+		hunk.Ours = strings.Join(ours, "\n")
+		hunk.Base = strings.Join(base, "\n")
+		hunk.Theirs = strings.Join(theirs, "\n")
+
+		beforeStart := i - contextLines
+		if beforeStart < 0 {
+			beforeStart = 0
+		}
+		if len(hunks) > 0 && beforeStart < hunks[len(hunks)-1].EndLine {
+			beforeStart = hunks[len(hunks)-1].EndLine
+		}
+		hunk.Before = strings.Join(lines[beforeStart:i], "\n")
+
+		afterEnd := end + 1 + contextLines
+		if afterEnd > len(lines) {
+			afterEnd = len(lines)
+		}
+		for k := end + 1; k < afterEnd; k++ {
+			if isMarker(lines[k], oursMarker) {
+				afterEnd = k
+				break
+			}
+		}
+		hunk.After = strings.Join(lines[end+1:afterEnd], "\n")
+
+		hunks = append(hunks, hunk)
+		i = end
+	}
+
+	return hunks, nil
+}
+
+// String renders the hunk with its context and markers, as it appears in the file
+func (h Hunk) String() string {
+	var b strings.Builder
+	if h.Before != "" {
+		b.WriteString(h.Before + "\n")
+	}
+	b.WriteString(strings.TrimSpace(oursMarker+" "+h.OursLabel) + "\n")
+	if h.Ours != "" {
+		b.WriteString(h.Ours + "\n")
+	}
+	if h.Base != "" {
+		b.WriteString(baseMarker + " base\n" + h.Base + "\n")
+	}
+	b.WriteString(splitMarker + "\n")
+	if h.Theirs != "" {
+		b.WriteString(h.Theirs + "\n")
+	}
+	b.WriteString(strings.TrimSpace(theirsMarker + " " + h.TheirsLabel))
+	if h.After != "" {
+		b.WriteString("\n" + h.After)
+	}
+	return b.String()
+}
+
+// Resolve replaces the conflicted hunks at the given indices with their
+// resolutions. Hunks without a resolution are left untouched.
+func Resolve(content string, resolutions map[int]string) (string, error) {
+	hunks, err := Parse(content, 0)
+	if err != nil {
+		return "", err
+	}
+
+	indices := make([]int, 0, len(resolutions))
+	for index := range resolutions {
+		if index < 0 || index >= len(hunks) {
+			return "", fmt.Errorf("conflict hunk %d does not exist", index+1)
+		}
+		indices = append(indices, index)
+	}
+
+	// Replace from the bottom up so earlier line numbers stay valid
+	sort.Sort(sort.Reverse(sort.IntSlice(indices)))
+
+	lines := strings.Split(content, "\n")
+	for _, index := range indices {
+		hunk := hunks[index]
+		resolution := strings.TrimSuffix(resolutions[index], "\n")
+
+		var replacement []string
+		if resolution != "" {
+			replacement = strings.Split(resolution, "\n")
+		}
+
+		updated := append([]string{}, lines[:hunk.StartLine-1]...)
+		updated = append(updated, replacement...)
+		lines = append(updated, lines[hunk.EndLine:]...)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,302 @@
+// Package difffilter removes noise from unified diffs before they are sent to the
+// model: lock files, generated code, vendored dependencies and hunks that only change
+// whitespace. What was left out is reported so the prompt can still mention it.
+package difffilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/glob"
+)
+
+// Reasons a file is left out of the diff
+const (
+	ReasonLockFile   = "lock file"
+	ReasonGenerated  = "generated"
+	ReasonVendored   = "vendored"
+	ReasonExcluded   = "excluded"
+	ReasonWhitespace = "whitespace only"
+)
+
+// LockFiles are dependency lock files, regenerated by package managers
+var LockFiles = []string{
+	"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml", "bun.lockb",
+	"go.sum", "Cargo.lock", "poetry.lock", "Pipfile.lock", "uv.lock", "composer.lock",
+	"Gemfile.lock", "Podfile.lock", "pubspec.lock", "mix.lock", "flake.lock",
+	"packages.lock.json", "gradle.lockfile",
+}
+
+// GeneratedFiles are files produced by tools rather than written by hand
+var GeneratedFiles = []string{
+	"*.pb.go", "*_pb2.py", "*_pb2_grpc.py", "*.pb.cc", "*.pb.h", "*_generated.go",
+	"*.gen.go", "*.generated.*", "*.min.js", "*.min.css", "*.map",
+}
+
+// VendoredDirs hold third-party code copied into the repository
+var VendoredDirs = []string{"vendor/", "node_modules/", "third_party/", "bower_components/"}
+
+// generatedMarker matches the comment code generators put at the top of their output
+// ("// Code generated ... DO NOT EDIT." in Go, "@generated" elsewhere)
+var generatedMarker = regexp.MustCompile(`^[+ -]\s*(//|#|/\*|\*)\s*(Code generated .* DO NOT EDIT\.?|.*@generated\b)`)
+
+// Options selects what Filter removes
+type Options struct {
+	// Exclude are extra glob patterns (see package glob) of files to leave out
+	Exclude []string
+	// Keep are glob patterns of files to send even though they look like noise
+	Keep []string
+	// KeepWhitespace keeps hunks that only change whitespace
+	KeepWhitespace bool
+}
+
+// Omitted is a file left out of the diff
+type Omitted struct {
+	Path   string
+	Reason string
+	// Lines is the number of added and removed lines left out
+	Lines int
+}
+
+// Result is a filtered diff
+type Result struct {
+	Diff    string
+	Omitted []Omitted
+	// WhitespaceHunks counts the whitespace-only hunks removed from files that are kept
+	WhitespaceHunks int
+}
+
+// Filter removes noise from a unified diff (as printed by git diff)
+func Filter(diff string, options Options) Result {
+	var result Result
+	var b strings.Builder
+	for _, file := range splitFiles(diff) {
+		if file.path == "" {
+			b.WriteString(file.text)
+			continue
+		}
+		if _, keep := glob.MatchAny(options.Keep, file.path); !keep {
+			if reason := noiseReason(file, options.Exclude); reason != "" {
+				result.Omitted = append(result.Omitted, Omitted{Path: file.path, Reason: reason, Lines: file.changedLines()})
+				continue
+			}
+		}
+		if options.KeepWhitespace {
+			b.WriteString(file.text)
+			continue
+		}
+
+		text, dropped, remaining := dropWhitespaceHunks(file)
+		if dropped > 0 && remaining == 0 {
+			result.Omitted = append(result.Omitted, Omitted{Path: file.path, Reason: ReasonWhitespace, Lines: file.changedLines()})
+			continue
+		}
+		result.WhitespaceHunks += dropped
+		b.WriteString(text)
+	}
+	result.Diff = b.String()
+	return result
+}
+
+// Note describes what was left out, for the prompt, or "" when nothing was
+func (r Result) Note() string {
+	if len(r.Omitted) == 0 && r.WhitespaceHunks == 0 {
+		return ""
+	}
+	var parts []string
+	for _, omitted := range r.Omitted {
+		parts = append(parts, fmt.Sprintf("%s (%s, %d changed lines)", omitted.Path, omitted.Reason, omitted.Lines))
+	}
+	if r.WhitespaceHunks > 0 {
+		parts = append(parts, fmt.Sprintf("%d whitespace-only hunk(s) in other files", r.WhitespaceHunks))
+	}
+	return "Left out of this diff as noise: " + strings.Join(parts, "; ")
+}
+
+// Condense shortens a diff to a summary per file: the file's header, its count of
+// added and removed lines, and its first linesPerFile changed lines. It keeps a diff
+// too large to send whole (e.g. after vendoring a dependency) readable for the model.
+func Condense(diff string, linesPerFile int) string {
+	var b strings.Builder
+	for _, file := range splitFiles(diff) {
+		if file.path == "" {
+			b.WriteString(file.text)
+			continue
+		}
+		added, removed, shown, skipped := 0, 0, 0, 0
+		var header, changes []string
+		inHunk := false
+		for _, line := range strings.Split(strings.TrimRight(file.text, "\n"), "\n") {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				inHunk = true
+				if shown < linesPerFile {
+					changes = append(changes, line)
+				}
+			case !inHunk:
+				header = append(header, line)
+			case strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-"):
+				if strings.HasPrefix(line, "+") {
+					added++
+				} else {
+					removed++
+				}
+				if shown < linesPerFile {
+					changes = append(changes, line)
+					shown++
+				} else {
+					skipped++
+				}
+			}
+		}
+		b.WriteString(strings.Join(header, "\n") + "\n")
+		fmt.Fprintf(&b, "[summary: +%d -%d lines]\n", added, removed)
+		if len(changes) > 0 {
+			b.WriteString(strings.Join(changes, "\n") + "\n")
+		}
+		if skipped > 0 {
+			fmt.Fprintf(&b, "[... %d more changed lines ...]\n", skipped)
+		}
+	}
+	return b.String()
+}
+
+// fileDiff is the part of a diff for one file
+type fileDiff struct {
+	path string
+	text string
+}
+
+// diffHeader matches the first line of a file's diff and captures the new path
+var diffHeader = regexp.MustCompile(`^diff --git "?a/.*?"? "?b/(.*?)"?$`)
+
+// splitFiles splits a diff at its "diff --git" lines. Text before the first one (e.g.
+// a commit header from git show) is returned with an empty path.
+func splitFiles(diff string) []fileDiff {
+	var files []fileDiff
+	current := fileDiff{}
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			current.text = b.String()
+			if current.text != "" {
+				files = append(files, current)
+			}
+			b.Reset()
+			current = fileDiff{}
+			if match := diffHeader.FindStringSubmatch(strings.TrimRight(line, "\n")); match != nil {
+				current.path = match[1]
+			}
+		}
+		b.WriteString(line)
+	}
+	current.text = b.String()
+	if current.text != "" {
+		files = append(files, current)
+	}
+	return files
+}
+
+// changedLines counts the added and removed lines of a file's diff
+func (f fileDiff) changedLines() int {
+	count := 0
+	inHunk := false
+	for _, line := range strings.Split(f.text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case inHunk && (strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")):
+			count++
+		}
+	}
+	return count
+}
+
+// noiseReason tells why a file is noise, or returns "" when it isn't
+func noiseReason(file fileDiff, exclude []string) string {
+	switch {
+	case matches(LockFiles, file.path):
+		return ReasonLockFile
+	case matches(VendoredDirs, file.path):
+		return ReasonVendored
+	case matches(GeneratedFiles, file.path) || hasGeneratedMarker(file.text):
+		return ReasonGenerated
+	case matches(exclude, file.path):
+		return ReasonExcluded
+	}
+	return ""
+}
+
+func matches(patterns []string, path string) bool {
+	_, ok := glob.MatchAny(patterns, path)
+	return ok
+}
+
+// hasGeneratedMarker looks for a generated-code comment in the first lines of each hunk,
+// where generators put it
+func hasGeneratedMarker(text string) bool {
+	linesInHunk := -1
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			linesInHunk = 0
+			continue
+		}
+		if linesInHunk < 0 || linesInHunk >= 10 {
+			continue
+		}
+		linesInHunk++
+		if generatedMarker.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropWhitespaceHunks removes the hunks whose removed and added lines differ only in
+// whitespace, returning the file's remaining diff and the number of hunks dropped
+// and kept
+func dropWhitespaceHunks(file fileDiff) (string, int, int) {
+	lines := strings.SplitAfter(file.text, "\n")
+	var b strings.Builder
+	dropped, kept := 0, 0
+
+	i := 0
+	// The header up to the first hunk
+	for ; i < len(lines) && !strings.HasPrefix(lines[i], "@@"); i++ {
+		b.WriteString(lines[i])
+	}
+	for i < len(lines) {
+		start := i
+		for i++; i < len(lines) && !strings.HasPrefix(lines[i], "@@"); i++ {
+		}
+		hunk := lines[start:i]
+		if whitespaceOnly(hunk[1:]) {
+			dropped++
+			continue
+		}
+		kept++
+		for _, line := range hunk {
+			b.WriteString(line)
+		}
+	}
+	return b.String(), dropped, kept
+}
+
+// whitespaceOnly reports whether a hunk changes lines and the removed and added lines
+// are the same once all whitespace is removed
+func whitespaceOnly(hunk []string) bool {
+	var removed, added strings.Builder
+	changed := false
+	for _, line := range hunk {
+		switch {
+		case strings.HasPrefix(line, "-"):
+			removed.WriteString(strings.Join(strings.Fields(line[1:]), ""))
+			changed = true
+		case strings.HasPrefix(line, "+"):
+			added.WriteString(strings.Join(strings.Fields(line[1:]), ""))
+			changed = true
+		}
+	}
+	return changed && removed.String() == added.String()
+}
@@ -0,0 +1,386 @@
+// Package engine exposes sgit's commit message and diff summary generation as a Go
+// API. It never writes to stdout or stderr, so it can be embedded in other tools:
+//
+//	client := solar.NewClient(apiKey, "", "en")
+//	eng := engine.New(client, git.NewCLI("/path/to/repo"))
+//	message, err := eng.GenerateCommitMessage(ctx)
+package engine
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/assets"
+	"github.com/hunkim/sgit/pkg/codecontext"
+	"github.com/hunkim/sgit/pkg/git"
+	"github.com/hunkim/sgit/pkg/solar"
+)
+
+const (
+	// recentCommitCount is how many recent commits are shown to the model for style
+	recentCommitCount = 5
+	// previewMaxBytes limits which new files get a content preview
+	previewMaxBytes = 50 * 1024
+	// previewMaxLines is the length of a new file's content preview
+	previewMaxLines = 20
+	// maxDeclarationsPerFile bounds the changed declarations listed for a file
+	maxDeclarationsPerFile = 8
+	// maxImageBytes limits which staged images are read for their dimensions
+	maxImageBytes = 10 * 1024 * 1024
+)
+
+// ErrNotRepository is returned when the engine's directory is not inside a git repository
+var ErrNotRepository = errors.New("not a git repository")
+
+// ErrNoStagedChanges is returned when a commit message is requested with nothing staged
+var ErrNoStagedChanges = errors.New("no staged changes - add files with 'git add' first")
+
+// ErrEmptyDiff is returned when a diff summary is requested for an empty diff
+var ErrEmptyDiff = errors.New("no differences to summarize")
+
+// ErrNoCommits is returned when a log analysis is requested for an empty history
+var ErrNoCommits = errors.New("no commits found")
+
+// Engine generates commit messages and diff summaries for a repository
+type Engine struct {
+	client    *solar.Client
+	repo      git.Repository
+	providers *Registry
+}
+
+// New creates an engine. Configure the client (convention, language, retries, cache)
+// with its setters before or after creating the engine.
+func New(client *solar.Client, repo git.Repository) *Engine {
+	return &Engine{client: client, repo: repo, providers: NewRegistry(DefaultProviders(repo)...)}
+}
+
+// Client returns the Solar LLM client used by the engine
+func (e *Engine) Client() *solar.Client {
+	return e.client
+}
+
+// Repository returns the repository the engine reads from
+func (e *Engine) Repository() git.Repository {
+	return e.repo
+}
+
+// ContextProviders returns the registry of providers the commit context is collected
+// from, to add providers to or disable them
+func (e *Engine) ContextProviders() *Registry {
+	return e.providers
+}
+
+// CommitContext is everything the model sees when writing a commit message
+type CommitContext struct {
+	Diff          string
+	Branch        string
+	RecentCommits string
+	FileList      string
+	// Hints are what providers found out about the change's intent, such as its
+	// ticket; merge them with the developer's own before setting them on the client
+	Hints solar.CommitHints
+	// Sections are further context, such as build or test results, shown to the
+	// model under their titles
+	Sections []solar.ContextSection
+}
+
+// CommitContext collects the context of the staged changes from the engine's context
+// providers: by default the staged diff, the branch, the recent commits and a
+// description of the staged files. It returns ErrNoStagedChanges when nothing is
+// staged.
+func (e *Engine) CommitContext(ctx context.Context) (*CommitContext, error) {
+	if !e.repo.IsRepository(ctx) {
+		return nil, ErrNotRepository
+	}
+	return e.providers.Collect(ctx)
+}
+
+// GenerateCommitMessage writes a commit message for the staged changes
+func (e *Engine) GenerateCommitMessage(ctx context.Context) (string, error) {
+	return e.GenerateCommitMessageStream(ctx, nil)
+}
+
+// GenerateCommitMessageStream writes a commit message for the staged changes, calling
+// onChunk with each piece of the response as it arrives (nil to disable streaming)
+func (e *Engine) GenerateCommitMessageStream(ctx context.Context, onChunk func(string)) (string, error) {
+	commitContext, err := e.CommitContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return e.GenerateCommitMessageFor(ctx, commitContext, onChunk)
+}
+
+// GenerateCommitMessageFor writes a commit message for an already collected context,
+// e.g. one built from a diff that is not staged in any repository
+func (e *Engine) GenerateCommitMessageFor(ctx context.Context, commitContext *CommitContext, onChunk func(string)) (string, error) {
+	e.client.SetContextSections(commitContext.Sections)
+	if onChunk == nil {
+		return e.client.GenerateComprehensiveCommitMessage(ctx, commitContext.Diff, commitContext.Branch,
+			commitContext.RecentCommits, commitContext.FileList)
+	}
+	return e.client.GenerateComprehensiveCommitMessageStream(ctx, commitContext.Diff, commitContext.Branch,
+		commitContext.RecentCommits, commitContext.FileList, onChunk)
+}
+
+// SummarizeDiff summarizes the output of git diff with the given arguments (e.g.
+// "--cached" or "main..feature"; none for the unstaged changes). It returns
+// ErrEmptyDiff when there is nothing to summarize.
+func (e *Engine) SummarizeDiff(ctx context.Context, args ...string) (string, error) {
+	return e.SummarizeDiffStream(ctx, nil, args...)
+}
+
+// SummarizeDiffStream is SummarizeDiff with streaming; onChunk is called with each
+// piece of the response as it arrives (nil to disable streaming)
+func (e *Engine) SummarizeDiffStream(ctx context.Context, onChunk func(string), args ...string) (string, error) {
+	diff, err := e.diff(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+
+	if onChunk == nil {
+		return e.client.SummarizeDiff(ctx, diff)
+	}
+	return e.client.SummarizeDiffStream(ctx, diff, onChunk)
+}
+
+// ReviewDiff reviews the output of git diff with the given arguments, like
+// SummarizeDiff, pointing out bugs, security and performance problems
+func (e *Engine) ReviewDiff(ctx context.Context, args ...string) (string, error) {
+	return e.ReviewDiffStream(ctx, nil, args...)
+}
+
+// ReviewDiffStream is ReviewDiff with streaming; onChunk is called with each piece of
+// the response as it arrives (nil to disable streaming)
+func (e *Engine) ReviewDiffStream(ctx context.Context, onChunk func(string), args ...string) (string, error) {
+	diff, err := e.diff(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+
+	// The names of the changed files help the model see the whole change at once
+	fileList, _ := e.repo.Diff(ctx, append([]string{"--stat"}, args...)...)
+	scope := "unstaged changes (git diff)"
+	if len(args) > 0 {
+		scope = "git diff " + strings.Join(args, " ")
+	}
+
+	if onChunk == nil {
+		return e.client.ReviewDiff(ctx, diff, scope, fileList)
+	}
+	return e.client.ReviewDiffStream(ctx, diff, scope, fileList, onChunk)
+}
+
+// AnalyzeLog analyzes the development patterns in the output of git log with the given
+// arguments (e.g. "-20" or "v1.0..HEAD"); timeframe describes the period for the
+// model, e.g. "last 20 commits". It returns ErrNoCommits for an empty history.
+func (e *Engine) AnalyzeLog(ctx context.Context, timeframe string, args ...string) (string, error) {
+	return e.AnalyzeLogStream(ctx, timeframe, nil, args...)
+}
+
+// AnalyzeLogStream is AnalyzeLog with streaming; onChunk is called with each piece of
+// the response as it arrives (nil to disable streaming)
+func (e *Engine) AnalyzeLogStream(ctx context.Context, timeframe string, onChunk func(string), args ...string) (string, error) {
+	if !e.repo.IsRepository(ctx) {
+		return "", ErrNotRepository
+	}
+
+	logOutput, err := e.repo.Log(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("error getting git log: %w", err)
+	}
+	if strings.TrimSpace(logOutput) == "" {
+		return "", ErrNoCommits
+	}
+
+	if onChunk == nil {
+		return e.client.AnalyzeLog(ctx, logOutput, timeframe)
+	}
+	return e.client.AnalyzeLogStream(ctx, logOutput, timeframe, onChunk)
+}
+
+// diff returns the output of git diff with the given arguments, or ErrEmptyDiff
+func (e *Engine) diff(ctx context.Context, args ...string) (string, error) {
+	if !e.repo.IsRepository(ctx) {
+		return "", ErrNotRepository
+	}
+
+	diff, err := e.repo.Diff(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("error getting git diff: %w", err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		return "", ErrEmptyDiff
+	}
+	return diff, nil
+}
+
+// DescribeStagedFiles lists the staged files with their status and size, adding a
+// content preview for small new text files and, for source files, the functions and
+// types the changes fall in with their doc comments. Binary files, which have no
+// diff, are described by their kind, old and new size and image dimensions.
+func DescribeStagedFiles(ctx context.Context, repo git.Repository) (string, error) {
+	files, err := repo.StagedFiles(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get staged files: %w", err)
+	}
+	if len(files) == 0 {
+		return "No files staged for commit", nil
+	}
+
+	// Staged paths are relative to the top of the working tree
+	root, err := repo.Root(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// The declarations the changes fall in are read from the staged version of each file
+	var changedLines map[string][]int
+	if diff, err := repo.StagedDiff(ctx); err == nil {
+		changedLines = codecontext.ChangedLines(diff)
+	}
+	binaryFiles := stagedBinaryFiles(ctx, repo)
+
+	var fileInfo []string
+	for _, file := range files {
+		fullPath := filepath.Join(root, filepath.FromSlash(file.Path))
+
+		fileSize := "unknown"
+		stat, statErr := os.Stat(fullPath)
+		if statErr == nil {
+			fileSize = fmt.Sprintf("%d bytes", stat.Size())
+		}
+		if oldPath, ok := binaryFiles[file.Path]; ok {
+			fileSize = describeBinaryChange(ctx, repo, file, oldPath)
+		}
+
+		fileDesc := fmt.Sprintf("- %s %s (%s)", file.Status, file.Path, fileSize)
+
+		// For new files (A = Added), include content preview
+		if file.Status == "A" && statErr == nil && stat.Size() <= previewMaxBytes && !git.IsBinaryFile(fullPath) {
+			contentPreview := fileContentPreview(fullPath, previewMaxLines)
+			fileDesc += fmt.Sprintf("\n  Content preview:\n%s",
+				strings.ReplaceAll(contentPreview, "\n", "\n  "))
+		}
+		if declarations := describeChangedDeclarations(ctx, repo, file, changedLines[file.Path]); declarations != "" {
+			fileDesc += "\n  Changed declarations:\n" + declarations
+		}
+
+		fileInfo = append(fileInfo, fileDesc)
+	}
+
+	return strings.Join(fileInfo, "\n"), nil
+}
+
+// stagedBinaryFiles maps the staged files git treats as binary to their path before
+// the change, which differs from the staged path for renames
+func stagedBinaryFiles(ctx context.Context, repo git.Repository) map[string]string {
+	output, err := repo.Output(ctx, "diff", "--cached", "--numstat", "-z")
+	if err != nil {
+		return nil
+	}
+	files := make(map[string]string)
+	// Each entry is "added\tremoved\tpath", or "added\tremoved\t" followed by the
+	// old and the new path for renames and copies
+	entries := strings.Split(output, "\x00")
+	for i := 0; i < len(entries); i++ {
+		fields := strings.SplitN(entries[i], "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		oldPath, newPath := fields[2], fields[2]
+		if newPath == "" && i+2 < len(entries) {
+			oldPath, newPath = entries[i+1], entries[i+2]
+			i += 2
+		}
+		// Binary files have "-" counts
+		if fields[0] == "-" && fields[1] == "-" {
+			files[newPath] = oldPath
+		}
+	}
+	return files
+}
+
+// describeBinaryChange describes a staged binary file from its last committed and
+// its staged version
+func describeBinaryChange(ctx context.Context, repo git.Repository, file git.FileStatus, oldPath string) string {
+	var old, new *assets.Version
+	if file.Status != "A" {
+		old = blobVersion(ctx, repo, "HEAD:"+oldPath, oldPath)
+	}
+	if file.Status != "D" {
+		new = blobVersion(ctx, repo, ":"+file.Path, file.Path)
+	}
+	return assets.Describe(file.Path, old, new)
+}
+
+// blobVersion returns the size of a blob, and its content when it is an image small
+// enough to read the dimensions of, or nil when the blob doesn't exist
+func blobVersion(ctx context.Context, repo git.Repository, object, path string) *assets.Version {
+	output, err := repo.Output(ctx, "cat-file", "-s", object)
+	if err != nil {
+		return nil
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return nil
+	}
+	version := &assets.Version{Size: size}
+	if assets.Kind(path) == "image" && size <= maxImageBytes {
+		if data, err := repo.Output(ctx, "cat-file", "blob", object); err == nil {
+			version.Data = []byte(data)
+		}
+	}
+	return version
+}
+
+// describeChangedDeclarations lists the declarations of a staged source file that the
+// changed lines fall in, one per line, or returns ""
+func describeChangedDeclarations(ctx context.Context, repo git.Repository, file git.FileStatus, lines []int) string {
+	if file.Status == "D" || len(lines) == 0 || !codecontext.Supported(file.Path) {
+		return ""
+	}
+	src, err := repo.Output(ctx, "show", ":"+file.Path)
+	if err != nil {
+		return ""
+	}
+	declarations := codecontext.Enclosing(file.Path, []byte(src), lines)
+	if len(declarations) == 0 {
+		return ""
+	}
+	more := ""
+	if len(declarations) > maxDeclarationsPerFile {
+		more = fmt.Sprintf("\n    (and %d more)", len(declarations)-maxDeclarationsPerFile)
+		declarations = declarations[:maxDeclarationsPerFile]
+	}
+	return "    " + strings.ReplaceAll(codecontext.Describe(declarations), "\n", "\n    ") + more
+}
+
+// fileContentPreview returns the first maxLines lines of a file
+func fileContentPreview(filePath string, maxLines int) string {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Sprintf("Error reading file: %v", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for len(lines) < maxLines && scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Sprintf("Error scanning file: %v", err)
+	}
+
+	content := strings.Join(lines, "\n")
+	if len(lines) == maxLines && scanner.Scan() {
+		content += "\n... (file continues)"
+	}
+	return content
+}
@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/git"
+)
+
+// Names of the built-in context providers
+const (
+	ProviderDiff          = "diff"
+	ProviderBranch        = "branch"
+	ProviderRecentCommits = "recent_commits"
+	ProviderFiles         = "files"
+)
+
+// ContextProvider supplies one kind of context for commit messages, such as the
+// diff, the recent commits or a ticket. A Registry runs its providers in order, each
+// adding to the CommitContext the ones before it built.
+type ContextProvider interface {
+	// Name identifies the provider, e.g. to disable it in config
+	Name() string
+	// Provide adds the provider's context to commitContext. An error stops the
+	// collection, so providers of optional context leave it out instead.
+	Provide(ctx context.Context, commitContext *CommitContext) error
+}
+
+// NewProvider returns a ContextProvider named name that runs provide
+func NewProvider(name string, provide func(ctx context.Context, commitContext *CommitContext) error) ContextProvider {
+	return &providerFunc{name: name, provide: provide}
+}
+
+type providerFunc struct {
+	name    string
+	provide func(ctx context.Context, commitContext *CommitContext) error
+}
+
+func (p *providerFunc) Name() string {
+	return p.name
+}
+
+func (p *providerFunc) Provide(ctx context.Context, commitContext *CommitContext) error {
+	return p.provide(ctx, commitContext)
+}
+
+// DefaultProviders returns the built-in providers for repo: the staged diff, the
+// branch, the recent commits and a description of the staged files
+func DefaultProviders(repo git.Repository) []ContextProvider {
+	return []ContextProvider{
+		NewProvider(ProviderDiff, func(ctx context.Context, commitContext *CommitContext) error {
+			diff, err := repo.StagedDiff(ctx)
+			if err != nil {
+				return fmt.Errorf("error getting git diff: %w", err)
+			}
+			if strings.TrimSpace(diff) == "" {
+				return ErrNoStagedChanges
+			}
+			commitContext.Diff = diff
+			return nil
+		}),
+		// The rest is optional context; a fresh repository has no commits yet
+		NewProvider(ProviderBranch, func(ctx context.Context, commitContext *CommitContext) error {
+			commitContext.Branch, _ = repo.CurrentBranch(ctx)
+			return nil
+		}),
+		NewProvider(ProviderRecentCommits, func(ctx context.Context, commitContext *CommitContext) error {
+			commitContext.RecentCommits, _ = repo.RecentCommits(ctx, recentCommitCount)
+			return nil
+		}),
+		NewProvider(ProviderFiles, func(ctx context.Context, commitContext *CommitContext) error {
+			commitContext.FileList, _ = DescribeStagedFiles(ctx, repo)
+			return nil
+		}),
+	}
+}
+
+// Registry is an ordered set of context providers, some of which may be disabled
+type Registry struct {
+	providers []ContextProvider
+	disabled  map[string]bool
+}
+
+// NewRegistry returns a registry of providers, run in the order given
+func NewRegistry(providers ...ContextProvider) *Registry {
+	r := &Registry{disabled: make(map[string]bool)}
+	for _, provider := range providers {
+		r.Register(provider)
+	}
+	return r
+}
+
+// Register adds a provider after the others, or replaces the one with the same name
+// in its place
+func (r *Registry) Register(provider ContextProvider) {
+	for i, existing := range r.providers {
+		if existing.Name() == provider.Name() {
+			r.providers[i] = provider
+			return
+		}
+	}
+	r.providers = append(r.providers, provider)
+}
+
+// Lookup returns the provider named name, or nil
+func (r *Registry) Lookup(name string) ContextProvider {
+	for _, provider := range r.providers {
+		if provider.Name() == name {
+			return provider
+		}
+	}
+	return nil
+}
+
+// Disable turns providers off by name. The diff can't be turned off.
+func (r *Registry) Disable(names ...string) {
+	for _, name := range names {
+		if name != ProviderDiff {
+			r.disabled[name] = true
+		}
+	}
+}
+
+// Enabled reports whether the provider named name is registered and not disabled
+func (r *Registry) Enabled(name string) bool {
+	return r.Lookup(name) != nil && !r.disabled[name]
+}
+
+// Names lists the registered providers in order
+func (r *Registry) Names() []string {
+	names := make([]string, len(r.providers))
+	for i, provider := range r.providers {
+		names[i] = provider.Name()
+	}
+	return names
+}
+
+// Collect runs the enabled providers in order and returns the context they built
+func (r *Registry) Collect(ctx context.Context) (*CommitContext, error) {
+	commitContext := &CommitContext{}
+	for _, provider := range r.providers {
+		if r.disabled[provider.Name()] {
+			continue
+		}
+		if err := provider.Provide(ctx, commitContext); err != nil {
+			return nil, err
+		}
+	}
+	return commitContext, nil
+}
@@ -0,0 +1,253 @@
+// Package git wraps the git operations sgit uses to build AI prompts behind the
+// Repository interface, so the same logic can run against any working tree and be
+// embedded in other tools.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hunkim/sgit/pkg/logging"
+)
+
+// Repository is the set of read operations sgit needs from a git working tree
+type Repository interface {
+	// IsRepository reports whether the working directory is inside the working tree of
+	// a git repository
+	IsRepository(ctx context.Context) bool
+	// Root returns the absolute path of the top-level working tree directory
+	Root(ctx context.Context) (string, error)
+	// CurrentBranch returns the checked-out branch, or "" on a detached HEAD
+	CurrentBranch(ctx context.Context) (string, error)
+	// HasStagedChanges reports whether the index differs from HEAD
+	HasStagedChanges(ctx context.Context) (bool, error)
+	// StagedDiff returns the unified diff of the staged changes
+	StagedDiff(ctx context.Context) (string, error)
+	// StagedFiles returns the staged files with their name-status letter
+	StagedFiles(ctx context.Context) ([]FileStatus, error)
+	// UntrackedFiles returns the untracked files that are not ignored
+	UntrackedFiles(ctx context.Context) ([]string, error)
+	// Status returns the working tree status in git status --porcelain format
+	Status(ctx context.Context) (string, error)
+	// RecentCommits returns the last n non-merge commits in --oneline format
+	RecentCommits(ctx context.Context, n int) (string, error)
+	// Diff returns the output of git diff with the given arguments
+	Diff(ctx context.Context, args ...string) (string, error)
+	// Log returns the output of git log with the given arguments
+	Log(ctx context.Context, args ...string) (string, error)
+	// Blame returns the output of git blame --porcelain with the given arguments
+	Blame(ctx context.Context, args ...string) (string, error)
+	// Output runs any git command and returns its stdout
+	Output(ctx context.Context, args ...string) (string, error)
+}
+
+// FileStatus is a changed file as reported by git diff --name-status
+type FileStatus struct {
+	Status string // A, M, D, R100, ...
+	Path   string // the new path for renames and copies
+}
+
+// Backend names accepted by New (the git_backend config key)
+const (
+	BackendCLI   = "cli"
+	BackendGoGit = "go-git"
+)
+
+// New returns a Repository for the working tree at dir using the named backend
+// ("" selects the git CLI)
+func New(backend, dir string) (Repository, error) {
+	switch strings.ToLower(strings.TrimSpace(backend)) {
+	case "", BackendCLI:
+		return NewCLI(dir), nil
+	case BackendGoGit, "gogit":
+		return NewGoGit(dir), nil
+	}
+	return nil, fmt.Errorf("unknown git backend '%s' (use %s or %s)", backend, BackendCLI, BackendGoGit)
+}
+
+// CLI implements Repository by running the git binary
+type CLI struct {
+	dir string
+}
+
+// NewCLI returns a Repository for the working tree at dir ("" for the current directory)
+func NewCLI(dir string) *CLI {
+	return &CLI{dir: dir}
+}
+
+// Output runs git with args in the repository directory and returns its stdout. On
+// failure the error includes the first line of git's stderr.
+func (r *CLI) Output(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	start := time.Now()
+	output, err := cmd.Output()
+	logging.Debug("git command", "args", args, "duration", time.Since(start), "bytes", len(output), "error", err)
+	if err != nil {
+		if message, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); message != "" {
+			return "", fmt.Errorf("%v: %s", err, message)
+		}
+		return "", err
+	}
+	return string(output), nil
+}
+
+// IsRepository reports whether the working directory is inside the working tree of a
+// git repository; a bare repository has none
+func (r *CLI) IsRepository(ctx context.Context) bool {
+	output, err := r.Output(ctx, "rev-parse", "--is-inside-work-tree")
+	return err == nil && strings.TrimSpace(output) == "true"
+}
+
+// Root returns the absolute path of the top-level working tree directory
+func (r *CLI) Root(ctx context.Context) (string, error) {
+	output, err := r.Output(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// CurrentBranch returns the checked-out branch, or "" on a detached HEAD
+func (r *CLI) CurrentBranch(ctx context.Context) (string, error) {
+	output, err := r.Output(ctx, "branch", "--show-current")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// HasStagedChanges reports whether the index differs from HEAD
+func (r *CLI) HasStagedChanges(ctx context.Context) (bool, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", "--cached", "--quiet")
+	cmd.Dir = r.dir
+	start := time.Now()
+	err := cmd.Run()
+	logging.Debug("git command", "args", cmd.Args[1:], "duration", time.Since(start), "error", err)
+	if err != nil {
+		// git diff --quiet exits with 1 when there are differences
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			return exitError.ExitCode() != 0, nil
+		}
+		return false, err
+	}
+	return false, nil
+}
+
+// StagedDiff returns the unified diff of the staged changes
+func (r *CLI) StagedDiff(ctx context.Context) (string, error) {
+	return r.Output(ctx, "diff", "--cached")
+}
+
+// StagedFiles returns the staged files with their name-status letter
+func (r *CLI) StagedFiles(ctx context.Context) ([]FileStatus, error) {
+	output, err := r.Output(ctx, "diff", "--cached", "--name-status")
+	if err != nil {
+		return nil, err
+	}
+	return ParseNameStatus(output), nil
+}
+
+// UntrackedFiles returns the untracked files that are not ignored
+func (r *CLI) UntrackedFiles(ctx context.Context) ([]string, error) {
+	output, err := r.Output(ctx, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(output), nil
+}
+
+// Status returns the working tree status in git status --porcelain format
+func (r *CLI) Status(ctx context.Context) (string, error) {
+	return r.Output(ctx, "status", "--porcelain")
+}
+
+// RecentCommits returns the last n non-merge commits in --oneline format
+func (r *CLI) RecentCommits(ctx context.Context, n int) (string, error) {
+	output, err := r.Output(ctx, "log", fmt.Sprintf("-%d", n), "--oneline", "--no-merges")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// Diff returns the output of git diff with the given arguments
+func (r *CLI) Diff(ctx context.Context, args ...string) (string, error) {
+	return r.Output(ctx, append([]string{"diff"}, args...)...)
+}
+
+// Log returns the output of git log with the given arguments
+func (r *CLI) Log(ctx context.Context, args ...string) (string, error) {
+	return r.Output(ctx, append([]string{"log"}, args...)...)
+}
+
+// Blame returns the output of git blame --porcelain with the given arguments
+func (r *CLI) Blame(ctx context.Context, args ...string) (string, error) {
+	return r.Output(ctx, append([]string{"blame", "--porcelain"}, args...)...)
+}
+
+// ParseNameStatus parses git diff --name-status output
+func ParseNameStatus(output string) []FileStatus {
+	var files []FileStatus
+	for _, line := range splitLines(output) {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		// Renames and copies list the old and the new path
+		files = append(files, FileStatus{Status: fields[0], Path: fields[len(fields)-1]})
+	}
+	return files
+}
+
+// binaryExts are extensions that are treated as binary without reading the file
+var binaryExts = map[string]bool{
+	".exe": true, ".dll": true, ".so": true, ".dylib": true, ".a": true, ".o": true, ".obj": true,
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".bmp": true, ".ico": true, ".tiff": true, ".svg": true,
+	".mp3": true, ".mp4": true, ".avi": true, ".mov": true, ".mkv": true, ".flv": true, ".wav": true,
+	".zip": true, ".tar": true, ".gz": true, ".bz2": true, ".xz": true, ".7z": true, ".rar": true,
+	".pdf": true, ".doc": true, ".docx": true, ".xls": true, ".xlsx": true, ".ppt": true, ".pptx": true,
+	".bin": true, ".dat": true, ".db": true, ".sqlite": true, ".sqlite3": true,
+}
+
+// IsBinaryFile reports whether a file is binary, judging by its extension or a NUL
+// byte in its first 512 bytes (the same heuristic git uses)
+func IsBinaryFile(filename string) bool {
+	if binaryExts[strings.ToLower(filepath.Ext(filename))] {
+		return true
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	buffer := make([]byte, 512)
+	n, err := file.Read(buffer)
+	if err != nil && n == 0 {
+		return false
+	}
+	return bytes.IndexByte(buffer[:n], 0) != -1
+}
+
+// splitLines splits command output into non-empty lines
+func splitLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,915 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/filesystem/dotgit"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// gitDateFormat is git's default date format in log output
+const gitDateFormat = "Mon Jan 2 15:04:05 2006 -0700"
+
+// GoGit implements Repository with go-git, so reading diffs, logs, status and blame
+// works without the git binary and without starting a process per query. Arguments
+// it does not understand are passed to the git CLI when it is installed.
+type GoGit struct {
+	dir      string
+	fallback *CLI
+
+	once sync.Once
+	repo *gogit.Repository
+	err  error
+}
+
+// NewGoGit returns a go-git Repository for the working tree at dir ("" for the
+// current directory)
+func NewGoGit(dir string) *GoGit {
+	return &GoGit{dir: dir, fallback: NewCLI(dir)}
+}
+
+// open opens the repository on first use, searching parent directories like git does.
+// Linked worktrees share the objects, refs and config of the main repository, and
+// GIT_DIR and GIT_WORK_TREE are honored for the current directory like git does.
+func (r *GoGit) open() (*gogit.Repository, error) {
+	r.once.Do(func() {
+		if gitDir := os.Getenv("GIT_DIR"); gitDir != "" && r.dir == "" {
+			r.repo, r.err = openGitDir(gitDir, os.Getenv("GIT_WORK_TREE"))
+			return
+		}
+		dir := r.dir
+		if dir == "" {
+			dir = "."
+		}
+		r.repo, r.err = gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{
+			DetectDotGit:          true,
+			EnableDotGitCommonDir: true,
+		})
+	})
+	return r.repo, r.err
+}
+
+// openGitDir opens the repository at an explicit git directory. Without a work tree
+// the current directory is the top of the working tree, unless core.bare is set.
+func openGitDir(gitDir, workTree string) (*gogit.Repository, error) {
+	gitDir, err := filepath.Abs(gitDir)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(gitDir); err != nil || !info.IsDir() {
+		return nil, gogit.ErrRepositoryNotExists
+	}
+
+	// A linked worktree's git directory names the main one in its commondir file
+	var repositoryFs billy.Filesystem = osfs.New(gitDir)
+	if content, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(content))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		repositoryFs = dotgit.NewRepositoryFilesystem(repositoryFs, osfs.New(common))
+	}
+	storage := filesystem.NewStorage(repositoryFs, cache.NewObjectLRUDefault())
+
+	if workTree == "" {
+		if config, err := storage.Config(); err == nil && config.Core.IsBare {
+			return gogit.Open(storage, nil)
+		}
+		if workTree, err = os.Getwd(); err != nil {
+			return nil, err
+		}
+	}
+	if workTree, err = filepath.Abs(workTree); err != nil {
+		return nil, err
+	}
+	return gogit.Open(storage, osfs.New(workTree))
+}
+
+// unsupported runs a command the go-git backend cannot handle with the git CLI
+func (r *GoGit) unsupported(ctx context.Context, args ...string) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", fmt.Errorf("'git %s' is not supported by the go-git backend and git is not installed", strings.Join(args, " "))
+	}
+	return r.fallback.Output(ctx, args...)
+}
+
+// Output runs diff, log, status and blame with go-git where the arguments allow it,
+// and any other command with the git CLI
+func (r *GoGit) Output(ctx context.Context, args ...string) (string, error) {
+	if len(args) == 0 {
+		return r.unsupported(ctx, args...)
+	}
+
+	switch args[0] {
+	case "diff":
+		return r.Diff(ctx, args[1:]...)
+	case "log":
+		return r.Log(ctx, args[1:]...)
+	case "status":
+		if len(args) == 2 && (args[1] == "--porcelain" || args[1] == "--short") {
+			return r.Status(ctx)
+		}
+	case "blame":
+		if len(args) > 1 && args[1] == "--porcelain" {
+			return r.Blame(ctx, args[2:]...)
+		}
+	}
+	return r.unsupported(ctx, args...)
+}
+
+// IsRepository reports whether the working directory is inside the working tree of a
+// git repository; a bare repository has none
+func (r *GoGit) IsRepository(ctx context.Context) bool {
+	repo, err := r.open()
+	if err != nil {
+		return false
+	}
+	_, err = repo.Worktree()
+	return err == nil
+}
+
+// Root returns the absolute path of the top-level working tree directory
+func (r *GoGit) Root(ctx context.Context) (string, error) {
+	repo, err := r.open()
+	if err != nil {
+		return "", err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	return worktree.Filesystem.Root(), nil
+}
+
+// CurrentBranch returns the checked-out branch, or "" on a detached HEAD
+func (r *GoGit) CurrentBranch(ctx context.Context) (string, error) {
+	repo, err := r.open()
+	if err != nil {
+		return "", err
+	}
+	// Read HEAD without resolving it, so a branch without commits yet still has a name
+	head, err := repo.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return "", err
+	}
+	if head.Type() == plumbing.SymbolicReference {
+		return head.Target().Short(), nil
+	}
+	return "", nil
+}
+
+// status returns the worktree status and the paths with a change, sorted
+func (r *GoGit) status(include func(*gogit.FileStatus) bool) (gogit.Status, []string, error) {
+	repo, err := r.open()
+	if err != nil {
+		return nil, nil, err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, nil, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var paths []string
+	for path, fileStatus := range status {
+		if include(fileStatus) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return status, paths, nil
+}
+
+func isStaged(status *gogit.FileStatus) bool {
+	return status.Staging != gogit.Unmodified && status.Staging != gogit.Untracked
+}
+
+func isUnstaged(status *gogit.FileStatus) bool {
+	return status.Worktree != gogit.Unmodified && status.Worktree != gogit.Untracked
+}
+
+func isUntracked(status *gogit.FileStatus) bool {
+	return status.Worktree == gogit.Untracked
+}
+
+// HasStagedChanges reports whether the index differs from HEAD
+func (r *GoGit) HasStagedChanges(ctx context.Context) (bool, error) {
+	_, paths, err := r.status(isStaged)
+	return len(paths) > 0, err
+}
+
+// StagedFiles returns the staged files with their name-status letter
+func (r *GoGit) StagedFiles(ctx context.Context) ([]FileStatus, error) {
+	status, paths, err := r.status(isStaged)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]FileStatus, 0, len(paths))
+	for _, path := range paths {
+		files = append(files, FileStatus{Status: string(status[path].Staging), Path: path})
+	}
+	return files, nil
+}
+
+// UntrackedFiles returns the untracked files that are not ignored
+func (r *GoGit) UntrackedFiles(ctx context.Context) ([]string, error) {
+	_, paths, err := r.status(isUntracked)
+	return paths, err
+}
+
+// Status returns the working tree status in git status --porcelain format
+func (r *GoGit) Status(ctx context.Context) (string, error) {
+	status, paths, err := r.status(func(fileStatus *gogit.FileStatus) bool {
+		return isStaged(fileStatus) || isUnstaged(fileStatus) || isUntracked(fileStatus)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Untracked files come last, as in git status
+	sort.SliceStable(paths, func(i, j int) bool {
+		return !isUntracked(status[paths[i]]) && isUntracked(status[paths[j]])
+	})
+
+	var b strings.Builder
+	for _, path := range paths {
+		fileStatus := status[path]
+		fmt.Fprintf(&b, "%c%c %s\n", fileStatus.Staging, fileStatus.Worktree, path)
+	}
+	return b.String(), nil
+}
+
+// RecentCommits returns the last n non-merge commits in --oneline format
+func (r *GoGit) RecentCommits(ctx context.Context, n int) (string, error) {
+	output, err := r.Log(ctx, fmt.Sprintf("-%d", n), "--oneline", "--no-merges")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// StagedDiff returns the unified diff of the staged changes
+func (r *GoGit) StagedDiff(ctx context.Context) (string, error) {
+	repo, err := r.open()
+	if err != nil {
+		return "", err
+	}
+	_, paths, err := r.status(isStaged)
+	if err != nil {
+		return "", err
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return "", err
+	}
+	headTree, err := r.headTree(repo)
+	if err != nil {
+		return "", err
+	}
+
+	var patches []fdiff.FilePatch
+	for _, path := range paths {
+		from, err := treeFile(headTree, path)
+		if err != nil {
+			return "", err
+		}
+		to, err := indexFile(repo, idx, path)
+		if err != nil {
+			return "", err
+		}
+		patches = append(patches, newFilePatch(from, to))
+	}
+	return encodePatch(patches)
+}
+
+// unstagedDiff returns the unified diff of the worktree against the index
+func (r *GoGit) unstagedDiff(ctx context.Context) (string, error) {
+	repo, err := r.open()
+	if err != nil {
+		return "", err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	_, paths, err := r.status(isUnstaged)
+	if err != nil {
+		return "", err
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return "", err
+	}
+
+	var patches []fdiff.FilePatch
+	for _, path := range paths {
+		from, err := indexFile(repo, idx, path)
+		if err != nil {
+			return "", err
+		}
+		to, err := worktreeFile(worktree, path)
+		if err != nil {
+			return "", err
+		}
+		patches = append(patches, newFilePatch(from, to))
+	}
+	return encodePatch(patches)
+}
+
+// Diff supports the unstaged diff (no arguments), --cached/--staged, and diffs
+// between two commits ("A B", "A..B" or "A...B"); anything else uses the git CLI
+func (r *GoGit) Diff(ctx context.Context, args ...string) (string, error) {
+	cached := false
+	var revisions []string
+	for _, arg := range args {
+		switch {
+		case arg == "--cached" || arg == "--staged":
+			cached = true
+		case arg == "--no-color" || arg == "--no-ext-diff":
+			// go-git output is never colored and never uses external tools
+		case strings.HasPrefix(arg, "-"):
+			return r.unsupported(ctx, append([]string{"diff"}, args...)...)
+		default:
+			revisions = append(revisions, arg)
+		}
+	}
+
+	switch {
+	case len(revisions) == 0 && cached:
+		return r.StagedDiff(ctx)
+	case len(revisions) == 0:
+		return r.unstagedDiff(ctx)
+	case len(revisions) == 1 && !cached:
+		if from, to, ok := strings.Cut(revisions[0], "..."); ok {
+			if output, err := r.commitDiff(ctx, from, to, true); err == nil {
+				return output, nil
+			}
+		} else if from, to, ok := strings.Cut(revisions[0], ".."); ok {
+			if output, err := r.commitDiff(ctx, from, to, false); err == nil {
+				return output, nil
+			}
+		}
+	case len(revisions) == 2 && !cached:
+		if output, err := r.commitDiff(ctx, revisions[0], revisions[1], false); err == nil {
+			return output, nil
+		}
+	}
+	// A single revision compares with the worktree, and arguments may be paths
+	return r.unsupported(ctx, append([]string{"diff"}, args...)...)
+}
+
+// commitDiff diffs the trees of two commits, or of their merge base and to
+func (r *GoGit) commitDiff(ctx context.Context, from, to string, fromMergeBase bool) (string, error) {
+	repo, err := r.open()
+	if err != nil {
+		return "", err
+	}
+	fromCommit, err := resolveCommit(repo, from)
+	if err != nil {
+		return "", err
+	}
+	toCommit, err := resolveCommit(repo, to)
+	if err != nil {
+		return "", err
+	}
+	if fromMergeBase {
+		bases, err := fromCommit.MergeBase(toCommit)
+		if err != nil || len(bases) == 0 {
+			return "", fmt.Errorf("no merge base between %s and %s", from, to)
+		}
+		fromCommit = bases[0]
+	}
+
+	fromTree, err := fromCommit.Tree()
+	if err != nil {
+		return "", err
+	}
+	toTree, err := toCommit.Tree()
+	if err != nil {
+		return "", err
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, fromTree, toTree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return "", err
+	}
+	patch, err := changes.PatchContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return patch.String(), nil
+}
+
+// Log supports -N/-n/--max-count, --oneline, --no-merges and a single revision or
+// "A..B" range; anything else uses the git CLI
+func (r *GoGit) Log(ctx context.Context, args ...string) (string, error) {
+	fallback := func() (string, error) {
+		return r.unsupported(ctx, append([]string{"log"}, args...)...)
+	}
+
+	maxCount := -1
+	oneline, noMerges := false, false
+	var revisions []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value := ""
+		switch {
+		case arg == "--oneline":
+			oneline = true
+			continue
+		case arg == "--no-merges":
+			noMerges = true
+			continue
+		case arg == "--no-color":
+			continue
+		case arg == "-n" || arg == "--max-count":
+			if i+1 >= len(args) {
+				return fallback()
+			}
+			i++
+			value = args[i]
+		case strings.HasPrefix(arg, "--max-count="):
+			value = strings.TrimPrefix(arg, "--max-count=")
+		case strings.HasPrefix(arg, "-n"):
+			value = strings.TrimPrefix(arg, "-n")
+		case strings.HasPrefix(arg, "-") && len(arg) > 1 && arg[1] >= '0' && arg[1] <= '9':
+			value = arg[1:]
+		case strings.HasPrefix(arg, "-"):
+			return fallback()
+		default:
+			revisions = append(revisions, arg)
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fallback()
+		}
+		maxCount = n
+	}
+	if len(revisions) > 1 {
+		return fallback()
+	}
+
+	repo, err := r.open()
+	if err != nil {
+		return "", err
+	}
+
+	from, exclude := "HEAD", ""
+	if len(revisions) == 1 {
+		from = revisions[0]
+		if before, after, ok := strings.Cut(revisions[0], ".."); ok {
+			if strings.HasPrefix(after, ".") {
+				return fallback() // symmetric difference
+			}
+			exclude, from = before, after
+			if exclude == "" {
+				exclude = "HEAD"
+			}
+			if from == "" {
+				from = "HEAD"
+			}
+		}
+	}
+
+	start, err := resolveCommit(repo, from)
+	if err != nil {
+		if len(revisions) == 0 {
+			// A branch without commits yet has an empty log, like git's error but quieter
+			return "", fmt.Errorf("your current branch does not have any commits yet")
+		}
+		return fallback()
+	}
+
+	// Commits reachable from the excluded revision are never visited
+	seen := make(map[plumbing.Hash]bool)
+	if exclude != "" {
+		excluded, err := resolveCommit(repo, exclude)
+		if err != nil {
+			return fallback()
+		}
+		err = object.NewCommitPreorderIter(excluded, nil, nil).ForEach(func(c *object.Commit) error {
+			seen[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var entries []string
+	err = object.NewCommitIterCTime(start, seen, nil).ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if maxCount >= 0 && len(entries) >= maxCount {
+			return storer.ErrStop
+		}
+		if noMerges && c.NumParents() > 1 {
+			return nil
+		}
+		entries = append(entries, formatLogEntry(c, oneline))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if oneline {
+		return strings.Join(entries, ""), nil
+	}
+	return strings.Join(entries, "\n"), nil
+}
+
+// formatLogEntry formats a commit like git log's default (medium) or --oneline format
+func formatLogEntry(c *object.Commit, oneline bool) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+	if oneline {
+		return fmt.Sprintf("%s %s\n", c.Hash.String()[:7], subject)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "commit %s\n", c.Hash)
+	if c.NumParents() > 1 {
+		parents := make([]string, 0, c.NumParents())
+		for _, parent := range c.ParentHashes {
+			parents = append(parents, parent.String()[:7])
+		}
+		fmt.Fprintf(&b, "Merge: %s\n", strings.Join(parents, " "))
+	}
+	fmt.Fprintf(&b, "Author: %s <%s>\n", c.Author.Name, c.Author.Email)
+	fmt.Fprintf(&b, "Date:   %s\n\n", c.Author.When.Format(gitDateFormat))
+	for _, line := range strings.Split(strings.TrimRight(c.Message, "\n"), "\n") {
+		fmt.Fprintf(&b, "    %s\n", line)
+	}
+	return b.String()
+}
+
+// Blame supports "[-L start,end] [rev] [--] file" and blames the committed lines; any
+// other option uses the git CLI
+func (r *GoGit) Blame(ctx context.Context, args ...string) (string, error) {
+	fallback := func() (string, error) {
+		return r.unsupported(ctx, append([]string{"blame", "--porcelain"}, args...)...)
+	}
+
+	lineRange := ""
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			positional = append(positional, args[i+1:]...)
+			i = len(args)
+		case arg == "-L":
+			if i+1 >= len(args) {
+				return fallback()
+			}
+			i++
+			lineRange = args[i]
+		case strings.HasPrefix(arg, "-L"):
+			lineRange = strings.TrimPrefix(arg, "-L")
+		case arg == "--porcelain" || arg == "--line-porcelain":
+			// Always porcelain
+		case strings.HasPrefix(arg, "-"):
+			return fallback()
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) == 0 || len(positional) > 2 {
+		return fallback()
+	}
+
+	repo, err := r.open()
+	if err != nil {
+		return "", err
+	}
+
+	revision, file := "HEAD", positional[len(positional)-1]
+	if len(positional) == 2 {
+		revision = positional[0]
+	}
+	commit, err := resolveCommit(repo, revision)
+	if err != nil {
+		return fallback()
+	}
+	path, err := r.repoPath(file)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := gogit.Blame(commit, path)
+	if err != nil {
+		return "", fmt.Errorf("error blaming %s: %v", file, err)
+	}
+
+	start, end, ok := parseLineRange(lineRange, len(result.Lines))
+	if !ok {
+		return fallback()
+	}
+
+	var b strings.Builder
+	described := make(map[plumbing.Hash]bool)
+	for number := start; number <= end; number++ {
+		line := result.Lines[number-1]
+		fmt.Fprintf(&b, "%s %d %d 1\n", line.Hash, number, number)
+		if !described[line.Hash] {
+			described[line.Hash] = true
+			summary := ""
+			if lineCommit, err := repo.CommitObject(line.Hash); err == nil {
+				summary, _, _ = strings.Cut(strings.TrimSpace(lineCommit.Message), "\n")
+			}
+			fmt.Fprintf(&b, "author %s\nauthor-mail <%s>\nauthor-time %d\nauthor-tz %s\nsummary %s\n",
+				line.AuthorName, line.Author, line.Date.Unix(), line.Date.Format("-0700"), summary)
+		}
+		fmt.Fprintf(&b, "filename %s\n\t%s\n", path, line.Text)
+	}
+	return b.String(), nil
+}
+
+// repoPath converts a path relative to the working directory to one relative to the
+// top of the working tree, as go-git expects
+func (r *GoGit) repoPath(file string) (string, error) {
+	root, err := r.Root(context.Background())
+	if err != nil {
+		return "", err
+	}
+	absolute, err := filepath.Abs(filepath.Join(r.dir, file))
+	if err != nil {
+		return "", err
+	}
+	if resolvedRoot, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolvedRoot
+	}
+	if resolved, err := filepath.EvalSymlinks(absolute); err == nil {
+		absolute = resolved
+	}
+	relative, err := filepath.Rel(root, absolute)
+	if err != nil || strings.HasPrefix(relative, "..") {
+		return "", fmt.Errorf("%s is outside the repository", file)
+	}
+	return filepath.ToSlash(relative), nil
+}
+
+// parseLineRange parses a numeric -L range ("10,20", "10,+5" or "10") for a file with
+// total lines; the empty range selects the whole file
+func parseLineRange(lineRange string, total int) (int, int, bool) {
+	if lineRange == "" {
+		return 1, total, total > 0
+	}
+
+	startText, endText, hasEnd := strings.Cut(lineRange, ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil || start < 1 || start > total {
+		return 0, 0, false
+	}
+	end := total
+	if hasEnd && endText != "" {
+		if strings.HasPrefix(endText, "+") {
+			count, err := strconv.Atoi(endText[1:])
+			if err != nil || count < 1 {
+				return 0, 0, false
+			}
+			end = start + count - 1
+		} else if end, err = strconv.Atoi(endText); err != nil || end < start {
+			return 0, 0, false
+		}
+	}
+	if end > total {
+		end = total
+	}
+	return start, end, true
+}
+
+// resolveCommit resolves a revision such as HEAD~2, a branch, tag or hash to a commit
+func resolveCommit(repo *gogit.Repository, revision string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, err
+	}
+	return repo.CommitObject(*hash)
+}
+
+// headTree returns the tree of HEAD, or nil before the first commit
+func (r *GoGit) headTree(repo *gogit.Repository) (*object.Tree, error) {
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	return commit.Tree()
+}
+
+// patchFile is one side of a file patch
+type patchFile struct {
+	path    string
+	hash    plumbing.Hash
+	mode    filemode.FileMode
+	content string
+	binary  bool
+}
+
+func (f *patchFile) Hash() plumbing.Hash     { return f.hash }
+func (f *patchFile) Mode() filemode.FileMode { return f.mode }
+func (f *patchFile) Path() string            { return f.path }
+
+func newPatchFile(path string, hash plumbing.Hash, mode filemode.FileMode, content []byte) *patchFile {
+	// Like git, a NUL byte in the first 8000 bytes marks the content as binary
+	sniff := content
+	if len(sniff) > 8000 {
+		sniff = sniff[:8000]
+	}
+	return &patchFile{
+		path:    path,
+		hash:    hash,
+		mode:    mode,
+		content: string(content),
+		binary:  bytes.IndexByte(sniff, 0) != -1,
+	}
+}
+
+// treeFile reads a file from a commit tree, returning nil if it doesn't exist there
+func treeFile(tree *object.Tree, path string) (*patchFile, error) {
+	if tree == nil {
+		return nil, nil
+	}
+	file, err := tree.File(path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	content, err := readBlob(&file.Blob)
+	if err != nil {
+		return nil, err
+	}
+	return newPatchFile(path, file.Hash, file.Mode, content), nil
+}
+
+// indexFile reads a file's staged content, returning nil if it isn't in the index
+func indexFile(repo *gogit.Repository, idx *index.Index, path string) (*patchFile, error) {
+	entry, err := idx.Entry(path)
+	if errors.Is(err, index.ErrEntryNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	blob, err := repo.BlobObject(entry.Hash)
+	if err != nil {
+		return nil, err
+	}
+	content, err := readBlob(blob)
+	if err != nil {
+		return nil, err
+	}
+	return newPatchFile(path, entry.Hash, entry.Mode, content), nil
+}
+
+// worktreeFile reads a file from the working tree, returning nil if it was deleted
+func worktreeFile(worktree *gogit.Worktree, path string) (*patchFile, error) {
+	info, err := worktree.Filesystem.Lstat(path)
+	if err != nil {
+		return nil, nil
+	}
+	mode, err := filemode.NewFromOSFileMode(info.Mode())
+	if err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	if mode == filemode.Symlink {
+		target, err := worktree.Filesystem.Readlink(path)
+		if err != nil {
+			return nil, err
+		}
+		content = []byte(target)
+	} else {
+		file, err := worktree.Filesystem.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		content, err = io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newPatchFile(path, plumbing.ComputeHash(plumbing.BlobObject, content), mode, content), nil
+}
+
+func readBlob(blob *object.Blob) ([]byte, error) {
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// filePatch implements go-git's diff.FilePatch for index and worktree content
+type filePatch struct {
+	from, to *patchFile
+	chunks   []fdiff.Chunk
+}
+
+func newFilePatch(from, to *patchFile) *filePatch {
+	patch := &filePatch{from: from, to: to}
+	if patch.IsBinary() {
+		return patch
+	}
+
+	var fromContent, toContent string
+	if from != nil {
+		fromContent = from.content
+	}
+	if to != nil {
+		toContent = to.content
+	}
+	for _, d := range diff.Do(fromContent, toContent) {
+		operation := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			operation = fdiff.Delete
+		case diffmatchpatch.DiffInsert:
+			operation = fdiff.Add
+		}
+		patch.chunks = append(patch.chunks, &textChunk{content: d.Text, operation: operation})
+	}
+	return patch
+}
+
+func (p *filePatch) IsBinary() bool {
+	return (p.from != nil && p.from.binary) || (p.to != nil && p.to.binary)
+}
+
+func (p *filePatch) Files() (fdiff.File, fdiff.File) {
+	// A missing side must be a nil interface, not a nil *patchFile
+	var from, to fdiff.File
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+func (p *filePatch) Chunks() []fdiff.Chunk { return p.chunks }
+
+// textChunk implements go-git's diff.Chunk
+type textChunk struct {
+	content   string
+	operation fdiff.Operation
+}
+
+func (c *textChunk) Content() string       { return c.content }
+func (c *textChunk) Type() fdiff.Operation { return c.operation }
+
+// filePatches implements go-git's diff.Patch
+type filePatches []fdiff.FilePatch
+
+func (p filePatches) FilePatches() []fdiff.FilePatch { return p }
+func (p filePatches) Message() string                { return "" }
+
+// encodePatch renders file patches as a unified diff
+func encodePatch(patches []fdiff.FilePatch) (string, error) {
+	var b bytes.Buffer
+	if err := fdiff.NewUnifiedEncoder(&b, fdiff.DefaultContextLines).Encode(filePatches(patches)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,269 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/httpclient"
+)
+
+// Client is a minimal GitHub REST API client
+type Client struct {
+	token   string
+	baseURL string
+}
+
+// NewPullRequest describes a pull request to be created
+type NewPullRequest struct {
+	Title string `json:"title"`
+	Head  string `json:"head"`
+	Base  string `json:"base"`
+	Body  string `json:"body"`
+	Draft bool   `json:"draft"`
+}
+
+// PullRequest represents a pull request returned by the API
+type PullRequest struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	State   string `json:"state"`
+	Head    Branch `json:"head"`
+	Base    Branch `json:"base"`
+}
+
+// Branch is the head or base branch of a pull request
+type Branch struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// NewReview describes a pull request review to be submitted
+type NewReview struct {
+	// CommitID is the commit the comments' lines refer to, the pull request's head
+	CommitID string `json:"commit_id,omitempty"`
+	Body     string `json:"body"`
+	// Event is APPROVE, REQUEST_CHANGES or COMMENT
+	Event    string          `json:"event"`
+	Comments []ReviewComment `json:"comments,omitempty"`
+}
+
+// ReviewComment is an inline comment of a review, on a line of the new version of a file
+type ReviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side string `json:"side"`
+	Body string `json:"body"`
+}
+
+// Review represents a pull request review returned by the API
+type Review struct {
+	ID      int64  `json:"id"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+}
+
+// NewRelease describes a release to be created
+type NewRelease struct {
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// Release represents a release returned by the API
+type Release struct {
+	ID      int64  `json:"id"`
+	HTMLURL string `json:"html_url"`
+	TagName string `json:"tag_name"`
+}
+
+// Issue represents a GitHub issue
+type Issue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Repository represents a GitHub repository
+type Repository struct {
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
+	Fork          bool   `json:"fork"`
+	CloneURL      string `json:"clone_url"`
+	// Parent is the repository a fork was made from
+	Parent *Repository `json:"parent"`
+}
+
+// NewClient creates a new GitHub API client. An empty baseURL uses api.github.com.
+func NewClient(token, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = "https://api.github.com"
+	}
+	return &Client{
+		token:   token,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// CreatePullRequest opens a new pull request in owner/repo
+func (c *Client) CreatePullRequest(ctx context.Context, owner, repo string, pr NewPullRequest) (*PullRequest, error) {
+	var created PullRequest
+	path := fmt.Sprintf("/repos/%s/%s/pulls", owner, repo)
+	if err := c.do(ctx, "POST", path, pr, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// CreateRelease publishes a release for an existing tag in owner/repo
+func (c *Client) CreateRelease(ctx context.Context, owner, repo string, release NewRelease) (*Release, error) {
+	var created Release
+	path := fmt.Sprintf("/repos/%s/%s/releases", owner, repo)
+	if err := c.do(ctx, "POST", path, release, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetPullRequest fetches a single pull request from owner/repo
+func (c *Client) GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error) {
+	var pr PullRequest
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d", owner, repo, number)
+	if err := c.do(ctx, "GET", path, nil, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// GetPullRequestDiff fetches the unified diff of a pull request from owner/repo
+func (c *Client) GetPullRequestDiff(ctx context.Context, owner, repo string, number int) (string, error) {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d", owner, repo, number)
+	diff, err := c.request(ctx, "GET", path, "application/vnd.github.diff", nil)
+	if err != nil {
+		return "", err
+	}
+	return string(diff), nil
+}
+
+// ListPullRequests lists the open pull requests of owner/repo whose head is head, in
+// the "<owner>:<branch>" form
+func (c *Client) ListPullRequests(ctx context.Context, owner, repo, head string) ([]PullRequest, error) {
+	var prs []PullRequest
+	path := fmt.Sprintf("/repos/%s/%s/pulls?state=open&head=%s", owner, repo, url.QueryEscape(head))
+	if err := c.do(ctx, "GET", path, nil, &prs); err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+// CreateReview submits a review with its inline comments on a pull request in owner/repo
+func (c *Client) CreateReview(ctx context.Context, owner, repo string, number int, review NewReview) (*Review, error) {
+	var created Review
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews", owner, repo, number)
+	if err := c.do(ctx, "POST", path, review, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetIssue fetches a single issue from owner/repo
+func (c *Client) GetIssue(ctx context.Context, owner, repo string, number int) (*Issue, error) {
+	var issue Issue
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d", owner, repo, number)
+	if err := c.do(ctx, "GET", path, nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// GetRepository fetches owner/repo, with its parent when it is a fork
+func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*Repository, error) {
+	var repository Repository
+	path := fmt.Sprintf("/repos/%s/%s", owner, repo)
+	if err := c.do(ctx, "GET", path, nil, &repository); err != nil {
+		return nil, err
+	}
+	return &repository, nil
+}
+
+// do performs an API request, encoding body as JSON and decoding the response into out
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	respBody, err := c.request(ctx, method, path, "application/vnd.github+json", body)
+	if err != nil {
+		return err
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("error unmarshaling response: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// request performs an API request asking for the accept media type, encoding body as
+// JSON, and returns the response body
+func (c *Client) request(ctx context.Context, method, path, accept string, body interface{}) ([]byte, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling request: %v", err)
+		}
+		reader = bytes.NewReader(jsonData)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := httpclient.Shared().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("GitHub API request failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return respBody, nil
+}
+
+// remotePattern matches both SSH (git@github.com:owner/repo.git) and HTTPS remote URLs
+var remotePattern = regexp.MustCompile(`^(?:https?://|ssh://)?(?:[^@/]+@)?([^/:]+)[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// ParseRemoteURL extracts the host, owner, and repository name from a git remote URL
+func ParseRemoteURL(url string) (host, owner, repo string, err error) {
+	m := remotePattern.FindStringSubmatch(strings.TrimSpace(url))
+	if m == nil {
+		return "", "", "", fmt.Errorf("unrecognized remote URL: %s", url)
+	}
+	return m[1], m[2], m[3], nil
+}
@@ -0,0 +1,102 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/httpclient"
+)
+
+// Client is a minimal GitLab REST API (v4) client, for gitlab.com or self-hosted instances
+type Client struct {
+	token   string
+	baseURL string
+}
+
+// NewMergeRequest describes a merge request to be created
+type NewMergeRequest struct {
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+}
+
+// MergeRequest represents a merge request returned by the API
+type MergeRequest struct {
+	IID    int    `json:"iid"`
+	WebURL string `json:"web_url"`
+	Title  string `json:"title"`
+}
+
+// NewClient creates a GitLab client. baseURL is the instance root, e.g. https://gitlab.example.com
+func NewClient(token, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = "https://gitlab.com"
+	}
+	return &Client{
+		token:   token,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// CreateMergeRequest opens a merge request in the project at projectPath (e.g. group/subgroup/repo).
+// Draft merge requests are marked with GitLab's "Draft:" title prefix.
+func (c *Client) CreateMergeRequest(ctx context.Context, projectPath string, mr NewMergeRequest, draft bool) (*MergeRequest, error) {
+	if draft && !strings.HasPrefix(mr.Title, "Draft:") {
+		mr.Title = "Draft: " + mr.Title
+	}
+
+	jsonData, err := json.Marshal(mr)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %v", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests", c.baseURL, url.PathEscape(projectPath))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+
+	resp, err := httpclient.Shared().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("GitLab API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var created MergeRequest
+	if err := json.Unmarshal(body, &created); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %v", err)
+	}
+	return &created, nil
+}
+
+// remotePattern matches SSH and HTTPS remote URLs, allowing nested groups in the path
+var remotePattern = regexp.MustCompile(`^(?:https?://|ssh://)?(?:[^@/]+@)?([^/:]+)(?::\d+)?[:/](.+?)(?:\.git)?/?$`)
+
+// ParseRemoteURL extracts the host and full project path (group/subgroup/repo) from a git remote URL
+func ParseRemoteURL(remoteURL string) (host, projectPath string, err error) {
+	m := remotePattern.FindStringSubmatch(strings.TrimSpace(remoteURL))
+	if m == nil || !strings.Contains(m[2], "/") {
+		return "", "", fmt.Errorf("unrecognized remote URL: %s", remoteURL)
+	}
+	return m[1], m[2], nil
+}
@@ -0,0 +1,224 @@
+package gitmoji
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Gitmoji is an emoji from the canonical gitmoji list (https://gitmoji.dev)
+type Gitmoji struct {
+	Emoji       string
+	Code        string
+	Description string
+}
+
+// List is the canonical gitmoji list
+var List = []Gitmoji{
+	{"🎨", ":art:", "Improve structure / format of the code"},
+	{"⚡️", ":zap:", "Improve performance"},
+	{"🔥", ":fire:", "Remove code or files"},
+	{"🐛", ":bug:", "Fix a bug"},
+	{"🚑️", ":ambulance:", "Critical hotfix"},
+	{"✨", ":sparkles:", "Introduce new features"},
+	{"📝", ":memo:", "Add or update documentation"},
+	{"🚀", ":rocket:", "Deploy stuff"},
+	{"💄", ":lipstick:", "Add or update the UI and style files"},
+	{"🎉", ":tada:", "Begin a project"},
+	{"✅", ":white_check_mark:", "Add, update, or pass tests"},
+	{"🔒️", ":lock:", "Fix security or privacy issues"},
+	{"🔐", ":closed_lock_with_key:", "Add or update secrets"},
+	{"🔖", ":bookmark:", "Release / Version tags"},
+	{"🚨", ":rotating_light:", "Fix compiler / linter warnings"},
+	{"🚧", ":construction:", "Work in progress"},
+	{"💚", ":green_heart:", "Fix CI Build"},
+	{"⬇️", ":arrow_down:", "Downgrade dependencies"},
+	{"⬆️", ":arrow_up:", "Upgrade dependencies"},
+	{"📌", ":pushpin:", "Pin dependencies to specific versions"},
+	{"👷", ":construction_worker:", "Add or update CI build system"},
+	{"📈", ":chart_with_upwards_trend:", "Add or update analytics or track code"},
+	{"♻️", ":recycle:", "Refactor code"},
+	{"➕", ":heavy_plus_sign:", "Add a dependency"},
+	{"➖", ":heavy_minus_sign:", "Remove a dependency"},
+	{"🔧", ":wrench:", "Add or update configuration files"},
+	{"🔨", ":hammer:", "Add or update development scripts"},
+	{"🌐", ":globe_with_meridians:", "Internationalization and localization"},
+	{"✏️", ":pencil2:", "Fix typos"},
+	{"💩", ":poop:", "Write bad code that needs to be improved"},
+	{"⏪️", ":rewind:", "Revert changes"},
+	{"🔀", ":twisted_rightwards_arrows:", "Merge branches"},
+	{"📦️", ":package:", "Add or update compiled files or packages"},
+	{"👽️", ":alien:", "Update code due to external API changes"},
+	{"🚚", ":truck:", "Move or rename resources (e.g.: files, paths, routes)"},
+	{"📄", ":page_facing_up:", "Add or update license"},
+	{"💥", ":boom:", "Introduce breaking changes"},
+	{"🍱", ":bento:", "Add or update assets"},
+	{"♿️", ":wheelchair:", "Improve accessibility"},
+	{"💡", ":bulb:", "Add or update comments in source code"},
+	{"🍻", ":beers:", "Write code drunkenly"},
+	{"💬", ":speech_balloon:", "Add or update text and literals"},
+	{"🗃️", ":card_file_box:", "Perform database related changes"},
+	{"🔊", ":loud_sound:", "Add or update logs"},
+	{"🔇", ":mute:", "Remove logs"},
+	{"👥", ":busts_in_silhouette:", "Add or update contributor(s)"},
+	{"🚸", ":children_crossing:", "Improve user experience / usability"},
+	{"🏗️", ":building_construction:", "Make architectural changes"},
+	{"📱", ":iphone:", "Work on responsive design"},
+	{"🤡", ":clown_face:", "Mock things"},
+	{"🥚", ":egg:", "Add or update an easter egg"},
+	{"🙈", ":see_no_evil:", "Add or update a .gitignore file"},
+	{"📸", ":camera_flash:", "Add or update snapshots"},
+	{"⚗️", ":alembic:", "Perform experiments"},
+	{"🔍️", ":mag:", "Improve SEO"},
+	{"🏷️", ":label:", "Add or update types"},
+	{"🌱", ":seedling:", "Add or update seed files"},
+	{"🚩", ":triangular_flag_on_post:", "Add, update, or remove feature flags"},
+	{"🥅", ":goal_net:", "Catch errors"},
+	{"💫", ":dizzy:", "Add or update animations and transitions"},
+	{"🗑️", ":wastebasket:", "Deprecate code that needs to be cleaned up"},
+	{"🛂", ":passport_control:", "Work on code related to authorization, roles and permissions"},
+	{"🩹", ":adhesive_bandage:", "Simple fix for a non-critical issue"},
+	{"🧐", ":monocle_face:", "Data exploration/inspection"},
+	{"⚰️", ":coffin:", "Remove dead code"},
+	{"🧪", ":test_tube:", "Add a failing test"},
+	{"👔", ":necktie:", "Add or update business logic"},
+	{"🩺", ":stethoscope:", "Add or update healthcheck"},
+	{"🧱", ":bricks:", "Infrastructure related changes"},
+	{"🧑‍💻", ":technologist:", "Improve developer experience"},
+	{"💸", ":money_with_wings:", "Add sponsorships or money related infrastructure"},
+	{"🧵", ":thread:", "Add or update code related to multithreading or concurrency"},
+	{"🦺", ":safety_vest:", "Add or update code related to validation"},
+	{"✈️", ":airplane:", "Improve offline support"},
+}
+
+// TypeCodes maps conventional commit types to the gitmoji code used for them
+var TypeCodes = map[string]string{
+	"feat":     ":sparkles:",
+	"fix":      ":bug:",
+	"docs":     ":memo:",
+	"style":    ":art:",
+	"refactor": ":recycle:",
+	"perf":     ":zap:",
+	"test":     ":white_check_mark:",
+	"build":    ":package:",
+	"ci":       ":construction_worker:",
+	"chore":    ":wrench:",
+	"revert":   ":rewind:",
+	"security": ":lock:",
+	"deps":     ":arrow_up:",
+	"i18n":     ":globe_with_meridians:",
+	"release":  ":bookmark:",
+	"hotfix":   ":ambulance:",
+	"wip":      ":construction:",
+}
+
+// typeOrder lists TypeCodes keys in the order they are presented in prompts
+var typeOrder = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert", "security", "deps", "i18n", "release", "hotfix", "wip"}
+
+// headerTypePattern matches the "type(scope)!:" prefix of a conventional subject
+var headerTypePattern = regexp.MustCompile(`^([a-zA-Z]+)(\([^()]*\))?!?:`)
+
+// Find looks up a gitmoji by emoji (with or without the variation selector) or :code:
+func Find(s string) (Gitmoji, bool) {
+	s = strings.TrimSpace(s)
+	for _, g := range List {
+		if s == g.Code || normalize(s) == normalize(g.Emoji) {
+			return g, true
+		}
+	}
+	return Gitmoji{}, false
+}
+
+// ForType returns the gitmoji mapped to a conventional commit type
+func ForType(commitType string) (Gitmoji, bool) {
+	code, ok := TypeCodes[strings.ToLower(commitType)]
+	if !ok {
+		return Gitmoji{}, false
+	}
+	return Find(code)
+}
+
+// TypeMapping describes the type → gitmoji mapping, one "type: emoji (description)" per line
+func TypeMapping() string {
+	var b strings.Builder
+	for _, commitType := range typeOrder {
+		g, _ := ForType(commitType)
+		fmt.Fprintf(&b, "- %s: %s (%s)\n", commitType, g.Emoji, g.Description)
+	}
+	return b.String()
+}
+
+// Split separates a leading emoji or :shortcode: from the rest of a subject line.
+// lead is "" when the subject does not start with one.
+func Split(subject string) (lead, rest string) {
+	subject = strings.TrimSpace(subject)
+	fields := strings.Fields(subject)
+	if len(fields) == 0 {
+		return "", subject
+	}
+
+	first := fields[0]
+	if !isShortcode(first) && !isEmoji(first) {
+		return "", subject
+	}
+	return first, strings.TrimSpace(strings.TrimPrefix(subject, first))
+}
+
+// Apply makes sure the subject line of message starts with a canonical gitmoji.
+// A valid :shortcode: is replaced by its emoji; a missing or unknown emoji is
+// replaced by the one mapped from the conventional commit type. It fails when
+// no valid gitmoji can be determined.
+func Apply(message string) (string, error) {
+	message = strings.TrimSpace(message)
+	subject, body, _ := strings.Cut(message, "\n")
+
+	lead, rest := Split(subject)
+	if lead != "" {
+		if g, ok := Find(lead); ok {
+			return joinMessage(g.Emoji+" "+rest, body), nil
+		}
+	}
+
+	m := headerTypePattern.FindStringSubmatch(rest)
+	if m == nil {
+		if lead != "" {
+			return message, fmt.Errorf("'%s' is not a gitmoji from https://gitmoji.dev", lead)
+		}
+		return message, fmt.Errorf("subject does not start with a gitmoji")
+	}
+	g, ok := ForType(m[1])
+	if !ok {
+		return message, fmt.Errorf("no gitmoji is mapped to commit type '%s'", m[1])
+	}
+	return joinMessage(g.Emoji+" "+rest, body), nil
+}
+
+func joinMessage(subject, body string) string {
+	if body == "" {
+		return subject
+	}
+	return subject + "\n" + body
+}
+
+// normalize drops variation selectors so "⚡" and "⚡️" compare equal
+func normalize(s string) string {
+	return strings.ReplaceAll(s, "\ufe0f", "")
+}
+
+func isShortcode(s string) bool {
+	return len(s) > 2 && strings.HasPrefix(s, ":") && strings.HasSuffix(s, ":") && !strings.ContainsAny(s[1:len(s)-1], ": ")
+}
+
+// isEmoji reports whether s consists only of symbol characters (and emoji joiners)
+func isEmoji(s string) bool {
+	for _, r := range s {
+		if r == '\u200d' || r == '\ufe0f' {
+			continue
+		}
+		if r <= unicode.MaxASCII || !unicode.IsSymbol(r) {
+			return false
+		}
+	}
+	return true
+}
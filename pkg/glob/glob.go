@@ -0,0 +1,73 @@
+// Package glob matches slash-separated paths against gitignore-style patterns, as
+// used by sgit's path policies in config.
+package glob
+
+import (
+	"path"
+	"strings"
+)
+
+// Match reports whether a slash-separated path relative to the repository root
+// matches pattern:
+//   - a pattern without a slash matches the file name at any depth ("*.env")
+//   - other patterns match from the root ("docs/*.md"), and "**" matches any number
+//     of directories ("dist/**", "**/testdata/*")
+//   - a pattern ending in "/" matches everything under that directory ("build/")
+//
+// Malformed patterns never match.
+func Match(pattern, name string) bool {
+	pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "./")
+	name = strings.TrimPrefix(name, "./")
+	if pattern == "" {
+		return false
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// MatchAny returns the first pattern that matches name, if any
+func MatchAny(patterns []string, name string) (string, bool) {
+	for _, pattern := range patterns {
+		if Match(pattern, name) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+func matchSegments(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			// Collapse repeated ** and try every number of skipped directories
+			for len(patterns) > 0 && patterns[0] == "**" {
+				patterns = patterns[1:]
+			}
+			if len(patterns) == 0 {
+				return len(names) > 0
+			}
+			for i := range names {
+				if matchSegments(patterns, names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(names) == 0 {
+			return false
+		}
+		if matched, err := path.Match(patterns[0], names[0]); err != nil || !matched {
+			return false
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+	return len(names) == 0
+}
@@ -0,0 +1,253 @@
+// Package history keeps a local log of generated commit messages, so a message is not
+// lost when the commit is aborted and can be reused later.
+package history
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultMaxEntries is how many messages are kept when no limit is configured
+const DefaultMaxEntries = 500
+
+// Outcomes of a generated message
+const (
+	StatusGenerated = "generated" // shown, but the commit didn't finish (e.g. interrupted)
+	StatusAccepted  = "accepted"  // committed, possibly after editing
+	StatusRejected  = "rejected"  // declined by the user
+	StatusAborted   = "aborted"   // the editor was closed empty or failed
+	StatusFailed    = "failed"    // git commit failed, e.g. a hook rejected it
+)
+
+// Entry is one generated commit message and what became of it
+type Entry struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Repo    string    `json:"repo,omitempty"`
+	Branch  string    `json:"branch,omitempty"`
+	Status  string    `json:"status,omitempty"`
+	Message string    `json:"message,omitempty"`
+	// Final is the message as committed, when it was edited
+	Final string `json:"final,omitempty"`
+	// Commit is the hash of the commit made with the message
+	Commit string `json:"commit,omitempty"`
+}
+
+// Text returns the message as committed, or as generated
+func (e Entry) Text() string {
+	if e.Final != "" {
+		return e.Final
+	}
+	return e.Message
+}
+
+// Store is an append-only JSON Lines file of entries. Later records with the same ID
+// update an entry, so a message is saved before the user decides on it.
+type Store struct {
+	path       string
+	maxEntries int
+}
+
+// New opens the history file at path, keeping at most maxEntries messages
+// (DefaultMaxEntries when 0)
+func New(path string, maxEntries int) *Store {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Store{path: path, maxEntries: maxEntries}
+}
+
+// DefaultPath returns the history file location (e.g. ~/.config/sgit/history.jsonl)
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding home directory: %v", err)
+	}
+	return filepath.Join(home, ".config", "sgit", "history.jsonl"), nil
+}
+
+// Path returns the history file location
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Add records a new message and returns its ID for Update
+func (s *Store) Add(entry Entry) (string, error) {
+	if entry.ID == "" {
+		id := make([]byte, 8)
+		if _, err := rand.Read(id); err != nil {
+			return "", err
+		}
+		entry.ID = hex.EncodeToString(id)
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if entry.Status == "" {
+		entry.Status = StatusGenerated
+	}
+	if err := s.append(entry); err != nil {
+		return "", err
+	}
+	return entry.ID, nil
+}
+
+// Update records the outcome of a message; empty fields of update are left as they were
+func (s *Store) Update(id string, update Entry) error {
+	update.ID, update.Time = id, time.Time{}
+	return s.append(update)
+}
+
+// List returns the entries, newest first. Entries of other repositories are left out
+// unless repo is empty.
+func (s *Store) List(repo string) ([]Entry, error) {
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		if repo == "" || entries[i].Repo == repo {
+			result = append(result, entries[i])
+		}
+	}
+	return result, nil
+}
+
+// Clear removes all entries
+func (s *Store) Clear() error {
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing %s: %v", s.path, err)
+	}
+	return nil
+}
+
+// load reads the file, folding updates into their entries, oldest first
+func (s *Store) load() ([]Entry, error) {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading history: %v", err)
+	}
+	defer file.Close()
+
+	byID := make(map[string]*Entry)
+	var order []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var record Entry
+		// Skip lines a crash left half-written
+		if json.Unmarshal(scanner.Bytes(), &record) != nil || record.ID == "" {
+			continue
+		}
+
+		entry, ok := byID[record.ID]
+		if !ok {
+			if record.Time.IsZero() {
+				// An update of an entry that was trimmed away
+				continue
+			}
+			byID[record.ID] = &record
+			order = append(order, record.ID)
+			continue
+		}
+		if record.Status != "" {
+			entry.Status = record.Status
+		}
+		if record.Message != "" {
+			entry.Message = record.Message
+		}
+		if record.Final != "" {
+			entry.Final = record.Final
+		}
+		if record.Commit != "" {
+			entry.Commit = record.Commit
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history: %v", err)
+	}
+
+	entries := make([]Entry, 0, len(order))
+	for _, id := range order {
+		entries = append(entries, *byID[id])
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return entries, nil
+}
+
+func (s *Store) append(record Entry) error {
+	// Updates have no time of their own
+	var timestamp *time.Time
+	if !record.Time.IsZero() {
+		timestamp = &record.Time
+	}
+	line, err := json.Marshal(struct {
+		Entry
+		Time *time.Time `json:"time,omitempty"`
+	}{record, timestamp})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("error creating history directory: %v", err)
+	}
+
+	// Messages can describe private code, so only the user can read them
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("error writing history: %v", err)
+	}
+	_, err = file.Write(append(line, '\n'))
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("error writing history: %v", err)
+	}
+
+	if record.Time.IsZero() {
+		return nil
+	}
+	return s.trim()
+}
+
+// trim rewrites the file with the newest entries when it holds more than the limit,
+// leaving some slack so it isn't rewritten on every message
+func (s *Store) trim() error {
+	entries, err := s.load()
+	if err != nil || len(entries) <= s.maxEntries+s.maxEntries/10 {
+		return err
+	}
+	entries = entries[len(entries)-s.maxEntries:]
+
+	var b strings.Builder
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("error writing history: %v", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error writing history: %v", err)
+	}
+	return nil
+}
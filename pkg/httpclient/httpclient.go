@@ -0,0 +1,124 @@
+// Package httpclient provides the HTTP client every API call of an sgit process goes
+// through: the LLM providers, GitHub, GitLab, Bitbucket and the ticket trackers. Sharing
+// one client keeps connections alive between calls, so a command that calls the API
+// several times, or sgit serve and sgit ipc across requests, pays for DNS, TCP and TLS
+// setup once per host instead of on every call.
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// dnsCacheTTL is how long resolved addresses are reused before looking a host up
+	// again
+	dnsCacheTTL = 5 * time.Minute
+	// idleConnTimeout is how long an unused connection is kept open; long enough to
+	// span the pauses of an interactive command, e.g. while the user reviews a message
+	idleConnTimeout = 5 * time.Minute
+	// maxIdleConnsPerHost allows a few concurrent calls to one API, e.g. the per-file
+	// summaries of a large diff, to keep their connections
+	maxIdleConnsPerHost = 8
+)
+
+var (
+	sharedOnce   sync.Once
+	sharedClient *http.Client
+)
+
+// Shared returns the process-wide HTTP client. It has no overall timeout, since
+// streamed responses can take minutes; calls are bounded by their context.
+func Shared() *http.Client {
+	sharedOnce.Do(func() {
+		sharedClient = &http.Client{Transport: newTransport()}
+	})
+	return sharedClient
+}
+
+// newTransport returns a transport like http.DefaultTransport, including its proxy
+// settings from the environment, that negotiates HTTP/2 (which also runs concurrent
+// calls to a host over one connection), keeps more idle connections for longer, and
+// caches DNS lookups
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = true
+	transport.MaxIdleConns = 64
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.IdleConnTimeout = idleConnTimeout
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	resolver := &dnsCache{entries: make(map[string]dnsEntry)}
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil || net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, address)
+		}
+		addresses, err := resolver.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		// Try each address in turn, like the default dialer
+		var dialErr error
+		for _, ip := range addresses {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			dialErr = err
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		resolver.forget(host)
+		return nil, dialErr
+	}
+	return transport
+}
+
+// dnsCache remembers the addresses of hosts for dnsCacheTTL
+type dnsCache struct {
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+}
+
+type dnsEntry struct {
+	addresses []string
+	expires   time.Time
+}
+
+// lookup returns the addresses of host, from the cache while they are fresh
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.addresses, nil
+	}
+
+	addresses, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	// The addresses are dialed one at a time, without the default dialer's racing of
+	// IPv6 and IPv4, so try IPv4 first: a broken IPv6 route can hang until the timeout
+	sort.SliceStable(addresses, func(i, j int) bool {
+		return net.ParseIP(addresses[i]).To4() != nil && net.ParseIP(addresses[j]).To4() == nil
+	})
+	c.mu.Lock()
+	c.entries[host] = dnsEntry{addresses: addresses, expires: time.Now().Add(dnsCacheTTL)}
+	c.mu.Unlock()
+	return addresses, nil
+}
+
+// forget drops host from the cache, e.g. after none of its addresses could be
+// reached, so the next dial looks it up again
+func (c *dnsCache) forget(host string) {
+	c.mu.Lock()
+	delete(c.entries, host)
+	c.mu.Unlock()
+}
@@ -0,0 +1,65 @@
+// Package i18n translates sgit's own terminal messages (progress, prompts, errors)
+// into the user's language. Messages are looked up by their English text, so any
+// message without a translation is shown in English.
+package i18n
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"golang.org/x/text/language"
+)
+
+// catalogs holds the translations of each supported language, keyed by the English
+// message or format string
+var catalogs = map[language.Tag]map[string]string{
+	language.Korean:   korean,
+	language.Japanese: japanese,
+}
+
+// supported lists the languages messages can be shown in, English first as the
+// fallback
+var supported = []language.Tag{language.English, language.Korean, language.Japanese}
+
+var matcher = language.NewMatcher(supported)
+
+// active is the catalog in use, nil for English
+var active atomic.Pointer[map[string]string]
+
+// SetLanguage shows messages in lang, a BCP-47 code such as ko or ja-JP. Languages
+// without translations show messages in English.
+func SetLanguage(lang string) {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		active.Store(nil)
+		return
+	}
+	_, index, confidence := matcher.Match(tag)
+	catalog, ok := catalogs[supported[index]]
+	if confidence == language.No || !ok {
+		active.Store(nil)
+		return
+	}
+	active.Store(&catalog)
+}
+
+// T translates format and formats it with args like fmt.Sprintf. Without args, the
+// translation is returned as it is.
+func T(format string, args ...interface{}) string {
+	format = Lookup(format)
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Lookup returns the translation of a complete message, such as an error's text, or
+// the message itself when there is none
+func Lookup(message string) string {
+	if catalog := active.Load(); catalog != nil {
+		if translated, ok := (*catalog)[message]; ok {
+			return translated
+		}
+	}
+	return message
+}
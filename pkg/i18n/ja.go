@@ -0,0 +1,82 @@
+package i18n
+
+// japanese holds the Japanese translations
+var japanese = map[string]string{
+	// Progress
+	"Gathering context":                            "コンテキストを収集中",
+	"Waiting for Solar LLM...":                     "Solar LLM の応答を待っています...",
+	"Critiquing the commit message":                "コミットメッセージを評価中",
+	"Planning which changes to include":            "含める変更を検討中",
+	"Reviewing the pull request":                   "プルリクエストをレビュー中",
+	"Exploring the repository":                     "リポジトリを調査中",
+	"Looking for lost commits":                     "失われたコミットを検索中",
+	"Writing the squashed commit message":          "まとめたコミットのメッセージを作成中",
+	"📊 %s: ~%d tokens":                             "📊 %s: ~%d トークン",
+	"📊 %s: ~%d tokens (truncated from ~%d tokens)": "📊 %s: ~%d トークン (~%d トークンから切り詰め)",
+	"Branch analysis":                              "ブランチ分析",
+	"Commit history":                               "コミット履歴",
+	"Content analysis":                             "内容分析",
+	"Diff analysis":                                "差分分析",
+	"History analysis":                             "履歴分析",
+	"Log analysis":                                 "ログ分析",
+	"Pull request analysis":                        "プルリクエスト分析",
+	"Reflog":                                       "Reflog",
+	"Repository overview":                          "リポジトリ概要",
+	"Squashed commits":                             "まとめるコミット",
+	"🔒 Redacted before sending: %s\n":              "🔒 送信前に伏せ字にしました: %s\n",
+	"🧹 Left out of the AI prompt: %s\n":            "🧹 AI プロンプトから除外: %s\n",
+	"🧹 Left out %d whitespace-only hunk(s)\n":      "🧹 空白のみのハンクを %d 件除外\n",
+
+	// Commit
+	"Staging all modified and deleted files...": "変更・削除されたファイルをすべてステージしています...",
+	"No changes to commit":                      "コミットする変更はありません",
+	"📦 Subproject %s (scope: %s)\n":             "📦 サブプロジェクト %s (スコープ: %s)\n",
+	"📦 Subproject %s\n":                         "📦 サブプロジェクト %s\n",
+	"Regenerating the message for the last commit and the staged changes with Solar LLM...": "Solar LLM で直前のコミットとステージされた変更のメッセージを再生成しています...",
+	"Generating comprehensive commit message with Solar LLM...":                             "Solar LLM でコミットメッセージを生成しています...",
+	"Generated commit message: ":                                                                    "生成されたコミットメッセージ: ",
+	"Refined commit message: ":                                                                      "修正したコミットメッセージ: ",
+	"\n⚠️  Couldn't generate a commit message: %v\n":                                                "\n⚠️  コミットメッセージを生成できませんでした: %v\n",
+	"📝 Using a draft message from the staged files instead (fallback: %s)\n":                        "📝 代わりにステージされたファイルから作成した下書きを使います (fallback: %s)\n",
+	"\nDraft commit message:\n%s\n\n":                                                               "\n下書きのコミットメッセージ:\n%s\n\n",
+	"\n✓ Commit message generated!":                                                                 "\n✓ コミットメッセージを生成しました！",
+	"🎫 Referenced %s in the message footer\n":                                                       "🎫 メッセージのフッターで %s を参照しました\n",
+	"♻️  Reusing the message generated %s (%s)\n":                                                   "♻️  %s に生成したメッセージを再利用します (%s)\n",
+	"Edit message (press Enter to use as-is): ":                                                     "メッセージを編集 (そのまま使う場合は Enter): ",
+	"Use this commit message? (y/n): ":                                                              "このコミットメッセージを使いますか？ (y/n): ",
+	"Commit cancelled":                                                                              "コミットを取り消しました",
+	"Empty commit message, aborting commit":                                                         "コミットメッセージが空のため、コミットを中止します",
+	"💡 Run 'sgit commit --reuse-last' to commit with the message after all":                         "💡 やはりこのメッセージでコミットするには 'sgit commit --reuse-last' を実行してください",
+	"\n💬 How should the message change? (Enter to accept): ":                                        "\n💬 メッセージをどう変えますか？ (そのまま使う場合は Enter): ",
+	"AI-generated message based on your changes.\nYou can edit, replace, or completely rewrite it.": "変更内容をもとに AI が生成したメッセージです。\n編集、置き換え、全面的な書き直しができます。",
+	"Draft message from the staged files; the AI message couldn't be generated.\nReplace the subject with what the change does and why.": "AI メッセージを生成できなかったため、ステージされたファイルから作成した下書きです。\n件名を変更の内容と理由に書き換えてください。",
+	"Message reused from the history ('sgit history').\nYou can edit, replace, or completely rewrite it.":                                "履歴 ('sgit history') から再利用したメッセージです。\n編集、置き換え、全面的な書き直しができます。",
+	"Please edit the commit message above.\nLines starting with '#' will be ignored.\nAn empty message aborts the commit.":               "上のコミットメッセージを編集してください。\n'#' で始まる行は無視されます。\nメッセージが空の場合はコミットを中止します。",
+	"\n⚠️  Generated message failed validation, regenerating (attempt %d/%d):\n":                                                         "\n⚠️  生成されたメッセージが検証に通らなかったため再生成します (試行 %d/%d):\n",
+	"⚠️  The message still doesn't pass validation, please fix it before committing:":                                                    "⚠️  メッセージはまだ検証に通りません。コミットする前に修正してください:",
+
+	// Staging
+	"📋 Unstaged changes:": "📋 ステージされていない変更:",
+	"Stage the %d file(s) marked ✅? (y = yes, a = all, n = none) [y]: ": "✅ の付いた %d 個のファイルをステージしますか？ (y = はい, a = すべて, n = なし) [y]: ",
+	"Nothing staged":          "何もステージしませんでした",
+	"✅ Staged %d file(s)\n\n": "✅ %d 個のファイルをステージしました\n\n",
+
+	// Large diffs
+	"⚠️  The diff is ~%d tokens%s, over max_diff_tokens (%d)\n":      "⚠️  差分は ~%d トークン%s で、max_diff_tokens (%d) を超えています\n",
+	"Send it whole (y), summarized per file (s), or stop (n)? [s]: ": "全体を送信 (y)、ファイルごとに要約 (s)、中止 (n) のどれにしますか？ [s]: ",
+	"✂️  Summarized the diff per file: ~%d tokens":                   "✂️  差分をファイルごとに要約しました: ~%d トークン",
+
+	// Errors
+	"Error: %v\n": "エラー: %v\n",
+	"Interrupted": "中断しました",
+	"Still stopping; press Ctrl-C again to quit now":                 "停止しています。すぐに終了するにはもう一度 Ctrl-C を押してください",
+	"⏳ API request failed (%s), retrying in %s (attempt %d/%d)...\n": "⏳ API リクエストが失敗しました (%s)。%s 後に再試行します (試行 %d/%d)...\n",
+	"a minute": "1 分",
+	"The API key was rejected. Run 'sgit config init' to set a new one, or set SGIT_API_KEY":                                                                   "API キーが拒否されました。'sgit config init' で新しいキーを設定するか、SGIT_API_KEY を設定してください",
+	"The API is rate limiting requests; retry in %s (max_retry_attempts sets how often sgit retries by itself)":                                                "API のレート制限に達しました。%s 後に再試行してください (sgit の自動再試行回数は max_retry_attempts で設定します)",
+	"The diff is too large for the model. Stage and commit fewer files at a time, use 'sgit diff --summary-only', or leave files out with diff_filter.exclude": "差分がモデルには大きすぎます。一度にコミットするファイルを減らすか、'sgit diff --summary-only' を使うか、diff_filter.exclude でファイルを除外してください",
+	"Couldn't reach the API. Check your network connection and proxy settings, and base_url if you set one":                                                    "API に接続できませんでした。ネットワーク接続とプロキシ設定、設定している場合は base_url を確認してください",
+	"not a git repository": "git リポジトリではありません",
+	"no API key configured (set SGIT_API_KEY or run 'sgit config init')":                     "API キーが設定されていません (SGIT_API_KEY を設定するか 'sgit config init' を実行してください)",
+	"no diff found - make sure to add files with 'git add' first, or use --include-unstaged": "差分がありません - 先に 'git add' でファイルを追加するか、--include-unstaged を使ってください",
+}
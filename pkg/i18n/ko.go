@@ -0,0 +1,82 @@
+package i18n
+
+// korean holds the Korean translations
+var korean = map[string]string{
+	// Progress
+	"Gathering context":                            "컨텍스트 수집 중",
+	"Waiting for Solar LLM...":                     "Solar LLM 응답 대기 중...",
+	"Critiquing the commit message":                "커밋 메시지 검토 중",
+	"Planning which changes to include":            "포함할 변경 사항 계획 중",
+	"Reviewing the pull request":                   "풀 리퀘스트 리뷰 중",
+	"Exploring the repository":                     "저장소 살펴보는 중",
+	"Looking for lost commits":                     "잃어버린 커밋 찾는 중",
+	"Writing the squashed commit message":          "합친 커밋의 메시지 작성 중",
+	"📊 %s: ~%d tokens":                             "📊 %s: ~%d 토큰",
+	"📊 %s: ~%d tokens (truncated from ~%d tokens)": "📊 %s: ~%d 토큰 (~%d 토큰에서 잘라냄)",
+	"Branch analysis":                              "브랜치 분석",
+	"Commit history":                               "커밋 기록",
+	"Content analysis":                             "내용 분석",
+	"Diff analysis":                                "변경 사항 분석",
+	"History analysis":                             "기록 분석",
+	"Log analysis":                                 "로그 분석",
+	"Pull request analysis":                        "풀 리퀘스트 분석",
+	"Reflog":                                       "Reflog",
+	"Repository overview":                          "저장소 개요",
+	"Squashed commits":                             "합칠 커밋",
+	"🔒 Redacted before sending: %s\n":              "🔒 전송 전에 가림: %s\n",
+	"🧹 Left out of the AI prompt: %s\n":            "🧹 AI 프롬프트에서 제외: %s\n",
+	"🧹 Left out %d whitespace-only hunk(s)\n":      "🧹 공백만 바뀐 헝크 %d개 제외\n",
+
+	// Commit
+	"Staging all modified and deleted files...": "수정 및 삭제된 파일을 모두 스테이징하는 중...",
+	"No changes to commit":                      "커밋할 변경 사항이 없습니다",
+	"📦 Subproject %s (scope: %s)\n":             "📦 하위 프로젝트 %s (스코프: %s)\n",
+	"📦 Subproject %s\n":                         "📦 하위 프로젝트 %s\n",
+	"Regenerating the message for the last commit and the staged changes with Solar LLM...": "Solar LLM으로 마지막 커밋과 스테이징된 변경 사항의 메시지를 다시 생성하는 중...",
+	"Generating comprehensive commit message with Solar LLM...":                             "Solar LLM으로 커밋 메시지를 생성하는 중...",
+	"Generated commit message: ":                                                                    "생성된 커밋 메시지: ",
+	"Refined commit message: ":                                                                      "다듬은 커밋 메시지: ",
+	"\n⚠️  Couldn't generate a commit message: %v\n":                                                "\n⚠️  커밋 메시지를 생성하지 못했습니다: %v\n",
+	"📝 Using a draft message from the staged files instead (fallback: %s)\n":                        "📝 대신 스테이징된 파일로 만든 초안 메시지를 사용합니다 (fallback: %s)\n",
+	"\nDraft commit message:\n%s\n\n":                                                               "\n초안 커밋 메시지:\n%s\n\n",
+	"\n✓ Commit message generated!":                                                                 "\n✓ 커밋 메시지를 생성했습니다!",
+	"🎫 Referenced %s in the message footer\n":                                                       "🎫 메시지 푸터에 %s 참조를 추가했습니다\n",
+	"♻️  Reusing the message generated %s (%s)\n":                                                   "♻️  %s에 생성된 메시지를 다시 사용합니다 (%s)\n",
+	"Edit message (press Enter to use as-is): ":                                                     "메시지 수정 (그대로 사용하려면 Enter): ",
+	"Use this commit message? (y/n): ":                                                              "이 커밋 메시지를 사용할까요? (y/n): ",
+	"Commit cancelled":                                                                              "커밋을 취소했습니다",
+	"Empty commit message, aborting commit":                                                         "커밋 메시지가 비어 있어 커밋을 중단합니다",
+	"💡 Run 'sgit commit --reuse-last' to commit with the message after all":                         "💡 그래도 이 메시지로 커밋하려면 'sgit commit --reuse-last'를 실행하세요",
+	"\n💬 How should the message change? (Enter to accept): ":                                        "\n💬 메시지를 어떻게 바꿀까요? (수락하려면 Enter): ",
+	"AI-generated message based on your changes.\nYou can edit, replace, or completely rewrite it.": "변경 사항을 바탕으로 AI가 생성한 메시지입니다.\n수정하거나 바꾸거나 완전히 새로 써도 됩니다.",
+	"Draft message from the staged files; the AI message couldn't be generated.\nReplace the subject with what the change does and why.": "AI 메시지를 생성하지 못해 스테이징된 파일로 만든 초안입니다.\n제목을 변경 내용과 이유로 바꿔 주세요.",
+	"Message reused from the history ('sgit history').\nYou can edit, replace, or completely rewrite it.":                                "기록('sgit history')에서 다시 사용한 메시지입니다.\n수정하거나 바꾸거나 완전히 새로 써도 됩니다.",
+	"Please edit the commit message above.\nLines starting with '#' will be ignored.\nAn empty message aborts the commit.":               "위의 커밋 메시지를 수정하세요.\n'#'로 시작하는 줄은 무시됩니다.\n메시지가 비어 있으면 커밋이 중단됩니다.",
+	"\n⚠️  Generated message failed validation, regenerating (attempt %d/%d):\n":                                                         "\n⚠️  생성된 메시지가 검증을 통과하지 못해 다시 생성합니다 (시도 %d/%d):\n",
+	"⚠️  The message still doesn't pass validation, please fix it before committing:":                                                    "⚠️  메시지가 아직 검증을 통과하지 못합니다. 커밋하기 전에 고쳐 주세요:",
+
+	// Staging
+	"📋 Unstaged changes:": "📋 스테이징되지 않은 변경 사항:",
+	"Stage the %d file(s) marked ✅? (y = yes, a = all, n = none) [y]: ": "✅ 표시된 파일 %d개를 스테이징할까요? (y = 예, a = 모두, n = 아니요) [y]: ",
+	"Nothing staged":          "스테이징한 파일이 없습니다",
+	"✅ Staged %d file(s)\n\n": "✅ 파일 %d개를 스테이징했습니다\n\n",
+
+	// Large diffs
+	"⚠️  The diff is ~%d tokens%s, over max_diff_tokens (%d)\n":      "⚠️  변경 사항이 ~%d 토큰%s으로 max_diff_tokens(%d)를 넘습니다\n",
+	"Send it whole (y), summarized per file (s), or stop (n)? [s]: ": "전체를 보낼까요 (y), 파일별로 요약할까요 (s), 아니면 중단할까요 (n)? [s]: ",
+	"✂️  Summarized the diff per file: ~%d tokens":                   "✂️  변경 사항을 파일별로 요약했습니다: ~%d 토큰",
+
+	// Errors
+	"Error: %v\n": "오류: %v\n",
+	"Interrupted": "중단되었습니다",
+	"Still stopping; press Ctrl-C again to quit now":                 "중단하는 중입니다. 바로 종료하려면 Ctrl-C를 한 번 더 누르세요",
+	"⏳ API request failed (%s), retrying in %s (attempt %d/%d)...\n": "⏳ API 요청 실패 (%s), %s 후 다시 시도합니다 (시도 %d/%d)...\n",
+	"a minute": "1분",
+	"The API key was rejected. Run 'sgit config init' to set a new one, or set SGIT_API_KEY":                                                                   "API 키가 거부되었습니다. 'sgit config init'으로 새 키를 설정하거나 SGIT_API_KEY를 설정하세요",
+	"The API is rate limiting requests; retry in %s (max_retry_attempts sets how often sgit retries by itself)":                                                "API 요청 한도를 넘었습니다. %s 후에 다시 시도하세요 (sgit의 자동 재시도 횟수는 max_retry_attempts로 설정합니다)",
+	"The diff is too large for the model. Stage and commit fewer files at a time, use 'sgit diff --summary-only', or leave files out with diff_filter.exclude": "변경 사항이 모델에 비해 너무 큽니다. 한 번에 더 적은 파일을 커밋하거나, 'sgit diff --summary-only'를 사용하거나, diff_filter.exclude로 파일을 제외하세요",
+	"Couldn't reach the API. Check your network connection and proxy settings, and base_url if you set one":                                                    "API에 연결할 수 없습니다. 네트워크 연결과 프록시 설정, 그리고 설정했다면 base_url을 확인하세요",
+	"not a git repository": "git 저장소가 아닙니다",
+	"no API key configured (set SGIT_API_KEY or run 'sgit config init')":                     "API 키가 설정되지 않았습니다 (SGIT_API_KEY를 설정하거나 'sgit config init'을 실행하세요)",
+	"no diff found - make sure to add files with 'git add' first, or use --include-unstaged": "변경 사항이 없습니다 - 먼저 'git add'로 파일을 추가하거나 --include-unstaged를 사용하세요",
+}
@@ -0,0 +1,196 @@
+// Package leftovers finds debug code left in a change by accident: print statements
+// that dump a variable, debugger breakpoints, FIXME markers, focused tests, debug
+// flags switched on and blocks of commented-out code. Like the secret scanner, it
+// only looks at added lines, so code that was already there is not flagged again.
+package leftovers
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Rule is one kind of leftover, found by a pattern on an added line
+type Rule struct {
+	Name string
+	// Description is what the finding is called in warnings, e.g. "debug print"
+	Description string
+	Pattern     *regexp.Regexp
+	// Extensions limits the rule to files with these extensions; empty means all files
+	Extensions []string
+}
+
+// Finding is a leftover on a line of the new version of a file
+type Finding struct {
+	File        string
+	Line        int
+	Rule        string
+	Description string
+	Text        string
+}
+
+// String describes the finding, e.g. "app.js:12 debug print: console.log(user)"
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d %s: %s", f.File, f.Line, f.Description, f.Text)
+}
+
+// Rule names
+const (
+	RuleDebugPrint    = "debug-print"
+	RuleDebugger      = "debugger"
+	RuleFixme         = "fixme"
+	RuleFocusedTest   = "focused-test"
+	RuleDebugFlag     = "debug-flag"
+	RuleCommentedCode = "commented-out-code"
+)
+
+// minCommentedLines is how many added comment lines in a row that read as code make
+// a commented-out block; one or two are often an example in a comment
+const minCommentedLines = 3
+
+var (
+	goFiles     = []string{".go"}
+	jsFiles     = []string{".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs", ".vue", ".svelte"}
+	pythonFiles = []string{".py"}
+	rubyFiles   = []string{".rb"}
+	phpFiles    = []string{".php"}
+	rustFiles   = []string{".rs"}
+	jvmFiles    = []string{".java", ".kt", ".scala"}
+	// docFiles are never scanned: they show debug code and FIXMEs on purpose
+	docFiles = []string{".md", ".markdown", ".rst", ".txt", ".adoc"}
+)
+
+// DefaultRules are the patterns of the leftovers found by ScanDiff. Print statements
+// are only flagged when they dump a value in a way only a debugging session would, or
+// say "debug", since printing is how many programs talk to their users.
+var DefaultRules = []Rule{
+	{Name: RuleDebugPrint, Description: "debug print", Extensions: goFiles,
+		Pattern: regexp.MustCompile(`\b(fmt\.Printf\(\s*"%[+#]v|(fmt|log)\.Print(ln|f)?\(\s*"(?i:debug|here|xxx)|spew\.Dump\(|^\s*print(ln)?\()`)},
+	{Name: RuleDebugPrint, Description: "debug print", Extensions: jsFiles,
+		Pattern: regexp.MustCompile(`\bconsole\.(log|debug|trace|dir|table)\(`)},
+	{Name: RuleDebugPrint, Description: "debug print", Extensions: pythonFiles,
+		Pattern: regexp.MustCompile(`^\s*(print\(\s*[A-Za-z_][\w.\[\]'"]*\s*\)\s*$|print\(\s*f?["'](?i:debug|here|xxx)|pprint\()`)},
+	{Name: RuleDebugPrint, Description: "debug print", Extensions: rubyFiles,
+		Pattern: regexp.MustCompile(`^\s*(p|pp)\s+\w`)},
+	{Name: RuleDebugPrint, Description: "debug print", Extensions: phpFiles,
+		Pattern: regexp.MustCompile(`\b(var_dump|print_r|dd|dump)\(`)},
+	{Name: RuleDebugPrint, Description: "debug print", Extensions: rustFiles,
+		Pattern: regexp.MustCompile(`\bdbg!\(`)},
+	{Name: RuleDebugPrint, Description: "debug print", Extensions: jvmFiles,
+		Pattern: regexp.MustCompile(`\b(System\.(out|err)\.print(ln)?\(|\.printStackTrace\(\))`)},
+
+	{Name: RuleDebugger, Description: "debugger breakpoint", Extensions: jsFiles,
+		Pattern: regexp.MustCompile(`^\s*debugger\s*;?\s*$`)},
+	{Name: RuleDebugger, Description: "debugger breakpoint", Extensions: pythonFiles,
+		Pattern: regexp.MustCompile(`\b(breakpoint\(\)|pdb\.set_trace\(\)|import i?pdb\b)`)},
+	{Name: RuleDebugger, Description: "debugger breakpoint", Extensions: rubyFiles,
+		Pattern: regexp.MustCompile(`\b(binding\.(pry|irb)|byebug|debugger)\b`)},
+	{Name: RuleDebugger, Description: "debugger breakpoint", Extensions: goFiles,
+		Pattern: regexp.MustCompile(`\bruntime\.Breakpoint\(\)`)},
+
+	{Name: RuleFixme, Description: "FIXME marker",
+		Pattern: regexp.MustCompile(`\b(FIXME|XXX)\b`)},
+
+	{Name: RuleFocusedTest, Description: "focused test", Extensions: jsFiles,
+		Pattern: regexp.MustCompile(`\b((describe|it|test|context)\.only|fdescribe|fit)\(`)},
+
+	// Only top-level settings and constants: a flag set in a function is usually the
+	// program's own --debug handling
+	{Name: RuleDebugFlag, Description: "debug flag turned on",
+		Pattern: regexp.MustCompile(`(?i)^(export\s+|const\s+|var\s+|let\s+)?["']?[\w.-]*debug[\w.-]*["']?\s*(=|:=|:)\s*["']?(true|1|yes|on)\b`)},
+}
+
+// hunkHeader matches the new-file line range of a unified diff hunk header
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// lineComment matches a line comment in C-like languages, shell, Python and Ruby
+var lineComment = regexp.MustCompile(`^\s*(//|#)\s?(.*)$`)
+
+// codeLike matches the text of a comment that reads as a statement rather than prose
+var codeLike = regexp.MustCompile(`([;{}]\s*$|^\s*}|^\s*(if|for|while|return|func|def|var|let|const|import)\b.*[({:=]|^\s*(return|break|continue|pass)\s*;?\s*$|^[\w.\[\]]+\s*(:=|=|\+=)\s*\S|^[\w.]+\(.*\)\s*$)`)
+
+// ScanDiff finds leftovers on the added lines of a unified diff. Line numbers refer to
+// the new version of each file.
+func ScanDiff(diff string) []Finding {
+	var findings []Finding
+	var currentFile string
+	lineNum := 0
+	// The run of added lines that look like commented-out code
+	var commented []Finding
+	endRun := func() {
+		if len(commented) >= minCommentedLines {
+			first := commented[0]
+			first.Rule, first.Description = RuleCommentedCode, fmt.Sprintf("commented-out code (%d lines)", len(commented))
+			findings = append(findings, first)
+		}
+		commented = nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			endRun()
+			currentFile = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+			if slices.Contains(docFiles, strings.ToLower(path.Ext(currentFile))) {
+				currentFile = ""
+			}
+			continue
+		case strings.HasPrefix(line, "--- "):
+			continue
+		case strings.HasPrefix(line, "@@"):
+			endRun()
+			if m := hunkHeader.FindStringSubmatch(line); m != nil {
+				start, _ := strconv.Atoi(m[1])
+				lineNum = start - 1
+			}
+			continue
+		}
+
+		if currentFile == "" || currentFile == "/dev/null" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "+"):
+			lineNum++
+			text := line[1:]
+			findings = append(findings, scanLine(currentFile, lineNum, text)...)
+			if m := lineComment.FindStringSubmatch(text); m != nil && codeLike.MatchString(m[2]) {
+				commented = append(commented, Finding{File: currentFile, Line: lineNum, Text: strings.TrimSpace(text)})
+			} else {
+				endRun()
+			}
+		case strings.HasPrefix(line, "-"):
+			// Removed lines don't exist in the new file
+		default:
+			endRun()
+			lineNum++
+		}
+	}
+	endRun()
+
+	return findings
+}
+
+// scanLine returns the first leftover on a line, if any, since one is enough to look at it
+func scanLine(file string, lineNum int, text string) []Finding {
+	ext := strings.ToLower(path.Ext(file))
+	for _, rule := range DefaultRules {
+		if len(rule.Extensions) > 0 && !slices.Contains(rule.Extensions, ext) {
+			continue
+		}
+		if rule.Pattern.MatchString(text) {
+			return []Finding{{File: file, Line: lineNum, Rule: rule.Name, Description: rule.Description, Text: strings.TrimSpace(text)}}
+		}
+	}
+	return nil
+}
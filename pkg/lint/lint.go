@@ -0,0 +1,310 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hunkim/sgit/pkg/gitmoji"
+)
+
+// Options controls the rule-based commit message checks
+type Options struct {
+	// Convention is the configured commit convention (conventional, angular, gitmoji, custom)
+	Convention string
+	// MaxSubjectLength is the longest allowed subject line
+	MaxSubjectLength int
+	// MaxBodyLineLength is the longest allowed body line (URLs are exempt)
+	MaxBodyLineLength int
+	// Gitmoji requires a canonical gitmoji before the convention's header
+	Gitmoji bool
+	// Types are the commit types allowed for this repository; empty means the
+	// convention's standard types
+	Types []string
+	// Scopes are the commit scopes allowed for this repository; empty allows any.
+	// A commit may still have no scope.
+	Scopes []string
+}
+
+// DefaultOptions returns the default limits for the given convention
+func DefaultOptions(convention string) Options {
+	return Options{
+		Convention:        convention,
+		MaxSubjectLength:  72,
+		MaxBodyLineLength: 100,
+	}
+}
+
+// Issue is a single problem found in a commit message
+type Issue struct {
+	Rule    string
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("[%s] %s", i.Rule, i.Message)
+}
+
+// conventionalTypes are the commit types accepted by the conventional and angular conventions
+var conventionalTypes = map[string][]string{
+	"conventional": {"feat", "fix", "docs", "style", "refactor", "test", "chore", "perf", "ci", "build", "revert"},
+	"angular":      {"build", "ci", "docs", "feat", "fix", "perf", "refactor", "test", "revert"},
+}
+
+// headerPattern matches "type(scope)!: description"
+var headerPattern = regexp.MustCompile(`^([a-zA-Z]+)(\([^()]+\))?(!)?: (.+)$`)
+
+// footerPattern matches a footer line such as "Refs: PROJ-1", "Closes #12" or
+// "BREAKING CHANGE: ..."
+var footerPattern = regexp.MustCompile(`^((?i:BREAKING[ -]CHANGE)|[A-Za-z][A-Za-z0-9-]*)(: | #)(.*)$`)
+
+// DefaultTypes returns the standard commit types of a convention, or nil when the
+// convention has no fixed types
+func DefaultTypes(convention string) []string {
+	if convention == "" {
+		convention = "conventional"
+	}
+	return conventionalTypes[convention]
+}
+
+// Footer is a trailer line of a commit message, e.g. "Refs: PROJ-1"
+type Footer struct {
+	Token string
+	Value string
+}
+
+// Message is a commit message split into its conventional-commit parts
+type Message struct {
+	// Gitmoji is the leading emoji of the subject, if any
+	Gitmoji string
+	// Type, Scope and Breaking come from a "type(scope)!: description" header;
+	// they are empty when the subject doesn't follow that format
+	Type     string
+	Scope    string
+	Breaking bool
+	// Subject is the full first line; Description is the part after the header
+	Subject     string
+	Description string
+	Body        string
+	Footers     []Footer
+}
+
+// Parse splits a commit message into gitmoji, type, scope, description, body and
+// footers. The last paragraph is treated as footers when every line is one.
+func Parse(message string) Message {
+	message = strings.TrimSpace(message)
+	subject, rest, _ := strings.Cut(message, "\n")
+	parsed := Message{Subject: strings.TrimSpace(subject)}
+
+	header := parsed.Subject
+	if lead, tail := gitmoji.Split(header); lead != "" {
+		parsed.Gitmoji, header = lead, tail
+	}
+	parsed.Description = header
+	if m := headerPattern.FindStringSubmatch(header); m != nil {
+		parsed.Type = m[1]
+		parsed.Scope = strings.Trim(m[2], "()")
+		parsed.Breaking = m[3] == "!"
+		parsed.Description = m[4]
+	}
+
+	paragraphs := strings.Split(strings.TrimSpace(rest), "\n\n")
+	if last := paragraphs[len(paragraphs)-1]; last != "" {
+		var footers []Footer
+		for _, line := range strings.Split(last, "\n") {
+			m := footerPattern.FindStringSubmatch(line)
+			if m == nil {
+				footers = nil
+				break
+			}
+			value := strings.TrimSpace(m[3])
+			if m[2] == " #" {
+				value = "#" + value
+			}
+			footers = append(footers, Footer{Token: m[1], Value: value})
+		}
+		if footers != nil {
+			parsed.Footers = footers
+			paragraphs = paragraphs[:len(paragraphs)-1]
+		}
+	}
+	parsed.Body = strings.TrimSpace(strings.Join(paragraphs, "\n\n"))
+	for _, footer := range parsed.Footers {
+		if footer.Token == "BREAKING CHANGE" || footer.Token == "BREAKING-CHANGE" {
+			parsed.Breaking = true
+		}
+	}
+	return parsed
+}
+
+// nonImperativeWords are common non-imperative verbs that don't follow the -ed/-ing suffix rules
+var nonImperativeWords = map[string]bool{
+	"adds": true, "fixes": true, "updates": true, "removes": true, "changes": true,
+	"implements": true, "improves": true, "refactors": true, "makes": true, "moves": true,
+	"renames": true, "uses": true, "creates": true, "deletes": true, "introduces": true,
+	"made": true, "wrote": true, "built": true, "ran": true, "did": true,
+}
+
+// imperativeEdWords end in -ed but are fine in imperative mood
+var imperativeEdWords = map[string]bool{
+	"embed": true, "feed": true, "seed": true, "need": true, "speed": true, "shed": true,
+	"proceed": true, "exceed": true, "succeed": true, "bleed": true, "breed": true,
+}
+
+// Check validates a commit message against the rules and returns the issues found
+func Check(message string, opts Options) []Issue {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return []Issue{{Rule: "empty", Message: "commit message is empty"}}
+	}
+
+	lines := strings.Split(message, "\n")
+	subject := strings.TrimSpace(lines[0])
+	var issues []Issue
+
+	if strings.HasPrefix(subject, "fixup!") || strings.HasPrefix(subject, "squash!") || strings.HasPrefix(subject, "amend!") {
+		return []Issue{{Rule: "autosquash", Message: "unsquashed fixup/squash commit"}}
+	}
+
+	if opts.MaxSubjectLength > 0 && utf8.RuneCountInString(subject) > opts.MaxSubjectLength {
+		issues = append(issues, Issue{Rule: "subject-length", Message: fmt.Sprintf("subject is %d characters (max %d)", utf8.RuneCountInString(subject), opts.MaxSubjectLength)})
+	}
+	if strings.HasSuffix(subject, ".") {
+		issues = append(issues, Issue{Rule: "subject-period", Message: "subject should not end with a period"})
+	}
+
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		issues = append(issues, Issue{Rule: "body-separator", Message: "subject and body must be separated by a blank line"})
+	}
+	if opts.MaxBodyLineLength > 0 {
+		for i, line := range lines[1:] {
+			if utf8.RuneCountInString(line) > opts.MaxBodyLineLength && !strings.Contains(line, "://") {
+				issues = append(issues, Issue{Rule: "body-line-length", Message: fmt.Sprintf("body line %d is %d characters (max %d)", i+2, utf8.RuneCountInString(line), opts.MaxBodyLineLength)})
+				break
+			}
+		}
+	}
+
+	// The gitmoji convention, or gitmoji: true on top of another one, puts an emoji first
+	header := subject
+	if opts.Gitmoji || opts.Convention == "gitmoji" {
+		lead, rest := gitmoji.Split(subject)
+		if lead == "" {
+			issues = append(issues, Issue{Rule: "gitmoji", Message: "subject must start with a gitmoji"})
+		} else if _, ok := gitmoji.Find(lead); !ok {
+			issues = append(issues, Issue{Rule: "gitmoji", Message: fmt.Sprintf("'%s' is not a gitmoji from https://gitmoji.dev", lead)})
+		}
+		header = rest
+	}
+
+	description := header
+	switch opts.Convention {
+	case "conventional", "angular", "":
+		convention := opts.Convention
+		if convention == "" {
+			convention = "conventional"
+		}
+
+		m := headerPattern.FindStringSubmatch(header)
+		if m == nil {
+			issues = append(issues, Issue{Rule: "header-format", Message: "subject must follow 'type(scope): description'"})
+			break
+		}
+		types := opts.Types
+		if len(types) == 0 {
+			types = conventionalTypes[convention]
+		}
+		if !contains(types, m[1]) {
+			issues = append(issues, Issue{Rule: "type", Message: fmt.Sprintf("unknown type '%s' (allowed: %s)", m[1], strings.Join(types, ", "))})
+		}
+		if scope := strings.Trim(m[2], "()"); scope != "" && len(opts.Scopes) > 0 {
+			// Several scopes are separated by commas, e.g. feat(api,web)
+			for _, s := range strings.Split(scope, ",") {
+				if s = strings.TrimSpace(s); !contains(opts.Scopes, s) {
+					issues = append(issues, Issue{Rule: "scope", Message: fmt.Sprintf("unknown scope '%s' (allowed: %s)", s, strings.Join(opts.Scopes, ", "))})
+				}
+			}
+		}
+		description = m[4]
+		if convention == "angular" {
+			if first, _ := utf8.DecodeRuneInString(description); unicode.IsUpper(first) {
+				issues = append(issues, Issue{Rule: "subject-case", Message: "summary should start with a lowercase letter"})
+			}
+		}
+	}
+
+	if word := firstWord(description); word != "" && !isImperative(word) {
+		issues = append(issues, Issue{Rule: "imperative-mood", Message: fmt.Sprintf("use imperative mood ('%s' → e.g. '%s')", word, imperativeSuggestion(word))})
+	}
+
+	if strings.TrimSpace(description) == "" {
+		issues = append(issues, Issue{Rule: "subject-empty", Message: "subject has no description"})
+	}
+	for _, footer := range Parse(message).Footers {
+		if strings.EqualFold(footer.Token, "breaking change") || strings.EqualFold(footer.Token, "breaking-change") {
+			if footer.Token != "BREAKING CHANGE" && footer.Token != "BREAKING-CHANGE" {
+				issues = append(issues, Issue{Rule: "footer-breaking-change", Message: fmt.Sprintf("write '%s' as 'BREAKING CHANGE'", footer.Token)})
+			}
+			if footer.Value == "" {
+				issues = append(issues, Issue{Rule: "footer-breaking-change", Message: "BREAKING CHANGE footer needs a description"})
+			}
+		}
+	}
+
+	return issues
+}
+
+func firstWord(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(strings.Trim(fields[0], ".,:;!?\"'`"))
+}
+
+// isImperative reports whether word looks like an imperative verb
+func isImperative(word string) bool {
+	if nonImperativeWords[word] {
+		return false
+	}
+	if strings.HasSuffix(word, "ed") && len(word) > 4 && !imperativeEdWords[word] {
+		return false
+	}
+	if strings.HasSuffix(word, "ing") && len(word) > 5 {
+		return false
+	}
+	return true
+}
+
+// imperativeSuggestion guesses the imperative form of a non-imperative verb
+func imperativeSuggestion(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "ied"):
+		return strings.TrimSuffix(word, "ied") + "y"
+	case strings.HasSuffix(word, "es") && (strings.HasSuffix(word, "xes") || strings.HasSuffix(word, "shes") || strings.HasSuffix(word, "ches")):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ed"):
+		if strings.HasSuffix(word, "ated") || strings.HasSuffix(word, "oved") || strings.HasSuffix(word, "ased") || strings.HasSuffix(word, "sed") || strings.HasSuffix(word, "ged") || strings.HasSuffix(word, "ved") {
+			return strings.TrimSuffix(word, "d")
+		}
+		return strings.TrimSuffix(word, "ed")
+	case strings.HasSuffix(word, "ing"):
+		return strings.TrimSuffix(word, "ing")
+	case strings.HasSuffix(word, "s"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+// Package logging records what sgit does behind the scenes (API requests, git
+// commands, timings, token counts and truncation decisions) as structured key=value
+// lines, for --verbose and --debug. Nothing is logged until Configure is called, so
+// programs embedding sgit's packages stay silent.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Level is how much is logged
+type Level int
+
+const (
+	// LevelOff logs nothing
+	LevelOff Level = iota
+	// LevelVerbose logs API requests, timings and token counts
+	LevelVerbose
+	// LevelDebug also logs every git command and truncation decision
+	LevelDebug
+)
+
+var logger atomic.Pointer[slog.Logger]
+
+// Configure starts logging at level to w, e.g. stderr or a log file; LevelOff stops it
+func Configure(level Level, w io.Writer) {
+	if level <= LevelOff || w == nil {
+		logger.Store(nil)
+		return
+	}
+	handlerLevel := slog.LevelInfo
+	if level >= LevelDebug {
+		handlerLevel = slog.LevelDebug
+	}
+	logger.Store(slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: handlerLevel})))
+}
+
+// Enabled reports whether messages at level are logged, to skip work that only
+// feeds the log
+func Enabled(level Level) bool {
+	l := logger.Load()
+	if l == nil || level <= LevelOff {
+		return false
+	}
+	if level >= LevelDebug {
+		return l.Enabled(context.Background(), slog.LevelDebug)
+	}
+	return true
+}
+
+// Info logs msg with key-value pairs at the verbose level
+func Info(msg string, args ...any) {
+	if l := logger.Load(); l != nil {
+		l.Info(msg, args...)
+	}
+}
+
+// Debug logs msg with key-value pairs at the debug level
+func Debug(msg string, args ...any) {
+	if l := logger.Load(); l != nil {
+		l.Debug(msg, args...)
+	}
+}
@@ -0,0 +1,271 @@
+// Package msgformat gives generated commit messages the shape git expects: a subject
+// that fits on one line, a body wrapped to a fixed width, one style of bullet, and
+// none of the markdown models like to write (code fences, **bold**, # headings),
+// which git log shows as it is and git commit's cleanup partly eats.
+package msgformat
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Options controls how a commit message is formatted
+type Options struct {
+	// MaxSubjectLength is the longest subject line; a longer one is cut at a word
+	// and continued in the body. 0 leaves the subject as it is.
+	MaxSubjectLength int
+	// BodyWidth is the column body paragraphs and bullets are wrapped at. Only
+	// paragraphs with a longer line are rewrapped; code, URLs and trailers never are.
+	// 0 leaves the body as it is.
+	BodyWidth int
+	// Bullet is the marker list items are given, e.g. "-"; empty keeps the model's
+	Bullet string
+	// StripMarkdown removes code fences, **bold**, # heading markers and horizontal
+	// rules
+	StripMarkdown bool
+}
+
+// DefaultOptions returns git's conventional limits: 72-column subjects and bodies,
+// "-" bullets and no markdown
+func DefaultOptions() Options {
+	return Options{
+		MaxSubjectLength: 72,
+		BodyWidth:        72,
+		Bullet:           "-",
+		StripMarkdown:    true,
+	}
+}
+
+// ellipsis marks where a subject that was too long is cut, and where the body picks
+// it up, the way GitHub shows long subjects
+const ellipsis = "…"
+
+var (
+	fencePattern   = regexp.MustCompile("^\\s*(```|~~~)")
+	boldPattern    = regexp.MustCompile(`\*\*([^*\s](?:[^*\n]*[^*\s])?)\*\*`)
+	headingPattern = regexp.MustCompile(`^#{1,6}\s+`)
+	rulePattern    = regexp.MustCompile(`^\s*(-{3,}|\*{3,}|_{3,})\s*$`)
+	// bulletPattern matches a list item: indentation, marker and text
+	bulletPattern = regexp.MustCompile(`^(\s*)([-*+•–]|\d+[.)])\s+(.*)$`)
+	// trailerPattern matches a trailer line such as "Refs: PROJ-1" or "Closes #12"
+	trailerPattern = regexp.MustCompile(`^((?i:BREAKING[ -]CHANGE)|[A-Za-z][A-Za-z0-9-]*)(: | #)`)
+)
+
+// Format returns message formatted with opts
+func Format(message string, opts Options) string {
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	if message == "" {
+		return message
+	}
+	if opts.StripMarkdown {
+		message = stripMarkdown(message)
+	}
+
+	subject, body, _ := strings.Cut(message, "\n")
+	subject = strings.TrimSpace(subject)
+	body = strings.Trim(body, "\n")
+	if opts.MaxSubjectLength > 0 {
+		var rest string
+		subject, rest = capSubject(subject, opts.MaxSubjectLength)
+		if rest != "" {
+			body = strings.TrimSpace(rest + "\n\n" + body)
+		}
+	}
+	if strings.TrimSpace(body) == "" {
+		return subject
+	}
+
+	paragraphs := strings.Split(body, "\n\n")
+	for i, paragraph := range paragraphs {
+		// The trailers are left as they are, however long
+		if i == len(paragraphs)-1 && isTrailerBlock(paragraph) {
+			continue
+		}
+		paragraphs[i] = formatParagraph(strings.Trim(paragraph, "\n"), opts)
+	}
+	return subject + "\n\n" + strings.Join(paragraphs, "\n\n")
+}
+
+// stripMarkdown removes the fences around the message or code in it (the code is
+// indented instead, which git log shows as code too), **bold**, heading markers and
+// horizontal rules
+func stripMarkdown(message string) string {
+	lines := strings.Split(message, "\n")
+	// A message wrapped in one fence loses it
+	if len(lines) > 2 && fencePattern.MatchString(lines[0]) && fencePattern.MatchString(lines[len(lines)-1]) {
+		lines = lines[1 : len(lines)-1]
+	}
+
+	var out []string
+	inCode := false
+	for _, line := range lines {
+		if fencePattern.MatchString(line) {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			if line != "" && !isCode(line) {
+				line = "    " + line
+			}
+			out = append(out, line)
+			continue
+		}
+		if rulePattern.MatchString(line) {
+			continue
+		}
+		if isCode(line) {
+			out = append(out, line)
+			continue
+		}
+		line = headingPattern.ReplaceAllString(line, "")
+		line = boldPattern.ReplaceAllString(line, "$1")
+		out = append(out, line)
+	}
+	// A dropped rule or fence can leave blank lines behind
+	message = strings.Join(out, "\n")
+	for strings.Contains(message, "\n\n\n") {
+		message = strings.ReplaceAll(message, "\n\n\n", "\n\n")
+	}
+	return strings.TrimSpace(message)
+}
+
+// capSubject cuts a subject longer than limit at the last word that fits, returning
+// the rest to start the body with
+func capSubject(subject string, limit int) (string, string) {
+	if utf8.RuneCountInString(subject) <= limit {
+		return subject, ""
+	}
+	runes := []rune(subject)
+	cut := strings.LastIndex(string(runes[:limit]), " ")
+	if cut <= 0 {
+		// A single word too long to cut; leave it to the lint rules
+		return subject, ""
+	}
+	head := strings.TrimRight(subject[:cut], " ,;:-")
+	rest := strings.TrimSpace(subject[cut:])
+	return head + ellipsis, ellipsis + rest
+}
+
+// isTrailerBlock reports whether every line of paragraph is a trailer
+func isTrailerBlock(paragraph string) bool {
+	for _, line := range strings.Split(strings.TrimSpace(paragraph), "\n") {
+		if !trailerPattern.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// block is a list item or run of text in a paragraph, with the lines it was written on
+type block struct {
+	indent string
+	marker string
+	lines  []string
+	code   bool
+}
+
+// formatParagraph normalizes the bullets of a paragraph and rewraps the items and
+// text that have a line wider than the body width
+func formatParagraph(paragraph string, opts Options) string {
+	var blocks []*block
+	var current *block
+	for _, line := range strings.Split(paragraph, "\n") {
+		if m := bulletPattern.FindStringSubmatch(line); m != nil {
+			marker := m[2]
+			if opts.Bullet != "" && !isNumbered(marker) {
+				marker = opts.Bullet
+			}
+			current = &block{indent: m[1], marker: marker, lines: []string{m[3]}}
+			blocks = append(blocks, current)
+			continue
+		}
+		// Lines indented past the text they follow by four spaces are code
+		base := ""
+		if current != nil && current.marker != "" {
+			base = strings.Repeat(" ", len(current.indent)+utf8.RuneCountInString(current.marker)+1)
+		}
+		code := isCode(strings.TrimPrefix(line, base))
+		if !code {
+			line = strings.TrimSpace(line)
+		}
+		if current == nil || current.code != code {
+			current = &block{code: code}
+			blocks = append(blocks, current)
+		}
+		current.lines = append(current.lines, line)
+	}
+
+	var out []string
+	for _, b := range blocks {
+		out = append(out, b.render(opts.BodyWidth)...)
+	}
+	return strings.Join(out, "\n")
+}
+
+// isCode reports whether line is indented like a code block
+func isCode(line string) bool {
+	return strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")
+}
+
+func isNumbered(marker string) bool {
+	return marker[0] >= '0' && marker[0] <= '9'
+}
+
+// render returns the lines of a block: as written when they fit in width, rewrapped
+// with the text of continuation lines under the first line's when they don't
+func (b *block) render(width int) []string {
+	prefix := b.indent
+	if b.marker != "" {
+		prefix += b.marker + " "
+	}
+	if b.code {
+		return b.lines
+	}
+
+	fits := true
+	for i, line := range b.lines {
+		if i == 0 {
+			line = prefix + line
+		}
+		if width > 0 && utf8.RuneCountInString(line) > width {
+			fits = false
+		}
+	}
+	if fits {
+		lines := append([]string{prefix + b.lines[0]}, b.lines[1:]...)
+		if b.marker != "" {
+			hanging := strings.Repeat(" ", utf8.RuneCountInString(prefix))
+			for i := 1; i < len(lines); i++ {
+				lines[i] = hanging + lines[i]
+			}
+		}
+		return lines
+	}
+	return wrap(strings.Fields(strings.Join(b.lines, " ")), prefix, width)
+}
+
+// wrap fills lines up to width with words, the first line starting with prefix and
+// the rest indented under its text. A word wider than the line, such as a URL, gets
+// a line of its own.
+func wrap(words []string, prefix string, width int) []string {
+	hanging := strings.Repeat(" ", utf8.RuneCountInString(prefix))
+	var lines []string
+	line, length := prefix, utf8.RuneCountInString(prefix)
+	empty := true
+	for _, word := range words {
+		wordLength := utf8.RuneCountInString(word)
+		if !empty && length+1+wordLength > width {
+			lines = append(lines, line)
+			line, length, empty = hanging, len(hanging), true
+		}
+		if !empty {
+			line += " "
+			length++
+		}
+		line += word
+		length += wordLength
+		empty = false
+	}
+	return append(lines, line)
+}
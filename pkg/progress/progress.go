@@ -0,0 +1,287 @@
+// Package progress draws spinners and progress bars on stderr. A single status line is
+// shared by the whole process, so concurrent callers and nested stages ("Generating
+// commit message › Calling Solar LLM") never fight over the terminal. Nothing is
+// drawn when stderr is not a terminal or progress output is disabled (--quiet, or
+// machine-readable output).
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hunkim/sgit/pkg/logging"
+	"golang.org/x/term"
+)
+
+// stageSeparator joins nested stage labels on the status line
+const stageSeparator = " › "
+
+var (
+	unicodeFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	asciiFrames   = []string{"|", "/", "-", "\\"}
+)
+
+// line is the status line shared by all spinners and bars
+type line struct {
+	mu       sync.Mutex
+	out      io.Writer
+	enabled  bool
+	isTTY    bool
+	frames   []string
+	delay    time.Duration
+	stages   []*stage
+	bar      string
+	frame    int
+	width    int // columns of the last drawn text, cleared before redrawing
+	stopTick chan struct{}
+}
+
+// stage is one running step; stages started while another runs are shown nested
+type stage struct {
+	label   string
+	started time.Time
+}
+
+var status = newLine(os.Stderr)
+
+func newLine(out *os.File) *line {
+	frames := unicodeFrames
+	termType := os.Getenv("TERM")
+	if !(strings.Contains(termType, "xterm") || strings.Contains(termType, "screen") ||
+		termType == "" || strings.Contains(termType, "color")) {
+		// Fall back to ASCII for older/simpler terminals
+		frames = asciiFrames
+	}
+	return &line{
+		out:     out,
+		enabled: true,
+		isTTY:   term.IsTerminal(int(out.Fd())),
+		frames:  frames,
+		delay:   100 * time.Millisecond,
+	}
+}
+
+// SetEnabled turns progress output on or off, e.g. off for --quiet or JSON output.
+// Disabling clears anything currently drawn.
+func SetEnabled(enabled bool) {
+	status.mu.Lock()
+	defer status.mu.Unlock()
+	if !enabled {
+		status.clearLocked()
+	}
+	status.enabled = enabled
+	if enabled {
+		status.drawLocked()
+	}
+}
+
+// Enabled reports whether progress is drawn: it is enabled and stderr is a terminal
+func Enabled() bool {
+	status.mu.Lock()
+	defer status.mu.Unlock()
+	return status.active()
+}
+
+// Stage is a running spinner step started with Begin
+type Stage struct {
+	s    *stage
+	once sync.Once
+}
+
+// Begin shows a spinner with label. While another stage is running the label is shown
+// after it as a nested step. Call End when the step is done.
+func Begin(label string) *Stage {
+	s := &stage{label: label, started: time.Now()}
+	status.mu.Lock()
+	defer status.mu.Unlock()
+	status.stages = append(status.stages, s)
+	status.startTickLocked()
+	status.drawLocked()
+	return &Stage{s: s}
+}
+
+// Update changes the label of a running stage, e.g. to show what it is doing now
+func (st *Stage) Update(label string) {
+	status.mu.Lock()
+	defer status.mu.Unlock()
+	st.s.label = label
+	status.drawLocked()
+}
+
+// End removes the stage (and any stages nested in it) from the status line and logs
+// how long it took. It is safe to call more than once.
+func (st *Stage) End() {
+	st.once.Do(func() {
+		status.mu.Lock()
+		label := st.s.label
+		for i, s := range status.stages {
+			if s == st.s {
+				status.stages = status.stages[:i]
+				break
+			}
+		}
+		if len(status.stages) == 0 {
+			status.stopTickLocked()
+		}
+		status.clearLocked()
+		status.drawLocked()
+		status.mu.Unlock()
+
+		// Logged after unlocking, as writing the log clears the status line
+		logging.Info("stage done", "stage", label, "duration", time.Since(st.s.started))
+	})
+}
+
+// Clear erases the status line so regular output can be printed; it is redrawn on
+// the next spinner tick. Use Println/Printf to do both in one step.
+func Clear() {
+	status.mu.Lock()
+	defer status.mu.Unlock()
+	status.clearLocked()
+}
+
+// Printf prints to stdout without mixing the text into the status line
+func Printf(format string, args ...interface{}) {
+	status.mu.Lock()
+	defer status.mu.Unlock()
+	status.clearLocked()
+	fmt.Printf(format, args...)
+}
+
+// Println prints a line to stdout without mixing it into the status line
+func Println(args ...interface{}) {
+	status.mu.Lock()
+	defer status.mu.Unlock()
+	status.clearLocked()
+	fmt.Println(args...)
+}
+
+// Bar is a progress bar for a known number of items
+type Bar struct {
+	label string
+	total int
+	done  int
+}
+
+// NewBar shows a "label [=====>    ] done/total" bar on the status line
+func NewBar(label string, total int) *Bar {
+	b := &Bar{label: label, total: total}
+	status.mu.Lock()
+	defer status.mu.Unlock()
+	status.bar = b.render()
+	status.drawLocked()
+	return b
+}
+
+// Add marks n more items as done
+func (b *Bar) Add(n int) {
+	status.mu.Lock()
+	defer status.mu.Unlock()
+	b.done += n
+	status.bar = b.render()
+	status.drawLocked()
+}
+
+// Finish removes the bar from the status line
+func (b *Bar) Finish() {
+	status.mu.Lock()
+	defer status.mu.Unlock()
+	status.bar = ""
+	status.clearLocked()
+	status.drawLocked()
+}
+
+func (b *Bar) render() string {
+	if b.total <= 0 {
+		return ""
+	}
+	const width = 30
+	done := b.done
+	if done > b.total {
+		done = b.total
+	}
+	filled := width * done / b.total
+	bar := strings.Repeat("=", filled)
+	if filled < width {
+		bar += ">" + strings.Repeat(" ", width-filled-1)
+	}
+	return fmt.Sprintf("%s [%s] %d/%d", b.label, bar, b.done, b.total)
+}
+
+func (l *line) active() bool {
+	return l.enabled && l.isTTY
+}
+
+func (l *line) text() string {
+	if l.bar != "" {
+		return l.bar
+	}
+	if len(l.stages) == 0 {
+		return ""
+	}
+	labels := make([]string, len(l.stages))
+	for i, s := range l.stages {
+		labels[i] = s.label
+	}
+	return l.frames[l.frame%len(l.frames)] + " " + strings.Join(labels, stageSeparator)
+}
+
+func (l *line) drawLocked() {
+	if !l.active() {
+		return
+	}
+	text := l.text()
+	if text == "" {
+		return
+	}
+	width := utf8.RuneCountInString(text)
+	padding := ""
+	if l.width > width {
+		padding = strings.Repeat(" ", l.width-width)
+	}
+	fmt.Fprint(l.out, "\r"+text+padding)
+	l.width = width
+}
+
+func (l *line) clearLocked() {
+	if !l.active() || l.width == 0 {
+		return
+	}
+	fmt.Fprint(l.out, "\r"+strings.Repeat(" ", l.width)+"\r")
+	l.width = 0
+}
+
+func (l *line) startTickLocked() {
+	if l.stopTick != nil {
+		return
+	}
+	stop := make(chan struct{})
+	l.stopTick = stop
+	go func() {
+		ticker := time.NewTicker(l.delay)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				l.mu.Lock()
+				l.frame++
+				l.drawLocked()
+				l.mu.Unlock()
+			}
+		}
+	}()
+}
+
+func (l *line) stopTickLocked() {
+	if l.stopTick != nil {
+		close(l.stopTick)
+		l.stopTick = nil
+	}
+}
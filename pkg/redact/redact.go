@@ -0,0 +1,125 @@
+// Package redact masks sensitive content (email addresses, IP addresses, credentials
+// and configurable patterns) in text before it leaves the machine.
+package redact
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/secrets"
+)
+
+// Names of the built-in rules, as they appear in a Report
+const (
+	Email      = "email"
+	IPAddress  = "IP address"
+	Credential = "credential"
+	Custom     = "custom pattern"
+)
+
+// Rule masks one kind of sensitive content. Valid, when set, confirms a match before
+// it is masked.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Valid   func(match string) bool
+}
+
+var (
+	emailPattern = regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)
+	ipv4Pattern  = regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`)
+	// ipv6Pattern finds candidates; net.ParseIP decides
+	ipv6Pattern = regexp.MustCompile(`\b[0-9A-Fa-f]{0,4}(?::[0-9A-Fa-f]{0,4}){2,7}\b`)
+)
+
+// DefaultRules masks the credentials the secret scanner knows (tokens, keys, passwords
+// in assignments, URLs with passwords), then email addresses and IPv4 and IPv6 addresses
+func DefaultRules() []Rule {
+	var rules []Rule
+	for _, rule := range secrets.DefaultRules {
+		rules = append(rules, Rule{Name: Credential, Pattern: rule.Pattern})
+	}
+	return append(rules,
+		Rule{Name: Email, Pattern: emailPattern},
+		Rule{Name: IPAddress, Pattern: ipv4Pattern},
+		Rule{Name: IPAddress, Pattern: ipv6Pattern, Valid: validIPv6},
+	)
+}
+
+// validIPv6 confirms an ipv6Pattern match. net.ParseIP alone accepts a bare "::",
+// which is also the scope operator in C++, Rust, Ruby and PHP (std::vector), so a
+// match must contain at least one hex digit as well.
+func validIPv6(match string) bool {
+	return strings.ContainsAny(match, "0123456789abcdefABCDEF") && net.ParseIP(match) != nil
+}
+
+// Report counts what was masked, by rule name
+type Report map[string]int
+
+// Total returns the number of masked matches
+func (r Report) Total() int {
+	total := 0
+	for _, count := range r {
+		total += count
+	}
+	return total
+}
+
+// String describes the report, e.g. "2 emails, 1 IP address"
+func (r Report) String() string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		if r[name] == 1 {
+			parts = append(parts, "1 "+name)
+		} else if strings.HasSuffix(name, "s") {
+			parts = append(parts, fmt.Sprintf("%d %ses", r[name], name))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d %ss", r[name], name))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Redactor masks the matches of its rules
+type Redactor struct {
+	rules []Rule
+}
+
+// New returns a redactor with the default rules plus patterns, regular expressions
+// for content such as customer IDs
+func New(patterns []string) (*Redactor, error) {
+	rules := DefaultRules()
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern '%s': %v", pattern, err)
+		}
+		rules = append(rules, Rule{Name: Custom, Pattern: re})
+	}
+	return &Redactor{rules: rules}, nil
+}
+
+// Redact returns text with every match replaced by a placeholder naming what was
+// there, e.g. [REDACTED EMAIL], and a report of what was masked
+func (r *Redactor) Redact(text string) (string, Report) {
+	report := make(Report)
+	for _, rule := range r.rules {
+		placeholder := "[REDACTED " + strings.ToUpper(rule.Name) + "]"
+		text = rule.Pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if match == placeholder || rule.Valid != nil && !rule.Valid(match) {
+				return match
+			}
+			report[rule.Name]++
+			return placeholder
+		})
+	}
+	return text, report
+}
@@ -0,0 +1,278 @@
+// Package render turns the markdown the model writes into the output format asked for:
+// the markdown itself, plain text for pipes, styled text for a terminal, or an HTML
+// document for reports. Rendering works line by line, so streamed output can be shown
+// as it arrives.
+package render
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Formats
+const (
+	Markdown = "markdown"
+	Plain    = "plain"
+	ANSI     = "ansi"
+	HTML     = "html"
+)
+
+// Formats lists the supported formats
+func Formats() []string {
+	return []string{Markdown, Plain, ANSI, HTML}
+}
+
+// ANSI styles
+const (
+	reset     = "\x1b[0m"
+	bold      = "\x1b[1m"
+	dim       = "\x1b[2m"
+	italic    = "\x1b[3m"
+	underline = "\x1b[4m"
+	cyan      = "\x1b[36m"
+	magenta   = "\x1b[35m"
+)
+
+var (
+	headingPattern  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletPattern   = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	numberedPattern = regexp.MustCompile(`^(\s*)(\d+)[.)]\s+(.*)$`)
+	rulePattern     = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	quotePattern    = regexp.MustCompile(`^\s*>\s?(.*)$`)
+
+	codePattern   = regexp.MustCompile("`([^`]+)`")
+	boldPattern   = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicPattern = regexp.MustCompile(`(^|[^*\w])\*([^*\s][^*]*)\*`)
+	linkPattern   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// Renderer renders markdown written in chunks
+type Renderer struct {
+	format  string
+	partial string // the unfinished last line
+	inCode  bool
+
+	// HTML is built up and written as one document by Flush
+	document  strings.Builder
+	list      string // "ul" or "ol" while a list is open
+	paragraph []string
+}
+
+// New returns a renderer for a format
+func New(format string) (*Renderer, error) {
+	for _, known := range Formats() {
+		if format == known {
+			return &Renderer{format: format}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown format '%s' (available: %s)", format, strings.Join(Formats(), ", "))
+}
+
+// Format returns the renderer's format
+func (r *Renderer) Format() string {
+	return r.format
+}
+
+// Write takes the next chunk of markdown and returns what can be shown of it so far:
+// the rendered complete lines. HTML is held back until Flush.
+func (r *Renderer) Write(chunk string) string {
+	if r.format == Markdown {
+		return chunk
+	}
+	text := r.partial + chunk
+	end := strings.LastIndex(text, "\n")
+	if end == -1 {
+		r.partial = text
+		return ""
+	}
+	r.partial = text[end+1:]
+
+	var b strings.Builder
+	for _, line := range strings.Split(text[:end], "\n") {
+		b.WriteString(r.line(line))
+	}
+	return b.String()
+}
+
+// Flush renders what is left, ending an HTML document
+func (r *Renderer) Flush() string {
+	if r.format == Markdown {
+		return ""
+	}
+	var b strings.Builder
+	if r.partial != "" {
+		b.WriteString(r.line(r.partial))
+		r.partial = ""
+	}
+	if r.format != HTML {
+		return strings.TrimSuffix(b.String(), "\n")
+	}
+
+	r.closeBlocks()
+	if r.inCode {
+		r.document.WriteString("</code></pre>\n")
+		r.inCode = false
+	}
+	return "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>sgit report</title>\n" +
+		"<style>body{font-family:sans-serif;max-width:50em;margin:2em auto;line-height:1.5}pre{background:#f4f4f4;padding:1em;overflow-x:auto}code{background:#f4f4f4}</style>\n" +
+		"</head>\n<body>\n" + r.document.String() + "</body>\n</html>"
+}
+
+// Render renders a whole markdown text
+func (r *Renderer) Render(text string) string {
+	return r.Write(text) + r.Flush()
+}
+
+// line renders one complete line
+func (r *Renderer) line(line string) string {
+	line = strings.TrimRight(line, "\r")
+	if strings.HasPrefix(strings.TrimSpace(line), "```") {
+		r.inCode = !r.inCode
+		if r.format == HTML {
+			r.closeBlocks()
+			if r.inCode {
+				r.document.WriteString("<pre><code>")
+			} else {
+				r.document.WriteString("</code></pre>\n")
+			}
+		}
+		return ""
+	}
+
+	switch r.format {
+	case ANSI:
+		return r.ansiLine(line) + "\n"
+	case HTML:
+		r.htmlLine(line)
+		return ""
+	}
+	return r.plainLine(line) + "\n"
+}
+
+func (r *Renderer) plainLine(line string) string {
+	if r.inCode {
+		return "    " + line
+	}
+	if match := headingPattern.FindStringSubmatch(line); match != nil {
+		return inlinePlain(match[2])
+	}
+	if rulePattern.MatchString(line) {
+		return strings.Repeat("-", 40)
+	}
+	if match := bulletPattern.FindStringSubmatch(line); match != nil {
+		return match[1] + "- " + inlinePlain(match[2])
+	}
+	if match := quotePattern.FindStringSubmatch(line); match != nil {
+		return "  " + inlinePlain(match[1])
+	}
+	return inlinePlain(line)
+}
+
+func (r *Renderer) ansiLine(line string) string {
+	if r.inCode {
+		return dim + "    " + line + reset
+	}
+	if match := headingPattern.FindStringSubmatch(line); match != nil {
+		style := bold
+		if len(match[1]) <= 2 {
+			style = bold + underline + magenta
+		}
+		return style + inlinePlain(match[2]) + reset
+	}
+	if rulePattern.MatchString(line) {
+		return dim + strings.Repeat("─", 40) + reset
+	}
+	if match := bulletPattern.FindStringSubmatch(line); match != nil {
+		return match[1] + "• " + inlineANSI(match[2])
+	}
+	if match := numberedPattern.FindStringSubmatch(line); match != nil {
+		return match[1] + bold + match[2] + "." + reset + " " + inlineANSI(match[3])
+	}
+	if match := quotePattern.FindStringSubmatch(line); match != nil {
+		return dim + "│ " + reset + italic + inlineANSI(match[1]) + reset
+	}
+	return inlineANSI(line)
+}
+
+func (r *Renderer) htmlLine(line string) {
+	if r.inCode {
+		r.document.WriteString(html.EscapeString(line) + "\n")
+		return
+	}
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case trimmed == "":
+		r.closeBlocks()
+	case headingPattern.MatchString(line):
+		r.closeBlocks()
+		match := headingPattern.FindStringSubmatch(line)
+		level := len(match[1])
+		fmt.Fprintf(&r.document, "<h%d>%s</h%d>\n", level, inlineHTML(match[2]), level)
+	case rulePattern.MatchString(line):
+		r.closeBlocks()
+		r.document.WriteString("<hr>\n")
+	case bulletPattern.MatchString(line):
+		r.openList("ul")
+		fmt.Fprintf(&r.document, "<li>%s</li>\n", inlineHTML(bulletPattern.FindStringSubmatch(line)[2]))
+	case numberedPattern.MatchString(line):
+		r.openList("ol")
+		fmt.Fprintf(&r.document, "<li>%s</li>\n", inlineHTML(numberedPattern.FindStringSubmatch(line)[3]))
+	case quotePattern.MatchString(line):
+		r.closeBlocks()
+		fmt.Fprintf(&r.document, "<blockquote>%s</blockquote>\n", inlineHTML(quotePattern.FindStringSubmatch(line)[1]))
+	default:
+		if r.list != "" {
+			r.closeBlocks()
+		}
+		r.paragraph = append(r.paragraph, inlineHTML(trimmed))
+	}
+}
+
+// openList starts a list of the kind, ending the open paragraph or another list
+func (r *Renderer) openList(kind string) {
+	if r.list == kind {
+		return
+	}
+	r.closeBlocks()
+	r.list = kind
+	r.document.WriteString("<" + kind + ">\n")
+}
+
+// closeBlocks ends the open paragraph and list
+func (r *Renderer) closeBlocks() {
+	if len(r.paragraph) > 0 {
+		r.document.WriteString("<p>" + strings.Join(r.paragraph, "\n") + "</p>\n")
+		r.paragraph = nil
+	}
+	if r.list != "" {
+		r.document.WriteString("</" + r.list + ">\n")
+		r.list = ""
+	}
+}
+
+// inlinePlain removes emphasis and code markers and spells out links
+func inlinePlain(text string) string {
+	text = codePattern.ReplaceAllString(text, "$1")
+	text = boldPattern.ReplaceAllString(text, "$1$2")
+	text = italicPattern.ReplaceAllString(text, "$1$2")
+	return linkPattern.ReplaceAllString(text, "$1 ($2)")
+}
+
+// inlineANSI styles emphasis, code and links. Links go first, since the escape codes
+// added for the others contain brackets.
+func inlineANSI(text string) string {
+	text = linkPattern.ReplaceAllString(text, "$1 ("+underline+"$2"+reset+")")
+	text = codePattern.ReplaceAllString(text, cyan+"$1"+reset)
+	text = boldPattern.ReplaceAllString(text, bold+"$1$2"+reset)
+	return italicPattern.ReplaceAllString(text, "$1"+italic+"$2"+reset)
+}
+
+func inlineHTML(text string) string {
+	text = html.EscapeString(text)
+	text = codePattern.ReplaceAllString(text, "<code>$1</code>")
+	text = boldPattern.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = italicPattern.ReplaceAllString(text, "$1<em>$2</em>")
+	return linkPattern.ReplaceAllString(text, `<a href="$2">$1</a>`)
+}
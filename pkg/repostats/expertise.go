@@ -0,0 +1,112 @@
+package repostats
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// expertiseHalfLife is how long it takes for a commit to count half as much toward its
+// author's expertise, so people who worked on the code recently rank above people who
+// did years ago
+const expertiseHalfLife = 365 * 24 * time.Hour
+
+// BlameShare is the number of lines of the current code an author last changed, as
+// git blame reports it
+type BlameShare struct {
+	Name  string
+	Email string
+	Lines int
+}
+
+// Expert is an author's expertise in a part of the repository
+type Expert struct {
+	Name    string
+	Email   string
+	Commits int
+	Added   int
+	Removed int
+	// Files counts the distinct files they changed there
+	Files int
+	// Lines counts the lines of the current code they last changed
+	Lines int
+	First time.Time
+	Last  time.Time
+	// Score is between 0 and 1: their share of the current lines and their share of
+	// the commits weighted by age, half each
+	Score float64
+}
+
+// Expertise ranks the authors of commits and blamed lines by expertise, the highest
+// score first. A commit counts half as much for every expertiseHalfLife before now.
+// Without blamed lines, e.g. when the code was deleted, the score is the weighted
+// commit share alone.
+func Expertise(commits []Commit, blamed []BlameShare, now time.Time) []Expert {
+	experts := make(map[string]*Expert)
+	weights := make(map[string]float64)
+	files := make(map[string]map[string]bool)
+	expert := func(name, email string) (string, *Expert) {
+		key := authorKey(Commit{Author: name, Email: email})
+		e, ok := experts[key]
+		if !ok {
+			e = &Expert{Name: name, Email: email}
+			experts[key] = e
+			files[key] = make(map[string]bool)
+		}
+		return key, e
+	}
+
+	var totalWeight float64
+	for _, commit := range commits {
+		key, e := expert(commit.Author, commit.Email)
+		e.Commits++
+		if e.First.IsZero() || commit.Time.Before(e.First) {
+			e.First = commit.Time
+		}
+		if commit.Time.After(e.Last) {
+			e.Last = commit.Time
+		}
+		for _, change := range commit.Files {
+			e.Added += change.Added
+			e.Removed += change.Removed
+			files[key][change.Path] = true
+		}
+
+		age := max(now.Sub(commit.Time), 0)
+		weight := math.Pow(0.5, float64(age)/float64(expertiseHalfLife))
+		weights[key] += weight
+		totalWeight += weight
+	}
+
+	totalLines := 0
+	for _, share := range blamed {
+		_, e := expert(share.Name, share.Email)
+		e.Lines += share.Lines
+		totalLines += share.Lines
+	}
+
+	ranked := make([]Expert, 0, len(experts))
+	for key, e := range experts {
+		e.Files = len(files[key])
+		var commitShare, lineShare float64
+		if totalWeight > 0 {
+			commitShare = weights[key] / totalWeight
+		}
+		if totalLines > 0 {
+			lineShare = float64(e.Lines) / float64(totalLines)
+			e.Score = (commitShare + lineShare) / 2
+		} else {
+			e.Score = commitShare
+		}
+		ranked = append(ranked, *e)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	return ranked
+}
@@ -0,0 +1,346 @@
+// Package repostats computes repository analytics from git history: commit
+// frequency, file churn, contributors and the bus factor. Everything is counted from
+// the log, so the numbers are exact and the same on every run.
+package repostats
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogFormat is the git log --format that ParseLog reads, used with --numstat
+const LogFormat = "%x1e%H%x1f%aN%x1f%aE%x1f%aI"
+
+// ownerShare is the part of a file's changed lines that makes an author one of its
+// owners for the bus factor
+const ownerShare = 0.25
+
+// Commit is a commit and the files it changed
+type Commit struct {
+	Hash   string
+	Author string
+	Email  string
+	Time   time.Time
+	Files  []FileChange
+}
+
+// FileChange is the lines a commit added to and removed from a file
+type FileChange struct {
+	Path    string
+	Added   int
+	Removed int
+	// Binary files have no line counts
+	Binary bool
+}
+
+// Stats are the analytics of a set of commits
+type Stats struct {
+	Commits    int
+	First      time.Time
+	Last       time.Time
+	ActiveDays int
+	Added      int
+	Removed    int
+	// FilesTouched counts the distinct files changed
+	FilesTouched int
+
+	// Weeks counts the commits of each week from the first to the last commit,
+	// weeks starting on Monday
+	Weeks []WeekCount
+	// Weekdays counts the commits of each day of the week, indexed by time.Weekday
+	Weekdays [7]int
+	// Hours counts the commits of each hour of the day, in the authors' time zones
+	Hours [24]int
+
+	// Hotspots are the changed files, the most often changed first
+	Hotspots []FileChurn
+	// Contributors are the authors, the most commits first
+	Contributors []Contributor
+
+	// BusFactor is how few authors would have to leave for more than half of the
+	// files to have no owner left (see Compute)
+	BusFactor int
+	// BusFactorAuthors are those authors, in the order they were counted
+	BusFactorAuthors []string
+	// OwnedFiles counts the files the bus factor is computed over
+	OwnedFiles int
+	// SingleAuthorFiles counts the files only one author has changed
+	SingleAuthorFiles int
+}
+
+// WeekCount is the number of commits in the week starting on Start
+type WeekCount struct {
+	Start   time.Time
+	Commits int
+}
+
+// FileChurn is how much a file changed
+type FileChurn struct {
+	Path    string
+	Commits int
+	Added   int
+	Removed int
+	Authors int
+}
+
+// Lines is the number of lines added and removed
+func (f FileChurn) Lines() int {
+	return f.Added + f.Removed
+}
+
+// Contributor is an author's share of the commits
+type Contributor struct {
+	Name    string
+	Email   string
+	Commits int
+	Added   int
+	Removed int
+	Files   int
+	First   time.Time
+	Last    time.Time
+}
+
+// ParseLog reads the commits from git log --numstat --format=LogFormat output.
+// Renamed files should be turned off with --no-renames, so paths are plain.
+func ParseLog(output string) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(output, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		fields := strings.Split(lines[0], "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		when, err := time.Parse(time.RFC3339, strings.TrimSpace(fields[3]))
+		if err != nil {
+			continue
+		}
+		commit := Commit{Hash: fields[0], Author: fields[1], Email: fields[2], Time: when}
+		for _, line := range lines[1:] {
+			parts := strings.SplitN(line, "\t", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			change := FileChange{Path: parts[2]}
+			if parts[0] == "-" {
+				change.Binary = true
+			} else {
+				change.Added, _ = strconv.Atoi(parts[0])
+				change.Removed, _ = strconv.Atoi(parts[1])
+			}
+			commit.Files = append(commit.Files, change)
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+// Compute counts the analytics of commits. The bus factor is computed over the
+// files in files, typically the ones that still exist, or over every changed file
+// when files is nil.
+//
+// An author owns a file when they changed at least a quarter of its lines, or the
+// most of anyone. The bus factor is found by repeatedly taking away the author who
+// owns the most files that still have an owner, until more than half of the files
+// have none left.
+func Compute(commits []Commit, files []string) Stats {
+	stats := Stats{Commits: len(commits)}
+	if len(commits) == 0 {
+		return stats
+	}
+
+	type fileState struct {
+		churn   FileChurn
+		authors map[string]int // lines changed per author
+	}
+	fileStates := make(map[string]*fileState)
+	contributors := make(map[string]*Contributor)
+	contributorFiles := make(map[string]map[string]bool)
+	days := make(map[string]bool)
+	weeks := make(map[time.Time]int)
+
+	stats.First, stats.Last = commits[0].Time, commits[0].Time
+	for _, commit := range commits {
+		if commit.Time.Before(stats.First) {
+			stats.First = commit.Time
+		}
+		if commit.Time.After(stats.Last) {
+			stats.Last = commit.Time
+		}
+		days[commit.Time.Format("2006-01-02")] = true
+		weeks[weekStart(commit.Time)]++
+		stats.Weekdays[commit.Time.Weekday()]++
+		stats.Hours[commit.Time.Hour()]++
+
+		key := authorKey(commit)
+		contributor, ok := contributors[key]
+		if !ok {
+			contributor = &Contributor{Name: commit.Author, Email: commit.Email, First: commit.Time, Last: commit.Time}
+			contributors[key] = contributor
+			contributorFiles[key] = make(map[string]bool)
+		}
+		contributor.Commits++
+		if commit.Time.Before(contributor.First) {
+			contributor.First = commit.Time
+		}
+		if commit.Time.After(contributor.Last) {
+			contributor.Last = commit.Time
+		}
+
+		for _, change := range commit.Files {
+			state, ok := fileStates[change.Path]
+			if !ok {
+				state = &fileState{churn: FileChurn{Path: change.Path}, authors: make(map[string]int)}
+				fileStates[change.Path] = state
+			}
+			state.churn.Commits++
+			state.churn.Added += change.Added
+			state.churn.Removed += change.Removed
+			// A binary change still counts as work on the file
+			state.authors[key] += max(change.Added+change.Removed, 1)
+
+			contributor.Added += change.Added
+			contributor.Removed += change.Removed
+			contributorFiles[key][change.Path] = true
+			stats.Added += change.Added
+			stats.Removed += change.Removed
+		}
+	}
+	stats.ActiveDays = len(days)
+	stats.FilesTouched = len(fileStates)
+
+	for week := weekStart(stats.First); !week.After(stats.Last); week = week.AddDate(0, 0, 7) {
+		stats.Weeks = append(stats.Weeks, WeekCount{Start: week, Commits: weeks[week]})
+	}
+
+	for _, state := range fileStates {
+		state.churn.Authors = len(state.authors)
+		stats.Hotspots = append(stats.Hotspots, state.churn)
+	}
+	sort.Slice(stats.Hotspots, func(i, j int) bool {
+		a, b := stats.Hotspots[i], stats.Hotspots[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		if a.Lines() != b.Lines() {
+			return a.Lines() > b.Lines()
+		}
+		return a.Path < b.Path
+	})
+
+	for key, contributor := range contributors {
+		contributor.Files = len(contributorFiles[key])
+		stats.Contributors = append(stats.Contributors, *contributor)
+	}
+	sort.Slice(stats.Contributors, func(i, j int) bool {
+		a, b := stats.Contributors[i], stats.Contributors[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return a.Name < b.Name
+	})
+
+	// Owners of each file the bus factor is computed over
+	owners := make(map[string][]string)
+	names := make(map[string]string)
+	for key, contributor := range contributors {
+		names[key] = contributor.Name
+	}
+	scope := files
+	if scope == nil {
+		for path := range fileStates {
+			scope = append(scope, path)
+		}
+	}
+	for _, path := range scope {
+		state, ok := fileStates[path]
+		if !ok {
+			continue
+		}
+		owners[path] = fileOwners(state.authors)
+		if len(state.authors) == 1 {
+			stats.SingleAuthorFiles++
+		}
+	}
+	stats.OwnedFiles = len(owners)
+	stats.BusFactorAuthors = busFactor(owners)
+	for i, key := range stats.BusFactorAuthors {
+		stats.BusFactorAuthors[i] = names[key]
+	}
+	stats.BusFactor = len(stats.BusFactorAuthors)
+	return stats
+}
+
+// fileOwners returns the authors who changed at least ownerShare of a file's lines,
+// or the one who changed the most
+func fileOwners(lines map[string]int) []string {
+	total, top := 0, ""
+	for author, n := range lines {
+		total += n
+		if top == "" || n > lines[top] || n == lines[top] && author < top {
+			top = author
+		}
+	}
+	owners := []string{top}
+	for author, n := range lines {
+		if author != top && float64(n) >= ownerShare*float64(total) {
+			owners = append(owners, author)
+		}
+	}
+	return owners
+}
+
+// busFactor takes away the author owning the most files that still have an owner
+// until more than half of the files have none, and returns the authors taken away
+func busFactor(owners map[string][]string) []string {
+	if len(owners) == 0 {
+		return nil
+	}
+	gone := make(map[string]bool)
+	var removed []string
+	for {
+		orphaned := 0
+		owned := make(map[string]int)
+		for _, fileOwners := range owners {
+			left := 0
+			for _, owner := range fileOwners {
+				if !gone[owner] {
+					left++
+					owned[owner]++
+				}
+			}
+			if left == 0 {
+				orphaned++
+			}
+		}
+		if orphaned*2 > len(owners) || len(owned) == 0 {
+			return removed
+		}
+
+		next := ""
+		for author, n := range owned {
+			if next == "" || n > owned[next] || n == owned[next] && author < next {
+				next = author
+			}
+		}
+		gone[next] = true
+		removed = append(removed, next)
+	}
+}
+
+// authorKey identifies an author across commits, by email when there is one
+func authorKey(commit Commit) string {
+	if commit.Email != "" {
+		return strings.ToLower(commit.Email)
+	}
+	return commit.Author
+}
+
+// weekStart returns midnight of the Monday starting t's week, in UTC so weeks
+// from different time zones line up
+func weekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
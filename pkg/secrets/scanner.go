@@ -0,0 +1,148 @@
+package secrets
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Rule describes a single heuristic used to detect a secret
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// Finding represents a potential secret found in a file or diff
+type Finding struct {
+	File  string
+	Line  int
+	Rule  string
+	Match string
+	Text  string
+}
+
+// DefaultRules contains the regex heuristics used to detect common credentials
+var DefaultRules = []Rule{
+	{Name: "private-key", Pattern: regexp.MustCompile(`-----BEGIN ((RSA|EC|DSA|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`)},
+	{Name: "aws-access-key", Pattern: regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "aws-secret-key", Pattern: regexp.MustCompile(`(?i)aws.{0,20}(secret|private).{0,20}['"][0-9a-zA-Z/+]{40}['"]`)},
+	{Name: "github-token", Pattern: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{Name: "gitlab-token", Pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9\-_]{20,}\b`)},
+	{Name: "slack-token", Pattern: regexp.MustCompile(`\bxox[abposr]-[0-9A-Za-z-]{10,}\b`)},
+	{Name: "google-api-key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z\-_]{35}\b`)},
+	{Name: "stripe-key", Pattern: regexp.MustCompile(`\b[sr]k_live_[0-9a-zA-Z]{24,}\b`)},
+	{Name: "openai-key", Pattern: regexp.MustCompile(`\bsk-(proj-)?[A-Za-z0-9_\-]{32,}\b`)},
+	{Name: "upstage-key", Pattern: regexp.MustCompile(`\bup_[A-Za-z0-9]{24,}\b`)},
+	{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\b`)},
+	{Name: "url-credentials", Pattern: regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@]+:[^/\s:@]{3,}@[^\s]+`)},
+	{Name: "password-assignment", Pattern: regexp.MustCompile(`(?i)\b(password|passwd|pwd|secret|api[_-]?key|access[_-]?token|auth[_-]?token|client[_-]?secret)\b["']?\s*[:=]\s*["'][^"'\s]{8,}["']`)},
+}
+
+// hunkHeader matches the new-file line range of a unified diff hunk header
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// ScanText scans the full content of a file for potential secrets
+func ScanText(file, content string) []Finding {
+	var findings []Finding
+	lineNum := 0
+	eachLine(content, func(line string) {
+		lineNum++
+		findings = append(findings, scanLine(file, lineNum, line)...)
+	})
+
+	return findings
+}
+
+// ScanDiff scans the added lines of a unified diff for potential secrets.
+// Line numbers refer to the new version of each file.
+func ScanDiff(diff string) []Finding {
+	var findings []Finding
+	var currentFile string
+	lineNum := 0
+
+	eachLine(diff, func(line string) {
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			currentFile = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+			return
+		case strings.HasPrefix(line, "--- "):
+			return
+		case strings.HasPrefix(line, "@@"):
+			if m := hunkHeader.FindStringSubmatch(line); m != nil {
+				start, _ := strconv.Atoi(m[1])
+				lineNum = start - 1
+			}
+			return
+		}
+
+		if currentFile == "" || currentFile == "/dev/null" {
+			return
+		}
+
+		switch {
+		case strings.HasPrefix(line, "+"):
+			lineNum++
+			findings = append(findings, scanLine(currentFile, lineNum, line[1:])...)
+		case strings.HasPrefix(line, "-"):
+			// Removed lines don't exist in the new file
+		default:
+			lineNum++
+		}
+	})
+
+	return findings
+}
+
+// eachLine calls fn for every line of text without a trailing newline. Unlike
+// bufio.Scanner it has no line length limit, so a long minified or generated line
+// can't end the scan early and let the lines after it through unchecked.
+func eachLine(text string, fn func(line string)) {
+	reader := bufio.NewReader(strings.NewReader(text))
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			fn(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func scanLine(file string, lineNum int, text string) []Finding {
+	var findings []Finding
+	for _, rule := range DefaultRules {
+		match := rule.Pattern.FindString(text)
+		if match == "" {
+			continue
+		}
+		findings = append(findings, Finding{
+			File:  file,
+			Line:  lineNum,
+			Rule:  rule.Name,
+			Match: match,
+			Text:  strings.TrimSpace(text),
+		})
+	}
+	return findings
+}
+
+// Mask hides most of a secret value while keeping enough to recognize it
+func Mask(value string) string {
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return value[:4] + strings.Repeat("*", len(value)-8) + value[len(value)-4:]
+}
+
+// String returns a human-readable, masked description of the finding
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d [%s] %s", f.File, f.Line, f.Rule, Mask(f.Match))
+}
+
+// MaskedText returns the offending line with the matched secret masked
+func (f Finding) MaskedText() string {
+	return strings.ReplaceAll(f.Text, f.Match, Mask(f.Match))
+}
@@ -0,0 +1,207 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Review verdicts, named like the GitHub review events they are posted as
+const (
+	VerdictApprove        = "APPROVE"
+	VerdictRequestChanges = "REQUEST_CHANGES"
+	VerdictComment        = "COMMENT"
+)
+
+// MaxLineComments bounds how many inline comments a pull request review keeps
+const MaxLineComments = 20
+
+// lineCommentPattern reads a comment line such as "- src/app.go:42: Check the error"
+var lineCommentPattern = regexp.MustCompile("^[-*•]?\\s*`?([^`\\s:][^`:]*)`?:(\\d+)(?:-\\d+)?`?\\s*:\\s*(.+)$")
+
+// hunkHeaderPattern reads where a hunk starts in the new version of a file
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// PullRequestReview is a review of a pull request with comments on specific lines
+type PullRequestReview struct {
+	// Verdict is VerdictApprove, VerdictRequestChanges or VerdictComment
+	Verdict string
+	// Summary is the overall assessment, the body of the review
+	Summary string
+	// Comments are on lines of the new version of the files, all within the diff
+	Comments []LineComment
+}
+
+// LineComment is a review comment on one line of the new version of a file
+type LineComment struct {
+	Path string
+	Line int
+	Body string
+}
+
+// ReviewPullRequest reviews a pull request's diff and returns an overall verdict and
+// summary with comments on specific lines. scope describes the pull request and
+// fileList the changed files; either may be empty. Comments the model places on
+// lines outside the diff, which can't be posted inline, are added to the summary.
+func (c *Client) ReviewPullRequest(ctx context.Context, diff, scope, fileList string) (PullRequestReview, error) {
+	// Lines are numbered so the model can cite them instead of counting from the hunk headers
+	sections := []BudgetSection{{Text: numberDiffLines(c.prepareDiff(diff)), Weight: 0.9}, {Text: fileList, Weight: 0.1}}
+	prompt, err := c.fitPrompt(sections, func(texts []string) (string, error) {
+		return fmt.Sprintf(`You are an experienced code reviewer reviewing a pull request. Comment on
+specific lines where there is something worth the author's attention.
+
+=== PULL REQUEST ===
+%s
+
+=== CHANGED FILES ===
+%s
+
+=== DIFF ===
+Each line of the new version of a file is prefixed with its line number; removed
+lines have none.
+%s
+
+Guidelines:
+- Comment on bugs, security and performance problems, missing error handling and
+  confusing code, not on style a formatter or linter would catch
+- Put each comment on the line with the problem, using the number it is prefixed with
+  and the path of the file as in the diff
+- Be specific and suggest the fix; skip praise and restating what the code does
+- At most %d comments, the most important ones; none is fine for a good change
+- The verdict is REQUEST_CHANGES when something must be fixed before merging, APPROVE
+  when the change is ready, and COMMENT otherwise
+
+Respond in exactly this format, keeping the labels and the verdict in English:
+VERDICT: <APPROVE|REQUEST_CHANGES|COMMENT>
+SUMMARY:
+<overall assessment in 1-4 sentences>
+COMMENTS:
+- <path>:<line>: <comment on one line>
+(or "none")`, scope, texts[1], texts[0], MaxLineComments), nil
+	})
+	if err != nil {
+		return PullRequestReview{}, err
+	}
+
+	response, err := c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+	if err != nil {
+		return PullRequestReview{}, err
+	}
+	return parsePullRequestReview(response, commentableLines(diff))
+}
+
+// parsePullRequestReview reads the verdict, summary and line comments from the review
+// response. Comments on lines that aren't in commentable go into the summary.
+func parsePullRequestReview(response string, commentable map[string]map[int]bool) (PullRequestReview, error) {
+	review := PullRequestReview{Verdict: VerdictComment}
+	var summary, elsewhere []string
+	section := ""
+	verdictFound := false
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(line)
+		label := strings.ToUpper(strings.Trim(trimmed, "*#: "))
+		switch {
+		case strings.HasPrefix(label, "VERDICT"):
+			verdict := strings.Trim(strings.TrimPrefix(label, "VERDICT"), ":*`. ")
+			switch verdict = strings.ReplaceAll(verdict, " ", "_"); verdict {
+			case VerdictApprove, VerdictRequestChanges, VerdictComment:
+				review.Verdict = verdict
+				verdictFound = true
+			}
+			continue
+		case label == "SUMMARY" || label == "COMMENTS":
+			section = label
+			continue
+		case strings.HasPrefix(label, "SUMMARY:"):
+			section = "SUMMARY"
+			trimmed = strings.Trim(trimmed[strings.Index(trimmed, ":")+1:], "* ")
+		}
+
+		switch section {
+		case "SUMMARY":
+			if trimmed != "" {
+				summary = append(summary, trimmed)
+			}
+		case "COMMENTS":
+			match := lineCommentPattern.FindStringSubmatch(strings.ReplaceAll(trimmed, "**", ""))
+			if match == nil {
+				continue
+			}
+			path := strings.TrimPrefix(strings.TrimSpace(match[1]), "b/")
+			lineNumber, _ := strconv.Atoi(match[2])
+			comment := LineComment{Path: path, Line: lineNumber, Body: strings.TrimSpace(match[3])}
+			if commentable[comment.Path][comment.Line] && len(review.Comments) < MaxLineComments {
+				review.Comments = append(review.Comments, comment)
+			} else {
+				elsewhere = append(elsewhere, fmt.Sprintf("- %s:%d: %s", comment.Path, comment.Line, comment.Body))
+			}
+		}
+	}
+	if !verdictFound && len(summary) == 0 {
+		return PullRequestReview{}, fmt.Errorf("could not read the review verdict from the response")
+	}
+
+	review.Summary = strings.Join(summary, " ")
+	if len(elsewhere) > 0 {
+		review.Summary += "\n\nOther notes:\n" + strings.Join(elsewhere, "\n")
+	}
+	return review, nil
+}
+
+// numberDiffLines prefixes the lines of the new version of each file in a unified
+// diff with their line numbers, leaving removed and header lines unnumbered
+func numberDiffLines(diff string) string {
+	var b strings.Builder
+	next := 0
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			inHunk = false
+		case strings.HasPrefix(line, "@@"):
+			if match := hunkHeaderPattern.FindStringSubmatch(line); match != nil {
+				next, _ = strconv.Atoi(match[1])
+				inHunk = true
+			}
+		case inHunk && (strings.HasPrefix(line, "+") || strings.HasPrefix(line, " ")):
+			fmt.Fprintf(&b, "%5d %s\n", next, line)
+			next++
+			continue
+		case inHunk && strings.HasPrefix(line, "-"):
+			fmt.Fprintf(&b, "%5s %s\n", "", line)
+			continue
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// commentableLines returns, for each file in a unified diff, the lines of its new
+// version that appear in the diff, which are the lines a review can comment on
+func commentableLines(diff string) map[string]map[int]bool {
+	lines := make(map[string]map[int]bool)
+	var current map[int]bool
+	next := 0
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current = nil
+		case current == nil && strings.HasPrefix(line, "+++ "):
+			if path := strings.TrimPrefix(line, "+++ "); path != "/dev/null" {
+				current = make(map[int]bool)
+				lines[strings.TrimPrefix(strings.Trim(path, `"`), "b/")] = current
+			}
+		case strings.HasPrefix(line, "@@"):
+			if match := hunkHeaderPattern.FindStringSubmatch(line); match != nil {
+				next, _ = strconv.Atoi(match[1])
+			}
+		case current != nil && (strings.HasPrefix(line, "+") || strings.HasPrefix(line, " ")):
+			current[next] = true
+			next++
+		}
+	}
+	return lines
+}
@@ -0,0 +1,94 @@
+package solar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// backend adapts chat requests to one kind of API: where they are sent, how they are
+// authenticated, and how replies are read
+type backend interface {
+	// newRequest builds the HTTP request for a chat request. It is called again for
+	// every retry, so signatures are fresh.
+	newRequest(ctx context.Context, request ChatRequest) (*http.Request, error)
+	// parseResponse returns the reply in the body of a successful response
+	parseResponse(body []byte) (string, error)
+	// newStream reads the reply of a successful streamed response in chunks
+	newStream(body io.Reader, bufferSize, maxEventSize int) chunkStream
+}
+
+// chunkStream returns the content chunks of a streamed reply, then io.EOF
+type chunkStream interface {
+	Next() (string, error)
+}
+
+// api returns the backend requests are sent through: the one chosen with UseAzure or
+// UseBedrock, or the OpenAI-compatible API at the base URL
+func (c *Client) api() backend {
+	if c.backend != nil {
+		return c.backend
+	}
+	return &openAIBackend{url: c.baseURL, authHeader: "Authorization", authValue: "Bearer " + c.apiKey}
+}
+
+// openAIBackend talks to an OpenAI-compatible chat completions API: Upstage, OpenAI,
+// Azure OpenAI, gateways and local servers
+type openAIBackend struct {
+	url        string
+	authHeader string
+	authValue  string
+}
+
+func (b *openAIBackend) newRequest(ctx context.Context, request ChatRequest) (*http.Request, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", b.url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(b.authHeader, b.authValue)
+	return req, nil
+}
+
+func (b *openAIBackend) parseResponse(body []byte) (string, error) {
+	var response ChatResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("error unmarshaling response: %v", err)
+	}
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("no response choices returned")
+	}
+	return response.Choices[0].Message.Content, nil
+}
+
+func (b *openAIBackend) newStream(body io.Reader, bufferSize, maxEventSize int) chunkStream {
+	return &sseChunkStream{events: newSSEReader(body, bufferSize, maxEventSize)}
+}
+
+// sseChunkStream reads the content deltas of a server-sent events stream of
+// completion chunks
+type sseChunkStream struct {
+	events *sseReader
+}
+
+func (s *sseChunkStream) Next() (string, error) {
+	event, err := s.events.Next()
+	if err == io.EOF {
+		return "", io.EOF
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading stream: %v", err)
+	}
+	if event.Data == "[DONE]" {
+		return "", io.EOF
+	}
+	// Errors reported mid-stream end the response instead of truncating it silently
+	return parseStreamEvent(event)
+}
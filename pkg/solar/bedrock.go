@@ -0,0 +1,273 @@
+package solar
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// BedrockConfig locates the Bedrock runtime API and how to sign requests to it
+type BedrockConfig struct {
+	// Region is the AWS region, e.g. us-east-1
+	Region string
+	// Credentials sign the requests. Without them, the client's API key is sent as a
+	// Bedrock API key (bearer token).
+	Credentials AWSCredentials
+	// Endpoint replaces https://bedrock-runtime.<region>.amazonaws.com, e.g. for a
+	// VPC endpoint
+	Endpoint string
+}
+
+// UseBedrock sends requests to AWS Bedrock's Converse API. The client's model name
+// is the Bedrock model or inference profile ID, e.g.
+// anthropic.claude-3-5-haiku-20241022-v1:0.
+func (c *Client) UseBedrock(config BedrockConfig) error {
+	region := strings.TrimSpace(config.Region)
+	if region == "" {
+		return fmt.Errorf("bedrock needs an AWS region (set it in config or AWS_REGION)")
+	}
+	if !config.Credentials.Valid() && c.apiKey == "" {
+		return fmt.Errorf("bedrock needs AWS credentials or a Bedrock API key")
+	}
+	endpoint := strings.TrimSuffix(strings.TrimSpace(config.Endpoint), "/")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", region)
+	}
+	c.backend = &bedrockBackend{endpoint: endpoint, region: region, credentials: config.Credentials, apiKey: c.apiKey}
+	return nil
+}
+
+// bedrockBackend talks to the Bedrock Converse API
+// (https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_Converse.html)
+type bedrockBackend struct {
+	endpoint    string
+	region      string
+	credentials AWSCredentials
+	apiKey      string
+}
+
+// converseContent is a content block of a Converse message; only text is used
+type converseContent struct {
+	Text string `json:"text"`
+}
+
+type converseMessage struct {
+	Role    string            `json:"role"`
+	Content []converseContent `json:"content"`
+}
+
+type converseRequest struct {
+	Messages        []converseMessage  `json:"messages"`
+	System          []converseContent  `json:"system,omitempty"`
+	InferenceConfig *converseInference `json:"inferenceConfig,omitempty"`
+}
+
+type converseInference struct {
+	MaxTokens   int      `json:"maxTokens,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"topP,omitempty"`
+}
+
+func (b *bedrockBackend) newRequest(ctx context.Context, request ChatRequest) (*http.Request, error) {
+	var converse converseRequest
+	for _, message := range request.Messages {
+		if message.Role == "system" {
+			converse.System = append(converse.System, converseContent{Text: message.Content})
+			continue
+		}
+		converse.Messages = append(converse.Messages, converseMessage{Role: message.Role, Content: []converseContent{{Text: message.Content}}})
+	}
+	if request.MaxTokens > 0 || request.Temperature != nil || request.TopP != nil {
+		converse.InferenceConfig = &converseInference{MaxTokens: request.MaxTokens, Temperature: request.Temperature, TopP: request.TopP}
+	}
+	payload, err := json.Marshal(converse)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %v", err)
+	}
+
+	operation := "converse"
+	if request.Stream {
+		operation = "converse-stream"
+	}
+	// Model IDs contain ':', which must reach the API encoded
+	endpoint := fmt.Sprintf("%s/model/%s/%s", b.endpoint, awsURIEncode(request.Model), operation)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.credentials.Valid() {
+		signAWSRequest(req, payload, b.credentials, "bedrock", b.region, time.Now())
+	} else {
+		req.Header.Set("Authorization", "Bearer "+b.apiKey)
+	}
+	return req, nil
+}
+
+func (b *bedrockBackend) parseResponse(body []byte) (string, error) {
+	var response struct {
+		Output struct {
+			Message converseMessage `json:"message"`
+		} `json:"output"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("error unmarshaling response: %v", err)
+	}
+	var text strings.Builder
+	for _, block := range response.Output.Message.Content {
+		text.WriteString(block.Text)
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no text in the response")
+	}
+	return text.String(), nil
+}
+
+func (b *bedrockBackend) newStream(body io.Reader, bufferSize, maxEventSize int) chunkStream {
+	if bufferSize <= 0 {
+		bufferSize = DefaultStreamBufferSize
+	}
+	if maxEventSize <= 0 {
+		maxEventSize = DefaultMaxStreamEventSize
+	}
+	return &eventStream{reader: bufio.NewReaderSize(body, bufferSize), maxMessageSize: maxEventSize}
+}
+
+// eventStream reads the text deltas of a ConverseStream response, sent in the AWS
+// event stream encoding (application/vnd.amazon.eventstream): binary messages of
+// headers and a JSON payload, each with CRC checksums
+type eventStream struct {
+	reader         *bufio.Reader
+	maxMessageSize int
+}
+
+func (s *eventStream) Next() (string, error) {
+	for {
+		headers, payload, err := s.readMessage()
+		if err == io.EOF {
+			return "", io.EOF
+		}
+		if err != nil {
+			return "", fmt.Errorf("error reading stream: %v", err)
+		}
+
+		if headers[":message-type"] == "exception" || headers[":message-type"] == "error" {
+			var exception struct {
+				Message string `json:"message"`
+			}
+			message := strings.TrimSpace(string(payload))
+			if json.Unmarshal(payload, &exception) == nil && exception.Message != "" {
+				message = exception.Message
+			}
+			kind := headers[":exception-type"]
+			if kind == "" {
+				kind = headers[":error-code"]
+			}
+			return "", newStreamError(kind + ": " + truncateForError(message))
+		}
+
+		switch headers[":event-type"] {
+		case "contentBlockDelta":
+			var event struct {
+				Delta struct {
+					Text string `json:"text"`
+				} `json:"delta"`
+			}
+			if err := json.Unmarshal(payload, &event); err != nil {
+				return "", fmt.Errorf("invalid stream data: %v (%s)", err, truncateForError(string(payload)))
+			}
+			if event.Delta.Text != "" {
+				return event.Delta.Text, nil
+			}
+		case "messageStop":
+			return "", io.EOF
+		}
+		// messageStart, contentBlockStop, metadata and reasoning deltas carry no text
+	}
+}
+
+// readMessage reads one event stream message and returns its string headers and payload
+func (s *eventStream) readMessage() (map[string]string, []byte, error) {
+	prelude := make([]byte, 12)
+	if _, err := io.ReadFull(s.reader, prelude); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, nil, errors.New("stream ended in the middle of a message")
+		}
+		return nil, nil, err
+	}
+	totalLength := binary.BigEndian.Uint32(prelude[0:4])
+	headersLength := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return nil, nil, errors.New("corrupt message prelude")
+	}
+	if totalLength > uint32(s.maxMessageSize) {
+		return nil, nil, errStreamEventTooLarge
+	}
+	if totalLength < 16 || headersLength > totalLength-16 {
+		return nil, nil, errors.New("invalid message length")
+	}
+
+	message := make([]byte, totalLength)
+	copy(message, prelude)
+	if _, err := io.ReadFull(s.reader, message[12:]); err != nil {
+		return nil, nil, errors.New("stream ended in the middle of a message")
+	}
+	if crc32.ChecksumIEEE(message[:totalLength-4]) != binary.BigEndian.Uint32(message[totalLength-4:]) {
+		return nil, nil, errors.New("corrupt message")
+	}
+
+	headers, err := parseEventHeaders(message[12 : 12+headersLength])
+	if err != nil {
+		return nil, nil, err
+	}
+	return headers, message[12+headersLength : totalLength-4], nil
+}
+
+// eventHeaderSizes are the value sizes of the fixed-size event stream header types,
+// by type number; -1 means a 2-byte length followed by the value
+var eventHeaderSizes = []int{0, 0, 1, 2, 4, 8, -1, -1, 8, 16}
+
+// parseEventHeaders reads the headers of an event stream message, keeping those with
+// string values
+func parseEventHeaders(data []byte) (map[string]string, error) {
+	headers := make(map[string]string)
+	for len(data) > 0 {
+		nameLength := int(data[0])
+		if len(data) < 1+nameLength+1 {
+			return nil, errors.New("invalid message headers")
+		}
+		name := string(data[1 : 1+nameLength])
+		valueType := int(data[1+nameLength])
+		data = data[2+nameLength:]
+
+		if valueType >= len(eventHeaderSizes) {
+			return nil, fmt.Errorf("unknown header type %d", valueType)
+		}
+		size := eventHeaderSizes[valueType]
+		if size == -1 {
+			if len(data) < 2 {
+				return nil, errors.New("invalid message headers")
+			}
+			size = int(binary.BigEndian.Uint16(data[:2]))
+			data = data[2:]
+		}
+		if len(data) < size {
+			return nil, errors.New("invalid message headers")
+		}
+		// Type 7 is a UTF-8 string
+		if valueType == 7 {
+			headers[name] = string(data[:size])
+		}
+		data = data[size:]
+	}
+	return headers, nil
+}
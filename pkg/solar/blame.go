@@ -0,0 +1,39 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExplainCodeHistory explains why a piece of code looks the way it does, based on the
+// current lines (annotated by git blame) and the commits that last touched them.
+// onChunk is called with each piece of the explanation as it arrives.
+func (c *Client) ExplainCodeHistory(ctx context.Context, location, blame, history string, onChunk func(string)) (string, error) {
+	// The commit history carries most of the signal; the blamed code anchors it
+	truncatedBlame, _ := c.tokenCounter.TruncateToWordLimit(blame, c.maxInputWords()/4)
+	truncatedHistory, _ := c.tokenCounter.TruncateToWordLimit(history, c.maxInputWords()*3/4)
+
+	prompt := fmt.Sprintf(`You are a senior engineer doing code archaeology: explaining to a teammate why a
+piece of code looks the way it does today.
+
+=== CODE (%s, annotated with git blame) ===
+%s
+
+=== COMMITS THAT LAST TOUCHED THESE LINES (oldest first, with their diffs for this file) ===
+%s
+
+Explain the code's history:
+
+1. **🎯 Purpose**: What this code does and the problem it solves
+2. **📜 Evolution**: How it got to its current shape, commit by commit where it matters
+   (reference short hashes and authors), skipping trivial formatting changes
+3. **💡 Rationale**: The reasons behind notable decisions, inferred from commit messages
+   and diffs (bug fixes, edge cases, performance, API changes)
+4. **⚠️ Watch Out**: Workarounds, subtle invariants or known issues someone editing
+   this code should keep in mind
+
+Be concrete and base every claim on the commits above. If the reason for a change is not
+evident, say so instead of guessing.`, location, truncatedBlame, truncatedHistory)
+
+	return c.StreamResponse(ctx, c.addLanguageInstruction(prompt), onChunk)
+}
@@ -0,0 +1,51 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// ClassifyAPIChanges classifies the public API changes found between two versions as
+// breaking or compatible for existing callers. apiChanges lists the removed, changed
+// and added symbols with their signatures, scope says what is compared, and commits
+// lists the commits in between (may be empty). The response has the form
+// "VERDICT: <breaking|compatible>", "BUMP: <major|minor|patch>", "CHANGES:" followed by
+// one "- [breaking|compatible] <symbol>: <why>" line per change, and
+// "FOOTER: <BREAKING CHANGE footer text, or none>".
+func (c *Client) ClassifyAPIChanges(ctx context.Context, apiChanges, scope, commits string) (string, error) {
+	truncatedChanges, _ := c.tokenCounter.TruncateToWordLimit(apiChanges, c.maxInputWords()*2/3)
+	truncatedCommits, _ := c.tokenCounter.TruncateToWordLimit(commits, c.maxInputWords()/4)
+	if truncatedCommits == "" {
+		truncatedCommits = "Not available."
+	}
+
+	prompt := fmt.Sprintf(`You are reviewing a library's public API for breaking changes (https://semver.org).
+
+Compared: %s
+
+=== PUBLIC API CHANGES (removed, changed and added symbols with their signatures) ===
+%s
+
+=== COMMITS ===
+%s
+
+Classify each change for code that already uses the previous version:
+- breaking: removed or renamed symbols, changed parameter or result types, added
+  parameters without defaults, methods added to interfaces callers implement, changed
+  field types, narrowed visibility
+- compatible: additions, new optional parameters, widened types, and renamed parameters
+  where callers can't name them (e.g. Go)
+
+Group the CHANGES lines by importance, breaking first, and keep each to one line. The
+footer explains what breaks and how callers migrate, in 1-3 sentences; write "none" when
+nothing breaks.
+
+Respond in exactly this format, keeping the labels and the VERDICT and BUMP values in English:
+VERDICT: <breaking|compatible>
+BUMP: <major|minor|patch>
+CHANGES:
+- [breaking|compatible] <symbol>: <why>
+FOOTER: <footer text, or none>`, scope, truncatedChanges, truncatedCommits)
+
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
@@ -0,0 +1,134 @@
+package solar
+
+import "github.com/hunkim/sgit/pkg/logging"
+
+// DefaultOutputReserve is how many tokens of the context are kept free for the reply
+// when max_tokens is not configured
+const DefaultOutputReserve = 4096
+
+// BudgetSection is one variable part of a prompt, such as the diff or the file list,
+// competing with the others for the input budget
+type BudgetSection struct {
+	Text string
+	// Weight is the section's share of the budget relative to the other sections
+	// when they don't all fit
+	Weight float64
+}
+
+// TokenBudget shares the tokens a prompt may use out between its sections
+type TokenBudget struct {
+	counter *TokenCounter
+	// Available is the number of tokens left for the sections
+	Available int
+}
+
+// NewTokenBudget returns the budget for the sections of a prompt sent to a model with
+// contextLimit tokens of context: InputTokenLimit(contextLimit) at most, less the
+// outputReserve kept for the reply and the tokens of template, the prompt's own text
+func (tc *TokenCounter) NewTokenBudget(contextLimit, outputReserve int, template string) *TokenBudget {
+	available := min(InputTokenLimit(contextLimit), contextLimit-outputReserve) - tc.EstimateTokens(template)
+	return &TokenBudget{counter: tc, Available: max(available, 0)}
+}
+
+// Fit truncates the sections so that together they fit the budget, and returns them
+// in order with their total token count. Each section is offered a share of the
+// budget by weight; sections that need less than their share keep all of their text
+// and the tokens they leave unused are shared out again among the rest.
+func (b *TokenBudget) Fit(sections ...BudgetSection) ([]string, int) {
+	fitted := make([]string, len(sections))
+	needs := make([]int, len(sections))
+	total := 0
+	for i, section := range sections {
+		fitted[i] = section.Text
+		needs[i] = b.counter.EstimateTokens(section.Text)
+		total += needs[i]
+	}
+	if total <= b.Available {
+		return fitted, total
+	}
+	logging.Info("prompt over its token budget", "tokens", total, "budget", b.Available, "sections", len(sections))
+
+	remaining := b.Available
+	open := make([]int, 0, len(sections))
+	for i := range sections {
+		if needs[i] > 0 {
+			open = append(open, i)
+		}
+	}
+	total = 0
+	for len(open) > 0 {
+		weights := 0.0
+		for _, i := range open {
+			weights += sections[i].Weight
+		}
+		shares := make(map[int]int, len(open))
+		for _, i := range open {
+			shares[i] = remaining / len(open)
+			if weights > 0 {
+				shares[i] = int(float64(remaining) * sections[i].Weight / weights)
+			}
+		}
+
+		// Sections that fit in their share are settled; the others wait for what
+		// they leave over
+		var contested []int
+		left := remaining
+		for _, i := range open {
+			if needs[i] <= shares[i] {
+				left -= needs[i]
+				total += needs[i]
+				continue
+			}
+			contested = append(contested, i)
+		}
+		if len(contested) == len(open) {
+			for _, i := range open {
+				var tokens int
+				fitted[i], tokens = b.counter.TruncateToTokens(sections[i].Text, shares[i])
+				total += tokens
+			}
+			break
+		}
+		remaining, open = left, contested
+	}
+	return fitted, total
+}
+
+// commitSections weights the context of a commit prompt: the diff most, then the file
+// list, with the branch and recent commits kept short
+func commitSections(diff, branch, recentCommits, fileList string) []BudgetSection {
+	return []BudgetSection{
+		{Text: diff, Weight: 0.6},
+		{Text: branch, Weight: 0.05},
+		{Text: recentCommits, Weight: 0.1},
+		{Text: fileList, Weight: 0.25},
+	}
+}
+
+// promptBudget returns the budget for the sections of a prompt whose own text, with
+// every section left empty, is template. The system message, the language instruction
+// and the tokens reserved for the reply (max_tokens, or DefaultOutputReserve) are
+// taken out of it too. It grows and shrinks with the model's context window.
+func (c *Client) promptBudget(template string) *TokenBudget {
+	reserve := c.options.MaxTokens
+	if reserve <= 0 {
+		reserve = DefaultOutputReserve
+	}
+	systemPrompt := c.options.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = DefaultSystemPrompt
+	}
+	return c.tokenCounter.NewTokenBudget(c.ContextWindow(), reserve, systemPrompt+"\n"+c.addLanguageInstruction(template))
+}
+
+// fitPrompt builds a prompt from sections truncated to fit its budget. build renders
+// the prompt from the section texts, in order; it is called once with them empty to
+// measure the prompt's own text.
+func (c *Client) fitPrompt(sections []BudgetSection, build func(texts []string) (string, error)) (string, error) {
+	template, err := build(make([]string, len(sections)))
+	if err != nil {
+		return "", err
+	}
+	fitted, _ := c.promptBudget(template).Fit(sections...)
+	return build(fitted)
+}
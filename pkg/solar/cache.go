@@ -0,0 +1,180 @@
+package solar
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultCacheTTL is how long a cached response stays valid
+const DefaultCacheTTL = 7 * 24 * time.Hour
+
+// Cache stores API responses on disk, keyed by a hash of the request (model, messages
+// and sampling options), so re-running a command on unchanged content doesn't cost
+// another API call
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+// cacheEntry is the on-disk representation of a cached response
+type cacheEntry struct {
+	Created  time.Time `json:"created"`
+	Response string    `json:"response"`
+}
+
+// NewCache creates a response cache in dir. A ttl of 0 uses DefaultCacheTTL.
+func NewCache(dir string, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Cache{dir: dir, ttl: ttl}
+}
+
+// DefaultCacheDir returns the sgit cache directory (e.g. ~/.cache/sgit)
+func DefaultCacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding cache directory: %v", err)
+	}
+	return filepath.Join(base, "sgit"), nil
+}
+
+// Dir returns the directory the cache is stored in
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// key hashes the request, which holds everything that influences the response
+func (c *Cache) key(request []byte) string {
+	sum := sha256.Sum256(request)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Get returns a cached response for the request, if present and not expired
+func (c *Cache) Get(request []byte) (string, bool) {
+	data, err := os.ReadFile(c.path(c.key(request)))
+	if err != nil {
+		return "", false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return "", false
+	}
+	if time.Since(entry.Created) > c.ttl || entry.Response == "" {
+		return "", false
+	}
+	return entry.Response, true
+}
+
+// Put stores a response for the request. Failures are ignored since the cache
+// is only an optimization.
+func (c *Cache) Put(request []byte, response string) {
+	key := c.key(request)
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+
+	data, err := json.Marshal(cacheEntry{Created: time.Now(), Response: response})
+	if err != nil {
+		return
+	}
+
+	// Write to a uniquely named temp file and rename, so concurrent writers (serve,
+	// ipc) neither clobber each other nor expose partial entries
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Clear removes all cached responses and returns the number of entries removed.
+// Only the <xx>/<sha256>.json entries Put writes are deleted, along with the
+// subdirectories they leave empty, so a cache_dir shared with other files is safe.
+func (c *Cache) Clear() (int, error) {
+	subdirs, err := os.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("error reading cache: %v", err)
+	}
+
+	count := 0
+	for _, subdir := range subdirs {
+		if !subdir.IsDir() || !isCacheSubdir(subdir.Name()) {
+			continue
+		}
+		dir := filepath.Join(c.dir, subdir.Name())
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return count, fmt.Errorf("error reading cache: %v", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			isEntry := isCacheEntry(subdir.Name(), entry.Name())
+			if !isEntry && !isCacheTemp(subdir.Name(), entry.Name()) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+				return count, fmt.Errorf("error clearing cache: %v", err)
+			}
+			if isEntry {
+				count++
+			}
+		}
+		// Fails, harmlessly, if the directory holds anything besides cache entries
+		os.Remove(dir)
+	}
+	return count, nil
+}
+
+// isCacheSubdir reports whether name is a two-hex-digit key prefix directory
+func isCacheSubdir(name string) bool {
+	return len(name) == 2 && isHex(name)
+}
+
+// isCacheEntry reports whether name is a cache entry file (<sha256>.json) that
+// belongs in the prefix directory
+func isCacheEntry(prefix, name string) bool {
+	key := strings.TrimSuffix(name, ".json")
+	return key != name && len(key) == sha256.Size*2 && strings.HasPrefix(key, prefix) && isHex(key)
+}
+
+// isCacheTemp reports whether name is a temp file left behind by an interrupted Put
+func isCacheTemp(prefix, name string) bool {
+	key, _, found := strings.Cut(name, ".")
+	return found && strings.HasSuffix(name, ".tmp") && isCacheEntry(prefix, key+".json")
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// SetCache enables response caching. A nil cache disables it.
+func (c *Client) SetCache(cache *Cache) {
+	c.cache = cache
+}
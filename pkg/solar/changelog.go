@@ -0,0 +1,117 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChangelogFormats contains the built-in changelog output formats
+var ChangelogFormats = map[string]string{
+	"keepachangelog": `Use the Keep a Changelog format (https://keepachangelog.com/en/1.0.0/):
+## [VERSION] - DATE
+### Added
+### Changed
+### Deprecated
+### Removed
+### Fixed
+### Security
+Only include sections that have entries. Put breaking changes first under "### Changed",
+or "### Removed" for removed features, with a "**BREAKING:**" prefix; there is no
+separate section for them.`,
+	"markdown": `Use this markdown format:
+## VERSION (DATE)
+### ✨ Features
+### 🐛 Fixes
+### 💥 Breaking Changes
+### 🔧 Other Changes
+Only include sections that have entries.`,
+}
+
+// GenerateChangelog generates grouped changelog entries for a range of commits.
+// diff is the change over the range (may be empty) and formatInstructions describes
+// the expected markdown layout (see ChangelogFormats).
+func (c *Client) GenerateChangelog(ctx context.Context, commits, diffStat, diff, version, date, formatInstructions string) (string, error) {
+	// Commits name the changes and the diff shows what they do; the diffstat keeps the
+	// files the truncated diff leaves out in view
+	truncatedCommits, _ := c.tokenCounter.TruncateToWordLimit(commits, c.maxInputWords()/3)
+	truncatedDiffStat, _ := c.tokenCounter.TruncateToWordLimit(diffStat, c.maxInputWords()/6)
+	truncatedDiff, _ := c.tokenCounter.TruncateToWordLimit(c.prepareDiff(diff), c.maxInputWords()/2)
+	if truncatedDiff == "" {
+		truncatedDiff = "Not available."
+	}
+
+	prompt := fmt.Sprintf(`You are a release manager writing a changelog entry for end users and developers.
+
+Version: %s
+Date: %s
+
+=== COMMITS (hash, subject, body) ===
+%s
+
+=== FILES CHANGED ===
+%s
+
+=== CHANGES ===
+%s
+
+Write the changelog entry for this version.
+
+%s
+
+Rules:
+1. Group related commits into a single entry; don't list every commit one by one
+2. Write entries in user-facing language, describing the effect of the change
+3. Mark breaking changes (BREAKING CHANGE markers, "!" conventional commit types, or
+   incompatible changes the diff shows) where the format says; never add sections it doesn't have
+4. Skip purely internal noise (merge commits, typo fixes, CI tweaks) unless significant
+5. Use "-" bullets, one line per entry
+6. Replace VERSION and DATE with the values above
+
+Respond with only the markdown changelog entry, no explanations.`, version, date, truncatedCommits, truncatedDiffStat, truncatedDiff, formatInstructions)
+
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
+
+// RecommendVersionBump asks which semantic version bump (major, minor or patch) the
+// commits since the last release call for. ruleBump is the bump implied by conventional
+// commit types and BREAKING CHANGE markers, and apiChanges lists the public API
+// symbols removed, changed or added since the release (may be empty). The response has
+// the form "BUMP: <major|minor|patch>" followed by "REASON: <explanation>".
+func (c *Client) RecommendVersionBump(ctx context.Context, currentVersion, commits, diffStat, ruleBump, apiChanges string) (string, error) {
+	truncatedCommits, _ := c.tokenCounter.TruncateToWordLimit(commits, c.maxInputWords()/2)
+	truncatedDiffStat, _ := c.tokenCounter.TruncateToWordLimit(diffStat, c.maxInputWords()/4)
+	truncatedAPIChanges, _ := c.tokenCounter.TruncateToWordLimit(apiChanges, c.maxInputWords()/4)
+	if truncatedAPIChanges == "" {
+		truncatedAPIChanges = "None detected."
+	}
+
+	prompt := fmt.Sprintf(`You are a release manager deciding the next semantic version (https://semver.org).
+
+Current version: %s
+Bump implied by conventional commit types and BREAKING CHANGE markers: %s
+
+=== COMMITS SINCE THE CURRENT VERSION (hash, subject, body) ===
+%s
+
+=== FILES CHANGED ===
+%s
+
+=== PUBLIC API CHANGES (detected from the code) ===
+%s
+
+Decide the bump:
+- major: incompatible API or behavior changes (BREAKING CHANGE footers, "!" after the type,
+  removed or renamed public APIs, changed defaults users rely on)
+- minor: new backwards-compatible functionality (feat)
+- patch: backwards-compatible bug fixes and internal changes only
+
+Never recommend less than the bump implied by the commit markers. Recommend more only if the
+commits, changed files or API changes clearly show an undeclared breaking change or feature.
+A removed or changed public symbol is breaking unless existing callers keep compiling and working.
+
+Respond in exactly this format, keeping the BUMP and REASON labels and the bump value in English:
+BUMP: <major|minor|patch>
+REASON: <2-4 sentences citing the commits that drive the decision>`, currentVersion, ruleBump, truncatedCommits, truncatedDiffStat, truncatedAPIChanges)
+
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
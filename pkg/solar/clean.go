@@ -0,0 +1,88 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Untracked path classes for CleanVerdict
+const (
+	CleanJunk      = "JUNK"
+	CleanImportant = "IMPORTANT"
+	CleanUnknown   = "UNKNOWN"
+)
+
+// UntrackedPath is an untracked file or directory and a description of it (size,
+// age, file count, a few file names)
+type UntrackedPath struct {
+	Path        string
+	Description string
+}
+
+// CleanVerdict is the model's classification of one untracked path
+type CleanVerdict struct {
+	Class  string
+	Reason string
+}
+
+// ClassifyUntrackedPaths sorts untracked paths into junk that can be deleted (build
+// output, caches, logs), potentially important files (untracked source, notes) and
+// unknown ones, in a single request. Paths missing from the response are unknown.
+func (c *Client) ClassifyUntrackedPaths(ctx context.Context, projectFiles string, paths []UntrackedPath) (map[string]CleanVerdict, error) {
+	var b strings.Builder
+	for i, p := range paths {
+		fmt.Fprintf(&b, "=== PATH %d: %s ===\n%s\n\n", i+1, p.Path, p.Description)
+	}
+	listing, _ := c.tokenCounter.TruncateToWordLimit(b.String(), c.maxInputWords()*2/3)
+	project, _ := c.tokenCounter.TruncateToWordLimit(projectFiles, c.maxInputWords()/6)
+
+	prompt := fmt.Sprintf(`You are a careful assistant helping a developer clean untracked files out of a git working tree. Deleted files cannot be recovered, so only call something junk when you are confident it can be regenerated or is worthless.
+
+=== PROJECT FILES (top-level entries and tracked file types) ===
+%s
+
+=== UNTRACKED PATHS (%d) ===
+%s
+Classify each path as one of:
+- JUNK: regenerable or disposable, e.g. build output, dependency directories, caches, logs, editor swap files, coverage reports, OS files
+- IMPORTANT: may hold work that exists nowhere else, e.g. new source files, notes, documents, local configuration or environment files, data the developer created
+- UNKNOWN: you can't tell from the description
+
+Respond with exactly one line per path, in order, and nothing else:
+"<path number>. JUNK|IMPORTANT|UNKNOWN: [brief reason]"
+
+Keep each reason under 60 characters.`, project, len(paths), listing)
+
+	response, err := c.GenerateResponse(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCleanVerdicts(response, paths), nil
+}
+
+// parseCleanVerdicts maps "N. CLASS: reason" lines back to paths
+func parseCleanVerdicts(response string, paths []UntrackedPath) map[string]CleanVerdict {
+	verdicts := make(map[string]CleanVerdict)
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "*`"))
+
+		var index int
+		if n, err := fmt.Sscanf(line, "%d.", &index); n != 1 || err != nil {
+			continue
+		}
+		if index < 1 || index > len(paths) {
+			continue
+		}
+		rest := strings.TrimSpace(line[strings.Index(line, ".")+1:])
+
+		class, reason, _ := strings.Cut(rest, ":")
+		class = strings.ToUpper(strings.TrimSpace(class))
+		switch class {
+		case CleanJunk, CleanImportant, CleanUnknown:
+			verdicts[paths[index-1].Path] = CleanVerdict{Class: class, Reason: strings.TrimSpace(reason)}
+		}
+	}
+	return verdicts
+}
@@ -1,16 +1,17 @@
 package solar
 
 import (
-	"bufio"
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
-	"os"
 	"strings"
-	"sync"
 	"time"
+
+	"github.com/hunkim/sgit/pkg/difffilter"
+	"github.com/hunkim/sgit/pkg/logging"
+	"github.com/hunkim/sgit/pkg/redact"
 )
 
 // Client represents the Solar LLM API client
@@ -18,8 +19,36 @@ type Client struct {
 	apiKey       string
 	modelName    string
 	baseURL      string
+	backend      backend
 	language     string
+	convention   Convention
+	hints        CommitHints
+	sections     []ContextSection
+	correction   string
+	commitTypes  []string
+	commitScopes []string
+	gitmoji      bool
+	options      GenerationOptions
+	retry        RetryPolicy
+	onRetry      RetryNotifier
+	onActivity   ActivityFunc
+	cache        *Cache
+	promptDir    string
+	templateVars TemplateVars
 	tokenCounter *TokenCounter
+	dryRun       PromptInspector
+	redactor     *redact.Redactor
+	onRedacted   RedactionNotifier
+
+	// contextWindow overrides ContextWindows for the model; 0 uses them
+	contextWindow int
+
+	diffFilter     *difffilter.Options
+	onDiffFiltered DiffFilterNotifier
+	lastDiffNote   string
+
+	streamBufferSize   int
+	maxStreamEventSize int
 }
 
 // Message represents a chat message
@@ -30,9 +59,14 @@ type Message struct {
 
 // ChatRequest represents the request structure for Solar LLM API
 type ChatRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
-	Stream   bool      `json:"stream"`
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	Stream      bool      `json:"stream"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	TopP        *float64  `json:"top_p,omitempty"`
+	MaxTokens   int       `json:"max_tokens,omitempty"`
+	// ReasoningEffort is "low", "medium" or "high" for models that reason before answering
+	ReasoningEffort string `json:"reasoning_effort,omitempty"`
 }
 
 // ChatResponse represents the response structure from Solar LLM API
@@ -60,94 +94,13 @@ type Choice struct {
 	Message Message `json:"message"`
 }
 
-// Options for the request (commented out as it might not be supported)
-// type Options struct {
-// 	ReasoningEffort string `json:"reasoning_effort"`
-// }
-
-// Spinner represents a loading spinner
-type Spinner struct {
-	chars    []string
-	delay    time.Duration
-	active   bool
-	mu       sync.RWMutex
-	stopChan chan bool
-}
-
-// NewSpinner creates a new spinner with default settings
-func NewSpinner() *Spinner {
-	// Try to use Unicode spinner if available, fall back to ASCII
-	unicodeSpinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-
-	// ASCII fallback spinner
-	asciiSpinner := []string{"|", "/", "-", "\\"}
-
-	// Check if we're likely in a terminal that supports Unicode
-	termType := os.Getenv("TERM")
-	if strings.Contains(termType, "xterm") || strings.Contains(termType, "screen") ||
-		termType == "" || strings.Contains(termType, "color") {
-		return &Spinner{
-			chars:    unicodeSpinner,
-			delay:    100 * time.Millisecond,
-			stopChan: make(chan bool, 1),
-		}
-	}
-
-	// Fall back to ASCII for older/simpler terminals
-	return &Spinner{
-		chars:    asciiSpinner,
-		delay:    100 * time.Millisecond,
-		stopChan: make(chan bool, 1),
-	}
-}
-
-// Start begins the spinner animation
-func (s *Spinner) Start(message string) {
-	s.mu.Lock()
-	s.active = true
-	s.mu.Unlock()
-
-	go func() {
-		i := 0
-		for {
-			select {
-			case <-s.stopChan:
-				return
-			default:
-				s.mu.RLock()
-				if !s.active {
-					s.mu.RUnlock()
-					return
-				}
-				s.mu.RUnlock()
-
-				fmt.Printf("\r%s %s", s.chars[i%len(s.chars)], message)
-				i++
-				time.Sleep(s.delay)
-			}
-		}
-	}()
-}
-
-// Stop ends the spinner animation and clears the line
-func (s *Spinner) Stop() {
-	s.mu.Lock()
-	s.active = false
-	s.mu.Unlock()
-
-	select {
-	case s.stopChan <- true:
-	default:
-	}
-
-	// Clear the spinner line
-	fmt.Print("\r" + strings.Repeat(" ", 60) + "\r")
-}
+// DefaultModel is the model used when none is configured
+const DefaultModel = "solar-pro2-preview"
 
 // NewClient creates a new Solar LLM client
 func NewClient(apiKey, modelName, language string) *Client {
 	if modelName == "" {
-		modelName = "solar-pro2-preview"
+		modelName = DefaultModel
 	}
 	if language == "" {
 		language = "English"
@@ -157,6 +110,8 @@ func NewClient(apiKey, modelName, language string) *Client {
 		modelName:    modelName,
 		baseURL:      "https://api.upstage.ai/v1/chat/completions",
 		language:     language,
+		convention:   Conventions[DefaultConvention],
+		retry:        RetryPolicy{MaxAttempts: DefaultMaxAttempts, BaseDelay: defaultBaseDelay},
 		tokenCounter: NewTokenCounter(),
 	}
 }
@@ -167,404 +122,354 @@ func (c *Client) addLanguageInstruction(prompt string) string {
 		return prompt
 	}
 
-	// Map language codes to full names for clearer AI instructions
-	languageNames := map[string]string{
-		"ko": "Korean (한국어)",
-		"ja": "Japanese (日本語)",
-		"zh": "Chinese (中文)",
-		"es": "Spanish (Español)",
-		"fr": "French (Français)",
-		"de": "German (Deutsch)",
-	}
-
-	languageName, exists := languageNames[c.language]
-	if !exists {
-		// Fallback: use the code as is if not in our map
-		languageName = c.language
-	}
+	// Spell out the language (e.g. "Vietnamese (Tiếng Việt)" for vi) for clearer AI instructions
+	languageName := LanguageName(c.language)
 
 	languageInstruction := fmt.Sprintf("IMPORTANT: Please respond in %s. All explanations, commit messages, summaries, and analysis should be written in %s.\n\n", languageName, languageName)
 	return languageInstruction + prompt
 }
 
 // GenerateCommitMessage generates a commit message based on the git diff
-func (c *Client) GenerateCommitMessage(diff string) (string, error) {
+func (c *Client) GenerateCommitMessage(ctx context.Context, diff string) (string, error) {
 	// Apply word limiting to diff content
-	truncatedDiff, _, _ := c.tokenCounter.TruncateContent(diff)
+	truncatedDiff, _, _ := c.truncateContent(c.prepareDiff(diff))
 
-	prompt := fmt.Sprintf(`You are an expert software developer who writes excellent commit messages following the Conventional Commits specification.
+	prompt, err := c.renderPrompt(PromptCommit, PromptData{Diff: truncatedDiff})
+	if err != nil {
+		return "", err
+	}
 
-Analyze the following git diff and generate a concise, descriptive commit message:
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
 
-%s
+// GenerateComprehensiveCommitMessage generates a comprehensive commit message based on the git diff, branch, recent commits, and file list
+func (c *Client) GenerateComprehensiveCommitMessage(ctx context.Context, diff, branch, recentCommits, fileList string) (string, error) {
+	prompt, err := c.comprehensiveCommitPrompt(diff, branch, recentCommits, fileList)
+	if err != nil {
+		return "", err
+	}
 
-Guidelines:
-1. Use conventional commit format: type(scope): description
-2. Types: feat, fix, docs, style, refactor, test, chore, perf, ci, build
-3. Include scope if relevant (e.g., auth, api, ui, db)
-4. Description should be imperative mood ("add" not "added")
-5. Keep first line under 50 characters if possible
-6. If changes are complex, add a brief body explaining the what and why
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
 
-Examples:
-- feat(auth): add OAuth2 integration
-- fix(api): handle null pointer in user service
-- docs: update installation instructions
-- refactor(db): optimize query performance
+// GenerateComprehensiveCommitMessageStream generates a commit message with streaming,
+// calling onChunk with each piece of the message as it arrives
+func (c *Client) GenerateComprehensiveCommitMessageStream(ctx context.Context, diff, branch, recentCommits, fileList string, onChunk func(string)) (string, error) {
+	prompt, err := c.comprehensiveCommitPrompt(diff, branch, recentCommits, fileList)
+	if err != nil {
+		return "", err
+	}
 
-Respond with only the commit message, no explanations.`, truncatedDiff)
+	return c.StreamResponse(ctx, c.addLanguageInstruction(prompt), onChunk)
+}
 
-	return c.GenerateResponse(c.addLanguageInstruction(prompt))
+// comprehensiveCommitPrompt builds the intention-focused commit prompt shared by the streaming and non-streaming variants,
+// with the context truncated to fit the token budget
+func (c *Client) comprehensiveCommitPrompt(diff, branch, recentCommits, fileList string) (string, error) {
+	return c.fitPrompt(commitSections(c.prepareDiff(diff), branch, recentCommits, fileList), func(texts []string) (string, error) {
+		return c.renderPrompt(PromptComprehensiveCommit, PromptData{
+			Diff:          texts[0],
+			Branch:        texts[1],
+			RecentCommits: texts[2],
+			FileList:      texts[3],
+		})
+	})
 }
 
-// GenerateComprehensiveCommitMessage generates a comprehensive commit message based on the git diff, branch, recent commits, and file list
-func (c *Client) GenerateComprehensiveCommitMessage(diff, branch, recentCommits, fileList string) (string, error) {
-	// Apply token/word limiting before creating the prompt - reuse the same logic as streaming version
-	truncatedDiff, truncatedBranch, truncatedRecentCommits, truncatedFileList, _ := c.tokenCounter.SplitContent(diff, branch, recentCommits, fileList)
+// SummarizeDiff generates a summary of the git diff
+func (c *Client) SummarizeDiff(ctx context.Context, diff string) (string, error) {
+	// Apply word limiting to diff content
+	truncatedDiff, _, _ := c.truncateContent(c.prepareDiff(diff))
+
+	prompt, err := c.renderPrompt(PromptDiffSummary, PromptData{Diff: truncatedDiff})
+	if err != nil {
+		return "", err
+	}
 
-	prompt := fmt.Sprintf(`You are an expert software developer who writes excellent commit messages following the Conventional Commits specification.
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
 
-Your task is to analyze the changes and UNDERSTAND THE DEVELOPER'S INTENTION, not just describe what changed.
+// AnalyzeLog generates insights from the git log
+func (c *Client) AnalyzeLog(ctx context.Context, logOutput, timeframe string) (string, error) {
+	// Apply word limiting to log output
+	truncatedLog, _, _ := c.truncateContent(logOutput)
 
-=== GIT DIFF ===
-%s
+	prompt, err := c.renderPrompt(PromptLogAnalysis, PromptData{Log: truncatedLog, Timeframe: timeframe})
+	if err != nil {
+		return "", err
+	}
 
-=== CURRENT BRANCH ===
-%s
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
 
-=== RECENT COMMITS (last 5) ===
-%s
+// AnalyzeLogStream generates insights from the git log with streaming, calling onChunk
+// with each piece of the analysis as it arrives
+func (c *Client) AnalyzeLogStream(ctx context.Context, logOutput, timeframe string, onChunk func(string)) (string, error) {
+	return c.AnalyzeLogFocusStream(ctx, logOutput, timeframe, LogFocus{}, onChunk)
+}
 
-=== FILES CHANGED ===
-%s
+// LogFocus narrows a log analysis to one contributor and/or one part of the tree
+type LogFocus struct {
+	Author string
+	Path   string
+}
 
-INTENTION ANALYSIS - Consider these aspects:
-1. **Purpose**: Why was this change made? (bug fix, new feature, improvement, refactor, etc.)
-2. **Context Clues**: 
-   - Branch name patterns (feature/, fix/, hotfix/, etc.)
-   - File patterns (test files = testing, config files = configuration, etc.)
-   - Code patterns (adding validation = security/reliability, adding logs = debugging, etc.)
-3. **Development Flow**: 
-   - How does this fit with recent commits?
-   - Is this part of a larger feature or fix?
-   - Is this completing something started earlier?
-4. **Impact Intent**:
-   - Performance improvement? Security enhancement? User experience? Developer experience?
-   - Breaking changes? Backward compatibility? API changes?
-5. **Technical Intention**:
-   - Architecture improvements? Code quality? Maintainability?
-   - Integration with external systems? Internal refactoring?
-
-REASONING PATTERNS TO LOOK FOR:
-- Adding tests → ensuring reliability/quality
-- Adding error handling → improving robustness  
-- Adding validation → security/data integrity
-- Adding logging → debugging/monitoring
-- Changing config → deployment/environment setup
-- Updating docs → knowledge sharing/onboarding
-- Refactoring → code quality/maintainability
-- Adding endpoints → new functionality
-- Fixing types → type safety/correctness
-- Adding dependencies → leveraging external capabilities
-
-Generate a commit message that:
-1. Follows conventional commit format: type(scope): description
-2. Types: feat, fix, docs, style, refactor, test, chore, perf, ci, build
-3. CAPTURES THE INTENTION, not just the mechanics
-4. Uses imperative mood ("add" not "added")
-5. Includes a brief body (2-3 lines) explaining:
-   - WHY this change was made (the intention/purpose)
-   - WHAT problem it solves or improvement it provides
-   - HOW it impacts users/developers/system
-6. Mentions breaking changes if applicable
-7. Keep total length between 200-400 characters
-
-Examples of intention-focused messages:
-❌ "feat(api): add new endpoint" (describes mechanics)
-✅ "feat(api): enable user profile customization" (describes intention)
-
-❌ "fix(db): change query" (describes mechanics)  
-✅ "fix(db): prevent memory leak in long-running queries" (describes intention)
-
-❌ "refactor(auth): update code" (describes mechanics)
-✅ "refactor(auth): simplify token validation for better maintainability" (describes intention)
-
-Format:
-type(scope): intention-focused summary that explains WHY
-
-Brief explanation of the purpose and impact of this change.
-Focus on the problem solved or improvement made, not just what files changed.
-
-BREAKING CHANGE: description if applicable (only if truly breaking)
-
-Respond with only the commit message, no explanations.`, truncatedDiff, truncatedBranch, truncatedRecentCommits, truncatedFileList)
-
-	return c.GenerateResponse(c.addLanguageInstruction(prompt))
-}
-
-// GenerateComprehensiveCommitMessageStream generates a commit message with streaming
-func (c *Client) GenerateComprehensiveCommitMessageStream(diff, branch, recentCommits, fileList string) (string, error) {
-	// Apply token/word limiting before creating the prompt
-	truncatedDiff, truncatedBranch, truncatedRecentCommits, truncatedFileList, totalWords := c.tokenCounter.SplitContent(diff, branch, recentCommits, fileList)
-
-	fmt.Printf("📊 Content analysis: %d words total", totalWords)
-	if totalWords > MaxInputWords {
-		fmt.Printf(" (truncated from %d)", c.tokenCounter.CountWords(diff+branch+recentCommits+fileList))
+// describe says what the analysis focuses on, or returns "" for the whole project
+func (f LogFocus) describe() string {
+	switch {
+	case f.Author != "" && f.Path != "":
+		return fmt.Sprintf("the work of %s on %s", f.Author, f.Path)
+	case f.Author != "":
+		return fmt.Sprintf("the work of %s", f.Author)
+	case f.Path != "":
+		return fmt.Sprintf("the changes to %s", f.Path)
 	}
-	fmt.Println()
+	return ""
+}
 
-	prompt := fmt.Sprintf(`You are an expert software developer who writes excellent commit messages following the Conventional Commits specification.
+// AnalyzeLogFocusStream is AnalyzeLogStream for a log filtered to the focus, with the
+// analysis aimed at that contributor or subsystem instead of the whole project
+func (c *Client) AnalyzeLogFocusStream(ctx context.Context, logOutput, timeframe string, focus LogFocus, onChunk func(string)) (string, error) {
+	// Apply word limiting to log output
+	truncatedLog, _, _ := c.truncateContent(logOutput)
 
-Your task is to analyze the changes and UNDERSTAND THE DEVELOPER'S INTENTION, not just describe what changed.
+	prompt, err := c.renderPrompt(PromptLogAnalysisDetailed, PromptData{Log: truncatedLog, Timeframe: timeframe, Focus: focus.describe()})
+	if err != nil {
+		return "", err
+	}
 
-=== GIT DIFF ===
-%s
+	return c.StreamResponse(ctx, c.addLanguageInstruction(prompt), onChunk)
+}
 
-=== CURRENT BRANCH ===
-%s
+// SummarizeDiffStream generates a summary of the git diff with streaming, calling onChunk
+// with each piece of the summary as it arrives
+func (c *Client) SummarizeDiffStream(ctx context.Context, diff string, onChunk func(string)) (string, error) {
+	return c.SummarizeRevisionDiffStream(ctx, diff, "", "", onChunk)
+}
 
-=== RECENT COMMITS (last 5) ===
-%s
+// SummarizeRevisionDiffStream is SummarizeDiffStream for a diff between revisions. scope
+// says what the diff compares (e.g. "changes introduced by commit a1b2c3d") and commits
+// lists the commits in the range; either may be empty.
+func (c *Client) SummarizeRevisionDiffStream(ctx context.Context, diff, scope, commits string, onChunk func(string)) (string, error) {
+	// Apply word limiting to diff content
+	truncatedDiff, _, _ := c.truncateContent(c.prepareDiff(diff))
+	truncatedCommits, _ := c.tokenCounter.TruncateToWordLimit(commits, c.maxInputWords()/8)
 
-=== FILES CHANGED ===
-%s
+	prompt, err := c.renderPrompt(PromptDiffSummaryDetailed, PromptData{Diff: truncatedDiff, Scope: scope, Commits: truncatedCommits})
+	if err != nil {
+		return "", err
+	}
 
-INTENTION ANALYSIS - Consider these aspects:
-1. **Purpose**: Why was this change made? (bug fix, new feature, improvement, refactor, etc.)
-2. **Context Clues**: 
-   - Branch name patterns (feature/, fix/, hotfix/, etc.)
-   - File patterns (test files = testing, config files = configuration, etc.)
-   - Code patterns (adding validation = security/reliability, adding logs = debugging, etc.)
-3. **Development Flow**: 
-   - How does this fit with recent commits?
-   - Is this part of a larger feature or fix?
-   - Is this completing something started earlier?
-4. **Impact Intent**:
-   - Performance improvement? Security enhancement? User experience? Developer experience?
-   - Breaking changes? Backward compatibility? API changes?
-5. **Technical Intention**:
-   - Architecture improvements? Code quality? Maintainability?
-   - Integration with external systems? Internal refactoring?
-
-REASONING PATTERNS TO LOOK FOR:
-- Adding tests → ensuring reliability/quality
-- Adding error handling → improving robustness  
-- Adding validation → security/data integrity
-- Adding logging → debugging/monitoring
-- Changing config → deployment/environment setup
-- Updating docs → knowledge sharing/onboarding
-- Refactoring → code quality/maintainability
-- Adding endpoints → new functionality
-- Fixing types → type safety/correctness
-- Adding dependencies → leveraging external capabilities
-
-Generate a commit message that:
-1. Follows conventional commit format: type(scope): description
-2. Types: feat, fix, docs, style, refactor, test, chore, perf, ci, build
-3. CAPTURES THE INTENTION, not just the mechanics
-4. Uses imperative mood ("add" not "added")
-5. Includes a brief body (2-3 lines) explaining:
-   - WHY this change was made (the intention/purpose)
-   - WHAT problem it solves or improvement it provides
-   - HOW it impacts users/developers/system
-6. Mentions breaking changes if applicable
-7. Keep total length between 200-400 characters
-
-Examples of intention-focused messages:
-❌ "feat(api): add new endpoint" (describes mechanics)
-✅ "feat(api): enable user profile customization" (describes intention)
-
-❌ "fix(db): change query" (describes mechanics)  
-✅ "fix(db): prevent memory leak in long-running queries" (describes intention)
-
-❌ "refactor(auth): update code" (describes mechanics)
-✅ "refactor(auth): simplify token validation for better maintainability" (describes intention)
-
-Format:
-type(scope): intention-focused summary that explains WHY
-
-Brief explanation of the purpose and impact of this change.
-Focus on the problem solved or improvement made, not just what files changed.
-
-BREAKING CHANGE: description if applicable (only if truly breaking)
-
-Respond with only the commit message, no explanations.`, truncatedDiff, truncatedBranch, truncatedRecentCommits, truncatedFileList)
-
-	return c.GenerateResponseStream(c.addLanguageInstruction(prompt))
+	return c.StreamResponse(ctx, c.addLanguageInstruction(prompt), onChunk)
 }
 
-// SummarizeDiff generates a summary of the git diff
-func (c *Client) SummarizeDiff(diff string) (string, error) {
-	// Apply word limiting to diff content
-	truncatedDiff, _, _ := c.tokenCounter.TruncateContent(diff)
+// AnalyzeMergeConflicts provides guidance for resolving merge conflicts. conflictInfo
+// may list just the conflicted files or include the conflicting hunks themselves.
+func (c *Client) AnalyzeMergeConflicts(ctx context.Context, conflictInfo string) (string, error) {
+	truncatedInfo, _, _ := c.truncateContent(conflictInfo)
+
+	prompt, err := c.renderPrompt(PromptMergeConflict, PromptData{Conflicts: truncatedInfo})
+	if err != nil {
+		return "", err
+	}
+
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
 
-	prompt := fmt.Sprintf(`Analyze the following git diff and provide a clear, concise summary of the changes:
+// JudgeCommitMessages reviews numbered commit messages against the configured convention.
+// The response has one "N: OK" or "N: ISSUE - reason" line per message.
+func (c *Client) JudgeCommitMessages(ctx context.Context, messages string) (string, error) {
+	truncatedMessages, _, _ := c.truncateContent(messages)
+
+	prompt := fmt.Sprintf(`You are reviewing commit messages for a CI lint check.
 
 %s
+Each commit message below is numbered. Judge whether it follows the convention above, uses imperative mood, and has a subject that clearly states the intent of the change (not vague like "update code" or "fix stuff").
 
-Provide:
-1. **Summary**: One-line overview of what changed
-2. **Files Modified**: List of main files/components affected
-3. **Type of Changes**: New features, bug fixes, refactoring, etc.
-4. **Impact**: Potential effects on functionality
-5. **Notable**: Any important details (breaking changes, performance impacts, etc.)
+%s
 
-Keep it concise but informative.`, truncatedDiff)
+Respond with exactly one line per message, in this format:
+N: OK
+N: ISSUE - <short reason>
 
-	return c.GenerateResponse(c.addLanguageInstruction(prompt))
+Only report real problems; minor style preferences are OK.`, c.conventionSection(), truncatedMessages)
+
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
 }
 
-// AnalyzeLog generates insights from the git log
-func (c *Client) AnalyzeLog(logOutput, timeframe string) (string, error) {
-	// Apply word limiting to log output
-	truncatedLog, _, _ := c.tokenCounter.TruncateContent(logOutput)
+// RewriteCommitMessage rewrites a commit message so it follows the configured convention,
+// using the commit's changes and the lint issues found
+func (c *Client) RewriteCommitMessage(ctx context.Context, message, issues, diffStat string) (string, error) {
+	truncatedDiffStat, _ := c.tokenCounter.TruncateToWordLimit(diffStat, c.maxInputWords()/2)
 
-	prompt := fmt.Sprintf(`Analyze the following git log (%s) and provide insights:
+	prompt := fmt.Sprintf(`Rewrite this commit message so it follows %s and fixes the issues listed. Keep the original meaning and any trailers (e.g. Signed-off-by, Co-authored-by, issue references).
 
 %s
+=== ORIGINAL MESSAGE ===
+%s
 
-Provide:
-1. **Activity Summary**: Overall development activity
-2. **Key Features**: Major features or changes
-3. **Bug Fixes**: Important fixes
-4. **Contributors**: Active contributors and their focus areas
-5. **Patterns**: Development patterns, frequency, focus areas
-6. **Recommendations**: Suggestions for the project
+=== ISSUES ===
+%s
 
-Be concise but insightful.`, timeframe, truncatedLog)
+=== FILES CHANGED ===
+%s
+
+Respond with only the rewritten commit message, no explanations.`, c.convention.Spec, c.conventionSection(), message, issues, truncatedDiffStat)
 
-	return c.GenerateResponse(c.addLanguageInstruction(prompt))
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
 }
 
-// AnalyzeLogStream generates insights from the git log with streaming
-func (c *Client) AnalyzeLogStream(logOutput, timeframe string) (string, error) {
-	// Apply word limiting to log output
-	truncatedLog, wordCount, wasTruncated := c.tokenCounter.TruncateContent(logOutput)
+// SummarizePush summarizes the commits about to be pushed and points out anything a
+// reviewer should double-check before they leave the machine
+func (c *Client) SummarizePush(ctx context.Context, destination, commits, diffStat, risks string) (string, error) {
+	truncatedCommits, _ := c.tokenCounter.TruncateToWordLimit(commits, c.maxInputWords()/2)
+	truncatedDiffStat, _ := c.tokenCounter.TruncateToWordLimit(diffStat, c.maxInputWords()/4)
 
-	if wasTruncated {
-		fmt.Printf("📊 Log analysis: %d words (truncated from %d words)\n", wordCount, c.tokenCounter.CountWords(logOutput))
-	} else {
-		fmt.Printf("📊 Log analysis: %d words\n", wordCount)
+	if risks == "" {
+		risks = "None detected"
 	}
 
-	prompt := fmt.Sprintf(`Analyze the following git log (%s) and provide detailed insights:
+	prompt := fmt.Sprintf(`Summarize the commits that are about to be pushed to %s, for a developer doing a final check before pushing.
 
+=== COMMITS ===
 %s
 
-DEVELOPMENT ANALYSIS - Provide comprehensive insights:
+=== FILES CHANGED ===
+%s
 
-1. **📊 Activity Summary**: 
-   - Overall development velocity and patterns
-   - Peak activity periods and quiet phases
-   - Commit frequency and distribution
+=== AUTOMATED SAFETY CHECKS ===
+%s
 
-2. **🚀 Key Features & Improvements**:
-   - Major features implemented
-   - Significant improvements made
-   - New capabilities added
+Provide:
+1. A one-line overview of what this push delivers
+2. 3-6 bullet points covering the main changes
+3. A short "Double-check" list of anything risky you notice (debug code, unfinished work, unexpected files, large or generated files, migrations, config changes); write "Nothing notable" if there is none
 
-3. **🐛 Bug Fixes & Maintenance**:
-   - Critical fixes applied
-   - Performance improvements
-   - Security enhancements
+Be concise - this is shown right before a confirmation prompt.`, destination, truncatedCommits, truncatedDiffStat, risks)
 
-4. **👥 Contributor Insights**:
-   - Active contributors and their focus areas
-   - Collaboration patterns
-   - Expertise distribution
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
 
-5. **🔍 Development Patterns**:
-   - Coding practices and conventions
-   - Testing and documentation habits
-   - Release and deployment patterns
+// ResolveConflictHunk proposes a resolution for a single conflicted hunk. The response
+// contains an EXPLANATION line followed by the resolved code in a RESOLUTION block.
+func (c *Client) ResolveConflictHunk(ctx context.Context, file, hunk, operationContext string) (string, error) {
+	truncatedHunk, _, _ := c.truncateContent(hunk)
 
-6. **💡 Recommendations**:
-   - Areas for improvement
-   - Suggested next steps
-   - Technical debt considerations
+	prompt := fmt.Sprintf(`You are resolving a git conflict in %s.
 
-Be insightful and actionable. Focus on trends, patterns, and meaningful observations.`, timeframe, truncatedLog)
+%s
 
-	return c.GenerateResponseStream(c.addLanguageInstruction(prompt))
+The conflicted hunk is shown below with surrounding context. The section between <<<<<<< and ======= is "ours", the section between ======= and >>>>>>> is "theirs" (a ||||||| section, if present, is the common ancestor).
+
+%s
+
+Propose the code that should replace the whole conflict block, from the <<<<<<< line through the >>>>>>> line:
+- Keep the intent of both sides when they make independent changes
+- Prefer the side that supersedes the other when they change the same thing
+- Do not include conflict markers or the surrounding context lines
+- Preserve the file's indentation and style
+
+Respond in exactly this format:
+EXPLANATION: <one or two sentences on what each side changed and why this resolution is correct>
+RESOLUTION:
+`+"```"+`
+<resolved code>
+`+"```"+`
+
+Keep the EXPLANATION and RESOLUTION labels in English and the code unchanged in any language.`, file, operationContext, truncatedHunk)
+
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
 }
 
-// SummarizeDiffStream generates a summary of the git diff with streaming
-func (c *Client) SummarizeDiffStream(diff string) (string, error) {
-	// Apply word limiting to diff content
-	truncatedDiff, wordCount, wasTruncated := c.tokenCounter.TruncateContent(diff)
+// GeneratePullRequest generates a pull request title and markdown body from a branch's changes
+func (c *Client) GeneratePullRequest(ctx context.Context, branch, base, commits, fileList, diff string) (string, error) {
+	prompt, err := c.fitPrompt(commitSections(c.prepareDiff(diff), branch, commits, fileList), func(texts []string) (string, error) {
+		return fmt.Sprintf(`You are an expert software developer writing a pull request for code review.
 
-	if wasTruncated {
-		fmt.Printf("📊 Diff analysis: %d words (truncated from %d words)\n", wordCount, c.tokenCounter.CountWords(diff))
-	} else {
-		fmt.Printf("📊 Diff analysis: %d words\n", wordCount)
-	}
+Branch '%s' is being merged into '%s'.
+
+=== COMMITS ===
+%s
 
-	prompt := fmt.Sprintf(`Analyze the following git diff and provide a comprehensive, structured summary:
+=== FILES CHANGED ===
+%s
 
+=== GIT DIFF ===
 %s
 
-CHANGE ANALYSIS - Provide detailed insights:
+Write a pull request title and description that help reviewers understand the change quickly.
+
+Requirements:
+1. The title is a single line under 72 characters, in imperative mood
+2. The body is GitHub-flavored markdown with these sections:
+   ## Summary
+   (2-4 sentences on what this PR does and why)
+   ## Changes
+   (bullet list of the notable changes, grouped logically)
+   ## Testing
+   (how the change was or should be tested; mention gaps honestly)
+3. Mention breaking changes or migration steps under a "## Breaking Changes" section only if applicable
+4. Do not invent issue numbers, links, or test results
+
+Respond in exactly this format, with no other text:
+TITLE: <title>
+BODY:
+<markdown body>`, texts[1], base, texts[2], texts[3], texts[0]), nil
+	})
+	if err != nil {
+		return "", err
+	}
 
-1. **📋 Summary**: 
-   - High-level overview of what changed
-   - Primary purpose and intention of changes
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
 
-2. **📁 Files & Components**:
-   - Main files modified, added, or removed
-   - Components and modules affected
-   - Architecture areas impacted
+// SuggestBranchNames suggests git branch names for a description of the planned work
+func (c *Client) SuggestBranchNames(ctx context.Context, description, conventions string, count int) (string, error) {
+	prompt := fmt.Sprintf(`You are an expert software developer who names git branches clearly and consistently.
 
-3. **🔄 Type of Changes**:
-   - New features implemented
-   - Bug fixes applied  
-   - Refactoring and improvements
-   - Configuration or documentation updates
+Suggest %d branch names for the following work:
 
-4. **⚡ Impact Assessment**:
-   - Functional changes and new capabilities
-   - Performance implications
-   - User experience impacts
-   - Developer experience changes
+%s
 
-5. **🎯 Technical Details**:
-   - Key algorithms or logic changes
-   - API modifications
-   - Database or schema changes
-   - Dependencies added or updated
+Branch naming conventions for this repository:
+%s
 
-6. **⚠️ Important Notes**:
-   - Breaking changes (if any)
-   - Migration requirements
-   - Testing considerations
-   - Deployment implications
+Rules:
+1. Use only lowercase letters, digits, '-', '/' and '.'
+2. Keep each name under 50 characters
+3. Make names descriptive of the intent, not generic ("fix/token-refresh-race", not "fix/bug")
+4. Order suggestions from best to worst
 
-Be thorough yet concise. Focus on what matters most for understanding the change.`, truncatedDiff)
+Respond with only the branch names, one per line, with no numbering or explanations.`, count, description, conventions)
 
-	return c.GenerateResponseStream(c.addLanguageInstruction(prompt))
+	return c.GenerateResponse(ctx, prompt)
 }
 
-// AnalyzeMergeConflicts provides guidance for resolving merge conflicts
-func (c *Client) AnalyzeMergeConflicts(conflictFiles string) (string, error) {
-	prompt := fmt.Sprintf(`Analyze the following merge conflict information and provide resolution guidance:
+// JudgeSecretFindings asks the model whether regex-detected secret candidates are real credentials.
+// The findings should already be masked; raw secret values must never be sent to the API.
+func (c *Client) JudgeSecretFindings(ctx context.Context, findings string) (string, error) {
+	prompt := fmt.Sprintf(`You are a security reviewer checking staged code changes for leaked credentials before a git commit.
+
+A regex scanner flagged the following lines. Secret values are partially masked with '*'.
 
 %s
 
-Provide:
-1. **Conflict Summary**: What files have conflicts and why
-2. **Resolution Strategy**: Recommended approach for resolving
-3. **Risk Assessment**: Potential risks of different resolution approaches
-4. **Testing Recommendations**: What to test after resolution
-5. **Prevention**: How to avoid similar conflicts in the future
+For each numbered finding decide whether it is likely a REAL secret (API key, token, private key, password)
+or a FALSE POSITIVE (placeholder, example value, test fixture, environment variable reference, hash, documentation).
+
+Respond with exactly one line per finding, in this format:
+<number>: SECRET - <brief reason>
+<number>: SAFE - <brief reason>
 
-Be practical and actionable.`, conflictFiles)
+Keep each reason under 60 characters. Do not add any other text.`, findings)
 
-	return c.GenerateResponse(c.addLanguageInstruction(prompt))
+	return c.GenerateResponse(ctx, prompt)
 }
 
 // GenerateMergeCommitMessage generates a comprehensive merge commit message
-func (c *Client) GenerateMergeCommitMessage(sourceBranch, targetBranch, changes string) (string, error) {
+func (c *Client) GenerateMergeCommitMessage(ctx context.Context, sourceBranch, targetBranch, changes string) (string, error) {
 	// Apply word limiting to changes content
-	truncatedChanges, _, _ := c.tokenCounter.TruncateContent(changes)
+	truncatedChanges, _, _ := c.truncateContent(changes)
 
 	prompt := fmt.Sprintf(`Generate a comprehensive merge commit message for merging '%s' into '%s'.
 
@@ -574,44 +479,47 @@ Changes being merged:
 Create a merge commit message that:
 1. Clearly states what is being merged
 2. Summarizes the key changes/features
-3. Follows conventional commit format if appropriate
+3. Follows %s where it fits a merge commit
 4. Mentions any important notes about the merge
 
-Format as a proper merge commit message.`, sourceBranch, targetBranch, truncatedChanges)
+Format as a proper merge commit message.`, sourceBranch, targetBranch, truncatedChanges, c.convention.Spec)
+
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
+
+// ActivityFunc is called when a non-streaming request is sent, e.g. to show a spinner;
+// the returned func is called when the response has arrived
+type ActivityFunc func() (done func())
 
-	return c.GenerateResponse(c.addLanguageInstruction(prompt))
+// SetActivityFunc registers a callback for the duration of non-streaming requests.
+// Streaming callers see progress through their chunk callback instead.
+func (c *Client) SetActivityFunc(onActivity ActivityFunc) {
+	c.onActivity = onActivity
 }
 
 // GenerateResponse sends a prompt to Solar LLM and returns the response
-func (c *Client) GenerateResponse(prompt string) (string, error) {
-	request := ChatRequest{
-		Model: c.modelName,
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Stream: false,
+func (c *Client) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	request := c.newChatRequest([]Message{{Role: "user", Content: prompt}}, false)
+	if c.dryRun != nil {
+		c.dryRun(request)
+		return "", ErrDryRun
 	}
-
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		return "", fmt.Errorf("error marshaling request: %v", err)
+	if c.cache != nil {
+		if cached, ok := c.cache.Get(cacheKey(request)); ok {
+			logging.Info("api response from cache", "response_tokens", c.tokenCounter.EstimateTokens(cached))
+			return cached, nil
+		}
 	}
 
-	req, err := http.NewRequest("POST", c.baseURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("error creating request: %v", err)
+	if c.onActivity != nil {
+		done := c.onActivity()
+		defer done()
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	start := time.Now()
+	resp, err := c.doWithRetry(ctx, request)
 	if err != nil {
-		return "", fmt.Errorf("error making request: %v", err)
+		return "", err
 	}
 	defer resp.Body.Close()
 
@@ -621,116 +529,92 @@ func (c *Client) GenerateResponse(prompt string) (string, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var response ChatResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("error unmarshaling response: %v", err)
+		return "", newAPIError(resp, body)
 	}
 
-	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no response choices returned")
+	content, err := c.api().parseResponse(body)
+	if err != nil {
+		return "", err
 	}
 
-	content := response.Choices[0].Message.Content
-
 	// Clean up the response by removing any <think>...</think> tags
-	content = cleanResponse(content)
-
-	return strings.TrimSpace(content), nil
-}
-
-// GenerateResponseStream sends a prompt to Solar LLM and returns the streaming response
-func (c *Client) GenerateResponseStream(prompt string) (string, error) {
-	request := ChatRequest{
-		Model: c.modelName,
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Stream: true,
-	}
+	content = strings.TrimSpace(cleanResponse(content))
+	logging.Info("api response", "duration", time.Since(start), "response_tokens", c.tokenCounter.EstimateTokens(content))
 
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		return "", fmt.Errorf("error marshaling request: %v", err)
+	if c.cache != nil && content != "" {
+		c.cache.Put(cacheKey(request), content)
 	}
 
-	req, err := http.NewRequest("POST", c.baseURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("error creating request: %v", err)
-	}
+	return content, nil
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+// StreamResponse sends a prompt to Solar LLM and calls onChunk with each piece of
+// content as it arrives. It returns the full cleaned-up response.
+func (c *Client) StreamResponse(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	return c.StreamChat(ctx, []Message{{Role: "user", Content: prompt}}, onChunk)
+}
 
-	// Start spinner while waiting for response
-	spinner := NewSpinner()
-	spinner.Start("Thinking...")
+// StreamChat continues a multi-turn conversation: messages holds the user and
+// assistant turns so far, ending with a user turn. Chunks of the reply are passed to
+// onChunk as they arrive, and the full cleaned-up reply is returned.
+func (c *Client) StreamChat(ctx context.Context, messages []Message, onChunk func(string)) (string, error) {
+	request := c.newChatRequest(messages, true)
+	if c.dryRun != nil {
+		c.dryRun(request)
+		return "", ErrDryRun
+	}
+	if c.cache != nil {
+		if cached, ok := c.cache.Get(cacheKey(request)); ok {
+			logging.Info("api response from cache", "response_tokens", c.tokenCounter.EstimateTokens(cached))
+			onChunk(cached)
+			return cached, nil
+		}
+	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	start := time.Now()
+	resp, err := c.doWithRetry(ctx, request)
 	if err != nil {
-		spinner.Stop()
-		return "", fmt.Errorf("error making request: %v", err)
+		return "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		spinner.Stop()
 		body, _ := ioutil.ReadAll(resp.Body)
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return "", newAPIError(resp, body)
 	}
 
 	var fullContent strings.Builder
-	scanner := bufio.NewScanner(resp.Body)
-	firstChunk := true
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" || !strings.HasPrefix(line, "data: ") {
-			continue
-		}
-
-		// Remove "data: " prefix
-		data := strings.TrimPrefix(line, "data: ")
-		if data == "[DONE]" {
+	var firstChunk time.Duration
+	stream := c.api().newStream(resp.Body, c.streamBufferSize, c.maxStreamEventSize)
+	for {
+		content, err := stream.Next()
+		if err == io.EOF {
 			break
 		}
-
-		var streamResp StreamResponse
-		if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
-			continue // Skip invalid JSON lines
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			return "", err
 		}
-
-		if len(streamResp.Choices) > 0 && streamResp.Choices[0].Delta.Content != "" {
-			// Stop spinner on first content chunk and start printing
-			if firstChunk {
-				spinner.Stop()
-				fmt.Print("Generated commit message: ")
-				firstChunk = false
+		if content != "" {
+			if firstChunk == 0 {
+				firstChunk = time.Since(start)
 			}
-
-			content := streamResp.Choices[0].Delta.Content
-			fmt.Print(content) // Print streaming content immediately
+			onChunk(content)
 			fullContent.WriteString(content)
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("error reading stream: %v", err)
-	}
-
-	fmt.Println() // Add newline after streaming
-
-	finalContent := fullContent.String()
 	// Clean up the response by removing any <think>...</think> tags
-	finalContent = cleanResponse(finalContent)
+	finalContent := strings.TrimSpace(cleanResponse(fullContent.String()))
+	logging.Info("api response", "duration", time.Since(start), "first_chunk", firstChunk, "response_tokens", c.tokenCounter.EstimateTokens(finalContent))
+
+	if c.cache != nil && finalContent != "" {
+		c.cache.Put(cacheKey(request), finalContent)
+	}
 
-	return strings.TrimSpace(finalContent), nil
+	return finalContent, nil
 }
 
 // cleanResponse removes <think>...</think> blocks from the AI response.
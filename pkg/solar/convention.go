@@ -0,0 +1,312 @@
+package solar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/gitmoji"
+)
+
+// Convention describes a commit message style the prompts should follow
+type Convention struct {
+	// Name is the identifier used in config (e.g. "conventional")
+	Name string
+	// Spec is a short description used in prompt introductions
+	Spec string
+	// Rules lists the formatting rules specific to this convention
+	Rules string
+	// Format shows the expected layout of the message
+	Format string
+	// Examples are sample subject lines following the convention
+	Examples string
+}
+
+// DefaultConvention is used when no convention is configured
+const DefaultConvention = "conventional"
+
+// Conventions contains the built-in commit message style profiles
+var Conventions = map[string]Convention{
+	"conventional": {
+		Name: "conventional",
+		Spec: "the Conventional Commits specification",
+		Rules: `- Use conventional commit format: type(scope): description
+- Types: feat, fix, docs, style, refactor, test, chore, perf, ci, build
+- Include scope if relevant (e.g., auth, api, ui, db)`,
+		Format: `type(scope): intention-focused summary that explains WHY
+
+Brief explanation of the purpose and impact of this change.
+Focus on the problem solved or improvement made, not just what files changed.
+
+BREAKING CHANGE: description if applicable (only if truly breaking)`,
+		Examples: `- feat(auth): add OAuth2 integration
+- fix(api): handle null pointer in user service
+- docs: update installation instructions
+- refactor(db): optimize query performance`,
+	},
+	"angular": {
+		Name: "angular",
+		Spec: "the Angular commit message guidelines",
+		Rules: `- Header format: <type>(<scope>): <short summary>
+- Types: build, ci, docs, feat, fix, perf, refactor, test
+- Scope is the name of the affected package or component
+- Summary is lowercase, has no period at the end, and uses present tense
+- The body is mandatory (except for docs) and explains the motivation for the change
+- Footer holds "BREAKING CHANGE: <description>" and issue references such as "Closes #123"`,
+		Format: `<type>(<scope>): <short summary>
+
+<body explaining the motivation for the change and contrasting new with previous behavior>
+
+<footer: BREAKING CHANGE and issue references, only if applicable>`,
+		Examples: `- feat(router): add support for lazy-loaded modules
+- fix(http): prevent duplicate requests on retry
+- perf(core): avoid recomputing change detection for static nodes
+- docs(changelog): update release notes for 1.2.0`,
+	},
+	"gitmoji": {
+		Name: "gitmoji",
+		Spec: "the gitmoji commit convention (https://gitmoji.dev)",
+		Rules: `- Start the subject line with exactly one gitmoji that matches the intent of the change
+- Common gitmojis: ✨ new feature, 🐛 bug fix, 📝 documentation, ♻️ refactor, ⚡️ performance,
+  ✅ tests, 🔧 configuration, 🔥 remove code or files, 💄 UI and style, 🔒️ security,
+  ⬆️ upgrade dependencies, 🎨 improve structure or format, 🚀 deploy, 🚑️ critical hotfix
+- Follow the emoji with a short capitalized summary in imperative mood`,
+		Format: `<gitmoji> Intention-focused summary that explains WHY
+
+Brief explanation of the purpose and impact of this change.
+
+💥 BREAKING CHANGE: description if applicable (only if truly breaking)`,
+		Examples: `- ✨ Add OAuth2 login with Google
+- 🐛 Handle null pointer in user service
+- 📝 Update installation instructions
+- ♻️ Simplify query builder`,
+	},
+}
+
+// ConventionNames returns the names of all built-in conventions plus "custom"
+func ConventionNames() []string {
+	names := make([]string, 0, len(Conventions)+1)
+	for name := range Conventions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append(names, "custom")
+}
+
+// CustomConvention builds a convention from user-supplied guidelines
+func CustomConvention(guidelines string) Convention {
+	return Convention{
+		Name:  "custom",
+		Spec:  "the team's commit message guidelines below",
+		Rules: strings.TrimSpace(guidelines),
+	}
+}
+
+// SetConvention selects the commit message convention used by the commit prompts.
+// For the "custom" convention, customGuidelines holds the user's guideline text.
+func (c *Client) SetConvention(name, customGuidelines string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DefaultConvention
+	}
+
+	if name == "custom" {
+		if strings.TrimSpace(customGuidelines) == "" {
+			return fmt.Errorf("custom convention requires a non-empty guidelines template")
+		}
+		c.convention = CustomConvention(customGuidelines)
+		return nil
+	}
+
+	convention, ok := Conventions[name]
+	if !ok {
+		return fmt.Errorf("unknown commit convention '%s' (available: %s)", name, strings.Join(ConventionNames(), ", "))
+	}
+	c.convention = convention
+	return nil
+}
+
+// conventionSection renders the convention rules, format, and examples for a prompt
+func (c *Client) conventionSection() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "COMMIT CONVENTION (%s):\n%s\n", c.convention.Spec, c.convention.Rules)
+	if c.convention.Format != "" {
+		fmt.Fprintf(&b, "\nFormat:\n%s\n", c.convention.Format)
+	}
+	if c.convention.Examples != "" {
+		fmt.Fprintf(&b, "\nExamples:\n%s\n", c.convention.Examples)
+	}
+	if len(c.commitTypes) > 0 {
+		fmt.Fprintf(&b, "\nALLOWED TYPES: this repository only uses these commit types: %s\n", strings.Join(c.commitTypes, ", "))
+	}
+	if len(c.commitScopes) > 0 {
+		fmt.Fprintf(&b, "\nALLOWED SCOPES: this repository only uses these scopes: %s. Pick the one that fits the change, or leave the scope out when none does; never invent another\n", strings.Join(c.commitScopes, ", "))
+	}
+	if c.gitmoji && c.convention.Name != "gitmoji" {
+		fmt.Fprintf(&b, "\nGITMOJI: Prefix the subject line with exactly one gitmoji (https://gitmoji.dev) mapped from the change type, followed by a space and the subject as described above:\n%s", gitmoji.TypeMapping())
+		b.WriteString("Use the emoji itself, not a :shortcode:. Example: ✨ feat(auth): add OAuth2 integration\n")
+	}
+	return b.String()
+}
+
+// SetCommitTypes limits the commit types the prompts allow to a repository's own
+// list; an empty list keeps the convention's types
+func (c *Client) SetCommitTypes(types []string) {
+	c.commitTypes = nil
+	for _, t := range types {
+		if t = strings.TrimSpace(t); t != "" {
+			c.commitTypes = append(c.commitTypes, t)
+		}
+	}
+}
+
+// SetCommitScopes limits the scopes the prompts allow to a repository's own list; an
+// empty list allows any scope
+func (c *Client) SetCommitScopes(scopes []string) {
+	c.commitScopes = nil
+	for _, s := range scopes {
+		if s = strings.TrimSpace(s); s != "" {
+			c.commitScopes = append(c.commitScopes, s)
+		}
+	}
+}
+
+// SetCorrection makes the next commit message prompts point out the problems found
+// in a previous attempt so the model fixes them. No problems clears the correction.
+func (c *Client) SetCorrection(previous string, problems []string) {
+	if len(problems) == 0 {
+		c.correction = ""
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("\nYOUR PREVIOUS ATTEMPT WAS REJECTED:\n")
+	fmt.Fprintf(&b, "  %s\n", strings.ReplaceAll(strings.TrimSpace(previous), "\n", "\n  "))
+	b.WriteString("It has these problems, which the new message must not have:\n")
+	for _, problem := range problems {
+		fmt.Fprintf(&b, "- %s\n", problem)
+	}
+	c.correction = b.String()
+}
+
+// correctionSection renders the problems set with SetCorrection, or ""
+func (c *Client) correctionSection() string {
+	return c.correction
+}
+
+// SetGitmoji enables prefixing generated commit subjects with the gitmoji mapped
+// from the change type, on top of the selected convention
+func (c *Client) SetGitmoji(enabled bool) {
+	c.gitmoji = enabled
+}
+
+// CommitHints carries what the developer already knows about a change, so the
+// generated commit message respects it instead of guessing
+type CommitHints struct {
+	// Type is the commit type to use (e.g. fix, feat)
+	Type string
+	// Scope is the commit scope to use (e.g. auth)
+	Scope string
+	// Hint is a free-form description of the change's intent
+	Hint string
+	// TicketKey is the ticket the change belongs to (e.g. PROJ-123)
+	TicketKey string
+	// TicketTitle and TicketDescription describe the ticket, when it could be fetched
+	TicketTitle       string
+	TicketDescription string
+	// AmendedMessage is the current message of the commit being amended
+	AmendedMessage string
+	// APIChanges lists the public symbols the change removes or changes, which may
+	// break callers
+	APIChanges string
+}
+
+// SetCommitHints sets the hints injected into the commit message prompts
+func (c *Client) SetCommitHints(hints CommitHints) {
+	c.hints = CommitHints{
+		Type:              strings.TrimSpace(hints.Type),
+		Scope:             strings.TrimSpace(hints.Scope),
+		Hint:              strings.TrimSpace(hints.Hint),
+		TicketKey:         strings.TrimSpace(hints.TicketKey),
+		TicketTitle:       strings.TrimSpace(hints.TicketTitle),
+		TicketDescription: strings.TrimSpace(hints.TicketDescription),
+		AmendedMessage:    strings.TrimSpace(hints.AmendedMessage),
+		APIChanges:        strings.TrimSpace(hints.APIChanges),
+	}
+}
+
+// ContextSection is context from a source other than the diff and the repository,
+// such as build or test results, shown to the model under its title
+type ContextSection struct {
+	Title string
+	Text  string
+}
+
+// maxContextSectionWords bounds each context section in a prompt
+const maxContextSectionWords = 300
+
+// SetContextSections sets the context sections added to the commit message prompts;
+// sections without text are left out
+func (c *Client) SetContextSections(sections []ContextSection) {
+	c.sections = nil
+	for _, section := range sections {
+		title, text := strings.TrimSpace(section.Title), strings.TrimSpace(section.Text)
+		if title != "" && text != "" {
+			c.sections = append(c.sections, ContextSection{Title: title, Text: text})
+		}
+	}
+}
+
+// contextSections renders the context sections for a prompt, each starting with a
+// blank line, or "" when there are none
+func (c *Client) contextSections() string {
+	var b strings.Builder
+	for _, section := range c.sections {
+		text, _ := c.tokenCounter.TruncateToWordLimit(section.Text, maxContextSectionWords)
+		fmt.Fprintf(&b, "\n=== %s ===\n%s\n", strings.ToUpper(section.Title), text)
+	}
+	return b.String()
+}
+
+// hintsSection renders the developer's hints for a prompt, or "" when there are none
+func (c *Client) hintsSection() string {
+	if c.hints == (CommitHints{}) {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\nDEVELOPER GUIDANCE (takes precedence over your own analysis):\n")
+	if c.hints.Type != "" {
+		fmt.Fprintf(&b, "- Use the commit type '%s'\n", c.hints.Type)
+	}
+	if c.hints.Scope != "" {
+		fmt.Fprintf(&b, "- Use the scope '%s'\n", c.hints.Scope)
+	}
+	if c.hints.Hint != "" {
+		fmt.Fprintf(&b, "- The developer describes the change as: %s\n", c.hints.Hint)
+	}
+	if c.hints.TicketKey != "" {
+		if c.hints.TicketTitle != "" {
+			fmt.Fprintf(&b, "- The change is part of ticket %s: %s\n", c.hints.TicketKey, c.hints.TicketTitle)
+		} else {
+			fmt.Fprintf(&b, "- The change is part of ticket %s\n", c.hints.TicketKey)
+		}
+		if c.hints.TicketDescription != "" {
+			description, _ := c.tokenCounter.TruncateToWordLimit(c.hints.TicketDescription, 300)
+			fmt.Fprintf(&b, "  Ticket description:\n  %s\n", strings.ReplaceAll(description, "\n", "\n  "))
+		}
+		b.WriteString("- Use the ticket to explain why the change was made, but describe what the diff actually does\n")
+		fmt.Fprintf(&b, "- Don't add the ticket key or other ticket references yourself; a footer referencing %s is appended automatically\n", c.hints.TicketKey)
+	}
+	if c.hints.AmendedMessage != "" {
+		message, _ := c.tokenCounter.TruncateToWordLimit(c.hints.AmendedMessage, 300)
+		fmt.Fprintf(&b, "- The last commit is being amended; its current message is:\n  %s\n", strings.ReplaceAll(message, "\n", "\n  "))
+		b.WriteString("- The diff covers that commit together with the newly staged changes; write one message describing all of it, keeping what still applies from the current message\n")
+	}
+	if c.hints.APIChanges != "" {
+		changes, _ := c.tokenCounter.TruncateToWordLimit(c.hints.APIChanges, 400)
+		fmt.Fprintf(&b, "- The change removes or changes these public API symbols:\n  %s\n", strings.ReplaceAll(changes, "\n", "\n  "))
+		b.WriteString("- If any of them breaks existing callers, mark the commit as breaking the way the convention does (e.g. \"!\" after the type) and end the message with a \"BREAKING CHANGE: <what breaks and how to migrate>\" footer; otherwise don't mention them as breaking\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// critiqueScorePattern reads a score line such as "ACCURACY: 7/10"
+var critiqueScorePattern = regexp.MustCompile(`^(ACCURACY|COMPLETENESS|CONVENTION)\s*:\s*(\d+)`)
+
+// CommitCritique is a second opinion on a generated commit message, each score from
+// 1 to 10
+type CommitCritique struct {
+	// Accuracy is whether the message only claims what the diff does, with the right
+	// type and scope
+	Accuracy int
+	// Completeness is whether the message covers the important parts of the change
+	Completeness int
+	// Convention is how well the message follows the commit convention
+	Convention int
+	// Problems are the concrete things to fix
+	Problems []string
+}
+
+// Score is the critique's overall score: the lowest of its three scores, since a
+// message that is wrong in one way is not made right by the others
+func (c CommitCritique) Score() int {
+	return min(c.Accuracy, c.Completeness, c.Convention)
+}
+
+func (c CommitCritique) String() string {
+	return fmt.Sprintf("accuracy %d/10, completeness %d/10, convention %d/10", c.Accuracy, c.Completeness, c.Convention)
+}
+
+// CritiqueCommitMessage scores a generated commit message against the diff it
+// describes, for a second pass that catches invented scopes, wrong types and
+// missing changes
+func (c *Client) CritiqueCommitMessage(ctx context.Context, message, diff, fileList string) (CommitCritique, error) {
+	truncatedDiff, _ := c.tokenCounter.TruncateToWordLimit(c.prepareDiff(diff), c.maxInputWords()*3/4)
+
+	prompt := fmt.Sprintf(`You are reviewing a commit message written for the change below. Check it strictly
+against the diff; do not rewrite it.
+
+=== COMMIT MESSAGE ===
+%s
+
+=== CHANGED FILES ===
+%s
+
+=== DIFF ===
+%s
+%s%s
+Score the message from 1 to 10 on:
+- ACCURACY: everything it claims is in the diff; the type matches the kind of change
+  (a fix is not a feat, a refactor changes no behavior); the scope names the part of
+  the code that actually changed
+- COMPLETENESS: the subject captures the main change and the body mentions every
+  significant part of it, without padding
+- CONVENTION: it follows the commit convention and guidance above
+
+Respond in exactly this format, keeping the labels in English:
+ACCURACY: <1-10>
+COMPLETENESS: <1-10>
+CONVENTION: <1-10>
+PROBLEMS:
+- <one concrete problem per line, or "none">`, strings.TrimSpace(message), fileList, truncatedDiff, c.conventionSection(), c.hintsSection())
+
+	response, err := c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+	if err != nil {
+		return CommitCritique{}, err
+	}
+	return parseCommitCritique(response)
+}
+
+// parseCommitCritique reads the scores and problems from the critique response
+func parseCommitCritique(response string) (CommitCritique, error) {
+	critique := CommitCritique{}
+	scores := 0
+	inProblems := false
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(strings.ReplaceAll(line, "*", ""))
+		if match := critiqueScorePattern.FindStringSubmatch(strings.ToUpper(trimmed)); match != nil {
+			score, _ := strconv.Atoi(match[2])
+			score = max(1, min(score, 10))
+			switch match[1] {
+			case "ACCURACY":
+				critique.Accuracy = score
+			case "COMPLETENESS":
+				critique.Completeness = score
+			case "CONVENTION":
+				critique.Convention = score
+			}
+			scores++
+			continue
+		}
+		if strings.HasPrefix(strings.ToUpper(trimmed), "PROBLEMS") {
+			inProblems = true
+			continue
+		}
+		if !inProblems {
+			continue
+		}
+		problem := strings.TrimSpace(strings.TrimLeft(trimmed, "-•"))
+		if problem != "" && !strings.EqualFold(strings.Trim(problem, "."), "none") {
+			critique.Problems = append(critique.Problems, problem)
+		}
+	}
+	if scores < 3 {
+		return CommitCritique{}, fmt.Errorf("could not read the critique scores from the response")
+	}
+	return critique, nil
+}
@@ -0,0 +1,16 @@
+package solar
+
+import "errors"
+
+// ErrDryRun is returned instead of a response when the client is in dry-run mode
+var ErrDryRun = errors.New("dry run: the prompt was not sent")
+
+// PromptInspector is shown each request, as it would be sent, in dry-run mode
+type PromptInspector func(request ChatRequest)
+
+// SetDryRun puts the client in dry-run mode: requests are passed to inspect, fully
+// rendered (truncated, with the system message and language instruction), instead of
+// being sent, and ErrDryRun is returned. A nil inspect sends requests again.
+func (c *Client) SetDryRun(inspect PromptInspector) {
+	c.dryRun = inspect
+}
@@ -0,0 +1,128 @@
+package solar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Kinds of API failure, matched with errors.Is so callers can suggest a fix
+var (
+	// ErrAuth means the API key is missing, invalid or not allowed to use the model
+	ErrAuth = errors.New("authentication failed")
+	// ErrRateLimited means the API is rejecting requests until later
+	ErrRateLimited = errors.New("rate limited")
+	// ErrContextTooLarge means the prompt doesn't fit in the model's context window
+	ErrContextTooLarge = errors.New("request too large for the model's context window")
+	// ErrNetwork means the API couldn't be reached at all
+	ErrNetwork = errors.New("network error")
+)
+
+// contextTooLargeMessage matches the ways APIs say a prompt is too long
+var contextTooLargeMessage = regexp.MustCompile(`(?i)context[_ ]length|context window|maximum context|too many tokens|token limit|prompt is too long|input is too long|reduce the length`)
+
+// APIError is an error response from the API. Its kind (ErrAuth, ErrRateLimited,
+// ErrContextTooLarge, or none) is available through errors.Is.
+type APIError struct {
+	// StatusCode is the HTTP status, or 0 for an error reported in a stream
+	StatusCode int
+	// Message is the API's error message, or the start of the response body
+	Message string
+	// RetryAfter is how long a rate limited client should wait, when the API said
+	RetryAfter time.Duration
+
+	kind error
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode == 0 {
+		return "API error during streaming: " + e.Message
+	}
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns the kind of the error
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
+
+// newAPIError builds the error for a non-200 response
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	err := &APIError{StatusCode: resp.StatusCode, Message: apiErrorMessage(body)}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		err.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+	err.kind = classifyAPIError(resp.StatusCode, err.Message)
+	return err
+}
+
+// newStreamError builds the error for an error event in a stream
+func newStreamError(message string) *APIError {
+	return &APIError{Message: message, kind: classifyAPIError(0, message)}
+}
+
+// apiErrorMessage pulls the message out of an OpenAI-style error body
+// ({"error": {"message": ...}}), falling back to the start of the body
+func apiErrorMessage(body []byte) string {
+	var response struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+	}
+	if json.Unmarshal(body, &response) == nil {
+		var object streamError
+		var text string
+		switch {
+		case json.Unmarshal(response.Error, &object) == nil && object.Message != "":
+			if object.Type != "" {
+				return object.Type + ": " + object.Message
+			}
+			return object.Message
+		case json.Unmarshal(response.Error, &text) == nil && text != "":
+			return text
+		case response.Message != "":
+			return response.Message
+		}
+	}
+	return truncateForError(strings.TrimSpace(string(body)))
+}
+
+// classifyAPIError picks the kind of an API error from its status and message
+func classifyAPIError(status int, message string) error {
+	lower := strings.ToLower(message)
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrAuth
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status == http.StatusRequestEntityTooLarge || contextTooLargeMessage.MatchString(message):
+		return ErrContextTooLarge
+	// Errors in a stream have no status; go by what they say
+	case status == 0 && (strings.Contains(lower, "rate limit") || strings.Contains(lower, "rate_limit") || strings.Contains(lower, "throttl")):
+		return ErrRateLimited
+	case status == 0 && (strings.Contains(lower, "api key") || strings.Contains(lower, "unauthorized")):
+		return ErrAuth
+	}
+	return nil
+}
+
+// networkError is a request that never got a response
+type networkError struct {
+	err error
+}
+
+func (e *networkError) Error() string {
+	return fmt.Sprintf("error making request: %v", e.err)
+}
+
+func (e *networkError) Unwrap() error {
+	return e.err
+}
+
+// Is makes every networkError match ErrNetwork
+func (e *networkError) Is(target error) bool {
+	return target == ErrNetwork
+}
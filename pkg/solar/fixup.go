@@ -0,0 +1,35 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChooseFixupTarget picks the earlier commit a staged change fixes, for git commit
+// --fixup. candidates is a numbered list of commits with the files they touched and how
+// much of the staged change overlaps with them. The response has the form
+// "CHOICE: <candidate number>" and "WHY: ...".
+func (c *Client) ChooseFixupTarget(ctx context.Context, diff, candidates string) (string, error) {
+	truncatedDiff, _ := c.tokenCounter.TruncateToWordLimit(c.prepareDiff(diff), c.maxInputWords()/2)
+	truncatedCandidates, _ := c.tokenCounter.TruncateToWordLimit(candidates, c.maxInputWords()/4)
+
+	prompt := fmt.Sprintf(`A developer staged a small change that corrects an earlier commit on their branch and
+wants to turn it into a "fixup!" commit, to be squashed into that commit later.
+
+=== STAGED CHANGE ===
+%s
+
+=== CANDIDATE COMMITS (newest first) ===
+%s
+
+Pick the commit this change belongs to. The strongest evidence is a candidate that last
+touched the lines being changed; next, one that touched the same files. Use the commit
+subjects to break ties by meaning: the fix should complete or correct what that commit
+set out to do, not start something new.
+
+Respond in exactly this format, keeping the labels in English:
+CHOICE: <candidate number>
+WHY: <1 sentence>`, truncatedDiff, truncatedCandidates)
+
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
@@ -0,0 +1,88 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MaxHunkWords limits how much of a single hunk is sent for staging analysis
+const MaxHunkWords = 1500
+
+// HunkSample is one hunk of an unstaged diff and the file it belongs to
+type HunkSample struct {
+	Path string
+	Hunk string
+}
+
+// HunkDecision is the model's explanation of a hunk and whether to stage it
+type HunkDecision struct {
+	Stage       bool
+	Explanation string
+}
+
+// AnalyzeHunksForStaging explains each hunk in one line and recommends staging or
+// skipping it, batching all hunks into a single request. The result is indexed like
+// hunks; hunks missing from the response get no decision.
+func (c *Client) AnalyzeHunksForStaging(ctx context.Context, hunks []HunkSample) (map[int]HunkDecision, error) {
+	var b strings.Builder
+	for i, hunk := range hunks {
+		text, _ := c.tokenCounter.TruncateToWordLimit(hunk.Hunk, MaxHunkWords)
+		fmt.Fprintf(&b, "=== HUNK %d: %s ===\n%s\n\n", i+1, hunk.Path, text)
+	}
+
+	prompt := fmt.Sprintf(`You are helping a developer stage part of their working tree changes for a focused commit, hunk by hunk, like git add -p.
+
+Here are %d unstaged hunks:
+
+%s
+Decide for each hunk whether it belongs in the commit. Hunks that make up the main
+change being worked on, and changes they need (imports, tests, docs), should be staged.
+Recommend skipping:
+1. Debugging leftovers: print/log statements, commented-out code, TODO hacks
+2. Local-only changes: hard-coded paths, ports, credentials, feature toggles flipped on
+3. Unrelated edits that deserve their own commit
+4. Accidental changes: stray whitespace or formatting in otherwise untouched code
+
+Respond with exactly one line per hunk, in order, and nothing else:
+- "<hunk number>. STAGE: [what the hunk does]" if it should be staged
+- "<hunk number>. SKIP: [what the hunk does, and why to skip it]" if it should not
+
+Keep STAGE and SKIP in English and each explanation under 80 characters.`, len(hunks), b.String())
+
+	response, err := c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseHunkDecisions(response, len(hunks)), nil
+}
+
+// parseHunkDecisions maps "N. STAGE: ..." / "N. SKIP: ..." lines back to hunk indexes
+func parseHunkDecisions(response string, count int) map[int]HunkDecision {
+	decisions := make(map[int]HunkDecision)
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "*`"))
+
+		var index int
+		if n, err := fmt.Sscanf(line, "%d.", &index); n != 1 || err != nil {
+			continue
+		}
+		if index < 1 || index > count {
+			continue
+		}
+		rest := ""
+		if i := strings.Index(line, "."); i >= 0 {
+			rest = strings.TrimSpace(strings.Trim(line[i+1:], " *"))
+		}
+
+		upper := strings.ToUpper(rest)
+		switch {
+		case strings.HasPrefix(upper, "STAGE:"):
+			decisions[index-1] = HunkDecision{Stage: true, Explanation: strings.TrimSpace(rest[len("STAGE:"):])}
+		case strings.HasPrefix(upper, "SKIP:"):
+			decisions[index-1] = HunkDecision{Stage: false, Explanation: strings.TrimSpace(rest[len("SKIP:"):])}
+		}
+	}
+	return decisions
+}
@@ -0,0 +1,74 @@
+package solar
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// CommonLanguages are suggested by shell completion and sgit config. Any other BCP-47
+// code or language name works too.
+var CommonLanguages = []string{
+	"en", "ko", "ja", "zh", "zh-TW", "es", "fr", "de", "pt", "pt-BR", "vi",
+	"it", "ru", "id", "th", "hi", "ar", "tr", "nl", "pl", "uk",
+}
+
+// NormalizeLanguage turns a BCP-47 code ("pt-br") or a language name ("Portuguese",
+// "Tiếng Việt") into a canonical BCP-47 tag. Names that don't match a known language
+// are kept as given, so any language the model understands can be used.
+func NormalizeLanguage(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("empty language")
+	}
+
+	if tag, err := language.Parse(value); err == nil && tag != language.Und {
+		return tag.String(), nil
+	}
+
+	for _, tag := range display.Supported.Tags() {
+		if strings.EqualFold(display.English.Tags().Name(tag), value) || strings.EqualFold(display.Self.Name(tag), value) {
+			return tag.String(), nil
+		}
+	}
+
+	// A free-form name, e.g. "Klingon" or "Brazilian Portuguese, informal"
+	if len(value) > 40 {
+		return "", fmt.Errorf("invalid language '%s'", value)
+	}
+	hasLetter := false
+	for _, r := range value {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsSpace(r) || strings.ContainsRune("-(),.'", r):
+		default:
+			return "", fmt.Errorf("invalid language '%s'", value)
+		}
+	}
+	if !hasLetter {
+		return "", fmt.Errorf("invalid language '%s'", value)
+	}
+	return value, nil
+}
+
+// LanguageName describes a language for prompts and menus, e.g. "Portuguese (português)"
+// for pt. Free-form names are returned unchanged.
+func LanguageName(lang string) string {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return lang
+	}
+
+	name := display.English.Tags().Name(tag)
+	if name == "" {
+		return lang
+	}
+	if self := display.Self.Name(tag); self != "" && !strings.EqualFold(self, name) {
+		return fmt.Sprintf("%s (%s)", name, self)
+	}
+	return name
+}
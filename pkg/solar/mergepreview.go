@@ -0,0 +1,53 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// AssessMerge rates the risk of merging sourceBranch into targetBranch and recommends
+// how to integrate it, before anything is merged. preview is sgit's reading of a dry
+// run of the merge (how the branches diverged, whether it fast-forwards and which files
+// conflict), incoming and local list the commits only on sourceBranch and only on
+// targetBranch, and diffStat the files the merge changes. The response has the form
+// "RISK: low|medium|high", "STRATEGY: merge|rebase|squash", "WHY: ..." and "CHECK: ...".
+func (c *Client) AssessMerge(ctx context.Context, sourceBranch, targetBranch, preview, incoming, local, diffStat string) (string, error) {
+	truncatedIncoming, _ := c.tokenCounter.TruncateToWordLimit(incoming, c.maxInputWords()/3)
+	truncatedLocal, _ := c.tokenCounter.TruncateToWordLimit(local, c.maxInputWords()/6)
+	truncatedDiffStat, _ := c.tokenCounter.TruncateToWordLimit(diffStat, c.maxInputWords()/3)
+
+	prompt := fmt.Sprintf(`You are a git expert reviewing a merge before it is made. The developer wants to
+merge '%s' into '%s'; nothing has been merged yet.
+
+=== DRY-RUN MERGE ===
+%s
+
+=== COMMITS TO MERGE (only on %s) ===
+%s
+
+=== COMMITS ONLY ON %s SINCE THE BRANCHES DIVERGED ===
+%s
+
+=== FILES THE MERGE CHANGES ===
+%s
+
+Assess how risky the merge is and recommend how to integrate the branch:
+- merge: long-lived or shared branches, history worth keeping as it is, commits
+  others already built on
+- rebase: a short private branch whose commits are worth keeping one by one, when a
+  linear history is wanted or conflicts are easier to resolve commit by commit
+- squash: many small work-in-progress or fixup commits that make up one change
+
+Base the risk on the conflicts (how many, and whether they are in code, config or
+generated files), how far the branches diverged, and what the changes touch (schemas,
+APIs, dependencies, build or deployment files).
+
+Respond in exactly this format, keeping the labels in English:
+RISK: <low, medium or high>
+STRATEGY: <merge, rebase or squash>
+WHY: <1-3 sentences on the risk and the strategy>
+CHECK: <what to look at or test after merging, in one sentence>`,
+		sourceBranch, targetBranch, preview, sourceBranch, truncatedIncoming, targetBranch, truncatedLocal, truncatedDiffStat)
+
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
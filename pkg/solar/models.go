@@ -0,0 +1,90 @@
+package solar
+
+import "strings"
+
+// ContextWindows are the context sizes in tokens of models, by model name prefix.
+// Models that aren't listed are assumed to have ModelContextLimit.
+var ContextWindows = map[string]int{
+	"solar-pro2": 65536,
+	"solar-pro":  32768,
+	"solar-mini": 32768,
+
+	"gpt-5":         400000,
+	"gpt-4.1":       1047576,
+	"gpt-4o":        128000,
+	"gpt-4-turbo":   128000,
+	"gpt-4":         8192,
+	"gpt-3.5-turbo": 16385,
+	"o3":            200000,
+	"o4-mini":       200000,
+
+	"claude":           200000,
+	"anthropic.claude": 200000,
+	"meta.llama3-1":    128000,
+
+	"llama3.1": 131072,
+	"llama3.2": 131072,
+	"llama3.3": 131072,
+	"llama3":   8192,
+	"mistral":  32768,
+	"qwen2.5":  32768,
+	"gemma2":   8192,
+}
+
+// ContextWindow returns the context size in tokens of a model, matching the longest
+// prefix in ContextWindows; ok is false for models without a known size
+func ContextWindow(model string) (tokens int, ok bool) {
+	model = strings.ToLower(model)
+	match := ""
+	for prefix, size := range ContextWindows {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(match) {
+			match, tokens = prefix, size
+		}
+	}
+	return tokens, match != ""
+}
+
+// InputTokenLimit is the most input a prompt may use with a model with contextWindow
+// tokens of context: MaxInputTokens for ModelContextLimit, and in proportion for
+// smaller and larger models, so small models keep room for the reply and large ones
+// aren't truncated to the size of a small one
+func InputTokenLimit(contextWindow int) int {
+	if contextWindow <= 0 {
+		return MaxInputTokens
+	}
+	return int(int64(MaxInputTokens) * int64(contextWindow) / ModelContextLimit)
+}
+
+// SetContextWindow sets the context size of the model in tokens, e.g. from config for
+// a model ContextWindows doesn't know; 0 or less uses ContextWindows
+func (c *Client) SetContextWindow(tokens int) {
+	c.contextWindow = max(tokens, 0)
+}
+
+// ContextWindow returns the context size of the client's model in tokens
+func (c *Client) ContextWindow() int {
+	if c.contextWindow > 0 {
+		return c.contextWindow
+	}
+	if tokens, ok := ContextWindow(c.modelName); ok {
+		return tokens
+	}
+	return ModelContextLimit
+}
+
+// maxInputWords is the most words of input a prompt may have with the client's model,
+// MaxInputWords scaled like InputTokenLimit
+func (c *Client) maxInputWords() int {
+	return InputTokenLimit(c.ContextWindow()) * MaxInputWords / MaxInputTokens
+}
+
+// truncateContent is TokenCounter.TruncateContent with the limit of the client's model
+func (c *Client) truncateContent(content string) (string, int, bool) {
+	limit := c.maxInputWords()
+	words := c.tokenCounter.CountWords(content)
+	if words <= limit {
+		return content, words, false
+	}
+	truncated, actualWords := c.tokenCounter.TruncateToWordLimit(content, limit)
+	return truncated, actualWords, true
+}
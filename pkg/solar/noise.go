@@ -0,0 +1,39 @@
+package solar
+
+import (
+	"github.com/hunkim/sgit/pkg/difffilter"
+	"github.com/hunkim/sgit/pkg/logging"
+)
+
+// DiffFilterNotifier is called with what was left out of a diff before it is sent
+type DiffFilterNotifier func(result difffilter.Result)
+
+// SetDiffFilter leaves lock files, generated code, vendored files and whitespace-only
+// hunks out of the diffs sent to the model, noting them at the top of the diff
+// instead. A nil options sends diffs unchanged.
+func (c *Client) SetDiffFilter(options *difffilter.Options, notify DiffFilterNotifier) {
+	c.diffFilter = options
+	c.onDiffFiltered = notify
+}
+
+// prepareDiff applies the diff filter to a diff about to go into a prompt
+func (c *Client) prepareDiff(diff string) string {
+	if c.diffFilter == nil || diff == "" {
+		return diff
+	}
+	result := difffilter.Filter(diff, *c.diffFilter)
+	note := result.Note()
+	if note == "" {
+		return diff
+	}
+
+	// Tell the user once per diff, even when it goes into several prompts
+	if c.onDiffFiltered != nil && note != c.lastDiffNote {
+		c.onDiffFiltered(result)
+	}
+	if note != c.lastDiffNote {
+		logging.Info("filtered diff noise", "note", note)
+	}
+	c.lastDiffNote = note
+	return "[" + note + "]\n\n" + result.Diff
+}
@@ -0,0 +1,71 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// RepositoryOverview is what a newcomer's guide to a repository is written from
+type RepositoryOverview struct {
+	// Structure describes the tracked files: top-level and key directories, file
+	// types, build files and the start of the README and manifests
+	Structure string
+	// Activity lists recent commits and the directories they touched most
+	Activity string
+	// Contributors lists the top contributors by commit count
+	Contributors string
+	// Releases lists the newest tags with their dates, and the release tooling found
+	Releases string
+}
+
+// OnboardStream writes a guide to the repository for a developer who is new to it:
+// where to start, the core packages, how to build and test, how changes and releases
+// happen, and whom to ask. Chunks of the guide are passed to onChunk as they arrive.
+func (c *Client) OnboardStream(ctx context.Context, overview RepositoryOverview, onChunk func(string)) (string, error) {
+	sections := []BudgetSection{
+		{Text: overview.Structure, Weight: 0.55},
+		{Text: overview.Activity, Weight: 0.3},
+		{Text: overview.Contributors, Weight: 0.05},
+		{Text: overview.Releases, Weight: 0.1},
+	}
+	prompt, err := c.fitPrompt(sections, func(texts []string) (string, error) {
+		return fmt.Sprintf(`You are a senior engineer on this project writing an onboarding guide for a developer who joins it today.
+
+=== REPOSITORY STRUCTURE ===
+%s
+
+=== RECENT ACTIVITY ===
+%s
+
+=== TOP CONTRIBUTORS (commits, name) ===
+%s
+
+=== RELEASES ===
+%s
+
+Write the guide in markdown with these sections:
+## 👋 What This Project Is
+(2-3 sentences on what the project does and the main technologies, from the README and manifests)
+## 🚪 Start Here
+(the 3-5 files or directories to read first, in order, with why each matters)
+## 🧱 Core Packages
+(the key directories and what each is responsible for; group the rest as supporting code)
+## 🛠️ Build, Test & Run
+(the commands from the build files and manifests; say so when none are evident)
+## 🔥 Where Work Is Happening
+(the areas with the most recent activity and what the recent commits are about)
+## 🚀 How Releases Happen
+(tagging scheme, cadence from the tag dates, and release tooling or CI workflows found)
+## 🙋 Who to Ask
+(the top contributors and the areas they appear to work on, when the activity shows it)
+
+Rules:
+1. Base every statement on the information above; don't invent commands, files or people
+2. Name real paths so the reader can open them
+3. Keep it scannable: short bullets, no more than a screen or two in total`, texts[0], texts[1], texts[2], texts[3]), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return c.StreamResponse(ctx, c.addLanguageInstruction(prompt), onChunk)
+}
@@ -0,0 +1,82 @@
+package solar
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultSystemPrompt frames every request sent by sgit
+const DefaultSystemPrompt = `You are sgit, an AI assistant built into a git workflow. You help developers write commit messages, understand changes, and review history. Follow the output format requested in each prompt exactly and do not add commentary outside it.`
+
+// GenerationOptions controls sampling and the system message sent with each request.
+// Nil or zero values leave the API defaults in place.
+type GenerationOptions struct {
+	SystemPrompt string
+	Temperature  *float64
+	TopP         *float64
+	MaxTokens    int
+	// ReasoningEffort trades speed and cost for quality on reasoning models such as
+	// solar-pro2; see ReasoningEfforts
+	ReasoningEffort string
+}
+
+// ReasoningEfforts are the accepted reasoning_effort values, cheapest first
+var ReasoningEfforts = []string{"low", "medium", "high"}
+
+// NormalizeReasoningEffort checks a reasoning_effort value and returns it in lower case
+func NormalizeReasoningEffort(value string) (string, error) {
+	effort := strings.ToLower(strings.TrimSpace(value))
+	for _, known := range ReasoningEfforts {
+		if effort == known {
+			return effort, nil
+		}
+	}
+	return "", fmt.Errorf("invalid reasoning_effort '%s' (use %s)", value, strings.Join(ReasoningEfforts, ", "))
+}
+
+// SetGenerationOptions configures the system message and sampling parameters.
+// An empty SystemPrompt uses DefaultSystemPrompt.
+func (c *Client) SetGenerationOptions(options GenerationOptions) {
+	if options.SystemPrompt == "" {
+		options.SystemPrompt = DefaultSystemPrompt
+	}
+	c.options = options
+}
+
+// SetModel switches the model of later requests, e.g. to regenerate a message with
+// another model; an empty name keeps the current one
+func (c *Client) SetModel(modelName string) {
+	if modelName != "" {
+		c.modelName = modelName
+	}
+}
+
+// newChatRequest builds a request for the conversation messages (user and assistant
+// turns) with the system message and sampling options, masking sensitive content in
+// the messages when a redactor is set
+func (c *Client) newChatRequest(messages []Message, stream bool) ChatRequest {
+	systemPrompt := c.options.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = DefaultSystemPrompt
+	}
+
+	return ChatRequest{
+		Model:       c.modelName,
+		Messages:    append([]Message{{Role: "system", Content: systemPrompt}}, c.redactMessages(messages)...),
+		Stream:      stream,
+		Temperature: c.options.Temperature,
+		TopP:        c.options.TopP,
+		MaxTokens:   c.options.MaxTokens,
+
+		ReasoningEffort: c.options.ReasoningEffort,
+	}
+}
+
+// cacheKey identifies a request for the response cache. Streaming and
+// non-streaming requests for the same prompt share an entry.
+func cacheKey(request ChatRequest) []byte {
+	request.Stream = false
+	data, _ := json.Marshal(request)
+	return data
+}
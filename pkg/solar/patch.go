@@ -0,0 +1,57 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// CannotAdaptPrefix starts RepairPatch's response when the rejected hunks can't be
+// adapted, e.g. because the code they change is gone or the change is already there
+const CannotAdaptPrefix = "CANNOT:"
+
+// RepairPatch adapts the hunks of a patch that no longer apply to the current code.
+// patch is the whole patch for context, rejects the rejected hunks git apply --reject
+// wrote to .rej files, and current the current code around them, in sections labeled
+// with their file and line numbers. correction, when not empty, is why the previous
+// attempt didn't apply. The response is a unified diff of the rejected hunks only, or
+// CannotAdaptPrefix followed by the reason.
+func (c *Client) RepairPatch(ctx context.Context, patch, rejects, current, correction string) (string, error) {
+	truncatedPatch, _ := c.tokenCounter.TruncateToWordLimit(patch, c.maxInputWords()/4)
+	truncatedRejects, _ := c.tokenCounter.TruncateToWordLimit(rejects, c.maxInputWords()/4)
+	truncatedCurrent, _ := c.tokenCounter.TruncateToWordLimit(current, c.maxInputWords()/2)
+
+	prompt := fmt.Sprintf(`You are an expert developer porting a patch to a codebase that changed since the
+patch was written. Some hunks applied; the ones below were rejected because the code
+they change no longer matches.
+
+=== WHOLE PATCH (for context) ===
+%s
+
+=== REJECTED HUNKS ===
+%s
+
+=== CURRENT CODE AROUND THE REJECTED HUNKS (with line numbers) ===
+%s
+
+Write a patch that makes the change each rejected hunk intended, against the current
+code:
+1. Find where the hunk's change belongs now: the code may have moved, been renamed or
+   been edited around it
+2. Copy context and removed lines exactly from the current code, with its indentation,
+   and without the line numbers
+3. Only cover the rejected hunks; the rest of the patch is already applied
+4. Keep the intent of the change; don't fix, refactor or reformat anything else
+
+Respond with only the patch as a unified diff: a "diff --git a/<file> b/<file>" line,
+"--- a/<file>" and "+++ b/<file>" headers and @@ hunks for each file, no explanations
+or code fences. If a hunk can't be adapted, because the code it changes is gone or the
+change is already there, respond with "%s" followed by the reason instead, keeping
+the label in English.`,
+		truncatedPatch, truncatedRejects, truncatedCurrent, CannotAdaptPrefix)
+
+	if correction != "" {
+		prompt += fmt.Sprintf("\n\nYour previous patch didn't apply: %s\nFix it, copying the context lines exactly from the current code.", correction)
+	}
+
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
@@ -0,0 +1,68 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// GenerateCherryPickMessage rewrites the message of a cherry-picked commit. original is
+// the picked commit's message, source describes where it came from and target the
+// branch it was applied to, and diff is the change as applied.
+func (c *Client) GenerateCherryPickMessage(ctx context.Context, original, source, target, diff string) (string, error) {
+	truncatedDiff, _ := c.tokenCounter.TruncateToWordLimit(c.prepareDiff(diff), c.maxInputWords()*3/4)
+
+	prompt := fmt.Sprintf(`A commit was cherry-picked from %s onto the branch '%s'. Rewrite its commit
+message so it reads well on the new branch.
+
+=== ORIGINAL COMMIT MESSAGE ===
+%s
+
+=== CHANGE AS APPLIED ===
+%s
+
+%s
+Guidelines:
+- Describe the change as it was applied here; if the diff differs from what the original
+  message claims (e.g. parts were dropped while resolving conflicts), say what actually changed
+- Keep references to issues, tickets and co-authors from the original message
+- Mention in the body that this is a backport or cherry-pick of the original change,
+  and why it might be needed on '%s' if the context makes that clear
+- Leave out the "(cherry picked from commit ...)" line; it is copied from the original
+  message afterwards
+
+Respond with only the commit message, no explanations.`, source, target, original, truncatedDiff, c.conventionSection(), target)
+
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
+
+// GenerateRevertMessage writes the message for a commit that reverts another one.
+// reverted is the reverted commit's hash and message, reason is the developer's
+// explanation (may be empty), and diff is the reverting change.
+func (c *Client) GenerateRevertMessage(ctx context.Context, reverted, reason, diff string) (string, error) {
+	truncatedDiff, _ := c.tokenCounter.TruncateToWordLimit(c.prepareDiff(diff), c.maxInputWords()*3/4)
+	if reason == "" {
+		reason = "(not given - infer it from the reverted change only if it is obvious, otherwise don't speculate)"
+	}
+
+	prompt := fmt.Sprintf(`Write the commit message for a revert.
+
+=== REVERTED COMMIT ===
+%s
+
+=== WHY IT IS REVERTED ===
+%s
+
+=== REVERTING CHANGE ===
+%s
+
+%s
+Guidelines:
+- The subject says what is being reverted in terms of behavior (e.g. "revert: restore
+  token refresh on 401" rather than repeating the mechanical 'Revert "..."' default)
+- The body explains why the change is reverted, using the reason above
+- Leave out the "This reverts commit <hash>." line; git's is added afterwards
+
+Respond with only the commit message, no explanations.`, reverted, reason, truncatedDiff, c.conventionSection())
+
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
@@ -0,0 +1,21 @@
+package solar
+
+import "strings"
+
+// InputPrices are list prices in USD per million input tokens, by model name prefix
+var InputPrices = map[string]float64{
+	"solar-pro2": 0.15,
+	"solar-mini": 0.15,
+}
+
+// InputPrice returns the price per million input tokens of a model, matching the
+// longest prefix in InputPrices; ok is false for models without a known price
+func InputPrice(model string) (price float64, ok bool) {
+	match := ""
+	for prefix, p := range InputPrices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(match) {
+			match, price = prefix, p
+		}
+	}
+	return price, match != ""
+}
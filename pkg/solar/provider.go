@@ -0,0 +1,106 @@
+package solar
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Providers are the chat completion APIs the client can talk to
+const (
+	// ProviderUpstage is Upstage's Solar API, the default
+	ProviderUpstage = "upstage"
+	// ProviderOpenAI is any OpenAI-compatible chat completions API (OpenAI, a
+	// gateway, or a local server); base_url selects which one
+	ProviderOpenAI = "openai"
+	// ProviderAzure is Azure OpenAI, addressed by resource endpoint and deployment
+	// and authenticated with an api-key header; see UseAzure
+	ProviderAzure = "azure"
+	// ProviderBedrock is AWS Bedrock's Converse API, authenticated with SigV4
+	// signed requests; see UseBedrock
+	ProviderBedrock = "bedrock"
+)
+
+// DefaultAzureAPIVersion is the Azure OpenAI api-version used when none is configured
+const DefaultAzureAPIVersion = "2024-10-21"
+
+// providerBaseURLs are the default API roots of the providers
+var providerBaseURLs = map[string]string{
+	ProviderUpstage: "https://api.upstage.ai/v1",
+	ProviderOpenAI:  "https://api.openai.com/v1",
+}
+
+// ProviderNames lists the supported providers
+func ProviderNames() []string {
+	return []string{ProviderUpstage, ProviderOpenAI, ProviderAzure, ProviderBedrock}
+}
+
+// ProviderBaseURL returns the default API root of a provider; empty means Upstage.
+// Azure and Bedrock have none, their endpoints come from their own settings.
+func ProviderBaseURL(provider string) (string, error) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if provider == "" {
+		provider = ProviderUpstage
+	}
+	if provider == ProviderAzure || provider == ProviderBedrock {
+		return "", nil
+	}
+	baseURL, ok := providerBaseURLs[provider]
+	if !ok {
+		return "", fmt.Errorf("unknown provider '%s' (available: %s)", provider, strings.Join(ProviderNames(), ", "))
+	}
+	return baseURL, nil
+}
+
+// SetBaseURL points the client at an API root such as https://api.openai.com/v1, or
+// at a full chat completions URL. An empty URL keeps the current one.
+func (c *Client) SetBaseURL(baseURL string) {
+	baseURL = strings.TrimSuffix(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return
+	}
+	if !strings.HasSuffix(baseURL, "/chat/completions") {
+		baseURL += "/chat/completions"
+	}
+	c.baseURL = baseURL
+}
+
+// AzureConfig locates an Azure OpenAI deployment
+type AzureConfig struct {
+	// Endpoint is the resource URL, e.g. https://my-resource.openai.azure.com
+	Endpoint string
+	// Deployment is the name of the model deployment
+	Deployment string
+	// APIVersion is the api-version to call; empty uses DefaultAzureAPIVersion
+	APIVersion string
+	// ADToken is a Microsoft Entra ID access token, sent instead of the API key
+	ADToken string
+}
+
+// UseAzure sends requests to an Azure OpenAI deployment, authenticated with the
+// client's API key in the api-key header (or with an Entra ID token)
+func (c *Client) UseAzure(config AzureConfig) error {
+	endpoint := strings.TrimSuffix(strings.TrimSpace(config.Endpoint), "/")
+	if endpoint == "" {
+		return fmt.Errorf("azure needs the resource endpoint, e.g. https://my-resource.openai.azure.com")
+	}
+	deployment := strings.TrimSpace(config.Deployment)
+	if deployment == "" {
+		return fmt.Errorf("azure needs the name of the model deployment")
+	}
+	version := strings.TrimSpace(config.APIVersion)
+	if version == "" {
+		version = DefaultAzureAPIVersion
+	}
+
+	backend := &openAIBackend{
+		url:        fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s", endpoint, url.PathEscape(deployment), url.QueryEscape(version)),
+		authHeader: "api-key",
+		authValue:  c.apiKey,
+	}
+	if token := strings.TrimSpace(config.ADToken); token != "" {
+		backend.authHeader, backend.authValue = "Authorization", "Bearer "+token
+	}
+	c.backend = backend
+	return nil
+}
@@ -0,0 +1,34 @@
+package solar
+
+import "github.com/hunkim/sgit/pkg/redact"
+
+// RedactionNotifier is called with what was masked in a request before it is sent
+type RedactionNotifier func(report redact.Report)
+
+// SetRedactor masks sensitive content (emails, IP addresses, credentials, configured
+// patterns) in every message sent to the model; the system message is sgit's own and
+// is left as it is. A nil redactor sends messages unchanged.
+func (c *Client) SetRedactor(redactor *redact.Redactor, notify RedactionNotifier) {
+	c.redactor = redactor
+	c.onRedacted = notify
+}
+
+// redactMessages returns messages with sensitive content masked, reporting what was
+func (c *Client) redactMessages(messages []Message) []Message {
+	if c.redactor == nil {
+		return messages
+	}
+	redacted := make([]Message, len(messages))
+	total := make(redact.Report)
+	for i, message := range messages {
+		content, report := c.redactor.Redact(message.Content)
+		redacted[i] = Message{Role: message.Role, Content: content}
+		for name, count := range report {
+			total[name] += count
+		}
+	}
+	if total.Total() > 0 && c.onRedacted != nil {
+		c.onRedacted(total)
+	}
+	return redacted
+}
@@ -0,0 +1,45 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// CommitMessageConversation returns the conversation that produced message for a
+// change: the comprehensive commit prompt, answered with message. Continue it with
+// RefineCommitMessageStream.
+func (c *Client) CommitMessageConversation(diff, branch, recentCommits, fileList, message string) ([]Message, error) {
+	prompt, err := c.comprehensiveCommitPrompt(diff, branch, recentCommits, fileList)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Message{
+		{Role: "user", Content: c.addLanguageInstruction(prompt)},
+		{Role: "assistant", Content: message},
+	}, nil
+}
+
+// RefineCommitMessageStream asks the model to revise the last commit message in the
+// conversation according to the developer's feedback, streaming the new message to
+// onChunk. It returns the new message and the conversation with both turns added.
+func (c *Client) RefineCommitMessageStream(ctx context.Context, conversation []Message, feedback string, onChunk func(string)) (string, []Message, error) {
+	turn := Message{
+		Role: "user",
+		Content: fmt.Sprintf(`Revise the commit message based on this feedback from the developer:
+
+%s
+
+Keep following the commit convention and rules from the first message, and keep
+everything the feedback doesn't ask to change. Respond with only the revised commit
+message, no explanations.`, feedback),
+	}
+
+	// Copy so the caller's conversation is never modified in place
+	next := append(append([]Message{}, conversation...), turn)
+	message, err := c.StreamChat(ctx, next, onChunk)
+	if err != nil {
+		return "", conversation, err
+	}
+	return message, append(next, Message{Role: "assistant", Content: message}), nil
+}
@@ -0,0 +1,57 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExplainReflogStream explains recent reflog entries in plain language for a developer
+// who may have lost work: what each operation did and when, which commits are no longer
+// on any branch, and which of the numbered recovery options brings them back. lostWork
+// describes the commits only the reflog still points to, and state the current branch
+// and working tree. Chunks of the explanation are passed to onChunk as they arrive.
+func (c *Client) ExplainReflogStream(ctx context.Context, reflog, lostWork, state, options string, onChunk func(string)) (string, error) {
+	sections := []BudgetSection{
+		{Text: reflog, Weight: 0.45},
+		{Text: lostWork, Weight: 0.45},
+		{Text: state, Weight: 0.1},
+	}
+	prompt, err := c.fitPrompt(sections, func(texts []string) (string, error) {
+		return fmt.Sprintf(`You are a calm git expert helping a developer who is worried they lost work, e.g. after a bad reset, rebase or branch deletion.
+
+=== REFLOG (newest first: selector, time, commit, operation) ===
+%s
+
+=== COMMITS NO LONGER ON ANY BRANCH OR TAG (only the reflog still points to them) ===
+%s
+
+=== REPOSITORY STATE ===
+%s
+
+=== RECOVERY OPTIONS ===
+%s
+
+Write in markdown:
+## 🕰️ What Happened
+(the recent operations as a short timeline in plain language, newest first, with their
+times, e.g. "At 14:02 you reset main back 3 commits, to a1b2c3d". Group routine
+checkouts and commits; dwell on resets, rebases, amends and anything that moved a
+branch backwards)
+## 🧭 Lost Work
+(for each group of commits that is no longer on a branch: when it was left behind and
+what it contained, from the commit messages and files; say plainly when nothing is lost)
+## 🛟 How to Get It Back
+(recommend recovery options by number, saying what each one changes; prefer creating a
+branch, which changes nothing else. Mention that uncommitted changes discarded by
+reset --hard or checkout are not in the reflog)
+
+Rules:
+1. Base everything on the reflog and commits above; don't invent commits or times
+2. Refer to commits by their short hash
+3. Be reassuring and brief`, texts[0], texts[1], texts[2], options), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return c.StreamResponse(ctx, c.addLanguageInstruction(prompt), onChunk)
+}
@@ -0,0 +1,185 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"time"
+
+	"github.com/hunkim/sgit/pkg/httpclient"
+	"github.com/hunkim/sgit/pkg/logging"
+)
+
+const (
+	// DefaultMaxAttempts is the default number of attempts for a single API call
+	DefaultMaxAttempts = 3
+	// defaultBaseDelay is the initial backoff delay, doubled after each attempt
+	defaultBaseDelay = 1 * time.Second
+	// maxRetryDelay caps both computed backoff and server-provided Retry-After values
+	maxRetryDelay = 60 * time.Second
+)
+
+// RetryPolicy controls how failed API calls are retried
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+}
+
+// RetryNotifier is called before each retry with the failure reason, the delay before
+// the next attempt, and the attempt number
+type RetryNotifier func(reason string, wait time.Duration, attempt, maxAttempts int)
+
+// SetRetryPolicy configures retries for 429/5xx responses and network errors.
+// maxAttempts of 1 (or less) disables retries entirely.
+func (c *Client) SetRetryPolicy(maxAttempts int, baseDelay time.Duration) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	if baseDelay <= 0 {
+		baseDelay = defaultBaseDelay
+	}
+	c.retry = RetryPolicy{MaxAttempts: maxAttempts, BaseDelay: baseDelay}
+}
+
+// SetRetryNotifier sets a function that is told about each retry, e.g. to show a notice
+func (c *Client) SetRetryNotifier(notify RetryNotifier) {
+	c.onRetry = notify
+}
+
+// isRetryableStatus reports whether an HTTP status is worth retrying
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// doWithRetry sends the request to the API, retrying transient failures with
+// exponential backoff. Non-retryable responses are returned to the caller as-is.
+func (c *Client) doWithRetry(ctx context.Context, request ChatRequest) (*http.Response, error) {
+	policy := c.retry
+	if policy.MaxAttempts < 1 {
+		policy = RetryPolicy{MaxAttempts: DefaultMaxAttempts, BaseDelay: defaultBaseDelay}
+	}
+
+	httpClient := httpclient.Shared()
+
+	// reused tells the log whether a call skipped connection setup
+	var reused bool
+	if logging.Enabled(logging.LevelVerbose) {
+		logging.Info("api request", "model", request.Model, "stream", request.Stream, "messages", len(request.Messages), "prompt_tokens", c.requestTokens(request))
+		ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) { reused = info.Reused },
+		})
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := c.api().newRequest(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := httpClient.Do(req)
+		logAttempt(req, resp, err, attempt, time.Since(start), reused)
+
+		var reason string
+		var wait time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			reason = err.Error()
+		case isRetryableStatus(resp.StatusCode):
+			reason = fmt.Sprintf("status %d", resp.StatusCode)
+			wait = parseRetryAfter(resp.Header.Get("Retry-After"))
+		default:
+			return resp, nil
+		}
+
+		if attempt >= policy.MaxAttempts {
+			if err != nil {
+				return nil, &networkError{err: err}
+			}
+			return resp, nil
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		if wait == 0 {
+			wait = backoffDelay(policy.BaseDelay, attempt)
+		}
+
+		if c.onRetry != nil {
+			c.onRetry(reason, wait, attempt+1, policy.MaxAttempts)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// requestTokens estimates the tokens of the messages in a request
+func (c *Client) requestTokens(request ChatRequest) int {
+	tokens := 0
+	for _, message := range request.Messages {
+		tokens += c.tokenCounter.EstimateTokens(message.Content)
+	}
+	return tokens
+}
+
+// logAttempt logs one HTTP round trip to the API; for streaming requests duration is
+// the time until the response headers arrived. reused is whether it went over a
+// connection kept alive from an earlier call.
+func logAttempt(req *http.Request, resp *http.Response, err error, attempt int, duration time.Duration, reused bool) {
+	if err != nil {
+		logging.Info("api attempt failed", "method", req.Method, "url", req.URL.Redacted(), "attempt", attempt, "duration", duration, "error", err)
+		return
+	}
+	logging.Info("api attempt", "method", req.Method, "url", req.URL.Redacted(), "attempt", attempt, "status", resp.StatusCode, "duration", duration, "proto", resp.Proto, "conn_reused", reused)
+	logging.Debug("api response headers", "request_id", resp.Header.Get("X-Request-Id"), "content_type", resp.Header.Get("Content-Type"))
+}
+
+// backoffDelay returns an exponentially growing delay with jitter for the given attempt
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	delay := base << uint(attempt-1)
+	if delay > maxRetryDelay || delay <= 0 {
+		delay = maxRetryDelay
+	}
+	// Add up to 25% jitter so concurrent clients don't retry in lockstep
+	jitter := time.Duration(rand.Int63n(int64(delay)/4 + 1))
+	return delay + jitter
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if when, err := http.ParseTime(value); err == nil {
+		wait = time.Until(when)
+	}
+
+	if wait < 0 {
+		return 0
+	}
+	if wait > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return wait
+}
@@ -0,0 +1,51 @@
+package solar
+
+import (
+	"context"
+)
+
+// ReviewDiff reviews a diff for bugs, security and performance problems and
+// maintainability issues. scope says what the diff compares and fileList describes the
+// changed files; either may be empty.
+func (c *Client) ReviewDiff(ctx context.Context, diff, scope, fileList string) (string, error) {
+	prompt, err := c.codeReviewPrompt(diff, scope, fileList)
+	if err != nil {
+		return "", err
+	}
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
+
+// ReviewDiffStream is ReviewDiff with streaming, calling onChunk with each piece of the
+// review as it arrives
+func (c *Client) ReviewDiffStream(ctx context.Context, diff, scope, fileList string, onChunk func(string)) (string, error) {
+	prompt, err := c.codeReviewPrompt(diff, scope, fileList)
+	if err != nil {
+		return "", err
+	}
+	return c.StreamResponse(ctx, c.addLanguageInstruction(prompt), onChunk)
+}
+
+func (c *Client) codeReviewPrompt(diff, scope, fileList string) (string, error) {
+	// The diff is what gets reviewed; the file list only orients the model
+	sections := []BudgetSection{{Text: c.prepareDiff(diff), Weight: 0.9}, {Text: fileList, Weight: 0.1}}
+	return c.fitPrompt(sections, func(texts []string) (string, error) {
+		return c.renderPrompt(PromptCodeReview, PromptData{Diff: texts[0], Scope: scope, FileList: texts[1]})
+	})
+}
+
+// CompareBranchesStream writes a report for reviewing the changes between two branches:
+// per-file summaries, risk areas, a suggested review order and an estimated review
+// time. scope says what is compared, commits lists the commits in --oneline format and
+// fileList the changed files with their line counts. Chunks of the report are passed
+// to onChunk as they arrive.
+func (c *Client) CompareBranchesStream(ctx context.Context, diff, scope, commits, fileList string, onChunk func(string)) (string, error) {
+	// The file list is what the report is organized around, so it gets more room here
+	sections := []BudgetSection{{Text: c.prepareDiff(diff), Weight: 0.7}, {Text: commits, Weight: 0.1}, {Text: fileList, Weight: 0.2}}
+	prompt, err := c.fitPrompt(sections, func(texts []string) (string, error) {
+		return c.renderPrompt(PromptReviewReport, PromptData{Diff: texts[0], Scope: scope, Commits: texts[1], FileList: texts[2]})
+	})
+	if err != nil {
+		return "", err
+	}
+	return c.StreamResponse(ctx, c.addLanguageInstruction(prompt), onChunk)
+}
@@ -0,0 +1,164 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ProjectScaffold is the starting advice for a new repository
+type ProjectScaffold struct {
+	// Gitignore is a complete .gitignore
+	Gitignore string
+	// Readme is a README.md skeleton, or "" when the project already has a README
+	Readme string
+	// BranchPrefixes and BranchPattern follow the branch_prefixes and branch_pattern
+	// settings; BranchPolicy explains them
+	BranchPrefixes []string
+	BranchPattern  string
+	BranchPolicy   string
+	// CommitMessage is the message for the first commit
+	CommitMessage string
+}
+
+// scaffoldSections are the labels of the ScaffoldProject response
+var scaffoldSections = []string{"GITIGNORE", "README", "BRANCHING", "COMMIT"}
+
+// ScaffoldProject suggests a .gitignore, a README skeleton, a branch naming policy and
+// the first commit message for a directory that was just put under git. project
+// describes its files and manifests, gitignore is the current .gitignore (may be
+// empty), and hasReadme tells whether a README already exists.
+func (c *Client) ScaffoldProject(ctx context.Context, project, gitignore string, hasReadme bool) (*ProjectScaffold, error) {
+	truncatedProject, _ := c.tokenCounter.TruncateToWordLimit(project, c.maxInputWords()*3/4)
+	if gitignore == "" {
+		gitignore = "None yet."
+	}
+	readmeInstruction := `a README.md skeleton in markdown: the project name as the title, a
+   one-line description inferred from the files, and Installation, Usage, Development
+   (build, test and lint commands from the manifests) and Contributing sections, with
+   TODO markers where only the developer can fill in the details. Mention the branch
+   naming policy under Contributing.`
+	if hasReadme {
+		readmeInstruction = `the project already has a README; write only "none".`
+	}
+
+	prompt := fmt.Sprintf(`You are helping a developer set up a git repository for an existing project directory.
+
+=== PROJECT DIRECTORY ===
+%s
+
+=== CURRENT .gitignore ===
+%s
+
+Suggest, based only on the languages, frameworks and tools the files show:
+
+1. GITIGNORE: a complete .gitignore grouped into commented sections (dependencies,
+   build output, environment and secrets, editor and OS files). Keep the current
+   patterns. Ignore secret files such as .env, but not the lock files the project
+   should commit.
+2. README: %s
+3. BRANCHING: a branch naming policy for the project, as three lines:
+   PREFIXES: comma-separated branch prefixes, e.g. feature/, fix/, docs/
+   PATTERN: a pattern using <prefix>, <ticket> and <short-description>
+   WHY: one or two sentences on when to use each prefix
+4. COMMIT: the first commit message in conventional commit format, with a subject
+   under 72 characters and a short body saying what the initial project contains.
+
+Respond in exactly this format, with each label on its own line, no code fences, and
+the labels and the PREFIXES, PATTERN and WHY keys in English:
+=== GITIGNORE ===
+<.gitignore>
+=== README ===
+<README.md or none>
+=== BRANCHING ===
+PREFIXES: <prefixes>
+PATTERN: <pattern>
+WHY: <explanation>
+=== COMMIT ===
+<commit message>`, truncatedProject, gitignore, readmeInstruction)
+
+	response, err := c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+	if err != nil {
+		return nil, err
+	}
+	return parseProjectScaffold(response), nil
+}
+
+// parseProjectScaffold splits the response into its "=== LABEL ===" sections
+func parseProjectScaffold(response string) *ProjectScaffold {
+	sections := make(map[string][]string)
+	current := ""
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "===") && strings.HasSuffix(trimmed, "===") {
+			label := strings.ToUpper(strings.TrimSpace(strings.Trim(trimmed, "=")))
+			for _, section := range scaffoldSections {
+				if label == section {
+					current = section
+				}
+			}
+			continue
+		}
+		if current != "" {
+			sections[current] = append(sections[current], line)
+		}
+	}
+	section := func(name string) string {
+		return stripFence(strings.TrimSpace(strings.Join(sections[name], "\n")))
+	}
+
+	scaffold := &ProjectScaffold{
+		Gitignore:     section("GITIGNORE"),
+		Readme:        section("README"),
+		CommitMessage: section("COMMIT"),
+	}
+	if strings.EqualFold(strings.Trim(scaffold.Readme, ". "), "none") {
+		scaffold.Readme = ""
+	}
+
+	var why []string
+	for _, line := range strings.Split(section("BRANCHING"), "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(strings.TrimLeft(line, "-* ")), ":")
+		value = strings.TrimSpace(value)
+		switch strings.ToUpper(strings.Trim(key, "* ")) {
+		case "PREFIXES":
+			if found {
+				for _, prefix := range strings.Split(value, ",") {
+					if prefix = strings.Trim(strings.TrimSpace(prefix), "`"); prefix != "" {
+						scaffold.BranchPrefixes = append(scaffold.BranchPrefixes, prefix)
+					}
+				}
+				continue
+			}
+		case "PATTERN":
+			if found {
+				scaffold.BranchPattern = strings.Trim(value, "`\"")
+				continue
+			}
+		case "WHY":
+			if found {
+				why = append(why, value)
+				continue
+			}
+		}
+		if strings.TrimSpace(line) != "" {
+			why = append(why, strings.TrimSpace(line))
+		}
+	}
+	scaffold.BranchPolicy = strings.Join(why, " ")
+	return scaffold
+}
+
+// stripFence removes a markdown code fence around a section, which models add despite
+// being asked not to
+func stripFence(text string) string {
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	if newline := strings.Index(text, "\n"); newline != -1 {
+		text = text[newline+1:]
+	} else {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
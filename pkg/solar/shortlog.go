@@ -0,0 +1,71 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// Audiences of SummarizeChanges
+const (
+	AudienceEngineering = "engineering"
+	AudienceProduct     = "product"
+)
+
+// ShortlogAudiences contains how SummarizeChanges writes for each audience
+var ShortlogAudiences = map[string]string{
+	AudienceEngineering: `The readers are engineers: the team, other teams that depend on this code, and
+whoever operates it. Write a technical summary in markdown:
+## Summary
+One or two sentences on what this range of changes is about.
+### ✨ Features
+### 🐛 Fixes
+### ⚠️ Breaking Changes & Migrations
+### 🔧 Internals
+Name the components, APIs, configuration and dependencies that changed, and say
+what other engineers have to do about breaking changes, migrations and new
+configuration. Only include sections that have entries.`,
+	AudienceProduct: `The readers are product managers, marketing, support and customers, who don't read
+code. Write a "what's new" announcement in markdown:
+## What's New
+One or two sentences on the theme of the release.
+### New
+### Improved
+### Fixed
+Describe each change by what people can now do or no longer run into, in plain,
+friendly language. Leave out internal work (refactoring, tests, CI, dependencies)
+unless users notice it, e.g. as speed or reliability, and never mention file
+names, functions, commit hashes or code. Only include sections that have entries.`,
+}
+
+// SummarizeChanges writes a summary of the commits in a range of history for an
+// audience, e.g. for a release email. rangeDescription says what the range is,
+// commits lists them with their bodies and diffStat the files they changed;
+// audienceInstructions says who the summary is for (see ShortlogAudiences). onChunk is
+// called with each piece of the summary as it arrives.
+func (c *Client) SummarizeChanges(ctx context.Context, rangeDescription, commits, diffStat, audienceInstructions string, onChunk func(string)) (string, error) {
+	// Commits carry most of the signal; the diffstat only adds context
+	truncatedCommits, _ := c.tokenCounter.TruncateToWordLimit(commits, c.maxInputWords()*2/3)
+	truncatedDiffStat, _ := c.tokenCounter.TruncateToWordLimit(diffStat, c.maxInputWords()/3)
+
+	prompt := fmt.Sprintf(`You are writing the summary of changes for a release email.
+
+Changes: %s
+
+=== COMMITS (hash, author, subject, body) ===
+%s
+
+=== FILES CHANGED ===
+%s
+
+%s
+
+Rules:
+1. Group related commits into one bullet; don't list every commit one by one
+2. Lead with the changes that matter most to the readers
+3. Leave out merge commits, typo fixes and reverted work
+4. Stay factual: don't invent features, numbers or benefits the commits don't show
+
+Respond with only the markdown summary, no explanations.`, rangeDescription, truncatedCommits, truncatedDiffStat, audienceInstructions)
+
+	return c.StreamResponse(ctx, c.addLanguageInstruction(prompt), onChunk)
+}
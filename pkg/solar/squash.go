@@ -0,0 +1,47 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// GenerateSquashMessage writes one commit message for several commits being squashed
+// together. messages holds the commits' messages, oldest first, and diff and fileList
+// their combined change. The message describes the end result, not the steps that
+// led to it.
+func (c *Client) GenerateSquashMessage(ctx context.Context, messages, diff, fileList string) (string, error) {
+	sections := []BudgetSection{
+		{Text: messages, Weight: 0.3},
+		{Text: c.prepareDiff(diff), Weight: 0.55},
+		{Text: fileList, Weight: 0.15},
+	}
+	prompt, err := c.fitPrompt(sections, func(texts []string) (string, error) {
+		return fmt.Sprintf(`Several commits are being squashed into one. Write the commit message for the
+combined commit.
+
+=== MESSAGES OF THE SQUASHED COMMITS (oldest first) ===
+%s
+
+=== COMBINED CHANGE ===
+%s
+
+=== FILES CHANGED ===
+%s
+
+%s%s%s
+Guidelines:
+- Describe what the combined change does as a whole, as if it had been made in one go.
+  Work in progress, fixes of earlier commits in the list ("fix typo", "fix again",
+  "wip") and changes undone later are not part of the story
+- Base the type and scope on the combined change, not on the most frequent type in the list
+- Use the body for the important parts of the change when there is more than one
+- Keep issue references and trailers (e.g. Refs, Closes, Co-authored-by, Signed-off-by)
+  from the original messages, once each, at the end
+
+Respond with only the commit message, no explanations.`, texts[0], texts[1], texts[2], c.conventionSection(), c.hintsSection(), c.correctionSection()), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
@@ -0,0 +1,172 @@
+package solar
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// DefaultStreamBufferSize is the read buffer of a streamed response
+	DefaultStreamBufferSize = 64 * 1024
+	// DefaultMaxStreamEventSize caps one server-sent event, so a broken stream can't
+	// use unbounded memory
+	DefaultMaxStreamEventSize = 8 * 1024 * 1024
+)
+
+// SetStreamLimits sets the read buffer of streamed responses and the largest event
+// accepted in them, in bytes; 0 keeps the default
+func (c *Client) SetStreamLimits(bufferSize, maxEventSize int) {
+	c.streamBufferSize = bufferSize
+	c.maxStreamEventSize = maxEventSize
+}
+
+// errStreamEventTooLarge is returned when an event exceeds the maximum size
+var errStreamEventTooLarge = errors.New("stream event exceeds the maximum size")
+
+// sseEvent is one server-sent event: its type (empty for the default "message") and
+// its data lines joined with "\n"
+type sseEvent struct {
+	Event string
+	Data  string
+}
+
+// sseReader parses a text/event-stream body (https://html.spec.whatwg.org/multipage/server-sent-events.html)
+// with no limit on line length other than maxEventSize
+type sseReader struct {
+	reader       *bufio.Reader
+	maxEventSize int
+}
+
+func newSSEReader(body io.Reader, bufferSize, maxEventSize int) *sseReader {
+	if bufferSize <= 0 {
+		bufferSize = DefaultStreamBufferSize
+	}
+	if maxEventSize <= 0 {
+		maxEventSize = DefaultMaxStreamEventSize
+	}
+	return &sseReader{reader: bufio.NewReaderSize(body, bufferSize), maxEventSize: maxEventSize}
+}
+
+// Next returns the next event with data, or io.EOF at the end of the stream. An
+// event cut off by the end of the stream is still returned.
+func (r *sseReader) Next() (sseEvent, error) {
+	var event sseEvent
+	var data []string
+	hasData, size := false, 0
+
+	for {
+		line, err := r.readLine()
+		if err != nil && (err != io.EOF || line == "") {
+			if err == io.EOF && hasData {
+				event.Data = strings.Join(data, "\n")
+				return event, nil
+			}
+			return sseEvent{}, err
+		}
+
+		// A blank line dispatches the event; events without data are ignored
+		if line == "" {
+			if hasData {
+				event.Data = strings.Join(data, "\n")
+				return event, nil
+			}
+			event = sseEvent{}
+			continue
+		}
+		// Comments keep idle connections alive
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			size += len(value)
+			if size > r.maxEventSize {
+				return sseEvent{}, errStreamEventTooLarge
+			}
+			data = append(data, value)
+			hasData = true
+		case "event":
+			event.Event = value
+		}
+		// id and retry only matter for reconnecting, which a completion can't do
+	}
+}
+
+// readLine reads a line of any length up to the maximum event size, without its
+// line ending
+func (r *sseReader) readLine() (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.reader.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > r.maxEventSize {
+			return "", errStreamEventTooLarge
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		return string(line), err
+	}
+}
+
+// streamError is an error object sent in the middle of a stream, in OpenAI's format
+type streamError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// parseStreamEvent returns the content delta of a completion chunk, or the API error
+// the event reports
+func parseStreamEvent(event sseEvent) (string, error) {
+	var chunk struct {
+		StreamResponse
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(event.Data), &chunk); err != nil {
+		if event.Event == "error" {
+			return "", newStreamError(truncateForError(event.Data))
+		}
+		return "", fmt.Errorf("invalid stream data: %v (%s)", err, truncateForError(event.Data))
+	}
+
+	if len(chunk.Error) > 0 && string(chunk.Error) != "null" {
+		// The error is usually an object, but some servers send a plain string
+		message := string(chunk.Error)
+		var object streamError
+		if json.Unmarshal(chunk.Error, &object) == nil && object.Message != "" {
+			message = object.Message
+			if object.Type != "" {
+				message = object.Type + ": " + message
+			}
+		} else {
+			json.Unmarshal(chunk.Error, &message)
+		}
+		return "", newStreamError(message)
+	}
+	if event.Event == "error" {
+		return "", newStreamError(truncateForError(event.Data))
+	}
+
+	if len(chunk.Choices) == 0 {
+		return "", nil
+	}
+	return chunk.Choices[0].Delta.Content, nil
+}
+
+// truncateForError shortens text quoted in an error message
+func truncateForError(text string) string {
+	const maxLength = 200
+	if len(text) <= maxLength {
+		return text
+	}
+	return text[:maxLength] + "..."
+}
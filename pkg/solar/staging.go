@@ -0,0 +1,174 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MaxStagingFileWords limits how much of a single file is sent for staging analysis
+const MaxStagingFileWords = 5000
+
+// FileSample is a file's path and (possibly truncated) content
+type FileSample struct {
+	Path    string
+	Content string
+}
+
+// StagingDecision is the model's recommendation for one untracked file
+type StagingDecision struct {
+	Add    bool
+	Reason string
+}
+
+// AnalyzeFilesForStaging asks whether each file belongs in version control, batching
+// all files into a single request. Files missing from the response get no decision.
+func (c *Client) AnalyzeFilesForStaging(ctx context.Context, files []FileSample) (map[string]StagingDecision, error) {
+	var b strings.Builder
+	for i, file := range files {
+		content, _ := c.tokenCounter.TruncateToWordLimit(file.Content, MaxStagingFileWords)
+		fmt.Fprintf(&b, "=== FILE %d: %s ===\n%s\n\n", i+1, file.Path, content)
+	}
+
+	prompt := fmt.Sprintf(`You are a helpful assistant that analyzes files in software projects to determine if they should be added to git version control.
+
+Analyze each of the following %d files and determine if it should be added to git:
+
+%s
+Consider these factors:
+1. Is this a source code file, configuration, or documentation that belongs in version control?
+2. Is this a temporary file, log file, or build artifact that should be ignored?
+3. Does this file contain sensitive information (passwords, keys, tokens)?
+4. Is this a generated file that can be recreated from source?
+
+Respond with exactly one line per file, in order, and nothing else:
+- "<file number>. YES: [brief reason]" if the file should be added
+- "<file number>. NO: [brief reason]" if the file should not be added
+
+Keep each reason under 50 characters.`, len(files), b.String())
+
+	response, err := c.GenerateResponse(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseStagingDecisions(response, files), nil
+}
+
+// maxPlannedFileWords limits how much of each unstaged file's change is sent when
+// planning a commit
+const maxPlannedFileWords = 1500
+
+// PlanCommitStaging proposes which unstaged changes to include in the commit being
+// made, so it stays coherent: staged is the diff already staged (may be empty) and
+// files the unstaged files with their diff, or content for new files. Add means the
+// file belongs in this commit; files missing from the response get no decision.
+func (c *Client) PlanCommitStaging(ctx context.Context, staged string, files []FileSample) (map[string]StagingDecision, error) {
+	var b strings.Builder
+	for i, file := range files {
+		content, _ := c.tokenCounter.TruncateToWordLimit(file.Content, maxPlannedFileWords)
+		fmt.Fprintf(&b, "=== FILE %d: %s ===\n%s\n\n", i+1, file.Path, content)
+	}
+	if strings.TrimSpace(staged) == "" {
+		staged = "Nothing is staged yet."
+	}
+
+	sections := []BudgetSection{
+		{Text: c.prepareDiff(staged), Weight: 0.4},
+		{Text: b.String(), Weight: 0.6},
+	}
+	prompt, err := c.fitPrompt(sections, func(texts []string) (string, error) {
+		return fmt.Sprintf(`A developer is about to commit. Decide which of their unstaged changes belong in this
+commit, so it is one coherent change.
+
+=== ALREADY STAGED ===
+%s
+
+=== UNSTAGED FILES (%d) ===
+%s
+Guidelines:
+- When something is staged, include the files that are part of the same change (e.g.
+  the tests, docs or call sites of the staged code) and leave out unrelated work
+- When nothing is staged, include the files that make up the main change and leave out
+  unrelated or half-finished work
+- Leave out debug leftovers, local configuration, generated files, build artifacts and
+  files that may contain secrets
+
+Respond with exactly one line per file, in order, and nothing else:
+- "<file number>. YES: [brief reason]" to include the file in this commit
+- "<file number>. NO: [brief reason]" to leave it for a later commit
+
+Keep YES and NO in English and each reason under 60 characters.`, texts[0], len(files), texts[1]), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+	if err != nil {
+		return nil, err
+	}
+	return parseStagingDecisions(response, files), nil
+}
+
+// parseStagingDecisions maps "N. YES: reason" / "N. NO: reason" lines back to file paths
+func parseStagingDecisions(response string, files []FileSample) map[string]StagingDecision {
+	decisions := make(map[string]StagingDecision)
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "*`"))
+
+		var index int
+		var rest string
+		if n, err := fmt.Sscanf(line, "%d.", &index); n != 1 || err != nil {
+			continue
+		}
+		if i := strings.Index(line, "."); i >= 0 {
+			rest = strings.TrimSpace(line[i+1:])
+		}
+		if index < 1 || index > len(files) {
+			continue
+		}
+
+		upper := strings.ToUpper(rest)
+		switch {
+		case strings.HasPrefix(upper, "YES:"):
+			decisions[files[index-1].Path] = StagingDecision{Add: true, Reason: strings.TrimSpace(rest[len("YES:"):])}
+		case strings.HasPrefix(upper, "NO:"):
+			decisions[files[index-1].Path] = StagingDecision{Add: false, Reason: strings.TrimSpace(rest[len("NO:"):])}
+		}
+	}
+	return decisions
+}
+
+// SuggestGitignore proposes a complete .gitignore for a project, grouped into
+// commented sections, based on its tracked files, untracked paths and current .gitignore
+func (c *Client) SuggestGitignore(ctx context.Context, projectFiles, untracked, current string) (string, error) {
+	truncatedProject, _ := c.tokenCounter.TruncateToWordLimit(projectFiles, c.maxInputWords()/3)
+	truncatedUntracked, _ := c.tokenCounter.TruncateToWordLimit(untracked, c.maxInputWords()/3)
+	truncatedCurrent, _ := c.tokenCounter.TruncateToWordLimit(current, c.maxInputWords()/3)
+
+	prompt := fmt.Sprintf(`You are an expert developer writing the .gitignore file for a project.
+
+=== PROJECT FILES (top-level entries and tracked file types) ===
+%s
+
+=== UNTRACKED PATHS ===
+%s
+
+=== CURRENT .gitignore ===
+%s
+
+Write a complete .gitignore for this project:
+1. Detect the languages, frameworks and tools in use and ignore their build output,
+   dependency directories, caches, logs and editor/OS files
+2. Ignore untracked paths that are artifacts, local environment files or secrets,
+   but never files that look like source code, documentation or shared configuration
+3. Keep every meaningful pattern from the current .gitignore
+4. Group patterns into sections with a "# Section name" comment header
+   (e.g. "# Build output", "# Dependencies", "# Logs", "# Environment", "# Editors & OS")
+5. Keep example/template files such as .env.example tracked (use "!" negation if needed)
+
+Respond with only the .gitignore content, no explanations or code fences.`, truncatedProject, truncatedUntracked, truncatedCurrent)
+
+	return c.GenerateResponse(ctx, prompt)
+}
@@ -0,0 +1,40 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// CommentOnStatsStream writes commentary on repository analytics that sgit computed
+// from the history: what the numbers say about the project's activity, its hotspots
+// and its knowledge risks. The model interprets the numbers in report but doesn't
+// compute new ones. scope says which history the numbers cover. Chunks of the
+// commentary are passed to onChunk as they arrive.
+func (c *Client) CommentOnStatsStream(ctx context.Context, report, scope string, onChunk func(string)) (string, error) {
+	truncatedReport, _ := c.tokenCounter.TruncateToWordLimit(report, c.maxInputWords())
+
+	prompt := fmt.Sprintf(`You are helping a team understand the health of their git repository. The
+statistics below were computed exactly from the history (%s).
+
+=== REPOSITORY STATISTICS ===
+%s
+
+Write a short commentary in markdown:
+### 📈 Activity
+Trends in the commit frequency: steady, growing, slowing down, bursts.
+### 🔥 Hotspots
+What the most changed files suggest: areas under active development, or code that
+keeps needing fixes and may deserve a refactor or more tests.
+### 👥 Knowledge Risk
+What the contributor breakdown and bus factor mean for the project, and which areas
+depend on too few people.
+### 💡 Suggestions
+Two to four concrete suggestions that follow from the numbers.
+
+Rules:
+1. Only use the numbers given; don't compute, estimate or invent other figures
+2. Quote the figures that support each point
+3. Say so when the history is too short or too small to draw a conclusion`, scope, truncatedReport)
+
+	return c.StreamResponse(ctx, c.addLanguageInstruction(prompt), onChunk)
+}
@@ -0,0 +1,66 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// WorkSummaryFormats contains the built-in report formats for SummarizeWork
+var WorkSummaryFormats = map[string]string{
+	"standup": `Write a daily standup update in markdown:
+### ✅ Done
+### 🔨 In Progress
+### 🚧 Blockers
+Keep it short enough to read aloud in under a minute: a few bullets per section, one
+line each. Infer "In Progress" from WIP commits, unfinished series or branches that
+aren't merged; write "None" under Blockers unless the commits show one (reverts,
+repeated fixes of the same problem).`,
+	"weekly": `Write a weekly report in markdown:
+## Week summary
+One or two sentences on the main focus of the period.
+### 🚀 Shipped
+### 🐛 Fixed
+### 🔧 Maintenance
+### 📌 Next
+Group related commits into themes instead of listing each one, and mention the
+branch when work happened outside the main branch. Only include sections that have
+entries; "Next" lists the unfinished threads the commits point to.`,
+	"perf-review": `Write a self-review for a performance review in markdown:
+## Summary
+### 🏆 Key Contributions
+### 📈 Impact
+### 🤝 Collaboration & Quality
+### 🌱 Growth Areas
+Describe accomplishments as outcomes (features delivered, bugs removed, reliability,
+performance, developer experience), backed by concrete commits and the size of the
+work. Stay factual: don't invent metrics, customers or results the commits don't show.`,
+}
+
+// SummarizeWork writes a report of one author's commits over a period, e.g. a standup
+// update or a weekly report. formatInstructions describes the expected markdown layout
+// (see WorkSummaryFormats). onChunk is called with each piece of the report as it
+// arrives.
+func (c *Client) SummarizeWork(ctx context.Context, author, period, commits, formatInstructions string, onChunk func(string)) (string, error) {
+	truncatedCommits, _ := c.tokenCounter.TruncateToWordLimit(commits, c.maxInputWords())
+
+	prompt := fmt.Sprintf(`You are helping a developer report on their own work from their git history.
+
+Author: %s
+Period: %s
+
+=== COMMITS (oldest first: date, branches, hash, subject, body, size) ===
+%s
+
+%s
+
+Rules:
+1. Write in the first person, as the developer
+2. Describe what the work achieved, not how git recorded it; don't list hashes
+   unless they help a reader find the change
+3. Merge commits that belong to the same piece of work into one bullet
+4. Leave out trivial noise (typo fixes, formatting, merge commits) unless it is all there is
+
+Respond with only the markdown report, no explanations.`, author, period, truncatedCommits, formatInstructions)
+
+	return c.StreamResponse(ctx, c.addLanguageInstruction(prompt), onChunk)
+}
@@ -0,0 +1,176 @@
+package solar
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed prompts/*.tmpl
+var defaultPrompts embed.FS
+
+// Names of the prompts that can be overridden with a <name>.tmpl file in the prompt directory
+const (
+	PromptCommit              = "commit"
+	PromptComprehensiveCommit = "comprehensive-commit"
+	PromptDiffSummary         = "diff-summary"
+	PromptDiffSummaryDetailed = "diff-summary-detailed"
+	PromptLogAnalysis         = "log-analysis"
+	PromptLogAnalysisDetailed = "log-analysis-detailed"
+	PromptMergeConflict       = "merge-conflict"
+	PromptCodeReview          = "code-review"
+	PromptReviewReport        = "review-report"
+)
+
+// PromptNames lists the overridable prompts with what each one is used for
+var PromptNames = []struct {
+	Name        string
+	Description string
+}{
+	{PromptCommit, "short commit message from a diff"},
+	{PromptComprehensiveCommit, "commit message from the diff, branch, recent commits and files (sgit commit)"},
+	{PromptDiffSummary, "concise diff summary"},
+	{PromptDiffSummaryDetailed, "structured diff summary (sgit diff)"},
+	{PromptLogAnalysis, "concise history analysis"},
+	{PromptLogAnalysisDetailed, "detailed history analysis (sgit log)"},
+	{PromptMergeConflict, "merge conflict overview (--ai-help)"},
+	{PromptCodeReview, "code review of a diff (sgit serve /review)"},
+	{PromptReviewReport, "reviewer's guide to a branch (sgit compare)"},
+}
+
+// PromptData holds the variables available to prompt templates. Fields that don't
+// apply to a prompt are empty.
+type PromptData struct {
+	Convention      string // one-line name of the commit convention
+	ConventionRules string // the convention's rules and examples, ends with a blank line
+	Hints           string // --type/--scope/--hint guidance, ends with a blank line if set
+	Language        string // response language code (the language instruction is added separately)
+	Diff            string
+	Scope           string // what a diff compares, e.g. "changes from v1.2.0 to HEAD"
+	Commits         string // commits in a diffed range, in --oneline format
+	Branch          string
+	RecentCommits   string
+	FileList        string
+	Context         string // further context sections (build, tests, ...), each starting with a blank line
+	Log             string
+	Timeframe       string
+	Focus           string // what a log analysis is about, e.g. "the work of alice on pkg/solar"
+	Conflicts       string
+
+	// Repository metadata, set for every prompt (see TemplateVars)
+	RepoName string
+	Ticket   string
+	Author   string
+	Date     string
+}
+
+// TemplateVars is the repository metadata prompt templates, trailers.custom lines and
+// pr_footer can use: {{.RepoName}}, {{.Ticket}}, {{.Author}}, {{.Branch}} and {{.Date}}.
+// It is resolved when a message is generated.
+type TemplateVars struct {
+	RepoName string
+	Ticket   string // ticket key the change belongs to, e.g. PROJ-123
+	Author   string // the git author's name
+	Branch   string // the current branch
+	Date     string // today, as YYYY-MM-DD
+}
+
+// SetTemplateVars sets the repository metadata given to prompt templates
+func (c *Client) SetTemplateVars(vars TemplateVars) {
+	c.templateVars = vars
+}
+
+// ExpandTemplate fills the variables of TemplateVars into text, a Go text/template
+func ExpandTemplate(text string, vars TemplateVars) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New("metadata").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %v", text, err)
+	}
+	var expanded strings.Builder
+	if err := tmpl.Execute(&expanded, vars); err != nil {
+		return "", fmt.Errorf("error expanding template %q: %v", text, err)
+	}
+	return expanded.String(), nil
+}
+
+// DefaultPromptDir returns the directory prompt overrides are loaded from
+// (e.g. ~/.config/sgit/prompts)
+func DefaultPromptDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding home directory: %v", err)
+	}
+	return filepath.Join(home, ".config", "sgit", "prompts"), nil
+}
+
+// SetPromptDir makes the client load <dir>/<name>.tmpl instead of the built-in
+// prompt when such a file exists ("" uses only the built-in prompts)
+func (c *Client) SetPromptDir(dir string) {
+	c.promptDir = dir
+}
+
+// DefaultPrompt returns the built-in template for a prompt name
+func DefaultPrompt(name string) (string, error) {
+	content, err := defaultPrompts.ReadFile("prompts/" + name + ".tmpl")
+	if err != nil {
+		return "", fmt.Errorf("unknown prompt '%s'", name)
+	}
+	return string(content), nil
+}
+
+// PromptOverride returns the path of the override file for a prompt, or "" if the
+// built-in template is used
+func (c *Client) PromptOverride(name string) string {
+	if c.promptDir == "" {
+		return ""
+	}
+	path := filepath.Join(c.promptDir, name+".tmpl")
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// renderPrompt executes the named prompt template, preferring the user's override
+func (c *Client) renderPrompt(name string, data PromptData) (string, error) {
+	text, err := DefaultPrompt(name)
+	if err != nil {
+		return "", err
+	}
+	source := "built-in"
+	if path := c.PromptOverride(name); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("error reading prompt template: %v", err)
+		}
+		text, source = string(content), path
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt template %s (%s): %v", name, source, err)
+	}
+
+	data.Convention = c.convention.Spec
+	data.ConventionRules = c.conventionSection()
+	data.Hints = c.hintsSection() + c.correctionSection()
+	data.Context = c.contextSections()
+	data.Language = c.language
+	data.RepoName, data.Ticket, data.Author, data.Date = c.templateVars.RepoName, c.templateVars.Ticket, c.templateVars.Author, c.templateVars.Date
+	// Prompts that take the branch as context keep it
+	if data.Branch == "" {
+		data.Branch = c.templateVars.Branch
+	}
+
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, data); err != nil {
+		return "", fmt.Errorf("error rendering prompt template %s (%s): %v", name, source, err)
+	}
+	return strings.TrimRight(prompt.String(), "\n"), nil
+}
@@ -0,0 +1,49 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// SuggestTests proposes test cases and skeleton test functions for the code paths a
+// diff changes. frameworks names the language and test framework of each changed file
+// type, and testFiles lists existing test files whose conventions the tests should
+// follow; either may be empty. onChunk is called with each piece of the suggestions as
+// it arrives.
+func (c *Client) SuggestTests(ctx context.Context, diff, frameworks, testFiles string, onChunk func(string)) (string, error) {
+	truncatedDiff, _, _ := c.truncateContent(c.prepareDiff(diff))
+	truncatedTestFiles, _ := c.tokenCounter.TruncateToWordLimit(testFiles, c.maxInputWords()/10)
+
+	if frameworks == "" {
+		frameworks = "Not detected; use the test framework the diff's language usually uses."
+	}
+	if truncatedTestFiles == "" {
+		truncatedTestFiles = "None found."
+	}
+
+	prompt := fmt.Sprintf(`You are a senior engineer writing tests for a code change.
+
+=== DIFF ===
+%s
+
+=== LANGUAGES AND TEST FRAMEWORKS ===
+%s
+
+=== EXISTING TEST FILES (follow their conventions) ===
+%s
+
+Suggest the tests this change needs:
+1. Start with a short list of the concrete cases to cover for each changed function or
+   code path: the normal behavior, edge cases (empty input, boundaries, nil/null,
+   errors) and any regression the change fixes
+2. Then give skeleton test functions in the framework above, ready to paste into the
+   test file they belong to (name the file), with test names, setup and assertions
+   filled in from the diff and TODO comments where values must come from the developer
+3. Match the package, naming and helpers of the existing test files
+4. Skip code that needs no tests (renames, comments, formatting, generated code) and
+   say so in one line if that is the whole change
+
+Respond in markdown with the code in fenced blocks tagged with the language.`, truncatedDiff, frameworks, truncatedTestFiles)
+
+	return c.StreamResponse(ctx, c.addLanguageInstruction(prompt), onChunk)
+}
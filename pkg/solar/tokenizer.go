@@ -2,15 +2,27 @@ package solar
 
 import (
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hunkim/sgit/pkg/logging"
 )
 
 const (
-	// Maximum tokens allowed for input (40K as requested by user)
+	// Maximum tokens allowed for input (40K as requested by user) with a model with
+	// ModelContextLimit of context; InputTokenLimit scales it for other models
 	MaxInputTokens = 40000
 	// Maximum words to stay under 40K tokens (40K / 1.5 = ~27K words)
 	MaxInputWords = 27000
-	// Solar Pro model's actual context limit
+	// Solar Pro model's actual context limit, also assumed for models that aren't in
+	// ContextWindows
 	ModelContextLimit = 65536
+
+	// lettersPerToken is about how many letters of a word or identifier make up a
+	// token in Solar's vocabulary
+	lettersPerToken = 5
+	// spacesPerToken is the longest run of spaces the vocabulary has a token for
+	spacesPerToken = 4
 )
 
 // TokenCounter provides functionality to count tokens using Solar Pro tokenizer logic
@@ -25,21 +37,88 @@ func NewTokenCounter() *TokenCounter {
 	}
 }
 
-// EstimateTokens provides a simple word-based token estimation
+// EstimateTokens counts the tokens Solar's SentencePiece tokenizer splits text into.
+// It follows how the tokenizer treats each kind of text rather than loading its
+// vocabulary: a space joins the word after it, words and identifiers take a token per
+// few letters (camelCase humps start a new one), and digits, punctuation, line breaks
+// and non-Latin characters take a token each.
 func (tc *TokenCounter) EstimateTokens(text string) int {
-	if text == "" {
-		return 0
+	tokens := 0
+	letters, spaces := 0, 0
+	var previous rune
+	flushLetters := func() {
+		if letters > 0 {
+			tokens += (letters + lettersPerToken - 1) / lettersPerToken
+			letters = 0
+		}
 	}
+	for _, r := range text {
+		switch {
+		case r < utf8.RuneSelf && unicode.IsLetter(r):
+			// A capital after a lower case letter starts a new piece of an identifier
+			if unicode.IsUpper(r) && unicode.IsLower(previous) {
+				flushLetters()
+			}
+			letters++
+		case r == ' ':
+			flushLetters()
+			spaces++
+			previous = r
+			continue
+		default:
+			flushLetters()
+			tokens++
+		}
+		// The first space is part of the next token; runs of them (indentation) are
+		// tokens of their own
+		if spaces > 1 {
+			tokens += (spaces - 1 + spacesPerToken - 1) / spacesPerToken
+		}
+		spaces = 0
+		previous = r
+	}
+	flushLetters()
+	if spaces > 1 {
+		tokens += (spaces - 1 + spacesPerToken - 1) / spacesPerToken
+	}
+	return tokens
+}
 
-	// Simple word count
-	words := strings.Fields(text)
-	wordCount := len(words)
+// TruncateToTokens cuts text at a line boundary so it and the truncation notice fit
+// in maxTokens, and returns it with its token count. Unlike TruncateToWordLimit it
+// keeps the text's lines and indentation, which diffs need.
+func (tc *TokenCounter) TruncateToTokens(text string, maxTokens int) (string, int) {
+	total := tc.EstimateTokens(text)
+	if total <= maxTokens {
+		return text, total
+	}
 
-	// For code/diff content, assume 1.5 tokens per word (conservative)
-	// This accounts for special characters, variable names, operators, etc.
-	estimatedTokens := int(float64(wordCount) * 1.5)
+	logging.Debug("truncating to fit the token budget", "tokens", total, "limit", maxTokens)
+	notice := "\n[... truncated to stay within token limit ...]"
+	limit := maxTokens - tc.EstimateTokens(notice)
+	if limit <= 0 {
+		return "", 0
+	}
 
-	return estimatedTokens
+	var kept strings.Builder
+	used := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		lineTokens := tc.EstimateTokens(line)
+		if used+lineTokens > limit {
+			// A line too long for what is left (minified code, a lock file) is cut
+			// short rather than dropped when nothing else would be kept
+			if kept.Len() == 0 {
+				runes := []rune(line)
+				kept.WriteString(string(runes[:len(runes)*(limit-used)/lineTokens]))
+				kept.WriteString("\n")
+			}
+			break
+		}
+		kept.WriteString(line)
+		used += lineTokens
+	}
+	truncated := strings.TrimRight(kept.String(), "\n") + "\n" + notice
+	return truncated, tc.EstimateTokens(truncated)
 }
 
 // TruncateToWordLimit truncates text to fit within the specified word limit
@@ -53,6 +132,7 @@ func (tc *TokenCounter) TruncateToWordLimit(text string, maxWords int) (string,
 		return text, len(words)
 	}
 
+	logging.Debug("truncating to fit the word limit", "words", len(words), "limit", maxWords)
 	// Take the first N words and add truncation notice
 	truncatedWords := words[:maxWords]
 	truncatedText := strings.Join(truncatedWords, " ")
@@ -66,45 +146,12 @@ func (tc *TokenCounter) CountWords(text string) int {
 	return len(strings.Fields(text))
 }
 
-// SplitContent intelligently splits content into sections for better truncation
+// SplitContent fits the sections of a commit prompt into the input budget, sharing it
+// out through a TokenBudget with the diff weighted most, and returns them with their
+// total token count
 func (tc *TokenCounter) SplitContent(diff, branch, recentCommits, fileList string) (string, string, string, string, int) {
-	// Calculate words for each section
-	diffWords := tc.CountWords(diff)
-	branchWords := tc.CountWords(branch)
-	recentCommitsWords := tc.CountWords(recentCommits)
-	fileListWords := tc.CountWords(fileList)
-
-	totalWords := diffWords + branchWords + recentCommitsWords + fileListWords
-
-	// If total is within limit, return as-is
-	if totalWords <= MaxInputWords {
-		return diff, branch, recentCommits, fileList, totalWords
-	}
-
-	// Priority order: diff (most important), fileList, recentCommits, branch
-	remainingWords := MaxInputWords
-
-	// Always preserve branch info (small)
-	if branchWords < remainingWords {
-		remainingWords -= branchWords
-	} else {
-		branch, _ = tc.TruncateToWordLimit(branch, remainingWords/4)
-		remainingWords -= remainingWords / 4
-	}
-
-	// Allocate words proportionally, with diff getting priority
-	diffAllocation := int(float64(remainingWords) * 0.6)                            // 60% for diff
-	fileListAllocation := int(float64(remainingWords) * 0.25)                       // 25% for file list
-	recentCommitsAllocation := remainingWords - diffAllocation - fileListAllocation // remainder for recent commits
-
-	// Truncate each section
-	truncatedDiff, actualDiffWords := tc.TruncateToWordLimit(diff, diffAllocation)
-	truncatedFileList, actualFileListWords := tc.TruncateToWordLimit(fileList, fileListAllocation)
-	truncatedRecentCommits, actualRecentCommitsWords := tc.TruncateToWordLimit(recentCommits, recentCommitsAllocation)
-
-	actualTotal := actualDiffWords + branchWords + actualRecentCommitsWords + actualFileListWords
-
-	return truncatedDiff, branch, truncatedRecentCommits, truncatedFileList, actualTotal
+	fitted, total := tc.NewTokenBudget(ModelContextLimit, DefaultOutputReserve, "").Fit(commitSections(diff, branch, recentCommits, fileList)...)
+	return fitted[0], fitted[1], fitted[2], fitted[3], total
 }
 
 // TruncateContent truncates a single content input to fit within word limits
@@ -0,0 +1,43 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExplainUndo explains what the last git operation did and which of the numbered undo
+// options fits the situation best. operation is sgit's reading of the reflog, state
+// describes the working tree and whether the commits are pushed. The response has the
+// form "WHAT HAPPENED: ...", "CONSEQUENCES: ...", "CHOICE: <option number>" and
+// "WHY: ...".
+func (c *Client) ExplainUndo(ctx context.Context, operation, reflog, state, options string) (string, error) {
+	truncatedReflog, _ := c.tokenCounter.TruncateToWordLimit(reflog, c.maxInputWords()/2)
+	truncatedState, _ := c.tokenCounter.TruncateToWordLimit(state, c.maxInputWords()/4)
+
+	prompt := fmt.Sprintf(`You are a git expert helping a developer undo their last operation safely.
+
+=== LAST OPERATION (as read from the reflog) ===
+%s
+
+=== RECENT REFLOG (newest first) ===
+%s
+
+=== REPOSITORY STATE ===
+%s
+
+=== UNDO OPTIONS ===
+%s
+
+Explain briefly what the last operation did, what undoing it will change, and pick the
+option that undoes it with the least risk. Prefer options that keep the developer's
+work (staged or in the working tree) and never rewrite commits that are already pushed
+unless the developer clearly works alone on the branch.
+
+Respond in exactly this format, keeping the labels in English:
+WHAT HAPPENED: <1-2 sentences>
+CONSEQUENCES: <1-3 sentences on what the chosen undo changes and what is kept>
+CHOICE: <option number>
+WHY: <1-2 sentences>`, operation, truncatedReflog, truncatedState, options)
+
+	return c.GenerateResponse(ctx, c.addLanguageInstruction(prompt))
+}
@@ -0,0 +1,41 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// SummarizeExpertise writes who to ask about a file or directory, and for review of
+// changes to it. ranking is sgit's ranking of the people who worked on path (their
+// share of the current lines from git blame, commits, lines changed and when they last
+// changed it), and contributions lists each person's commits there. onChunk is called
+// with each piece of the summary as it arrives.
+func (c *Client) SummarizeExpertise(ctx context.Context, path, ranking, contributions string, onChunk func(string)) (string, error) {
+	truncatedContributions, _ := c.tokenCounter.TruncateToWordLimit(contributions, c.maxInputWords()*3/4)
+
+	prompt := fmt.Sprintf(`You are a senior engineer helping a teammate find who knows '%s' best, to pick a
+reviewer or ask a question.
+
+=== RANKING (computed from git blame and git log) ===
+%s
+
+=== EACH PERSON'S COMMITS TO %s (newest first) ===
+%s
+
+For each person in the ranking, in the same order, write a short markdown section:
+### <name>
+1-2 sentences on what they built, fixed or changed there, naming the features,
+components or functions their commits are about, and whether their work there is
+recent or mostly in the past.
+**Ask about:** the topics they are the best person to ask about
+
+Then end with:
+### 👀 Suggested reviewers
+Who to ask for a review of a change to %s, and why, in 1-3 bullets. Prefer people
+who know the current code and worked on it recently.
+
+Base every statement on the commits above and don't invent work. Keep the ranking's
+order; it is computed, so don't re-rank people.`, path, ranking, path, truncatedContributions, path)
+
+	return c.StreamResponse(ctx, c.addLanguageInstruction(prompt), onChunk)
+}
@@ -0,0 +1,35 @@
+package solar
+
+import (
+	"context"
+	"fmt"
+)
+
+// SummarizeWorktrees describes what is in progress in each worktree of a repository.
+// worktrees has one section per worktree with its branch, uncommitted changes,
+// upstream state, any stopped operation, recent commits and a diffstat. onChunk is
+// called with each piece of the summary as it arrives.
+func (c *Client) SummarizeWorktrees(ctx context.Context, worktrees string, onChunk func(string)) (string, error) {
+	truncatedWorktrees, _ := c.tokenCounter.TruncateToWordLimit(worktrees, c.maxInputWords())
+
+	prompt := fmt.Sprintf(`You are helping a developer who works on several branches at once, each checked out
+in its own git worktree, pick up where they left off.
+
+=== WORKTREES ===
+%s
+
+For each worktree, in the order given, write one bullet in markdown:
+- **<branch or path>**: what is being worked on, inferred from the branch name, the
+  changed files and the recent commits (one sentence), then its state and the next step
+  (e.g. "uncommitted changes to commit", "2 commits to push", "rebase stopped on
+  conflicts: resolve and continue", "clean and up to date")
+
+Then add a short "### Cleanup" section naming worktrees that look finished or
+abandoned (clean and nothing to push, a missing directory, no commits for a long time)
+and can be removed with 'git worktree remove <path>' or 'git worktree prune'. Leave the
+section out when every worktree is still in use.
+
+Don't repeat the raw counts back; say what they mean for the developer.`, truncatedWorktrees)
+
+	return c.StreamResponse(ctx, c.addLanguageInstruction(prompt), onChunk)
+}
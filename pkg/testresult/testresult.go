@@ -0,0 +1,242 @@
+// Package testresult reads how many tests passed and failed from the output of common
+// test runners: go test, pytest, Jest and Vitest, Mocha, RSpec, cargo test and Maven.
+// Output it doesn't recognize still gives a result from the exit status.
+package testresult
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Result is the outcome of a test run
+type Result struct {
+	// Passed is whether the test command succeeded
+	Passed bool
+	// Counted is whether the counts below were read from the output
+	Counted                         bool
+	PassCount, FailCount, SkipCount int
+	// Unit is what was counted: "test", or "package" for go test without -v
+	Unit string
+	// Failures names the failing tests, where the runner lists them
+	Failures []string
+}
+
+// maxFailures bounds the failing tests named in a Result
+const maxFailures = 10
+
+// Summary describes the result in a few words, e.g. "all 142 tests pass" or "3 of 142
+// tests fail"
+func (r Result) Summary() string {
+	if !r.Counted {
+		if r.Passed {
+			return "tests pass"
+		}
+		return "tests fail"
+	}
+
+	total := r.PassCount + r.FailCount
+	var summary string
+	switch {
+	case r.FailCount > 0:
+		summary = fmt.Sprintf("%d of %d %s fail", r.FailCount, total, plural(r.Unit, total))
+	case !r.Passed:
+		// The runner failed, e.g. to compile, without reporting a failing test
+		summary = fmt.Sprintf("%s fail (%d %s pass)", plural(r.Unit, 2), r.PassCount, plural(r.Unit, r.PassCount))
+	case total == 1:
+		summary = fmt.Sprintf("1 %s passes", r.Unit)
+	default:
+		summary = fmt.Sprintf("all %d %s pass", total, plural(r.Unit, total))
+	}
+	if r.SkipCount > 0 {
+		summary += fmt.Sprintf(", %d skipped", r.SkipCount)
+	}
+	return summary
+}
+
+func plural(unit string, n int) string {
+	if n == 1 {
+		return unit
+	}
+	return unit + "s"
+}
+
+var (
+	goTest = regexp.MustCompile(`(?m)^--- (PASS|FAIL|SKIP): (\S+)`)
+	// go test without -v prints a line per package, e.g. "ok  	example.com/app	0.01s"
+	goPackage = regexp.MustCompile(`(?m)^(ok|FAIL)[ \t]+\S+`)
+	// pytest ends with e.g. "==== 2 failed, 140 passed, 1 skipped in 3.12s ===="
+	pytestSummary = regexp.MustCompile(`(?m)^=+ (.*\d+ (?:passed|failed|error).*) in [\d.]+m?s\b.*=+\s*$`)
+	pytestFailed  = regexp.MustCompile(`(?m)^(?:FAILED|ERROR) (\S+)`)
+	// Jest: "Tests:       2 failed, 140 passed, 142 total"; Vitest: "      Tests  2 failed | 140 passed (142)"
+	jestSummary = regexp.MustCompile(`(?m)^\s*Tests:?\s+(.*\d+ (?:passed|failed).*)$`)
+	cargoResult = regexp.MustCompile(`(?m)^test result: \w+\. (\d+) passed; (\d+) failed; (\d+) ignored`)
+	cargoFailed = regexp.MustCompile(`(?m)^test (\S+) \.\.\. FAILED`)
+	mavenResult = regexp.MustCompile(`(?m)Tests run: (\d+), Failures: (\d+), Errors: (\d+), Skipped: (\d+)\s*$`)
+	rspecResult = regexp.MustCompile(`(?m)^(\d+) examples?, (\d+) failures?(?:, (\d+) pending)?`)
+	mochaResult = regexp.MustCompile(`(?m)^\s*(\d+) (passing|failing|pending)\b`)
+	countWords  = regexp.MustCompile(`(\d+) (passed|failed|errors?|skipped|todo)\b`)
+)
+
+// Parse reads the result of a test run from its output; passed is whether the command
+// exited successfully
+func Parse(output string, passed bool) Result {
+	result := Result{Passed: passed, Unit: "test"}
+	for _, parse := range []func(string, *Result) bool{parseGoTest, parseCargo, parsePytest, parseJest, parseMaven, parseRSpec, parseMocha, parseGoPackages} {
+		if parse(output, &result) {
+			result.Counted = true
+			break
+		}
+	}
+	if len(result.Failures) > maxFailures {
+		result.Failures = result.Failures[:maxFailures]
+	}
+	return result
+}
+
+// parseGoTest counts the top-level tests of go test -v, and the failing tests it
+// reports without -v
+func parseGoTest(output string, result *Result) bool {
+	matches := goTest.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return false
+	}
+	var passed, failed, skipped int
+	var failures []string
+	for _, m := range matches {
+		switch m[1] {
+		case "PASS":
+			passed++
+		case "FAIL":
+			failed++
+			failures = append(failures, m[2])
+		case "SKIP":
+			skipped++
+		}
+	}
+	result.Failures = failures
+	// Without -v only failures are listed, and the packages are counted instead
+	if passed == 0 && !result.Passed {
+		return false
+	}
+	result.PassCount, result.FailCount, result.SkipCount = passed, failed, skipped
+	return true
+}
+
+// parseGoPackages counts the packages of go test without -v
+func parseGoPackages(output string, result *Result) bool {
+	matches := goPackage.FindAllStringSubmatch(output, -1)
+	counted := false
+	for _, m := range matches {
+		switch m[1] {
+		case "ok":
+			result.PassCount++
+			counted = true
+		case "FAIL":
+			result.FailCount++
+			counted = true
+		}
+	}
+	if counted {
+		result.Unit = "package"
+	}
+	return counted
+}
+
+func parseCargo(output string, result *Result) bool {
+	matches := cargoResult.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return false
+	}
+	for _, m := range matches {
+		result.PassCount += atoi(m[1])
+		result.FailCount += atoi(m[2])
+		result.SkipCount += atoi(m[3])
+	}
+	for _, m := range cargoFailed.FindAllStringSubmatch(output, -1) {
+		result.Failures = append(result.Failures, m[1])
+	}
+	return true
+}
+
+func parsePytest(output string, result *Result) bool {
+	matches := pytestSummary.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return false
+	}
+	addCounts(matches[len(matches)-1][1], result)
+	for _, m := range pytestFailed.FindAllStringSubmatch(output, -1) {
+		result.Failures = append(result.Failures, m[1])
+	}
+	return true
+}
+
+func parseJest(output string, result *Result) bool {
+	matches := jestSummary.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return false
+	}
+	addCounts(matches[len(matches)-1][1], result)
+	return true
+}
+
+// parseMaven reads the totals Maven prints last
+func parseMaven(output string, result *Result) bool {
+	matches := mavenResult.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return false
+	}
+	m := matches[len(matches)-1]
+	run, failures, errors, skipped := atoi(m[1]), atoi(m[2]), atoi(m[3]), atoi(m[4])
+	result.FailCount = failures + errors
+	result.SkipCount = skipped
+	result.PassCount = run - result.FailCount - skipped
+	return true
+}
+
+func parseRSpec(output string, result *Result) bool {
+	matches := rspecResult.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return false
+	}
+	m := matches[len(matches)-1]
+	result.FailCount = atoi(m[2])
+	result.SkipCount = atoi(m[3])
+	result.PassCount = atoi(m[1]) - result.FailCount - result.SkipCount
+	return true
+}
+
+func parseMocha(output string, result *Result) bool {
+	matches := mochaResult.FindAllStringSubmatch(output, -1)
+	for _, m := range matches {
+		switch m[2] {
+		case "passing":
+			result.PassCount = atoi(m[1])
+		case "failing":
+			result.FailCount = atoi(m[1])
+		case "pending":
+			result.SkipCount = atoi(m[1])
+		}
+	}
+	return len(matches) > 0
+}
+
+// addCounts adds the counts of a summary line such as "2 failed, 140 passed, 1 skipped"
+func addCounts(line string, result *Result) {
+	for _, m := range countWords.FindAllStringSubmatch(line, -1) {
+		switch n := atoi(m[1]); {
+		case m[2] == "passed":
+			result.PassCount += n
+		case m[2] == "failed" || strings.HasPrefix(m[2], "error"):
+			result.FailCount += n
+		default:
+			result.SkipCount += n
+		}
+	}
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
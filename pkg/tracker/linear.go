@@ -0,0 +1,103 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/httpclient"
+)
+
+// DefaultLinearAPIURL is the Linear GraphQL endpoint
+const DefaultLinearAPIURL = "https://api.linear.app/graphql"
+
+// LinearClient fetches issues from Linear
+type LinearClient struct {
+	apiURL string
+	token  string
+}
+
+// NewLinearClient creates a Linear client. token is a personal API key or an OAuth
+// access token ("Bearer ..."); apiURL may be empty for the default endpoint.
+func NewLinearClient(token, apiURL string) *LinearClient {
+	if apiURL == "" {
+		apiURL = DefaultLinearAPIURL
+	}
+	return &LinearClient{apiURL: apiURL, token: token}
+}
+
+// linearIssueQuery looks an issue up by its identifier, e.g. ENG-123
+const linearIssueQuery = `query Issue($id: String!) { issue(id: $id) { identifier title description url } }`
+
+// GetTicket fetches the title and description of a Linear issue
+func (l *LinearClient) GetTicket(ctx context.Context, key string) (*Ticket, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"query":     linearIssueQuery,
+		"variables": map[string]string{"id": key},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", l.apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", l.token)
+
+	resp, err := httpclient.Shared().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Linear API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data struct {
+			Issue *struct {
+				Identifier  string `json:"identifier"`
+				Title       string `json:"title"`
+				Description string `json:"description"`
+				URL         string `json:"url"`
+			} `json:"issue"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %v", err)
+	}
+
+	if len(result.Errors) > 0 {
+		var messages []string
+		for _, e := range result.Errors {
+			messages = append(messages, e.Message)
+		}
+		return nil, fmt.Errorf("Linear API error: %s", strings.Join(messages, "; "))
+	}
+	if result.Data.Issue == nil {
+		return nil, fmt.Errorf("Linear issue %s not found", key)
+	}
+
+	issue := result.Data.Issue
+	return &Ticket{
+		Key:         issue.Identifier,
+		Title:       issue.Title,
+		Description: issue.Description,
+		URL:         issue.URL,
+	}, nil
+}
@@ -0,0 +1,106 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/hunkim/sgit/pkg/httpclient"
+)
+
+// Ticket is an issue from an external tracker (GitHub, Jira, ...)
+type Ticket struct {
+	Key         string
+	Title       string
+	Description string
+	URL         string
+}
+
+// Tracker fetches tickets by key from an issue tracker
+type Tracker interface {
+	GetTicket(ctx context.Context, key string) (*Ticket, error)
+}
+
+// ticketKeyPattern matches Jira-style ticket keys such as PROJ-123 (Linear uses the same form)
+var ticketKeyPattern = regexp.MustCompile(`\b([A-Z][A-Z0-9]+-\d+)\b`)
+
+// FindTicketKey returns the first Jira-style ticket key found in text, or ""
+func FindTicketKey(text string) string {
+	return ticketKeyPattern.FindString(strings.ToUpper(text))
+}
+
+// IsTicketKey reports whether s is a Jira-style ticket key
+func IsTicketKey(s string) bool {
+	return ticketKeyPattern.MatchString(s) && ticketKeyPattern.FindString(s) == s
+}
+
+// JiraClient fetches issues from a Jira Cloud or Server instance
+type JiraClient struct {
+	baseURL string
+	email   string
+	token   string
+}
+
+// NewJiraClient creates a Jira client. When email is empty, token is sent as a
+// bearer token (Jira Server/Data Center personal access tokens); otherwise basic
+// auth with email and API token is used (Jira Cloud).
+func NewJiraClient(baseURL, email, token string) *JiraClient {
+	return &JiraClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		email:   email,
+		token:   token,
+	}
+}
+
+// GetTicket fetches the summary and description of a Jira issue
+func (j *JiraClient) GetTicket(ctx context.Context, key string) (*Ticket, error) {
+	url := fmt.Sprintf("%s/rest/api/2/issue/%s?fields=summary,description", j.baseURL, key)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if j.email != "" {
+		req.SetBasicAuth(j.email, j.token)
+	} else if j.token != "" {
+		req.Header.Set("Authorization", "Bearer "+j.token)
+	}
+
+	resp, err := httpclient.Shared().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Jira API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var issue struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary     string `json:"summary"`
+			Description string `json:"description"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(body, &issue); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %v", err)
+	}
+
+	return &Ticket{
+		Key:         issue.Key,
+		Title:       issue.Fields.Summary,
+		Description: issue.Fields.Description,
+		URL:         fmt.Sprintf("%s/browse/%s", j.baseURL, issue.Key),
+	}, nil
+}
@@ -0,0 +1,360 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// GenerateFunc produces a commit message, calling onChunk as content streams in
+type GenerateFunc func(ctx context.Context, onChunk func(string)) (string, error)
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	paneStyle     = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8"))
+	activeStyle   = paneStyle.Copy().BorderForeground(lipgloss.Color("12"))
+	helpStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	addedStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	removedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	hunkStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	fileLineStyle = lipgloss.NewStyle().Bold(true)
+)
+
+// chunkMsg carries a piece of streamed content
+type chunkMsg struct {
+	events chan tea.Msg
+	text   string
+}
+
+// doneMsg signals the end of a generation
+type doneMsg struct {
+	events  chan tea.Msg
+	content string
+	err     error
+}
+
+// commitReview is the bubbletea model for reviewing an AI commit message
+type commitReview struct {
+	ctx      context.Context
+	generate GenerateFunc
+	diff     string
+
+	candidates []string
+	current    int
+	streaming  bool
+	partial    string
+	events     chan tea.Msg
+	cancel     context.CancelFunc
+	err        error
+
+	editing  bool
+	editor   textarea.Model
+	diffView viewport.Model
+	ready    bool
+	width    int
+	height   int
+
+	result   string
+	accepted bool
+}
+
+// RunCommitReview shows the staged diff next to the streaming AI message and lets
+// the user accept, edit inline, regenerate, or switch between candidates. Regenerating
+// while a message still streams abandons it.
+// It returns the chosen message and whether the user accepted it.
+func RunCommitReview(ctx context.Context, diff string, generate GenerateFunc) (string, bool, error) {
+	editor := textarea.New()
+	editor.ShowLineNumbers = false
+	editor.CharLimit = 0
+
+	m := &commitReview{
+		ctx:      ctx,
+		generate: generate,
+		diff:     colorizeDiff(diff),
+		editor:   editor,
+	}
+
+	final, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	if err != nil {
+		return "", false, fmt.Errorf("error running TUI: %v", err)
+	}
+
+	review := final.(*commitReview)
+	return review.result, review.accepted, nil
+}
+
+func (m *commitReview) Init() tea.Cmd {
+	return m.startGeneration()
+}
+
+// startGeneration runs the generator in the background, forwarding chunks to the UI
+func (m *commitReview) startGeneration() tea.Cmd {
+	ctx, cancel := context.WithCancel(m.ctx)
+	events := make(chan tea.Msg, 64)
+
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.cancel = cancel
+	m.events = events
+	m.streaming = true
+	m.partial = ""
+	m.err = nil
+
+	go func() {
+		defer cancel()
+		content, err := m.generate(ctx, func(chunk string) {
+			select {
+			case events <- chunkMsg{events: events, text: chunk}:
+			case <-ctx.Done():
+			}
+		})
+		events <- doneMsg{events: events, content: content, err: err}
+		close(events)
+	}()
+
+	return waitForEvent(events)
+}
+
+func waitForEvent(events chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-events
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+func (m *commitReview) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.layout()
+		return m, nil
+
+	case chunkMsg:
+		if msg.events != m.events {
+			// An abandoned generation; drain it so it can finish
+			return m, waitForEvent(msg.events)
+		}
+		m.partial += msg.text
+		return m, waitForEvent(m.events)
+
+	case doneMsg:
+		if msg.events != m.events {
+			return m, nil
+		}
+		m.streaming = false
+		if msg.err != nil {
+			m.err = msg.err
+		} else if msg.content != "" {
+			m.candidates = append(m.candidates, msg.content)
+			m.current = len(m.candidates) - 1
+		}
+		m.partial = ""
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.editing {
+			return m.updateEditing(msg)
+		}
+		return m.updateBrowsing(msg)
+	}
+
+	return m, nil
+}
+
+// updateBrowsing handles keys while viewing candidates
+func (m *commitReview) updateBrowsing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q", "esc":
+		if m.cancel != nil {
+			m.cancel()
+		}
+		return m, tea.Quit
+
+	case "enter", "a":
+		if m.streaming || len(m.candidates) == 0 {
+			return m, nil
+		}
+		m.result = m.candidates[m.current]
+		m.accepted = true
+		return m, tea.Quit
+
+	case "e":
+		if m.streaming || len(m.candidates) == 0 {
+			return m, nil
+		}
+		m.editing = true
+		m.editor.SetValue(m.candidates[m.current])
+		m.editor.Focus()
+		return m, textarea.Blink
+
+	case "r":
+		return m, m.startGeneration()
+
+	case "tab", "right", "l":
+		if len(m.candidates) > 0 {
+			m.current = (m.current + 1) % len(m.candidates)
+		}
+		return m, nil
+
+	case "shift+tab", "left", "h":
+		if len(m.candidates) > 0 {
+			m.current = (m.current + len(m.candidates) - 1) % len(m.candidates)
+		}
+		return m, nil
+	}
+
+	// Remaining keys (arrows, pgup/pgdn, j/k) scroll the diff
+	var cmd tea.Cmd
+	m.diffView, cmd = m.diffView.Update(msg)
+	return m, cmd
+}
+
+// updateEditing handles keys while editing a candidate inline
+func (m *commitReview) updateEditing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+s":
+		if edited := strings.TrimSpace(m.editor.Value()); edited != "" {
+			m.candidates[m.current] = edited
+		}
+		m.editing = false
+		m.editor.Blur()
+		return m, nil
+	case "esc":
+		m.editing = false
+		m.editor.Blur()
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+
+	var cmd tea.Cmd
+	m.editor, cmd = m.editor.Update(msg)
+	return m, cmd
+}
+
+// sideBySide reports whether the panes fit next to each other
+func (m *commitReview) sideBySide() bool {
+	return m.width >= 120
+}
+
+// paneSize returns the inner size of each pane for the current window
+func (m *commitReview) paneSize() (int, int) {
+	// Reserve lines for the title and help bars, and 2 for each pane's border
+	available := m.height - 4
+	if m.sideBySide() {
+		return m.width/2 - 2, available - 2
+	}
+	return m.width - 2, available/2 - 2
+}
+
+func (m *commitReview) layout() {
+	w, h := m.paneSize()
+	if w < 10 {
+		w = 10
+	}
+	if h < 3 {
+		h = 3
+	}
+
+	if !m.ready {
+		m.diffView = viewport.New(w, h)
+		m.diffView.SetContent(m.diff)
+		m.ready = true
+	} else {
+		m.diffView.Width = w
+		m.diffView.Height = h
+	}
+	m.editor.SetWidth(w)
+	m.editor.SetHeight(h - 1)
+}
+
+func (m *commitReview) View() string {
+	if !m.ready {
+		return "Loading..."
+	}
+
+	w, h := m.paneSize()
+
+	title := titleStyle.Render("sgit commit — review AI message")
+	if len(m.candidates) > 1 {
+		title += helpStyle.Render(fmt.Sprintf("  candidate %d/%d", m.current+1, len(m.candidates)))
+	}
+
+	diffPane := paneStyle.Width(w).Height(h).Render(m.diffView.View())
+	messagePane := activeStyle.Width(w).Height(h).Render(m.messageView(w))
+
+	var body string
+	if m.sideBySide() {
+		body = lipgloss.JoinHorizontal(lipgloss.Top, diffPane, messagePane)
+	} else {
+		body = lipgloss.JoinVertical(lipgloss.Left, diffPane, messagePane)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, body, helpStyle.Render(m.helpLine()))
+}
+
+// messageView renders the commit message pane contents
+func (m *commitReview) messageView(width int) string {
+	if m.editing {
+		return m.editor.View()
+	}
+	if m.streaming {
+		if m.partial == "" {
+			return helpStyle.Render("Generating commit message with Solar LLM...")
+		}
+		return lipgloss.NewStyle().Width(width).Render(m.partial + "▌")
+	}
+	if m.err != nil {
+		message := errorStyle.Render(fmt.Sprintf("Error: %v", m.err))
+		return lipgloss.NewStyle().Width(width).Render(message + "\n\nPress r to retry or q to quit.")
+	}
+	if len(m.candidates) == 0 {
+		return helpStyle.Render("No message generated. Press r to retry.")
+	}
+	return lipgloss.NewStyle().Width(width).Render(m.candidates[m.current])
+}
+
+func (m *commitReview) helpLine() string {
+	if m.editing {
+		return "ctrl+s save • esc discard edits"
+	}
+	if m.streaming {
+		return "r regenerate • ↑/↓ scroll diff • q quit"
+	}
+	if len(m.candidates) == 0 {
+		return "r retry • ↑/↓ scroll diff • q quit"
+	}
+	help := "enter accept • e edit • r regenerate"
+	if len(m.candidates) > 1 {
+		help += " • tab/←/→ switch candidate"
+	}
+	return help + " • ↑/↓ scroll diff • q quit"
+}
+
+// colorizeDiff applies diff syntax colors line by line
+func colorizeDiff(diff string) string {
+	lines := strings.Split(diff, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "diff --git"):
+			lines[i] = fileLineStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = addedStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = removedStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = hunkStyle.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}